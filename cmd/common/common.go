@@ -19,7 +19,9 @@
 package common
 
 import (
+	"encoding/hex"
 	"fmt"
+	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/cmd/utils"
 	"github.com/polynetwork/poly/common"
@@ -135,6 +137,31 @@ func GetAccount(ctx *cli.Context, address ...string) (*account.Account, error) {
 	return GetAccountMulti(wallet, passwd, accAddr)
 }
 
+// GetSigner returns the remote signer if one is specified, or the signer of the wallet account
+func GetSigner(ctx *cli.Context) (utils.TxSigner, error) {
+	url := ctx.String(utils.GetFlagName(utils.RemoteSignerFlag))
+	if url == "" {
+		acc, err := GetAccount(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return utils.NewAccountSigner(acc), nil
+	}
+	pkstr := ctx.String(utils.GetFlagName(utils.RemoteSignerPubKeyFlag))
+	if pkstr == "" {
+		return nil, fmt.Errorf("missing argument %s", utils.GetFlagName(utils.RemoteSignerPubKeyFlag))
+	}
+	data, err := hex.DecodeString(pkstr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pub key:%s", pkstr)
+	}
+	pubKey, err := keypair.DeserializePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pub key:%s", pkstr)
+	}
+	return utils.NewRemoteSigner(url, pubKey), nil
+}
+
 func IsBase58Address(address string) bool {
 	if address == "" {
 		return false
@@ -35,10 +35,12 @@ func SetOntologyConfig(ctx *cli.Context) (*config.OntologyConfig, error) {
 	}
 	setCommonConfig(ctx, cfg.Common)
 	setConsensusConfig(ctx, cfg.Consensus)
+	setTxPoolConfig(ctx, cfg.TxPool)
 	setP2PNodeConfig(ctx, cfg.P2PNode)
 	setRpcConfig(ctx, cfg.Rpc)
 	setRestfulConfig(ctx, cfg.Restful)
 	setWebSocketConfig(ctx, cfg.Ws)
+	setPublisherConfig(ctx, cfg.Publisher)
 	if cfg.Genesis.ConsensusType == config.CONSENSUS_TYPE_SOLO {
 		cfg.Ws.EnableHttpWs = true
 		cfg.Restful.EnableHttpRestful = true
@@ -121,6 +123,9 @@ func setCommonConfig(ctx *cli.Context, cfg *config.CommonConfig) {
 	cfg.LogLevel = ctx.Uint(utils.GetFlagName(utils.LogLevelFlag))
 	cfg.EnableEventLog = !ctx.Bool(utils.GetFlagName(utils.DisableEventLogFlag))
 	cfg.DataDir = ctx.String(utils.GetFlagName(utils.DataDirFlag))
+	cfg.ExecWorkers = ctx.Uint(utils.GetFlagName(utils.ExecWorkersFlag))
+	cfg.StateDiff = ctx.Bool(utils.GetFlagName(utils.StateDiffFlag))
+	cfg.StateDiffDir = ctx.String(utils.GetFlagName(utils.StateDiffDirFlag))
 }
 
 func setConsensusConfig(ctx *cli.Context, cfg *config.ConsensusConfig) {
@@ -128,6 +133,11 @@ func setConsensusConfig(ctx *cli.Context, cfg *config.ConsensusConfig) {
 	cfg.MaxTxInBlock = ctx.Uint(utils.GetFlagName(utils.MaxTxInBlockFlag))
 }
 
+func setTxPoolConfig(ctx *cli.Context, cfg *config.TxPoolConfig) {
+	cfg.HeaderSyncWeight = ctx.Uint(utils.GetFlagName(utils.TxpoolHeaderSyncWeightFlag))
+	cfg.ImportTransferWeight = ctx.Uint(utils.GetFlagName(utils.TxpoolImportTransferWeightFlag))
+}
+
 func setP2PNodeConfig(ctx *cli.Context, cfg *config.P2PNodeConfig) {
 	cfg.NetworkId = uint32(ctx.Uint(utils.GetFlagName(utils.NetworkIdFlag)))
 	cfg.NetworkMagic = config.GetNetworkMagic(cfg.NetworkId)
@@ -179,6 +189,13 @@ func setWebSocketConfig(ctx *cli.Context, cfg *config.WebSocketConfig) {
 	cfg.HttpWsPort = ctx.Uint(utils.GetFlagName(utils.WsPortFlag))
 }
 
+func setPublisherConfig(ctx *cli.Context, cfg *config.PublisherConfig) {
+	cfg.EnablePublisher = ctx.Bool(utils.GetFlagName(utils.PublishEnabledFlag))
+	cfg.NatsAddress = ctx.String(utils.GetFlagName(utils.PublishNatsFlag))
+	cfg.Subject = ctx.String(utils.GetFlagName(utils.PublishSubjectFlag))
+	cfg.CheckpointPath = ctx.String(utils.GetFlagName(utils.PublishCheckpointFlag))
+}
+
 func SetRpcPort(ctx *cli.Context) {
 	if ctx.IsSet(utils.GetFlagName(utils.RPCPortFlag)) {
 		config.DefConfig.Rpc.HttpJsonPort = ctx.Uint(utils.GetFlagName(utils.RPCPortFlag))
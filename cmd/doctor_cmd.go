@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	"github.com/urfave/cli"
+)
+
+var DoctorCommand = cli.Command{
+	Action:    checkChain,
+	Name:      "doctor",
+	Usage:     "Check the configuration of a registered side chain end to end",
+	ArgsUsage: "[arguments...]",
+	Description: `Check a chain being onboarded: registration, header sync freshness, validator set, asset binds resolvable
+both ways, relay fee and proof spec. Each failed check is printed with what to do about it.`,
+	Flags: []cli.Flag{
+		utils.RPCPortFlag,
+		utils.DoctorChainIdFlag,
+		utils.DoctorMaxLagFlag,
+	},
+}
+
+func checkChain(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	chainID := uint64(ctx.Uint(utils.GetFlagName(utils.DoctorChainIdFlag)))
+	if chainID == 0 {
+		PrintErrorMsg("Missing %s argument.", utils.DoctorChainIdFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	maxLag := uint32(ctx.Uint(utils.GetFlagName(utils.DoctorMaxLagFlag)))
+	report, err := utils.GetChainReport(chainID, maxLag)
+	if err != nil {
+		return fmt.Errorf("GetChainReport error:%s", err)
+	}
+	PrintInfoMsg("Chain:%d at poly height:%d", report.ChainID, report.Height)
+	for _, check := range report.Checks {
+		switch check.Status {
+		case cross_chain_manager.CHECK_PASS:
+			PrintInfoMsg("  [pass] %s: %s", check.Name, check.Message)
+		case cross_chain_manager.CHECK_SKIP:
+			PrintInfoMsg("  [skip] %s: %s", check.Name, check.Message)
+		default:
+			PrintErrorMsg("  [fail] %s: %s", check.Name, check.Message)
+		}
+	}
+	if report.Failed() {
+		return fmt.Errorf("chain %d failed the checks", chainID)
+	}
+	PrintInfoMsg("Chain %d passed the checks.", chainID)
+	return nil
+}
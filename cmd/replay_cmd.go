@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/store/ledgerstore"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/urfave/cli"
+)
+
+var ReplayCrossChainCommand = cli.Command{
+	Action:    replayCrossChain,
+	Name:      "replay-crosschain",
+	Usage:     "Replay a historical native transaction and print its storage changes and events",
+	ArgsUsage: "[arguments...]",
+	Flags: []cli.Flag{
+		utils.ReplayHeightFlag,
+		utils.ReplayTxFlag,
+		utils.ReplaySandboxFlag,
+		utils.DataDirFlag,
+		utils.ConfigFlag,
+		utils.NetworkIdFlag,
+	},
+	Description: `Re-executes the transaction of the block at height against the state of height-1, for debugging
+disputed cross chain verifications. Only the latest state is kept by a node, so the state of height-1 is rebuilt
+in the sandbox dir by executing all the blocks before, which takes a while on a long chain.
+The ledger of the node is only read, but the node must be stopped during replay.`,
+}
+
+func replayCrossChain(ctx *cli.Context) error {
+	height := uint32(ctx.Uint(utils.GetFlagName(utils.ReplayHeightFlag)))
+	if height == 0 {
+		PrintErrorMsg("Missing %s argument.", utils.ReplayHeightFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	hashStr := ctx.String(utils.GetFlagName(utils.ReplayTxFlag))
+	if hashStr == "" {
+		PrintErrorMsg("Missing %s argument.", utils.ReplayTxFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	txHash, err := common.Uint256FromHexString(hashStr)
+	if err != nil {
+		return fmt.Errorf("invalid tx hash:%s error:%s", hashStr, err)
+	}
+	sandboxDir := ctx.String(utils.GetFlagName(utils.ReplaySandboxFlag))
+	if _, err := os.Stat(sandboxDir); !os.IsNotExist(err) {
+		return fmt.Errorf("sandbox dir:%s already exists", sandboxDir)
+	}
+	store, err := openSnapshotLedgerStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	bookkeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		return fmt.Errorf("GetBookkeepers error:%s", err)
+	}
+	defer os.RemoveAll(sandboxDir)
+
+	PrintInfoMsg("Start rebuild state of height %d.", height-1)
+	result, err := store.ReplayTransaction(height, txHash, sandboxDir, bookkeepers)
+	if err != nil {
+		return fmt.Errorf("replay error:%s", err)
+	}
+	printReplayResult(result)
+	return nil
+}
+
+func printReplayResult(result *ledgerstore.ReplayResult) {
+	PrintInfoMsg("Height:%d", result.Height)
+	PrintInfoMsg("TxHash:%s", result.TxHash.ToHexString())
+	if result.Notify.State == event.CONTRACT_STATE_SUCCESS {
+		PrintInfoMsg("State:success")
+	} else {
+		PrintInfoMsg("State:fail")
+		PrintInfoMsg("Error:%s", result.Error)
+	}
+	PrintInfoMsg("Storage changes:%d", len(result.Changes))
+	for _, change := range result.Changes {
+		PrintInfoMsg("  Contract:%s Key:%x", change.Contract.ToHexString(), change.Key)
+		PrintInfoMsg("    Old:%x", change.Old)
+		if len(change.New) == 0 {
+			PrintInfoMsg("    New:<deleted>")
+		} else {
+			PrintInfoMsg("    New:%x", change.New)
+		}
+	}
+	PrintInfoMsg("Events:%d", len(result.Notify.Notify))
+	for _, notify := range result.Notify.Notify {
+		PrintInfoMsg("  Contract:%s", notify.ContractAddress.ToHexString())
+		PrintJsonObject(notify.States)
+	}
+}
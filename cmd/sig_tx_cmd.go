@@ -53,6 +53,8 @@ var MultiSigTxCommand = cli.Command{
 		utils.AccountMultiMFlag,
 		utils.AccountMultiPubKeyFlag,
 		utils.AccountAddressFlag,
+		utils.RemoteSignerFlag,
+		utils.RemoteSignerPubKeyFlag,
 		utils.SendTxFlag,
 		utils.PrepareExecTransactionFlag,
 	},
@@ -68,6 +70,8 @@ var SigTxCommand = cli.Command{
 		utils.RPCPortFlag,
 		utils.WalletFileFlag,
 		utils.AccountAddressFlag,
+		utils.RemoteSignerFlag,
+		utils.RemoteSignerPubKeyFlag,
 		utils.SendTxFlag,
 		utils.PrepareExecTransactionFlag,
 	},
@@ -170,11 +174,11 @@ func multiSigToTx(ctx *cli.Context) error {
 		return fmt.Errorf("TransactionFromRawBytes error:%s", err)
 	}
 
-	acc, err := cmdcom.GetAccount(ctx)
+	signer, err := cmdcom.GetSigner(ctx)
 	if err != nil {
-		return fmt.Errorf("GetAccount error:%s", err)
+		return fmt.Errorf("GetSigner error:%s", err)
 	}
-	err = utils.MultiSigTransaction(tx, uint16(m), pubKeys, acc)
+	err = utils.MultiSigTransactionWith(tx, uint16(m), pubKeys, signer)
 	if err != nil {
 		return fmt.Errorf("MultiSigTransaction error:%s", err)
 	}
@@ -233,12 +237,12 @@ func sigToTx(ctx *cli.Context) error {
 		return fmt.Errorf("TransactionFromRawBytes error:%s", err)
 	}
 
-	acc, err := cmdcom.GetAccount(ctx)
+	signer, err := cmdcom.GetSigner(ctx)
 	if err != nil {
-		return fmt.Errorf("GetAccount error:%s", err)
+		return fmt.Errorf("GetSigner error:%s", err)
 	}
 
-	err = utils.SignTransaction(acc, tx)
+	err = utils.SignTransactionWith(signer, tx)
 	if err != nil {
 		return fmt.Errorf("SignTransaction error:%s", err)
 	}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/core/store/ledgerstore"
+	"github.com/urfave/cli"
+)
+
+var SnapshotCommand = cli.Command{
+	Action:    cli.ShowSubcommandHelp,
+	Name:      "snapshot",
+	Usage:     "Export or import state snapshot for fast node bootstrap",
+	ArgsUsage: "[arguments...]",
+	Description: `A state snapshot carries the states at current block of a stopped node, a new node imports it
+into an empty data dir and syncs from the snapshot height instead of executing all the history blocks.
+The block hashes of the snapshot are verified against the trusted block hash, but the states are only
+checked against the entries hash printed at export, compare it with the one of a node you trust.`,
+	Subcommands: []cli.Command{
+		{
+			Action:    exportSnapshot,
+			Name:      "export",
+			Usage:     "Export state snapshot of current block",
+			ArgsUsage: "[sub-command options]",
+			Flags: []cli.Flag{
+				utils.SnapshotFileFlag,
+				utils.DataDirFlag,
+				utils.ConfigFlag,
+				utils.NetworkIdFlag,
+			},
+			Description: "Note that the node must be stopped during export",
+		},
+		{
+			Action:    importSnapshot,
+			Name:      "import",
+			Usage:     "Import state snapshot into an empty data dir",
+			ArgsUsage: "[sub-command options]",
+			Flags: []cli.Flag{
+				utils.SnapshotFileFlag,
+				utils.SnapshotTrustedHashFlag,
+				utils.DataDirFlag,
+				utils.ConfigFlag,
+				utils.NetworkIdFlag,
+			},
+		},
+	},
+}
+
+func openSnapshotLedgerStore(ctx *cli.Context) (*ledgerstore.LedgerStoreImp, error) {
+	log.InitLog(log.InfoLog)
+	cfg, err := SetOntologyConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("SetOntologyConfig error:%s", err)
+	}
+	dbDir := utils.GetStoreDirPath(cfg.Common.DataDir, cfg.P2PNode.NetworkName)
+	store, err := ledgerstore.NewLedgerStore(dbDir)
+	if err != nil {
+		return nil, fmt.Errorf("NewLedgerStore error:%s", err)
+	}
+	return store, nil
+}
+
+func exportSnapshot(ctx *cli.Context) error {
+	snapshotFile := ctx.String(utils.GetFlagName(utils.SnapshotFileFlag))
+	if snapshotFile == "" {
+		PrintErrorMsg("Missing %s argument.", utils.SnapshotFileFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	store, err := openSnapshotLedgerStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	sf, err := os.OpenFile(snapshotFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0664)
+	if err != nil {
+		return fmt.Errorf("open file:%s error:%s", snapshotFile, err)
+	}
+	defer sf.Close()
+	fWriter := bufio.NewWriter(sf)
+
+	PrintInfoMsg("Start export snapshot.")
+	manifest, err := store.ExportSnapshot(fWriter)
+	if err != nil {
+		return fmt.Errorf("export snapshot error:%s", err)
+	}
+	err = fWriter.Flush()
+	if err != nil {
+		return fmt.Errorf("export flush file error:%s", err)
+	}
+	PrintInfoMsg("Export snapshot successfully.")
+	printSnapshotManifest(manifest)
+	PrintInfoMsg("Snapshot file:%s", snapshotFile)
+	return nil
+}
+
+func importSnapshot(ctx *cli.Context) error {
+	snapshotFile := ctx.String(utils.GetFlagName(utils.SnapshotFileFlag))
+	if snapshotFile == "" {
+		PrintErrorMsg("Missing %s argument.", utils.SnapshotFileFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	hashStr := ctx.String(utils.GetFlagName(utils.SnapshotTrustedHashFlag))
+	if hashStr == "" {
+		PrintErrorMsg("Missing %s argument.", utils.SnapshotTrustedHashFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	trustedHash, err := common.Uint256FromHexString(hashStr)
+	if err != nil {
+		return fmt.Errorf("invalid trusted hash:%s error:%s", hashStr, err)
+	}
+	store, err := openSnapshotLedgerStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	sf, err := os.OpenFile(snapshotFile, os.O_RDONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("OpenFile error:%s", err)
+	}
+	defer sf.Close()
+
+	PrintInfoMsg("Start import snapshot.")
+	manifest, err := store.ImportSnapshot(bufio.NewReader(sf), trustedHash)
+	if err != nil {
+		return fmt.Errorf("import snapshot error:%s", err)
+	}
+	PrintInfoMsg("Import snapshot successfully, node of network %s starts from it at next launch.", config.DefConfig.P2PNode.NetworkName)
+	printSnapshotManifest(manifest)
+	return nil
+}
+
+func printSnapshotManifest(manifest *ledgerstore.SnapshotManifest) {
+	PrintInfoMsg("Height:%d", manifest.Height)
+	PrintInfoMsg("BlockHash:%s", manifest.BlockHash.ToHexString())
+	PrintInfoMsg("EntryCount:%d", manifest.EntryCount)
+	PrintInfoMsg("EntriesHash:%s", manifest.EntriesHash.ToHexString())
+}
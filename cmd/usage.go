@@ -101,6 +101,9 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.LogLevelFlag,
 			utils.DisableEventLogFlag,
 			utils.DataDirFlag,
+			utils.ExecWorkersFlag,
+			utils.StateDiffFlag,
+			utils.StateDiffDirFlag,
 		},
 	},
 	{
@@ -137,6 +140,8 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "TXPOOL",
 		Flags: []cli.Flag{
 			utils.TxpoolPreExecDisableFlag,
+			utils.TxpoolHeaderSyncWeightFlag,
+			utils.TxpoolImportTransferWeightFlag,
 			utils.DisableSyncVerifyTxFlag,
 			utils.DisableBroadcastNetTxFlag,
 		},
@@ -180,6 +185,15 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WsPortFlag,
 		},
 	},
+	{
+		Name: "PUBLISHER",
+		Flags: []cli.Flag{
+			utils.PublishEnabledFlag,
+			utils.PublishNatsFlag,
+			utils.PublishSubjectFlag,
+			utils.PublishCheckpointFlag,
+		},
+	},
 	{
 		Name: "TEST MODE",
 		Flags: []cli.Flag{
@@ -245,6 +259,29 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.ImportEndHeightFlag,
 		},
 	},
+	{
+		Name: "SNAPSHOT",
+		Flags: []cli.Flag{
+			utils.SnapshotFileFlag,
+			utils.SnapshotTrustedHashFlag,
+		},
+	},
+	{
+		Name: "REPLAY",
+		Flags: []cli.Flag{
+			utils.ReplayHeightFlag,
+			utils.ReplayTxFlag,
+			utils.ReplaySandboxFlag,
+		},
+	},
+	{
+		Name: "UTXO",
+		Flags: []cli.Flag{
+			utils.UtxoChainIdFlag,
+			utils.UtxoRedeemKeyFlag,
+			utils.UtxoFileFlag,
+		},
+	},
 	{
 		Name: "MISC",
 	},
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native/client"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+)
+
+// GetChainReport returns the checks of the configuration of a registered side chain, headers synced more
+// than maxLag poly blocks ago failing the header sync check unless maxLag is 0
+func GetChainReport(chainID uint64, maxLag uint32) (*cross_chain_manager.ChainReport, error) {
+	tx, err := NewInvokeTransaction(client.CheckChain(&cross_chain_manager.CheckChainParam{
+		ChainID: chainID,
+		MaxLag:  maxLag,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	sink := common.NewZeroCopySink(nil)
+	if err := tx.Serialization(sink); err != nil {
+		return nil, fmt.Errorf("tx serialization error:%s", err)
+	}
+	preResult, err := PrepareSendRawTransaction(hex.EncodeToString(sink.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	if preResult.State == 0 {
+		return nil, fmt.Errorf("prepare execute transaction failed. %v", preResult)
+	}
+	str, ok := preResult.Result.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid result:%v", preResult.Result)
+	}
+	raw, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("hex decode result error:%s", err)
+	}
+	report := new(cross_chain_manager.ChainReport)
+	if err := report.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("deserialize report error:%s", err)
+	}
+	return report, nil
+}
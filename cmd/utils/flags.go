@@ -26,10 +26,14 @@ import (
 )
 
 const (
-	DEFAULT_EXPORT_FILE   = "./OntBlocks.dat"
-	DEFAULT_ABI_PATH      = "./abi"
-	DEFAULT_EXPORT_HEIGHT = 0
-	DEFAULT_WALLET_PATH   = "./wallet_data"
+	DEFAULT_EXPORT_FILE    = "./OntBlocks.dat"
+	DEFAULT_SNAPSHOT_FILE  = "./PolySnapshot.dat"
+	DEFAULT_REPLAY_SANDBOX = "./ReplaySandbox"
+	DEFAULT_UTXO_FILE      = "./utxos.json"
+	DEFAULT_ABI_PATH       = "./abi"
+	DEFAULT_EXPORT_HEIGHT  = 0
+	DEFAULT_WALLET_PATH    = "./wallet_data"
+	DEFAULT_DOCTOR_MAX_LAG = 600
 )
 
 var (
@@ -67,6 +71,19 @@ var (
 		Usage: "Block data storage `<path>`",
 		Value: config.DEFAULT_DATA_DIR,
 	}
+	ExecWorkersFlag = cli.UintFlag{
+		Name:  "exec-workers",
+		Usage: "Execute the non-conflicting transactions of a block with `<count>` workers concurrently, 1 for serial execution",
+		Value: config.DEFAULT_EXEC_WORKERS,
+	}
+	StateDiffFlag = cli.BoolFlag{
+		Name:  "state-diff",
+		Usage: "Publish the contract storage changes of each block to websocket subscribers",
+	}
+	StateDiffDirFlag = cli.StringFlag{
+		Name:  "state-diff-dir",
+		Usage: "Write the contract storage changes of each block to files in `<path>`",
+	}
 
 	//Consensus setting
 	EnableConsensusFlag = cli.BoolFlag{
@@ -170,6 +187,26 @@ var (
 		Value: config.DEFAULT_WS_PORT,
 	}
 
+	//Publisher setting
+	PublishEnabledFlag = cli.BoolFlag{
+		Name:  "publish",
+		Usage: "Enable publishing the cross chain events to a NATS server",
+	}
+	PublishNatsFlag = cli.StringFlag{
+		Name:  "publishnats",
+		Usage: "NATS server address `<address>` the events are published to",
+		Value: config.DEFAULT_PUBLISH_NATS_ADDRESS,
+	}
+	PublishSubjectFlag = cli.StringFlag{
+		Name:  "publishsubject",
+		Usage: "Prefix `<subject>` of the NATS subjects the events are published on",
+		Value: config.DEFAULT_PUBLISH_SUBJECT,
+	}
+	PublishCheckpointFlag = cli.StringFlag{
+		Name:  "publishcheckpoint",
+		Usage: "File `<path>` of the last published height, defaults to a file in the data dir",
+	}
+
 	//Restful setting
 	RestfulEnableFlag = cli.BoolFlag{
 		Name:  "rest",
@@ -196,6 +233,14 @@ var (
 		Name:  "account,a",
 		Usage: "Account `<address>` when the Ontology node starts. If not specific, using default account instead",
 	}
+	RemoteSignerFlag = cli.StringFlag{
+		Name:  "remote-signer",
+		Usage: "Sign by the remote signing service at `<url>` instead of the wallet",
+	}
+	RemoteSignerPubKeyFlag = cli.StringFlag{
+		Name:  "remote-signer-pubkey",
+		Usage: "Hex `<public key>` the remote signing service signs for",
+	}
 	AccountDefaultFlag = cli.BoolFlag{
 		Name:  "default,d",
 		Usage: "Default settings to create a new account (equal to '-t ecdsa -b 256 -s SHA256withECDSA')",
@@ -434,12 +479,75 @@ var (
 		Value: "m",
 	}
 
+	//Snapshot setting
+	SnapshotFileFlag = cli.StringFlag{
+		Name:  "snapshot-file",
+		Usage: "Path of state snapshot `<file>`",
+		Value: DEFAULT_SNAPSHOT_FILE,
+	}
+	SnapshotTrustedHashFlag = cli.StringFlag{
+		Name:  "trusted-hash",
+		Usage: "Trusted block `<hash>` of the snapshot height, got from a node or explorer you trust",
+	}
+
+	//Replay setting
+	ReplayHeightFlag = cli.UintFlag{
+		Name:  "height",
+		Usage: "Block height `<number>` of the transaction to replay",
+	}
+	ReplayTxFlag = cli.StringFlag{
+		Name:  "tx",
+		Usage: "Transaction `<hash>` to replay",
+	}
+	ReplaySandboxFlag = cli.StringFlag{
+		Name:  "sandbox-dir",
+		Usage: "Empty `<path>` the state before the transaction is rebuilt in, removed after replay",
+		Value: DEFAULT_REPLAY_SANDBOX,
+	}
+
+	//Utxo setting
+	UtxoChainIdFlag = cli.UintFlag{
+		Name:  "chain-id",
+		Usage: "Side chain id `<number>` of the utxo chain",
+	}
+	UtxoRedeemKeyFlag = cli.StringFlag{
+		Name:  "redeem-key",
+		Usage: "Hex `<key>` of the redeem script, hash160 of the script",
+	}
+	UtxoFileFlag = cli.StringFlag{
+		Name:  "utxo-file",
+		Usage: "Path of the utxo set json `<file>`",
+		Value: DEFAULT_UTXO_FILE,
+	}
+
+	//Doctor setting
+	DoctorChainIdFlag = cli.UintFlag{
+		Name:  "chain",
+		Usage: "Side chain id `<number>` of the chain to check",
+	}
+	DoctorMaxLagFlag = cli.UintFlag{
+		Name:  "max-lag",
+		Usage: "Max poly `<blocks>` since the current header of the chain was synced, 0 not to check",
+		Value: DEFAULT_DOCTOR_MAX_LAG,
+	}
+
 	//PreExecute switcher
 	TxpoolPreExecDisableFlag = cli.BoolFlag{
 		Name:  "disable-tx-pool-pre-exec",
 		Usage: "Disable preExecute in tx pool",
 	}
 
+	TxpoolHeaderSyncWeightFlag = cli.UintFlag{
+		Name:  "tx-pool-header-sync-weight",
+		Usage: "Packing priority `<weight>` of the header-sync transactions in tx pool, ordinary transactions weigh 0",
+		Value: config.DEFAULT_HEADER_SYNC_TX_WEIGHT,
+	}
+	TxpoolImportTransferWeightFlag = cli.UintFlag{
+		Name:  "tx-pool-import-transfer-weight",
+		Usage: "Packing priority `<weight>` of the ImportOuterTransfer transactions in tx pool, ordinary transactions weigh 0",
+		Value: config.DEFAULT_IMPORT_TRANSFER_TX_WEIGHT,
+	}
+
 	//local PreExecute switcher
 	DisableSyncVerifyTxFlag = cli.BoolFlag{
 		Name:  "disable-sync-verify-tx",
@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+)
+
+// TxSigner signs for a public key wherever its private key is kept, so that the operators
+// and the consensus peers don't need hot keys on disk.
+type TxSigner interface {
+	PublicKey() keypair.PublicKey
+	// Sign returns the serialized signature of data
+	Sign(data []byte) ([]byte, error)
+}
+
+// AccountSigner signs with the private key of a wallet account
+type AccountSigner struct {
+	acc *account.Account
+}
+
+func NewAccountSigner(acc *account.Account) *AccountSigner {
+	return &AccountSigner{acc: acc}
+}
+
+func (this *AccountSigner) PublicKey() keypair.PublicKey {
+	return this.acc.PublicKey
+}
+
+func (this *AccountSigner) Sign(data []byte) ([]byte, error) {
+	return Sign(data, this.acc)
+}
+
+type remoteSigReq struct {
+	Qid     string          `json:"qid"`
+	Method  string          `json:"method"`
+	Account string          `json:"account"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type remoteSigRsp struct {
+	ErrorCode int    `json:"error_code"`
+	ErrorInfo string `json:"error_info"`
+	Result    struct {
+		SignedData string `json:"signed_data"`
+	} `json:"result"`
+}
+
+// RemoteSigner asks a signing service for the signatures, the service speaks the sigdata method
+// of sigsvr without any password: HSM backed services and the bridges of hardware wallets
+// keep the key and get the approval of the signing themselves.
+type RemoteSigner struct {
+	url     string
+	pubKey  keypair.PublicKey
+	account string
+	client  *http.Client
+}
+
+func NewRemoteSigner(url string, pubKey keypair.PublicKey) *RemoteSigner {
+	return &RemoteSigner{
+		url:     url,
+		pubKey:  pubKey,
+		account: types.AddressFromPubKey(pubKey).ToBase58(),
+		// hardware wallets wait for the confirmation of their owner
+		client: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+func (this *RemoteSigner) PublicKey() keypair.PublicKey {
+	return this.pubKey
+}
+
+func (this *RemoteSigner) Sign(data []byte) ([]byte, error) {
+	params, err := json.Marshal(map[string]string{"raw_data": hex.EncodeToString(data)})
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal params error:%s", err)
+	}
+	reqData, err := json.Marshal(&remoteSigReq{
+		Qid:     "cli",
+		Method:  "sigdata",
+		Account: this.account,
+		Params:  params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal request error:%s", err)
+	}
+	resp, err := this.client.Post(this.url, "application/json", bytes.NewReader(reqData))
+	if err != nil {
+		return nil, fmt.Errorf("send request to remote signer error:%s", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read remote signer response body error:%s", err)
+	}
+	rsp := &remoteSigRsp{}
+	if err = json.Unmarshal(body, rsp); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal remote signer response:%s error:%s", body, err)
+	}
+	if rsp.ErrorCode != 0 {
+		return nil, fmt.Errorf("remote signer error code:%d info:%s", rsp.ErrorCode, rsp.ErrorInfo)
+	}
+	sigData, err := hex.DecodeString(rsp.Result.SignedData)
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString signed data error:%s", err)
+	}
+	// never trust a signature from the wire
+	if err = signature.Verify(this.pubKey, data, sigData); err != nil {
+		return nil, fmt.Errorf("signature of remote signer not verified:%s", err)
+	}
+	return sigData, nil
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package utils
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSigServer(t *testing.T, signer *account.Account) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &remoteSigReq{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(req))
+		params := make(map[string]string)
+		assert.NoError(t, json.Unmarshal(req.Params, &params))
+		data, err := hex.DecodeString(params["raw_data"])
+		assert.NoError(t, err)
+		sigData, err := Sign(data, signer)
+		assert.NoError(t, err)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"qid":    req.Qid,
+			"method": req.Method,
+			"result": map[string]string{"signed_data": hex.EncodeToString(sigData)},
+		})
+	}))
+}
+
+func TestRemoteSigner(t *testing.T) {
+	acc := account.NewAccount("SHA256withECDSA")
+	svr := newSigServer(t, acc)
+	defer svr.Close()
+
+	signer := NewRemoteSigner(svr.URL, acc.PublicKey)
+	sigData, err := signer.Sign([]byte("HelloWorld"))
+	assert.NoError(t, err)
+	local, err := NewAccountSigner(acc).Sign([]byte("HelloWorld"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, sigData)
+	assert.NotEmpty(t, local)
+
+	// the service signs with another key
+	other := account.NewAccount("SHA256withECDSA")
+	signer = NewRemoteSigner(svr.URL, other.PublicKey)
+	_, err = signer.Sign([]byte("HelloWorld"))
+	assert.Error(t, err)
+}
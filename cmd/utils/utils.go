@@ -190,15 +190,21 @@ func GetTxHeight(txHash string) (uint32, error) {
 }
 
 func SignTransaction(signer *account.Account, tx *types.Transaction) error {
+	return SignTransactionWith(NewAccountSigner(signer), tx)
+}
+
+// SignTransactionWith signs tx by signer whether the key is local or not
+func SignTransactionWith(signer TxSigner, tx *types.Transaction) error {
+	pubKey := signer.PublicKey()
 	txHash := tx.Hash()
-	sigData, err := Sign(txHash.ToArray(), signer)
+	sigData, err := signer.Sign(txHash.ToArray())
 	if err != nil {
 		return fmt.Errorf("sign error:%s", err)
 	}
 	hasSig := false
 	for i, sig := range tx.Sigs {
-		if len(sig.PubKeys) == 1 && pubKeysEqual(sig.PubKeys, []keypair.PublicKey{signer.PublicKey}) {
-			if hasAlreadySig(txHash.ToArray(), signer.PublicKey, sig.SigData) {
+		if len(sig.PubKeys) == 1 && pubKeysEqual(sig.PubKeys, []keypair.PublicKey{pubKey}) {
+			if hasAlreadySig(txHash.ToArray(), pubKey, sig.SigData) {
 				//has already signed
 				return nil
 			}
@@ -209,7 +215,7 @@ func SignTransaction(signer *account.Account, tx *types.Transaction) error {
 	}
 	if !hasSig {
 		tx.Sigs = append(tx.Sigs, types.Sig{
-			PubKeys: []keypair.PublicKey{signer.PublicKey},
+			PubKeys: []keypair.PublicKey{pubKey},
 			M:       1,
 			SigData: [][]byte{sigData},
 		})
@@ -218,13 +224,19 @@ func SignTransaction(signer *account.Account, tx *types.Transaction) error {
 }
 
 func MultiSigTransaction(mutTx *types.Transaction, m uint16, pubKeys []keypair.PublicKey, signer *account.Account) error {
+	return MultiSigTransactionWith(mutTx, m, pubKeys, NewAccountSigner(signer))
+}
+
+// MultiSigTransactionWith adds the signature of signer to the multi-signature of mutTx
+func MultiSigTransactionWith(mutTx *types.Transaction, m uint16, pubKeys []keypair.PublicKey, signer TxSigner) error {
 	pkSize := len(pubKeys)
 	if m == 0 || int(m) > pkSize || pkSize > constants.MULTI_SIG_MAX_PUBKEY_SIZE {
 		return fmt.Errorf("invalid params")
 	}
+	pubKey := signer.PublicKey()
 	validPubKey := false
 	for _, pk := range pubKeys {
-		if keypair.ComparePublicKey(pk, signer.PublicKey) {
+		if keypair.ComparePublicKey(pk, pubKey) {
 			validPubKey = true
 			break
 		}
@@ -238,7 +250,7 @@ func MultiSigTransaction(mutTx *types.Transaction, m uint16, pubKeys []keypair.P
 	}
 
 	txHash := mutTx.Hash()
-	sigData, err := Sign(txHash.ToArray(), signer)
+	sigData, err := signer.Sign(txHash.ToArray())
 	if err != nil {
 		return fmt.Errorf("sign error:%s", err)
 	}
@@ -249,7 +261,7 @@ func MultiSigTransaction(mutTx *types.Transaction, m uint16, pubKeys []keypair.P
 			continue
 		}
 		hasMutilSig = true
-		if hasAlreadySig(txHash.ToArray(), signer.PublicKey, sigs.SigData) {
+		if hasAlreadySig(txHash.ToArray(), pubKey, sigs.SigData) {
 			break
 		}
 		sigs.SigData = append(sigs.SigData, sigData)
@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/client"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
+	sutils "github.com/polynetwork/poly/native/service/utils"
+)
+
+// UtxoSetJson is the json form of the utxos tracked for a redeem of a utxo chain
+type UtxoSetJson struct {
+	ChainID   uint64
+	RedeemKey string
+	Utxos     []*UtxoJson
+}
+
+// UtxoJson is a tracked utxo, TxHash is in the order explorers display it and ScriptClass is
+// derived from ScriptPubkey at export, it's not read at import
+type UtxoJson struct {
+	TxHash       string
+	Index        uint32
+	AtHeight     uint32
+	Value        uint64
+	ScriptPubkey string
+	ScriptClass  string
+}
+
+func NewUtxoSetJson(chainID uint64, redeemKey string, utxos *btc.Utxos) *UtxoSetJson {
+	set := &UtxoSetJson{
+		ChainID:   chainID,
+		RedeemKey: redeemKey,
+		Utxos:     make([]*UtxoJson, 0, len(utxos.Utxos)),
+	}
+	for _, v := range utxos.Utxos {
+		set.Utxos = append(set.Utxos, &UtxoJson{
+			TxHash:       sutils.FormatDisplayHash(v.Op.Hash),
+			Index:        v.Op.Index,
+			AtHeight:     v.AtHeight,
+			Value:        v.Value,
+			ScriptPubkey: hex.EncodeToString(v.ScriptPubkey),
+			ScriptClass:  txscript.GetScriptClass(v.ScriptPubkey).String(),
+		})
+	}
+	return set
+}
+
+func (this *UtxoSetJson) ToUtxos() (*btc.Utxos, error) {
+	utxos := &btc.Utxos{Utxos: make([]*btc.Utxo, 0, len(this.Utxos))}
+	for i, v := range this.Utxos {
+		hash, err := sutils.ParseDisplayHash(v.TxHash, common.UINT256_SIZE)
+		if err != nil {
+			return nil, fmt.Errorf("utxo %d, invalid tx hash:%s", i, err)
+		}
+		script, err := hex.DecodeString(v.ScriptPubkey)
+		if err != nil {
+			return nil, fmt.Errorf("utxo %d, invalid script pubkey:%s", i, err)
+		}
+		utxos.Utxos = append(utxos.Utxos, &btc.Utxo{
+			Op:           &btc.OutPoint{Hash: hash, Index: v.Index},
+			AtHeight:     v.AtHeight,
+			Value:        v.Value,
+			ScriptPubkey: script,
+		})
+	}
+	return utxos, nil
+}
+
+// NewInvokeTransaction returns the unsigned tx of the invocation for the poly chain the rpc server runs
+func NewInvokeTransaction(inv *client.Invocation) (*types.Transaction, error) {
+	networkId, err := GetNetworkId()
+	if err != nil {
+		return nil, fmt.Errorf("GetNetworkId error:%s", err)
+	}
+	return inv.Transaction(config.GetChainIdByNetId(networkId), uint32(time.Now().Unix()))
+}
+
+// GetBtcUtxos returns the utxos tracked for the redeem of the utxo chain
+func GetBtcUtxos(chainID uint64, redeemKey string) (*btc.Utxos, error) {
+	tx, err := NewInvokeTransaction(client.GetBtcUtxos(&cross_chain_manager.BtcUtxosParam{
+		ChainID:   chainID,
+		RedeemKey: redeemKey,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	sink := common.NewZeroCopySink(nil)
+	if err := tx.Serialization(sink); err != nil {
+		return nil, fmt.Errorf("tx serialization error:%s", err)
+	}
+	preResult, err := PrepareSendRawTransaction(hex.EncodeToString(sink.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	if preResult.State == 0 {
+		return nil, fmt.Errorf("prepare execute transaction failed. %v", preResult)
+	}
+	str, ok := preResult.Result.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid result:%v", preResult.Result)
+	}
+	raw, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("hex decode result error:%s", err)
+	}
+	utxos := new(btc.Utxos)
+	if err := utxos.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("deserialize utxos error:%s", err)
+	}
+	return utxos, nil
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUtxoSetJson(t *testing.T) {
+	p2sh, _ := hex.DecodeString("a91487a9652e9b396545598c0fc72cb5a98848bf93d387")
+	hash := make([]byte, 32)
+	hash[0] = 1
+	utxos := &btc.Utxos{Utxos: []*btc.Utxo{
+		{Op: &btc.OutPoint{Hash: hash, Index: 2}, AtHeight: 100, Value: 5000, ScriptPubkey: p2sh},
+	}}
+	set := NewUtxoSetJson(1, "87a9652e9b396545598c0fc72cb5a98848bf93d3", utxos)
+	assert.Equal(t, "0000000000000000000000000000000000000000000000000000000000000001", set.Utxos[0].TxHash)
+	assert.Equal(t, "scripthash", set.Utxos[0].ScriptClass)
+
+	utxos1, err := set.ToUtxos()
+	assert.NoError(t, err)
+	assert.Equal(t, utxos, utxos1)
+
+	set.Utxos[0].TxHash = "01"
+	_, err = set.ToUtxos()
+	assert.Error(t, err)
+}
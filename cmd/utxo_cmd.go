@@ -0,0 +1,166 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	cmdcom "github.com/polynetwork/poly/cmd/common"
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/client"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	"github.com/urfave/cli"
+)
+
+var UtxoCommand = cli.Command{
+	Action:    cli.ShowSubcommandHelp,
+	Name:      "utxo",
+	Usage:     "Export or correct the utxo set tracked for a btc redeem",
+	ArgsUsage: "[arguments...]",
+	Description: `The utxos of the multisig redeem of a utxo chain are tracked by poly to make the withdrawal txs.
+After a rollback of the chain or an accounting bug, export the tracked set, repair it and import it back.
+An import is a correction tx which replaces the tracked set once the consensus peers sign the same set,
+each peer runs the import with its own account.`,
+	Subcommands: []cli.Command{
+		{
+			Action:    exportUtxos,
+			Name:      "export",
+			Usage:     "Export the utxo set tracked for a redeem to json file",
+			ArgsUsage: "[sub-command options]",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.UtxoChainIdFlag,
+				utils.UtxoRedeemKeyFlag,
+				utils.UtxoFileFlag,
+			},
+		},
+		{
+			Action:    importUtxos,
+			Name:      "import",
+			Usage:     "Sign the correction tx replacing the utxo set tracked for a redeem with json file",
+			ArgsUsage: "[sub-command options]",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.UtxoFileFlag,
+				utils.WalletFileFlag,
+				utils.AccountAddressFlag,
+				utils.RemoteSignerFlag,
+				utils.RemoteSignerPubKeyFlag,
+				utils.PrepareExecTransactionFlag,
+			},
+		},
+	},
+}
+
+func exportUtxos(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	chainID := uint64(ctx.Uint(utils.GetFlagName(utils.UtxoChainIdFlag)))
+	if chainID == 0 {
+		PrintErrorMsg("Missing %s argument.", utils.UtxoChainIdFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	redeemKey := ctx.String(utils.GetFlagName(utils.UtxoRedeemKeyFlag))
+	if redeemKey == "" {
+		PrintErrorMsg("Missing %s argument.", utils.UtxoRedeemKeyFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	utxos, err := utils.GetBtcUtxos(chainID, redeemKey)
+	if err != nil {
+		return fmt.Errorf("GetBtcUtxos error:%s", err)
+	}
+	data, err := json.MarshalIndent(utils.NewUtxoSetJson(chainID, redeemKey, utxos), "", "\t")
+	if err != nil {
+		return fmt.Errorf("json.Marshal error:%s", err)
+	}
+	utxoFile := ctx.String(utils.GetFlagName(utils.UtxoFileFlag))
+	if err := ioutil.WriteFile(utxoFile, data, 0664); err != nil {
+		return fmt.Errorf("write file:%s error:%s", utxoFile, err)
+	}
+	var value uint64
+	for _, v := range utxos.Utxos {
+		value += v.Value
+	}
+	PrintInfoMsg("Export utxos successfully.")
+	PrintInfoMsg("Utxos:%d", len(utxos.Utxos))
+	PrintInfoMsg("Value:%d", value)
+	PrintInfoMsg("Export file:%s", utxoFile)
+	return nil
+}
+
+func importUtxos(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	utxoFile := ctx.String(utils.GetFlagName(utils.UtxoFileFlag))
+	set := new(utils.UtxoSetJson)
+	if err := utils.GetJsonObjectFromFile(utxoFile, set); err != nil {
+		return fmt.Errorf("read utxo file:%s error:%s", utxoFile, err)
+	}
+	utxos, err := set.ToUtxos()
+	if err != nil {
+		return fmt.Errorf("invalid utxo file:%s error:%s", utxoFile, err)
+	}
+	signer, err := cmdcom.GetSigner(ctx)
+	if err != nil {
+		return fmt.Errorf("GetSigner error:%s", err)
+	}
+	tx, err := utils.NewInvokeTransaction(client.CorrectBtcUtxos(&cross_chain_manager.CorrectBtcUtxosParam{
+		ChainID:   set.ChainID,
+		RedeemKey: set.RedeemKey,
+		Utxos:     utxos,
+		Address:   types.AddressFromPubKey(signer.PublicKey()),
+	}))
+	if err != nil {
+		return err
+	}
+	if err := utils.SignTransactionWith(signer, tx); err != nil {
+		return fmt.Errorf("SignTransaction error:%s", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	if err := tx.Serialization(sink); err != nil {
+		return fmt.Errorf("tx serialization error:%s", err)
+	}
+	rawTx := hex.EncodeToString(sink.Bytes())
+
+	if ctx.IsSet(utils.GetFlagName(utils.PrepareExecTransactionFlag)) {
+		preResult, err := utils.PrepareSendRawTransaction(rawTx)
+		if err != nil {
+			return err
+		}
+		if preResult.State == 0 {
+			return fmt.Errorf("prepare execute transaction failed. %v", preResult)
+		}
+		PrintInfoMsg("Prepare execute transaction success.")
+		PrintInfoMsg("Result:%v", preResult.Result)
+		return nil
+	}
+	txHash, err := utils.SendRawTransactionData(rawTx)
+	if err != nil {
+		return err
+	}
+	PrintInfoMsg("Send correction of %d utxos success.", len(utxos.Utxos))
+	PrintInfoMsg("  TxHash:%s", txHash)
+	PrintInfoMsg("\nTip:")
+	PrintInfoMsg("  The utxo set is replaced once enough consensus peers send the same correction.")
+	return nil
+}
@@ -54,6 +54,9 @@ const (
 	DEFAULT_RPC_LOCAL_PORT                  = uint(20337)
 	DEFAULT_REST_PORT                       = uint(20334)
 	DEFAULT_WS_PORT                         = uint(20335)
+	DEFAULT_PUBLISH_NATS_ADDRESS            = "127.0.0.1:4222"
+	DEFAULT_PUBLISH_SUBJECT                 = "poly.crosschain"
+	DEFAULT_PUBLISH_CHECKPOINT              = "publisher.checkpoint"
 	DEFAULT_REST_MAX_CONN                   = uint(1024)
 	DEFAULT_MAX_CONN_IN_BOUND               = uint(1024)
 	DEFAULT_MAX_CONN_OUT_BOUND              = uint(1024)
@@ -61,8 +64,11 @@ const (
 	DEFAULT_HTTP_INFO_PORT                  = uint(0)
 	DEFAULT_MAX_TX_IN_BLOCK                 = 60000
 	DEFAULT_MAX_SYNC_HEADER                 = 500
+	DEFAULT_HEADER_SYNC_TX_WEIGHT           = 2
+	DEFAULT_IMPORT_TRANSFER_TX_WEIGHT       = 1
 	DEFAULT_ENABLE_CONSENSUS                = true
 	DEFAULT_ENABLE_EVENT_LOG                = true
+	DEFAULT_EXEC_WORKERS                    = uint(4)
 	DEFAULT_CLI_RPC_PORT                    = uint(20000)
 	DEFUALT_CLI_RPC_ADDRESS                 = "127.0.0.1"
 	DEFAULT_GAS_LIMIT                       = 20000
@@ -106,6 +112,26 @@ var EXTRA_INFO_HEIGHT = map[uint32]uint32{
 
 var EXTRA_INFO_HEIGHT_FORK_CHECK bool
 
+var HEADER_COMMITMENT_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.HEADER_COMMITMENT_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.HEADER_COMMITMENT_HEIGHT_TESTNET,
+}
+
+var CROSS_STATES_ACC_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.CROSS_STATES_ACC_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.CROSS_STATES_ACC_HEIGHT_TESTNET,
+}
+
+var RECEIPTS_ROOT_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.RECEIPTS_ROOT_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.RECEIPTS_ROOT_HEIGHT_TESTNET,
+}
+
+var FAILURE_HOOK_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.FAILURE_HOOK_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.FAILURE_HOOK_HEIGHT_TESTNET,
+}
+
 func GetNetworkMagic(id uint32) uint32 {
 	nid, ok := NETWORK_MAGIC[id]
 	if ok {
@@ -118,6 +144,30 @@ func GetExtraInfoHeight(id uint32) uint32 {
 	return EXTRA_INFO_HEIGHT[id]
 }
 
+// GetHeaderCommitmentHeight returns the height from which the synced side chain
+// headers are committed into the cross states
+func GetHeaderCommitmentHeight(id uint32) uint32 {
+	return HEADER_COMMITMENT_HEIGHT[id]
+}
+
+// GetCrossStatesAccHeight returns the height from which the cross states are appended
+// to the accumulator and the block headers carry its root
+func GetCrossStatesAccHeight(id uint32) uint32 {
+	return CROSS_STATES_ACC_HEIGHT[id]
+}
+
+// GetReceiptsRootHeight returns the height from which the hashes of the transaction receipts are
+// committed by the root of their tree appended to the cross states of the block
+func GetReceiptsRootHeight(id uint32) uint32 {
+	return RECEIPTS_ROOT_HEIGHT[id]
+}
+
+// GetFailureHookHeight returns the height from which the failure hooks of the native contracts run for
+// the failed transactions, and their writes are committed though the transaction fails
+func GetFailureHookHeight(id uint32) uint32 {
+	return FAILURE_HOOK_HEIGHT[id]
+}
+
 func GetNetworkName(id uint32) string {
 	name, ok := NETWORK_NAME[id]
 	if ok {
@@ -242,6 +292,7 @@ type GenesisConfig struct {
 	VBFT          *VBFTConfig
 	DBFT          *DBFTConfig
 	SOLO          *SOLOConfig
+	Bootstrap     *BootstrapConfig // applied at block 0 when set, test networks only
 }
 
 func NewGenesisConfig() *GenesisConfig {
@@ -265,6 +316,7 @@ type VBFTConfig struct {
 	VrfValue             string          `json:"vrf_value"`
 	VrfProof             string          `json:"vrf_proof"`
 	Peers                []*VBFTPeerInfo `json:"peers"`
+	DevChain             bool            `json:"dev_chain"` // allow node manager to re-run initialization, test networks only
 }
 
 func (self *VBFTConfig) Serialization(sink *common.ZeroCopySink) error {
@@ -280,6 +332,10 @@ func (self *VBFTConfig) Serialization(sink *common.ZeroCopySink) error {
 			return err
 		}
 	}
+	// only written when set so the genesis of existing networks is unchanged
+	if self.DevChain {
+		sink.WriteBool(self.DevChain)
+	}
 	return nil
 }
 
@@ -321,6 +377,13 @@ func (this *VBFTConfig) Deserialization(source *common.ZeroCopySource) error {
 		}
 		peers = append(peers, peer)
 	}
+	devChain := false
+	if source.Len() > 0 {
+		devChain, eof = source.NextBool()
+		if eof {
+			return fmt.Errorf("serialization.ReadBool, deserialize devChain error!")
+		}
+	}
 	this.BlockMsgDelay = blockMsgDelay
 	this.HashMsgDelay = hashMsgDelay
 	this.PeerHandshakeTimeout = peerHandshakeTimeout
@@ -328,6 +391,7 @@ func (this *VBFTConfig) Deserialization(source *common.ZeroCopySource) error {
 	this.VrfValue = vrfValue
 	this.VrfProof = vrfProof
 	this.Peers = peers
+	this.DevChain = devChain
 	return nil
 }
 
@@ -369,6 +433,134 @@ func (this *VBFTPeerInfo) Deserialization(source *common.ZeroCopySource) error {
 	return nil
 }
 
+//
+// Bootstrap genesis config, the relayers and side chains ready at block 0 without any governance ceremony
+//
+type BootstrapConfig struct {
+	Relayers   []string              `json:"relayers"`
+	SideChains []*BootstrapSideChain `json:"side_chains"`
+}
+
+func (this *BootstrapConfig) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteVarUint(uint64(len(this.Relayers)))
+	for _, relayer := range this.Relayers {
+		address, err := common.AddressFromBase58(relayer)
+		if err != nil {
+			return fmt.Errorf("serialize BootstrapConfig relayer %s error: %v", relayer, err)
+		}
+		address.Serialization(sink)
+	}
+	sink.WriteVarUint(uint64(len(this.SideChains)))
+	for _, sideChain := range this.SideChains {
+		if err := sideChain.Serialization(sink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *BootstrapConfig) Deserialization(source *common.ZeroCopySource) error {
+	length, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("serialization.ReadVarUint, deserialize relayer length error!")
+	}
+	relayers := make([]string, 0)
+	for i := 0; uint64(i) < length; i++ {
+		address := new(common.Address)
+		if err := address.Deserialization(source); err != nil {
+			return fmt.Errorf("address.Deserialize, deserialize relayer error!")
+		}
+		relayers = append(relayers, address.ToBase58())
+	}
+	length, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("serialization.ReadVarUint, deserialize side chain length error!")
+	}
+	sideChains := make([]*BootstrapSideChain, 0)
+	for i := 0; uint64(i) < length; i++ {
+		sideChain := new(BootstrapSideChain)
+		if err := sideChain.Deserialization(source); err != nil {
+			return fmt.Errorf("deserialize side chain error, error:%s", err)
+		}
+		sideChains = append(sideChains, sideChain)
+	}
+	this.Relayers = relayers
+	this.SideChains = sideChains
+	return nil
+}
+
+type BootstrapSideChain struct {
+	ChainId       uint64 `json:"chain_id"`
+	Router        uint64 `json:"router"`
+	Name          string `json:"name"`
+	BlocksToWait  uint64 `json:"blocks_to_wait"`
+	CCMCAddress   string `json:"ccmc_address"`   // hex
+	ExtraInfo     string `json:"extra_info"`     // hex
+	GenesisHeader string `json:"genesis_header"` // hex, the header sync of the chain is left uninitialized when empty
+}
+
+func (this *BootstrapSideChain) Serialization(sink *common.ZeroCopySink) error {
+	ccmcAddress, err := common.HexToBytes(this.CCMCAddress)
+	if err != nil {
+		return fmt.Errorf("serialize BootstrapSideChain %d ccmc address error: %v", this.ChainId, err)
+	}
+	extraInfo, err := common.HexToBytes(this.ExtraInfo)
+	if err != nil {
+		return fmt.Errorf("serialize BootstrapSideChain %d extra info error: %v", this.ChainId, err)
+	}
+	genesisHeader, err := common.HexToBytes(this.GenesisHeader)
+	if err != nil {
+		return fmt.Errorf("serialize BootstrapSideChain %d genesis header error: %v", this.ChainId, err)
+	}
+	sink.WriteUint64(this.ChainId)
+	sink.WriteUint64(this.Router)
+	sink.WriteString(this.Name)
+	sink.WriteUint64(this.BlocksToWait)
+	sink.WriteVarBytes(ccmcAddress)
+	sink.WriteVarBytes(extraInfo)
+	sink.WriteVarBytes(genesisHeader)
+	return nil
+}
+
+func (this *BootstrapSideChain) Deserialization(source *common.ZeroCopySource) error {
+	chainId, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("serialization.ReadUint64, deserialize chainId error!")
+	}
+	router, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("serialization.ReadUint64, deserialize router error!")
+	}
+	name, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("serialization.ReadString, deserialize name error!")
+	}
+	blocksToWait, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("serialization.ReadUint64, deserialize blocksToWait error!")
+	}
+	ccmcAddress, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("serialization.ReadVarBytes, deserialize ccmcAddress error!")
+	}
+	extraInfo, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("serialization.ReadVarBytes, deserialize extraInfo error!")
+	}
+	genesisHeader, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("serialization.ReadVarBytes, deserialize genesisHeader error!")
+	}
+	this.ChainId = chainId
+	this.Router = router
+	this.Name = name
+	this.BlocksToWait = blocksToWait
+	this.CCMCAddress = common.ToHexString(ccmcAddress)
+	this.ExtraInfo = common.ToHexString(extraInfo)
+	this.GenesisHeader = common.ToHexString(genesisHeader)
+	return nil
+}
+
 type DBFTConfig struct {
 	GenBlockTime uint
 	Bookkeepers  []string
@@ -387,6 +579,9 @@ type CommonConfig struct {
 	GasLimit       uint64
 	GasPrice       uint64
 	DataDir        string
+	ExecWorkers    uint   //Count of transactions of a block executed concurrently, not more than 1 means serially
+	StateDiff      bool   //Publish the contract storage changes of each block to websocket subscribers
+	StateDiffDir   string //Dir the contract storage changes of each block are written to, empty means not written
 }
 
 type ConsensusConfig struct {
@@ -394,6 +589,14 @@ type ConsensusConfig struct {
 	MaxTxInBlock    uint
 }
 
+// TxPoolConfig holds the weights by which the tx pool orders the transactions for a block,
+// the header-sync and ImportOuterTransfer transactions are packed before the ordinary ones
+// whose weight is zero.
+type TxPoolConfig struct {
+	HeaderSyncWeight     uint
+	ImportTransferWeight uint
+}
+
 type P2PRsvConfig struct {
 	ReservedPeers []string `json:"reserved"`
 	MaskPeers     []string `json:"mask"`
@@ -440,14 +643,25 @@ type WebSocketConfig struct {
 	HttpKeyPath  string
 }
 
+// PublisherConfig configures the push of the cross chain events to a NATS
+// server, the checkpoint defaults to a file in the data dir
+type PublisherConfig struct {
+	EnablePublisher bool
+	NatsAddress     string
+	Subject         string
+	CheckpointPath  string
+}
+
 type OntologyConfig struct {
 	Genesis   *GenesisConfig
 	Common    *CommonConfig
 	Consensus *ConsensusConfig
+	TxPool    *TxPoolConfig
 	P2PNode   *P2PNodeConfig
 	Rpc       *RpcConfig
 	Restful   *RestfulConfig
 	Ws        *WebSocketConfig
+	Publisher *PublisherConfig
 }
 
 func NewOntologyConfig() *OntologyConfig {
@@ -459,11 +673,16 @@ func NewOntologyConfig() *OntologyConfig {
 			SystemFee:      make(map[string]int64),
 			GasLimit:       DEFAULT_GAS_LIMIT,
 			DataDir:        DEFAULT_DATA_DIR,
+			ExecWorkers:    DEFAULT_EXEC_WORKERS,
 		},
 		Consensus: &ConsensusConfig{
 			EnableConsensus: true,
 			MaxTxInBlock:    DEFAULT_MAX_TX_IN_BLOCK,
 		},
+		TxPool: &TxPoolConfig{
+			HeaderSyncWeight:     DEFAULT_HEADER_SYNC_TX_WEIGHT,
+			ImportTransferWeight: DEFAULT_IMPORT_TRANSFER_TX_WEIGHT,
+		},
 		P2PNode: &P2PNodeConfig{
 			ReservedCfg:               &P2PRsvConfig{},
 			ReservedPeersOnly:         false,
@@ -496,6 +715,11 @@ func NewOntologyConfig() *OntologyConfig {
 			EnableHttpWs: true,
 			HttpWsPort:   DEFAULT_WS_PORT,
 		},
+		Publisher: &PublisherConfig{
+			EnablePublisher: false,
+			NatsAddress:     DEFAULT_PUBLISH_NATS_ADDRESS,
+			Subject:         DEFAULT_PUBLISH_SUBJECT,
+		},
 	}
 }
 
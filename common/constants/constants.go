@@ -19,6 +19,7 @@
 package constants
 
 import (
+	"math"
 	"time"
 )
 
@@ -43,3 +44,19 @@ const (
 // extra info change height
 const EXTRA_INFO_HEIGHT_MAINNET = 2917744
 const EXTRA_INFO_HEIGHT_TESTNET = 1664798
+
+// header commitment activation height, not scheduled yet
+const HEADER_COMMITMENT_HEIGHT_MAINNET = math.MaxUint32
+const HEADER_COMMITMENT_HEIGHT_TESTNET = math.MaxUint32
+
+// cross states accumulator activation height, not scheduled yet
+const CROSS_STATES_ACC_HEIGHT_MAINNET = math.MaxUint32
+const CROSS_STATES_ACC_HEIGHT_TESTNET = math.MaxUint32
+
+// receipts root activation height, not scheduled yet
+const RECEIPTS_ROOT_HEIGHT_MAINNET = math.MaxUint32
+const RECEIPTS_ROOT_HEIGHT_TESTNET = math.MaxUint32
+
+// failure hook activation height, not scheduled yet
+const FAILURE_HOOK_HEIGHT_MAINNET = math.MaxUint32
+const FAILURE_HOOK_HEIGHT_TESTNET = math.MaxUint32
@@ -777,3 +777,9 @@ func (pool *BlockPool) getCrossStatesRoot(blkNum uint32) (common.Uint256, error)
 	defer pool.lock.RUnlock()
 	return pool.chainStore.getCrossStateRoot(blkNum)
 }
+
+func (pool *BlockPool) getCrossStatesAccRoot(blkNum uint32) (common.Uint256, error) {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+	return pool.chainStore.getCrossStatesAccRoot(blkNum)
+}
@@ -60,12 +60,16 @@ func OpenBlockStore(db *ledger.Ledger, serverPid *actor.PID) (*ChainStore, error
 	if err != nil {
 		return nil, fmt.Errorf("GetCrossStatesRoot blockNum:%d, error :%s", chainstore.chainedBlockNum, err)
 	}
+	crossStatesAccRoot, err := db.GetCrossStatesAccRoot(chainstore.chainedBlockNum)
+	if err != nil {
+		return nil, fmt.Errorf("GetCrossStatesAccRoot blockNum:%d, error :%s", chainstore.chainedBlockNum, err)
+	}
 	writeSet := overlaydb.NewMemDB(1, 1)
 	block, err := chainstore.getBlock(chainstore.chainedBlockNum)
 	if err != nil {
 		return nil, err
 	}
-	chainstore.pendingBlocks[chainstore.chainedBlockNum] = &PendingBlock{block: block, execResult: &store.ExecuteResult{WriteSet: writeSet, MerkleRoot: merkleRoot, CrossStatesRoot: crossStatesRoot}}
+	chainstore.pendingBlocks[chainstore.chainedBlockNum] = &PendingBlock{block: block, execResult: &store.ExecuteResult{WriteSet: writeSet, MerkleRoot: merkleRoot, CrossStatesRoot: crossStatesRoot, CrossStatesAccRoot: crossStatesAccRoot}}
 	return chainstore, nil
 }
 
@@ -103,6 +107,17 @@ func (self *ChainStore) getCrossStateRoot(blkNum uint32) (common.Uint256, error)
 	}
 }
 
+func (self *ChainStore) getCrossStatesAccRoot(blkNum uint32) (common.Uint256, error) {
+	if blk, present := self.pendingBlocks[blkNum]; blk != nil && present {
+		return blk.execResult.CrossStatesAccRoot, nil
+	}
+	crossStatesAccRoot, err := self.db.GetCrossStatesAccRoot(blkNum)
+	if err != nil {
+		return common.Uint256{}, fmt.Errorf("GetCrossStatesAccRoot blockNum:%d, error :%s", blkNum, err)
+	}
+	return crossStatesAccRoot, nil
+}
+
 func (self *ChainStore) getExecWriteSet(blkNum uint32) *overlaydb.MemDB {
 	if blk, present := self.pendingBlocks[blkNum]; blk != nil && present {
 		return blk.execResult.WriteSet
@@ -197,9 +197,18 @@ func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, tx
 	if err != nil {
 		return nil, fmt.Errorf("failed to GetCrossStatesRoot: %s,blkNum:%d", err, (blkNum - 1))
 	}
+	version := uint32(0)
+	crossStatesAccRoot := common.UINT256_EMPTY
+	if blkNum >= config.GetCrossStatesAccHeight(config.DefConfig.P2PNode.NetworkId) {
+		version = types.HEADER_VERSION_CROSS_STATES_ACC
+		crossStatesAccRoot, err = self.blockPool.getCrossStatesAccRoot(blkNum - 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to GetCrossStatesAccRoot: %s,blkNum:%d", err, (blkNum - 1))
+		}
+	}
 
 	blkHeader := &types.Header{
-		Version:          types.CURR_HEADER_VERSION,
+		Version:          version,
 		ChainID:          config.GetChainIdByNetId(config.DefConfig.P2PNode.NetworkId),
 		PrevBlockHash:    prevBlkHash,
 		TransactionsRoot: txRoot,
@@ -210,6 +219,8 @@ func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, tx
 		NextBookkeeper:   nextBookkeeper,
 		ConsensusData:    common.GetNonce(),
 		ConsensusPayload: consensusPayload,
+
+		CrossStatesAccRoot: crossStatesAccRoot,
 	}
 
 	blk := &types.Block{
@@ -31,6 +31,7 @@ import (
 	"github.com/ontio/ontology-eventbus/actor"
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	actorTypes "github.com/polynetwork/poly/consensus/actor"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
@@ -1074,6 +1075,25 @@ func (self *Server) processProposalMsg(msg *blockProposalMsg) {
 		log.Errorf("BlockPrposalMessage check crossStateRoot blocknum:%d,msg crossStateRoot:%s,self crossStateRoot:%s", msg.GetBlockNum(), msgCrossStateRoot.ToHexString(), crossStateRoot.ToHexString())
 		return
 	}
+	if msgBlkNum >= config.GetCrossStatesAccHeight(config.DefConfig.P2PNode.NetworkId) {
+		if msg.Block.Block.Header.Version < types.HEADER_VERSION_CROSS_STATES_ACC {
+			log.Errorf("BlockPrposalMessage check header version blocknum:%d,msg version:%d", msg.GetBlockNum(), msg.Block.Block.Header.Version)
+			return
+		}
+		crossStatesAccRoot, err := self.blockPool.getCrossStatesAccRoot(msgBlkNum - 1)
+		if err != nil {
+			log.Errorf("failed to getCrossStatesAccRoot: %s,blkNum:%d", err, (msgBlkNum - 1))
+			return
+		}
+		msgCrossStatesAccRoot := msg.Block.getPrevBlockCrossStatesAccRoot()
+		if crossStatesAccRoot != msgCrossStatesAccRoot {
+			log.Errorf("BlockPrposalMessage check crossStatesAccRoot blocknum:%d,msg crossStatesAccRoot:%s,self crossStatesAccRoot:%s", msg.GetBlockNum(), msgCrossStatesAccRoot.ToHexString(), crossStatesAccRoot.ToHexString())
+			return
+		}
+	} else if msg.Block.Block.Header.Version >= types.HEADER_VERSION_CROSS_STATES_ACC {
+		log.Errorf("BlockPrposalMessage check header version blocknum:%d,msg version:%d", msg.GetBlockNum(), msg.Block.Block.Header.Version)
+		return
+	}
 
 	cfg := vconfig.ChainConfig{}
 	if blk.getNewChainConfig() != nil {
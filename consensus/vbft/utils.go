@@ -164,7 +164,8 @@ func GetPeersConfig(memdb *overlaydb.MemDB) ([]*config.VBFTPeerInfo, error) {
 		return nil, err
 	}
 	var peerstakes []*config.VBFTPeerInfo
-	for _, id := range peerMap.PeerPoolMap {
+	for _, pubkey := range peerMap.SortedPubkeys() {
+		id := peerMap.PeerPoolMap[pubkey]
 		if id.Status == node_manager.CandidateStatus || id.Status == node_manager.ConsensusStatus {
 			config := &config.VBFTPeerInfo{
 				Index:      uint32(id.Index),
@@ -37,7 +37,10 @@ const (
 	BlockVersion uint32 = 0
 	GenesisNonce uint64 = 2083236893
 
-	INIT_CONFIG = "initConfig"
+	INIT_CONFIG         = "initConfig"
+	INIT_SIDE_CHAIN     = "initSideChain"
+	INIT_GENESIS_HEADER = "initGenesisHeader"
+	INIT_RELAYER        = "initRelayer"
 )
 
 var GenBlockTime = (config.DEFAULT_GEN_BLOCK_TIME * time.Second)
@@ -62,9 +65,9 @@ func BuildGenesisBlock(defaultBookkeeper []keypair.PublicKey, genesisConfig *con
 		return nil, fmt.Errorf("consensus genesis init failed: %s", err)
 	}
 
-	//blockdata
+	//blockdata, the genesis header keeps the first version so that its hash never changes
 	genesisHeader := &types.Header{
-		Version:          types.CURR_HEADER_VERSION,
+		Version:          0,
 		ChainID:          config.GetChainIdByNetId(config.DefConfig.P2PNode.NetworkId),
 		PrevBlockHash:    common.Uint256{},
 		TransactionsRoot: common.Uint256{},
@@ -84,6 +87,21 @@ func BuildGenesisBlock(defaultBookkeeper []keypair.PublicKey, genesisConfig *con
 			nodeManagerConfig,
 		},
 	}
+	// the bootstrap transactions are only added when configured so the genesis of existing networks is unchanged
+	if genesisConfig.Bootstrap != nil {
+		if err := checkBootstrapChainIDs(genesisConfig.Bootstrap); err != nil {
+			return nil, fmt.Errorf("bootstrap genesis init failed: %s", err)
+		}
+		bootstrap := common.NewZeroCopySink(nil)
+		if err := genesisConfig.Bootstrap.Serialization(bootstrap); err != nil {
+			return nil, fmt.Errorf("bootstrap genesis init failed: %s", err)
+		}
+		genesisBlock.Transactions = append(genesisBlock.Transactions,
+			NewInitBootstrapTransaction(utils.SideChainManagerContractAddress, INIT_SIDE_CHAIN, bootstrap.Bytes(), 1),
+			NewInitBootstrapTransaction(utils.HeaderSyncContractAddress, INIT_GENESIS_HEADER, bootstrap.Bytes(), 2),
+			NewInitBootstrapTransaction(utils.RelayerManagerContractAddress, INIT_RELAYER, bootstrap.Bytes(), 3),
+		)
+	}
 	genesisBlock.RebuildMerkleRoot()
 	return genesisBlock, nil
 }
@@ -128,3 +146,29 @@ func NewInitNodeManagerTransaction(
 
 	return NewInvokeTransaction(invokeCode.Bytes(), 0), nil
 }
+
+// checkBootstrapChainIDs rejects the bootstrap side chains reusing a chain id, either among themselves or
+// one assigned to a chain of another router
+func checkBootstrapChainIDs(bootstrap *config.BootstrapConfig) error {
+	chainIDs := make(map[uint64]bool)
+	for _, v := range bootstrap.SideChains {
+		if chainIDs[v.ChainId] {
+			return fmt.Errorf("duplicate side chain id %d", v.ChainId)
+		}
+		chainIDs[v.ChainId] = true
+		if err := utils.CheckChainID(v.ChainId, v.Router); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewInitBootstrapTransaction returns the genesis transaction applying the bootstrap config to a native contract
+func NewInitBootstrapTransaction(contract common.Address, method string, paramBytes []byte, nonce uint32) *types.Transaction {
+	contractInvokeParam := &states.ContractInvokeParam{Address: contract,
+		Method: method, Args: paramBytes}
+	invokeCode := new(common.ZeroCopySink)
+	contractInvokeParam.Serialization(invokeCode)
+
+	return NewInvokeTransaction(invokeCode.Bytes(), nonce)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"testing"
@@ -41,3 +42,23 @@ func TestGenesisBlockInit(t *testing.T) {
 	assert.NotNil(t, block)
 	assert.NotEqual(t, block.Header.TransactionsRoot, common.UINT256_EMPTY)
 }
+
+func TestGenesisBootstrapChainIDs(t *testing.T) {
+	_, pub, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	conf := &config.GenesisConfig{Bootstrap: &config.BootstrapConfig{
+		SideChains: []*config.BootstrapSideChain{
+			{ChainId: utils.ETH_CHAIN_ID, Router: utils.ETH_ROUTER, Name: "ethereum", BlocksToWait: 1},
+			{ChainId: 100, Router: utils.BSC_ROUTER, Name: "devnet", BlocksToWait: 1},
+		},
+	}}
+	_, err := BuildGenesisBlock([]keypair.PublicKey{pub}, conf)
+	assert.Nil(t, err)
+
+	conf.Bootstrap.SideChains[1].ChainId = utils.ETH_CHAIN_ID
+	_, err = BuildGenesisBlock([]keypair.PublicKey{pub}, conf)
+	assert.NotNil(t, err)
+
+	conf.Bootstrap.SideChains[1].ChainId = utils.HECO_CHAIN_ID
+	_, err = BuildGenesisBlock([]keypair.PublicKey{pub}, conf)
+	assert.NotNil(t, err)
+}
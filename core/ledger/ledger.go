@@ -88,6 +88,10 @@ func (self *Ledger) GetCrossStateRoot(height uint32) (common.Uint256, error) {
 	return self.ldgStore.GetCrossStateRoot(height)
 }
 
+func (self *Ledger) GetCrossStatesAccRoot(height uint32) (common.Uint256, error) {
+	return self.ldgStore.GetCrossStatesAccRoot(height)
+}
+
 func (self *Ledger) GetBlockRootWithPreBlockHashes(startHeight uint32, txRoots []common.Uint256) common.Uint256 {
 	return self.ldgStore.GetBlockRootWithPreBlockHashes(startHeight, txRoots)
 }
@@ -177,6 +181,14 @@ func (self *Ledger) GetCrossStatesProof(height uint32, key []byte) ([]byte, erro
 	return self.ldgStore.GetCrossStatesProof(height, key)
 }
 
+func (self *Ledger) GetCrossStatesAccProof(height uint32, key []byte, rootHeight uint32) ([]byte, error) {
+	return self.ldgStore.GetCrossStatesAccProof(height, key, rootHeight)
+}
+
+func (self *Ledger) GetReceiptProof(txHash common.Uint256) (uint32, []byte, []byte, error) {
+	return self.ldgStore.GetReceiptProof(txHash)
+}
+
 func (self *Ledger) PreExecuteContract(tx *types.Transaction) (*cstate.PreExecResult, error) {
 	return self.ldgStore.PreExecuteContract(tx)
 }
@@ -189,6 +201,14 @@ func (self *Ledger) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify
 	return self.ldgStore.GetEventNotifyByBlock(height)
 }
 
+func (self *Ledger) GetEventNotifyByChain(chainID uint64, height uint32) ([]*event.ExecuteNotify, error) {
+	return self.ldgStore.GetEventNotifyByChain(chainID, height)
+}
+
+func (self *Ledger) GetChainEventHeights(chainID uint64, start, end uint32, limit int) ([]uint32, error) {
+	return self.ldgStore.GetChainEventHeights(chainID, start, end, limit)
+}
+
 func (self *Ledger) Close() error {
 	return self.ldgStore.Close()
 }
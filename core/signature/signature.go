@@ -19,7 +19,12 @@
 package signature
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"errors"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ontio/ontology-crypto/ec"
 	"github.com/ontio/ontology-crypto/keypair"
 	s "github.com/ontio/ontology-crypto/signature"
 )
@@ -82,3 +87,41 @@ func VerifyMultiSignature(data []byte, keys []keypair.PublicKey, m int, sigs [][
 
 	return nil
 }
+
+// ConvertToEthCompatible converts the secp256k1 signature of data signed by pubKey into
+// the 65 bytes r || s || v format, so that evm chains can recover the signer with ecrecover
+func ConvertToEthCompatible(pubKey keypair.PublicKey, data, signature []byte) ([]byte, error) {
+	sigObj, err := s.Deserialize(signature)
+	if err != nil {
+		return nil, errors.New("invalid signature data: " + err.Error())
+	}
+	if sigObj.Scheme != s.SHA256withECDSA {
+		return nil, errors.New("only SHA256withECDSA signature can be converted")
+	}
+	dsa, ok := sigObj.Value.(*s.DSASignature)
+	if !ok {
+		return nil, errors.New("invalid ecdsa signature")
+	}
+	pub, ok := pubKey.(*ec.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid ecdsa public key")
+	}
+	r, sv := dsa.R.Bytes(), dsa.S.Bytes()
+	if len(r) > 32 || len(sv) > 32 {
+		return nil, errors.New("invalid signature length")
+	}
+	sig := make([]byte, 65)
+	copy(sig[32-len(r):32], r)
+	copy(sig[64-len(sv):64], sv)
+
+	digest := sha256.Sum256(data)
+	expect := crypto.FromECDSAPub(pub.PublicKey)
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		recovered, err := crypto.Ecrecover(digest[:], sig)
+		if err == nil && bytes.Equal(recovered, expect) {
+			return sig, nil
+		}
+	}
+	return nil, errors.New("signature is not recoverable on secp256k1")
+}
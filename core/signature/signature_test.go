@@ -18,10 +18,15 @@
 package signature
 
 import (
+	"crypto/sha256"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ontio/ontology-crypto/ec"
 	"github.com/ontio/ontology-crypto/keypair"
+	s "github.com/ontio/ontology-crypto/signature"
 	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/core/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -59,3 +64,28 @@ func TestVerifyMultiSignature(t *testing.T) {
 	assert.Nil(t, err)
 
 }
+
+func TestConvertToEthCompatible(t *testing.T) {
+	pri, pub, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.SECP256K1)
+	assert.NoError(t, err)
+	acc := &account.Account{
+		PrivateKey: pri,
+		PublicKey:  pub,
+		Address:    types.AddressFromPubKey(pub),
+		SigScheme:  s.SHA256withECDSA,
+	}
+	data := []byte{1, 2, 3}
+	sig, err := Sign(acc, data)
+	assert.NoError(t, err)
+
+	ethSig, err := ConvertToEthCompatible(pub, data, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, 65, len(ethSig))
+	digest := sha256.Sum256(data)
+	recovered, err := crypto.SigToPub(digest[:], ethSig)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(*recovered), crypto.PubkeyToAddress(*pub.(*ec.PublicKey).PublicKey))
+
+	_, err = ConvertToEthCompatible(account.NewAccount("").PublicKey, data, sig)
+	assert.Error(t, err)
+}
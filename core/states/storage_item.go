@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/serialization"
 )
 
@@ -56,18 +57,26 @@ func (storageItem *StorageItem) ToArray() []byte {
 	return b.Bytes()
 }
 
+// GetValueFromRawStorageItem decodes raw in place and copies out the value, it is on the hot path of every native storage read
 func GetValueFromRawStorageItem(raw []byte) ([]byte, error) {
-	item := StorageItem{}
-	err := item.Deserialize(bytes.NewBuffer(raw))
-	if err != nil {
-		return nil, err
+	source := common.NewZeroCopySource(raw)
+	if _, eof := source.NextByte(); eof {
+		return nil, fmt.Errorf("[StorageItem], StateBase Deserialize failed,%s", io.ErrUnexpectedEOF)
 	}
-
-	return item.Value, nil
+	value, eof := source.NextVarBytes()
+	if eof {
+		return nil, fmt.Errorf("[StorageItem], Value Deserialize failed, error:%s", io.ErrUnexpectedEOF)
+	}
+	if len(value) == 0 {
+		return nil, nil
+	}
+	return append(make([]byte, 0, len(value)), value...), nil
 }
 
+// GenRawStorageItem encodes value into a storage item of default state version with a single allocation
 func GenRawStorageItem(value []byte) []byte {
-	item := StorageItem{}
-	item.Value = value
-	return item.ToArray()
+	sink := common.NewZeroCopySink(make([]byte, 0, 1+9+len(value)))
+	sink.WriteByte(0)
+	sink.WriteVarBytes(value)
+	return sink.Bytes()
 }
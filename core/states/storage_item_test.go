@@ -39,3 +39,39 @@ func TestStorageItem_Serialize_Deserialize(t *testing.T) {
 		t.Fatalf("StorageItem deserialize error: %v", err)
 	}
 }
+
+func TestRawStorageItem(t *testing.T) {
+	for _, value := range [][]byte{nil, {1}, bytes.Repeat([]byte{2}, 300)} {
+		item := &StorageItem{Value: value}
+		raw := GenRawStorageItem(value)
+		if !bytes.Equal(raw, item.ToArray()) {
+			t.Fatalf("GenRawStorageItem of %d bytes mismatch StorageItem.ToArray", len(value))
+		}
+		v, err := GetValueFromRawStorageItem(raw)
+		if err != nil {
+			t.Fatalf("GetValueFromRawStorageItem error: %v", err)
+		}
+		if !bytes.Equal(v, value) {
+			t.Fatalf("GetValueFromRawStorageItem of %d bytes mismatch", len(value))
+		}
+	}
+	if _, err := GetValueFromRawStorageItem([]byte{0, 2, 1}); err == nil {
+		t.Fatalf("GetValueFromRawStorageItem should fail on short value")
+	}
+}
+
+func BenchmarkGenRawStorageItem(b *testing.B) {
+	value := bytes.Repeat([]byte{1}, 32)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GenRawStorageItem(value)
+	}
+}
+
+func BenchmarkGetValueFromRawStorageItem(b *testing.B) {
+	raw := GenRawStorageItem(bytes.Repeat([]byte{1}, 32))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetValueFromRawStorageItem(raw)
+	}
+}
@@ -38,13 +38,18 @@ const (
 	IX_HEADER_HASH_LIST DataEntryPrefix = 0x09 //Block height => block hash key prefix
 
 	//SYSTEM
-	SYS_CURRENT_BLOCK      DataEntryPrefix = 0x10 //Current block key prefix
-	SYS_VERSION            DataEntryPrefix = 0x11 //Store version key prefix
-	SYS_CURRENT_STATE_ROOT DataEntryPrefix = 0x12 //no use
-	SYS_BLOCK_MERKLE_TREE  DataEntryPrefix = 0x13 // Block merkle tree root key prefix
-	SYS_STATE_MERKLE_TREE  DataEntryPrefix = 0x20 // state merkle tree root key prefix
-	SYS_CROSS_STATES       DataEntryPrefix = 0x22
-	SYS_CROSS_STATES_HASH  DataEntryPrefix = 0x23
+	SYS_CURRENT_BLOCK         DataEntryPrefix = 0x10 //Current block key prefix
+	SYS_VERSION               DataEntryPrefix = 0x11 //Store version key prefix
+	SYS_CURRENT_STATE_ROOT    DataEntryPrefix = 0x12 //no use
+	SYS_BLOCK_MERKLE_TREE     DataEntryPrefix = 0x13 // Block merkle tree root key prefix
+	SYS_STATE_MERKLE_TREE     DataEntryPrefix = 0x20 // state merkle tree root key prefix
+	SYS_CROSS_STATES          DataEntryPrefix = 0x22
+	SYS_CROSS_STATES_HASH     DataEntryPrefix = 0x23
+	SYS_CROSS_STATES_ACC_TREE DataEntryPrefix = 0x24 // cross states accumulator key prefix
+	SYS_CROSS_STATES_ACC_ROOT DataEntryPrefix = 0x25 // block height => accumulator size + root
+	SYS_RECEIPTS              DataEntryPrefix = 0x26 // block height => notify hashes of the transactions
+	SYS_MIGRATIONS            DataEntryPrefix = 0x27 // applied state migrations
 
-	EVENT_NOTIFY DataEntryPrefix = 0x14 //Event notify key prefix
+	EVENT_NOTIFY       DataEntryPrefix = 0x14 //Event notify key prefix
+	EVENT_NOTIFY_CHAIN DataEntryPrefix = 0x15 //Chain id + block height => transactions with events bound to the chain
 )
@@ -88,6 +88,8 @@ type EventStore interface {
 	SaveEventNotifyByTx(txHash common.Uint256, notify *event.ExecuteNotify) error
 	//Save transaction hashes which have event notify gen
 	SaveEventNotifyByBlock(height uint32, txHashs []common.Uint256) error
+	//Save transaction hashes of a block which have events bound to the chain
+	SaveEventNotifyByChain(chainID uint64, height uint32, txHashs []common.Uint256) error
 	//GetEventNotifyByTx return event notify by transaction hash
 	GetEventNotifyByTx(txHash common.Uint256) (*event.ExecuteNotify, error)
 	//Commit event notify to store
@@ -29,6 +29,7 @@ import (
 	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/store/leveldbstore"
 	"github.com/polynetwork/poly/native/event"
+	"math"
 )
 
 //Saving event notifies gen by smart contract execution
@@ -88,6 +89,65 @@ func (this *EventStore) SaveEventNotifyByBlock(height uint32, txHashs []common.U
 	return nil
 }
 
+//SaveEventNotifyByChain persist the hashes of the transactions in block which have events bound to the chain
+func (this *EventStore) SaveEventNotifyByChain(chainID uint64, height uint32, txHashs []common.Uint256) error {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(uint64(len(txHashs)))
+	for _, txHash := range txHashs {
+		sink.WriteHash(txHash)
+	}
+	this.store.BatchPut(this.getEventNotifyByChainKey(chainID, height), sink.Bytes())
+	return nil
+}
+
+//GetEventNotifyByChain return the event notifies of the transactions in block which have events bound to the chain
+func (this *EventStore) GetEventNotifyByChain(chainID uint64, height uint32) ([]*event.ExecuteNotify, error) {
+	data, err := this.store.Get(this.getEventNotifyByChainKey(chainID, height))
+	if err != nil {
+		return nil, err
+	}
+	source := common.NewZeroCopySource(data)
+	size, eof := source.NextVarUint()
+	if eof {
+		return nil, fmt.Errorf("read size error")
+	}
+	evtNotifies := make([]*event.ExecuteNotify, 0, size)
+	for i := uint64(0); i < size; i++ {
+		txHash, eof := source.NextHash()
+		if eof {
+			return nil, fmt.Errorf("read No.%d txHash error", i)
+		}
+		evtNotify, err := this.GetEventNotifyByTx(txHash)
+		if err != nil {
+			return nil, fmt.Errorf("getEventNotifyByTx Height:%d by txhash:%s error:%s", height, txHash.ToHexString(), err)
+		}
+		evtNotifies = append(evtNotifies, evtNotify)
+	}
+	return evtNotifies, nil
+}
+
+//GetChainEventHeights return at most limit heights from start to end of the blocks which have events bound to the chain
+func (this *EventStore) GetChainEventHeights(chainID uint64, start, end uint32, limit int) ([]uint32, error) {
+	heights := make([]uint32, 0)
+	if start > end {
+		return heights, nil
+	}
+	iter := this.store.NewRangeIterator(this.getEventNotifyByChainKey(chainID, start),
+		append(this.getEventNotifyByChainKey(chainID, math.MaxUint32), 0))
+	defer iter.Release()
+	for iter.Next() && len(heights) < limit {
+		height := binary.BigEndian.Uint32(iter.Key()[9:])
+		if height > end {
+			break
+		}
+		heights = append(heights, height)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return heights, nil
+}
+
 //GetEventNotifyByTx return event notify by trasanction hash
 func (this *EventStore) GetEventNotifyByTx(txHash common.Uint256) (*event.ExecuteNotify, error) {
 	key := this.getEventNotifyByTxKey(txHash)
@@ -200,6 +260,15 @@ func (this *EventStore) getEventNotifyByBlockKey(height uint32) ([]byte, error)
 	return key, nil
 }
 
+// getEventNotifyByChainKey orders the keys of a chain by height
+func (this *EventStore) getEventNotifyByChainKey(chainID uint64, height uint32) []byte {
+	key := make([]byte, 13)
+	key[0] = byte(scom.EVENT_NOTIFY_CHAIN)
+	binary.BigEndian.PutUint64(key[1:], chainID)
+	binary.BigEndian.PutUint32(key[9:], height)
+	return key
+}
+
 func (this *EventStore) getEventNotifyByTxKey(txHash common.Uint256) []byte {
 	data := txHash.ToArray()
 	key := make([]byte, 1+len(data))
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventNotifyByChain(t *testing.T) {
+	store := testLedgerStore.eventStore
+	notify := func(txHash common.Uint256, from, to uint64) *event.ExecuteNotify {
+		return &event.ExecuteNotify{
+			TxHash: txHash,
+			State:  event.CONTRACT_STATE_SUCCESS,
+			Notify: []*event.NotifyEventInfo{
+				{States: []interface{}{"other"}},
+				{States: []interface{}{"makeProof", from, to}, FromChainID: event.ChainID(from), ToChainID: event.ChainID(to)},
+			},
+		}
+	}
+	first := notify(common.Uint256{1}, 1000, 1001)
+	second := notify(common.Uint256{2}, 1001, 1002)
+	third := notify(common.Uint256{3}, 1000, 1000)
+
+	store.NewBatch()
+	for _, v := range []*event.ExecuteNotify{first, second, third} {
+		assert.NoError(t, store.SaveEventNotifyByTx(v.TxHash, v))
+	}
+	assert.NoError(t, SaveChainIndex(store, 5, []*event.ExecuteNotify{first, second}))
+	assert.NoError(t, SaveChainIndex(store, 9, []*event.ExecuteNotify{third}))
+	assert.NoError(t, store.CommitTo())
+
+	heights, err := store.GetChainEventHeights(1000, 0, 100, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{5, 9}, heights)
+	heights, _ = store.GetChainEventHeights(1000, 6, 100, 10)
+	assert.Equal(t, []uint32{9}, heights)
+	heights, _ = store.GetChainEventHeights(1000, 0, 8, 10)
+	assert.Equal(t, []uint32{5}, heights)
+	heights, _ = store.GetChainEventHeights(1000, 0, 100, 1)
+	assert.Equal(t, []uint32{5}, heights)
+	heights, _ = store.GetChainEventHeights(1002, 0, 100, 10)
+	assert.Equal(t, []uint32{5}, heights)
+	heights, _ = store.GetChainEventHeights(1003, 0, 100, 10)
+	assert.Len(t, heights, 0)
+
+	notifies, err := store.GetEventNotifyByChain(1001, 5)
+	assert.NoError(t, err)
+	if assert.Len(t, notifies, 2) {
+		assert.Equal(t, first.TxHash, notifies[0].TxHash)
+		assert.Equal(t, uint64(1002), *notifies[1].Notify[1].ToChainID)
+	}
+	// a transaction is indexed once for an event bound to the chain on both sides
+	notifies, _ = store.GetEventNotifyByChain(1000, 9)
+	assert.Len(t, notifies, 1)
+}
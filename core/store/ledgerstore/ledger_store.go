@@ -55,10 +55,11 @@ const (
 
 var (
 	//Storage save path.
-	DBDirEvent          = "ledgerevent"
-	DBDirBlock          = "block"
-	DBDirState          = "states"
-	MerkleTreeStorePath = "merkle_tree.db"
+	DBDirEvent              = "ledgerevent"
+	DBDirBlock              = "block"
+	DBDirState              = "states"
+	MerkleTreeStorePath     = "merkle_tree.db"
+	CrossStatesAccStorePath = "cross_states_acc.db"
 )
 
 //LedgerStoreImp is main store struct fo ledger
@@ -515,6 +516,12 @@ func (this *LedgerStoreImp) GetCrossStateRoot(height uint32) (common.Uint256, er
 	return this.stateStore.GetCrossStateRoot(height)
 }
 
+//GetCrossStatesAccRoot return the root of the cross states accumulator after the block at height
+func (this *LedgerStoreImp) GetCrossStatesAccRoot(height uint32) (common.Uint256, error) {
+	_, root, err := this.stateStore.GetCrossStatesAcc(height)
+	return root, err
+}
+
 func (this *LedgerStoreImp) ExecuteBlock(block *types.Block) (result store.ExecuteResult, err error) {
 	this.getSavingBlockLock()
 	defer this.releaseSavingBlockLock()
@@ -601,6 +608,91 @@ func (this *LedgerStoreImp) GetCrossStatesProof(height uint32, key []byte) ([]by
 	return path, nil
 }
 
+//GetCrossStatesAccProof return the merkle proof of the cross state of key committed at height
+//against the accumulator root carried by the header at rootHeight
+func (this *LedgerStoreImp) GetCrossStatesAccProof(height uint32, key []byte, rootHeight uint32) ([]byte, error) {
+	if height < config.GetCrossStatesAccHeight(config.DefConfig.P2PNode.NetworkId) {
+		return nil, fmt.Errorf("cross states of height %d are not accumulated", height)
+	}
+	if height >= rootHeight {
+		return nil, fmt.Errorf("cross states of height %d are not covered by the header at height %d", height, rootHeight)
+	}
+	hashes, err := this.stateStore.GetCrossStates(height)
+	if err != nil {
+		return nil, fmt.Errorf("GetCrossStates:%s", err)
+	}
+	state, err := this.stateStore.GetStorageValue(key)
+	if err != nil {
+		return nil, fmt.Errorf("GetStorageState key:%x", key)
+	}
+	leaf := merkle.HashLeaf(state)
+	index := -1
+	for i, v := range hashes {
+		if v == leaf {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("cross state of key %x is not committed at height %d", key, height)
+	}
+	offset, _, err := this.stateStore.GetCrossStatesAcc(height - 1)
+	if err != nil {
+		return nil, fmt.Errorf("GetCrossStatesAcc:%s", err)
+	}
+	size, _, err := this.stateStore.GetCrossStatesAcc(rootHeight - 1)
+	if err != nil {
+		return nil, fmt.Errorf("GetCrossStatesAcc:%s", err)
+	}
+	return this.stateStore.GetCrossStatesAccProof(state, offset+uint32(index), size)
+}
+
+//GetReceiptProof returns the height of the block containing txHash, the merkle proof of its notify hash against
+//the receipts root of the block and the proof of the receipts root against the cross states root of the block
+func (this *LedgerStoreImp) GetReceiptProof(txHash common.Uint256) (uint32, []byte, []byte, error) {
+	_, height, err := this.GetTransaction(txHash)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("GetTransaction %s error %s", txHash.ToHexString(), err)
+	}
+	if height < config.GetReceiptsRootHeight(config.DefConfig.P2PNode.NetworkId) {
+		return 0, nil, nil, fmt.Errorf("receipts of height %d are not committed", height)
+	}
+	block, err := this.GetBlockByHeight(height)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("GetBlockByHeight %d error %s", height, err)
+	}
+	receipts, err := this.stateStore.GetReceipts(height)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("GetReceipts:%s", err)
+	}
+	if len(receipts) != len(block.Transactions) {
+		return 0, nil, nil, fmt.Errorf("%d receipts saved for %d transactions of height %d", len(receipts),
+			len(block.Transactions), height)
+	}
+	leaves := make([]common.Uint256, 0, len(receipts))
+	var notifyHash []byte
+	for i, v := range receipts {
+		leaves = append(leaves, merkle.HashLeaf(v[:]))
+		if block.Transactions[i].Hash() == txHash {
+			notifyHash = receipts[i][:]
+		}
+	}
+	receiptProof, err := merkle.MerkleLeafPath(notifyHash, leaves)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	hashes, err := this.stateStore.GetCrossStates(height)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("GetCrossStates:%s", err)
+	}
+	root := merkle.TreeHasher{}.HashFullTreeWithLeafHash(leaves)
+	crossStatesProof, err := merkle.MerkleLeafPath(root[:], hashes)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return height, receiptProof, crossStatesProof, nil
+}
+
 func (this *LedgerStoreImp) saveBlockToBlockStore(block *types.Block) error {
 	blockHash := block.Hash()
 	blockHeight := block.Header.Height
@@ -624,23 +716,50 @@ func (this *LedgerStoreImp) saveBlockToBlockStore(block *types.Block) error {
 
 func (this *LedgerStoreImp) executeBlock(block *types.Block) (result store.ExecuteResult, err error) {
 	overlay := this.stateStore.NewOverlayDB()
+	if err = runMigrations(overlay, config.DefConfig.P2PNode.NetworkId, block.Header.Height); err != nil {
+		return
+	}
 
-	cache := storage.NewCacheDB(overlay)
-	for _, tx := range block.Transactions {
-		cache.Reset()
-		notify, crossHashes, e := this.handleTransaction(overlay, cache, block, tx)
-		if e != nil {
-			err = e
+	if workers := int(config.DefConfig.Common.ExecWorkers); workers > 1 && len(block.Transactions) > 1 {
+		result.Notify, result.CrossHashes, err = this.executeTransactionsParallel(overlay, block, workers)
+		if err != nil {
 			return
 		}
-		result.Notify = append(result.Notify, notify)
-		result.CrossHashes = append(result.CrossHashes, crossHashes...)
+	} else {
+		cache := storage.NewCacheDB(overlay)
+		for _, tx := range block.Transactions {
+			cache.Reset()
+			notify, crossHashes, e := this.handleTransaction(overlay, cache, block, tx)
+			if e != nil {
+				err = e
+				return
+			}
+			result.Notify = append(result.Notify, notify)
+			result.CrossHashes = append(result.CrossHashes, crossHashes...)
+		}
+	}
+	if block.Header.Height >= config.GetReceiptsRootHeight(config.DefConfig.P2PNode.NetworkId) {
+		leaves := make([]common.Uint256, 0, len(result.Notify))
+		for _, notify := range result.Notify {
+			notify.NotifyHash, err = notify.Hash()
+			if err != nil {
+				err = fmt.Errorf("hash notify of tx %s error %s", notify.TxHash.ToHexString(), err)
+				return
+			}
+			leaves = append(leaves, merkle.HashLeaf(notify.NotifyHash[:]))
+		}
+		// the receipts root is the last cross state so that the header of the next block commits to it
+		result.ReceiptsRoot = merkle.TreeHasher{}.HashFullTreeWithLeafHash(leaves)
+		result.CrossHashes = append(result.CrossHashes, merkle.HashLeaf(result.ReceiptsRoot[:]))
 	}
 	if len(result.CrossHashes) != 0 {
 		result.CrossStatesRoot = merkle.TreeHasher{}.HashFullTreeWithLeafHash(result.CrossHashes)
 	} else {
 		result.CrossStatesRoot = common.UINT256_EMPTY
 	}
+	if block.Header.Height >= config.GetCrossStatesAccHeight(config.DefConfig.P2PNode.NetworkId) {
+		result.CrossStatesAccRoot = this.stateStore.GetCrossStatesAccRootWithNewLeafHashes(result.CrossHashes)
+	}
 	result.Hash = overlay.ChangeHash()
 	result.WriteSet = overlay.GetWriteSet()
 	result.MerkleRoot = this.stateStore.GetStateMerkleRootWithNewHash(result.Hash)
@@ -657,6 +776,9 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 			return fmt.Errorf("SaveNotify error %s", err)
 		}
 	}
+	if err := SaveChainIndex(this.eventStore, blockHeight, result.Notify); err != nil {
+		return err
+	}
 
 	err := this.stateStore.AddStateMerkleTreeRoot(blockHeight, result.Hash)
 	if err != nil {
@@ -678,6 +800,21 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 		return err
 	}
 
+	if blockHeight >= config.GetReceiptsRootHeight(config.DefConfig.P2PNode.NetworkId) {
+		receipts := make([]common.Uint256, 0, len(result.Notify))
+		for _, notify := range result.Notify {
+			receipts = append(receipts, notify.NotifyHash)
+		}
+		this.stateStore.AddReceipts(blockHeight, receipts)
+	}
+
+	if blockHeight >= config.GetCrossStatesAccHeight(config.DefConfig.P2PNode.NetworkId) {
+		err = this.stateStore.AddCrossStatesAcc(blockHeight, result.CrossHashes)
+		if err != nil {
+			return fmt.Errorf("AddCrossStatesAcc error %s", err)
+		}
+	}
+
 	log.Debugf("the state transition hash of block %d is:%s", blockHeight, result.Hash.ToHexString())
 
 	result.WriteSet.ForEach(func(key, val []byte) {
@@ -744,6 +881,15 @@ func (this *LedgerStoreImp) submitBlock(block *types.Block, result store.Execute
 			block.Header.Height, blockRoot.ToHexString(), block.Header.BlockRoot.ToHexString())
 	}
 
+	var diff *types.StateDiff
+	if stateDiffEnabled() {
+		var err error
+		diff, err = this.getStateDiff(block, result.WriteSet)
+		if err != nil {
+			return fmt.Errorf("get state diff height:%d error:%s", blockHeight, err)
+		}
+	}
+
 	this.blockStore.NewBatch()
 	this.stateStore.NewBatch()
 	this.eventStore.NewBatch()
@@ -773,6 +919,9 @@ func (this *LedgerStoreImp) submitBlock(block *types.Block, result store.Execute
 		return fmt.Errorf("stateStore.CommitTo height:%d error %s", blockHeight, err)
 	}
 	this.setCurrentBlock(blockHeight, blockHash)
+	if diff != nil {
+		exportStateDiff(diff)
+	}
 
 	if events.DefActorPublisher != nil {
 		events.DefActorPublisher.Publish(
@@ -971,6 +1120,16 @@ func (this *LedgerStoreImp) GetEventNotifyByBlock(height uint32) ([]*event.Execu
 	return this.eventStore.GetEventNotifyByBlock(height)
 }
 
+//GetEventNotifyByChain return the events notify of the transactions in block with events bound to the chain. Wrap function of EventStore.GetEventNotifyByChain
+func (this *LedgerStoreImp) GetEventNotifyByChain(chainID uint64, height uint32) ([]*event.ExecuteNotify, error) {
+	return this.eventStore.GetEventNotifyByChain(chainID, height)
+}
+
+//GetChainEventHeights return the heights of the blocks with events bound to the chain. Wrap function of EventStore.GetChainEventHeights
+func (this *LedgerStoreImp) GetChainEventHeights(chainID uint64, start, end uint32, limit int) ([]uint32, error) {
+	return this.eventStore.GetChainEventHeights(chainID, start, end, limit)
+}
+
 //Close ledger store.
 func (this *LedgerStoreImp) Close() error {
 	err := this.blockStore.Close()
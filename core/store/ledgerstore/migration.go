@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/native/storage"
+)
+
+// Migration rewrites the storage of native contracts into a new layout. It runs once, before the
+// transactions of the block at its switch height, and its writes are part of the state of that block.
+type Migration struct {
+	Name string
+	// Height is the switch height by network id, the migration never runs on the networks left out
+	Height map[uint32]uint32
+	// Checksum is the expected hash of the writes of the migration, the block fails to execute if they
+	// differ. It is not verified when empty, e.g. for the networks the expected writes are unknown on.
+	Checksum common.Uint256
+	Migrate  func(db *storage.CacheDB, height uint32) error
+}
+
+// MigrationRecord is a migration applied at Height whose writes hash to Checksum
+type MigrationRecord struct {
+	Name     string
+	Height   uint32
+	Checksum common.Uint256
+}
+
+// AppliedMigrations are the applied migrations in the order they run
+type AppliedMigrations struct {
+	Records []*MigrationRecord
+}
+
+func (this *AppliedMigrations) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Records)))
+	for _, v := range this.Records {
+		sink.WriteString(v.Name)
+		sink.WriteUint32(v.Height)
+		sink.WriteHash(v.Checksum)
+	}
+}
+
+func (this *AppliedMigrations) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("AppliedMigrations deserialize length error")
+	}
+	records := make([]*MigrationRecord, 0)
+	for i := uint64(0); i < n; i++ {
+		name, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("AppliedMigrations deserialize name of No.%d record error", i)
+		}
+		height, eof := source.NextUint32()
+		if eof {
+			return fmt.Errorf("AppliedMigrations deserialize height of No.%d record error", i)
+		}
+		checksum, eof := source.NextHash()
+		if eof {
+			return fmt.Errorf("AppliedMigrations deserialize checksum of No.%d record error", i)
+		}
+		records = append(records, &MigrationRecord{Name: name, Height: height, Checksum: checksum})
+	}
+	this.Records = records
+	return nil
+}
+
+func (this *AppliedMigrations) contains(name string) bool {
+	for _, v := range this.Records {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// migrations are registered by the contracts changing their layout, in the order they run
+var migrations []*Migration
+
+// RegisterMigration registers m from the init of a contract. The migrations of the same block run in
+// ascending order of names.
+func RegisterMigration(m *Migration) {
+	for _, v := range migrations {
+		if v.Name == m.Name {
+			panic(fmt.Sprintf("migration %s is already registered", m.Name))
+		}
+	}
+	migrations = append(migrations, m)
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return migrations[i].Name < migrations[j].Name
+	})
+}
+
+// Migrations returns the names of the registered migrations
+func Migrations() []string {
+	names := make([]string, 0, len(migrations))
+	for _, v := range migrations {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func migrationsKey() []byte {
+	return []byte{byte(scom.SYS_MIGRATIONS)}
+}
+
+func getAppliedMigrations(overlay *overlaydb.OverlayDB) (*AppliedMigrations, error) {
+	applied := new(AppliedMigrations)
+	data, err := overlay.Get(migrationsKey())
+	if err != nil {
+		return nil, fmt.Errorf("get applied migrations error %s", err)
+	}
+	if data == nil {
+		return applied, nil
+	}
+	if err := applied.Deserialization(common.NewZeroCopySource(data)); err != nil {
+		return nil, fmt.Errorf("deserialize applied migrations error %s", err)
+	}
+	return applied, nil
+}
+
+// runMigrations applies the migrations switching at height on the network to overlay, the ones already
+// applied are skipped
+func runMigrations(overlay *overlaydb.OverlayDB, networkId, height uint32) error {
+	var applied *AppliedMigrations
+	for _, m := range migrations {
+		if h, ok := m.Height[networkId]; !ok || h != height {
+			continue
+		}
+		if applied == nil {
+			var err error
+			if applied, err = getAppliedMigrations(overlay); err != nil {
+				return err
+			}
+		}
+		if applied.contains(m.Name) {
+			continue
+		}
+		cache := storage.NewCacheDB(overlay)
+		if err := m.Migrate(cache, height); err != nil {
+			return fmt.Errorf("migration %s error %s", m.Name, err)
+		}
+		checksum := migrationChecksum(cache)
+		if m.Checksum != common.UINT256_EMPTY && checksum != m.Checksum {
+			return fmt.Errorf("migration %s checksum %s, expected %s", m.Name, checksum.ToHexString(),
+				m.Checksum.ToHexString())
+		}
+		cache.Commit()
+		applied.Records = append(applied.Records, &MigrationRecord{Name: m.Name, Height: height, Checksum: checksum})
+		sink := common.NewZeroCopySink(nil)
+		applied.Serialization(sink)
+		overlay.Put(migrationsKey(), sink.Bytes())
+	}
+	return nil
+}
+
+// migrationChecksum hashes the writes of a migration in key order, deletions have empty values
+func migrationChecksum(cache *storage.CacheDB) common.Uint256 {
+	h := sha256.New()
+	sink := common.NewZeroCopySink(nil)
+	cache.ForEach(func(key, val []byte) {
+		sink.Reset()
+		sink.WriteVarBytes(key)
+		sink.WriteVarBytes(val)
+		h.Write(sink.Bytes())
+	})
+	var checksum common.Uint256
+	h.Sum(checksum[:0])
+	return checksum
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMigrationOverlay(t *testing.T) *overlaydb.OverlayDB {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	if err != nil {
+		t.Fatalf("NewMemLevelDBStore error %s", err)
+	}
+	overlay := overlaydb.NewOverlayDB(store)
+	cache := storage.NewCacheDB(overlay)
+	cache.Put([]byte("old/a"), []byte{1})
+	cache.Put([]byte("old/b"), []byte{2})
+	cache.Commit()
+	return overlay
+}
+
+func moveOld(db *storage.CacheDB, height uint32) error {
+	for _, k := range []string{"a", "b"} {
+		val, err := db.Get([]byte("old/" + k))
+		if err != nil {
+			return err
+		}
+		db.Put([]byte("new/"+k), val)
+		db.Delete([]byte("old/" + k))
+	}
+	return nil
+}
+
+func TestRunMigrations(t *testing.T) {
+	saved := migrations
+	defer func() { migrations = saved }()
+	migrations = nil
+
+	runs := 0
+	RegisterMigration(&Migration{
+		Name:   "test/move",
+		Height: map[uint32]uint32{1: 10},
+		Migrate: func(db *storage.CacheDB, height uint32) error {
+			runs++
+			return moveOld(db, height)
+		},
+	})
+	assert.Panics(t, func() { RegisterMigration(&Migration{Name: "test/move"}) })
+
+	overlay := newMigrationOverlay(t)
+	assert.NoError(t, runMigrations(overlay, 1, 9))
+	assert.NoError(t, runMigrations(overlay, 2, 10))
+	assert.Equal(t, 0, runs, "migration runs off its switch height")
+
+	assert.NoError(t, runMigrations(overlay, 1, 10))
+	assert.Equal(t, 1, runs)
+	cache := storage.NewCacheDB(overlay)
+	val, _ := cache.Get([]byte("new/b"))
+	assert.Equal(t, []byte{2}, val)
+	val, _ = cache.Get([]byte("old/b"))
+	assert.Nil(t, val)
+
+	applied, err := getAppliedMigrations(overlay)
+	assert.NoError(t, err)
+	if assert.Len(t, applied.Records, 1) {
+		assert.Equal(t, "test/move", applied.Records[0].Name)
+		assert.Equal(t, uint32(10), applied.Records[0].Height)
+	}
+
+	assert.NoError(t, runMigrations(overlay, 1, 10))
+	assert.Equal(t, 1, runs, "applied migration runs again")
+}
+
+func TestRunMigrationsChecksum(t *testing.T) {
+	saved := migrations
+	defer func() { migrations = saved }()
+	migrations = nil
+
+	expected := &Migration{Name: "test/move", Height: map[uint32]uint32{1: 10}, Migrate: moveOld}
+	RegisterMigration(expected)
+	overlay := newMigrationOverlay(t)
+	if !assert.NoError(t, runMigrations(overlay, 1, 10)) {
+		return
+	}
+	applied, _ := getAppliedMigrations(overlay)
+	checksum := applied.Records[0].Checksum
+
+	// the same writes pass the verification on another node
+	expected.Checksum = checksum
+	assert.NoError(t, runMigrations(newMigrationOverlay(t), 1, 10))
+
+	expected.Checksum = common.Uint256{1}
+	overlay = newMigrationOverlay(t)
+	assert.Error(t, runMigrations(overlay, 1, 10))
+	applied, _ = getAppliedMigrations(overlay)
+	assert.Len(t, applied.Records, 0, "migration failing the checksum is recorded")
+
+	expected.Checksum = common.UINT256_EMPTY
+	expected.Migrate = func(db *storage.CacheDB, height uint32) error {
+		return fmt.Errorf("layout unknown")
+	}
+	assert.Error(t, runMigrations(newMigrationOverlay(t), 1, 10))
+}
+
+func TestAppliedMigrationsSerialization(t *testing.T) {
+	applied := &AppliedMigrations{Records: []*MigrationRecord{
+		{Name: "a", Height: 1, Checksum: common.Uint256{1}},
+		{Name: "b", Height: 2, Checksum: common.Uint256{2}},
+	}}
+	sink := common.NewZeroCopySink(nil)
+	applied.Serialization(sink)
+	decoded := new(AppliedMigrations)
+	assert.NoError(t, decoded.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, applied, decoded)
+	assert.Error(t, decoded.Deserialization(common.NewZeroCopySource(sink.Bytes()[:sink.Size()-1])))
+}
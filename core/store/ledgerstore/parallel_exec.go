@@ -0,0 +1,175 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/storage"
+)
+
+const (
+	TX_OVERLAY_CAP    = 16 * 1024 //Initial capacity of the write set of a speculative transaction
+	TX_OVERLAY_KV_NUM = 64
+)
+
+//readSetStore records the keys and the iterated prefixes a transaction reads from the state at block start
+type readSetStore struct {
+	scom.PersistStore
+	keys     [][]byte
+	prefixes [][]byte
+}
+
+func (self *readSetStore) Get(key []byte) ([]byte, error) {
+	self.keys = append(self.keys, append([]byte{}, key...))
+	return self.PersistStore.Get(key)
+}
+
+func (self *readSetStore) Has(key []byte) (bool, error) {
+	self.keys = append(self.keys, append([]byte{}, key...))
+	return self.PersistStore.Has(key)
+}
+
+func (self *readSetStore) NewIterator(prefix []byte) scom.StoreIterator {
+	self.prefixes = append(self.prefixes, append([]byte{}, prefix...))
+	return self.PersistStore.NewIterator(prefix)
+}
+
+//conflicts return whether any key read is written by the former transactions of the block
+func (self *readSetStore) conflicts(written map[string]struct{}) bool {
+	for _, key := range self.keys {
+		if _, ok := written[string(key)]; ok {
+			return true
+		}
+	}
+	for _, prefix := range self.prefixes {
+		for key := range written {
+			if bytes.HasPrefix([]byte(key), prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//txExecution is the result of executing a transaction against the state at block start
+type txExecution struct {
+	notify      *event.ExecuteNotify
+	crossHashes []common.Uint256
+	reads       *readSetStore
+	writes      *overlaydb.MemDB
+	err         error
+}
+
+//txHandler executes tx against overlay through cache
+type txHandler func(overlay *overlaydb.OverlayDB, cache *storage.CacheDB, tx *types.Transaction) (*event.ExecuteNotify,
+	[]common.Uint256, error)
+
+//speculateTransaction execute tx against the state at block start, recording its read and write set
+func speculateTransaction(store scom.PersistStore, tx *types.Transaction, handle txHandler) *txExecution {
+	reads := &readSetStore{PersistStore: store}
+	overlay := overlaydb.NewOverlayDBWithCap(reads, TX_OVERLAY_CAP, TX_OVERLAY_KV_NUM)
+	cache := storage.NewCacheDB(overlay)
+	notify, crossHashes, err := handle(overlay, cache, tx)
+	return &txExecution{
+		notify:      notify,
+		crossHashes: crossHashes,
+		reads:       reads,
+		writes:      overlay.GetWriteSet(),
+		err:         err,
+	}
+}
+
+//executeTransactionsParallel execute the transactions of block with workers concurrently
+func (this *LedgerStoreImp) executeTransactionsParallel(overlay *overlaydb.OverlayDB, block *types.Block,
+	workers int) ([]*event.ExecuteNotify, []common.Uint256, error) {
+	return executeParallel(overlay, this.stateStore.store, block.Transactions, workers,
+		func(overlay *overlaydb.OverlayDB, cache *storage.CacheDB, tx *types.Transaction) (*event.ExecuteNotify,
+			[]common.Uint256, error) {
+			return this.handleTransaction(overlay, cache, block, tx)
+		})
+}
+
+//executeParallel execute txs with workers concurrently. Every transaction is speculated against store, the
+//state at block start, then the results are committed to overlay in block order. A transaction reading any
+//key written to overlay before it, by the migrations of the block or a former transaction, is executed again
+//against overlay, so the write set, notifies and cross states are the same as executing them one by one. The
+//failure hook of a failed transaction writes through the same overlay, its reads and writes are in the sets
+//of the transaction like the ones of the failed invocation deciding them.
+func executeParallel(overlay *overlaydb.OverlayDB, store scom.PersistStore, txs []*types.Transaction, workers int,
+	handle txHandler) ([]*event.ExecuteNotify, []common.Uint256, error) {
+	execs := make([]*txExecution, len(txs))
+	jobs := make(chan int, len(txs))
+	for i := range txs {
+		jobs <- i
+	}
+	close(jobs)
+	wg := new(sync.WaitGroup)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				execs[i] = speculateTransaction(store, txs[i], handle)
+			}
+		}()
+	}
+	wg.Wait()
+
+	notifies := make([]*event.ExecuteNotify, 0, len(txs))
+	var crossHashes []common.Uint256
+	written := make(map[string]struct{})
+	overlay.GetWriteSet().ForEach(func(key, val []byte) {
+		written[string(key)] = struct{}{}
+	})
+	cache := storage.NewCacheDB(overlay)
+	for i, tx := range txs {
+		exec := execs[i]
+		if exec.err == nil && !exec.reads.conflicts(written) {
+			exec.writes.ForEach(func(key, val []byte) {
+				written[string(key)] = struct{}{}
+				if len(val) == 0 {
+					overlay.Delete(key)
+				} else {
+					overlay.Put(key, val)
+				}
+			})
+			notifies = append(notifies, exec.notify)
+			crossHashes = append(crossHashes, exec.crossHashes...)
+			continue
+		}
+		cache.Reset()
+		notify, hashes, err := handle(overlay, cache, tx)
+		if err != nil {
+			return nil, nil, err
+		}
+		// the cache of a failed tx only keeps the writes of the failure hook
+		cache.ForEach(func(key, val []byte) {
+			written[string(key)] = struct{}{}
+		})
+		notifies = append(notifies, notify)
+		crossHashes = append(crossHashes, hashes...)
+	}
+	return notifies, crossHashes, nil
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	nstates "github.com/polynetwork/poly/native/states"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSetConflicts(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	store.Put([]byte("a1"), []byte("v"))
+	reads := &readSetStore{PersistStore: store}
+	overlay := overlaydb.NewOverlayDBWithCap(reads, TX_OVERLAY_CAP, TX_OVERLAY_KV_NUM)
+
+	val, err := overlay.Get([]byte("a1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("v"), val)
+	overlay.Put([]byte("c1"), []byte("w"))
+	_, err = overlay.Get([]byte("c1"))
+	assert.Nil(t, err)
+	iter := overlay.NewIterator([]byte("b"))
+	iter.Release()
+
+	assert.False(t, reads.conflicts(map[string]struct{}{"a2": {}, "c1": {}}), "own writes are not read from block state")
+	assert.True(t, reads.conflicts(map[string]struct{}{"a1": {}}))
+	assert.True(t, reads.conflicts(map[string]struct{}{"b9": {}}), "key under iterated prefix conflicts")
+}
+
+func TestExecuteParallelAfterMigration(t *testing.T) {
+	saved := migrations
+	defer func() { migrations = saved }()
+	migrations = nil
+	RegisterMigration(&Migration{Name: "test/move", Height: map[uint32]uint32{1: 10}, Migrate: moveOld})
+
+	// every tx copies old/a and new/a under its own keys
+	handle := func(overlay *overlaydb.OverlayDB, cache *storage.CacheDB, tx *types.Transaction) (*event.ExecuteNotify,
+		[]common.Uint256, error) {
+		for _, k := range []string{"old/a", "new/a"} {
+			val, err := cache.Get([]byte(k))
+			if err != nil {
+				return nil, nil, err
+			}
+			cache.Put([]byte(fmt.Sprintf("tx%d/%s", tx.Nonce, k)), append([]byte{0}, val...))
+		}
+		cache.Commit()
+		return &event.ExecuteNotify{State: event.CONTRACT_STATE_SUCCESS}, nil, nil
+	}
+	txs := []*types.Transaction{{Nonce: 1}, {Nonce: 2}, {Nonce: 3}}
+	migrated := func() (*overlaydb.OverlayDB, *leveldbstore.LevelDBStore) {
+		store, _ := leveldbstore.NewMemLevelDBStore()
+		store.Put([]byte("old/a"), []byte{1})
+		store.Put([]byte("old/b"), []byte{2})
+		overlay := overlaydb.NewOverlayDB(store)
+		assert.NoError(t, runMigrations(overlay, 1, 10))
+		return overlay, store
+	}
+
+	sequential, _ := migrated()
+	cache := storage.NewCacheDB(sequential)
+	for _, tx := range txs {
+		cache.Reset()
+		_, _, err := handle(sequential, cache, tx)
+		assert.NoError(t, err)
+	}
+
+	parallel, store := migrated()
+	notifies, _, err := executeParallel(parallel, store, txs, 2, handle)
+	assert.NoError(t, err)
+	assert.Len(t, notifies, len(txs))
+	val, _ := parallel.Get([]byte("tx1/new/a"))
+	assert.Equal(t, []byte{0, 1}, val, "tx speculated against the state before the migration")
+	assert.Equal(t, sequential.ChangeHash(), parallel.ChangeHash())
+}
+
+func TestExecuteParallelFailureHook(t *testing.T) {
+	networkID := config.DefConfig.P2PNode.NetworkId
+	saved := config.FAILURE_HOOK_HEIGHT[networkID]
+	defer func() { config.FAILURE_HOOK_HEIGHT[networkID] = saved }()
+	config.FAILURE_HOOK_HEIGHT[networkID] = 0
+
+	// the import fails after writing, its failure hook counts the failures, the last tx reads the count
+	contract := common.Address{0xfe}
+	native.Contracts[contract] = func(ns *native.NativeService) {
+		ns.Register("import", func(ns *native.NativeService) ([]byte, error) {
+			ns.GetCacheDB().Put([]byte("imported"), []byte{1})
+			return nil, errors.New("import fails")
+		})
+		ns.Register("read", func(ns *native.NativeService) ([]byte, error) {
+			count, err := ns.GetCacheDB().Get([]byte("count"))
+			if err != nil {
+				return nil, err
+			}
+			ns.GetCacheDB().Put([]byte("read"), append([]byte{0}, count...))
+			return nil, nil
+		})
+	}
+	native.FailureHooks[contract] = func(ns *native.NativeService, method string, cause error) {
+		count, _ := ns.GetCacheDB().Get([]byte("count"))
+		ns.GetCacheDB().Put([]byte("count"), []byte{byte(len(count)) + 1})
+	}
+	defer func() {
+		delete(native.Contracts, contract)
+		delete(native.FailureHooks, contract)
+	}()
+
+	block := &types.Block{Header: &types.Header{Height: 1}}
+	block.Hash() // cached before the concurrent executions read it
+	newTx := func(nonce uint32, method string) *types.Transaction {
+		sink := common.NewZeroCopySink(nil)
+		(&nstates.ContractInvokeParam{Address: contract, Method: method}).Serialization(sink)
+		return &types.Transaction{TxType: types.Invoke, Nonce: nonce, Payload: &payload.InvokeCode{Code: sink.Bytes()}}
+	}
+	txs := []*types.Transaction{newTx(1, "import"), newTx(2, "import"), newTx(3, "read")}
+	handle := func(overlay *overlaydb.OverlayDB, cache *storage.CacheDB, tx *types.Transaction) (*event.ExecuteNotify,
+		[]common.Uint256, error) {
+		notify := &event.ExecuteNotify{State: event.CONTRACT_STATE_FAIL}
+		hashes, _ := new(StateStore).HandleInvokeTransaction(nil, overlay, cache, tx, block, notify)
+		return notify, hashes, nil
+	}
+
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	sequential := overlaydb.NewOverlayDB(store)
+	cache := storage.NewCacheDB(sequential)
+	for _, tx := range txs {
+		cache.Reset()
+		_, _, err := handle(sequential, cache, tx)
+		assert.NoError(t, err)
+	}
+
+	store, _ = leveldbstore.NewMemLevelDBStore()
+	parallel := overlaydb.NewOverlayDB(store)
+	notifies, _, err := executeParallel(parallel, store, txs, 3, handle)
+	assert.NoError(t, err)
+	assert.Len(t, notifies, len(txs))
+	assert.Equal(t, sequential.ChangeHash(), parallel.ChangeHash())
+}
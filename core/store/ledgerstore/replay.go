@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/storage"
+)
+
+//ReplayResult is the outcome of re-executing a historical transaction
+type ReplayResult struct {
+	Height  uint32
+	TxHash  common.Uint256
+	Error   string
+	Changes []*types.StorageChange
+	Notify  *event.ExecuteNotify
+}
+
+//ReplayTransaction re-executes the transaction txHash of block height against the state at height-1.
+//
+//The state store only keeps the latest state, so the state is rebuilt in a sandbox ledger at sandboxDir
+//from the genesis bookkeepers by executing the blocks of this ledger from genesis to height-1, then the transactions before txHash in
+//the block are applied and txHash is executed on top of them. Nothing is written to this ledger.
+func (this *LedgerStoreImp) ReplayTransaction(height uint32, txHash common.Uint256, sandboxDir string,
+	defaultBookkeeper []keypair.PublicKey) (*ReplayResult, error) {
+	if height == 0 {
+		return nil, fmt.Errorf("genesis block can not be replayed")
+	}
+	block, err := this.GetBlockByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockByHeight %d error %s", height, err)
+	}
+	index := -1
+	for i, tx := range block.Transactions {
+		if tx.Hash() == txHash {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("tx %s is not in block of height %d", txHash.ToHexString(), height)
+	}
+
+	sandbox, err := this.buildSandbox(height-1, sandboxDir, defaultBookkeeper)
+	if err != nil {
+		return nil, err
+	}
+	defer sandbox.Close()
+
+	before := sandbox.stateStore.NewOverlayDB()
+	if err = sandbox.applyTransactions(before, block, block.Transactions[:index]); err != nil {
+		return nil, err
+	}
+	after := sandbox.stateStore.NewOverlayDB()
+	if err = sandbox.applyTransactions(after, block, block.Transactions[:index]); err != nil {
+		return nil, err
+	}
+	cache := storage.NewCacheDB(after)
+	notify := &event.ExecuteNotify{TxHash: txHash, State: event.CONTRACT_STATE_FAIL}
+	result := &ReplayResult{Height: height, TxHash: txHash, Notify: notify}
+	_, err = sandbox.stateStore.HandleInvokeTransaction(sandbox, after, cache, block.Transactions[index], block, notify)
+	if after.Error() != nil {
+		return nil, fmt.Errorf("replay tx %s error %s", txHash.ToHexString(), after.Error())
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	cache.ForEach(func(key, val []byte) {
+		old, _ := before.Get(key)
+		if change := newStorageChange(key, old, val); change != nil {
+			result.Changes = append(result.Changes, change)
+		}
+	})
+	return result, nil
+}
+
+//buildSandbox creates a ledger at dir holding the state of this ledger at height
+func (this *LedgerStoreImp) buildSandbox(height uint32, dir string, defaultBookkeeper []keypair.PublicKey) (*LedgerStoreImp, error) {
+	if height > this.GetCurrentBlockHeight() {
+		return nil, fmt.Errorf("height %d is above current block height %d", height, this.GetCurrentBlockHeight())
+	}
+	genesisBlock, err := this.GetBlockByHeight(0)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockByHeight 0 error %s", err)
+	}
+	sandbox, err := NewLedgerStore(dir)
+	if err != nil {
+		return nil, fmt.Errorf("NewLedgerStore error %s", err)
+	}
+	if _, err = sandbox.blockStore.GetVersion(); err != scom.ErrNotFound {
+		sandbox.Close()
+		return nil, fmt.Errorf("sandbox dir %s is not empty", dir)
+	}
+	err = sandbox.InitLedgerStoreWithGenesisBlock(genesisBlock, defaultBookkeeper)
+	if err != nil {
+		sandbox.Close()
+		return nil, fmt.Errorf("InitLedgerStoreWithGenesisBlock error %s", err)
+	}
+	for h := uint32(1); h <= height; h++ {
+		block, err := this.GetBlockByHeight(h)
+		if err != nil {
+			sandbox.Close()
+			return nil, fmt.Errorf("GetBlockByHeight %d error %s", h, err)
+		}
+		result, err := sandbox.ExecuteBlock(block)
+		if err != nil {
+			sandbox.Close()
+			return nil, fmt.Errorf("execute block %d error %s", h, err)
+		}
+		if err = sandbox.SubmitBlock(block, result); err != nil {
+			sandbox.Close()
+			return nil, fmt.Errorf("submit block %d error %s", h, err)
+		}
+	}
+	return sandbox, nil
+}
+
+func (this *LedgerStoreImp) applyTransactions(overlay *overlaydb.OverlayDB, block *types.Block, txs []*types.Transaction) error {
+	cache := storage.NewCacheDB(overlay)
+	for _, tx := range txs {
+		cache.Reset()
+		if _, _, err := this.handleTransaction(overlay, cache, block, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayTransactionRejects(t *testing.T) {
+	_, err := testLedgerStore.ReplayTransaction(0, common.UINT256_EMPTY, "test/replay", nil)
+	assert.Error(t, err, "genesis block should not be replayed")
+
+	height := testLedgerStore.GetCurrentBlockHeight() + 1
+	_, err = testLedgerStore.ReplayTransaction(height, common.UINT256_EMPTY, "test/replay", nil)
+	assert.Error(t, err, "block above current height should not be replayed")
+}
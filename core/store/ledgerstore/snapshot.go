@@ -0,0 +1,357 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/serialization"
+	"github.com/polynetwork/poly/consensus/vbft/config"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/merkle"
+)
+
+const (
+	SNAPSHOT_VERSION          = byte(1)
+	SNAPSHOT_ENTRY_BATCH_SIZE = 10000 //Count of state entries committed in one batch while importing
+)
+
+//SnapshotManifest describes a state snapshot taken at block Height.
+//
+//A snapshot carries the whole state store, the block hashes from genesis to Height and the blocks
+//a node needs to start from Height: the genesis block, the last vbft config block and the block at Height.
+//On import the block hash at Height must match the trusted one, the hash list must rebuild the
+//BlockRoot of that block and the cross states root of Height must match its header.
+//The rest of the state is not committed by any header, so it is only checked against EntriesHash,
+//which should be compared with the one reported by a trusted node.
+type SnapshotManifest struct {
+	Version     byte
+	Height      uint32
+	BlockHash   common.Uint256
+	EntryCount  uint64
+	EntriesHash common.Uint256
+}
+
+//ExportSnapshot write the snapshot of current block to w. The node must be stopped.
+func (this *LedgerStoreImp) ExportSnapshot(w io.Writer) (*SnapshotManifest, error) {
+	blockHash, height, err := this.blockStore.GetCurrentBlock()
+	if err != nil {
+		return nil, fmt.Errorf("blockStore.GetCurrentBlock error %s", err)
+	}
+	stateHash, stateHeight, err := this.stateStore.GetCurrentBlock()
+	if err != nil {
+		return nil, fmt.Errorf("stateStore.GetCurrentBlock error %s", err)
+	}
+	if stateHeight != height || stateHash != blockHash {
+		return nil, fmt.Errorf("state store at height %d is behind block store at height %d, start the node once to recover it", stateHeight, height)
+	}
+	manifest := &SnapshotManifest{
+		Version:   SNAPSHOT_VERSION,
+		Height:    height,
+		BlockHash: blockHash,
+	}
+	if err := serialization.WriteByte(w, manifest.Version); err != nil {
+		return nil, err
+	}
+	if err := serialization.WriteUint32(w, manifest.Height); err != nil {
+		return nil, err
+	}
+	if err := manifest.BlockHash.Serialize(w); err != nil {
+		return nil, err
+	}
+
+	hashes := make([]common.Uint256, 0, height+1)
+	for i := uint32(0); i <= height; i++ {
+		hash, err := this.blockStore.GetBlockHash(i)
+		if err != nil {
+			return nil, fmt.Errorf("GetBlockHash height %d error %s", i, err)
+		}
+		if err := hash.Serialize(w); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	block, err := this.blockStore.GetBlock(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlock height %d error %s", height, err)
+	}
+	heights, err := snapshotBlockHeights(block.Header)
+	if err != nil {
+		return nil, err
+	}
+	if err := serialization.WriteVarUint(w, uint64(len(heights))); err != nil {
+		return nil, err
+	}
+	for _, h := range heights {
+		blk, err := this.blockStore.GetBlock(hashes[h])
+		if err != nil {
+			return nil, fmt.Errorf("GetBlock height %d error %s", h, err)
+		}
+		if err := serialization.WriteVarBytes(w, blk.ToArray()); err != nil {
+			return nil, err
+		}
+	}
+
+	digest := sha256.New()
+	entryWriter := io.MultiWriter(w, digest)
+	iter := this.stateStore.store.NewIterator(nil)
+	defer iter.Release()
+	for iter.Next() {
+		if err := serialization.WriteBool(w, true); err != nil {
+			return nil, err
+		}
+		if err := serialization.WriteVarBytes(entryWriter, iter.Key()); err != nil {
+			return nil, err
+		}
+		if err := serialization.WriteVarBytes(entryWriter, iter.Value()); err != nil {
+			return nil, err
+		}
+		manifest.EntryCount++
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("iterate state store error %s", err)
+	}
+	copy(manifest.EntriesHash[:], digest.Sum(nil))
+	if err := serialization.WriteBool(w, false); err != nil {
+		return nil, err
+	}
+	if err := serialization.WriteUint64(w, manifest.EntryCount); err != nil {
+		return nil, err
+	}
+	if err := manifest.EntriesHash.Serialize(w); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+//ImportSnapshot restore the ledger from the snapshot read from r, the block hash of the snapshot must be trustedHash.
+//It is the first and only operation after NewLedgerStore on an empty data dir,
+//the node starts from the snapshot height at next launch.
+func (this *LedgerStoreImp) ImportSnapshot(r io.Reader, trustedHash common.Uint256) (*SnapshotManifest, error) {
+	_, err := this.blockStore.GetVersion()
+	if err == nil {
+		return nil, fmt.Errorf("ledger has been initialized, snapshot can only be imported into an empty data dir")
+	}
+	if err != scom.ErrNotFound {
+		return nil, fmt.Errorf("GetVersion error %s", err)
+	}
+
+	manifest := new(SnapshotManifest)
+	manifest.Version, err = serialization.ReadByte(r)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot version error %s", err)
+	}
+	if manifest.Version != SNAPSHOT_VERSION {
+		return nil, fmt.Errorf("unsupported snapshot version %d", manifest.Version)
+	}
+	manifest.Height, err = serialization.ReadUint32(r)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot height error %s", err)
+	}
+	if err = manifest.BlockHash.Deserialize(r); err != nil {
+		return nil, fmt.Errorf("read snapshot block hash error %s", err)
+	}
+	if manifest.BlockHash != trustedHash {
+		return nil, fmt.Errorf("snapshot block hash %s mismatch trusted hash %s", manifest.BlockHash.ToHexString(), trustedHash.ToHexString())
+	}
+	height := manifest.Height
+
+	hashes := make([]common.Uint256, height+1)
+	for i := range hashes {
+		if err = hashes[i].Deserialize(r); err != nil {
+			return nil, fmt.Errorf("read block hash of height %d error %s", i, err)
+		}
+	}
+	if hashes[height] != manifest.BlockHash {
+		return nil, fmt.Errorf("block hash of height %d mismatch snapshot block hash", height)
+	}
+
+	count, err := serialization.ReadVarUint(r, uint64(height)+1)
+	if err != nil {
+		return nil, fmt.Errorf("read block count error %s", err)
+	}
+	blocks := make(map[uint32]*types.Block, count)
+	for i := uint64(0); i < count; i++ {
+		raw, err := serialization.ReadVarBytes(r)
+		if err != nil {
+			return nil, fmt.Errorf("read No.%d block error %s", i, err)
+		}
+		block, err := types.BlockFromRawBytes(raw)
+		if err != nil {
+			return nil, fmt.Errorf("deserialize No.%d block error %s", i, err)
+		}
+		h := block.Header.Height
+		if h > height || block.Hash() != hashes[h] {
+			return nil, fmt.Errorf("block of height %d is not in the chain of snapshot", h)
+		}
+		blocks[h] = block
+	}
+	top, ok := blocks[height]
+	if !ok {
+		return nil, fmt.Errorf("block of height %d is missing", height)
+	}
+	genesis, ok := blocks[0]
+	if !ok {
+		return nil, fmt.Errorf("genesis block is missing")
+	}
+
+	//leaves of block merkle tree are the previous block hashes of block 0 to height
+	leaves := make([]common.Uint256, 0, height+1)
+	leaves = append(leaves, genesis.Header.PrevBlockHash)
+	leaves = append(leaves, hashes[:height]...)
+	tree, err := this.stateStore.rebuildBlockMerkleTree(leaves)
+	if err != nil {
+		return nil, err
+	}
+	if height > 0 && tree.Root() != top.Header.BlockRoot {
+		return nil, fmt.Errorf("block hashes mismatch block root of height %d", height)
+	}
+
+	digest := sha256.New()
+	entryReader := io.TeeReader(r, digest)
+	this.stateStore.NewBatch()
+	for {
+		more, err := serialization.ReadBool(r)
+		if err != nil {
+			return nil, fmt.Errorf("read state entry error %s", err)
+		}
+		if !more {
+			break
+		}
+		key, err := serialization.ReadVarBytes(entryReader)
+		if err != nil {
+			return nil, fmt.Errorf("read state entry key error %s", err)
+		}
+		value, err := serialization.ReadVarBytes(entryReader)
+		if err != nil {
+			return nil, fmt.Errorf("read state entry value error %s", err)
+		}
+		this.stateStore.BatchPutRawKeyVal(key, value)
+		manifest.EntryCount++
+		if manifest.EntryCount%SNAPSHOT_ENTRY_BATCH_SIZE == 0 {
+			if err = this.stateStore.CommitTo(); err != nil {
+				return nil, fmt.Errorf("stateStore.CommitTo error %s", err)
+			}
+			this.stateStore.NewBatch()
+		}
+	}
+	if err = this.stateStore.CommitTo(); err != nil {
+		return nil, fmt.Errorf("stateStore.CommitTo error %s", err)
+	}
+	copy(manifest.EntriesHash[:], digest.Sum(nil))
+	entryCount, err := serialization.ReadUint64(r)
+	if err != nil {
+		return nil, fmt.Errorf("read entry count error %s", err)
+	}
+	var entriesHash common.Uint256
+	if err = entriesHash.Deserialize(r); err != nil {
+		return nil, fmt.Errorf("read entries hash error %s", err)
+	}
+	if entryCount != manifest.EntryCount || entriesHash != manifest.EntriesHash {
+		return nil, fmt.Errorf("state entries are corrupted")
+	}
+
+	if err = this.verifySnapshotState(top, tree); err != nil {
+		return nil, err
+	}
+
+	this.blockStore.NewBatch()
+	for i, hash := range hashes {
+		this.blockStore.SaveBlockHash(uint32(i), hash)
+	}
+	for start := uint32(0); start+HEADER_INDEX_BATCH_SIZE <= height; start += HEADER_INDEX_BATCH_SIZE {
+		err = this.blockStore.SaveHeaderIndexList(start, hashes[start:start+HEADER_INDEX_BATCH_SIZE])
+		if err != nil {
+			return nil, fmt.Errorf("SaveHeaderIndexList start %d error %s", start, err)
+		}
+	}
+	for h, block := range blocks {
+		if err = this.blockStore.SaveBlock(block); err != nil {
+			return nil, fmt.Errorf("SaveBlock height %d error %s", h, err)
+		}
+	}
+	if err = this.blockStore.SaveCurrentBlock(height, manifest.BlockHash); err != nil {
+		return nil, fmt.Errorf("SaveCurrentBlock error %s", err)
+	}
+	if err = this.blockStore.CommitTo(); err != nil {
+		return nil, fmt.Errorf("blockStore.CommitTo error %s", err)
+	}
+	this.eventStore.NewBatch()
+	if err = this.eventStore.SaveCurrentBlock(height, manifest.BlockHash); err != nil {
+		return nil, fmt.Errorf("eventStore.SaveCurrentBlock error %s", err)
+	}
+	if err = this.eventStore.CommitTo(); err != nil {
+		return nil, fmt.Errorf("eventStore.CommitTo error %s", err)
+	}
+	//version is saved at last, a partly imported data dir is cleared at next launch
+	if err = this.initGenesisBlock(); err != nil {
+		return nil, fmt.Errorf("init error %s", err)
+	}
+	return manifest, nil
+}
+
+//verifySnapshotState check the imported state store is at the snapshot block
+func (this *LedgerStoreImp) verifySnapshotState(top *types.Block, tree *merkle.CompactMerkleTree) error {
+	blockHash, height, err := this.stateStore.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("stateStore.GetCurrentBlock error %s", err)
+	}
+	if height != top.Header.Height || blockHash != top.Hash() {
+		return fmt.Errorf("state store is at height %d other than snapshot height %d", height, top.Header.Height)
+	}
+	treeSize, treeHashes, err := this.stateStore.GetBlockMerkleTree()
+	if err != nil {
+		return fmt.Errorf("GetBlockMerkleTree error %s", err)
+	}
+	if treeSize != tree.TreeSize() || merkle.NewTree(treeSize, treeHashes, nil).Root() != tree.Root() {
+		return fmt.Errorf("block merkle tree of state store mismatch block hashes")
+	}
+	crossStatesRoot, err := this.stateStore.GetCrossStateRoot(height)
+	if err != nil {
+		return fmt.Errorf("GetCrossStateRoot error %s", err)
+	}
+	if crossStatesRoot != top.Header.CrossStateRoot {
+		return fmt.Errorf("cross states root mismatch header of height %d", height)
+	}
+	return nil
+}
+
+//snapshotBlockHeights return the heights of blocks carried by the snapshot of the block with header
+func snapshotBlockHeights(header *types.Header) ([]uint32, error) {
+	heights := []uint32{0}
+	if strings.ToLower(config.DefConfig.Genesis.ConsensusType) == "vbft" && header.Height > 0 {
+		blkInfo, err := vconfig.VbftBlock(header)
+		if err != nil {
+			return nil, err
+		}
+		if blkInfo.NewChainConfig == nil && blkInfo.LastConfigBlockNum > 0 && blkInfo.LastConfigBlockNum < header.Height {
+			heights = append(heights, blkInfo.LastConfigBlockNum)
+		}
+	}
+	if header.Height > 0 {
+		heights = append(heights, header.Height)
+	}
+	return heights, nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	manifest, err := testLedgerStore.ExportSnapshot(buf)
+	if err != nil {
+		t.Fatalf("ExportSnapshot error %s", err)
+	}
+	assert.Equal(t, testLedgerStore.GetCurrentBlockHash(), manifest.BlockHash)
+	raw := buf.Bytes()
+
+	store, err := NewLedgerStore("test/snapshot")
+	if err != nil {
+		t.Fatalf("NewLedgerStore error %s", err)
+	}
+	defer store.Close()
+
+	_, err = store.ImportSnapshot(bytes.NewReader(raw), common.UINT256_EMPTY)
+	assert.Error(t, err, "snapshot of untrusted hash should be rejected")
+
+	tampered := make([]byte, len(raw))
+	copy(tampered, raw)
+	tampered[len(tampered)-common.UINT256_SIZE-10] ^= 0xff
+	_, err = store.ImportSnapshot(bytes.NewReader(tampered), manifest.BlockHash)
+	assert.Error(t, err, "snapshot with corrupted entries should be rejected")
+
+	imported, err := store.ImportSnapshot(bytes.NewReader(raw), manifest.BlockHash)
+	if err != nil {
+		t.Fatalf("ImportSnapshot error %s", err)
+	}
+	assert.Equal(t, manifest, imported)
+
+	blockHash, height, err := store.blockStore.GetCurrentBlock()
+	assert.Nil(t, err)
+	assert.Equal(t, manifest.Height, height)
+	assert.Equal(t, manifest.BlockHash, blockHash)
+	bookkeeper, err := store.stateStore.GetBookkeeperState()
+	assert.Nil(t, err)
+	expected, err := testLedgerStore.stateStore.GetBookkeeperState()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, bookkeeper)
+
+	_, err = store.ImportSnapshot(bytes.NewReader(raw), manifest.BlockHash)
+	assert.Error(t, err, "snapshot should not be imported into an initialized ledger")
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/events"
+	"github.com/polynetwork/poly/events/message"
+)
+
+func stateDiffEnabled() bool {
+	return config.DefConfig.Common.StateDiff || config.DefConfig.Common.StateDiffDir != ""
+}
+
+//newStorageChange splits a raw state key of contract storage into contract and key, returns nil for other keys
+func newStorageChange(key, old, val []byte) *types.StorageChange {
+	if len(key) < 1+common.ADDR_LEN || key[0] != byte(scom.ST_STORAGE) {
+		return nil
+	}
+	contract, _ := common.AddressParseFromBytes(key[1 : 1+common.ADDR_LEN])
+	return &types.StorageChange{
+		Contract: contract,
+		Key:      append([]byte{}, key[1+common.ADDR_LEN:]...),
+		Old:      append([]byte{}, old...),
+		New:      append([]byte{}, val...),
+	}
+}
+
+//getStateDiff collects the contract storage changes of block, it must be called before the write set is committed
+func (this *LedgerStoreImp) getStateDiff(block *types.Block, writeSet *overlaydb.MemDB) (*types.StateDiff, error) {
+	diff := &types.StateDiff{
+		Height:    block.Header.Height,
+		BlockHash: block.Hash(),
+		Changes:   make([]*types.StorageChange, 0),
+	}
+	var err error
+	writeSet.ForEach(func(key, val []byte) {
+		if err != nil {
+			return
+		}
+		old, e := this.stateStore.store.Get(key)
+		if e != nil && e != scom.ErrNotFound {
+			err = fmt.Errorf("get state of key %x error %s", key, e)
+			return
+		}
+		if change := newStorageChange(key, old, val); change != nil {
+			diff.Changes = append(diff.Changes, change)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+//exportStateDiff publishes the state diff of a saved block and writes it to the state diff dir.
+//The block is already saved, so errors are only logged.
+func exportStateDiff(diff *types.StateDiff) {
+	if config.DefConfig.Common.StateDiff && events.DefActorPublisher != nil {
+		events.DefActorPublisher.Publish(message.TOPIC_STATE_DIFF, &message.StateDiffMsg{Diff: diff})
+	}
+	dir := config.DefConfig.Common.StateDiffDir
+	if dir == "" {
+		return
+	}
+	if err := writeStateDiff(dir, diff); err != nil {
+		log.Errorf("write state diff of block %d error %s", diff.Height, err)
+	}
+}
+
+//writeStateDiff writes diff to <dir>/<height>.diff, the file appears after it's completely written
+func writeStateDiff(dir string, diff *types.StateDiff) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	sink := common.NewZeroCopySink(nil)
+	diff.Serialization(sink)
+	path := fmt.Sprintf("%s%s%d.diff", dir, string(os.PathSeparator), diff.Height)
+	if err := ioutil.WriteFile(path+".tmp", sink.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
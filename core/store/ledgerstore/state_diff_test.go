@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStorageChange(t *testing.T) {
+	contract := common.Address{1, 2, 3}
+	key := append([]byte{byte(scom.ST_STORAGE)}, contract[:]...)
+	key = append(key, []byte("key")...)
+	change := newStorageChange(key, nil, []byte("new"))
+	assert.Equal(t, contract, change.Contract)
+	assert.Equal(t, []byte("key"), change.Key)
+	assert.Equal(t, []byte{}, change.Old)
+	assert.Equal(t, []byte("new"), change.New)
+
+	key[0] = byte(scom.SYS_CURRENT_BLOCK)
+	assert.Nil(t, newStorageChange(key, nil, []byte("new")), "non storage key should be skipped")
+	assert.Nil(t, newStorageChange([]byte{byte(scom.ST_STORAGE)}, nil, nil), "short key should be skipped")
+}
+
+func TestWriteStateDiff(t *testing.T) {
+	diff := &types.StateDiff{
+		Height:    3,
+		BlockHash: common.Uint256{3},
+		Changes: []*types.StorageChange{
+			{Contract: common.Address{1}, Key: []byte("key"), Old: []byte{}, New: []byte("new")},
+		},
+	}
+	err := writeStateDiff("test/statediff", diff)
+	assert.Nil(t, err)
+
+	raw, err := ioutil.ReadFile("test/statediff/3.diff")
+	assert.Nil(t, err)
+	decoded := new(types.StateDiff)
+	err = decoded.Deserialization(common.NewZeroCopySource(raw))
+	assert.Nil(t, err)
+	assert.Equal(t, diff, decoded)
+}
@@ -23,6 +23,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"path/filepath"
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
@@ -47,6 +48,10 @@ type StateStore struct {
 	deltaMerkleTree      *merkle.CompactMerkleTree //Merkle tree of delta state root
 	merkleHashStore      merkle.HashStore
 	stateHashCheckHeight uint32
+
+	crossStatesAccPath      string                    //Cross states accumulator store path
+	crossStatesAccTree      *merkle.CompactMerkleTree //Accumulator of the cross states of all blocks
+	crossStatesAccHashStore merkle.HashStore
 }
 
 //NewStateStore return state store instance
@@ -60,6 +65,8 @@ func NewStateStore(dbDir, merklePath string) (*StateStore, error) {
 		dbDir:      dbDir,
 		store:      store,
 		merklePath: merklePath,
+
+		crossStatesAccPath: filepath.Join(filepath.Dir(merklePath), CrossStatesAccStorePath),
 	}
 	_, height, err := stateStore.GetCurrentBlock()
 	if err != nil && err != scom.ErrNotFound {
@@ -80,6 +87,7 @@ func NewMemStateStore(stateHashHeight uint32) *StateStore {
 		merkleTree:           merkle.NewTree(0, nil, nil),
 		deltaMerkleTree:      merkle.NewTree(0, nil, nil),
 		stateHashCheckHeight: stateHashHeight,
+		crossStatesAccTree:   merkle.NewTree(0, nil, merkle.NewMemHashStore()),
 	}
 
 	return stateStore
@@ -122,6 +130,16 @@ func (self *StateStore) init(currBlockHeight uint32) error {
 		}
 		self.deltaMerkleTree = merkle.NewTree(treeSize, hashes, nil)
 	}
+
+	treeSize, hashes, err = self.getMerkleTree(genCrossStatesAccTreeKey())
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	self.crossStatesAccHashStore, err = merkle.NewFileHashStore(self.crossStatesAccPath, treeSize)
+	if err != nil {
+		log.Warn("cross states accumulator store is inconsistent with ChainStore. proof will be unavailable")
+	}
+	self.crossStatesAccTree = merkle.NewTree(treeSize, hashes, self.crossStatesAccHashStore)
 	return nil
 }
 
@@ -269,6 +287,94 @@ func (self *StateStore) GetCrossStates(height uint32) (hashes []common.Uint256,
 	return
 }
 
+//AddReceipts saves the notify hashes of the transactions of block at height
+func (self *StateStore) AddReceipts(height uint32, receipts []common.Uint256) {
+	sink := common.NewZeroCopySink(make([]byte, 0, len(receipts)*common.UINT256_SIZE))
+	for _, v := range receipts {
+		sink.WriteHash(v)
+	}
+	self.store.BatchPut(genReceiptsKey(height), sink.Bytes())
+}
+
+//GetReceipts returns the notify hashes of the transactions of block at height
+func (self *StateStore) GetReceipts(height uint32) ([]common.Uint256, error) {
+	value, err := self.store.Get(genReceiptsKey(height))
+	if err != nil {
+		return nil, err
+	}
+	source := common.NewZeroCopySource(value)
+	receipts := make([]common.Uint256, 0, source.Size()/common.UINT256_SIZE)
+	for source.Len() > 0 {
+		hash, eof := source.NextHash()
+		if eof {
+			return nil, io.ErrUnexpectedEOF
+		}
+		receipts = append(receipts, hash)
+	}
+	return receipts, nil
+}
+
+//AddCrossStatesAcc appends the cross states of block to the accumulator and saves its size and root at height
+func (self *StateStore) AddCrossStatesAcc(height uint32, crossStates []common.Uint256) error {
+	hashStore := self.crossStatesAccHashStore
+	if hashStore != nil {
+		self.crossStatesAccTree = merkle.NewTree(self.crossStatesAccTree.TreeSize(), self.crossStatesAccTree.Hashes(),
+			lazyFlushHashStore{hashStore})
+	}
+	for _, v := range crossStates {
+		self.crossStatesAccTree.AppendHash(v)
+	}
+	if hashStore != nil {
+		if err := hashStore.Flush(); err != nil {
+			return fmt.Errorf("flush cross states accumulator store error %s", err)
+		}
+		self.crossStatesAccTree = merkle.NewTree(self.crossStatesAccTree.TreeSize(), self.crossStatesAccTree.Hashes(), hashStore)
+	}
+
+	treeSize := self.crossStatesAccTree.TreeSize()
+	hashes := self.crossStatesAccTree.Hashes()
+	value := common.NewZeroCopySink(make([]byte, 0, 4+len(hashes)*common.UINT256_SIZE))
+	value.WriteUint32(treeSize)
+	for _, hash := range hashes {
+		value.WriteHash(hash)
+	}
+	self.store.BatchPut(genCrossStatesAccTreeKey(), value.Bytes())
+
+	value.Reset()
+	value.WriteUint32(treeSize)
+	value.WriteHash(self.crossStatesAccTree.Root())
+	self.store.BatchPut(genCrossStatesAccRootKey(height), value.Bytes())
+	return nil
+}
+
+//GetCrossStatesAcc return the size and root of the cross states accumulator after the block at height,
+//the accumulator is empty before it is activated
+func (self *StateStore) GetCrossStatesAcc(height uint32) (uint32, common.Uint256, error) {
+	value, err := self.store.Get(genCrossStatesAccRootKey(height))
+	if err == scom.ErrNotFound {
+		return 0, merkle.NewTree(0, nil, nil).Root(), nil
+	}
+	if err != nil {
+		return 0, common.UINT256_EMPTY, err
+	}
+	source := common.NewZeroCopySource(value)
+	treeSize, eof := source.NextUint32()
+	root, eof := source.NextHash()
+	if eof {
+		return 0, common.UINT256_EMPTY, io.ErrUnexpectedEOF
+	}
+	return treeSize, root, nil
+}
+
+func (self *StateStore) GetCrossStatesAccRootWithNewLeafHashes(crossStates []common.Uint256) common.Uint256 {
+	return self.crossStatesAccTree.GetRootWithNewLeafHashes(crossStates)
+}
+
+//GetCrossStatesAccProof return merkle proof of the No.m cross state in the accumulator of size n
+func (self *StateStore) GetCrossStatesAccProof(raw []byte, m, n uint32) ([]byte, error) {
+	return self.crossStatesAccTree.MerkleInclusionLeafPath(raw, m, n)
+}
+
 //AddBlockMerkleTreeRoot add a new tree root
 func (self *StateStore) AddBlockMerkleTreeRoot(preBlockHash common.Uint256) error {
 	key := self.genBlockMerkleTreeKey()
@@ -285,6 +391,30 @@ func (self *StateStore) AddBlockMerkleTreeRoot(preBlockHash common.Uint256) erro
 	return nil
 }
 
+//rebuildBlockMerkleTree rebuild the block merkle tree and its hash store from leaves, the hash store is flushed once at last
+func (self *StateStore) rebuildBlockMerkleTree(leaves []common.Uint256) (*merkle.CompactMerkleTree, error) {
+	if self.merkleHashStore == nil {
+		return nil, fmt.Errorf("merkle hash store is not available")
+	}
+	tree := merkle.NewTree(0, nil, lazyFlushHashStore{self.merkleHashStore})
+	for _, leaf := range leaves {
+		tree.Append(leaf.ToArray())
+	}
+	if err := self.merkleHashStore.Flush(); err != nil {
+		return nil, fmt.Errorf("flush merkle hash store error %s", err)
+	}
+	self.merkleTree = merkle.NewTree(tree.TreeSize(), tree.Hashes(), self.merkleHashStore)
+	return self.merkleTree, nil
+}
+
+type lazyFlushHashStore struct {
+	merkle.HashStore
+}
+
+func (self lazyFlushHashStore) Flush() error {
+	return nil
+}
+
 //GetMerkleProof return merkle proof of block hash
 func (self *StateStore) GetMerkleProof(raw []byte, proofHeight, rootHeight uint32) ([]byte, error) {
 	return self.merkleTree.MerkleInclusionLeafPath(raw, proofHeight, rootHeight+1)
@@ -455,6 +585,24 @@ func genCrossStatesRootKey(height uint32) []byte {
 	return key
 }
 
+func genReceiptsKey(height uint32) []byte {
+	key := make([]byte, 5, 5)
+	key[0] = byte(scom.SYS_RECEIPTS)
+	binary.LittleEndian.PutUint32(key[1:], height)
+	return key
+}
+
+func genCrossStatesAccTreeKey() []byte {
+	return []byte{byte(scom.SYS_CROSS_STATES_ACC_TREE)}
+}
+
+func genCrossStatesAccRootKey(height uint32) []byte {
+	key := make([]byte, 5, 5)
+	key[0] = byte(scom.SYS_CROSS_STATES_ACC_ROOT)
+	binary.LittleEndian.PutUint32(key[1:], height)
+	return key
+}
+
 func (self *StateStore) genStateMerkleRootKey(height uint32) []byte {
 	key := make([]byte, 5, 5)
 	key[0] = byte(scom.DATA_STATE_MERKLE_ROOT)
@@ -480,5 +628,8 @@ func (self *StateStore) ClearAll() error {
 //Close state store
 func (self *StateStore) Close() error {
 	self.merkleHashStore.Close()
+	if self.crossStatesAccHashStore != nil {
+		self.crossStatesAccHashStore.Close()
+	}
 	return self.store.Close()
 }
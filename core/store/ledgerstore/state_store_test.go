@@ -24,6 +24,7 @@ import (
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/merkle"
+	"github.com/polynetwork/poly/native/event"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -71,3 +72,72 @@ func TestStateMerkleRoot(t *testing.T) {
 	}
 
 }
+
+func TestCrossStatesAcc(t *testing.T) {
+	db := NewMemStateStore(0)
+	size, root, err := db.GetCrossStatesAcc(0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), size)
+	assert.Equal(t, merkle.NewTree(0, nil, nil).Root(), root)
+
+	var states [][]byte
+	for height := uint32(1); height <= 50; height++ {
+		crossHashes := make([]common.Uint256, 0)
+		for i := rand.Intn(4); i > 0; i-- {
+			state := make([]byte, 32)
+			rand.Read(state)
+			states = append(states, state)
+			crossHashes = append(crossHashes, merkle.HashLeaf(state))
+		}
+		root1 := db.GetCrossStatesAccRootWithNewLeafHashes(crossHashes)
+		db.NewBatch()
+		err := db.AddCrossStatesAcc(height, crossHashes)
+		assert.Nil(t, err)
+		db.CommitTo()
+		size, root2, err := db.GetCrossStatesAcc(height)
+		assert.Nil(t, err)
+		assert.Equal(t, root1, root2)
+		assert.Equal(t, uint32(len(states)), size)
+
+		for i, state := range states {
+			proof, err := db.GetCrossStatesAccProof(state, uint32(i), size)
+			assert.Nil(t, err)
+			value, err := merkle.MerkleProve(proof, root2[:])
+			assert.Nil(t, err)
+			assert.Equal(t, state, value)
+		}
+	}
+}
+
+func TestReceipts(t *testing.T) {
+	db := NewMemStateStore(0)
+	notify := &event.ExecuteNotify{
+		State: event.CONTRACT_STATE_SUCCESS,
+		Notify: []*event.NotifyEventInfo{
+			{ContractAddress: common.Address{1}, States: []interface{}{"makeProof", "01"}},
+			{ContractAddress: common.Address{2}, States: []interface{}{"btcTxToRelay", uint64(2)}},
+		},
+	}
+	hash1, err := notify.Hash()
+	assert.Nil(t, err)
+	notify.Notify[0], notify.Notify[1] = notify.Notify[1], notify.Notify[0]
+	hash2, err := notify.Hash()
+	assert.Nil(t, err)
+	assert.NotEqual(t, hash1, hash2, "the order of notifications should be committed")
+
+	receipts := []common.Uint256{hash1, hash2}
+	db.NewBatch()
+	db.AddReceipts(10, receipts)
+	db.CommitTo()
+	got, err := db.GetReceipts(10)
+	assert.Nil(t, err)
+	assert.Equal(t, receipts, got)
+
+	leaves := []common.Uint256{merkle.HashLeaf(hash1[:]), merkle.HashLeaf(hash2[:])}
+	root := merkle.TreeHasher{}.HashFullTreeWithLeafHash(leaves)
+	proof, err := merkle.MerkleLeafPath(hash2[:], leaves)
+	assert.Nil(t, err)
+	value, err := merkle.MerkleProve(proof, root[:])
+	assert.Nil(t, err)
+	assert.Equal(t, hash2[:], value)
+}
@@ -41,7 +41,21 @@ func (self *StateStore) HandleInvokeTransaction(store store.LedgerStore, overlay
 	if err != nil {
 		return nil, fmt.Errorf("HandleInvokeTransaction Error: %+v\n", err)
 	}
+	if block.Header.Height == 0 {
+		service.SetGenesis()
+	}
 	if _, err := service.Invoke(); err != nil {
+		// drop the writes of the failed invocation and keep the ones of the failure hook, which only runs
+		// from its activation height as the failed txs wrote nothing before
+		cache.Reset()
+		if block.Header.Height < config.GetFailureHookHeight(config.DefConfig.P2PNode.NetworkId) {
+			return nil, err
+		}
+		failed, e := native.NewNativeService(cache, tx, block.Header.Timestamp, block.Header.Height,
+			block.Hash(), block.Header.ChainID, invoke.Code, false)
+		if e == nil && failed.InvokeFailureHook(err) {
+			cache.Commit()
+		}
 		return nil, err
 	}
 	notify.Notify = append(notify.Notify, service.GetNotify()...)
@@ -60,3 +74,33 @@ func SaveNotify(eventStore scommon.EventStore, txHash common.Uint256, notify *ev
 	event.PushSmartCodeEvent(txHash, 0, event.EVENT_NOTIFY, notify)
 	return nil
 }
+
+// SaveChainIndex indexes the transactions of the block at height by the chains their events are bound to
+func SaveChainIndex(eventStore scommon.EventStore, height uint32, notifies []*event.ExecuteNotify) error {
+	if !config.DefConfig.Common.EnableEventLog {
+		return nil
+	}
+	var chainIDs []uint64
+	txHashs := make(map[uint64][]common.Uint256)
+	for _, notify := range notifies {
+		bound := make(map[uint64]bool)
+		for _, v := range notify.Notify {
+			for _, id := range v.ChainIDs() {
+				if bound[id] {
+					continue
+				}
+				bound[id] = true
+				if _, ok := txHashs[id]; !ok {
+					chainIDs = append(chainIDs, id)
+				}
+				txHashs[id] = append(txHashs[id], notify.TxHash)
+			}
+		}
+	}
+	for _, id := range chainIDs {
+		if err := eventStore.SaveEventNotifyByChain(id, height, txHashs[id]); err != nil {
+			return fmt.Errorf("SaveEventNotifyByChain error %s", err)
+		}
+	}
+	return nil
+}
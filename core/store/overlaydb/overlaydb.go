@@ -36,9 +36,14 @@ const initCap = 4 * 1024 * 1024
 const initkvNum = 1024
 
 func NewOverlayDB(store common.PersistStore) *OverlayDB {
+	return NewOverlayDBWithCap(store, initCap, initkvNum)
+}
+
+// NewOverlayDBWithCap return an OverlayDB whose write set is preallocated with capacity
+func NewOverlayDBWithCap(store common.PersistStore, capacity, kvNum int) *OverlayDB {
 	return &OverlayDB{
 		store: store,
-		memdb: NewMemDB(initCap, initkvNum),
+		memdb: NewMemDB(capacity, kvNum),
 	}
 }
 
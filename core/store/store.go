@@ -29,12 +29,14 @@ import (
 )
 
 type ExecuteResult struct {
-	WriteSet        *overlaydb.MemDB
-	CrossHashes     []common.Uint256
-	CrossStatesRoot common.Uint256
-	Hash            common.Uint256
-	MerkleRoot      common.Uint256
-	Notify          []*event.ExecuteNotify
+	WriteSet           *overlaydb.MemDB
+	CrossHashes        []common.Uint256
+	CrossStatesRoot    common.Uint256
+	CrossStatesAccRoot common.Uint256
+	ReceiptsRoot       common.Uint256
+	Hash               common.Uint256
+	MerkleRoot         common.Uint256
+	Notify             []*event.ExecuteNotify
 }
 
 // LedgerStore provides func with store package.
@@ -47,6 +49,7 @@ type LedgerStore interface {
 	SubmitBlock(b *types.Block, exec ExecuteResult) error // called by consensus
 	GetStateMerkleRoot(height uint32) (result common.Uint256, err error)
 	GetCrossStateRoot(height uint32) (result common.Uint256, err error)
+	GetCrossStatesAccRoot(height uint32) (result common.Uint256, err error)
 	GetCurrentBlockHash() common.Uint256
 	GetCurrentBlockHeight() uint32
 	GetCurrentHeaderHeight() uint32
@@ -62,9 +65,13 @@ type LedgerStore interface {
 	GetBlockRootWithPreBlockHashes(startHeight uint32, txRoots []common.Uint256) common.Uint256
 	GetMerkleProof(raw []byte, m, n uint32) ([]byte, error)
 	GetCrossStatesProof(height uint32, key []byte) ([]byte, error)
+	GetCrossStatesAccProof(height uint32, key []byte, rootHeight uint32) ([]byte, error)
+	GetReceiptProof(txHash common.Uint256) (uint32, []byte, []byte, error)
 	GetBookkeeperState() (*states.BookkeeperState, error)
 	GetStorageItem(key *states.StorageKey) (*states.StorageItem, error)
 	PreExecuteContract(tx *types.Transaction) (*cstates.PreExecResult, error)
 	GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error)
 	GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error)
+	GetEventNotifyByChain(chainID uint64, height uint32) ([]*event.ExecuteNotify, error)
+	GetChainEventHeights(chainID uint64, start, end uint32, limit int) ([]uint32, error)
 }
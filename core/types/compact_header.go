@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+const COMPACT_SIG_SIZE = 65
+
+// CompactHeader is the form of a header submitted to the cross chain manager contracts of evm chains.
+// RawHeader is the unsigned header carrying all the roots, Bitmap marks the signers among the keepers
+// of the epoch in their order and Sigs concatenates the ecrecover compatible signatures in the same order.
+type CompactHeader struct {
+	RawHeader []byte
+	Bitmap    []byte
+	Sigs      []byte
+}
+
+// Signers returns the indexes of the signing keepers
+func (this *CompactHeader) Signers() []int {
+	signers := make([]int, 0)
+	for i := 0; i < len(this.Bitmap)*8; i++ {
+		if this.Bitmap[i/8]&(1<<uint(i%8)) != 0 {
+			signers = append(signers, i)
+		}
+	}
+	return signers
+}
+
+// Hash returns the hash of the header, same as Header.Hash
+func (this *CompactHeader) Hash() common.Uint256 {
+	temp := sha256.Sum256(this.RawHeader)
+	return common.Uint256(sha256.Sum256(temp[:]))
+}
+
+func (this *CompactHeader) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.RawHeader)
+	sink.WriteVarBytes(this.Bitmap)
+	sink.WriteVarBytes(this.Sigs)
+}
+
+func (this *CompactHeader) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.RawHeader, eof = source.NextVarBytes()
+	if eof {
+		return errors.New("[CompactHeader] read raw header error")
+	}
+	this.Bitmap, eof = source.NextVarBytes()
+	if eof {
+		return errors.New("[CompactHeader] read bitmap error")
+	}
+	this.Sigs, eof = source.NextVarBytes()
+	if eof {
+		return errors.New("[CompactHeader] read signatures error")
+	}
+	if len(this.Sigs) != len(this.Signers())*COMPACT_SIG_SIZE {
+		return fmt.Errorf("[CompactHeader] signatures length %d mismatch signers", len(this.Sigs))
+	}
+	return nil
+}
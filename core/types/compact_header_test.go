@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactHeader(t *testing.T) {
+	h := Header{
+		Version:          HEADER_VERSION_CROSS_STATES_ACC,
+		ChainID:          123,
+		Height:           123,
+		ConsensusPayload: []byte{123},
+	}
+	compact := &CompactHeader{
+		RawHeader: h.GetMessage(),
+		Bitmap:    []byte{0x05, 0x01},
+		Sigs:      make([]byte, 3*COMPACT_SIG_SIZE),
+	}
+	assert.Equal(t, h.Hash(), compact.Hash())
+	assert.Equal(t, []int{0, 2, 8}, compact.Signers())
+
+	sink := common.NewZeroCopySink(nil)
+	compact.Serialization(sink)
+	var compact1 CompactHeader
+	err := compact1.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, *compact, compact1)
+
+	compact.Sigs = compact.Sigs[COMPACT_SIG_SIZE:]
+	sink.Reset()
+	compact.Serialization(sink)
+	err = compact1.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Error(t, err)
+}
@@ -41,6 +41,9 @@ type Header struct {
 	ConsensusData    uint64
 	ConsensusPayload []byte
 	NextBookkeeper   common.Address
+	// root of the accumulator over the cross states of all the previous blocks,
+	// only serialized from HEADER_VERSION_CROSS_STATES_ACC
+	CrossStatesAccRoot common.Uint256
 
 	//Program *program.Program
 	Bookkeepers []keypair.PublicKey
@@ -81,6 +84,9 @@ func (bd *Header) serializationUnsigned(sink *common.ZeroCopySink) {
 	sink.WriteUint64(bd.ConsensusData)
 	sink.WriteVarBytes(bd.ConsensusPayload)
 	sink.WriteBytes(bd.NextBookkeeper[:])
+	if bd.Version >= HEADER_VERSION_CROSS_STATES_ACC {
+		sink.WriteBytes(bd.CrossStatesAccRoot[:])
+	}
 }
 
 func (bd *Header) Serialize(w io.Writer) error {
@@ -145,6 +151,11 @@ func (bd *Header) serializeUnsigned(w io.Writer) error {
 	if err := serialization.WriteBytes(w, bd.NextBookkeeper[:]); err != nil {
 		return err
 	}
+	if bd.Version >= HEADER_VERSION_CROSS_STATES_ACC {
+		if err := serialization.WriteBytes(w, bd.CrossStatesAccRoot[:]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -247,6 +258,12 @@ func (bd *Header) deserializationUnsigned(source *common.ZeroCopySource) error {
 	if eof {
 		return errors.New("[Header] read nextBookkeeper error")
 	}
+	if bd.Version >= HEADER_VERSION_CROSS_STATES_ACC {
+		bd.CrossStatesAccRoot, eof = source.NextHash()
+		if eof {
+			return errors.New("[Header] read crossStatesAccRoot error")
+		}
+	}
 	return nil
 }
 
@@ -337,6 +354,12 @@ func (bd *Header) deserializeUnsigned(w io.Reader) error {
 	if err != nil {
 		return errors.New("[Header] read nextBookkeeper error")
 	}
+	if bd.Version >= HEADER_VERSION_CROSS_STATES_ACC {
+		bd.CrossStatesAccRoot, err = serialization.ReadHash(w)
+		if err != nil {
+			return errors.New("[Header] read crossStatesAccRoot error")
+		}
+	}
 	return nil
 }
 
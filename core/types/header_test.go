@@ -81,3 +81,37 @@ func TestHeader(t *testing.T) {
 	assert.Equal(t, header1, header2)
 
 }
+
+func TestHeaderCrossStatesAccRoot(t *testing.T) {
+	h := Header{
+		Version:            HEADER_VERSION_CROSS_STATES_ACC,
+		ChainID:            123,
+		Height:             123,
+		ConsensusPayload:   []byte{123},
+		CrossStatesAccRoot: common.Uint256{1, 2, 3},
+	}
+	sink := common.NewZeroCopySink(nil)
+	err := h.Serialization(sink)
+	assert.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	err = h.Serialize(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, sink.Bytes(), buf.Bytes())
+
+	var header1 Header
+	err = header1.Deserialize(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, h.CrossStatesAccRoot, header1.CrossStatesAccRoot)
+
+	var header2 Header
+	err = header2.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, header1, header2)
+
+	h.Version = 0
+	old := common.NewZeroCopySink(nil)
+	err = h.Serialization(old)
+	assert.NoError(t, err)
+	assert.Equal(t, len(sink.Bytes())-common.UINT256_SIZE, len(old.Bytes()))
+}
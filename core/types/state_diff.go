@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"io"
+
+	"github.com/polynetwork/poly/common"
+)
+
+//StorageChange is a contract storage entry written in a block, an empty New means deleted
+type StorageChange struct {
+	Contract common.Address
+	Key      []byte
+	Old      []byte
+	New      []byte
+}
+
+//StateDiff holds the contract storage changes of a block
+type StateDiff struct {
+	Height    uint32
+	BlockHash common.Uint256
+	Changes   []*StorageChange
+}
+
+func (self *StateDiff) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(self.Height)
+	sink.WriteHash(self.BlockHash)
+	sink.WriteVarUint(uint64(len(self.Changes)))
+	for _, change := range self.Changes {
+		sink.WriteAddress(change.Contract)
+		sink.WriteVarBytes(change.Key)
+		sink.WriteVarBytes(change.Old)
+		sink.WriteVarBytes(change.New)
+	}
+}
+
+func (self *StateDiff) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	self.Height, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	self.BlockHash, eof = source.NextHash()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	self.Changes = make([]*StorageChange, 0)
+	for i := uint64(0); i < n; i++ {
+		change := new(StorageChange)
+		if change.Contract, eof = source.NextAddress(); eof {
+			return io.ErrUnexpectedEOF
+		}
+		if change.Key, eof = source.NextVarBytes(); eof {
+			return io.ErrUnexpectedEOF
+		}
+		if change.Old, eof = source.NextVarBytes(); eof {
+			return io.ErrUnexpectedEOF
+		}
+		if change.New, eof = source.NextVarBytes(); eof {
+			return io.ErrUnexpectedEOF
+		}
+		self.Changes = append(self.Changes, change)
+	}
+	return nil
+}
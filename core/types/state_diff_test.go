@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateDiffSerialization(t *testing.T) {
+	diff := &StateDiff{
+		Height:    10,
+		BlockHash: common.Uint256{1, 2, 3},
+		Changes: []*StorageChange{
+			{Contract: common.Address{9}, Key: []byte("key"), Old: []byte("old"), New: []byte("new")},
+			{Contract: common.Address{8}, Key: []byte("deleted"), Old: []byte("old"), New: []byte{}},
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	diff.Serialization(sink)
+
+	decoded := new(StateDiff)
+	err := decoded.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, diff, decoded)
+
+	err = new(StateDiff).Deserialization(common.NewZeroCopySource(sink.Bytes()[:sink.Size()-1]))
+	assert.Error(t, err)
+}
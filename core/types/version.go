@@ -19,5 +19,8 @@
 package types
 
 const CURR_TX_VERSION = 0
-const CURR_HEADER_VERSION = 0
+const CURR_HEADER_VERSION = 1
 const MAX_ATTRIBUTES_LEN = 0
+
+// headers from this version carry the root of the cross states accumulator
+const HEADER_VERSION_CROSS_STATES_ACC = 1
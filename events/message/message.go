@@ -29,6 +29,7 @@ const (
 	TOPIC_NODE_DISCONNECT           = "noddis"
 	TOPIC_NODE_CONSENSUS_DISCONNECT = "nodcnsdis"
 	TOPIC_SMART_CODE_EVENT          = "scevt"
+	TOPIC_STATE_DIFF                = "stdiff"
 )
 
 type SaveBlockCompleteMsg struct {
@@ -43,6 +44,10 @@ type SmartCodeEventMsg struct {
 	Event *types.SmartCodeEvent
 }
 
+type StateDiffMsg struct {
+	Diff *types.StateDiff
+}
+
 type BlockConsensusComplete struct {
 	Block *types.Block
 }
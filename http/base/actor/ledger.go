@@ -82,8 +82,11 @@ func GetTxnWithHeightByTxHash(hash common.Uint256) (uint32, *types.Transaction,
 	return height, tx, err
 }
 
-//PreExecuteContract from ledger
+//PreExecuteContract from ledger, the results of the imports of cross chain txs are cached briefly
 func PreExecuteContract(tx *types.Transaction) (*cstate.PreExecResult, error) {
+	if key, ok := getProofCacheKey(tx); ok {
+		return preExecuteProof(key, tx)
+	}
 	return ledger.DefLedger.PreExecuteContract(tx)
 }
 
@@ -97,6 +100,16 @@ func GetEventNotifyByHeight(height uint32) ([]*event.ExecuteNotify, error) {
 	return ledger.DefLedger.GetEventNotifyByBlock(height)
 }
 
+//GetEventNotifyByChain from ledger
+func GetEventNotifyByChain(chainID uint64, height uint32) ([]*event.ExecuteNotify, error) {
+	return ledger.DefLedger.GetEventNotifyByChain(chainID, height)
+}
+
+//GetChainEventHeights from ledger
+func GetChainEventHeights(chainID uint64, start, end uint32, limit int) ([]uint32, error) {
+	return ledger.DefLedger.GetChainEventHeights(chainID, start, end, limit)
+}
+
 //GetMerkleProof from ledger
 func GetMerkleProof(proofHeight uint32, rootHeight uint32) ([]byte, error) {
 	return ledger.DefLedger.GetMerkleProof(proofHeight, rootHeight)
@@ -105,3 +118,11 @@ func GetMerkleProof(proofHeight uint32, rootHeight uint32) ([]byte, error) {
 func GetCrossStatesProof(height uint32, key []byte) ([]byte, error) {
 	return ledger.DefLedger.GetCrossStatesProof(height, key)
 }
+
+func GetCrossStatesAccProof(height uint32, key []byte, rootHeight uint32) ([]byte, error) {
+	return ledger.DefLedger.GetCrossStatesAccProof(height, key, rootHeight)
+}
+
+func GetReceiptProof(txHash common.Uint256) (uint32, []byte, []byte, error) {
+	return ledger.DefLedger.GetReceiptProof(txHash)
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package actor
+
+import (
+	"crypto/sha256"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/golang-lru"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	cstate "github.com/polynetwork/poly/native/states"
+)
+
+const (
+	PROOF_CACHE_SIZE = 1024
+	PROOF_CACHE_TTL  = 10 * time.Second
+)
+
+// proofCache keeps the preExec results of the cross chain txs imported to poly, relayers preExec a proof
+// before submitting it and dashboards poll it, so the same proof is verified once per block by the node.
+// It is local to the node and never used by consensus.
+var proofCache, _ = lru.NewARC(PROOF_CACHE_SIZE)
+
+// proofCacheKey is the source chain and the hash of the entrance param with the proof and the signers
+// of the tx, the notify of a cached result is the one of the tx verified first
+type proofCacheKey struct {
+	chainID uint64
+	hash    common.Uint256
+}
+
+type proofCacheItem struct {
+	height uint32
+	expire time.Time
+	result *cstate.PreExecResult
+	err    error
+}
+
+// getProofCacheKey returns false if tx is not an import of cross chain tx
+func getProofCacheKey(tx *types.Transaction) (proofCacheKey, bool) {
+	invokeCode, ok := tx.Payload.(*payload.InvokeCode)
+	if !ok {
+		return proofCacheKey{}, false
+	}
+	invokeParam := new(cstate.ContractInvokeParam)
+	if err := invokeParam.Deserialization(common.NewZeroCopySource(invokeCode.Code)); err != nil {
+		return proofCacheKey{}, false
+	}
+	if invokeParam.Address != utils.CrossChainManagerContractAddress ||
+		invokeParam.Method != cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME {
+		return proofCacheKey{}, false
+	}
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(invokeParam.Args)); err != nil {
+		return proofCacheKey{}, false
+	}
+	signers, err := tx.GetSignatureAddresses()
+	if err != nil {
+		return proofCacheKey{}, false
+	}
+	sort.Slice(signers, func(i, j int) bool {
+		return signers[i].ToHexString() < signers[j].ToHexString()
+	})
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(invokeParam.Args)
+	for _, v := range signers {
+		sink.WriteAddress(v)
+	}
+	return proofCacheKey{chainID: params.SourceChainID, hash: sha256.Sum256(sink.Bytes())}, true
+}
+
+// preExecuteProof returns the cached result of tx at height which is not expired, or preExecutes tx
+// and caches the result
+func preExecuteProof(key proofCacheKey, tx *types.Transaction) (*cstate.PreExecResult, error) {
+	height := ledger.DefLedger.GetCurrentBlockHeight()
+	if v, ok := proofCache.Get(key); ok {
+		item := v.(*proofCacheItem)
+		if item.height == height && time.Now().Before(item.expire) {
+			return item.result, item.err
+		}
+		proofCache.Remove(key)
+	}
+	result, err := ledger.DefLedger.PreExecuteContract(tx)
+	proofCache.Add(key, &proofCacheItem{
+		height: height,
+		expire: time.Now().Add(PROOF_CACHE_TTL),
+		result: result,
+		err:    err,
+	})
+	return result, err
+}
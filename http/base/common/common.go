@@ -20,18 +20,31 @@
 package common
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
 	"github.com/polynetwork/poly/core/types"
 	ontErrors "github.com/polynetwork/poly/errors"
 	bactor "github.com/polynetwork/poly/http/base/actor"
 	"github.com/polynetwork/poly/native/event"
+	ccom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	cstate "github.com/polynetwork/poly/native/states"
 )
 
 const MAX_SEARCH_HEIGHT uint32 = 100
 
+const (
+	MAX_EVENT_SCAN_BLOCKS uint32 = 1000
+	MAX_EVENT_PAGE_SIZE   uint32 = 1000
+	MAX_OUTBOUND_RANGE    uint64 = 100
+)
+
 type BalanceOfRsp struct {
 	Ont string `json:"ont"`
 	Ong string `json:"ong"`
@@ -53,6 +66,7 @@ type ExecuteNotify struct {
 	State       byte
 	GasConsumed uint64
 	Notify      []NotifyEventInfo
+	NotifyHash  string `json:",omitempty"`
 }
 
 type PreExecuteResult struct {
@@ -64,6 +78,221 @@ type PreExecuteResult struct {
 type NotifyEventInfo struct {
 	ContractAddress string
 	States          interface{}
+	FromChainID     *uint64 `json:",omitempty"`
+	ToChainID       *uint64 `json:",omitempty"`
+}
+
+// BoundTo tells whether the event is a cross chain event from or to chainID
+func (this *NotifyEventInfo) BoundTo(chainID uint64) bool {
+	return (this.FromChainID != nil && *this.FromChainID == chainID) ||
+		(this.ToChainID != nil && *this.ToChainID == chainID)
+}
+
+// EventFilter narrows the events returned by getsmartcodeevent over the heights from the
+// queried one to EndHeight. The cross chain events carry the event name, the source and
+// the destination chain id as the first states, FromChainID and ToChainID match them.
+// Cursor is the NextCursor of the previous page and Limit is the max number of the
+// transactions in a page.
+type EventFilter struct {
+	EndHeight   uint32  `json:"endHeight"`
+	Contract    string  `json:"contract"`
+	EventName   string  `json:"eventName"`
+	FromChainID *uint64 `json:"fromChainId"`
+	ToChainID   *uint64 `json:"toChainId"`
+	Cursor      string  `json:"cursor"`
+	Limit       uint32  `json:"limit"`
+}
+
+// EventPage is a page of the filtered events, NextCursor is empty once all the heights are scanned
+type EventPage struct {
+	Events     []*ExecuteNotify
+	NextCursor string
+}
+
+// EventCursor is the position of the transaction to resume the scan from
+type EventCursor struct {
+	Height uint32
+	Index  uint32
+}
+
+func (this EventCursor) String() string {
+	return fmt.Sprintf("%d:%d", this.Height, this.Index)
+}
+
+// ParseEventCursor parses the cursor formatted as height:index
+func ParseEventCursor(cursor string) (EventCursor, error) {
+	parts := strings.Split(cursor, ":")
+	if len(parts) != 2 {
+		return EventCursor{}, fmt.Errorf("invalid cursor %s", cursor)
+	}
+	height, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return EventCursor{}, fmt.Errorf("invalid cursor height: %v", err)
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return EventCursor{}, fmt.Errorf("invalid cursor index: %v", err)
+	}
+	return EventCursor{Height: uint32(height), Index: uint32(index)}, nil
+}
+
+func matchChainID(states []interface{}, i int, chainID *uint64) bool {
+	if chainID == nil {
+		return true
+	}
+	if len(states) <= i {
+		return false
+	}
+	switch v := states[i].(type) {
+	case float64:
+		return uint64(v) == *chainID
+	case uint64:
+		return v == *chainID
+	}
+	return false
+}
+
+func matchChainIDField(field, chainID *uint64) bool {
+	return chainID == nil || (field != nil && *field == *chainID)
+}
+
+// Match tells whether the event passes the filter
+func (this *EventFilter) Match(evt *NotifyEventInfo) bool {
+	if this.Contract != "" && !strings.EqualFold(this.Contract, evt.ContractAddress) {
+		return false
+	}
+	if this.EventName == "" && this.FromChainID == nil && this.ToChainID == nil {
+		return true
+	}
+	states, ok := evt.States.([]interface{})
+	if !ok || len(states) == 0 {
+		return false
+	}
+	if this.EventName != "" {
+		if name, ok := states[0].(string); !ok || name != this.EventName {
+			return false
+		}
+	}
+	// the chain id fields are matched if the event has any, the ones left nil are not bound to a chain
+	if evt.FromChainID != nil || evt.ToChainID != nil {
+		return matchChainIDField(evt.FromChainID, this.FromChainID) && matchChainIDField(evt.ToChainID, this.ToChainID)
+	}
+	return matchChainID(states, 1, this.FromChainID) && matchChainID(states, 2, this.ToChainID)
+}
+
+// FilterNotify keeps the events of notify passing the filter, false is returned if none does
+func (this *EventFilter) FilterNotify(notify *ExecuteNotify) bool {
+	evts := make([]NotifyEventInfo, 0, len(notify.Notify))
+	for i := range notify.Notify {
+		if this.Match(&notify.Notify[i]) {
+			evts = append(evts, notify.Notify[i])
+		}
+	}
+	notify.Notify = evts
+	return len(evts) > 0
+}
+
+// OutboundPayload is a cross chain payload emitted to a chain, Key is the storage key to
+// query its proof in the cross states of PolyHeight with getcrossstatesproof.
+type OutboundPayload struct {
+	Sequence   uint64
+	TxHash     string
+	PolyHeight uint32
+	Key        string
+	Payload    string
+}
+
+// SideHeader is a side chain header synced to poly. Commitment is in the cross states of
+// poly block PolyHeight and CrossStatesProof is its merkle path against the CrossStateRoot
+// of that block, Header is the header as stored by the header sync contract.
+type SideHeader struct {
+	ChainID          uint64
+	Height           uint64
+	Hash             string
+	Header           string
+	PolyHeight       uint32
+	Commitment       string
+	CrossStatesProof string
+}
+
+// PendingMultiSign is a btc transaction waiting for the signatures of the redeem's keepers,
+// Digests are what to sign for each input.
+type PendingMultiSign struct {
+	TxHash  string
+	RawTx   string
+	Digests []string
+}
+
+// BtcReserve is the last reserve report of a btc redeem, Pending is the change of the txs waiting
+// for signatures, Minted is the outstanding amount on each destination chain and Ratio the reserve
+// over the obligations, empty if nothing is minted.
+type BtcReserve struct {
+	ChainID     uint64
+	RedeemKey   string
+	Height      uint32
+	Reserve     uint64
+	Pending     uint64
+	Obligations uint64
+	Minted      map[uint64]uint64
+	Ratio       string
+	Covered     bool
+}
+
+// PendingConsensusSign is a governance proposal waiting for the signatures of consensus peers,
+// it's approved by invoking Method of the node manager contract with Input.
+type PendingConsensusSign struct {
+	Key    string
+	Method string
+	Input  string
+	Signed []string
+}
+
+// ReceiptProof proves the notifications of the transaction at Height, ReceiptProof is the merkle path
+// of its notify hash against the receipts root and CrossStatesProof is the path of the receipts root
+// against the CrossStateRoot carried by the header at Height+1.
+type ReceiptProof struct {
+	Height           uint32
+	ReceiptProof     string
+	CrossStatesProof string
+}
+
+// SideChainInfo is a chain id registered on poly, Status is one of active, probation, quitting, blacked
+// and removed.
+type SideChainInfo struct {
+	ChainID uint64
+	Name    string
+	Router  uint64
+	Status  string
+}
+
+// CrossChainStats are the counters of cross chain manager, Transfers counts the transfers from
+// FromChainID to ToChainID, Volume is the total amount of Asset on ToChainID in decimal and
+// Failures counts the failed imports by reason.
+type CrossChainStats struct {
+	FromChainID uint64
+	ToChainID   uint64
+	Transfers   uint64
+	Asset       string `json:",omitempty"`
+	Volume      string `json:",omitempty"`
+	Failures    map[string]uint64
+}
+
+// FailedImportInfo is an entry of the log of the failed imports, ProofHash is the sha256 of the proof
+type FailedImportInfo struct {
+	Index     uint64
+	Height    uint32
+	TxHash    string
+	ChainID   uint64
+	ProofHash string
+	Relayer   string
+	Reason    string
+}
+
+// VerifyMetrics are the proof verification metrics of the node, the histograms by chain handler
+// and the totals of the handlers in the recent blocks
+type VerifyMetrics struct {
+	Handlers []*ccom.HandlerMetrics
+	Blocks   []*ccom.BlockMetrics
 }
 
 type TxAttributeInfo struct {
@@ -155,17 +384,21 @@ func GetExecuteNotify(obj *event.ExecuteNotify) (map[string]bool, ExecuteNotify)
 	evts := []NotifyEventInfo{}
 	var contractAddrs = make(map[string]bool)
 	for _, v := range obj.Notify {
-		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
+		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States, v.FromChainID, v.ToChainID})
 		contractAddrs[v.ContractAddress.ToHexString()] = true
 	}
 	txhash := obj.TxHash.ToHexString()
-	return contractAddrs, ExecuteNotify{txhash, obj.State, obj.GasConsumed, evts}
+	notifyHash := ""
+	if obj.NotifyHash != common.UINT256_EMPTY {
+		notifyHash = obj.NotifyHash.ToHexString()
+	}
+	return contractAddrs, ExecuteNotify{txhash, obj.State, obj.GasConsumed, evts, notifyHash}
 }
 
 func ConvertPreExecuteResult(obj *cstate.PreExecResult) PreExecuteResult {
 	evts := []NotifyEventInfo{}
 	for _, v := range obj.Notify {
-		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
+		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States, v.FromChainID, v.ToChainID})
 	}
 	return PreExecuteResult{obj.State, obj.Result, evts}
 }
@@ -266,6 +499,34 @@ func GetBlockTransactions(block *types.Block) interface{} {
 	return b
 }
 
+func GetStateDiff(diff *types.StateDiff) interface{} {
+	type StorageChange struct {
+		Contract string
+		Key      string
+		Old      string
+		New      string
+	}
+	type StateDiff struct {
+		Hash    string
+		Height  uint32
+		Changes []StorageChange
+	}
+	changes := make([]StorageChange, len(diff.Changes))
+	for i, change := range diff.Changes {
+		changes[i] = StorageChange{
+			Contract: change.Contract.ToHexString(),
+			Key:      common.ToHexString(change.Key),
+			Old:      common.ToHexString(change.Old),
+			New:      common.ToHexString(change.New),
+		}
+	}
+	return StateDiff{
+		Hash:    diff.BlockHash.ToHexString(),
+		Height:  diff.Height,
+		Changes: changes,
+	}
+}
+
 func GetAddress(str string) (common.Address, error) {
 	var address common.Address
 	var err error
@@ -276,3 +537,77 @@ func GetAddress(str string) (common.Address, error) {
 	}
 	return address, err
 }
+
+// GetKeepers returns the keepers of the epoch signing the header at height in their configured order
+func GetKeepers(height uint32) ([]keypair.PublicKey, error) {
+	if height == 0 {
+		return nil, fmt.Errorf("genesis header is not signed")
+	}
+	prev, err := bactor.GetHeaderByHeight(height - 1)
+	if err != nil {
+		return nil, fmt.Errorf("get header of height %d error: %s", height-1, err)
+	}
+	info, err := vconfig.VbftBlock(prev)
+	if err != nil {
+		return nil, err
+	}
+	cfgHeight := info.LastConfigBlockNum
+	if info.NewChainConfig == nil {
+		cfg, err := bactor.GetHeaderByHeight(cfgHeight)
+		if err != nil {
+			return nil, fmt.Errorf("get config header of height %d error: %s", cfgHeight, err)
+		}
+		if info, err = vconfig.VbftBlock(cfg); err != nil {
+			return nil, err
+		}
+		if info.NewChainConfig == nil {
+			return nil, fmt.Errorf("no chain config in header of height %d", cfgHeight)
+		}
+	}
+	keepers := make([]keypair.PublicKey, 0, len(info.NewChainConfig.Peers))
+	for _, peer := range info.NewChainConfig.Peers {
+		pk, err := vconfig.Pubkey(peer.ID)
+		if err != nil {
+			return nil, err
+		}
+		keepers = append(keepers, pk)
+	}
+	return keepers, nil
+}
+
+// NewCompactHeader builds the compact form of header signed by the keepers
+func NewCompactHeader(header *types.Header, keepers []keypair.PublicKey) (*types.CompactHeader, error) {
+	if len(header.Bookkeepers) != len(header.SigData) {
+		return nil, fmt.Errorf("bookkeepers %d mismatch signatures %d", len(header.Bookkeepers), len(header.SigData))
+	}
+	index := make(map[string]int, len(keepers))
+	for i, pk := range keepers {
+		index[vconfig.PubkeyID(pk)] = i
+	}
+	hash := header.Hash()
+	sigs := make([][]byte, len(keepers))
+	for i, pk := range header.Bookkeepers {
+		idx, ok := index[vconfig.PubkeyID(pk)]
+		if !ok {
+			return nil, fmt.Errorf("bookkeeper %s is not a keeper", vconfig.PubkeyID(pk))
+		}
+		sig, err := signature.ConvertToEthCompatible(pk, hash[:], header.SigData[i])
+		if err != nil {
+			return nil, fmt.Errorf("convert signature of bookkeeper %s error: %s", vconfig.PubkeyID(pk), err)
+		}
+		sigs[idx] = sig
+	}
+
+	compact := &types.CompactHeader{
+		RawHeader: header.GetMessage(),
+		Bitmap:    make([]byte, (len(keepers)+7)/8),
+	}
+	for i, sig := range sigs {
+		if sig == nil {
+			continue
+		}
+		compact.Bitmap[i/8] |= 1 << uint(i%8)
+		compact.Sigs = append(compact.Sigs, sig...)
+	}
+	return compact, nil
+}
@@ -20,7 +20,12 @@ package rpc
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
@@ -32,6 +37,14 @@ import (
 	bactor "github.com/polynetwork/poly/http/base/actor"
 	bcomn "github.com/polynetwork/poly/http/base/common"
 	berr "github.com/polynetwork/poly/http/base/error"
+	"github.com/polynetwork/poly/native/client"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
+	ccom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 //get best block hash
@@ -318,6 +331,9 @@ func GetNetworkId(params []interface{}) map[string]interface{} {
 }
 
 //get smartconstract event
+// The events of a height range are filtered and paged with a filter object, e.g.
+//   {"jsonrpc": "2.0", "method": "getsmartcodeevent", "params": [100, {"endHeight": 200, "toChainId": 2, "limit": 50}], "id": 0}
+// the next page is queried with the NextCursor of the result as the cursor of the filter.
 func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	if !config.DefConfig.Common.EnableEventLog {
 		return responsePack(berr.INVALID_METHOD, "")
@@ -330,6 +346,17 @@ func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	// block height
 	case float64:
 		height := uint32(params[0].(float64))
+		if len(params) > 1 {
+			filter := new(bcomn.EventFilter)
+			raw, err := json.Marshal(params[1])
+			if err != nil {
+				return responsePack(berr.INVALID_PARAMS, "")
+			}
+			if err := json.Unmarshal(raw, filter); err != nil {
+				return responsePack(berr.INVALID_PARAMS, err.Error())
+			}
+			return getFilteredEvents(height, filter)
+		}
 		eventInfos, err := bactor.GetEventNotifyByHeight(height)
 		if err != nil {
 			if err == scom.ErrNotFound {
@@ -365,6 +392,132 @@ func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	return responsePack(berr.INVALID_PARAMS, "")
 }
 
+// getFilteredEvents scans the events from the height or the cursor of filter, the scan
+// stops at a full page or after MAX_EVENT_SCAN_BLOCKS blocks and the cursor of the next
+// page is returned.
+func getFilteredEvents(height uint32, filter *bcomn.EventFilter) map[string]interface{} {
+	cursor := bcomn.EventCursor{Height: height}
+	if filter.Cursor != "" {
+		var err error
+		if cursor, err = bcomn.ParseEventCursor(filter.Cursor); err != nil {
+			return responsePack(berr.INVALID_PARAMS, err.Error())
+		}
+	}
+	endHeight := filter.EndHeight
+	if endHeight < height {
+		endHeight = height
+	}
+	if cur := bactor.GetCurrentBlockHeight(); endHeight > cur {
+		endHeight = cur
+	}
+	limit := filter.Limit
+	if limit == 0 || limit > bcomn.MAX_EVENT_PAGE_SIZE {
+		limit = bcomn.MAX_EVENT_PAGE_SIZE
+	}
+
+	page := &bcomn.EventPage{Events: make([]*bcomn.ExecuteNotify, 0)}
+	scanned := uint32(0)
+	for h := cursor.Height; h <= endHeight; h++ {
+		if scanned >= bcomn.MAX_EVENT_SCAN_BLOCKS {
+			page.NextCursor = bcomn.EventCursor{Height: h}.String()
+			return responseSuccess(page)
+		}
+		scanned++
+		eventInfos, err := bactor.GetEventNotifyByHeight(h)
+		if err != nil {
+			if err == scom.ErrNotFound {
+				continue
+			}
+			return responsePack(berr.INTERNAL_ERROR, "")
+		}
+		start := uint32(0)
+		if h == cursor.Height {
+			start = cursor.Index
+		}
+		for i := start; i < uint32(len(eventInfos)); i++ {
+			if uint32(len(page.Events)) >= limit {
+				page.NextCursor = bcomn.EventCursor{Height: h, Index: i}.String()
+				return responseSuccess(page)
+			}
+			_, notify := bcomn.GetExecuteNotify(eventInfos[i])
+			if filter.FilterNotify(&notify) {
+				page.Events = append(page.Events, &notify)
+			}
+		}
+	}
+	return responseSuccess(page)
+}
+
+// GetChainEvents returns the cross chain events from or to a chain, from the start height or the
+// NextCursor of the previous page to the end height or the current one, through the index of the events
+// by chain. The page ends at the block after which MAX_EVENT_PAGE_SIZE transactions are collected, the
+// events emitted before the chain id fields are added to them are not indexed.
+func GetChainEvents(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	chainID, ok := params[0].(float64)
+	if !ok || chainID < 0 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var start uint32
+	switch v := params[1].(type) {
+	case float64:
+		if v < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		start = uint32(v)
+	case string:
+		cursor, err := bcomn.ParseEventCursor(v)
+		if err != nil {
+			return responsePack(berr.INVALID_PARAMS, err.Error())
+		}
+		start = cursor.Height
+	default:
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	end := bactor.GetCurrentBlockHeight()
+	if len(params) > 2 {
+		e, ok := params[2].(float64)
+		if !ok || e < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		if uint32(e) < end {
+			end = uint32(e)
+		}
+	}
+	heights, err := bactor.GetChainEventHeights(uint64(chainID), start, end, int(bcomn.MAX_EVENT_SCAN_BLOCKS))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	page := &bcomn.EventPage{Events: make([]*bcomn.ExecuteNotify, 0)}
+	for _, h := range heights {
+		if uint32(len(page.Events)) >= bcomn.MAX_EVENT_PAGE_SIZE {
+			page.NextCursor = bcomn.EventCursor{Height: h}.String()
+			return responseSuccess(page)
+		}
+		eventInfos, err := bactor.GetEventNotifyByChain(uint64(chainID), h)
+		if err != nil {
+			return responsePack(berr.INTERNAL_ERROR, "")
+		}
+		for _, eventInfo := range eventInfos {
+			_, notify := bcomn.GetExecuteNotify(eventInfo)
+			evts := make([]bcomn.NotifyEventInfo, 0, len(notify.Notify))
+			for _, evt := range notify.Notify {
+				if evt.BoundTo(uint64(chainID)) {
+					evts = append(evts, evt)
+				}
+			}
+			notify.Notify = evts
+			page.Events = append(page.Events, &notify)
+		}
+	}
+	if uint32(len(heights)) >= bcomn.MAX_EVENT_SCAN_BLOCKS && heights[len(heights)-1] < end {
+		page.NextCursor = bcomn.EventCursor{Height: heights[len(heights)-1] + 1}.String()
+	}
+	return responseSuccess(page)
+}
+
 //get block height by transaction hash
 func GetBlockHeightByTxHash(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
@@ -437,6 +590,59 @@ func GetCrossStatesProof(params []interface{}) map[string]interface{} {
 	return responseSuccess(bcomn.MerkleProof{"CrossStatesProof", hex.EncodeToString(proof)})
 }
 
+//get the proof of a cross chain state committed at height against the
+//cross states accumulator root in the header at rootHeight
+func GetCrossStatesAccProof(params []interface{}) map[string]interface{} {
+	if len(params) < 3 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	height, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[1].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	key, err := hex.DecodeString(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	rootHeight, ok := params[2].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	proof, err := bactor.GetCrossStatesAccProof(uint32(height), key, uint32(rootHeight))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(bcomn.MerkleProof{"CrossStatesAccProof", hex.EncodeToString(proof)})
+}
+
+//get the proof of the notifications emitted by a transaction
+func GetReceiptProof(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	txHash, err := common.Uint256FromHexString(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	height, receiptProof, crossStatesProof, err := bactor.GetReceiptProof(txHash)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(bcomn.ReceiptProof{
+		Height:           height,
+		ReceiptProof:     hex.EncodeToString(receiptProof),
+		CrossStatesProof: hex.EncodeToString(crossStatesProof),
+	})
+}
+
 func GetHeaderByHeight(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
 		return responsePack(berr.INVALID_PARAMS, nil)
@@ -453,6 +659,32 @@ func GetHeaderByHeight(params []interface{}) map[string]interface{} {
 	return responseSuccess(hex.EncodeToString(header.ToArray()))
 }
 
+//get the compact header by height, to be submitted to the cross chain manager contracts of evm chains
+func GetCompactHeader(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	height, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	header, err := bactor.GetHeaderByHeight(uint32(height))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	keepers, err := bcomn.GetKeepers(header.Height)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	compact, err := bcomn.NewCompactHeader(header, keepers)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	sink := common.NewZeroCopySink(nil)
+	compact.Serialization(sink)
+	return responseSuccess(hex.EncodeToString(sink.Bytes()))
+}
+
 //get block transactions by height
 func GetBlockTxsByHeight(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
@@ -496,3 +728,534 @@ func GetStateMerkleRoot(params []interface{}) map[string]interface{} {
 	}
 
 }
+
+// get a canonical side chain header synced to poly by height or hash with the proof of
+// its commitment, the header is canonical if the proof verifies against the CrossStateRoot
+// of the poly block at PolyHeight.
+// A JSON example for getsideheader method as following:
+//   {"jsonrpc": "2.0", "method": "getsideheader", "params": [2, 12345], "id": 0}
+//   {"jsonrpc": "2.0", "method": "getsideheader", "params": [2, "header hash in hex"], "id": 0}
+func GetSideHeader(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	chainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	chainIDBytes := utils.GetUint64Bytes(uint64(chainID))
+	contract := utils.HeaderSyncContractAddress
+
+	var height uint64
+	switch v := params[1].(type) {
+	case float64:
+		height = uint64(v)
+	case string:
+		hash, err := hex.DecodeString(v)
+		if err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		value, err := bactor.GetStorageItem(contract, append(append([]byte(hscommon.HEADER_COMMITMENT_HASH), chainIDBytes...), hash...))
+		if err != nil {
+			if err == scom.ErrNotFound {
+				return responsePack(berr.UNKNOWN_BLOCK, "")
+			}
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+		height = utils.GetBytesUint64(value)
+	default:
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+
+	key := append(append([]byte(hscommon.HEADER_COMMITMENT), chainIDBytes...), utils.GetUint64Bytes(height)...)
+	value, err := bactor.GetStorageItem(contract, key)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return responsePack(berr.UNKNOWN_BLOCK, "")
+		}
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	commitment := new(hscommon.HeaderCommitment)
+	if err := commitment.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	// the hash index is left on reorganization, the header is not canonical any more
+	if hash, ok := params[1].(string); ok && !strings.EqualFold(hash, hex.EncodeToString(commitment.Hash)) {
+		return responsePack(berr.UNKNOWN_BLOCK, "")
+	}
+
+	var header []byte
+	for _, prefix := range []string{hscommon.HEADER_INDEX, hscommon.BLOCK_HEADER} {
+		header, err = bactor.GetStorageItem(contract, append(append([]byte(prefix), chainIDBytes...), commitment.Hash...))
+		if err == nil {
+			break
+		}
+		if err != scom.ErrNotFound {
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+	}
+	proof, err := bactor.GetCrossStatesProof(commitment.PolyHeight, utils.ConcatKey(contract, key))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(bcomn.SideHeader{
+		ChainID:          commitment.ChainID,
+		Height:           commitment.Height,
+		Hash:             hex.EncodeToString(commitment.Hash),
+		Header:           hex.EncodeToString(header),
+		PolyHeight:       commitment.PolyHeight,
+		Commitment:       hex.EncodeToString(value),
+		CrossStatesProof: hex.EncodeToString(proof),
+	})
+}
+
+// get the cross chain payloads emitted to a chain in the sequence range [from, to], the
+// payloads of a chain are numbered from 0 in the order they are made
+// A JSON example for gettxsbyrange method as following:
+//   {"jsonrpc": "2.0", "method": "gettxsbyrange", "params": [2, 0, 99], "id": 0}
+func GetTxsByRange(params []interface{}) map[string]interface{} {
+	if len(params) < 3 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	chainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	from, ok := params[1].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	to, ok := params[2].(float64)
+	if !ok || to < from {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	if uint64(to)-uint64(from) >= bcomn.MAX_OUTBOUND_RANGE {
+		return responsePack(berr.INVALID_PARAMS, fmt.Sprintf("range is larger than %d", bcomn.MAX_OUTBOUND_RANGE))
+	}
+	contract := utils.CrossChainManagerContractAddress
+	chainIDBytes := utils.GetUint64Bytes(uint64(chainID))
+
+	result := make([]bcomn.OutboundPayload, 0)
+	for seq := uint64(from); seq <= uint64(to); seq++ {
+		key := append(append([]byte(cross_chain_manager.OUTBOUND_PAYLOAD), chainIDBytes...), utils.GetUint64Bytes(seq)...)
+		value, err := bactor.GetStorageItem(contract, key)
+		if err != nil {
+			if err == scom.ErrNotFound {
+				break
+			}
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+		payload := new(cross_chain_manager.OutboundPayload)
+		if err := payload.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+		requestKey := append(append([]byte(ccom.REQUEST), chainIDBytes...), payload.TxHash...)
+		request, err := bactor.GetStorageItem(contract, requestKey)
+		if err != nil {
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+		result = append(result, bcomn.OutboundPayload{
+			Sequence:   seq,
+			TxHash:     hex.EncodeToString(payload.TxHash),
+			PolyHeight: payload.PolyHeight,
+			Key:        hex.EncodeToString(utils.ConcatKey(contract, requestKey)),
+			Payload:    hex.EncodeToString(request),
+		})
+	}
+	return responseSuccess(result)
+}
+
+// get the counters of cross chain manager, the transfers from a chain to another, the volume of an
+// asset on the target chain if its hash is given and the failed imports by reason
+// A JSON example for getcrosschainstats method as following:
+//   {"jsonrpc": "2.0", "method": "getcrosschainstats", "params": [2, 6, "asset hash in hex"], "id": 0}
+func GetCrossChainStats(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	fromChainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	toChainID, ok := params[1].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	getCounter := func(key []byte) ([]byte, error) {
+		value, err := bactor.GetStorageItem(utils.CrossChainManagerContractAddress, key)
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return value, err
+	}
+	toChainIDBytes := utils.GetUint64Bytes(uint64(toChainID))
+
+	key := append(append([]byte(cross_chain_manager.TRANSFER_COUNT), utils.GetUint64Bytes(uint64(fromChainID))...),
+		toChainIDBytes...)
+	value, err := getCounter(key)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	result := bcomn.CrossChainStats{
+		FromChainID: uint64(fromChainID),
+		ToChainID:   uint64(toChainID),
+		Transfers:   utils.GetBytesUint64(value),
+		Failures:    make(map[string]uint64),
+	}
+	if len(params) > 2 {
+		str, ok := params[2].(string)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		asset, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
+		if err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		value, err := getCounter(append(append([]byte(cross_chain_manager.ASSET_VOLUME), toChainIDBytes...), asset...))
+		if err != nil {
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+		result.Asset = hex.EncodeToString(asset)
+		result.Volume = new(big.Int).SetBytes(value).String()
+	}
+	for _, reason := range cross_chain_manager.FailureReasons {
+		value, err := getCounter(append([]byte(cross_chain_manager.FAILURE_COUNT), []byte(reason)...))
+		if err != nil {
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+		result.Failures[reason] = utils.GetBytesUint64(value)
+	}
+	return responseSuccess(result)
+}
+
+// get the most recent failed imports logged by cross chain manager, the latest first
+// A JSON example for getfailedimports method as following:
+//   {"jsonrpc": "2.0", "method": "getfailedimports", "params": [100], "id": 0}
+func GetFailedImports(params []interface{}) map[string]interface{} {
+	count := cross_chain_manager.FAILED_IMPORT_LOG_SIZE
+	if len(params) > 0 {
+		n, ok := params[0].(float64)
+		if !ok || n < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		count = uint64(n)
+	}
+	value, err := bactor.GetStorageItem(utils.CrossChainManagerContractAddress, []byte(cross_chain_manager.FAILED_IMPORT_COUNT))
+	if err != nil && err != scom.ErrNotFound {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	total := utils.GetBytesUint64(value)
+	if count > total {
+		count = total
+	}
+	if count > cross_chain_manager.FAILED_IMPORT_LOG_SIZE {
+		count = cross_chain_manager.FAILED_IMPORT_LOG_SIZE
+	}
+	result := make([]bcomn.FailedImportInfo, 0, count)
+	for index := total; index > total-count; index-- {
+		raw, err := bactor.GetStorageItem(utils.CrossChainManagerContractAddress, append([]byte(cross_chain_manager.FAILED_IMPORT),
+			utils.GetUint64Bytes((index-1)%cross_chain_manager.FAILED_IMPORT_LOG_SIZE)...))
+		if err != nil {
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+		item := new(cross_chain_manager.FailedImport)
+		if err := item.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+		result = append(result, bcomn.FailedImportInfo{
+			Index:     item.Index,
+			Height:    item.Height,
+			TxHash:    item.TxHash.ToHexString(),
+			ChainID:   item.ChainID,
+			ProofHash: hex.EncodeToString(item.ProofHash[:]),
+			Relayer:   hex.EncodeToString(item.Relayer),
+			Reason:    item.Reason,
+		})
+	}
+	return responseSuccess(result)
+}
+
+// get the proof verification latency and proof size by chain handler measured by the node, the
+// histograms since the node started and the totals of the given number of recent blocks
+// A JSON example for getverifymetrics method as following:
+//   {"jsonrpc": "2.0", "method": "getverifymetrics", "params": [100], "id": 0}
+func GetVerifyMetrics(params []interface{}) map[string]interface{} {
+	count := ccom.VERIFY_METRICS_BLOCKS
+	if len(params) > 0 {
+		n, ok := params[0].(float64)
+		if !ok || n < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		count = int(n)
+	}
+	handlers, blocks := ccom.GetVerifyMetrics()
+	if len(blocks) > 0 {
+		last := blocks[len(blocks)-1].Height
+		i := 0
+		for i < len(blocks) && int64(blocks[i].Height)+int64(count) <= int64(last) {
+			i++
+		}
+		blocks = blocks[i:]
+	}
+	return responseSuccess(bcomn.VerifyMetrics{Handlers: handlers, Blocks: blocks})
+}
+
+// get the btc transactions of a redeem waiting for signatures
+// A JSON example for getpendingmultisign method as following:
+//   {"jsonrpc": "2.0", "method": "getpendingmultisign", "params": [1, "redeem key in hex"], "id": 0}
+func GetPendingMultiSign(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	chainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	redeemKey, ok := params[1].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	key := append([]byte(btc.BTC_PENDING_MULTI_SIGN), utils.GetUint64Bytes(uint64(chainID))...)
+	key = append(key, []byte(redeemKey)...)
+	value, err := bactor.GetStorageItem(utils.CrossChainManagerContractAddress, key)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return responseSuccess([]bcomn.PendingMultiSign{})
+		}
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	pending := new(btc.PendingMultiSigns)
+	if err := pending.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	result := make([]bcomn.PendingMultiSign, 0, len(pending.Items))
+	for _, v := range pending.Items {
+		digests := make([]string, len(v.Digests))
+		for i, d := range v.Digests {
+			digests[i] = hex.EncodeToString(d)
+		}
+		result = append(result, bcomn.PendingMultiSign{
+			TxHash:  hex.EncodeToString(v.TxHash),
+			RawTx:   hex.EncodeToString(v.RawTx),
+			Digests: digests,
+		})
+	}
+	return responseSuccess(result)
+}
+
+// get the last reserve report of a btc redeem, comparing its coins with the amounts minted against them
+// A JSON example for getbtcreserve method as following:
+//   {"jsonrpc": "2.0", "method": "getbtcreserve", "params": [1, "redeem key in hex"], "id": 0}
+func GetBtcReserve(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	chainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	redeemKey, ok := params[1].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	key := append([]byte(btc.BTC_RESERVE_REPORT), utils.GetUint64Bytes(uint64(chainID))...)
+	key = append(key, []byte(redeemKey)...)
+	value, err := bactor.GetStorageItem(utils.CrossChainManagerContractAddress, key)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return responseSuccess(nil)
+		}
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	report := new(btc.ReserveReport)
+	if err := report.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	minted := make(map[uint64]uint64, len(report.Minted))
+	for _, v := range report.Minted {
+		minted[v.ToChainID] = v.Amount
+	}
+	ratio := ""
+	if report.Obligations > 0 {
+		ratio = new(big.Rat).SetFrac(new(big.Int).SetUint64(report.Reserve+report.Pending),
+			new(big.Int).SetUint64(report.Obligations)).FloatString(4)
+	}
+	return responseSuccess(bcomn.BtcReserve{
+		ChainID:     uint64(chainID),
+		RedeemKey:   redeemKey,
+		Height:      report.Height,
+		Reserve:     report.Reserve,
+		Pending:     report.Pending,
+		Obligations: report.Obligations,
+		Minted:      minted,
+		Ratio:       ratio,
+		Covered:     report.Covered(),
+	})
+}
+
+// get the governance proposals waiting for the signatures of consensus peers, each peer
+// approves one by sending a transaction invoking the method with the input itself
+func GetPendingConsensusSigns(params []interface{}) map[string]interface{} {
+	txn, err := client.GetPendingConsensusSigns().Transaction(
+		config.GetChainIdByNetId(config.DefConfig.P2PNode.NetworkId), uint32(time.Now().Unix()))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	res, err := bactor.PreExecuteContract(txn)
+	if err != nil {
+		return responsePack(berr.SMARTCODE_ERROR, err.Error())
+	}
+	value, err := common.HexToBytes(res.Result.(string))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	pending := new(node_manager.PendingConsensusSigns)
+	if err := pending.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	result := make([]bcomn.PendingConsensusSign, 0, len(pending.Items))
+	for _, v := range pending.Items {
+		signed := make([]string, 0)
+		raw, err := bactor.GetStorageItem(utils.NodeManagerContractAddress,
+			append([]byte(node_manager.CONSENSUS_SIGNS), v.Key.ToArray()...))
+		if err != nil && err != scom.ErrNotFound {
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+		if err == nil {
+			signs := new(node_manager.ConsensusSigns)
+			if err := signs.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+				return responsePack(berr.INTERNAL_ERROR, err.Error())
+			}
+			for addr := range signs.SignsMap {
+				signed = append(signed, addr.ToBase58())
+			}
+			sort.Strings(signed)
+		}
+		result = append(result, bcomn.PendingConsensusSign{
+			Key:    v.Key.ToHexString(),
+			Method: v.Method,
+			Input:  hex.EncodeToString(v.Input),
+			Signed: signed,
+		})
+	}
+	return responseSuccess(result)
+}
+
+// get all the chain ids ever registered on poly with their routers and statuses
+func GetSideChains(params []interface{}) map[string]interface{} {
+	value, err := bactor.GetStorageItem(utils.SideChainManagerContractAddress, []byte(side_chain_manager.CHAIN_ID_REGISTRY))
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return responseSuccess([]bcomn.SideChainInfo{})
+		}
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	registry := new(side_chain_manager.ChainIDRegistry)
+	if err := registry.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	result := make([]bcomn.SideChainInfo, 0, len(registry.Records))
+	for _, v := range registry.Records {
+		status, err := getSideChainStatus(v.ChainId)
+		if err != nil {
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+		result = append(result, bcomn.SideChainInfo{
+			ChainID: v.ChainId,
+			Name:    v.Name,
+			Router:  v.Router,
+			Status:  status,
+		})
+	}
+	return responseSuccess(result)
+}
+
+func getSideChainStatus(chainID uint64) (string, error) {
+	chainIDBytes := utils.GetUint64Bytes(chainID)
+	checks := []struct {
+		contract common.Address
+		prefix   string
+		status   string
+	}{
+		{utils.SideChainManagerContractAddress, side_chain_manager.SIDE_CHAIN, ""},
+		{utils.CrossChainManagerContractAddress, cross_chain_manager.BLACKED_CHAIN, "blacked"},
+		{utils.SideChainManagerContractAddress, side_chain_manager.SIDE_CHAIN_WIND_DOWN, "quitting"},
+		{utils.SideChainManagerContractAddress, side_chain_manager.BOND, "probation"},
+	}
+	for i, v := range checks {
+		_, err := bactor.GetStorageItem(v.contract, append([]byte(v.prefix), chainIDBytes...))
+		if err != nil && err != scom.ErrNotFound {
+			return "", err
+		}
+		if i == 0 && err == scom.ErrNotFound {
+			return "removed", nil
+		}
+		if i > 0 && err == nil {
+			return v.status, nil
+		}
+	}
+	return "active", nil
+}
+
+// submit the signatures of a btc keeper, they are wrapped into a MultiSign transaction
+// A JSON example for submitmultisign method as following:
+//   {"jsonrpc": "2.0", "method": "submitmultisign", "params": [1, "redeem key in hex", "tx hash in hex",
+//   "keeper address", ["sig of input 0 in hex", ...]], "id": 0}
+func SubmitMultiSign(params []interface{}) map[string]interface{} {
+	if len(params) < 5 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	chainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	redeemKey, ok := params[1].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[2].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	txHash, err := hex.DecodeString(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, ok := params[3].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	rawSigs, ok := params[4].([]interface{})
+	if !ok || len(rawSigs) == 0 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	sigs := make([][]byte, len(rawSigs))
+	for i, v := range rawSigs {
+		str, ok := v.(string)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		if sigs[i], err = hex.DecodeString(str); err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+
+	multiSignParam := &ccom.MultiSignParam{
+		ChainID:   uint64(chainID),
+		RedeemKey: redeemKey,
+		TxHash:    txHash,
+		Address:   address,
+		Signs:     sigs,
+	}
+	txn, err := client.MultiSign(multiSignParam).Transaction(
+		config.GetChainIdByNetId(config.DefConfig.P2PNode.NetworkId), uint32(time.Now().Unix()))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	hash := txn.Hash()
+	if errCode, desc := bcomn.SendTxToPool(txn); errCode != ontErrors.ErrNoError {
+		log.Warnf("SubmitMultiSign verified %s error: %s", hash.ToHexString(), desc)
+		return responsePack(int64(errCode), desc)
+	}
+	return responseSuccess(hash.ToHexString())
+}
@@ -51,13 +51,28 @@ func StartRPCServer() error {
 	rpc.HandleFunc("getmempooltxcount", rpc.GetMemPoolTxCount)
 	rpc.HandleFunc("getmempooltxstate", rpc.GetMemPoolTxState)
 	rpc.HandleFunc("getsmartcodeevent", rpc.GetSmartCodeEvent)
+	rpc.HandleFunc("getchainevents", rpc.GetChainEvents)
 	rpc.HandleFunc("getblockheightbytxhash", rpc.GetBlockHeightByTxHash)
 
 	rpc.HandleFunc("getmerkleproof", rpc.GetMerkleProof)
 	rpc.HandleFunc("getcrossstatesproof", rpc.GetCrossStatesProof)
+	rpc.HandleFunc("getcrossstatesaccproof", rpc.GetCrossStatesAccProof)
+	rpc.HandleFunc("getreceiptproof", rpc.GetReceiptProof)
 	rpc.HandleFunc("getheaderbyheight", rpc.GetHeaderByHeight)
+	rpc.HandleFunc("getcompactheader", rpc.GetCompactHeader)
 	rpc.HandleFunc("getblocktxsbyheight", rpc.GetBlockTxsByHeight)
 	rpc.HandleFunc("getstatemerkleroot", rpc.GetStateMerkleRoot)
+	rpc.HandleFunc("getsideheader", rpc.GetSideHeader)
+	rpc.HandleFunc("gettxsbyrange", rpc.GetTxsByRange)
+	rpc.HandleFunc("getcrosschainstats", rpc.GetCrossChainStats)
+	rpc.HandleFunc("getverifymetrics", rpc.GetVerifyMetrics)
+	rpc.HandleFunc("getfailedimports", rpc.GetFailedImports)
+
+	rpc.HandleFunc("getpendingmultisign", rpc.GetPendingMultiSign)
+	rpc.HandleFunc("getbtcreserve", rpc.GetBtcReserve)
+	rpc.HandleFunc("getpendingconsensussigns", rpc.GetPendingConsensusSigns)
+	rpc.HandleFunc("getsidechains", rpc.GetSideChains)
+	rpc.HandleFunc("submitmultisign", rpc.SubmitMultiSign)
 
 	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpJsonPort)), nil)
 	if err != nil {
@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package publisher
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const NATS_TIMEOUT = 10 * time.Second
+
+// NatsSink publishes to a NATS server over its text protocol. A batch is
+// followed by a PING and is accepted once the server answers PONG, which it
+// does only after processing every PUB sent before it.
+type NatsSink struct {
+	address string
+	conn    net.Conn
+	reader  *bufio.Reader
+}
+
+func NewNatsSink(address string) *NatsSink {
+	return &NatsSink{address: address}
+}
+
+func (this *NatsSink) connect() error {
+	conn, err := net.DialTimeout("tcp", this.address, NATS_TIMEOUT)
+	if err != nil {
+		return fmt.Errorf("dial nats %s error: %s", this.address, err)
+	}
+	conn.SetDeadline(time.Now().Add(NATS_TIMEOUT))
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("read nats info error: %s", err)
+	}
+	if !strings.HasPrefix(line, "INFO") {
+		conn.Close()
+		return fmt.Errorf("unexpected nats greeting: %s", strings.TrimSpace(line))
+	}
+	if _, err := conn.Write([]byte("CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"poly\"}\r\n")); err != nil {
+		conn.Close()
+		return fmt.Errorf("write nats connect error: %s", err)
+	}
+	this.conn, this.reader = conn, reader
+	return nil
+}
+
+func (this *NatsSink) Publish(msgs []*Envelope) error {
+	if this.conn == nil {
+		if err := this.connect(); err != nil {
+			return err
+		}
+	}
+	if err := this.publish(msgs); err != nil {
+		this.Close()
+		return err
+	}
+	return nil
+}
+
+func (this *NatsSink) publish(msgs []*Envelope) error {
+	this.conn.SetDeadline(time.Now().Add(NATS_TIMEOUT))
+	w := bufio.NewWriter(this.conn)
+	for _, msg := range msgs {
+		fmt.Fprintf(w, "PUB %s %d\r\n", msg.Subject, len(msg.Data))
+		w.Write(msg.Data)
+		w.WriteString("\r\n")
+	}
+	w.WriteString("PING\r\n")
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write nats messages error: %s", err)
+	}
+	for {
+		line, err := this.reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("read nats reply error: %s", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := this.conn.Write([]byte("PONG\r\n")); err != nil {
+				return fmt.Errorf("write nats pong error: %s", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats error: %s", line)
+		}
+	}
+}
+
+func (this *NatsSink) Close() {
+	if this.conn == nil {
+		return
+	}
+	this.conn.Close()
+	this.conn, this.reader = nil, nil
+}
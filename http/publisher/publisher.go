@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package publisher pushes the cross chain events of the persisted blocks to
+// an external message queue. The events of a block are published before the
+// checkpoint moves past it, so a restart may publish the events of the last
+// block again but never skips one, consumers dedupe on the position of the
+// message.
+//
+// An event is published on the subject of the source and the destination
+// chain, <subject>.<from>.<to> with _ for a chain the event is not bound to,
+// so that e.g. the relayer of chain 2 subscribes to <subject>.*.2.
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	cfg "github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/events/message"
+	bactor "github.com/polynetwork/poly/http/base/actor"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	// RETRY_INTERVAL is the wait before the publishing is retried after an error,
+	// it also bounds the delay of a block whose save notification is missed
+	RETRY_INTERVAL = 5 * time.Second
+	// MAX_BLOCKS_PER_ROUND bounds the blocks published before the quit signal is
+	// checked again while catching up
+	MAX_BLOCKS_PER_ROUND = 100
+)
+
+// Message is the published form of a cross chain event. Height, TxIndex and
+// EventIndex are the position of the event in the chain, a message with a
+// position already seen is a redelivery.
+type Message struct {
+	Height          uint32
+	TxIndex         uint32
+	EventIndex      uint32
+	TxHash          string
+	ContractAddress string
+	States          interface{}
+	FromChainID     *uint64 `json:",omitempty"`
+	ToChainID       *uint64 `json:",omitempty"`
+}
+
+// Envelope is a message with the subject it is published on
+type Envelope struct {
+	Subject string
+	Data    []byte
+}
+
+// Sink is the message queue the events are published to. Publish returns once
+// the queue has accepted all the messages.
+type Sink interface {
+	Publish(msgs []*Envelope) error
+	Close()
+}
+
+type Publisher struct {
+	sink       Sink
+	subject    string
+	checkpoint string
+	next       uint32
+	wake       chan struct{}
+	quit       chan struct{}
+}
+
+var publisher *Publisher
+
+// StartServer starts publishing the cross chain events to the configured queue
+// from the block after the checkpoint.
+func StartServer() error {
+	config := cfg.DefConfig.Publisher
+	checkpoint := config.CheckpointPath
+	if checkpoint == "" {
+		checkpoint = filepath.Join(cfg.DefConfig.Common.DataDir, cfg.DEFAULT_PUBLISH_CHECKPOINT)
+	}
+	p, err := NewPublisher(NewNatsSink(config.NatsAddress), config.Subject, checkpoint)
+	if err != nil {
+		return err
+	}
+	publisher = p
+	bactor.SubscribeEvent(message.TOPIC_SAVE_BLOCK_COMPLETE, func(v interface{}) {
+		p.notify()
+	})
+	go p.run()
+	return nil
+}
+
+func Stop() {
+	if publisher == nil {
+		return
+	}
+	close(publisher.quit)
+	publisher = nil
+}
+
+func NewPublisher(sink Sink, subject, checkpoint string) (*Publisher, error) {
+	next, err := loadCheckpoint(checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{
+		sink:       sink,
+		subject:    subject,
+		checkpoint: checkpoint,
+		next:       next,
+		wake:       make(chan struct{}, 1),
+		quit:       make(chan struct{}),
+	}, nil
+}
+
+func (this *Publisher) notify() {
+	select {
+	case this.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (this *Publisher) run() {
+	defer this.sink.Close()
+	ticker := time.NewTicker(RETRY_INTERVAL)
+	defer ticker.Stop()
+	for {
+		caughtUp, err := this.publishBlocks(bactor.GetCurrentBlockHeight())
+		if err != nil {
+			log.Errorf("publisher: publish block %d error: %s", this.next, err)
+		}
+		if err == nil && !caughtUp {
+			select {
+			case <-this.quit:
+				return
+			default:
+				continue
+			}
+		}
+		select {
+		case <-this.quit:
+			return
+		case <-this.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishBlocks publishes at most MAX_BLOCKS_PER_ROUND blocks up to current,
+// the checkpoint is saved after each block.
+func (this *Publisher) publishBlocks(current uint32) (bool, error) {
+	for i := 0; i < MAX_BLOCKS_PER_ROUND; i++ {
+		if this.next > current {
+			return true, nil
+		}
+		msgs, err := blockMessages(this.subject, this.next)
+		if err != nil {
+			return false, err
+		}
+		if len(msgs) != 0 {
+			if err := this.sink.Publish(msgs); err != nil {
+				return false, err
+			}
+		}
+		if err := saveCheckpoint(this.checkpoint, this.next); err != nil {
+			return false, err
+		}
+		this.next++
+	}
+	return this.next > current, nil
+}
+
+func chainToken(chainID *uint64) string {
+	if chainID == nil {
+		return "_"
+	}
+	return strconv.FormatUint(*chainID, 10)
+}
+
+func blockMessages(subject string, height uint32) ([]*Envelope, error) {
+	notifies, err := bactor.GetEventNotifyByHeight(height)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetEventNotifyByHeight error: %s", err)
+	}
+	msgs := make([]*Envelope, 0)
+	for i, notify := range notifies {
+		for j, v := range notify.Notify {
+			if v.ContractAddress != utils.CrossChainManagerContractAddress {
+				continue
+			}
+			msg, err := json.Marshal(&Message{
+				Height:          height,
+				TxIndex:         uint32(i),
+				EventIndex:      uint32(j),
+				TxHash:          notify.TxHash.ToHexString(),
+				ContractAddress: v.ContractAddress.ToHexString(),
+				States:          v.States,
+				FromChainID:     v.FromChainID,
+				ToChainID:       v.ToChainID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("marshal No.%d event of tx %s error: %s", j, notify.TxHash.ToHexString(), err)
+			}
+			msgs = append(msgs, &Envelope{
+				Subject: subject + "." + chainToken(v.FromChainID) + "." + chainToken(v.ToChainID),
+				Data:    msg,
+			})
+		}
+	}
+	return msgs, nil
+}
+
+// loadCheckpoint returns the height to publish from, the one after the last
+// published height or 0 if nothing is published yet
+func loadCheckpoint(path string) (uint32, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read checkpoint %s error: %s", path, err)
+	}
+	height, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("parse checkpoint %s error: %s", path, err)
+	}
+	return uint32(height) + 1, nil
+}
+
+// saveCheckpoint records height as published, the file is replaced by rename
+// so that a crash leaves either the old or the new checkpoint
+func saveCheckpoint(path string, height uint32) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(strconv.FormatUint(uint64(height), 10)), 0644); err != nil {
+		return fmt.Errorf("write checkpoint error: %s", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename checkpoint error: %s", err)
+	}
+	return nil
+}
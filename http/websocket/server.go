@@ -38,6 +38,7 @@ var ws *websocket.WsServer
 func StartServer() {
 	bactor.SubscribeEvent(message.TOPIC_SAVE_BLOCK_COMPLETE, sendBlock2WSclient)
 	bactor.SubscribeEvent(message.TOPIC_SMART_CODE_EVENT, pushSmartCodeEvent)
+	bactor.SubscribeEvent(message.TOPIC_STATE_DIFF, pushStateDiff)
 	go func() {
 		ws = websocket.InitWsServer()
 		ws.Start()
@@ -48,6 +49,7 @@ func sendBlock2WSclient(v interface{}) {
 		go func() {
 			pushBlock(v)
 			pushBlockTransactions(v)
+			pushBlockEvents(v)
 		}()
 	}
 }
@@ -123,3 +125,26 @@ func pushBlockTransactions(v interface{}) {
 		ws.BroadcastToSubscribers(nil, websocket.WSTOPIC_TXHASHS, resp)
 	}
 }
+
+// pushBlockEvents pushes the events of the saved block to the event subscriptions, the
+// heights missed are caught up
+func pushBlockEvents(v interface{}) {
+	if ws == nil {
+		return
+	}
+	if block, ok := v.(types.Block); ok {
+		ws.PushEvents(block.Header.Height)
+	}
+}
+
+func pushStateDiff(v interface{}) {
+	if ws == nil {
+		return
+	}
+	resp := rest.ResponsePack(Err.SUCCESS)
+	if diff, ok := v.(*types.StateDiff); ok {
+		resp["Result"] = bcomn.GetStateDiff(diff)
+		resp["Action"] = "sendstatediff"
+		ws.BroadcastToSubscribers(nil, websocket.WSTOPIC_STATE_DIFF, resp)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package websocket
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/polynetwork/poly/common/log"
+	scom "github.com/polynetwork/poly/core/store/common"
+	bactor "github.com/polynetwork/poly/http/base/actor"
+	bcomn "github.com/polynetwork/poly/http/base/common"
+	Err "github.com/polynetwork/poly/http/base/error"
+	"github.com/polynetwork/poly/http/base/rest"
+	"github.com/polynetwork/poly/native/event"
+)
+
+// MAX_EVENT_BACKFILL_BLOCKS bounds the heights backfilled by subscribeevents, the older
+// events are queried page by page with getsmartcodeevent
+const MAX_EVENT_BACKFILL_BLOCKS uint32 = 100000
+
+// eventSubscribe pushes the events of Contracts named in EventNames block by block, an empty
+// list matches any. next is the height to push next, the heights are pushed in order once.
+type eventSubscribe struct {
+	sync.Mutex
+	Contracts  []string
+	EventNames []string
+	next       uint32
+}
+
+func (this *eventSubscribe) match(evt *bcomn.NotifyEventInfo) bool {
+	if len(this.Contracts) > 0 {
+		found := false
+		for _, contract := range this.Contracts {
+			if strings.EqualFold(contract, evt.ContractAddress) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(this.EventNames) == 0 {
+		return true
+	}
+	states, ok := evt.States.([]interface{})
+	if !ok || len(states) == 0 {
+		return false
+	}
+	name, ok := states[0].(string)
+	if !ok {
+		return false
+	}
+	for _, v := range this.EventNames {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// filter keeps the txs with matched events and the matched events of them
+func (this *eventSubscribe) filter(eventInfos []*event.ExecuteNotify) []*bcomn.ExecuteNotify {
+	notifies := make([]*bcomn.ExecuteNotify, 0)
+	for _, eventInfo := range eventInfos {
+		_, notify := bcomn.GetExecuteNotify(eventInfo)
+		evts := make([]bcomn.NotifyEventInfo, 0, len(notify.Notify))
+		for i := range notify.Notify {
+			if this.match(&notify.Notify[i]) {
+				evts = append(evts, notify.Notify[i])
+			}
+		}
+		if len(evts) > 0 {
+			notify.Notify = evts
+			notifies = append(notifies, &notify)
+		}
+	}
+	return notifies
+}
+
+func stringList(v interface{}) []string {
+	list := []string{}
+	items, _ := v.([]interface{})
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// subscribeEvents replaces the event subscription of the session. The events from Height are
+// backfilled before the ones of the new blocks, only the new blocks are pushed without Height.
+// The pushes may arrive ahead of the reply of the subscription.
+func (self *WsServer) subscribeEvents(cmd map[string]interface{}) map[string]interface{} {
+	sessionId, _ := cmd["SessionId"].(string)
+	current := bactor.GetCurrentBlockHeight()
+	sub := &eventSubscribe{
+		Contracts:  stringList(cmd["Contracts"]),
+		EventNames: stringList(cmd["EventNames"]),
+		next:       current + 1,
+	}
+	if param, ok := cmd["Height"].(string); ok {
+		height, err := strconv.ParseUint(param, 10, 32)
+		if err != nil || uint32(height) > current+1 || current+1-uint32(height) > MAX_EVENT_BACKFILL_BLOCKS {
+			return rest.ResponsePack(Err.INVALID_PARAMS)
+		}
+		sub.next = uint32(height)
+	}
+	self.Lock()
+	self.EventSubs[sessionId] = sub
+	self.Unlock()
+	go self.pushEvents(sessionId, sub, current)
+
+	resp := rest.ResponsePack(Err.SUCCESS)
+	resp["Result"] = map[string]interface{}{
+		"Contracts":  sub.Contracts,
+		"EventNames": sub.EventNames,
+		"Height":     sub.next,
+	}
+	return resp
+}
+
+func (self *WsServer) unsubscribeEvents(cmd map[string]interface{}) map[string]interface{} {
+	sessionId, _ := cmd["SessionId"].(string)
+	self.Lock()
+	delete(self.EventSubs, sessionId)
+	self.Unlock()
+	return rest.ResponsePack(Err.SUCCESS)
+}
+
+// PushEvents pushes the events up to height to the event subscriptions
+func (self *WsServer) PushEvents(height uint32) {
+	self.RLock()
+	subs := make(map[string]*eventSubscribe, len(self.EventSubs))
+	for k, v := range self.EventSubs {
+		subs[k] = v
+	}
+	self.RUnlock()
+	for sessionId, sub := range subs {
+		go self.pushEvents(sessionId, sub, height)
+	}
+}
+
+// pushEvents pushes the events of sub from its next height up to height, it stops once the
+// subscription is replaced or the session is closed. A height failed to push is retried by
+// the push of the next block.
+func (self *WsServer) pushEvents(sessionId string, sub *eventSubscribe, height uint32) {
+	sub.Lock()
+	defer sub.Unlock()
+	for ; sub.next <= height; sub.next++ {
+		self.RLock()
+		current := self.EventSubs[sessionId]
+		self.RUnlock()
+		s := self.SessionList.GetSessionById(sessionId)
+		if current != sub || s == nil {
+			return
+		}
+		eventInfos, err := bactor.GetEventNotifyByHeight(sub.next)
+		if err != nil {
+			if err == scom.ErrNotFound {
+				continue
+			}
+			log.Errorf("websocket pushEvents, get events of height %d error: %s", sub.next, err)
+			return
+		}
+		notifies := sub.filter(eventInfos)
+		if len(notifies) == 0 {
+			continue
+		}
+		resp := rest.ResponsePack(Err.SUCCESS)
+		resp["Action"] = "pushevents"
+		resp["Result"] = map[string]interface{}{
+			"Height": sub.next,
+			"Events": notifies,
+		}
+		if err := s.Send(marshalResp(resp)); err != nil {
+			return
+		}
+	}
+}
@@ -43,6 +43,7 @@ const (
 	WSTOPIC_JSON_BLOCK = 2
 	WSTOPIC_RAW_BLOCK  = 3
 	WSTOPIC_TXHASHS    = 4
+	WSTOPIC_STATE_DIFF = 5
 )
 
 type handler func(map[string]interface{}) map[string]interface{}
@@ -58,16 +59,18 @@ type subscribe struct {
 	SubscribeJsonBlock    bool     `json:"SubscribeJsonBlock"`
 	SubscribeRawBlock     bool     `json:"SubscribeRawBlock"`
 	SubscribeBlockTxHashs bool     `json:"SubscribeBlockTxHashs"`
+	SubscribeStateDiff    bool     `json:"SubscribeStateDiff"`
 }
 type WsServer struct {
 	sync.RWMutex
 	Upgrader     websocket.Upgrader
 	listener     net.Listener
 	server       *http.Server
-	SessionList  *session.SessionList // websocket sesseionlist
-	ActionMap    map[string]Handler   //handler functions
-	TxHashMap    map[string]string    //key: txHash   value:sessionid
-	SubscribeMap map[string]subscribe //key: sessionId   value:subscribeInfo
+	SessionList  *session.SessionList       // websocket sesseionlist
+	ActionMap    map[string]Handler         //handler functions
+	TxHashMap    map[string]string          //key: txHash   value:sessionid
+	SubscribeMap map[string]subscribe       //key: sessionId   value:subscribeInfo
+	EventSubs    map[string]*eventSubscribe //key: sessionId   value:event subscription
 }
 
 //init websocket server
@@ -77,6 +80,7 @@ func InitWsServer() *WsServer {
 		SessionList:  session.NewSessionList(),
 		TxHashMap:    make(map[string]string),
 		SubscribeMap: make(map[string]subscribe),
+		EventSubs:    make(map[string]*eventSubscribe),
 	}
 	return ws
 }
@@ -157,6 +161,9 @@ func (self *WsServer) registryMethod() {
 		if b, ok := cmd["SubscribeBlockTxHashs"].(bool); ok {
 			sub.SubscribeBlockTxHashs = b
 		}
+		if b, ok := cmd["SubscribeStateDiff"].(bool); ok {
+			sub.SubscribeStateDiff = b
+		}
 		if ctsf, ok := cmd["ContractsFilter"].([]interface{}); ok {
 			sub.ContractsFilter = []string{}
 			for _, v := range ctsf {
@@ -190,6 +197,8 @@ func (self *WsServer) registryMethod() {
 		"sendrawtransaction":        {handler: rest.SendRawTransaction, pushFlag: true},
 		"heartbeat":                 {handler: heartbeat},
 		"subscribe":                 {handler: subscribe},
+		"subscribeevents":           {handler: self.subscribeEvents},
+		"unsubscribeevents":         {handler: self.unsubscribeEvents},
 		"getstorage":                {handler: rest.GetStorage},
 		"getmerkleproof":            {handler: rest.GetMerkleProof},
 		"getblocktxsbyheight":       {handler: rest.GetBlockTxsByHeight},
@@ -369,6 +378,7 @@ func (self *WsServer) deleteSubscribe(sessionId string) {
 	self.Lock()
 	defer self.Unlock()
 	delete(self.SubscribeMap, sessionId)
+	delete(self.EventSubs, sessionId)
 }
 
 func marshalResp(resp map[string]interface{}) []byte {
@@ -423,6 +433,8 @@ func (self *WsServer) BroadcastToSubscribers(contractAddrs map[string]bool, sub
 			s.Send(data)
 		} else if sub == WSTOPIC_TXHASHS && v.SubscribeBlockTxHashs {
 			s.Send(data)
+		} else if sub == WSTOPIC_STATE_DIFF && v.SubscribeStateDiff {
+			s.Send(data)
 		} else if sub == WSTOPIC_EVENT && v.SubscribeEvent {
 			if len(v.ContractsFilter) == 0 {
 				s.Send(data)
@@ -48,6 +48,7 @@ import (
 	"github.com/polynetwork/poly/http/jsonrpc"
 	"github.com/polynetwork/poly/http/localrpc"
 	"github.com/polynetwork/poly/http/nodeinfo"
+	"github.com/polynetwork/poly/http/publisher"
 	"github.com/polynetwork/poly/http/restful"
 	"github.com/polynetwork/poly/http/websocket"
 	_ "github.com/polynetwork/poly/native/service"
@@ -73,6 +74,10 @@ func setupAPP() *cli.App {
 		cmd.InfoCommand,
 		cmd.ImportCommand,
 		cmd.ExportCommand,
+		cmd.SnapshotCommand,
+		cmd.ReplayCrossChainCommand,
+		cmd.UtxoCommand,
+		cmd.DoctorCommand,
 		cmd.SigTxCommand,
 		cmd.MultiSigAddrCommand,
 		cmd.MultiSigTxCommand,
@@ -85,6 +90,9 @@ func setupAPP() *cli.App {
 		utils.LogLevelFlag,
 		utils.DisableEventLogFlag,
 		utils.DataDirFlag,
+		utils.ExecWorkersFlag,
+		utils.StateDiffFlag,
+		utils.StateDiffDirFlag,
 		//account setting
 		utils.WalletFileFlag,
 		utils.AccountAddressFlag,
@@ -94,6 +102,8 @@ func setupAPP() *cli.App {
 		utils.MaxTxInBlockFlag,
 		//txpool setting
 		utils.TxpoolPreExecDisableFlag,
+		utils.TxpoolHeaderSyncWeightFlag,
+		utils.TxpoolImportTransferWeightFlag,
 		utils.DisableSyncVerifyTxFlag,
 		utils.DisableBroadcastNetTxFlag,
 		//p2p setting
@@ -122,6 +132,11 @@ func setupAPP() *cli.App {
 		//ws setting
 		utils.WsEnabledFlag,
 		utils.WsPortFlag,
+		//publisher setting
+		utils.PublishEnabledFlag,
+		utils.PublishNatsFlag,
+		utils.PublishSubjectFlag,
+		utils.PublishCheckpointFlag,
 	}
 	app.Before = func(context *cli.Context) error {
 		runtime.GOMAXPROCS(runtime.NumCPU())
@@ -188,6 +203,7 @@ func startOntology(ctx *cli.Context) {
 	}
 	initRestful(ctx)
 	initWs(ctx)
+	initPublisher(ctx)
 	initNodeInfo(ctx, p2pSvr)
 
 	go logCurrBlockHeight()
@@ -399,6 +415,22 @@ func initWs(ctx *cli.Context) {
 	log.Infof("Ws init success")
 }
 
+func initPublisher(ctx *cli.Context) {
+	if !config.DefConfig.Publisher.EnablePublisher {
+		return
+	}
+	if !config.DefConfig.Common.EnableEventLog {
+		log.Warnf("Publisher needs the event log, nothing is published")
+		return
+	}
+	if err := publisher.StartServer(); err != nil {
+		log.Errorf("initPublisher error: %s", err)
+		return
+	}
+
+	log.Infof("Publisher init success")
+}
+
 func initNodeInfo(ctx *cli.Context, p2pSvr *p2pserver.P2PServer) {
 	if config.DefConfig.P2PNode.HttpInfoPort == 0 {
 		return
@@ -196,12 +196,27 @@ func MerkleHashes(preLeaves []common.Uint256, depth int) [][]common.Uint256 {
 }
 
 func MerkleProve(path []byte, root []byte) ([]byte, error) {
+	return MerkleProveWithHash(path, root, func(data []byte) []byte {
+		sum := sha256.Sum256(data)
+		return sum[:]
+	})
+}
+
+// MerkleProveWithHash is MerkleProve for the trees whose leaves and children are digested by
+// hashFunc instead of sha256, hashFunc must return 32 bytes
+func MerkleProveWithHash(path []byte, root []byte, hashFunc func([]byte) []byte) ([]byte, error) {
+	digest := func(data []byte) (common.Uint256, error) {
+		return common.Uint256ParseFromBytes(hashFunc(data))
+	}
 	source := common.NewZeroCopySource(path)
 	value, eof := source.NextVarBytes()
 	if eof {
 		return nil, errors.New("read bytes error")
 	}
-	hash := HashLeaf(value)
+	hash, err := digest(append([]byte{0}, value...))
+	if err != nil {
+		return nil, fmt.Errorf("hash leaf error: %v", err)
+	}
 	size := int((source.Size() - source.Pos()) / (common.UINT256_SIZE + 1))
 	for i := 0; i < size; i++ {
 		f, eof := source.NextByte()
@@ -212,10 +227,14 @@ func MerkleProve(path []byte, root []byte) ([]byte, error) {
 		if eof {
 			return nil, errors.New("read hash error")
 		}
+		data := []byte{1}
 		if f == LEFT {
-			hash = HashChildren(v, hash)
+			data = append(append(data, v[:]...), hash[:]...)
 		} else {
-			hash = HashChildren(hash, v)
+			data = append(append(data, hash[:]...), v[:]...)
+		}
+		if hash, err = digest(data); err != nil {
+			return nil, fmt.Errorf("hash children error: %v", err)
 		}
 	}
 
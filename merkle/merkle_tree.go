@@ -148,15 +148,25 @@ func (self *CompactMerkleTree) GetRootWithNewLeaves(newLeaf []common.Uint256) co
 	return tree.Root()
 }
 
+// GetRootWithNewLeafHashes returns the new root hash if the leaf hashes are appended to the merkle tree
+func (self *CompactMerkleTree) GetRootWithNewLeafHashes(leafHashes []common.Uint256) common.Uint256 {
+	tree := self.cloneMem()
+	for _, h := range leafHashes {
+		tree.AppendHash(h)
+	}
+
+	return tree.Root()
+}
+
 // Append appends a leaf to the merkle tree and returns the audit path
 func (self *CompactMerkleTree) Append(leafv []byte) []common.Uint256 {
 	leaf := self.hasher.hash_leaf(leafv)
 
-	return self.appendHash(leaf)
+	return self.AppendHash(leaf)
 }
 
 // AppendHash appends a leaf hash to the merkle tree and returns the audit path
-func (self *CompactMerkleTree) appendHash(leaf common.Uint256) []common.Uint256 {
+func (self *CompactMerkleTree) AppendHash(leaf common.Uint256) []common.Uint256 {
 	size := len(self.hashes)
 	auditPath := make([]common.Uint256, size, size)
 	storehashes := make([]common.Uint256, 0)
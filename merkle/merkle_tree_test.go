@@ -26,6 +26,7 @@ import (
 
 	"github.com/polynetwork/poly/common"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
 )
 
 func TestMerkleLeaf3(t *testing.T) {
@@ -324,3 +325,36 @@ func TestAudit(t *testing.T) {
 		assert.Equal(t, []byte(fmt.Sprintf("%d", i)), value)
 	}
 }
+
+func TestMerkleProveWithHash(t *testing.T) {
+	keccak := func(data []byte) []byte {
+		h := sha3.NewLegacyKeccak256()
+		h.Write(data)
+		return h.Sum(nil)
+	}
+	leaf := func(data []byte) []byte {
+		return keccak(append([]byte{0}, data...))
+	}
+	children := func(left, right []byte) []byte {
+		return keccak(append(append([]byte{1}, left...), right...))
+	}
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+	root := children(children(leaf(a), leaf(b)), leaf(c))
+
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(b)
+	sink.WriteByte(LEFT)
+	sink.WriteBytes(leaf(a))
+	sink.WriteByte(RIGHT)
+	sink.WriteBytes(leaf(c))
+	value, err := MerkleProveWithHash(sink.Bytes(), root, keccak)
+	assert.NoError(t, err)
+	assert.Equal(t, b, value)
+
+	_, err = MerkleProve(sink.Bytes(), root)
+	assert.Error(t, err)
+	_, err = MerkleProveWithHash(sink.Bytes(), root, func(data []byte) []byte {
+		return keccak(data)[:20]
+	})
+	assert.Error(t, err)
+}
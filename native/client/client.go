@@ -0,0 +1,546 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+// Package client builds the transactions invoking the native contracts, the arguments are the
+// very param types the contracts deserialize so that the encoding can't drift from them.
+package client
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/governance/treasury"
+	"github.com/polynetwork/poly/native/service/header_sync"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+)
+
+type serializable interface {
+	Serialization(sink *common.ZeroCopySink)
+}
+
+// Invocation is a call to a method of a native contract
+type Invocation struct {
+	Contract common.Address
+	Method   string
+	Args     []byte
+}
+
+func newInvocation(contract common.Address, method string, param serializable) *Invocation {
+	sink := common.NewZeroCopySink(nil)
+	if param != nil {
+		param.Serialization(sink)
+	}
+	return &Invocation{
+		Contract: contract,
+		Method:   method,
+		Args:     sink.Bytes(),
+	}
+}
+
+// Code returns the invoke code of the invocation
+func (this *Invocation) Code() []byte {
+	param := &states.ContractInvokeParam{
+		Address: this.Contract,
+		Method:  this.Method,
+		Args:    this.Args,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	return sink.Bytes()
+}
+
+// Transaction returns the unsigned transaction of the invocation for the poly chain chainID
+func (this *Invocation) Transaction(chainID uint64, nonce uint32) (*types.Transaction, error) {
+	tx := &types.Transaction{
+		Version: types.CURR_TX_VERSION,
+		TxType:  types.Invoke,
+		Payload: &payload.InvokeCode{Code: this.Code()},
+		Nonce:   nonce,
+		ChainID: chainID,
+	}
+	sink := common.NewZeroCopySink(nil)
+	if err := tx.Serialization(sink); err != nil {
+		return nil, fmt.Errorf("Transaction, serialize transaction error: %v", err)
+	}
+	// decode again to fill the raw bytes and the hash
+	tx, err := types.TransactionFromRawBytes(sink.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Transaction, deserialize transaction error: %v", err)
+	}
+	return tx, nil
+}
+
+// node manager
+
+func RegisterCandidate(param *node_manager.RegisterPeerParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.REGISTER_CANDIDATE, param)
+}
+
+func UnRegisterCandidate(param *node_manager.PeerParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.UNREGISTER_CANDIDATE, param)
+}
+
+func ApproveCandidate(param *node_manager.PeerParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.APPROVE_CANDIDATE, param)
+}
+
+func BlackNode(param *node_manager.PeerListParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.BLACK_NODE, param)
+}
+
+func WhiteNode(param *node_manager.PeerParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.WHITE_NODE, param)
+}
+
+func QuitNode(param *node_manager.PeerParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.QUIT_NODE, param)
+}
+
+func UpdateConfig(param *node_manager.UpdateConfigParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.UPDATE_CONFIG, param)
+}
+
+func CommitDpos() *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.COMMIT_DPOS, nil)
+}
+
+// ResetConfig re-runs the initialization of node manager with configuration, dev chains only
+func ResetConfig(configuration *config.VBFTConfig) (*Invocation, error) {
+	sink := common.NewZeroCopySink(nil)
+	if err := configuration.Serialization(sink); err != nil {
+		return nil, fmt.Errorf("%s, serialize configuration error: %v", node_manager.RESET_CONFIG, err)
+	}
+	return &Invocation{
+		Contract: utils.NodeManagerContractAddress,
+		Method:   node_manager.RESET_CONFIG,
+		Args:     sink.Bytes(),
+	}, nil
+}
+
+func SetApplyLimit(param *node_manager.ApplyLimitParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.SET_APPLY_LIMIT, param)
+}
+
+func SetMutationLimit(param *node_manager.MutationLimitParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.SET_MUTATION_LIMIT, param)
+}
+
+func GetPendingApplies() *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.GET_PENDING_APPLIES, nil)
+}
+
+func GetBlackList() *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.GET_BLACK_LIST, nil)
+}
+
+func GetPeerIndexes() *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.GET_PEER_INDEXES, nil)
+}
+
+func Slash(param *node_manager.SlashParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.SLASH, param)
+}
+
+func SetSlashLimit(param *node_manager.SlashLimitParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.SET_SLASH_LIMIT, param)
+}
+
+func SetOperatorCommittee(param *node_manager.OperatorCommitteeParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.SET_OPERATOR_COMMITTEE, param)
+}
+
+// ApproveOperatorCall approves inv as a member of the operator committee, inv is invoked by node manager once
+// the committee approved it
+func ApproveOperatorCall(inv *Invocation, member common.Address) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.APPROVE_OPERATOR_CALL, &node_manager.OperatorCallParam{
+		Contract: inv.Contract,
+		Method:   inv.Method,
+		Args:     inv.Args,
+		Address:  member,
+	})
+}
+
+func GetOperatorCommittee() *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.GET_OPERATOR_COMMITTEE, nil)
+}
+
+// GetPendingConsensusSigns queries the governance proposals waiting for the signs of the consensus peers
+func GetPendingConsensusSigns() *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.GET_PENDING_SIGNS, nil)
+}
+
+// GetEvidences queries the evidences recorded against the peer of the hex encoded peerPubkey
+func GetEvidences(peerPubkey string) *Invocation {
+	return &Invocation{
+		Contract: utils.NodeManagerContractAddress,
+		Method:   node_manager.GET_EVIDENCES,
+		Args:     []byte(peerPubkey),
+	}
+}
+
+// ValidateConfig checks the configuration against the constraints of UpdateConfig by preExec
+func ValidateConfig(param *node_manager.UpdateConfigParam) *Invocation {
+	return newInvocation(utils.NodeManagerContractAddress, node_manager.VALIDATE_CONFIG, param)
+}
+
+// relayer manager
+
+func RegisterRelayer(param *relayer_manager.RelayerListParam) *Invocation {
+	return newInvocation(utils.RelayerManagerContractAddress, relayer_manager.REGISTER_RELAYER, param)
+}
+
+func ApproveRegisterRelayer(param *relayer_manager.ApproveRelayerParam) *Invocation {
+	return newInvocation(utils.RelayerManagerContractAddress, relayer_manager.APPROVE_REGISTER_RELAYER, param)
+}
+
+func RemoveRelayer(param *relayer_manager.RelayerListParam) *Invocation {
+	return newInvocation(utils.RelayerManagerContractAddress, relayer_manager.REMOVE_RELAYER, param)
+}
+
+func ApproveRemoveRelayer(param *relayer_manager.ApproveRelayerParam) *Invocation {
+	return newInvocation(utils.RelayerManagerContractAddress, relayer_manager.APPROVE_REMOVE_RELAYER, param)
+}
+
+func SetRelayerConfig(param *relayer_manager.RelayerConfigParam) *Invocation {
+	return newInvocation(utils.RelayerManagerContractAddress, relayer_manager.SET_RELAYER_CONFIG, param)
+}
+
+// GetRelayer queries the stake of the registered relayer
+func GetRelayer(relayer common.Address) *Invocation {
+	return &Invocation{
+		Contract: utils.RelayerManagerContractAddress,
+		Method:   relayer_manager.GET_RELAYER,
+		Args:     relayer[:],
+	}
+}
+
+// GetRelayerApply queries the relayers of the pending application of applyID
+func GetRelayerApply(applyID uint64) *Invocation {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(applyID)
+	return &Invocation{
+		Contract: utils.RelayerManagerContractAddress,
+		Method:   relayer_manager.GET_RELAYER_APPLY,
+		Args:     sink.Bytes(),
+	}
+}
+
+// side chain manager
+
+func registerSideChainInvocation(method string, param *side_chain_manager.RegisterSideChainParam) (*Invocation, error) {
+	sink := common.NewZeroCopySink(nil)
+	if err := param.Serialization(sink); err != nil {
+		return nil, fmt.Errorf("%s, serialize param error: %v", method, err)
+	}
+	return &Invocation{
+		Contract: utils.SideChainManagerContractAddress,
+		Method:   method,
+		Args:     sink.Bytes(),
+	}, nil
+}
+
+func RegisterSideChain(param *side_chain_manager.RegisterSideChainParam) (*Invocation, error) {
+	return registerSideChainInvocation(side_chain_manager.REGISTER_SIDE_CHAIN, param)
+}
+
+func ApproveRegisterSideChain(param *side_chain_manager.ChainidParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.APPROVE_REGISTER_SIDE_CHAIN, param)
+}
+
+func UpdateSideChain(param *side_chain_manager.RegisterSideChainParam) (*Invocation, error) {
+	return registerSideChainInvocation(side_chain_manager.UPDATE_SIDE_CHAIN, param)
+}
+
+func ApproveUpdateSideChain(param *side_chain_manager.ChainidParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.APPROVE_UPDATE_SIDE_CHAIN, param)
+}
+
+func QuitSideChain(param *side_chain_manager.ChainidParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.QUIT_SIDE_CHAIN, param)
+}
+
+func ApproveQuitSideChain(param *side_chain_manager.ChainidParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.APPROVE_QUIT_SIDE_CHAIN, param)
+}
+
+func PruneSideChain(param *side_chain_manager.ChainidParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.PRUNE_SIDE_CHAIN, param)
+}
+
+func RegisterRedeem(param *side_chain_manager.RegisterRedeemParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.REGISTER_REDEEM, param)
+}
+
+func SetBtcTxParam(param *side_chain_manager.BtcTxParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.SET_BTC_TX_PARAM, param)
+}
+
+func SetLockEventTopic(param *side_chain_manager.LockEventTopicParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.SET_LOCK_EVENT_TOPIC, param)
+}
+
+func SetConfirmationTiers(param *side_chain_manager.ConfirmationTiersParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.SET_CONFIRMATION_TIERS, param)
+}
+
+func SetOpReturnLimit(param *side_chain_manager.OpReturnLimitParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.SET_OP_RETURN_LIMIT, param)
+}
+
+func SetForkSchedule(param *side_chain_manager.ForkScheduleParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.SET_FORK_SCHEDULE, param)
+}
+
+func SetMaxProofAge(param *side_chain_manager.MaxProofAgeParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.SET_MAX_PROOF_AGE, param)
+}
+
+func ApproveLateProof(param *side_chain_manager.LateProofParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.APPROVE_LATE_PROOF, param)
+}
+
+func SetMerkleHash(param *side_chain_manager.MerkleHashParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.SET_MERKLE_HASH, param)
+}
+
+func CreateInstance(param *side_chain_manager.InstanceParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.CREATE_INSTANCE, param)
+}
+
+func SetInstanceAdmin(param *side_chain_manager.InstanceParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.SET_INSTANCE_ADMIN, param)
+}
+
+// InInstance runs the invocation of cross_chain_manager or side_chain_manager in a bridge instance
+func InInstance(instance string, inv *Invocation) *Invocation {
+	return newInvocation(inv.Contract, side_chain_manager.INVOKE_INSTANCE, &side_chain_manager.InvokeInstanceParam{
+		Instance: instance,
+		Method:   inv.Method,
+		Args:     inv.Args,
+	})
+}
+
+func SetBondConfig(param *side_chain_manager.BondConfigParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.SET_BOND_CONFIG, param)
+}
+
+func RegisterBondedSideChain(param *side_chain_manager.RegisterBondedParam) (*Invocation, error) {
+	sink := common.NewZeroCopySink(nil)
+	if err := param.Serialization(sink); err != nil {
+		return nil, fmt.Errorf("%s, serialize param error: %v", side_chain_manager.REGISTER_BONDED_SIDE_CHAIN, err)
+	}
+	return &Invocation{
+		Contract: utils.SideChainManagerContractAddress,
+		Method:   side_chain_manager.REGISTER_BONDED_SIDE_CHAIN,
+		Args:     sink.Bytes(),
+	}, nil
+}
+
+func ApproveProbation(param *side_chain_manager.ChainidParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.APPROVE_PROBATION, param)
+}
+
+func SlashBond(param *side_chain_manager.ChainidParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.SLASH_BOND, param)
+}
+
+func GetBondBalance(param *side_chain_manager.BondBalanceParam) *Invocation {
+	return newInvocation(utils.SideChainManagerContractAddress, side_chain_manager.GET_BOND_BALANCE, param)
+}
+
+// treasury
+
+func ProposeDisbursement(param *treasury.DisbursementParam) *Invocation {
+	return newInvocation(utils.TreasuryContractAddress, treasury.PROPOSE_DISBURSEMENT, param)
+}
+
+func ApproveDisbursement(param *treasury.ApproveDisbursementParam) *Invocation {
+	return newInvocation(utils.TreasuryContractAddress, treasury.APPROVE_DISBURSEMENT, param)
+}
+
+func GetTreasuryBalance(param *treasury.BalanceParam) *Invocation {
+	return newInvocation(utils.TreasuryContractAddress, treasury.GET_BALANCE, param)
+}
+
+func SetHeaderReward(param *treasury.HeaderRewardParam) *Invocation {
+	return newInvocation(utils.TreasuryContractAddress, treasury.SET_HEADER_REWARD, param)
+}
+
+// header sync
+
+func SyncGenesisHeader(param *hscommon.SyncGenesisHeaderParam) *Invocation {
+	return newInvocation(utils.HeaderSyncContractAddress, header_sync.SYNC_GENESIS_HEADER, param)
+}
+
+func AttestGenesisHeader(param *hscommon.AttestGenesisHeaderParam) *Invocation {
+	return newInvocation(utils.HeaderSyncContractAddress, header_sync.ATTEST_GENESIS, param)
+}
+
+func SyncBlockHeader(param *hscommon.SyncBlockHeaderParam) *Invocation {
+	return newInvocation(utils.HeaderSyncContractAddress, header_sync.SYNC_BLOCK_HEADER, param)
+}
+
+func SetVerifyParams(param *hscommon.SetVerifyParamsParam) *Invocation {
+	return newInvocation(utils.HeaderSyncContractAddress, header_sync.SET_VERIFY_PARAMS, param)
+}
+
+func SyncCrossChainMsg(param *hscommon.SyncCrossChainMsgParam) *Invocation {
+	return newInvocation(utils.HeaderSyncContractAddress, header_sync.SYNC_CROSS_CHAIN_MSG, param)
+}
+
+// cross chain manager
+
+func ImportOuterTransfer(param *ccmcom.EntranceParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME, param)
+}
+
+func MultiSign(param *ccmcom.MultiSignParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.MULTI_SIGN, param)
+}
+
+func BlackChain(param *cross_chain_manager.BlackChainParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.BLACK_CHAIN, param)
+}
+
+func WhiteChain(param *cross_chain_manager.BlackChainParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.WHITE_CHAIN, param)
+}
+
+func SignReceipt(param *cross_chain_manager.SignReceiptParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.SIGN_RECEIPT, param)
+}
+
+func GetTransferCount(param *cross_chain_manager.TransferCountParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_TRANSFER_COUNT, param)
+}
+
+func GetAssetVolume(param *cross_chain_manager.AssetVolumeParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_ASSET_VOLUME, param)
+}
+
+func GetFailureCount(param *cross_chain_manager.FailureCountParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_FAILURE_COUNT, param)
+}
+
+func GetEpochSummary(param *cross_chain_manager.EpochSummaryParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_EPOCH_SUMMARY, param)
+}
+
+func GetFailedImports(param *cross_chain_manager.FailedImportsParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_FAILED_IMPORTS, param)
+}
+
+func AllowContract(param *cross_chain_manager.AllowContractParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.ALLOW_CONTRACT, param)
+}
+
+func GetContractAllowed(param *cross_chain_manager.ContractChainParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_CONTRACT_ALLOWED, param)
+}
+
+func BlockRecipient(param *cross_chain_manager.BlockRecipientParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.BLOCK_RECIPIENT, param)
+}
+
+func GetBlockedRecipient(param *cross_chain_manager.RecipientParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_BLOCKED_RECIPIENT, param)
+}
+
+func SendAdminMessage(param *cross_chain_manager.SendAdminMessageParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.SEND_ADMIN_MESSAGE, param)
+}
+
+func GetAdminSequence(param *cross_chain_manager.ToChainParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_ADMIN_SEQUENCE, param)
+}
+
+func GetEpochPush(param *cross_chain_manager.ToChainParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_EPOCH_PUSH, param)
+}
+
+func UpdateFee(param *cross_chain_manager.UpdateFeeParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.UPDATE_FEE, param)
+}
+
+func GetFeeRate(param *cross_chain_manager.ToChainParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_FEE_RATE, param)
+}
+
+func GetRelayerFee(param *cross_chain_manager.RelayerFeeParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_RELAYER_FEE, param)
+}
+
+func ClaimFee(param *cross_chain_manager.ClaimFeeParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.CLAIM_FEE, param)
+}
+
+func UpdateFeeProxy(param *cross_chain_manager.UpdateFeeProxyParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.UPDATE_FEE_PROXY, param)
+}
+
+func CheckChain(param *cross_chain_manager.CheckChainParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.CHECK_CHAIN, param)
+}
+
+func BindAsset(param *cross_chain_manager.BindAssetParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.BIND_ASSET, param)
+}
+
+func GetAssetSupply(param *cross_chain_manager.AssetParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_ASSET_SUPPLY, param)
+}
+
+func FreezeAsset(param *cross_chain_manager.FreezeAssetParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.FREEZE_ASSET, param)
+}
+
+func UnfreezeAsset(param *cross_chain_manager.FreezeAssetParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.UNFREEZE_ASSET, param)
+}
+
+func GetAssetFrozen(param *cross_chain_manager.AssetParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_ASSET_FROZEN, param)
+}
+
+func RetryCommit(param *cross_chain_manager.PendingCommitParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.RETRY_COMMIT, param)
+}
+
+func RefundPendingCommit(param *cross_chain_manager.RefundPendingCommitParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.REFUND_PENDING_COMMIT, param)
+}
+
+func GetPendingCommit(param *cross_chain_manager.PendingCommitParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_PENDING_COMMIT, param)
+}
+
+func CorrectBtcUtxos(param *cross_chain_manager.CorrectBtcUtxosParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.CORRECT_BTC_UTXOS, param)
+}
+
+func GetBtcUtxos(param *cross_chain_manager.BtcUtxosParam) *Invocation {
+	return newInvocation(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_BTC_UTXOS, param)
+}
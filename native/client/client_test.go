@@ -0,0 +1,336 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
+	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/header_sync"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+	"github.com/stretchr/testify/assert"
+)
+
+type deserializable interface {
+	Deserialization(source *common.ZeroCopySource) error
+}
+
+func TestInvocations(t *testing.T) {
+	addr := common.Address{1, 2, 3}
+	registerSideChain, err := RegisterSideChain(&side_chain_manager.RegisterSideChainParam{
+		Address:      addr,
+		ChainId:      2,
+		Router:       utils.ETH_ROUTER,
+		Name:         "eth",
+		BlocksToWait: 12,
+		CCMCAddress:  []byte{4, 5},
+		ExtraInfo:    []byte{6},
+	})
+	assert.NoError(t, err)
+
+	cases := []struct {
+		inv      *Invocation
+		contract common.Address
+		method   string
+		param    interface{}
+		decoded  deserializable
+	}{
+		{
+			inv:      RegisterCandidate(&node_manager.RegisterPeerParam{PeerPubkey: "02abcd", Address: addr}),
+			contract: utils.NodeManagerContractAddress,
+			method:   node_manager.REGISTER_CANDIDATE,
+			param:    &node_manager.RegisterPeerParam{PeerPubkey: "02abcd", Address: addr},
+			decoded:  new(node_manager.RegisterPeerParam),
+		},
+		{
+			inv:      BlackNode(&node_manager.PeerListParam{PeerPubkeyList: []string{"02abcd", "03ef"}, Address: addr}),
+			contract: utils.NodeManagerContractAddress,
+			method:   node_manager.BLACK_NODE,
+			param:    &node_manager.PeerListParam{PeerPubkeyList: []string{"02abcd", "03ef"}, Address: addr},
+			decoded:  new(node_manager.PeerListParam),
+		},
+		{
+			inv:      Slash(&node_manager.SlashParam{PeerPubkey: "02abcd", Kind: node_manager.EVIDENCE_DOUBLE_SIGN, Height: 10, Evidence: []byte{1, 2}, Address: addr}),
+			contract: utils.NodeManagerContractAddress,
+			method:   node_manager.SLASH,
+			param:    &node_manager.SlashParam{PeerPubkey: "02abcd", Kind: node_manager.EVIDENCE_DOUBLE_SIGN, Height: 10, Evidence: []byte{1, 2}, Address: addr},
+			decoded:  new(node_manager.SlashParam),
+		},
+		{
+			inv:      ApproveOperatorCall(SetApplyLimit(&node_manager.ApplyLimitParam{Limit: 5, Address: addr}), addr),
+			contract: utils.NodeManagerContractAddress,
+			method:   node_manager.APPROVE_OPERATOR_CALL,
+			param:    &node_manager.OperatorCallParam{Contract: utils.NodeManagerContractAddress, Method: node_manager.SET_APPLY_LIMIT, Args: SetApplyLimit(&node_manager.ApplyLimitParam{Limit: 5, Address: addr}).Args, Address: addr},
+			decoded:  new(node_manager.OperatorCallParam),
+		},
+		{
+			inv:      ValidateConfig(&node_manager.UpdateConfigParam{Configuration: &node_manager.Configuration{BlockMsgDelay: 10000, HashMsgDelay: 10000, PeerHandshakeTimeout: 10, MaxBlockChangeView: 60000}}),
+			contract: utils.NodeManagerContractAddress,
+			method:   node_manager.VALIDATE_CONFIG,
+			param:    &node_manager.UpdateConfigParam{Configuration: &node_manager.Configuration{BlockMsgDelay: 10000, HashMsgDelay: 10000, PeerHandshakeTimeout: 10, MaxBlockChangeView: 60000}},
+			decoded:  new(node_manager.UpdateConfigParam),
+		},
+		{
+			inv:      SetMutationLimit(&node_manager.MutationLimitParam{Address: addr, Limit: 8}),
+			contract: utils.NodeManagerContractAddress,
+			method:   node_manager.SET_MUTATION_LIMIT,
+			param:    &node_manager.MutationLimitParam{Address: addr, Limit: 8},
+			decoded:  new(node_manager.MutationLimitParam),
+		},
+		{
+			inv:      ApproveRegisterRelayer(&relayer_manager.ApproveRelayerParam{ID: 3, Address: addr}),
+			contract: utils.RelayerManagerContractAddress,
+			method:   relayer_manager.APPROVE_REGISTER_RELAYER,
+			param:    &relayer_manager.ApproveRelayerParam{ID: 3, Address: addr},
+			decoded:  new(relayer_manager.ApproveRelayerParam),
+		},
+		{
+			inv:      SetRelayerConfig(&relayer_manager.RelayerConfigParam{Config: relayer_manager.RelayerConfig{Required: true, Stake: big.NewInt(100)}, Address: addr}),
+			contract: utils.RelayerManagerContractAddress,
+			method:   relayer_manager.SET_RELAYER_CONFIG,
+			param:    &relayer_manager.RelayerConfigParam{Config: relayer_manager.RelayerConfig{Required: true, Stake: big.NewInt(100)}, Address: addr},
+			decoded:  new(relayer_manager.RelayerConfigParam),
+		},
+		{
+			inv:      registerSideChain,
+			contract: utils.SideChainManagerContractAddress,
+			method:   side_chain_manager.REGISTER_SIDE_CHAIN,
+			param: &side_chain_manager.RegisterSideChainParam{
+				Address:      addr,
+				ChainId:      2,
+				Router:       utils.ETH_ROUTER,
+				Name:         "eth",
+				BlocksToWait: 12,
+				CCMCAddress:  []byte{4, 5},
+				ExtraInfo:    []byte{6},
+			},
+			decoded: new(side_chain_manager.RegisterSideChainParam),
+		},
+		{
+			inv:      ApproveQuitSideChain(&side_chain_manager.ChainidParam{Chainid: 2, Address: addr}),
+			contract: utils.SideChainManagerContractAddress,
+			method:   side_chain_manager.APPROVE_QUIT_SIDE_CHAIN,
+			param:    &side_chain_manager.ChainidParam{Chainid: 2, Address: addr},
+			decoded:  new(side_chain_manager.ChainidParam),
+		},
+		{
+			inv: SetForkSchedule(&side_chain_manager.ForkScheduleParam{Address: addr, ChainId: 2,
+				Schedule: &side_chain_manager.ForkSchedule{Forks: []*side_chain_manager.Fork{{Name: "london", Height: 100}}}}),
+			contract: utils.SideChainManagerContractAddress,
+			method:   side_chain_manager.SET_FORK_SCHEDULE,
+			param: &side_chain_manager.ForkScheduleParam{Address: addr, ChainId: 2,
+				Schedule: &side_chain_manager.ForkSchedule{Forks: []*side_chain_manager.Fork{{Name: "london", Height: 100}}}},
+			decoded: new(side_chain_manager.ForkScheduleParam),
+		},
+		{
+			inv:      ApproveLateProof(&side_chain_manager.LateProofParam{Address: addr, ChainId: 2, Height: 100}),
+			contract: utils.SideChainManagerContractAddress,
+			method:   side_chain_manager.APPROVE_LATE_PROOF,
+			param:    &side_chain_manager.LateProofParam{Address: addr, ChainId: 2, Height: 100},
+			decoded:  new(side_chain_manager.LateProofParam),
+		},
+		{
+			inv:      SetMerkleHash(&side_chain_manager.MerkleHashParam{Address: addr, ChainId: 3, Hash: "keccak256"}),
+			contract: utils.SideChainManagerContractAddress,
+			method:   side_chain_manager.SET_MERKLE_HASH,
+			param:    &side_chain_manager.MerkleHashParam{Address: addr, ChainId: 3, Hash: "keccak256"},
+			decoded:  new(side_chain_manager.MerkleHashParam),
+		},
+		{
+			inv:      SlashBond(&side_chain_manager.ChainidParam{Chainid: 2, Address: addr}),
+			contract: utils.SideChainManagerContractAddress,
+			method:   side_chain_manager.SLASH_BOND,
+			param:    &side_chain_manager.ChainidParam{Chainid: 2, Address: addr},
+			decoded:  new(side_chain_manager.ChainidParam),
+		},
+		{
+			inv:      GetBondBalance(&side_chain_manager.BondBalanceParam{Address: addr}),
+			contract: utils.SideChainManagerContractAddress,
+			method:   side_chain_manager.GET_BOND_BALANCE,
+			param:    &side_chain_manager.BondBalanceParam{Address: addr},
+			decoded:  new(side_chain_manager.BondBalanceParam),
+		},
+		{
+			inv:      SyncBlockHeader(&hscommon.SyncBlockHeaderParam{ChainID: 2, Address: addr, Headers: [][]byte{{1}, {2}}}),
+			contract: utils.HeaderSyncContractAddress,
+			method:   header_sync.SYNC_BLOCK_HEADER,
+			param:    &hscommon.SyncBlockHeaderParam{ChainID: 2, Address: addr, Headers: [][]byte{{1}, {2}}},
+			decoded:  new(hscommon.SyncBlockHeaderParam),
+		},
+		{
+			inv:      AttestGenesisHeader(&hscommon.AttestGenesisHeaderParam{ChainID: 2, GenesisHeader: []byte{1}, Address: addr}),
+			contract: utils.HeaderSyncContractAddress,
+			method:   header_sync.ATTEST_GENESIS,
+			param:    &hscommon.AttestGenesisHeaderParam{ChainID: 2, GenesisHeader: []byte{1}, Address: addr},
+			decoded:  new(hscommon.AttestGenesisHeaderParam),
+		},
+		{
+			inv:      SetVerifyParams(&hscommon.SetVerifyParamsParam{ChainID: 2, EffectiveHeight: 100, Params: []byte{1}, Address: addr}),
+			contract: utils.HeaderSyncContractAddress,
+			method:   header_sync.SET_VERIFY_PARAMS,
+			param:    &hscommon.SetVerifyParamsParam{ChainID: 2, EffectiveHeight: 100, Params: []byte{1}, Address: addr},
+			decoded:  new(hscommon.SetVerifyParamsParam),
+		},
+		{
+			inv: ImportOuterTransfer(&ccmcom.EntranceParam{SourceChainID: 2, Height: 100, Proof: []byte{1},
+				RelayerAddress: addr[:], Extra: []byte{2}, HeaderOrCrossChainMsg: []byte{3}}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME,
+			param: &ccmcom.EntranceParam{SourceChainID: 2, Height: 100, Proof: []byte{1},
+				RelayerAddress: addr[:], Extra: []byte{2}, HeaderOrCrossChainMsg: []byte{3}},
+			decoded: new(ccmcom.EntranceParam),
+		},
+		{
+			inv:      BlackChain(&cross_chain_manager.BlackChainParam{ChainID: 2}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.BLACK_CHAIN,
+			param:    &cross_chain_manager.BlackChainParam{ChainID: 2},
+			decoded:  new(cross_chain_manager.BlackChainParam),
+		},
+		{
+			inv:      SendAdminMessage(&cross_chain_manager.SendAdminMessageParam{ToChainID: 2, Type: ccmcom.ADMIN_PAUSE, Address: addr}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.SEND_ADMIN_MESSAGE,
+			param:    &cross_chain_manager.SendAdminMessageParam{ToChainID: 2, Type: ccmcom.ADMIN_PAUSE, Args: []byte{}, Address: addr},
+			decoded:  new(cross_chain_manager.SendAdminMessageParam),
+		},
+		{
+			inv:      BindAsset(&cross_chain_manager.BindAssetParam{Asset: "USDT", ChainID: 2, AssetHash: []byte{1, 2}, Address: addr}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.BIND_ASSET,
+			param:    &cross_chain_manager.BindAssetParam{Asset: "USDT", ChainID: 2, AssetHash: []byte{1, 2}, Address: addr},
+			decoded:  new(cross_chain_manager.BindAssetParam),
+		},
+		{
+			inv:      UpdateFee(&cross_chain_manager.UpdateFeeParam{ChainID: 2, Rate: 30, Address: addr}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.UPDATE_FEE,
+			param:    &cross_chain_manager.UpdateFeeParam{ChainID: 2, Rate: 30, Address: addr},
+			decoded:  new(cross_chain_manager.UpdateFeeParam),
+		},
+		{
+			inv:      ClaimFee(&cross_chain_manager.ClaimFeeParam{Relayer: addr, ToChainID: 2, Asset: []byte{1}, ToAddress: []byte{2}}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.CLAIM_FEE,
+			param:    &cross_chain_manager.ClaimFeeParam{Relayer: addr, ToChainID: 2, Asset: []byte{1}, ToAddress: []byte{2}},
+			decoded:  new(cross_chain_manager.ClaimFeeParam),
+		},
+		{
+			inv: UpdateFeeProxy(&cross_chain_manager.UpdateFeeProxyParam{ToChainID: 2, Route: &cross_chain_manager.FeeRoute{
+				FromChainID: 3, FromContract: []byte{1}, ToContract: []byte{2}}, Allowed: true, Payout: true, Address: addr}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.UPDATE_FEE_PROXY,
+			param: &cross_chain_manager.UpdateFeeProxyParam{ToChainID: 2, Route: &cross_chain_manager.FeeRoute{
+				FromChainID: 3, FromContract: []byte{1}, ToContract: []byte{2}}, Allowed: true, Payout: true, Address: addr},
+			decoded: new(cross_chain_manager.UpdateFeeProxyParam),
+		},
+		{
+			inv:      CheckChain(&cross_chain_manager.CheckChainParam{ChainID: 2, MaxLag: 100}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.CHECK_CHAIN,
+			param:    &cross_chain_manager.CheckChainParam{ChainID: 2, MaxLag: 100},
+			decoded:  new(cross_chain_manager.CheckChainParam),
+		},
+		{
+			inv:      FreezeAsset(&cross_chain_manager.FreezeAssetParam{Asset: "USDT", Address: addr}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.FREEZE_ASSET,
+			param:    &cross_chain_manager.FreezeAssetParam{Asset: "USDT", Address: addr},
+			decoded:  new(cross_chain_manager.FreezeAssetParam),
+		},
+		{
+			inv:      BlockRecipient(&cross_chain_manager.BlockRecipientParam{ChainID: 2, Recipient: []byte{3, 4}, Blocked: true, Address: addr}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.BLOCK_RECIPIENT,
+			param:    &cross_chain_manager.BlockRecipientParam{ChainID: 2, Recipient: []byte{3, 4}, Blocked: true, Address: addr},
+			decoded:  new(cross_chain_manager.BlockRecipientParam),
+		},
+		{
+			inv:      AllowContract(&cross_chain_manager.AllowContractParam{Contract: addr, ChainID: 2, Allowed: true, Address: addr}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.ALLOW_CONTRACT,
+			param:    &cross_chain_manager.AllowContractParam{Contract: addr, ChainID: 2, Allowed: true, Address: addr},
+			decoded:  new(cross_chain_manager.AllowContractParam),
+		},
+		{
+			inv: CorrectBtcUtxos(&cross_chain_manager.CorrectBtcUtxosParam{ChainID: 1, RedeemKey: "87a9652e",
+				Utxos:   &btc.Utxos{Utxos: []*btc.Utxo{{Op: &btc.OutPoint{Hash: make([]byte, 32)}, AtHeight: 10, Value: 1000, ScriptPubkey: []byte{0xa9}}}},
+				Address: addr}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.CORRECT_BTC_UTXOS,
+			param: &cross_chain_manager.CorrectBtcUtxosParam{ChainID: 1, RedeemKey: "87a9652e",
+				Utxos:   &btc.Utxos{Utxos: []*btc.Utxo{{Op: &btc.OutPoint{Hash: make([]byte, 32)}, AtHeight: 10, Value: 1000, ScriptPubkey: []byte{0xa9}}}},
+				Address: addr},
+			decoded: new(cross_chain_manager.CorrectBtcUtxosParam),
+		},
+		{
+			inv:      RefundPendingCommit(&cross_chain_manager.RefundPendingCommitParam{FromChainID: 2, DoneID: []byte{1, 2}, Address: addr}),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   cross_chain_manager.REFUND_PENDING_COMMIT,
+			param:    &cross_chain_manager.RefundPendingCommitParam{FromChainID: 2, DoneID: []byte{1, 2}, Address: addr},
+			decoded:  new(cross_chain_manager.RefundPendingCommitParam),
+		},
+		{
+			inv:      CreateInstance(&side_chain_manager.InstanceParam{Address: addr, Instance: "canary", Admin: addr}),
+			contract: utils.SideChainManagerContractAddress,
+			method:   side_chain_manager.CREATE_INSTANCE,
+			param:    &side_chain_manager.InstanceParam{Address: addr, Instance: "canary", Admin: addr},
+			decoded:  new(side_chain_manager.InstanceParam),
+		},
+		{
+			inv:      InInstance("canary", BlackChain(&cross_chain_manager.BlackChainParam{ChainID: 2})),
+			contract: utils.CrossChainManagerContractAddress,
+			method:   side_chain_manager.INVOKE_INSTANCE,
+			param: &side_chain_manager.InvokeInstanceParam{
+				Instance: "canary",
+				Method:   cross_chain_manager.BLACK_CHAIN,
+				Args:     BlackChain(&cross_chain_manager.BlackChainParam{ChainID: 2}).Args,
+			},
+			decoded: new(side_chain_manager.InvokeInstanceParam),
+		},
+	}
+	for _, c := range cases {
+		invokeParam := new(states.ContractInvokeParam)
+		err := invokeParam.Deserialization(common.NewZeroCopySource(c.inv.Code()))
+		assert.NoError(t, err, c.method)
+		assert.Equal(t, c.contract, invokeParam.Address, c.method)
+		assert.Equal(t, c.method, invokeParam.Method, c.method)
+
+		err = c.decoded.Deserialization(common.NewZeroCopySource(invokeParam.Args))
+		assert.NoError(t, err, c.method)
+		assert.Equal(t, c.param, c.decoded, c.method)
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	inv := CommitDpos()
+	tx, err := inv.Transaction(2, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), tx.ChainID)
+	assert.Equal(t, uint32(7), tx.Nonce)
+	assert.Equal(t, inv.Code(), tx.Payload.(*payload.InvokeCode).Code)
+}
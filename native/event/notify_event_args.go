@@ -19,6 +19,10 @@
 package event
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
 	"github.com/polynetwork/poly/common"
 )
 
@@ -31,6 +35,28 @@ const (
 type NotifyEventInfo struct {
 	ContractAddress common.Address
 	States          interface{}
+	// FromChainID and ToChainID are the chains of a cross chain event, nil for the other events. They
+	// repeat the chain ids in States so that the events can be indexed and filtered by chain, Hash
+	// doesn't commit to them.
+	FromChainID *uint64 `json:",omitempty"`
+	ToChainID   *uint64 `json:",omitempty"`
+}
+
+// ChainID returns a reference to id for the chain id fields of NotifyEventInfo
+func ChainID(id uint64) *uint64 {
+	return &id
+}
+
+// ChainIDs returns the distinct chain ids the event is bound to, from first
+func (this *NotifyEventInfo) ChainIDs() []uint64 {
+	var ids []uint64
+	if this.FromChainID != nil {
+		ids = append(ids, *this.FromChainID)
+	}
+	if this.ToChainID != nil && (this.FromChainID == nil || *this.ToChainID != *this.FromChainID) {
+		ids = append(ids, *this.ToChainID)
+	}
+	return ids
 }
 
 type ExecuteNotify struct {
@@ -38,4 +64,23 @@ type ExecuteNotify struct {
 	State       byte
 	GasConsumed uint64
 	Notify      []*NotifyEventInfo
+	NotifyHash  common.Uint256 // set from the receipts root height, see Hash
+}
+
+// Hash returns the hash committing to the transaction, its state and the ordered notifications, the states
+// of each notification are hashed in the json encoding saved by the event store
+func (this *ExecuteNotify) Hash() (common.Uint256, error) {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteHash(this.TxHash)
+	sink.WriteByte(this.State)
+	sink.WriteVarUint(uint64(len(this.Notify)))
+	for i, v := range this.Notify {
+		states, err := json.Marshal(v.States)
+		if err != nil {
+			return common.UINT256_EMPTY, fmt.Errorf("marshal states of No.%d notify error: %v", i, err)
+		}
+		sink.WriteAddress(v.ContractAddress)
+		sink.WriteVarBytes(states)
+	}
+	return sha256.Sum256(sink.Bytes()), nil
 }
@@ -31,10 +31,14 @@ import (
 type (
 	Handler         func(native *NativeService) ([]byte, error)
 	RegisterService func(native *NativeService)
+	// FailureHook is called with the method and the error of a failed invocation of the contract,
+	// it runs against a clean cache and is the only writer of the failed tx.
+	FailureHook func(native *NativeService, method string, cause error)
 )
 
 var (
-	Contracts = make(map[common.Address]RegisterService)
+	Contracts    = make(map[common.Address]RegisterService)
+	FailureHooks = make(map[common.Address]FailureHook)
 )
 
 const (
@@ -56,6 +60,8 @@ type NativeService struct {
 	crossHashes   []common.Uint256
 	contexts      []common.Address
 	preExec       bool
+	genesis       bool
+	instanceAdmin *common.Address
 }
 
 func NewNativeService(cacheDB *storage.CacheDB, tx *types.Transaction,
@@ -108,7 +114,7 @@ func (this *NativeService) Invoke() (interface{}, error) {
 	}
 	result, err := service(this)
 	if err != nil {
-		return result, fmt.Errorf("[Invoke] Native serivce function execute error:%s", err)
+		return result, fmt.Errorf("[Invoke] Native serivce function execute error:%w", err)
 	}
 	this.PopContext()
 	this.notifications = append(notifications, this.notifications...)
@@ -117,6 +123,54 @@ func (this *NativeService) Invoke() (interface{}, error) {
 	return result, nil
 }
 
+// InvokeFailureHook runs the failure hook of the contract invoked by the input of the service,
+// it returns false if the contract has no hook.
+func (this *NativeService) InvokeFailureHook(cause error) bool {
+	invokeParam := new(states.ContractInvokeParam)
+	if err := invokeParam.Deserialization(common.NewZeroCopySource(this.input)); err != nil {
+		return false
+	}
+	hook, ok := FailureHooks[invokeParam.Address]
+	if !ok {
+		return false
+	}
+	this.input = invokeParam.Args
+	hook(this, invokeParam.Method, cause)
+	return true
+}
+
+// Dispatch runs method of the contract being invoked with args as input, so that a method of the contract
+// can forward the invocation to another one, e.g. after switching the storage namespace
+func (this *NativeService) Dispatch(method string, args []byte) ([]byte, error) {
+	service, ok := this.serviceMap[method]
+	if !ok {
+		return nil, fmt.Errorf("[Dispatch] Native contract %x doesn't support this function %s.", this.CurrentContext(), method)
+	}
+	input := this.input
+	this.input = args
+	defer func() { this.input = input }()
+	return service(this)
+}
+
+// EnterInstance moves the storage of contracts into the namespace of a bridge instance for the rest of the
+// transaction, admin of the instance approves the governance methods of it in place of the consensus nodes
+func (this *NativeService) EnterInstance(namespace []byte, admin common.Address, contracts ...common.Address) {
+	addresses := make([][]byte, len(contracts))
+	for i := range contracts {
+		addresses[i] = contracts[i][:]
+	}
+	this.cacheDB.SetNamespace(namespace, addresses...)
+	this.instanceAdmin = &admin
+}
+
+// InstanceAdmin returns the admin of the bridge instance entered, false for the default instance
+func (this *NativeService) InstanceAdmin() (common.Address, bool) {
+	if this.instanceAdmin == nil {
+		return common.ADDRESS_EMPTY, false
+	}
+	return *this.instanceAdmin, true
+}
+
 func (this *NativeService) NativeCall(address common.Address, method string, args []byte) (interface{}, error) {
 	c := states.ContractInvokeParam{
 		Address: address,
@@ -161,13 +215,27 @@ func (this *NativeService) PutMerkleVal(data []byte) {
 	this.crossHashes = append(this.crossHashes, merkle.HashLeaf(data))
 }
 
-func (this *NativeService) checkAccountAddress(address common.Address) bool {
+// GetWitnesses returns the distinct addresses signing the transaction in the order of the signatures,
+// so that a method can take the approvals of several signers of one transaction
+func (this *NativeService) GetWitnesses() []common.Address {
 	addresses, err := this.tx.GetSignatureAddresses()
 	if err != nil {
 		log.Errorf("get signature address error:%v", err)
-		return false
+		return nil
 	}
+	witnesses := make([]common.Address, 0, len(addresses))
+	seen := make(map[common.Address]bool, len(addresses))
 	for _, v := range addresses {
+		if !seen[v] {
+			seen[v] = true
+			witnesses = append(witnesses, v)
+		}
+	}
+	return witnesses
+}
+
+func (this *NativeService) checkAccountAddress(address common.Address) bool {
+	for _, v := range this.GetWitnesses() {
 		if v == address {
 			return true
 		}
@@ -182,9 +250,34 @@ func (this *NativeService) checkContractAddress(address common.Address) bool {
 	return false
 }
 
-// CheckWitness check whether authorization correct
+// CheckContractWitness checks whether address is the registered native contract calling the current one,
+// so that the calling contract stands as the witness of a composed flow without account signatures. It
+// authorizes the calls of that contract only, and never stands for the approval of the consensus peers.
+func (this *NativeService) CheckContractWitness(address common.Address) bool {
+	if _, ok := Contracts[address]; !ok {
+		return false
+	}
+	return this.checkContractAddress(address)
+}
+
+// SetGenesis marks the service as running a transaction of the genesis block, such transactions are
+// built by every node from the same genesis config and carry no signature
+func (this *NativeService) SetGenesis() {
+	this.genesis = true
+}
+
+func (this *NativeService) IsGenesis() bool {
+	return this.genesis
+}
+
+// IsPreExec returns true if the service runs a pre-executed transaction, which is not part of a block
+func (this *NativeService) IsPreExec() bool {
+	return this.preExec
+}
+
+// CheckWitness check whether authorization correct, the genesis block stands as the witness of any address
 func (this *NativeService) CheckWitness(address common.Address) bool {
-	if this.checkAccountAddress(address) || this.checkContractAddress(address) {
+	if this.genesis || this.checkAccountAddress(address) || this.checkContractAddress(address) {
 		return true
 	}
 	return false
@@ -210,6 +303,10 @@ func (this *NativeService) GetHeight() uint32 {
 	return this.height
 }
 
+func (this *NativeService) GetBlockTime() uint32 {
+	return this.time
+}
+
 func (this *NativeService) GetChainID() uint64 {
 	return this.chainID
 }
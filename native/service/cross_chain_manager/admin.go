@@ -0,0 +1,159 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// SendAdminMessage emits an admin message to the CCM contract of a destination chain once the consensus
+// peers approve it. The message goes through the normal cross chain channel, proved by the cross states
+// like any transfer, with the contract of poly as the source and the next admin sequence of the chain.
+func SendAdminMessage(native *native.NativeService) ([]byte, error) {
+	params := new(SendAdminMessageParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SendAdminMessage, contract params deserialize error: %v", err)
+	}
+	if err := scom.CheckAdminMessageType(params.Type); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SendAdminMessage, %v", err)
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SendAdminMessage, checkWitness error: %v", err)
+	}
+
+	// a blacked chain is still reachable, governance may have to pause it
+	sideChain, err := side_chain_manager.GetSideChain(native, params.ToChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SendAdminMessage, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SendAdminMessage, side chain %d is not registered", params.ToChainID)
+	}
+	if !hasCCMContract(sideChain) {
+		return utils.BYTE_FALSE, fmt.Errorf("SendAdminMessage, side chain %d has no CCM contract", params.ToChainID)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(params.ToChainID)
+	sink.WriteUint8(params.Type)
+	sink.WriteVarBytes(params.Args)
+	ok, err := node_manager.CheckConsensusSigns(native, SEND_ADMIN_MESSAGE, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SendAdminMessage, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	seq, err := emitAdminMessage(native, sideChain, params.Type, params.Args)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SendAdminMessage, %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"sendAdminMessage", params.ToChainID, params.Type, seq},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// hasCCMContract reports whether the chain runs a CCM contract able to handle admin messages
+func hasCCMContract(sideChain *side_chain_manager.SideChain) bool {
+	if sideChain.Router == utils.BTC_ROUTER || sideChain.Router == utils.BCH_ROUTER || sideChain.Router == utils.ZCASH_ROUTER {
+		return false
+	}
+	return len(sideChain.CCMCAddress) != 0
+}
+
+// emitAdminMessage makes the cross chain tx of the next admin message to the CCM contract of sideChain,
+// returning the sequence of the message
+func emitAdminMessage(native *native.NativeService, sideChain *side_chain_manager.SideChain, typ uint8, args []byte) (uint64, error) {
+	seq, err := GetAdminSequence(native, sideChain.ChainId)
+	if err != nil {
+		return 0, err
+	}
+	message := &scom.AdminMessage{
+		Type:     typ,
+		Sequence: seq,
+		Args:     args,
+	}
+	sink := common.NewZeroCopySink(nil)
+	message.Serialization(sink)
+	chainIDBytes := utils.GetUint64Bytes(sideChain.ChainId)
+	crossChainID := sha256.Sum256(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ADMIN_SEQUENCE),
+		chainIDBytes, utils.GetUint64Bytes(seq)))
+	txHash := native.GetTx().Hash()
+	txParam := &scom.MakeTxParam{
+		TxHash:              txHash.ToArray(),
+		CrossChainID:        crossChainID[:],
+		FromContractAddress: utils.CrossChainManagerContractAddress[:],
+		ToChainID:           sideChain.ChainId,
+		ToContractAddress:   sideChain.CCMCAddress,
+		Method:              scom.ADMIN_MESSAGE_METHOD,
+		Args:                sink.Bytes(),
+	}
+	if err := MakeTransaction(native, txParam, native.GetChainID()); err != nil {
+		return 0, err
+	}
+	native.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ADMIN_SEQUENCE), chainIDBytes),
+		cstates.GenRawStorageItem(utils.GetUint64Bytes(seq+1)))
+	return seq, nil
+}
+
+// GetAdminSequence returns the number of the admin messages sent to toChainID, which is
+// the sequence of the next one.
+func GetAdminSequence(native *native.NativeService, toChainID uint64) (uint64, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ADMIN_SEQUENCE), utils.GetUint64Bytes(toChainID)))
+	if err != nil {
+		return 0, fmt.Errorf("GetAdminSequence, get sequence store error: %v", err)
+	}
+	if store == nil {
+		return 0, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("GetAdminSequence, deserialize from raw storage item err: %v", err)
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+// GetAdminSequenceQuery returns the sequence of the next admin message to the chain, to be called by preExec
+func GetAdminSequenceQuery(native *native.NativeService) ([]byte, error) {
+	params := new(ToChainParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetAdminSequenceQuery, contract params deserialize error: %v", err)
+	}
+	seq, err := GetAdminSequence(native, params.ToChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.GetUint64Bytes(seq), nil
+}
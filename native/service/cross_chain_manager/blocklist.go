@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// RecipientBlockedError rejects a cross chain tx to a blocked recipient
+type RecipientBlockedError struct {
+	FromChainID uint64
+	DoneID      []byte
+	ToChainID   uint64
+	Recipient   []byte
+}
+
+func (this *RecipientBlockedError) Error() string {
+	return fmt.Sprintf("ImportExTransfer, recipient %x on chain %d is blocked", this.Recipient, this.ToChainID)
+}
+
+// BlockRecipient adds a recipient on a chain to the blocklist or removes it once the consensus peers approve it,
+// the cross chain txs made by lock proxy to a blocked recipient are rejected.
+func BlockRecipient(native *native.NativeService) ([]byte, error) {
+	params := new(BlockRecipientParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("BlockRecipient, contract params deserialize error: %v", err)
+	}
+	if len(params.Recipient) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("BlockRecipient, recipient can't be empty")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("BlockRecipient, checkWitness error: %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(params.ChainID)
+	sink.WriteVarBytes(params.Recipient)
+	sink.WriteBool(params.Blocked)
+	ok, err := node_manager.CheckConsensusSigns(native, BLOCK_RECIPIENT, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("BlockRecipient, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if params.Blocked {
+		native.GetCacheDB().Put(blockedRecipientKey(params.ChainID, params.Recipient), cstates.GenRawStorageItem([]byte{1}))
+	} else {
+		native.GetCacheDB().Delete(blockedRecipientKey(params.ChainID, params.Recipient))
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"blockRecipient", params.ChainID, params.Recipient, params.Blocked},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+func blockedRecipientKey(chainID uint64, recipient []byte) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BLOCKED_RECIPIENT), utils.GetUint64Bytes(chainID), recipient)
+}
+
+func blockedAttemptsKey(chainID uint64, recipient []byte) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BLOCKED_ATTEMPTS), utils.GetUint64Bytes(chainID), recipient)
+}
+
+// IsRecipientBlocked tells whether recipient on chainID is in the blocklist
+func IsRecipientBlocked(native *native.NativeService, chainID uint64, recipient []byte) (bool, error) {
+	raw, err := getCounter(native, blockedRecipientKey(chainID, recipient))
+	if err != nil {
+		return false, fmt.Errorf("IsRecipientBlocked, %v", err)
+	}
+	return raw != nil, nil
+}
+
+// checkRecipient rejects the cross chain tx doneID from fromChainID if it's made by lock proxy to a blocked
+// recipient, the recipient of other txs can't be resolved and they are not checked.
+func checkRecipient(native *native.NativeService, fromChainID uint64, doneID []byte, txParam *scom.MakeTxParam) error {
+	_, recipient, _ := scom.DecodeTransfer(txParam.Args)
+	if recipient == nil {
+		return nil
+	}
+	blocked, err := IsRecipientBlocked(native, txParam.ToChainID, recipient)
+	if err != nil {
+		return fmt.Errorf("ImportExTransfer, %v", err)
+	}
+	if !blocked {
+		return nil
+	}
+	return &RecipientBlockedError{
+		FromChainID: fromChainID,
+		DoneID:      doneID,
+		ToChainID:   txParam.ToChainID,
+		Recipient:   recipient,
+	}
+}
+
+// recordBlockedAttempt is run by the failure hook, the cross chain tx stays marked done so that it's not
+// imported by relaying it again once the recipient is removed from the blocklist.
+func recordBlockedAttempt(native *native.NativeService, blocked *RecipientBlockedError) {
+	attempts, err := GetBlockedAttempts(native, blocked.ToChainID, blocked.Recipient)
+	if err != nil {
+		return
+	}
+	attempts.Count++
+	attempts.FromChainID = blocked.FromChainID
+	attempts.DoneID = blocked.DoneID
+	attempts.Height = native.GetHeight()
+	sink := common.NewZeroCopySink(nil)
+	attempts.Serialization(sink)
+	native.GetCacheDB().Put(blockedAttemptsKey(blocked.ToChainID, blocked.Recipient), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// GetBlockedAttempts returns the attempts rejected for recipient on chainID, the count is 0 if there is none
+func GetBlockedAttempts(native *native.NativeService, chainID uint64, recipient []byte) (*BlockedAttempts, error) {
+	raw, err := getCounter(native, blockedAttemptsKey(chainID, recipient))
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockedAttempts, %v", err)
+	}
+	attempts := new(BlockedAttempts)
+	if raw == nil {
+		return attempts, nil
+	}
+	if err := attempts.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetBlockedAttempts, deserialize blocked attempts error: %v", err)
+	}
+	return attempts, nil
+}
+
+// GetBlockedRecipientQuery returns whether the recipient is blocked followed by the attempts rejected for it,
+// to be called by preExec
+func GetBlockedRecipientQuery(native *native.NativeService) ([]byte, error) {
+	params := new(RecipientParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetBlockedRecipientQuery, contract params deserialize error: %v", err)
+	}
+	blocked, err := IsRecipientBlocked(native, params.ChainID, params.Recipient)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	attempts, err := GetBlockedAttempts(native, params.ChainID, params.Recipient)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteBool(blocked)
+	attempts.Serialization(sink)
+	return sink.Bytes(), nil
+}
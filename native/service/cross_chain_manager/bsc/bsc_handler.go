@@ -25,7 +25,6 @@ import (
 
 	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/light"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
@@ -35,8 +34,12 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/bsc"
+	"github.com/polynetwork/poly/native/service/precompile"
 )
 
+// the crypto primitives the proof verification relies on
+var keccak256 = precompile.MustHash("cross_chain_manager/bsc", precompile.KECCAK256)
+
 // Handler ...
 type Handler struct {
 }
@@ -64,7 +67,7 @@ func (h *Handler) MakeDepositProposal(service *native.NativeService) (*scom.Make
 	}
 
 	if err := scom.CheckDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
-		return nil, fmt.Errorf("eth MakeDepositProposal, check done transaction error:%s", err)
+		return nil, fmt.Errorf("eth MakeDepositProposal, check done transaction error:%w", err)
 	}
 	if err := scom.PutDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
 		return nil, fmt.Errorf("eth MakeDepositProposal, PutDoneTx error:%s", err)
@@ -78,11 +81,18 @@ func verifyFromTx(native *native.NativeService, proof, extra []byte, fromChainID
 		return
 	}
 
+	blocksToWait, err := side_chain_manager.GetRequiredConfirmations(native, sideChain, scom.GetTransferAmount(extra))
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromTx, get required confirmations error:%s", err)
+	}
 	cheight32 := uint32(cheight)
 
-	if cheight32 < height || cheight32-height < uint32(sideChain.BlocksToWait-1) {
+	if cheight32 < height || cheight32-height < uint32(blocksToWait-1) {
 		return nil, fmt.Errorf("verifyFromTx, transaction is not confirmed, current height: %d, input height: %d", cheight, height)
 	}
+	if err := side_chain_manager.CheckProofAge(native, fromChainID, uint64(height), cheight); err != nil {
+		return nil, fmt.Errorf("verifyFromTx, %v", err)
+	}
 
 	headerWithSum, err := bsc.GetCanonicalHeader(native, fromChainID, uint64(height))
 	if err != nil {
@@ -160,7 +170,7 @@ func verifyMerkleProof(bscProof *Proof, blockData *types.Header, contractAddr []
 	if !bytes.Equal(addr, contractAddr) {
 		return nil, fmt.Errorf("verifyMerkleProof, contract address is error, proof address: %s, side chain address: %s", bscProof.Address, hex.EncodeToString(contractAddr))
 	}
-	acctKey := crypto.Keccak256(addr)
+	acctKey := keccak256(addr)
 
 	//2. verify account proof
 	acctVal, err := trie.VerifyProof(blockData.Root, acctKey, ns)
@@ -206,7 +216,7 @@ func verifyMerkleProof(bscProof *Proof, blockData *types.Header, contractAddr []
 	}
 
 	sp := bscProof.StorageProofs[0]
-	storageKey := crypto.Keccak256(ecommon.HexToHash(scom.Replace0x(sp.Key)).Bytes())
+	storageKey := keccak256(ecommon.HexToHash(scom.Replace0x(sp.Key)).Bytes())
 
 	for _, prf := range sp.Proof {
 		nodeList.Put(nil, ecommon.Hex2Bytes(scom.Replace0x(prf)))
@@ -234,6 +244,6 @@ func checkProofResult(result, value []byte) bool {
 		s = append(s, 0)
 	}
 	s = append(s, tempBytes...)
-	hash := crypto.Keccak256(value)
+	hash := keccak256(value)
 	return bytes.Equal(s, hash)
 }
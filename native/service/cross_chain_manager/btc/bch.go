@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	chaincfg_bch "github.com/gcash/bchd/chaincfg"
+	"github.com/gcash/bchutil"
+)
+
+// Bitcoin Cash reuses the utxo framework of bitcoin. The differences are the CashAddr
+// address format, the lock script which is a plain p2sh since there is no segwit, and
+// the SIGHASH_FORKID signature digest.
+const SIGHASH_FORKID = txscript.SigHashType(0x40)
+
+func getBchNetParam(netParam *chaincfg.Params) *chaincfg_bch.Params {
+	switch netParam.Name {
+	case chaincfg.TestNet3Params.Name:
+		return &chaincfg_bch.TestNet3Params
+	case chaincfg.RegressionNetParams.Name:
+		return &chaincfg_bch.RegressionNetParams
+	case chaincfg.SimNetParams.Name:
+		return &chaincfg_bch.SimNetParams
+	default:
+		return &chaincfg_bch.MainNetParams
+	}
+}
+
+// getBchTxOuts accepts both CashAddr and legacy addresses
+func getBchTxOuts(amounts map[string]int64, netParam *chaincfg.Params) ([]*wire.TxOut, error) {
+	bchParam := getBchNetParam(netParam)
+	outs := make([]*wire.TxOut, 0)
+	for encodedAddr, amount := range amounts {
+		addr, err := bchutil.DecodeAddress(encodedAddr, bchParam)
+		if err != nil {
+			return nil, fmt.Errorf("getBchTxOuts, decode addr fail: %v", err)
+		}
+		if !addr.IsForNet(bchParam) {
+			return nil, fmt.Errorf("getBchTxOuts, addr is not for %s", bchParam.Name)
+		}
+
+		// the scripts are the same with bitcoin once the hash is extracted
+		var btcAddr btcutil.Address
+		switch a := addr.(type) {
+		case *bchutil.AddressPubKeyHash:
+			btcAddr, err = btcutil.NewAddressPubKeyHash(a.ScriptAddress(), netParam)
+		case *bchutil.AddressScriptHash:
+			btcAddr, err = btcutil.NewAddressScriptHashFromHash(a.ScriptAddress(), netParam)
+		default:
+			return nil, fmt.Errorf("getBchTxOuts, address type %T not supported", addr)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("getBchTxOuts, failed to convert address: %v", err)
+		}
+		pkScript, err := txscript.PayToAddrScript(btcAddr)
+		if err != nil {
+			return nil, fmt.Errorf("getBchTxOuts, failed to generate pay-to-address script: %v", err)
+		}
+		outs = append(outs, wire.NewTxOut(amount, pkScript))
+	}
+
+	return outs, nil
+}
+
+// calcBchSigHash computes the SIGHASH_FORKID digest which commits to the amount
+// spent. It is the BIP143 algorithm with a fork id of zero.
+func calcBchSigHash(redeem []byte, hashType txscript.SigHashType, tx *wire.MsgTx, idx int, amt int64) ([]byte, error) {
+	if hashType&SIGHASH_FORKID == 0 {
+		return nil, fmt.Errorf("calcBchSigHash, SIGHASH_FORKID is not set in hash type %x", hashType)
+	}
+	return txscript.CalcWitnessSigHash(redeem, txscript.NewTxSigHashes(tx), hashType, tx, idx, amt)
+}
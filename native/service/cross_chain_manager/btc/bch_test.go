@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBchTxOuts(t *testing.T) {
+	expect, _ := hex.DecodeString("76a91476a04053bda0a88bda5177b86a15c3b29f55987388ac")
+	for _, addr := range []string{
+		"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a",
+		"qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a",
+		"1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu",
+	} {
+		outs, err := getBchTxOuts(map[string]int64{addr: 1000}, &chaincfg.MainNetParams)
+		assert.NoError(t, err, addr)
+		assert.Equal(t, 1, len(outs))
+		assert.Equal(t, int64(1000), outs[0].Value)
+		assert.Equal(t, expect, outs[0].PkScript, addr)
+	}
+
+	_, err := getBchTxOuts(map[string]int64{"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a": 1000}, &chaincfg.TestNet3Params)
+	assert.Error(t, err)
+}
+
+func TestGetBchLockScript(t *testing.T) {
+	rs, _ := hex.DecodeString(redeem)
+	script, err := getP2shLockScript(rs, &chaincfg.TestNet3Params)
+	assert.NoError(t, err)
+	assert.Equal(t, p2sh, script)
+	assert.Equal(t, txscript.ScriptHashTy, txscript.GetScriptClass(script))
+}
+
+func TestCalcBchSigHash(t *testing.T) {
+	rs, _ := hex.DecodeString(redeem)
+	txb, _ := hex.DecodeString(unsignedTx)
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	_ = mtx.BtcDecode(bytes.NewBuffer(txb), wire.ProtocolVersion, wire.LatestEncoding)
+
+	_, err := calcBchSigHash(rs, txscript.SigHashAll, mtx, 0, 1e5)
+	assert.Error(t, err)
+
+	h1, err := calcBchSigHash(rs, txscript.SigHashAll|SIGHASH_FORKID, mtx, 0, 1e5)
+	assert.NoError(t, err)
+	h2, err := calcBchSigHash(rs, txscript.SigHashAll|SIGHASH_FORKID, mtx, 0, 2e5)
+	assert.NoError(t, err)
+	// the digest commits to the amount spent
+	assert.NotEqual(t, h1, h2)
+}
@@ -61,11 +61,18 @@ func (this *BTCHandler) MultiSign(service *native.NativeService) error {
 		return fmt.Errorf("MultiSign, get btc redeem script with redeem key %v from db error: %v", params.RedeemKey, err)
 	}
 
-	netParam, err := getNetParam(service, params.ChainID)
+	chain, err := getUtxoChain(service, params.ChainID)
 	if err != nil {
 		return fmt.Errorf("MultiSign, %v", err)
 	}
-	_, addrs, n, err := txscript.ExtractPkScriptAddrs(redeemScript, netParam)
+	windDown, err := side_chain_manager.GetWindDown(service, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("MultiSign, %v", err)
+	}
+	if windDown != nil && service.GetHeight() > windDown.EndHeight {
+		return fmt.Errorf("MultiSign, chain %d finished winding down at height %d", params.ChainID, windDown.EndHeight)
+	}
+	_, addrs, n, err := txscript.ExtractPkScriptAddrs(redeemScript, chain.netParam)
 	if err != nil {
 		return fmt.Errorf("MultiSign, failed to extract pkscript addrs: %v", err)
 	}
@@ -93,7 +100,7 @@ func (this *BTCHandler) MultiSign(service *native.NativeService) error {
 	if err != nil {
 		return fmt.Errorf("MultiSign, failed to get stxos: %v", err)
 	}
-	err = verifySigs(params.Signs, params.Address, addrs, redeemScript, mtx, pkScripts, amts)
+	err = verifySigs(params.Signs, params.Address, addrs, redeemScript, mtx, pkScripts, amts, chain.sigHash())
 	if err != nil {
 		return fmt.Errorf("MultiSign, failed to verify: %v", err)
 	}
@@ -115,13 +122,12 @@ func (this *BTCHandler) MultiSign(service *native.NativeService) error {
 		if err != nil {
 			return fmt.Errorf("MultiSign, failed to add sig to tx: %v", err)
 		}
-		var buf bytes.Buffer
-		err = mtx.BtcEncode(&buf, wire.ProtocolVersion, wire.LatestEncoding)
+		rawTx, txid, err := chain.encodeTx(mtx)
 		if err != nil {
 			return fmt.Errorf("MultiSign, failed to encode msgtx to bytes: %v", err)
 		}
 
-		witScript, err := getLockScript(redeemScript, netParam)
+		witScript, err := chain.getLockScript(redeemScript)
 		if err != nil {
 			return fmt.Errorf("MultiSign, failed to get lock script: %v", err)
 		}
@@ -129,7 +135,6 @@ func (this *BTCHandler) MultiSign(service *native.NativeService) error {
 		if err != nil {
 			return fmt.Errorf("MultiSign, getUtxos error: %v", err)
 		}
-		txid := mtx.TxHash()
 		for i, v := range mtx.TxOut {
 			if bytes.Equal(witScript, v.PkScript) {
 				newUtxo := &Utxo{
@@ -150,11 +155,24 @@ func (this *BTCHandler) MultiSign(service *native.NativeService) error {
 				hex.EncodeToString(params.TxHash), err)
 		}
 		putStxos(service, params.ChainID, params.RedeemKey, stxos)
+		pending, err := getPendingMultiSigns(service, params.ChainID, params.RedeemKey)
+		if err != nil {
+			return fmt.Errorf("MultiSign, %v", err)
+		}
+		for i, v := range pending.Items {
+			if bytes.Equal(v.TxHash, params.TxHash) {
+				pending.Items = append(pending.Items[:i], pending.Items[i+1:]...)
+				break
+			}
+		}
+		putPendingMultiSigns(service, params.ChainID, params.RedeemKey, pending)
 		service.AddNotify(
 			&event.NotifyEventInfo{
 				ContractAddress: utils.CrossChainManagerContractAddress,
 				States: []interface{}{"btcTxToRelay", btcFromTxInfo.FromChainID, params.ChainID,
-					hex.EncodeToString(buf.Bytes()), hex.EncodeToString(btcFromTxInfo.FromTxHash), params.RedeemKey},
+					hex.EncodeToString(rawTx), hex.EncodeToString(btcFromTxInfo.FromTxHash), params.RedeemKey},
+				FromChainID: event.ChainID(btcFromTxInfo.FromChainID),
+				ToChainID:   event.ChainID(params.ChainID),
 			})
 	}
 	return nil
@@ -176,7 +194,7 @@ func (this *BTCHandler) MakeDepositProposal(service *native.NativeService) (*cro
 	}
 
 	if err := crosscommon.CheckDoneTx(service, value.TxHash, params.SourceChainID); err != nil {
-		return nil, fmt.Errorf("MakeDepositProposal, check done transaction error:%s", err)
+		return nil, fmt.Errorf("MakeDepositProposal, check done transaction error:%w", err)
 	}
 
 	if err := crosscommon.PutDoneTx(service, value.TxHash, params.SourceChainID); err != nil {
@@ -184,16 +202,29 @@ func (this *BTCHandler) MakeDepositProposal(service *native.NativeService) (*cro
 	}
 
 	// decode tx and then update utxos
-	mtx := wire.NewMsgTx(wire.TxVersion)
-	reader := bytes.NewReader(params.Extra)
-	err = mtx.BtcDecode(reader, wire.ProtocolVersion, wire.LatestEncoding)
+	chain, err := getUtxoChain(service, params.SourceChainID)
+	if err != nil {
+		return nil, fmt.Errorf("btc MakeDepositProposal, %v", err)
+	}
+	mtx, txHash, err := chain.decodeTx(params.Extra)
 	if err != nil {
 		return nil, fmt.Errorf("VerifyFromBtcProof, failed to decode the transaction %s: %s", hex.EncodeToString(params.Extra), err)
 	}
-	err = addUtxos(service, params.SourceChainID, service.GetHeight(), mtx)
+	// the txid of a legacy transaction is malleable, so the outpoints are tracked as well
+	ops := depositOutPoints(mtx, txHash)
+	if err := checkDoneOutPoints(service, params.SourceChainID, ops); err != nil {
+		return nil, fmt.Errorf("MakeDepositProposal, %v", err)
+	}
+	putDoneOutPoints(service, params.SourceChainID, ops, txHash)
+	err = addUtxos(service, params.SourceChainID, service.GetHeight(), mtx, txHash)
 	if err != nil {
 		return nil, fmt.Errorf("btc Vote, updateUtxo error: %s", err)
 	}
+	_, amount := getLockOutputs(mtx)
+	err = addMinted(service, params.SourceChainID, GetUtxoKey(mtx.TxOut[0].PkScript), value.ToChainID, uint64(amount))
+	if err != nil {
+		return nil, fmt.Errorf("btc MakeDepositProposal, %v", err)
+	}
 
 	return value, nil
 }
@@ -252,65 +283,117 @@ func makeBtcTx(service *native.NativeService, chainID uint64, amounts map[string
 		return fmt.Errorf("makeBtcTx, sum(%d) of amounts exceeds the MaxSatoshi", amountSum)
 	}
 
-	netParam, err := getNetParam(service, chainID)
+	chain, err := getUtxoChain(service, chainID)
 	if err != nil {
 		return fmt.Errorf("makeBtcTx, %v", err)
 	}
 	// get tx outs
-	outs, err := getTxOuts(amounts, netParam)
+	outs, err := chain.getTxOuts(amounts)
 	if err != nil {
 		return fmt.Errorf("makeBtcTx, %v", err)
 	}
-	script, err := getLockScript(redeemScript, netParam)
+	script, err := chain.getLockScript(redeemScript)
 	if err != nil {
 		return fmt.Errorf("makeBtcTx, %v", err)
 	}
 	out := wire.NewTxOut(0, script)
-	_, addrs, m, _ := txscript.ExtractPkScriptAddrs(redeemScript, netParam)
-	choosed, sum, gasFee, err := chooseUtxos(service, chainID, amountSum, append(outs, out), rk, m, len(addrs))
+	_, addrs, m, _ := txscript.ExtractPkScriptAddrs(redeemScript, chain.netParam)
+	choosed, _, gasFee, err := chooseUtxos(service, chainID, amountSum, append(outs, out), rk, m, len(addrs))
 	if err != nil {
 		return fmt.Errorf("makeBtcTx, chooseUtxos error: %v", err)
 	}
-	amts := make([]uint64, len(choosed))
-	txIns := make([]*wire.TxIn, len(choosed))
-	for i, u := range choosed {
+	tx, err := buildBtcTx(chain, choosed, outs, out, amountSum, gasFee, redeemScript)
+	if err != nil {
+		return fmt.Errorf("makeBtcTx, %v", err)
+	}
+	redeemKey := hex.EncodeToString(rk)
+	if err := subMinted(service, chainID, redeemKey, fromChainID, uint64(amountSum)); err != nil {
+		return fmt.Errorf("makeBtcTx, %v", err)
+	}
+	pending, err := getPendingMultiSigns(service, chainID, redeemKey)
+	if err != nil {
+		return fmt.Errorf("makeBtcTx, %v", err)
+	}
+	putBtcTx(service, chainID, rk, tx, pending, &BtcTxRequest{
+		Height:      service.GetHeight(),
+		FromChainID: fromChainID,
+		FromTxHash:  fromTxHash,
+		Amounts:     amounts,
+	})
+	return nil
+}
+
+// unsignedBtcTx is a tx withdrawing from a redeem which is built but not stored yet
+type unsignedBtcTx struct {
+	wireTx  []byte
+	rawTx   []byte
+	txHash  chainhash.Hash
+	amts    []uint64
+	digests [][]byte
+}
+
+// buildBtcTx builds the tx spending ins to outs, the fee is taken from outs in proportion and the rest
+// of ins goes back to the redeem by change. Nothing is written to the store.
+func buildBtcTx(chain *utxoChain, ins []*Utxo, outs []*wire.TxOut, change *wire.TxOut, amountSum, gasFee int64,
+	redeemScript []byte) (*unsignedBtcTx, error) {
+	var sum int64
+	amts := make([]uint64, len(ins))
+	txIns := make([]*wire.TxIn, len(ins))
+	for i, u := range ins {
 		hash, err := chainhash.NewHash(u.Op.Hash)
 		if err != nil {
-			return fmt.Errorf("makeBtcTx, chainhash.NewHash error: %v", err)
+			return nil, fmt.Errorf("buildBtcTx, chainhash.NewHash error: %v", err)
 		}
 		txIns[i] = wire.NewTxIn(wire.NewOutPoint(hash, u.Op.Index), u.ScriptPubkey, nil)
 		amts[i] = u.Value
+		sum += int64(u.Value)
+	}
+	if sum < amountSum {
+		return nil, fmt.Errorf("buildBtcTx, sum(%d) of inputs is less than the amount %d", sum, amountSum)
 	}
 	for i := range outs {
 		outs[i].Value = outs[i].Value - int64(float64(gasFee)/float64(amountSum)*float64(outs[i].Value))
 	}
-	out.Value = sum - amountSum
-	mtx, err := getUnsignedTx(txIns, outs, out, nil)
+	change.Value = sum - amountSum
+	mtx, err := getUnsignedTx(txIns, outs, change, nil)
 	if err != nil {
-		return fmt.Errorf("makeBtcTx, get rawtransaction fail: %v", err)
+		return nil, fmt.Errorf("buildBtcTx, get rawtransaction fail: %v", err)
 	}
 
 	var buf bytes.Buffer
 	err = mtx.BtcEncode(&buf, wire.ProtocolVersion, wire.LatestEncoding)
 	if err != nil {
-		return fmt.Errorf("makeBtcTx, serialize rawtransaction fail: %v", err)
+		return nil, fmt.Errorf("buildBtcTx, serialize rawtransaction fail: %v", err)
 	}
-	txHash := mtx.TxHash()
-	service.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_TX_PREFIX),
-		txHash[:]), buf.Bytes())
-
-	btcFromInfo := &BtcFromInfo{
-		FromTxHash:  fromTxHash,
-		FromChainID: fromChainID,
+	rawTx, txHash, err := chain.encodeTx(mtx)
+	if err != nil {
+		return nil, fmt.Errorf("buildBtcTx, serialize rawtransaction fail: %v", err)
 	}
-	if err = putBtcFromInfo(service, txHash[:], btcFromInfo); err != nil {
-		return fmt.Errorf("makeBtcTx, putBtcFromInfo failed: %v", err)
+	digests, err := calcSigDigests(chain, redeemScript, mtx, amts)
+	if err != nil {
+		return nil, fmt.Errorf("buildBtcTx, %v", err)
 	}
+	return &unsignedBtcTx{wireTx: buf.Bytes(), rawTx: rawTx, txHash: txHash, amts: amts, digests: digests}, nil
+}
+
+// putBtcTx stores tx made for request and appends it to pending of the redeem to collect signatures
+func putBtcTx(service *native.NativeService, chainID uint64, rk []byte, tx *unsignedBtcTx,
+	pending *PendingMultiSigns, request *BtcTxRequest) {
+	// the tx is kept in the wire format whatever the chain is, signers get the raw one of the chain from event
+	service.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_TX_PREFIX),
+		tx.txHash[:]), tx.wireTx)
+	_ = putBtcFromInfo(service, tx.txHash[:], &BtcFromInfo{
+		FromTxHash:  request.FromTxHash,
+		FromChainID: request.FromChainID,
+	})
+	putBtcTxRequest(service, tx.txHash[:], request)
+
+	redeemKey := hex.EncodeToString(rk)
+	pending.Items = append(pending.Items, &PendingMultiSign{TxHash: tx.txHash[:], RawTx: tx.rawTx, Digests: tx.digests})
+	putPendingMultiSigns(service, chainID, redeemKey, pending)
 	service.AddNotify(
 		&event.NotifyEventInfo{
 			ContractAddress: utils.CrossChainManagerContractAddress,
-			States:          []interface{}{"makeBtcTx", hex.EncodeToString(rk), hex.EncodeToString(buf.Bytes()), amts},
+			States:          []interface{}{"makeBtcTx", redeemKey, hex.EncodeToString(tx.rawTx), tx.amts},
 		})
-
-	return nil
 }
@@ -56,6 +56,10 @@ var (
 	toEthAddr         = "0x5cD3143f91a13Fe971043E1e4605C1c23b46bF44"
 	ebtcxAddr         = "0x9702640a6b971CA18EFC20AD73CA4e8bA390C910"
 
+	depositRawTx      = "01000000015dbdab5a45905efd23e0753d1aaf2a417d77dd8c079499a1643bc168817bf8ab4f0000006a47304402206553c4a3cb1c37cd68b4bb25412cc35d73b731dcef3874635761172f53d70bbf0220264e5afd78936a920d6bcc0720ef5f5d25e7a153f25e18264bd3952038780224012102141d092eca49eac51de2760d28cbced212b60efc23fdcbb57304823bb17aa64effffffff031027000000000000220020216a09cb8ee51da1a91ea8942552d7936c886a10b507299003661816c0e9f18b0000000000000000286a26cc02000000000000000000000000000000145cd3143f91a13fe971043e1e4605c1c23b46bf44a85b0100000000001976a9145f35a2cc0318fbc17c4c479964734e7a9f8819d788ac00000000"
+	depositProof      = "0000002037083b799b61659dedf733d4945e4ce65e31018ca7e1c2a247f0120000000000ddb35a12a3651cc57358ead0fde2e504f26cf46568b594238e487359651d2e5060d5715effff001d74ec61d6370100000a4702e34d13d88ca00bcea9e15428040de063fd3772fb0492b46bc9ac734612f7d1f8a7ffd7d1f965cad52b3ec06efa3e49e20344de6463d7688453050a37b52b09a2a2efe3057dca55982d5f7ff3b1f36fda89d2b2a1f015acd3ce7afda0abfe96662da89072ef81d5795add6f50dee212a41dbdd2720a1d8c53520bed8e7fa8732bbc20668e26657be4de157fe22cbb508e6e92030bf97b75298db89026f027d0516c4bffda74583043ca723e45505044373e8b6c4a4476a3908dc60d33cb6721b2bc97b3e2074d2ab6617ad3204fec91130fe06e5736ac9d07f66caee0c05309d5d8e752dadbe4c365f815e1902f6ce80be7269f296cb49bfd832c243dd4580dcba943ed5b67f8d233d19b6402fcc39e61bfe01938dc98e4dd2043efed8dabbd65df34229b60bd0a0afd0823ef8c8055cd52d1737d3a991575a6a41cbaeb1e03b75a00"
+	depositWrongProof = "0100003037db655b09de3449fe60bc0838ef3541e28d3ae31a05093f1bb63e4845a6b102695fd2a687fc1fc368f13227c2bb1b6b0fcac9760936d869a9ba01f8a75f825c5105245effff7f20050000000200000002e0c8d9fb711dd377d0ba8d1c16c154b903432aa8923c87f3f0fd6045be7b8c8a51cf2962a492309e6bd7aa56848b2817c1a760f9fb0823762200d2b286f988b90105"
+
 	sigs = []string{
 		"3045022100f77b28268bfed3c0ddc8d35e556164d7ce2f571715b5afbf79373e699f4023e102200f86442f305528088caca96453d7f4fd782c831198c51e7637a7024ca844e10d01",
 		"30450221009a320292de5b0881f2988b603d7dac98c666c9653c177e1add6e26b2ae7902860220211d58d5e261e9ce80fa1b12892f02eb1a10c04712fe1609464629a794f5eaa001",
@@ -140,20 +144,19 @@ func TestBTCHandler_MakeDepositProposal(t *testing.T) {
 	gh := netParam.GenesisBlock.Header
 	mr, _ := chainhash.NewHashFromStr("502e1d655973488e2394b56865f46cf204e5e2fdd0ea5873c51c65a3125ab3dd")
 	gh.MerkleRoot = *mr
-	db, err := syncGenesisHeader(&gh)
+	db, err := syncGenesisHeader(&gh, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 	db = registerRC(db)
 
 	txid, _ := chainhash.NewHashFromStr("67cb330dc68d90a376444a6c8b3e37445050453e72ca43305874daff4b6c51d0")
-	rawTx, _ := hex.DecodeString("01000000015dbdab5a45905efd23e0753d1aaf2a417d77dd8c079499a1643bc168817bf8ab4f0000006a47304402206553c4a3cb1c37cd68b4bb25412cc35d73b731dcef3874635761172f53d70bbf0220264e5afd78936a920d6bcc0720ef5f5d25e7a153f25e18264bd3952038780224012102141d092eca49eac51de2760d28cbced212b60efc23fdcbb57304823bb17aa64effffffff031027000000000000220020216a09cb8ee51da1a91ea8942552d7936c886a10b507299003661816c0e9f18b0000000000000000286a26cc02000000000000000000000000000000145cd3143f91a13fe971043e1e4605c1c23b46bf44a85b0100000000001976a9145f35a2cc0318fbc17c4c479964734e7a9f8819d788ac00000000")
+	rawTx, _ := hex.DecodeString(depositRawTx)
 	scAddr, _ := hex.DecodeString(strings.Replace(ebtcxAddr, "0x", "", 1))
 	handler := NewBTCHandler()
 
 	// wrong proof
-	wrongProof := "0100003037db655b09de3449fe60bc0838ef3541e28d3ae31a05093f1bb63e4845a6b102695fd2a687fc1fc368f13227c2bb1b6b0fcac9760936d869a9ba01f8a75f825c5105245effff7f20050000000200000002e0c8d9fb711dd377d0ba8d1c16c154b903432aa8923c87f3f0fd6045be7b8c8a51cf2962a492309e6bd7aa56848b2817c1a760f9fb0823762200d2b286f988b90105"
-	proof, _ := hex.DecodeString(wrongProof)
+	proof, _ := hex.DecodeString(depositWrongProof)
 	params := new(ccmcom.EntranceParam)
 	params.Height = 0
 	params.SourceChainID = 1
@@ -169,7 +172,7 @@ func TestBTCHandler_MakeDepositProposal(t *testing.T) {
 	assert.Error(t, err)
 
 	// normal case
-	proof, _ = hex.DecodeString("0000002037083b799b61659dedf733d4945e4ce65e31018ca7e1c2a247f0120000000000ddb35a12a3651cc57358ead0fde2e504f26cf46568b594238e487359651d2e5060d5715effff001d74ec61d6370100000a4702e34d13d88ca00bcea9e15428040de063fd3772fb0492b46bc9ac734612f7d1f8a7ffd7d1f965cad52b3ec06efa3e49e20344de6463d7688453050a37b52b09a2a2efe3057dca55982d5f7ff3b1f36fda89d2b2a1f015acd3ce7afda0abfe96662da89072ef81d5795add6f50dee212a41dbdd2720a1d8c53520bed8e7fa8732bbc20668e26657be4de157fe22cbb508e6e92030bf97b75298db89026f027d0516c4bffda74583043ca723e45505044373e8b6c4a4476a3908dc60d33cb6721b2bc97b3e2074d2ab6617ad3204fec91130fe06e5736ac9d07f66caee0c05309d5d8e752dadbe4c365f815e1902f6ce80be7269f296cb49bfd832c243dd4580dcba943ed5b67f8d233d19b6402fcc39e61bfe01938dc98e4dd2043efed8dabbd65df34229b60bd0a0afd0823ef8c8055cd52d1737d3a991575a6a41cbaeb1e03b75a00")
+	proof, _ = hex.DecodeString(depositProof)
 	params.Proof = proof
 
 	sink.Reset()
@@ -211,7 +214,7 @@ func TestBTCHandler_MakeTransaction(t *testing.T) {
 	mtx := wire.NewMsgTx(wire.TxVersion)
 	_ = mtx.BtcDecode(bytes.NewBuffer(rawTx), wire.ProtocolVersion, wire.LatestEncoding)
 	ns := getNativeFunc(nil, nil)
-	_ = addUtxos(ns, 1, 0, mtx)
+	_ = addUtxos(ns, 1, 0, mtx, mtx.TxHash())
 	setSideChain(ns)
 	registerRC(ns.GetCacheDB())
 	setBtcTxParam(ns.GetCacheDB(), utxoKey)
@@ -246,7 +249,7 @@ func TestBTCHandler_MultiSign(t *testing.T) {
 	mtx := wire.NewMsgTx(wire.TxVersion)
 	_ = mtx.BtcDecode(bytes.NewBuffer(rawTx), wire.ProtocolVersion, wire.LatestEncoding)
 	ns := getNativeFunc(nil, nil)
-	_ = addUtxos(ns, 1, 0, mtx)
+	_ = addUtxos(ns, 1, 0, mtx, mtx.TxHash())
 	setBtcTxParam(ns.GetCacheDB(), utxoKey)
 	registerRC(ns.GetCacheDB())
 
@@ -344,7 +347,7 @@ func TestBTCHandler_MultiSign(t *testing.T) {
 	assert.Equal(t, txid.String()+":1", utxos.Utxos[0].Op.String())
 }
 
-func syncGenesisHeader(genesisHeader *wire.BlockHeader) (*storage.CacheDB, error) {
+func syncGenesisHeader(genesisHeader *wire.BlockHeader, db *storage.CacheDB) (*storage.CacheDB, error) {
 	var buf bytes.Buffer
 	_ = genesisHeader.BtcEncode(&buf, wire.ProtocolVersion, wire.LatestEncoding)
 	btcHander := btc.NewBTCHandler()
@@ -360,7 +363,7 @@ func syncGenesisHeader(genesisHeader *wire.BlockHeader) (*storage.CacheDB, error
 	sink = new(common.ZeroCopySink)
 	params.Serialization(sink)
 
-	ns := getNativeFunc(sink.Bytes(), nil)
+	ns := getNativeFunc(sink.Bytes(), db)
 	err := btcHander.SyncGenesisHeader(ns)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// sigHashFunc computes the digest signed for the p2sh input idx of tx
+type sigHashFunc func(redeem []byte, hashType txscript.SigHashType, tx *wire.MsgTx, idx int, amt int64) ([]byte, error)
+
+// utxoChain gathers what differs between the utxo chains sharing this framework:
+// bitcoin, bitcoin cash and the transparent pool of zcash.
+type utxoChain struct {
+	router   uint64
+	netParam *chaincfg.Params
+	// consensus branch id of the current zcash network upgrade
+	branchID uint32
+}
+
+func getUtxoChain(service *native.NativeService, chainId uint64) (*utxoChain, error) {
+	side, err := side_chain_manager.GetSideChain(service, chainId)
+	if err != nil {
+		return nil, fmt.Errorf("getUtxoChain, get side chain error: %v", err)
+	}
+	if side == nil {
+		return nil, fmt.Errorf("getUtxoChain, side chain info for chainId: %d is not registered", chainId)
+	}
+	netParam, err := getNetParam(service, chainId)
+	if err != nil {
+		return nil, fmt.Errorf("getUtxoChain, %v", err)
+	}
+	chain := &utxoChain{
+		router:   side.Router,
+		netParam: netParam,
+	}
+	if side.Router == utils.ZCASH_ROUTER {
+		if len(side.ExtraInfo) != 4 {
+			return nil, fmt.Errorf("getUtxoChain, consensus branch id of zcash chain %d should be set in extra info", chainId)
+		}
+		chain.branchID = binary.LittleEndian.Uint32(side.ExtraInfo)
+	}
+	return chain, nil
+}
+
+func (this *utxoChain) getTxOuts(amounts map[string]int64) ([]*wire.TxOut, error) {
+	switch this.router {
+	case utils.BCH_ROUTER:
+		return getBchTxOuts(amounts, this.netParam)
+	case utils.ZCASH_ROUTER:
+		return getZcashTxOuts(amounts, this.netParam)
+	default:
+		return getTxOuts(amounts, this.netParam)
+	}
+}
+
+// getLockScript returns the script locking the utxos of redeem, there is no segwit
+// out of bitcoin.
+func (this *utxoChain) getLockScript(redeem []byte) ([]byte, error) {
+	switch this.router {
+	case utils.BCH_ROUTER, utils.ZCASH_ROUTER:
+		return getP2shLockScript(redeem, this.netParam)
+	default:
+		return getLockScript(redeem, this.netParam)
+	}
+}
+
+// sigHash returns nil for bitcoin which has both the legacy and the witness digest.
+func (this *utxoChain) sigHash() sigHashFunc {
+	switch this.router {
+	case utils.BCH_ROUTER:
+		return calcBchSigHash
+	case utils.ZCASH_ROUTER:
+		return func(redeem []byte, hashType txscript.SigHashType, tx *wire.MsgTx, idx int, amt int64) ([]byte, error) {
+			return calcZcashSigHash(redeem, hashType, tx, idx, amt, this.branchID)
+		}
+	default:
+		return nil
+	}
+}
+
+// hashType returns the sighash type the signers are expected to use
+func (this *utxoChain) hashType() txscript.SigHashType {
+	if this.router == utils.BCH_ROUTER {
+		return txscript.SigHashAll | SIGHASH_FORKID
+	}
+	return txscript.SigHashAll
+}
+
+// decodeTx decodes the raw transaction of the chain and returns its id
+func (this *utxoChain) decodeTx(raw []byte) (*wire.MsgTx, chainhash.Hash, error) {
+	if this.router == utils.ZCASH_ROUTER {
+		mtx, err := decodeZcashTx(raw)
+		if err != nil {
+			return nil, chainhash.Hash{}, err
+		}
+		return mtx, chainhash.DoubleHashH(raw), nil
+	}
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	if err := mtx.BtcDecode(bytes.NewReader(raw), wire.ProtocolVersion, wire.LatestEncoding); err != nil {
+		return nil, chainhash.Hash{}, err
+	}
+	return mtx, mtx.TxHash(), nil
+}
+
+// encodeTx encodes mtx in the format of the chain and returns its id
+func (this *utxoChain) encodeTx(mtx *wire.MsgTx) ([]byte, chainhash.Hash, error) {
+	if this.router == utils.ZCASH_ROUTER {
+		raw := encodeZcashTx(mtx)
+		return raw, chainhash.DoubleHashH(raw), nil
+	}
+	var buf bytes.Buffer
+	if err := mtx.BtcEncode(&buf, wire.ProtocolVersion, wire.LatestEncoding); err != nil {
+		return nil, chainhash.Hash{}, err
+	}
+	return buf.Bytes(), mtx.TxHash(), nil
+}
+
+func getP2shLockScript(redeem []byte, netParam *chaincfg.Params) ([]byte, error) {
+	addr, err := btcutil.NewAddressScriptHash(redeem, netParam)
+	if err != nil {
+		return nil, fmt.Errorf("getP2shLockScript, failed to get p2sh address: %v", err)
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, fmt.Errorf("getP2shLockScript, failed to get p2sh script: %v", err)
+	}
+	return script, nil
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/polynetwork/poly/common"
+	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/testsupport"
+	"github.com/polynetwork/poly/native/storage"
+)
+
+func TestConformance(t *testing.T) {
+	rawTx, _ := hex.DecodeString(depositRawTx)
+	proof, _ := hex.DecodeString(depositProof)
+	wrongProof, _ := hex.DecodeString(depositWrongProof)
+	params := &ccmcom.EntranceParam{
+		SourceChainID:  1,
+		Height:         0,
+		Proof:          proof,
+		RelayerAddress: acct.Address[:],
+		Extra:          rawTx,
+	}
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	valid := sink.Bytes()
+
+	params.Proof = wrongProof
+	sink = common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	malformed := testsupport.MalformedEntrances(valid)
+	malformed["wrong proof"] = sink.Bytes()
+
+	testsupport.Run(t, &testsupport.Fixture{
+		Handler: NewBTCHandler(),
+		Setup: func(t *testing.T, db *storage.CacheDB) {
+			gh := chaincfg.TestNet3Params.GenesisBlock.Header
+			mr, _ := chainhash.NewHashFromStr("502e1d655973488e2394b56865f46cf204e5e2fdd0ea5873c51c65a3125ab3dd")
+			gh.MerkleRoot = *mr
+			if _, err := syncGenesisHeader(&gh, db); err != nil {
+				t.Fatalf("syncGenesisHeader error: %v", err)
+			}
+			registerRC(db)
+			setSideChain(getNativeFunc(nil, db))
+		},
+		Valid:     valid,
+		Malformed: malformed,
+	})
+}
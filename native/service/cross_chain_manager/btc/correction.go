@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+)
+
+// GetUtxoSet returns the utxos tracked for the redeem of the chain, not including those spent by
+// the txs waiting for signatures
+func GetUtxoSet(native *native.NativeService, chainID uint64, redeemKey string) (*Utxos, error) {
+	return getUtxos(native, chainID, redeemKey)
+}
+
+// CorrectUtxos replaces the utxos tracked for the redeem of the chain, to recover the set after a
+// rollback of the chain or an accounting bug. The utxos must be locked by the redeem and not spent by
+// a tx waiting for signatures. The values of the set before and after are returned.
+func CorrectUtxos(native *native.NativeService, chainID uint64, redeemKey string, utxos *Utxos) (uint64, uint64, error) {
+	stxos, err := getStxos(native, chainID, redeemKey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("CorrectUtxos, %v", err)
+	}
+	spent := make(map[string]bool, len(stxos.Utxos))
+	for _, v := range stxos.Utxos {
+		spent[v.Op.String()] = true
+	}
+	seen := make(map[string]bool, len(utxos.Utxos))
+	var after uint64
+	for _, v := range utxos.Utxos {
+		if v.Op == nil || len(v.Op.Hash) != common.UINT256_SIZE {
+			return 0, 0, fmt.Errorf("CorrectUtxos, invalid out point")
+		}
+		op := v.Op.String()
+		if seen[op] {
+			return 0, 0, fmt.Errorf("CorrectUtxos, utxo %s is duplicated", op)
+		}
+		seen[op] = true
+		if spent[op] {
+			return 0, 0, fmt.Errorf("CorrectUtxos, utxo %s is spent by a tx waiting for signatures", op)
+		}
+		if GetUtxoKey(v.ScriptPubkey) != redeemKey {
+			return 0, 0, fmt.Errorf("CorrectUtxos, utxo %s is not locked by redeem %s", op, redeemKey)
+		}
+		after += v.Value
+	}
+
+	old, err := getUtxos(native, chainID, redeemKey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("CorrectUtxos, %v", err)
+	}
+	var before uint64
+	for _, v := range old.Utxos {
+		before += v.Value
+	}
+	putUtxos(native, chainID, redeemKey, utxos)
+	if _, err := reconcileReserve(native, chainID, redeemKey); err != nil {
+		return 0, 0, fmt.Errorf("CorrectUtxos, %v", err)
+	}
+	return before, after, nil
+}
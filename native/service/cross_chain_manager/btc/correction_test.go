@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrectUtxos(t *testing.T) {
+	ns := getNativeFunc(nil, nil)
+	redeemKey := GetUtxoKey(p2sh)
+	spent := &Utxo{Op: &OutPoint{Hash: make([]byte, 32), Index: 1}, Value: 5000, ScriptPubkey: p2sh}
+	putUtxos(ns, 1, redeemKey, &Utxos{Utxos: []*Utxo{{Op: &OutPoint{Hash: make([]byte, 32)}, Value: 10000, ScriptPubkey: p2sh}}})
+	putStxos(ns, 1, redeemKey, &Utxos{Utxos: []*Utxo{spent}})
+
+	utxos := &Utxos{Utxos: []*Utxo{
+		{Op: &OutPoint{Hash: make([]byte, 32)}, AtHeight: 10, Value: 10000, ScriptPubkey: p2sh},
+		{Op: &OutPoint{Hash: make([]byte, 32), Index: 2}, AtHeight: 12, Value: 3000, ScriptPubkey: p2sh},
+	}}
+	before, after, err := CorrectUtxos(ns, 1, redeemKey, utxos)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10000), before)
+	assert.Equal(t, uint64(13000), after)
+	set, err := GetUtxoSet(ns, 1, redeemKey)
+	assert.NoError(t, err)
+	assert.Equal(t, utxos, set)
+
+	_, _, err = CorrectUtxos(ns, 1, redeemKey, &Utxos{Utxos: []*Utxo{utxos.Utxos[0], utxos.Utxos[0]}})
+	assert.Error(t, err, "duplicated utxo")
+	_, _, err = CorrectUtxos(ns, 1, redeemKey, &Utxos{Utxos: []*Utxo{spent}})
+	assert.Error(t, err, "utxo spent by a pending tx")
+	_, _, err = CorrectUtxos(ns, 1, utxoKey, &Utxos{Utxos: []*Utxo{utxos.Utxos[1]}})
+	assert.Error(t, err, "utxo of another redeem")
+	set, err = GetUtxoSet(ns, 1, redeemKey)
+	assert.NoError(t, err)
+	assert.Equal(t, utxos, set, "rejected corrections change nothing")
+}
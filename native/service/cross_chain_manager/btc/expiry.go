@@ -0,0 +1,220 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func init() {
+	node_manager.RegisterViewChangeHook("cross_chain_manager/btc/expireTxs", node_manager.VIEW_CHANGE_HOUSEKEEPING, expireBtcTxs)
+}
+
+func btcTxRequestKey(txHash []byte) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_TX_REQUEST), txHash)
+}
+
+func putBtcTxRequest(native *native.NativeService, txHash []byte, request *BtcTxRequest) {
+	sink := common.NewZeroCopySink(nil)
+	request.Serialization(sink)
+	native.GetCacheDB().Put(btcTxRequestKey(txHash), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// getBtcTxRequest returns nil for the txs made before the requests were recorded, they never expire
+func getBtcTxRequest(native *native.NativeService, txHash []byte) (*BtcTxRequest, error) {
+	store, err := native.GetCacheDB().Get(btcTxRequestKey(txHash))
+	if err != nil {
+		return nil, fmt.Errorf("getBtcTxRequest, get store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("getBtcTxRequest, deserialize from raw storage item err:%v", err)
+	}
+	request := new(BtcTxRequest)
+	if err := request.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("getBtcTxRequest, deserialize BtcTxRequest error: %v", err)
+	}
+	return request, nil
+}
+
+// expireBtcTxs makes again the txs waiting for signatures over BTC_TX_EXPIRY_BLOCKS once a view. The
+// new tx spends the same utxos as the expired one, so at most one of them can ever be confirmed, and the
+// expired one is kept as it was if the new one fails to be made.
+func expireBtcTxs(native *native.NativeService, view uint32) {
+	type custody struct {
+		chainID   uint64
+		redeemKey string
+	}
+	prefix := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_PENDING_MULTI_SIGN))
+	custodies := make([]custody, 0)
+	iter := native.GetCacheDB().NewIterator(prefix)
+	for has := iter.First(); has; has = iter.Next() {
+		rest := iter.Key()[len(prefix):]
+		if len(rest) <= 8 {
+			continue
+		}
+		custodies = append(custodies, custody{chainID: utils.GetBytesUint64(rest[:8]), redeemKey: string(rest[8:])})
+	}
+	iter.Release()
+	for _, c := range custodies {
+		pending, err := getPendingMultiSigns(native, c.chainID, c.redeemKey)
+		if err != nil {
+			continue
+		}
+		for _, v := range pending.Items {
+			request, err := getBtcTxRequest(native, v.TxHash)
+			if err != nil || request == nil || native.GetHeight() < request.Height+BTC_TX_EXPIRY_BLOCKS {
+				continue
+			}
+			if err := expireBtcTx(native, c.chainID, c.redeemKey, v.TxHash, request); err != nil {
+				native.AddNotify(
+					&event.NotifyEventInfo{
+						ContractAddress: utils.CrossChainManagerContractAddress,
+						States: []interface{}{"btcTxExpiryFailed", c.chainID, c.redeemKey, hex.EncodeToString(v.TxHash),
+							err.Error()},
+					})
+			}
+		}
+	}
+}
+
+// expireBtcTx replaces the tx txHash of the redeem by the one spending the same utxos with the fee
+// rate of now. Everything is computed before the store is touched, the tx is left as it was on error.
+func expireBtcTx(native *native.NativeService, chainID uint64, redeemKey string, txHash []byte, request *BtcTxRequest) error {
+	txKey := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_TX_PREFIX), txHash)
+	txb, err := native.GetCacheDB().Get(txKey)
+	if err != nil {
+		return fmt.Errorf("expireBtcTx, get tx error: %v", err)
+	}
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	if err := mtx.BtcDecode(bytes.NewBuffer(txb), wire.ProtocolVersion, wire.LatestEncoding); err != nil {
+		return fmt.Errorf("expireBtcTx, failed to decode tx: %v", err)
+	}
+	rk, err := hex.DecodeString(redeemKey)
+	if err != nil {
+		return fmt.Errorf("expireBtcTx, decode redeem key error: %v", err)
+	}
+	redeemScript, err := side_chain_manager.GetBtcRedeemScriptBytes(native, redeemKey, chainID)
+	if err != nil {
+		return fmt.Errorf("expireBtcTx, get redeem script error: %v", err)
+	}
+	detail, err := side_chain_manager.GetBtcTxParam(native, rk, chainID)
+	if err != nil {
+		return fmt.Errorf("expireBtcTx, failed to get btcTxParam: %v", err)
+	}
+	if detail == nil {
+		return fmt.Errorf("expireBtcTx, no btcTxParam is set for redeem key %s", redeemKey)
+	}
+	chain, err := getUtxoChain(native, chainID)
+	if err != nil {
+		return fmt.Errorf("expireBtcTx, %v", err)
+	}
+
+	// the utxos spent by the tx stay in stxos and are spent again by the new one
+	stxos, err := getStxos(native, chainID, redeemKey)
+	if err != nil {
+		return fmt.Errorf("expireBtcTx, %v", err)
+	}
+	ins := make([]*Utxo, 0, len(mtx.TxIn))
+	for _, in := range mtx.TxIn {
+		var spent *Utxo
+		for _, v := range stxos.Utxos {
+			if bytes.Equal(in.PreviousOutPoint.Hash[:], v.Op.Hash) && in.PreviousOutPoint.Index == v.Op.Index {
+				spent = v
+				break
+			}
+		}
+		if spent == nil {
+			return fmt.Errorf("expireBtcTx, utxo %s spent by tx is not found", in.PreviousOutPoint.String())
+		}
+		ins = append(ins, spent)
+	}
+
+	var amountSum int64
+	for _, v := range request.Amounts {
+		amountSum += v
+	}
+	outs, err := chain.getTxOuts(request.Amounts)
+	if err != nil {
+		return fmt.Errorf("expireBtcTx, %v", err)
+	}
+	script, err := chain.getLockScript(redeemScript)
+	if err != nil {
+		return fmt.Errorf("expireBtcTx, %v", err)
+	}
+	out := wire.NewTxOut(0, script)
+	_, addrs, m, _ := txscript.ExtractPkScriptAddrs(redeemScript, chain.netParam)
+	cs := &CoinSelector{
+		txOuts:  append(outs, out),
+		feeRate: detail.FeeRate,
+		m:       m,
+		n:       len(addrs),
+	}
+	gasFee := cs.estimateTxFee(ins)
+	if amountSum <= 0 || float64(gasFee)/float64(amountSum) >= MAX_FEE_COST_PERCENTS {
+		return fmt.Errorf("expireBtcTx, fee %d is too high for the amount %d", gasFee, amountSum)
+	}
+	tx, err := buildBtcTx(chain, ins, outs, out, amountSum, int64(gasFee), redeemScript)
+	if err != nil {
+		return fmt.Errorf("expireBtcTx, %v", err)
+	}
+	pending, err := getPendingMultiSigns(native, chainID, redeemKey)
+	if err != nil {
+		return fmt.Errorf("expireBtcTx, %v", err)
+	}
+
+	// drop the tx and put the new one, the amount withdrawn stays the same
+	for i, v := range pending.Items {
+		if bytes.Equal(v.TxHash, txHash) {
+			pending.Items = append(pending.Items[:i], pending.Items[i+1:]...)
+			break
+		}
+	}
+	native.GetCacheDB().Delete(txKey)
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_FROM_TX_PREFIX), txHash))
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(MULTI_SIGN_INFO), txHash))
+	native.GetCacheDB().Delete(btcTxRequestKey(txHash))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{"btcTxExpired", chainID, redeemKey, hex.EncodeToString(txHash),
+				hex.EncodeToString(request.FromTxHash)},
+		})
+	putBtcTx(native, chainID, rk, tx, pending, &BtcTxRequest{
+		Height:      native.GetHeight(),
+		FromChainID: request.FromChainID,
+		FromTxHash:  request.FromTxHash,
+		Amounts:     request.Amounts,
+	})
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpireBtcTxs(t *testing.T) {
+	rawTx, _ := hex.DecodeString(fromBtcRawTx)
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	_ = mtx.BtcDecode(bytes.NewBuffer(rawTx), wire.ProtocolVersion, wire.LatestEncoding)
+	ns := getNativeFunc(nil, nil)
+	_ = addUtxos(ns, 1, 0, mtx, mtx.TxHash())
+	setBtcTxParam(ns.GetCacheDB(), utxoKey)
+	registerRC(ns.GetCacheDB())
+
+	rb, _ := hex.DecodeString(rdm)
+	err := makeBtcTx(ns, 1, map[string]int64{"mjEoyyCPsLzJ23xMX6Mti13zMyN36kzn57": 6000}, []byte{123},
+		2, rb, btcutil.Hash160(rb))
+	assert.NoError(t, err)
+	pending, err := getPendingMultiSigns(ns, 1, utxoKey)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(pending.Items))
+	txHash := pending.Items[0].TxHash
+
+	atHeight := func(height uint32) *native.NativeService {
+		service, _ := native.NewNativeService(ns.GetCacheDB(), &types.Transaction{ChainID: 0}, 0, height,
+			common.Uint256{0}, 0, nil, false)
+		return service
+	}
+	// not expired yet
+	service := atHeight(BTC_TX_EXPIRY_BLOCKS - 1)
+	expireBtcTxs(service, 1)
+	assert.Equal(t, 0, len(service.GetNotify()))
+
+	service = atHeight(BTC_TX_EXPIRY_BLOCKS)
+	expireBtcTxs(service, 1)
+	notifies := service.GetNotify()
+	assert.Equal(t, 2, len(notifies))
+	assert.Equal(t, "btcTxExpired", notifies[0].States.([]interface{})[0])
+	assert.Equal(t, hex.EncodeToString(txHash), notifies[0].States.([]interface{})[3])
+	assert.Equal(t, "makeBtcTx", notifies[1].States.([]interface{})[0])
+
+	// the utxo is spent again by the tx made for the same transfer
+	pending, err = getPendingMultiSigns(service, 1, utxoKey)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(pending.Items))
+	stxos, err := getStxos(service, 1, utxoKey)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(stxos.Utxos))
+	utxos, err := getUtxos(service, 1, utxoKey)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(utxos.Utxos))
+	newTx := wire.NewMsgTx(wire.TxVersion)
+	txb, _ := service.GetCacheDB().Get(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_TX_PREFIX),
+		pending.Items[0].TxHash))
+	assert.NoError(t, newTx.BtcDecode(bytes.NewBuffer(txb), wire.ProtocolVersion, wire.LatestEncoding))
+	assert.Equal(t, 1, len(newTx.TxIn))
+	assert.Equal(t, stxos.Utxos[0].Op.Hash, newTx.TxIn[0].PreviousOutPoint.Hash[:])
+	request, err := getBtcTxRequest(service, pending.Items[0].TxHash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(BTC_TX_EXPIRY_BLOCKS), request.Height)
+	assert.Equal(t, map[string]int64{"mjEoyyCPsLzJ23xMX6Mti13zMyN36kzn57": 6000}, request.Amounts)
+
+	// the tx is kept as it was when the new one can't be made
+	txHash = pending.Items[0].TxHash
+	putStxos(service, 1, utxoKey, &Utxos{Utxos: []*Utxo{}})
+	service = atHeight(2 * BTC_TX_EXPIRY_BLOCKS)
+	expireBtcTxs(service, 2)
+	notifies = service.GetNotify()
+	assert.Equal(t, 1, len(notifies))
+	assert.Equal(t, "btcTxExpiryFailed", notifies[0].States.([]interface{})[0])
+	pending, err = getPendingMultiSigns(service, 1, utxoKey)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(pending.Items))
+	assert.Equal(t, txHash, pending.Items[0].TxHash)
+	request, err = getBtcTxRequest(service, txHash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(BTC_TX_EXPIRY_BLOCKS), request.Height)
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/header_sync/btc"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func depositBlockKey(chainID uint64, txHash []byte) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_DEPOSIT_BLOCK), utils.GetUint64Bytes(chainID), txHash)
+}
+
+// PutDepositBlock records the canonical block at height as the block the deposit tx pending commit was proved in
+func PutDepositBlock(native *native.NativeService, chainID uint64, height uint32, tx []byte) error {
+	chain, err := getUtxoChain(native, chainID)
+	if err != nil {
+		return fmt.Errorf("PutDepositBlock, %v", err)
+	}
+	_, txHash, err := chain.decodeTx(tx)
+	if err != nil {
+		return fmt.Errorf("PutDepositBlock, failed to decode the transaction: %v", err)
+	}
+	hash, err := btc.GetBlockHashByHeight(native, chainID, height)
+	if err != nil {
+		return fmt.Errorf("PutDepositBlock, %v", err)
+	}
+	block := &DepositBlock{Height: height, Hash: hash.CloneBytes(), Tx: tx}
+	sink := common.NewZeroCopySink(nil)
+	block.Serialization(sink)
+	native.GetCacheDB().Put(depositBlockKey(chainID, txHash[:]), cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
+
+// GetDepositBlock returns nil if no block is recorded for the deposit txHash
+func GetDepositBlock(native *native.NativeService, chainID uint64, txHash []byte) (*DepositBlock, error) {
+	store, err := native.GetCacheDB().Get(depositBlockKey(chainID, txHash))
+	if err != nil {
+		return nil, fmt.Errorf("GetDepositBlock, get store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetDepositBlock, deserialize from raw storage item err:%v", err)
+	}
+	block := new(DepositBlock)
+	if err := block.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetDepositBlock, deserialize DepositBlock error: %v", err)
+	}
+	return block, nil
+}
+
+func DeleteDepositBlock(native *native.NativeService, chainID uint64, txHash []byte) {
+	native.GetCacheDB().Delete(depositBlockKey(chainID, txHash))
+}
+
+// IsCanonical returns false if the block has been reorganized out of the chain of the synced headers,
+// including the chain getting shorter than its height
+func (this *DepositBlock) IsCanonical(native *native.NativeService, chainID uint64) bool {
+	hash, err := btc.GetBlockHashByHeight(native, chainID, this.Height)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(hash.CloneBytes(), this.Hash)
+}
+
+// ReverseDeposit takes back a deposit pending commit whose block left the canonical chain: the utxos it
+// credited are removed, its amount is no longer minted on toChainID and its outpoints may be credited again.
+// It fails if any of the utxos of the deposit is already spent.
+func ReverseDeposit(native *native.NativeService, chainID uint64, block *DepositBlock, toChainID uint64) error {
+	chain, err := getUtxoChain(native, chainID)
+	if err != nil {
+		return fmt.Errorf("ReverseDeposit, %v", err)
+	}
+	mtx, txHash, err := chain.decodeTx(block.Tx)
+	if err != nil {
+		return fmt.Errorf("ReverseDeposit, failed to decode the transaction: %v", err)
+	}
+	utxoKey := GetUtxoKey(mtx.TxOut[0].PkScript)
+	utxos, err := getUtxos(native, chainID, utxoKey)
+	if err != nil {
+		return fmt.Errorf("ReverseDeposit, getUtxos err:%v", err)
+	}
+	indexes, amount := getLockOutputs(mtx)
+	kept := make([]*Utxo, 0, len(utxos.Utxos))
+	for _, v := range utxos.Utxos {
+		if !bytes.Equal(v.Op.Hash, txHash[:]) {
+			kept = append(kept, v)
+		}
+	}
+	if len(utxos.Utxos)-len(kept) != len(indexes) {
+		return fmt.Errorf("ReverseDeposit, utxos of deposit %s are already spent", txHash.String())
+	}
+	putUtxos(native, chainID, utxoKey, &Utxos{Utxos: kept})
+	if err := subMinted(native, chainID, utxoKey, toChainID, uint64(amount)); err != nil {
+		return fmt.Errorf("ReverseDeposit, %v", err)
+	}
+	for _, op := range depositOutPoints(mtx, txHash) {
+		native.GetCacheDB().Delete(doneOutPointKey(chainID, op))
+	}
+	DeleteDepositBlock(native, chainID, txHash[:])
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	hscom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseDeposit(t *testing.T) {
+	ns := getNativeFunc(nil, nil)
+	netType := make([]byte, 8)
+	binary.LittleEndian.PutUint64(netType, uint64(utils.TyTestnet3))
+	side := &side_chain_manager.SideChain{Name: "btc", ChainId: 1, BlocksToWait: 1, Router: utils.BTC_ROUTER, CCMCAddress: netType}
+	sink := common.NewZeroCopySink(nil)
+	assert.NoError(t, side.Serialization(sink))
+	ns.GetCacheDB().Put(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(side_chain_manager.SIDE_CHAIN),
+		utils.GetUint64Bytes(1)), states.GenRawStorageItem(sink.Bytes()))
+	putBlockIndex := func(ns *native.NativeService, height uint32, hash chainhash.Hash) {
+		ns.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscom.HEADER_INDEX), utils.GetUint64Bytes(1),
+			utils.GetUint32Bytes(height)), states.GenRawStorageItem(hash[:]))
+	}
+
+	raw, _ := hex.DecodeString(fromBtcRawTx)
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	assert.NoError(t, mtx.BtcDecode(bytes.NewReader(raw), wire.ProtocolVersion, wire.LatestEncoding))
+	txHash := mtx.TxHash()
+	rk := GetUtxoKey(mtx.TxOut[0].PkScript)
+
+	// the block is required to be synced
+	assert.Error(t, PutDepositBlock(ns, 1, 100, raw))
+	putBlockIndex(ns, 100, chainhash.Hash{1})
+	assert.NoError(t, PutDepositBlock(ns, 1, 100, raw))
+	block, err := GetDepositBlock(ns, 1, txHash[:])
+	assert.NoError(t, err)
+	assert.Equal(t, &DepositBlock{Height: 100, Hash: chainhash.Hash{1}.CloneBytes(), Tx: raw}, block)
+	assert.True(t, block.IsCanonical(ns, 1))
+
+	// the deposit is credited, then its block is reorganized out
+	assert.NoError(t, addUtxos(ns, 1, 1, mtx, txHash))
+	_, amount := getLockOutputs(mtx)
+	assert.NoError(t, addMinted(ns, 1, rk, 2, uint64(amount)))
+	putDoneOutPoints(ns, 1, depositOutPoints(mtx, txHash), txHash)
+	putBlockIndex(ns, 100, chainhash.Hash{2})
+	assert.False(t, block.IsCanonical(ns, 1))
+
+	assert.NoError(t, ReverseDeposit(ns, 1, block, 2))
+	utxos, err := getUtxos(ns, 1, rk)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(utxos.Utxos))
+	minted, err := getMinted(ns, 1, rk, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), minted)
+	assert.NoError(t, checkDoneOutPoints(ns, 1, depositOutPoints(mtx, txHash)))
+	block1, err := GetDepositBlock(ns, 1, txHash[:])
+	assert.NoError(t, err)
+	assert.Nil(t, block1)
+
+	// the utxos are gone, the deposit can't be reversed again
+	assert.Error(t, ReverseDeposit(ns, 1, block, 2))
+}
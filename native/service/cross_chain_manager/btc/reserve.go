@@ -0,0 +1,227 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func init() {
+	node_manager.RegisterViewChangeHook("cross_chain_manager/btc/reconcileReserves", node_manager.VIEW_CHANGE_HOUSEKEEPING,
+		reconcileReserves)
+}
+
+func mintedKey(chainID uint64, redeemKey string, toChainID uint64) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_MINTED), utils.GetUint64Bytes(chainID),
+		[]byte(redeemKey), utils.GetUint64Bytes(toChainID))
+}
+
+// getMinted returns the amount minted on toChainID against the coins locked in the redeem
+func getMinted(native *native.NativeService, chainID uint64, redeemKey string, toChainID uint64) (uint64, error) {
+	store, err := native.GetCacheDB().Get(mintedKey(chainID, redeemKey, toChainID))
+	if err != nil {
+		return 0, fmt.Errorf("getMinted, get store error: %v", err)
+	}
+	if store == nil {
+		return 0, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("getMinted, deserialize from raw storage item err:%v", err)
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+// addMinted records a deposit of amount wrapped on toChainID
+func addMinted(native *native.NativeService, chainID uint64, redeemKey string, toChainID, amount uint64) error {
+	minted, err := getMinted(native, chainID, redeemKey, toChainID)
+	if err != nil {
+		return err
+	}
+	native.GetCacheDB().Put(mintedKey(chainID, redeemKey, toChainID), cstates.GenRawStorageItem(utils.GetUint64Bytes(minted+amount)))
+	return nil
+}
+
+// subMinted records a withdrawal of amount burnt on fromChainID, the minted amount stops at
+// zero for the coins deposited before it was tracked
+func subMinted(native *native.NativeService, chainID uint64, redeemKey string, fromChainID, amount uint64) error {
+	minted, err := getMinted(native, chainID, redeemKey, fromChainID)
+	if err != nil {
+		return err
+	}
+	if minted <= amount {
+		native.GetCacheDB().Delete(mintedKey(chainID, redeemKey, fromChainID))
+		return nil
+	}
+	native.GetCacheDB().Put(mintedKey(chainID, redeemKey, fromChainID), cstates.GenRawStorageItem(utils.GetUint64Bytes(minted-amount)))
+	return nil
+}
+
+// reconcileReserves refreshes the reserve report of every redeem with coins minted against it
+// once a view, the reports failing to be made are left as they were.
+func reconcileReserves(native *native.NativeService, view uint32) {
+	type custody struct {
+		chainID   uint64
+		redeemKey string
+	}
+	prefix := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_MINTED))
+	custodies := make([]custody, 0)
+	iter := native.GetCacheDB().NewIterator(prefix)
+	for has := iter.First(); has; has = iter.Next() {
+		rest := iter.Key()[len(prefix):]
+		if len(rest) <= 16 {
+			continue
+		}
+		c := custody{chainID: utils.GetBytesUint64(rest[:8]), redeemKey: string(rest[8 : len(rest)-8])}
+		if n := len(custodies); n == 0 || custodies[n-1] != c {
+			custodies = append(custodies, c)
+		}
+	}
+	iter.Release()
+	for _, c := range custodies {
+		_, _ = reconcileReserve(native, c.chainID, c.redeemKey)
+	}
+}
+
+// reconcileReserve compares the coins of the redeem with the wrapped amounts minted against them
+// and stores the report, an alarm is notified if the reserve doesn't cover the obligations.
+func reconcileReserve(native *native.NativeService, chainID uint64, redeemKey string) (*ReserveReport, error) {
+	report := &ReserveReport{
+		Height: native.GetHeight(),
+		Minted: make([]*MintedAmount, 0),
+	}
+	utxos, err := getUtxos(native, chainID, redeemKey)
+	if err != nil {
+		return nil, fmt.Errorf("reconcileReserve, %v", err)
+	}
+	for _, u := range utxos.Utxos {
+		report.Reserve += u.Value
+	}
+	report.Pending, err = getPendingChange(native, chainID, redeemKey)
+	if err != nil {
+		return nil, fmt.Errorf("reconcileReserve, %v", err)
+	}
+
+	prefix := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_MINTED), utils.GetUint64Bytes(chainID),
+		[]byte(redeemKey))
+	iter := native.GetCacheDB().NewIterator(prefix)
+	for has := iter.First(); has; has = iter.Next() {
+		if len(iter.Key()) != len(prefix)+8 {
+			continue
+		}
+		raw, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			iter.Release()
+			return nil, fmt.Errorf("reconcileReserve, deserialize from raw storage item err:%v", err)
+		}
+		minted := &MintedAmount{
+			ToChainID: utils.GetBytesUint64(iter.Key()[len(prefix):]),
+			Amount:    utils.GetBytesUint64(raw),
+		}
+		report.Obligations += minted.Amount
+		report.Minted = append(report.Minted, minted)
+	}
+	iter.Release()
+
+	sink := common.NewZeroCopySink(nil)
+	report.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_RESERVE_REPORT),
+		utils.GetUint64Bytes(chainID), []byte(redeemKey)), cstates.GenRawStorageItem(sink.Bytes()))
+	if !report.Covered() {
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States: []interface{}{"btcReserveAlarm", chainID, redeemKey, report.Reserve + report.Pending,
+					report.Obligations},
+			})
+	}
+	return report, nil
+}
+
+// getPendingChange sums the change returned to the redeem by the txs waiting for signatures,
+// the utxos they spend are no longer counted in the reserve.
+func getPendingChange(native *native.NativeService, chainID uint64, redeemKey string) (uint64, error) {
+	pending, err := getPendingMultiSigns(native, chainID, redeemKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(pending.Items) == 0 {
+		return 0, nil
+	}
+	redeemScript, err := side_chain_manager.GetBtcRedeemScriptBytes(native, redeemKey, chainID)
+	if err != nil {
+		return 0, fmt.Errorf("getPendingChange, get redeem script error: %v", err)
+	}
+	chain, err := getUtxoChain(native, chainID)
+	if err != nil {
+		return 0, fmt.Errorf("getPendingChange, %v", err)
+	}
+	lockScript, err := chain.getLockScript(redeemScript)
+	if err != nil {
+		return 0, fmt.Errorf("getPendingChange, %v", err)
+	}
+	var change uint64
+	for _, v := range pending.Items {
+		txb, err := native.GetCacheDB().Get(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_TX_PREFIX),
+			v.TxHash))
+		if err != nil {
+			return 0, fmt.Errorf("getPendingChange, get tx error: %v", err)
+		}
+		mtx := wire.NewMsgTx(wire.TxVersion)
+		if err := mtx.BtcDecode(bytes.NewBuffer(txb), wire.ProtocolVersion, wire.LatestEncoding); err != nil {
+			return 0, fmt.Errorf("getPendingChange, failed to decode tx: %v", err)
+		}
+		for _, out := range mtx.TxOut {
+			if bytes.Equal(out.PkScript, lockScript) {
+				change += uint64(out.Value)
+			}
+		}
+	}
+	return change, nil
+}
+
+// GetReserveReport returns the last reserve report of the redeem, nil if there is none
+func GetReserveReport(native *native.NativeService, chainID uint64, redeemKey string) (*ReserveReport, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_RESERVE_REPORT),
+		utils.GetUint64Bytes(chainID), []byte(redeemKey)))
+	if err != nil {
+		return nil, fmt.Errorf("GetReserveReport, get store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetReserveReport, deserialize from raw storage item err:%v", err)
+	}
+	report := new(ReserveReport)
+	if err := report.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetReserveReport, %v", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileReserve(t *testing.T) {
+	ns := getNativeFunc(nil, nil)
+	putUtxos(ns, 1, utxoKey, &Utxos{Utxos: []*Utxo{{Op: &OutPoint{Hash: make([]byte, 32)}, Value: 10000, ScriptPubkey: p2sh}}})
+	assert.NoError(t, addMinted(ns, 1, utxoKey, 2, 8000))
+	assert.NoError(t, addMinted(ns, 1, utxoKey, 3, 7000))
+
+	report, err := reconcileReserve(ns, 1, utxoKey)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10000), report.Reserve)
+	assert.Equal(t, uint64(15000), report.Obligations)
+	assert.Equal(t, []*MintedAmount{{ToChainID: 2, Amount: 8000}, {ToChainID: 3, Amount: 7000}}, report.Minted)
+	assert.False(t, report.Covered())
+	assert.Equal(t, 1, len(ns.GetNotify()))
+	assert.Equal(t, "btcReserveAlarm", ns.GetNotify()[0].States.([]interface{})[0])
+
+	// a withdrawal over the minted amount stops at zero
+	assert.NoError(t, subMinted(ns, 1, utxoKey, 3, 9000))
+	reconcileReserves(ns, 1)
+	stored, err := GetReserveReport(ns, 1, utxoKey)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8000), stored.Obligations)
+	assert.Equal(t, []*MintedAmount{{ToChainID: 2, Amount: 8000}}, stored.Minted)
+	assert.True(t, stored.Covered())
+	assert.Equal(t, 1, len(ns.GetNotify()))
+
+	sink := common.NewZeroCopySink(nil)
+	stored.Serialization(sink)
+	decoded := new(ReserveReport)
+	assert.NoError(t, decoded.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, stored, decoded)
+}
@@ -463,3 +463,216 @@ func (this *BtcFromInfo) Deserialization(source *common.ZeroCopySource) error {
 	this.FromChainID = fromChainID
 	return nil
 }
+
+// BtcTxRequest is the transfer a tx waiting for signatures is made for at Height, the transfer is made
+// again by another tx once it expires
+type BtcTxRequest struct {
+	Height      uint32
+	FromChainID uint64
+	FromTxHash  []byte
+	Amounts     map[string]int64
+}
+
+func (this *BtcTxRequest) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Height)
+	sink.WriteUint64(this.FromChainID)
+	sink.WriteVarBytes(this.FromTxHash)
+	addrs := make([]string, 0, len(this.Amounts))
+	for k := range this.Amounts {
+		addrs = append(addrs, k)
+	}
+	sort.Strings(addrs)
+	sink.WriteVarUint(uint64(len(addrs)))
+	for _, k := range addrs {
+		sink.WriteString(k)
+		sink.WriteInt64(this.Amounts[k])
+	}
+}
+
+func (this *BtcTxRequest) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("BtcTxRequest deserialize height error")
+	}
+	this.FromChainID, eof = source.NextUint64()
+	if eof {
+		return fmt.Errorf("BtcTxRequest deserialize from chain id error")
+	}
+	this.FromTxHash, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BtcTxRequest deserialize from tx hash error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("BtcTxRequest deserialize amounts length error")
+	}
+	amounts := make(map[string]int64)
+	for i := uint64(0); i < n; i++ {
+		addr, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("BtcTxRequest deserialize address of No.%d amount error", i)
+		}
+		amount, eof := source.NextInt64()
+		if eof {
+			return fmt.Errorf("BtcTxRequest deserialize No.%d amount error", i)
+		}
+		amounts[addr] = amount
+	}
+	this.Amounts = amounts
+	return nil
+}
+
+// PendingMultiSign is a transaction waiting for the signatures of the redeem's keepers,
+// Digests are what every keeper signs for each input.
+type PendingMultiSign struct {
+	TxHash  []byte
+	RawTx   []byte
+	Digests [][]byte
+}
+
+type PendingMultiSigns struct {
+	Items []*PendingMultiSign
+}
+
+func (this *PendingMultiSigns) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Items)))
+	for _, v := range this.Items {
+		sink.WriteVarBytes(v.TxHash)
+		sink.WriteVarBytes(v.RawTx)
+		sink.WriteVarUint(uint64(len(v.Digests)))
+		for _, d := range v.Digests {
+			sink.WriteVarBytes(d)
+		}
+	}
+}
+
+func (this *PendingMultiSigns) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("PendingMultiSigns deserialize length error")
+	}
+	items := make([]*PendingMultiSign, 0)
+	for i := uint64(0); i < n; i++ {
+		txHash, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("PendingMultiSigns deserialize tx hash of No.%d item error", i)
+		}
+		rawTx, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("PendingMultiSigns deserialize raw tx of No.%d item error", i)
+		}
+		m, eof := source.NextVarUint()
+		if eof {
+			return fmt.Errorf("PendingMultiSigns deserialize digests length of No.%d item error", i)
+		}
+		digests := make([][]byte, 0)
+		for j := uint64(0); j < m; j++ {
+			d, eof := source.NextVarBytes()
+			if eof {
+				return fmt.Errorf("PendingMultiSigns deserialize No.%d digest of No.%d item error", j, i)
+			}
+			digests = append(digests, d)
+		}
+		items = append(items, &PendingMultiSign{TxHash: txHash, RawTx: rawTx, Digests: digests})
+	}
+	this.Items = items
+	return nil
+}
+
+// MintedAmount is the wrapped amount outstanding on a destination chain
+type MintedAmount struct {
+	ToChainID uint64
+	Amount    uint64
+}
+
+// ReserveReport reconciles the coins held by a redeem with the wrapped amounts minted against
+// them, Reserve is the sum of the utxos and Pending the change of the txs waiting for signatures.
+type ReserveReport struct {
+	Height      uint32
+	Reserve     uint64
+	Pending     uint64
+	Obligations uint64
+	Minted      []*MintedAmount
+}
+
+// Covered tells if the reserve ratio is at least 100%
+func (this *ReserveReport) Covered() bool {
+	return this.Reserve+this.Pending >= this.Obligations
+}
+
+func (this *ReserveReport) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Height)
+	sink.WriteUint64(this.Reserve)
+	sink.WriteUint64(this.Pending)
+	sink.WriteUint64(this.Obligations)
+	sink.WriteVarUint(uint64(len(this.Minted)))
+	for _, v := range this.Minted {
+		sink.WriteUint64(v.ToChainID)
+		sink.WriteUint64(v.Amount)
+	}
+}
+
+func (this *ReserveReport) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if this.Height, eof = source.NextUint32(); eof {
+		return fmt.Errorf("ReserveReport deserialize height error")
+	}
+	if this.Reserve, eof = source.NextUint64(); eof {
+		return fmt.Errorf("ReserveReport deserialize reserve error")
+	}
+	if this.Pending, eof = source.NextUint64(); eof {
+		return fmt.Errorf("ReserveReport deserialize pending error")
+	}
+	if this.Obligations, eof = source.NextUint64(); eof {
+		return fmt.Errorf("ReserveReport deserialize obligations error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ReserveReport deserialize minted length error")
+	}
+	minted := make([]*MintedAmount, 0)
+	for i := uint64(0); i < n; i++ {
+		toChainID, eof := source.NextUint64()
+		if eof {
+			return fmt.Errorf("ReserveReport deserialize to chain id of No.%d minted error", i)
+		}
+		amount, eof := source.NextUint64()
+		if eof {
+			return fmt.Errorf("ReserveReport deserialize amount of No.%d minted error", i)
+		}
+		minted = append(minted, &MintedAmount{ToChainID: toChainID, Amount: amount})
+	}
+	this.Minted = minted
+	return nil
+}
+
+// DepositBlock is the block a deposit pending commit was proved in, with the raw deposit tx
+type DepositBlock struct {
+	Height uint32
+	Hash   []byte
+	Tx     []byte
+}
+
+func (this *DepositBlock) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Height)
+	sink.WriteVarBytes(this.Hash)
+	sink.WriteVarBytes(this.Tx)
+}
+
+func (this *DepositBlock) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("DepositBlock deserialize height error")
+	}
+	this.Hash, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("DepositBlock deserialize hash error")
+	}
+	this.Tx, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("DepositBlock deserialize tx error")
+	}
+	return nil
+}
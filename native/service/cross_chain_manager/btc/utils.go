@@ -23,6 +23,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/big"
 	"sort"
 
 	"github.com/btcsuite/btcd/btcec"
@@ -48,10 +49,18 @@ const (
 	BTC_FROM_TX_PREFIX      = "btcfromtx"
 	UTXOS                   = "utxos"
 	STXOS                   = "stxos"
+	DONE_OUTPOINT           = "doneOutPoint"
+	BTC_MINTED              = "btcMinted"
+	BTC_RESERVE_REPORT      = "btcReserveReport"
 	MULTI_SIGN_INFO         = "multiSignInfo"
+	BTC_PENDING_MULTI_SIGN  = "btcPendingMultiSign"
+	BTC_TX_REQUEST          = "btcTxRequest"
+	BTC_DEPOSIT_BLOCK       = "btcDepositBlock"
 	MAX_FEE_COST_PERCENTS   = 1.0
 	MAX_SELECTING_TRY_LIMIT = 1000000
 	SELECTING_K             = 4.0
+	// blocks a tx waits for the signatures of the keepers before it's abandoned and made again
+	BTC_TX_EXPIRY_BLOCKS = 100000
 )
 
 func getNetParam(service *native.NativeService, chainId uint64) (*chaincfg.Params, error) {
@@ -79,23 +88,32 @@ func getNetParam(service *native.NativeService, chainId uint64) (*chaincfg.Param
 
 func verifyFromBtcTx(native *native.NativeService, proof, tx []byte, fromChainID uint64, height uint32) (*crosscommon.MakeTxParam, error) {
 	// decode tx
-	mtx := wire.NewMsgTx(wire.TxVersion)
-	reader := bytes.NewReader(tx)
-	err := mtx.BtcDecode(reader, wire.ProtocolVersion, wire.LatestEncoding)
+	chain, err := getUtxoChain(native, fromChainID)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyFromBtcProof, %v", err)
+	}
+	mtx, txHash, err := chain.decodeTx(tx)
 	if err != nil {
 		return nil, fmt.Errorf("VerifyFromBtcProof, failed to decode the transaction %s: %s", hex.EncodeToString(tx), err)
 	}
+	if len(mtx.TxOut) < 2 {
+		return nil, fmt.Errorf("VerifyFromBtcProof, not crosschain btc tx, only %d outputs", len(mtx.TxOut))
+	}
+	limit, err := side_chain_manager.GetOpReturnLimit(native, fromChainID)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyFromBtcProof, %v", err)
+	}
+	payload, err := getOpReturnPayload(mtx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyFromBtcProof, not crosschain btc tx, %v", err)
+	}
+	_, amount := getLockOutputs(mtx)
 	// check tx is legal format for btc cross chain transaction
-	err = ifCanResolve(mtx.TxOut[1], mtx.TxOut[0].Value)
+	err = ifCanResolve(payload, amount)
 	if err != nil {
 		return nil, fmt.Errorf("VerifyFromBtcProof, not crosschain btc tx, since failed to resolve parameter: %v", err)
 	}
 
-	// make sure the header with height is already synced, meaning the tx is already confirmed in btc block chain
-	bestHeader, err := btc.GetBestBlockHeader(native, fromChainID)
-	if err != nil {
-		return nil, fmt.Errorf("VerifyFromBtcProof, get best block header error:%s", err)
-	}
 	sideChain, err := side_chain_manager.GetSideChain(native, fromChainID)
 	if err != nil {
 		return nil, fmt.Errorf("VerifyFromBtcProof, side_chain_manager.GetSideChain error: %v", err)
@@ -103,23 +121,41 @@ func verifyFromBtcTx(native *native.NativeService, proof, tx []byte, fromChainID
 	if sideChain == nil {
 		return nil, fmt.Errorf("VerifyFromBtcProof, side chain is not registered")
 	}
-	bestHeight := bestHeader.Height
-	if bestHeight < height || bestHeight-height < uint32(sideChain.BlocksToWait-1) {
-		return nil, fmt.Errorf("verifyFromBtcTx, transaction is not confirmed, current height: %d, input height: %d", bestHeight, height)
-	}
-
-	// verify btc merkle proof
-	header, err := btc.GetHeaderByHeight(native, fromChainID, height)
+	blocksToWait, err := side_chain_manager.GetRequiredConfirmations(native, sideChain, big.NewInt(amount))
 	if err != nil {
-		return nil, fmt.Errorf("VerifyFromBtcProof, get header at height %d to verify btc merkle proof error:%s", height, err)
+		return nil, fmt.Errorf("VerifyFromBtcProof, get required confirmations error:%s", err)
 	}
-	if verified, err := verifyBtcMerkleProof(mtx, header.Header, proof); !verified {
-		return nil, fmt.Errorf("VerifyFromBtcProof, verify merkle proof error:%s", err)
+	if chain.router == utils.ZCASH_ROUTER {
+		if err := verifyZcashMerkleProof(native, fromChainID, txHash, proof, height, blocksToWait); err != nil {
+			return nil, fmt.Errorf("VerifyFromBtcProof, %v", err)
+		}
+	} else {
+		// make sure the header with height is already synced, meaning the tx is already confirmed in btc block chain
+		bestHeader, err := btc.GetBestBlockHeader(native, fromChainID)
+		if err != nil {
+			return nil, fmt.Errorf("VerifyFromBtcProof, get best block header error:%s", err)
+		}
+		bestHeight := bestHeader.Height
+		if bestHeight < height || bestHeight-height < uint32(blocksToWait-1) {
+			return nil, fmt.Errorf("verifyFromBtcTx, transaction is not confirmed, current height: %d, input height: %d", bestHeight, height)
+		}
+		if err := side_chain_manager.CheckProofAge(native, fromChainID, uint64(height), uint64(bestHeight)); err != nil {
+			return nil, fmt.Errorf("VerifyFromBtcProof, %v", err)
+		}
+
+		// verify btc merkle proof
+		header, err := btc.GetHeaderByHeight(native, fromChainID, height)
+		if err != nil {
+			return nil, fmt.Errorf("VerifyFromBtcProof, get header at height %d to verify btc merkle proof error:%s", height, err)
+		}
+		if verified, err := verifyBtcMerkleProof(mtx, header.Header, proof); !verified {
+			return nil, fmt.Errorf("VerifyFromBtcProof, verify merkle proof error:%s", err)
+		}
 	}
 
 	// decode the extra data from tx and construct MakeTxParam
 	var p targetChainParam
-	err = p.resolve(mtx.TxOut[0].Value, mtx.TxOut[1])
+	err = p.resolve(amount, payload)
 	if err != nil {
 		return nil, fmt.Errorf("verifyFromBtcTx, failed to resolve parameter: %v", err)
 	}
@@ -135,7 +171,6 @@ func verifyFromBtcTx(native *native.NativeService, proof, tx []byte, fromChainID
 	if toContractAddress == nil {
 		return nil, fmt.Errorf("verifyFromBtcTx, no contract binding with redeem key %s", rk)
 	}
-	txHash := mtx.TxHash()
 	return &crosscommon.MakeTxParam{
 		TxHash:              txHash[:],
 		CrossChainID:        txHash[:],
@@ -188,14 +223,12 @@ type targetChainParam struct {
 }
 
 // func about OP_RETURN
-func (p *targetChainParam) resolve(amount int64, paramOutput *wire.TxOut) error {
-	script := paramOutput.PkScript
-
-	if script[2] != OP_RETURN_SCRIPT_FLAG {
+func (p *targetChainParam) resolve(amount int64, payload []byte) error {
+	if len(payload) == 0 || payload[0] != OP_RETURN_SCRIPT_FLAG {
 		return errors.New("Wrong flag")
 	}
 	inputArgs := new(Args)
-	err := inputArgs.Deserialization(common.NewZeroCopySource(script[3:]))
+	err := inputArgs.Deserialization(common.NewZeroCopySource(payload[1:]))
 	if err != nil {
 		return fmt.Errorf("inputArgs.Deserialization fail: %v", err)
 	}
@@ -296,30 +329,87 @@ func GetUtxoKey(scriptPk []byte) string {
 	}
 }
 
-func addUtxos(native *native.NativeService, chainID uint64, height uint32, mtx *wire.MsgTx) error {
+func addUtxos(native *native.NativeService, chainID uint64, height uint32, mtx *wire.MsgTx, txHash chainhash.Hash) error {
 	utxoKey := GetUtxoKey(mtx.TxOut[0].PkScript)
 
 	utxos, err := getUtxos(native, chainID, utxoKey)
 	if err != nil {
 		return fmt.Errorf("addUtxos, getUtxos err:%v", err)
 	}
-	txHash := mtx.TxHash()
-	op := &OutPoint{
-		Hash:  txHash[:],
-		Index: 0,
+	indexes, _ := getLockOutputs(mtx)
+	for _, idx := range indexes {
+		op := &OutPoint{
+			Hash:  txHash[:],
+			Index: idx,
+		}
+		newUtxo := &Utxo{
+			Op:           op,
+			AtHeight:     height,
+			Value:        uint64(mtx.TxOut[idx].Value),
+			ScriptPubkey: mtx.TxOut[idx].PkScript,
+		}
+		utxos.Utxos = append(utxos.Utxos, newUtxo)
 	}
-	newUtxo := &Utxo{
-		Op:           op,
-		AtHeight:     height,
-		Value:        uint64(mtx.TxOut[0].Value),
-		ScriptPubkey: mtx.TxOut[0].PkScript,
+	putUtxos(native, chainID, utxoKey, utxos)
+	return nil
+}
+
+// depositOutPoints returns the outpoints identifying a deposit besides its txid, the lock outputs
+// it creates and the previous outputs it spends. Malleating a legacy transaction changes its txid,
+// and with it the lock outputs, but never the outputs it spends.
+func depositOutPoints(mtx *wire.MsgTx, txHash chainhash.Hash) []*OutPoint {
+	indexes, _ := getLockOutputs(mtx)
+	ops := make([]*OutPoint, 0, len(indexes)+len(mtx.TxIn))
+	for _, idx := range indexes {
+		ops = append(ops, &OutPoint{Hash: txHash[:], Index: idx})
+	}
+	for _, in := range mtx.TxIn {
+		// a coinbase input spends nothing and is shared by every coinbase
+		if in.PreviousOutPoint.Index == wire.MaxPrevOutIndex && in.PreviousOutPoint.Hash == (chainhash.Hash{}) {
+			continue
+		}
+		ops = append(ops, &OutPoint{Hash: in.PreviousOutPoint.Hash[:], Index: in.PreviousOutPoint.Index})
 	}
+	return ops
+}
 
-	utxos.Utxos = append(utxos.Utxos, newUtxo)
-	putUtxos(native, chainID, utxoKey, utxos)
+func doneOutPointKey(chainID uint64, op *OutPoint) []byte {
+	sink := common.NewZeroCopySink(nil)
+	op.Serialization(sink)
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(DONE_OUTPOINT), utils.GetUint64Bytes(chainID),
+		sink.Bytes())
+}
+
+// checkDoneOutPoints fails if any of the outpoints is already credited, which is the case for a
+// malleated variant of a processed deposit.
+func checkDoneOutPoints(native *native.NativeService, chainID uint64, ops []*OutPoint) error {
+	for _, op := range ops {
+		store, err := native.GetCacheDB().Get(doneOutPointKey(chainID, op))
+		if err != nil {
+			return fmt.Errorf("checkDoneOutPoints, get outpoint store error: %v", err)
+		}
+		if store == nil {
+			continue
+		}
+		raw, err := cstates.GetValueFromRawStorageItem(store)
+		if err != nil {
+			return fmt.Errorf("checkDoneOutPoints, deserialize from raw storage item err:%v", err)
+		}
+		hash, err := chainhash.NewHash(raw)
+		if err != nil {
+			return fmt.Errorf("checkDoneOutPoints, outpoint %s already credited", op.String())
+		}
+		return fmt.Errorf("checkDoneOutPoints, outpoint %s already credited by tx %s", op.String(), hash.String())
+	}
 	return nil
 }
 
+func putDoneOutPoints(native *native.NativeService, chainID uint64, ops []*OutPoint, txHash chainhash.Hash) {
+	for _, op := range ops {
+		native.GetCacheDB().Put(doneOutPointKey(chainID, op), cstates.GenRawStorageItem(txHash[:]))
+	}
+}
+
 func chooseUtxos(native *native.NativeService, chainID uint64, amount int64, outs []*wire.TxOut, rk []byte, m, n int) ([]*Utxo, int64, int64, error) {
 	utxoKey := hex.EncodeToString(rk)
 	utxos, err := getUtxos(native, chainID, utxoKey)
@@ -444,8 +534,9 @@ func getStxoAmts(service *native.NativeService, chainID uint64, txIns []*wire.Tx
 	return amts, stxos, nil
 }
 
+// p2shSigHash replaces the legacy digest for the p2sh inputs when it's not nil
 func verifySigs(sigs [][]byte, addr string, addrs []btcutil.Address, redeem []byte, tx *wire.MsgTx,
-	pkScripts [][]byte, amts []uint64) error {
+	pkScripts [][]byte, amts []uint64, p2shSigHash sigHashFunc) error {
 	if len(sigs) != len(tx.TxIn) {
 		return fmt.Errorf("not enough sig, only %d sigs but %d required", len(sigs), len(tx.TxIn))
 	}
@@ -469,21 +560,9 @@ func verifySigs(sigs [][]byte, addr string, addrs []btcutil.Address, redeem []by
 		if err != nil {
 			return fmt.Errorf("failed to parse no.%d sig: %v", i, err)
 		}
-		var hash []byte
-		switch c := txscript.GetScriptClass(pkScripts[i]); c {
-		case txscript.MultiSigTy, txscript.ScriptHashTy:
-			hash, err = txscript.CalcSignatureHash(redeem, txscript.SigHashType(sig[len(sig)-1]), tx, i)
-			if err != nil {
-				return fmt.Errorf("failed to calculate sig hash: %v", err)
-			}
-		case txscript.WitnessV0ScriptHashTy:
-			sh := txscript.NewTxSigHashes(tx)
-			hash, err = txscript.CalcWitnessSigHash(redeem, sh, txscript.SigHashType(sig[len(sig)-1]), tx, i, int64(amts[i]))
-			if err != nil {
-				return fmt.Errorf("failed to calculate sig hash: %v", err)
-			}
-		default:
-			return fmt.Errorf("script %s not supported", c)
+		hash, err := calcSigHash(redeem, txscript.SigHashType(sig[len(sig)-1]), tx, pkScripts[i], i, int64(amts[i]), p2shSigHash)
+		if err != nil {
+			return err
 		}
 		if !pSig.Verify(hash, signerAddr.(*btcutil.AddressPubKey).PubKey()) {
 			return fmt.Errorf("verify no.%d sig and not pass", i+1)
@@ -493,6 +572,85 @@ func verifySigs(sigs [][]byte, addr string, addrs []btcutil.Address, redeem []by
 	return nil
 }
 
+// calcSigHash computes the digest signed for input idx of tx which is locked by pkScript
+func calcSigHash(redeem []byte, hashType txscript.SigHashType, tx *wire.MsgTx, pkScript []byte, idx int, amt int64,
+	p2shSigHash sigHashFunc) ([]byte, error) {
+	var (
+		hash []byte
+		err  error
+	)
+	switch c := txscript.GetScriptClass(pkScript); c {
+	case txscript.MultiSigTy, txscript.ScriptHashTy:
+		if p2shSigHash != nil {
+			hash, err = p2shSigHash(redeem, hashType, tx, idx, amt)
+		} else {
+			hash, err = txscript.CalcSignatureHash(redeem, hashType, tx, idx)
+		}
+	case txscript.WitnessV0ScriptHashTy:
+		hash, err = txscript.CalcWitnessSigHash(redeem, txscript.NewTxSigHashes(tx), hashType, tx, idx, amt)
+	default:
+		return nil, fmt.Errorf("script %s not supported", c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate sig hash: %v", err)
+	}
+	return hash, nil
+}
+
+// calcSigDigests returns the digests that every signer of redeem has to sign for the inputs
+// of mtx, whose signature scripts still hold the scripts of the spent outputs.
+func calcSigDigests(chain *utxoChain, redeem []byte, mtx *wire.MsgTx, amts []uint64) ([][]byte, error) {
+	tx := mtx.Copy()
+	pkScripts := make([][]byte, len(tx.TxIn))
+	for i, in := range tx.TxIn {
+		pkScripts[i] = in.SignatureScript
+		in.SignatureScript = nil
+	}
+	digests := make([][]byte, len(tx.TxIn))
+	for i := range tx.TxIn {
+		hash, err := calcSigHash(redeem, chain.hashType(), tx, pkScripts[i], i, int64(amts[i]), chain.sigHash())
+		if err != nil {
+			return nil, fmt.Errorf("calcSigDigests, no.%d input: %v", i, err)
+		}
+		digests[i] = hash
+	}
+	return digests, nil
+}
+
+func putPendingMultiSigns(native *native.NativeService, chainID uint64, redeemKey string, pending *PendingMultiSigns) {
+	chainIDBytes := utils.GetUint64Bytes(chainID)
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_PENDING_MULTI_SIGN), chainIDBytes, []byte(redeemKey))
+	if len(pending.Items) == 0 {
+		native.GetCacheDB().Delete(key)
+		return
+	}
+	sink := common.NewZeroCopySink(nil)
+	pending.Serialization(sink)
+	native.GetCacheDB().Put(key, cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func getPendingMultiSigns(native *native.NativeService, chainID uint64, redeemKey string) (*PendingMultiSigns, error) {
+	chainIDBytes := utils.GetUint64Bytes(chainID)
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_PENDING_MULTI_SIGN), chainIDBytes, []byte(redeemKey))
+	store, err := native.GetCacheDB().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("getPendingMultiSigns, get store error: %v", err)
+	}
+	pending := &PendingMultiSigns{
+		Items: make([]*PendingMultiSign, 0),
+	}
+	if store != nil {
+		pendingBytes, err := cstates.GetValueFromRawStorageItem(store)
+		if err != nil {
+			return nil, fmt.Errorf("getPendingMultiSigns, deserialize from raw storage item err:%v", err)
+		}
+		if err := pending.Deserialization(common.NewZeroCopySource(pendingBytes)); err != nil {
+			return nil, fmt.Errorf("getPendingMultiSigns, deserialize pending multi signs err:%v", err)
+		}
+	}
+	return pending, nil
+}
+
 func putBtcMultiSignInfo(native *native.NativeService, txid []byte, multiSignInfo *MultiSignInfo) error {
 	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(MULTI_SIGN_INFO), txid)
 	sink := common.NewZeroCopySink(nil)
@@ -599,13 +757,12 @@ func getBtcFromInfo(native *native.NativeService, txid []byte) (*BtcFromInfo, er
 	return btcFromInfo, nil
 }
 
-func ifCanResolve(paramOutput *wire.TxOut, value int64) error {
-	script := paramOutput.PkScript
-	if script[2] != OP_RETURN_SCRIPT_FLAG {
+func ifCanResolve(payload []byte, value int64) error {
+	if len(payload) == 0 || payload[0] != OP_RETURN_SCRIPT_FLAG {
 		return errors.New("wrong flag")
 	}
 	args := Args{}
-	err := args.Deserialization(common.NewZeroCopySource(script[3:]))
+	err := args.Deserialization(common.NewZeroCopySource(payload[1:]))
 	if err != nil {
 		return err
 	}
@@ -614,3 +771,45 @@ func ifCanResolve(paramOutput *wire.TxOut, value int64) error {
 	}
 	return nil
 }
+
+// getLockOutputs returns the indexes of the outputs paying to the lock script of a deposit, which is
+// the script of the first output, and the total amount of them. The other outputs are taken as change.
+func getLockOutputs(mtx *wire.MsgTx) ([]uint32, int64) {
+	lockScript := mtx.TxOut[0].PkScript
+	indexes := make([]uint32, 0, 1)
+	var amount int64
+	for i, out := range mtx.TxOut {
+		if bytes.Equal(out.PkScript, lockScript) {
+			indexes = append(indexes, uint32(i))
+			amount += out.Value
+		}
+	}
+	return indexes, amount
+}
+
+// getOpReturnPayload finds the only OP_RETURN output of a deposit starting with OP_RETURN_SCRIPT_FLAG
+// and returns the data it pushes, which can not be longer than limit.
+func getOpReturnPayload(mtx *wire.MsgTx, limit uint64) ([]byte, error) {
+	var payload []byte
+	for i, out := range mtx.TxOut {
+		if len(out.PkScript) == 0 || out.PkScript[0] != txscript.OP_RETURN {
+			continue
+		}
+		pushes, err := txscript.PushedData(out.PkScript)
+		if err != nil || len(pushes) != 1 || len(pushes[0]) == 0 || pushes[0][0] != OP_RETURN_SCRIPT_FLAG {
+			continue
+		}
+		if payload != nil {
+			return nil, fmt.Errorf("more than one OP_RETURN output with flag, the second is No.%d", i)
+		}
+		if uint64(len(pushes[0])) > limit {
+			return nil, fmt.Errorf("OP_RETURN payload of No.%d output is %d bytes, more than limit %d", i,
+				len(pushes[0]), limit)
+		}
+		payload = pushes[0]
+	}
+	if payload == nil {
+		return nil, errors.New("no OP_RETURN output with flag")
+	}
+	return payload, nil
+}
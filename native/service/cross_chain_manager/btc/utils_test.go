@@ -20,9 +20,11 @@ import (
 	"bytes"
 	"encoding/hex"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/polynetwork/poly/common"
 	"sort"
 	"testing"
 )
@@ -110,14 +112,14 @@ func TestVerifySigs(t *testing.T) {
 	mtx := wire.NewMsgTx(wire.TxVersion)
 	mtx.BtcDecode(bytes.NewBuffer(txb), wire.TxVersion, wire.LatestEncoding)
 
-	err := verifySigs(sigs, addrs[0].EncodeAddress(), addrs, rs, mtx, getPkSs("p2sh"), []uint64{})
+	err := verifySigs(sigs, addrs[0].EncodeAddress(), addrs, rs, mtx, getPkSs("p2sh"), []uint64{}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	sig2b, _ := hex.DecodeString(sig2)
 	sigs = [][]byte{sig2b}
-	err = verifySigs(sigs, addrs[0].EncodeAddress(), addrs, rs, mtx, getPkSs("p2sh"), []uint64{})
+	err = verifySigs(sigs, addrs[0].EncodeAddress(), addrs, rs, mtx, getPkSs("p2sh"), []uint64{}, nil)
 	if err == nil {
 		t.Fatal("err should not be nil")
 	}
@@ -129,19 +131,19 @@ func TestVerifySigs(t *testing.T) {
 	mtx = wire.NewMsgTx(wire.TxVersion)
 	mtx.BtcDecode(bytes.NewBuffer(txb), wire.TxVersion, wire.LatestEncoding)
 
-	err = verifySigs(sigs, addrs[0].EncodeAddress(), addrs, rs, mtx, getPkSs("wit"), []uint64{btcutil.SatoshiPerBitcoin})
+	err = verifySigs(sigs, addrs[0].EncodeAddress(), addrs, rs, mtx, getPkSs("wit"), []uint64{btcutil.SatoshiPerBitcoin}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	wsig2b, _ := hex.DecodeString(wsigs[1])
 	sigs = [][]byte{wsig2b}
-	err = verifySigs(sigs, addrs[0].EncodeAddress(), addrs, rs, mtx, getPkSs("wit"), []uint64{btcutil.SatoshiPerBitcoin})
+	err = verifySigs(sigs, addrs[0].EncodeAddress(), addrs, rs, mtx, getPkSs("wit"), []uint64{btcutil.SatoshiPerBitcoin}, nil)
 	if err == nil {
 		t.Fatalf("err should not be nil")
 	}
 
-	err = verifySigs(sigs, addrs[1].EncodeAddress(), addrs, rs, mtx, getPkSs("wit"), []uint64{1000})
+	err = verifySigs(sigs, addrs[1].EncodeAddress(), addrs, rs, mtx, getPkSs("wit"), []uint64{1000}, nil)
 	if err == nil {
 		t.Fatalf("err should not be nil")
 	}
@@ -268,3 +270,95 @@ func TestUtxos_Choose(t *testing.T) {
 		t.Fatal("err should not be nil")
 	}
 }
+
+func TestGetDepositOutputs(t *testing.T) {
+	args := &Args{ToChainID: 2, Fee: 100, Address: bytes.Repeat([]byte{1}, 20)}
+	sink := common.NewZeroCopySink([]byte{OP_RETURN_SCRIPT_FLAG})
+	args.Serialization(sink)
+	payload := sink.Bytes()
+	// longer than 75 bytes so it is pushed by OP_PUSHDATA1
+	long := append(append([]byte{}, payload...), make([]byte, 80-len(payload))...)
+
+	opReturn := func(data []byte) []byte {
+		script, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_RETURN).AddData(data).Script()
+		return script
+	}
+	change, _ := hex.DecodeString("76a91428d2e8cee08857f569e5a1b147c5d5e87339e08188ac")
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	mtx.AddTxOut(wire.NewTxOut(1000, p2sh))
+	mtx.AddTxOut(wire.NewTxOut(500, change))
+	mtx.AddTxOut(wire.NewTxOut(0, opReturn(long)))
+	mtx.AddTxOut(wire.NewTxOut(2000, p2sh))
+
+	indexes, amount := getLockOutputs(mtx)
+	if amount != 3000 || len(indexes) != 2 || indexes[0] != 0 || indexes[1] != 3 {
+		t.Fatalf("wrong lock outputs %v with amount %d", indexes, amount)
+	}
+	data, err := getOpReturnPayload(mtx, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, long) {
+		t.Fatal("wrong payload")
+	}
+	var p targetChainParam
+	if err = p.resolve(amount, data); err != nil {
+		t.Fatal(err)
+	}
+	if p.args.ToChainID != 2 || !bytes.Equal(p.args.Address, args.Address) {
+		t.Fatal("wrong args")
+	}
+	if _, err = getOpReturnPayload(mtx, 79); err == nil {
+		t.Fatal("payload over limit should fail")
+	}
+
+	mtx.AddTxOut(wire.NewTxOut(0, opReturn(payload)))
+	if _, err = getOpReturnPayload(mtx, 80); err == nil {
+		t.Fatal("two OP_RETURN outputs with flag should fail")
+	}
+	mtx.TxOut = mtx.TxOut[:2]
+	if _, err = getOpReturnPayload(mtx, 80); err == nil {
+		t.Fatal("no OP_RETURN output should fail")
+	}
+}
+
+func TestDoneOutPoints(t *testing.T) {
+	raw, _ := hex.DecodeString(fromBtcRawTx)
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	if err := mtx.BtcDecode(bytes.NewReader(raw), wire.ProtocolVersion, wire.LatestEncoding); err != nil {
+		t.Fatal(err)
+	}
+	ns := getNativeFunc(nil, nil)
+	ops := depositOutPoints(mtx, mtx.TxHash())
+	if len(ops) != 2 {
+		t.Fatalf("wrong outpoints %d", len(ops))
+	}
+	if err := checkDoneOutPoints(ns, 1, ops); err != nil {
+		t.Fatal(err)
+	}
+	putDoneOutPoints(ns, 1, ops, mtx.TxHash())
+	if err := checkDoneOutPoints(ns, 1, ops); err == nil {
+		t.Fatal("credited outpoints should fail")
+	}
+
+	// a malleated variant has another txid but spends the same output
+	malleated := mtx.Copy()
+	malleated.TxIn[0].SignatureScript = append([]byte{txscript.OP_0}, malleated.TxIn[0].SignatureScript...)
+	if malleated.TxHash() == mtx.TxHash() {
+		t.Fatal("txid should change")
+	}
+	if err := checkDoneOutPoints(ns, 1, depositOutPoints(malleated, malleated.TxHash())); err == nil {
+		t.Fatal("malleated deposit should fail")
+	}
+	if err := checkDoneOutPoints(ns, 2, ops); err != nil {
+		t.Fatal(err)
+	}
+
+	// coinbase inputs are not tracked
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex), nil, nil))
+	coinbase.AddTxOut(wire.NewTxOut(1000, p2sh))
+	if ops := depositOutPoints(coinbase, coinbase.TxHash()); len(ops) != 1 {
+		t.Fatalf("wrong outpoints %d", len(ops))
+	}
+}
@@ -0,0 +1,364 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	chainhash_bch "github.com/gcash/bchd/chaincfg/chainhash"
+	wire_bch "github.com/gcash/bchd/wire"
+	"github.com/gcash/bchutil/merkleblock"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/header_sync/zcash"
+	"github.com/polynetwork/poly/native/service/precompile"
+)
+
+// Only the transparent pool of zcash is bridged. Transactions are in the sapling
+// format (v4) and signed with the ZIP-243 digest, which commits to the consensus
+// branch id of the current network upgrade. Transactions made by poly never expire.
+const (
+	ZCASH_OVERWINTERED_FLAG          = uint32(1 << 31)
+	ZCASH_SAPLING_TX_VERSION         = uint32(4)
+	ZCASH_SAPLING_VERSION_GROUP_ID   = uint32(0x892F2085)
+	ZCASH_EXPIRY_HEIGHT              = uint32(0)
+	ZCASH_ADDRESS_LENGTH             = 26
+	zcashSigHashMask                 = txscript.SigHashType(0x1f)
+	zcashSigHashPersonalization      = "ZcashSigHash"
+	zcashPrevoutHashPersonalization  = "ZcashPrevoutHash"
+	zcashSequenceHashPersonalization = "ZcashSequencHash"
+	zcashOutputsHashPersonalization  = "ZcashOutputsHash"
+)
+
+type zcashAddrPrefix struct {
+	pubKeyHash [2]byte
+	scriptHash [2]byte
+}
+
+var (
+	zcashMainnetPrefix = zcashAddrPrefix{pubKeyHash: [2]byte{0x1c, 0xb8}, scriptHash: [2]byte{0x1c, 0xbd}}
+	zcashTestnetPrefix = zcashAddrPrefix{pubKeyHash: [2]byte{0x1d, 0x25}, scriptHash: [2]byte{0x1c, 0xba}}
+
+	// the crypto primitive the address check relies on
+	zcashSha256d = precompile.MustHash("cross_chain_manager/zcash", precompile.SHA256D)
+)
+
+func getZcashAddrPrefix(netParam *chaincfg.Params) zcashAddrPrefix {
+	if netParam.Name == chaincfg.MainNetParams.Name {
+		return zcashMainnetPrefix
+	}
+	return zcashTestnetPrefix
+}
+
+// decodeZcashAddress decodes a transparent address, t1/t3 on mainnet and tm/t2 on testnet
+func decodeZcashAddress(addr string, netParam *chaincfg.Params) (btcutil.Address, error) {
+	decoded := base58.Decode(addr)
+	if len(decoded) != ZCASH_ADDRESS_LENGTH {
+		return nil, fmt.Errorf("decodeZcashAddress, wrong length %d of %s", len(decoded), addr)
+	}
+	payload := decoded[:ZCASH_ADDRESS_LENGTH-4]
+	if !bytes.Equal(zcashSha256d(payload)[:4], decoded[ZCASH_ADDRESS_LENGTH-4:]) {
+		return nil, fmt.Errorf("decodeZcashAddress, checksum mismatch for %s", addr)
+	}
+	prefix := getZcashAddrPrefix(netParam)
+	switch {
+	case bytes.Equal(payload[:2], prefix.pubKeyHash[:]):
+		return btcutil.NewAddressPubKeyHash(payload[2:], netParam)
+	case bytes.Equal(payload[:2], prefix.scriptHash[:]):
+		return btcutil.NewAddressScriptHashFromHash(payload[2:], netParam)
+	default:
+		return nil, fmt.Errorf("decodeZcashAddress, %s is not a transparent address for %s", addr, netParam.Name)
+	}
+}
+
+func getZcashTxOuts(amounts map[string]int64, netParam *chaincfg.Params) ([]*wire.TxOut, error) {
+	outs := make([]*wire.TxOut, 0)
+	for encodedAddr, amount := range amounts {
+		addr, err := decodeZcashAddress(encodedAddr, netParam)
+		if err != nil {
+			return nil, fmt.Errorf("getZcashTxOuts, %v", err)
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, fmt.Errorf("getZcashTxOuts, failed to generate pay-to-address script: %v", err)
+		}
+		outs = append(outs, wire.NewTxOut(amount, pkScript))
+	}
+	return outs, nil
+}
+
+// encodeZcashTx serializes the transparent mtx as a sapling transaction without any shielded part
+func encodeZcashTx(mtx *wire.MsgTx) []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint32(ZCASH_SAPLING_TX_VERSION | ZCASH_OVERWINTERED_FLAG)
+	sink.WriteUint32(ZCASH_SAPLING_VERSION_GROUP_ID)
+	sink.WriteVarUint(uint64(len(mtx.TxIn)))
+	for _, in := range mtx.TxIn {
+		writeZcashOutPoint(sink, &in.PreviousOutPoint)
+		sink.WriteVarBytes(in.SignatureScript)
+		sink.WriteUint32(in.Sequence)
+	}
+	sink.WriteVarUint(uint64(len(mtx.TxOut)))
+	for _, out := range mtx.TxOut {
+		writeZcashTxOut(sink, out)
+	}
+	sink.WriteUint32(mtx.LockTime)
+	sink.WriteUint32(ZCASH_EXPIRY_HEIGHT)
+	// valueBalance, vShieldedSpend, vShieldedOutput and vJoinSplit
+	sink.WriteInt64(0)
+	sink.WriteVarUint(0)
+	sink.WriteVarUint(0)
+	sink.WriteVarUint(0)
+	return sink.Bytes()
+}
+
+// decodeZcashTx decodes a sapling transaction, the ones with shielded parts are refused
+func decodeZcashTx(raw []byte) (*wire.MsgTx, error) {
+	source := common.NewZeroCopySource(raw)
+	header, eof := source.NextUint32()
+	if eof {
+		return nil, fmt.Errorf("decodeZcashTx, deserialize header error")
+	}
+	if header != ZCASH_SAPLING_TX_VERSION|ZCASH_OVERWINTERED_FLAG {
+		return nil, fmt.Errorf("decodeZcashTx, only overwintered v4 transaction supported, header is %x", header)
+	}
+	groupID, eof := source.NextUint32()
+	if eof {
+		return nil, fmt.Errorf("decodeZcashTx, deserialize version group id error")
+	}
+	if groupID != ZCASH_SAPLING_VERSION_GROUP_ID {
+		return nil, fmt.Errorf("decodeZcashTx, wrong version group id %x", groupID)
+	}
+
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	inNum, eof := source.NextVarUint()
+	if eof {
+		return nil, fmt.Errorf("decodeZcashTx, deserialize inputs length error")
+	}
+	for i := uint64(0); i < inNum; i++ {
+		hash, eof := source.NextBytes(chainhash.HashSize)
+		if eof {
+			return nil, fmt.Errorf("decodeZcashTx, deserialize No.%d input hash error", i)
+		}
+		index, eof := source.NextUint32()
+		if eof {
+			return nil, fmt.Errorf("decodeZcashTx, deserialize No.%d input index error", i)
+		}
+		script, eof := source.NextVarBytes()
+		if eof {
+			return nil, fmt.Errorf("decodeZcashTx, deserialize No.%d input script error", i)
+		}
+		sequence, eof := source.NextUint32()
+		if eof {
+			return nil, fmt.Errorf("decodeZcashTx, deserialize No.%d input sequence error", i)
+		}
+		in := wire.NewTxIn(&wire.OutPoint{Index: index}, script, nil)
+		copy(in.PreviousOutPoint.Hash[:], hash)
+		in.Sequence = sequence
+		mtx.AddTxIn(in)
+	}
+	outNum, eof := source.NextVarUint()
+	if eof {
+		return nil, fmt.Errorf("decodeZcashTx, deserialize outputs length error")
+	}
+	for i := uint64(0); i < outNum; i++ {
+		value, eof := source.NextInt64()
+		if eof {
+			return nil, fmt.Errorf("decodeZcashTx, deserialize No.%d output value error", i)
+		}
+		pkScript, eof := source.NextVarBytes()
+		if eof {
+			return nil, fmt.Errorf("decodeZcashTx, deserialize No.%d output script error", i)
+		}
+		mtx.AddTxOut(wire.NewTxOut(value, pkScript))
+	}
+	lockTime, eof := source.NextUint32()
+	if eof {
+		return nil, fmt.Errorf("decodeZcashTx, deserialize lock time error")
+	}
+	mtx.LockTime = lockTime
+	if _, eof = source.NextUint32(); eof {
+		return nil, fmt.Errorf("decodeZcashTx, deserialize expiry height error")
+	}
+	valueBalance, eof := source.NextInt64()
+	if eof {
+		return nil, fmt.Errorf("decodeZcashTx, deserialize value balance error")
+	}
+	spends, eof := source.NextVarUint()
+	if eof {
+		return nil, fmt.Errorf("decodeZcashTx, deserialize shielded spends error")
+	}
+	outputs, eof := source.NextVarUint()
+	if eof {
+		return nil, fmt.Errorf("decodeZcashTx, deserialize shielded outputs error")
+	}
+	joinSplits, eof := source.NextVarUint()
+	if eof {
+		return nil, fmt.Errorf("decodeZcashTx, deserialize join splits error")
+	}
+	if valueBalance != 0 || spends != 0 || outputs != 0 || joinSplits != 0 {
+		return nil, fmt.Errorf("decodeZcashTx, transaction with shielded part is not supported")
+	}
+	if source.Len() != 0 {
+		return nil, fmt.Errorf("decodeZcashTx, %d bytes left after the transaction", source.Len())
+	}
+	return mtx, nil
+}
+
+func writeZcashOutPoint(sink *common.ZeroCopySink, op *wire.OutPoint) {
+	sink.WriteBytes(op.Hash[:])
+	sink.WriteUint32(op.Index)
+}
+
+func writeZcashTxOut(sink *common.ZeroCopySink, out *wire.TxOut) {
+	sink.WriteInt64(out.Value)
+	sink.WriteVarBytes(out.PkScript)
+}
+
+// calcZcashSigHash computes the ZIP-243 digest for the transparent input idx of tx
+func calcZcashSigHash(redeem []byte, hashType txscript.SigHashType, tx *wire.MsgTx, idx int, amt int64,
+	branchID uint32) ([]byte, error) {
+	if idx < 0 || idx >= len(tx.TxIn) {
+		return nil, fmt.Errorf("calcZcashSigHash, input index %d out of range", idx)
+	}
+	var hashPrevouts, hashSequence, hashOutputs, zeroHash [32]byte
+	anyoneCanPay := hashType&txscript.SigHashAnyOneCanPay != 0
+	base := hashType & zcashSigHashMask
+
+	if !anyoneCanPay {
+		sink := common.NewZeroCopySink(nil)
+		for _, in := range tx.TxIn {
+			writeZcashOutPoint(sink, &in.PreviousOutPoint)
+		}
+		copy(hashPrevouts[:], blake2b256Personal([]byte(zcashPrevoutHashPersonalization), sink.Bytes()))
+	}
+	if !anyoneCanPay && base != txscript.SigHashSingle && base != txscript.SigHashNone {
+		sink := common.NewZeroCopySink(nil)
+		for _, in := range tx.TxIn {
+			sink.WriteUint32(in.Sequence)
+		}
+		copy(hashSequence[:], blake2b256Personal([]byte(zcashSequenceHashPersonalization), sink.Bytes()))
+	}
+	if base != txscript.SigHashSingle && base != txscript.SigHashNone {
+		sink := common.NewZeroCopySink(nil)
+		for _, out := range tx.TxOut {
+			writeZcashTxOut(sink, out)
+		}
+		copy(hashOutputs[:], blake2b256Personal([]byte(zcashOutputsHashPersonalization), sink.Bytes()))
+	} else if base == txscript.SigHashSingle && idx < len(tx.TxOut) {
+		sink := common.NewZeroCopySink(nil)
+		writeZcashTxOut(sink, tx.TxOut[idx])
+		copy(hashOutputs[:], blake2b256Personal([]byte(zcashOutputsHashPersonalization), sink.Bytes()))
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint32(ZCASH_SAPLING_TX_VERSION | ZCASH_OVERWINTERED_FLAG)
+	sink.WriteUint32(ZCASH_SAPLING_VERSION_GROUP_ID)
+	sink.WriteBytes(hashPrevouts[:])
+	sink.WriteBytes(hashSequence[:])
+	sink.WriteBytes(hashOutputs[:])
+	// hashJoinSplits, hashShieldedSpends and hashShieldedOutputs
+	sink.WriteBytes(zeroHash[:])
+	sink.WriteBytes(zeroHash[:])
+	sink.WriteBytes(zeroHash[:])
+	sink.WriteUint32(tx.LockTime)
+	sink.WriteUint32(ZCASH_EXPIRY_HEIGHT)
+	sink.WriteInt64(0)
+	sink.WriteUint32(uint32(hashType))
+	writeZcashOutPoint(sink, &tx.TxIn[idx].PreviousOutPoint)
+	sink.WriteVarBytes(redeem)
+	sink.WriteInt64(amt)
+	sink.WriteUint32(tx.TxIn[idx].Sequence)
+
+	personal := make([]byte, 16)
+	copy(personal, zcashSigHashPersonalization)
+	binary.LittleEndian.PutUint32(personal[12:], branchID)
+	return blake2b256Personal(personal, sink.Bytes()), nil
+}
+
+// verifyZcashMerkleProof checks the proof from `gettxoutproof` of zcashd, which is the
+// zcash header followed by the partial merkle tree, against the synced header at height.
+func verifyZcashMerkleProof(native *native.NativeService, chainID uint64, txHash chainhash.Hash, proof []byte,
+	height uint32, blocksToWait uint64) error {
+	best, err := zcash.GetBestBlockHeader(native, chainID)
+	if err != nil {
+		return fmt.Errorf("verifyZcashMerkleProof, get best block header error: %v", err)
+	}
+	if best.Height < height || uint64(best.Height-height)+1 < blocksToWait {
+		return fmt.Errorf("verifyZcashMerkleProof, transaction is not confirmed, current height: %d, input height: %d",
+			best.Height, height)
+	}
+	if err := side_chain_manager.CheckProofAge(native, chainID, uint64(height), uint64(best.Height)); err != nil {
+		return fmt.Errorf("verifyZcashMerkleProof, %v", err)
+	}
+	header, err := zcash.GetHeaderByHeight(native, chainID, height)
+	if err != nil {
+		return fmt.Errorf("verifyZcashMerkleProof, %v", err)
+	}
+
+	source := common.NewZeroCopySource(proof)
+	proofHeader := new(zcash.ZcashHeader)
+	if err := proofHeader.Deserialization(source); err != nil {
+		return fmt.Errorf("verifyZcashMerkleProof, deserialize header in proof error: %v", err)
+	}
+	msg := wire_bch.MsgMerkleBlock{}
+	var eof bool
+	msg.Transactions, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("verifyZcashMerkleProof, deserialize transactions number error")
+	}
+	hashNum, eof := source.NextVarUint()
+	if eof || hashNum > source.Len()/chainhash.HashSize {
+		return fmt.Errorf("verifyZcashMerkleProof, deserialize hashes length error")
+	}
+	for i := uint64(0); i < hashNum; i++ {
+		raw, _ := source.NextBytes(chainhash.HashSize)
+		hash := new(chainhash_bch.Hash)
+		copy(hash[:], raw)
+		msg.Hashes = append(msg.Hashes, hash)
+	}
+	msg.Flags, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("verifyZcashMerkleProof, deserialize flags error")
+	}
+
+	merkleBlock := merkleblock.NewMerkleBlockFromMsg(msg)
+	root := merkleBlock.ExtractMatches()
+	if root == nil || merkleBlock.BadTree() || len(merkleBlock.GetMatches()) == 0 {
+		return fmt.Errorf("verifyZcashMerkleProof, bad merkle tree")
+	}
+	if !bytes.Equal(root[:], header.Header.MerkleRoot[:]) {
+		return fmt.Errorf("verifyZcashMerkleProof, merkle root not equal, merkle root should be %s not %s",
+			header.Header.MerkleRoot.String(), root.String())
+	}
+	for _, v := range merkleBlock.GetMatches() {
+		if bytes.Equal(v[:], txHash[:]) {
+			return nil
+		}
+	}
+	return fmt.Errorf("verifyZcashMerkleProof, transaction %s not found in proof", txHash.String())
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlake2b256Personal(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	personal := append([]byte(zcashSigHashPersonalization), 0xbb, 0x09, 0xb8, 0x76)
+	assert.Equal(t, "7a45ddd061e80077023f8ac2436d21fb19b57ce7929df4ea3fff7bceefc15360",
+		hex.EncodeToString(blake2b256Personal(personal, data[:0])))
+	assert.Equal(t, "94bd394474be10b9818824985685496acd0d1d89494545c30d9a6b9338ba3921",
+		hex.EncodeToString(blake2b256Personal(personal, data[:128])))
+	assert.Equal(t, "b8bd169bc056e54c57d9ff8bf1b3d867d5129c9952cd2efc80a1d1d3c35671d8",
+		hex.EncodeToString(blake2b256Personal(personal, data)))
+}
+
+func TestZcashTxCodec(t *testing.T) {
+	txb, _ := hex.DecodeString(unsignedTx)
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	_ = mtx.BtcDecode(bytes.NewBuffer(txb), wire.ProtocolVersion, wire.LatestEncoding)
+
+	raw := encodeZcashTx(mtx)
+	decoded, err := decodeZcashTx(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, mtx.TxIn[0].PreviousOutPoint, decoded.TxIn[0].PreviousOutPoint)
+	assert.Equal(t, mtx.TxOut, decoded.TxOut)
+	assert.Equal(t, raw, encodeZcashTx(decoded))
+
+	chain := &utxoChain{router: utils.ZCASH_ROUTER, netParam: &chaincfg.TestNet3Params}
+	_, txid, err := chain.decodeTx(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, chainhash.DoubleHashH(raw), txid)
+	assert.NotEqual(t, mtx.TxHash(), txid)
+
+	// shielded part is refused
+	shielded := append([]byte{}, raw...)
+	shielded[len(shielded)-3] = 1
+	_, err = decodeZcashTx(shielded)
+	assert.Error(t, err)
+
+	// bitcoin tx is refused
+	_, err = decodeZcashTx(txb)
+	assert.Error(t, err)
+}
+
+func TestDecodeZcashAddress(t *testing.T) {
+	hash, _ := hex.DecodeString("76a04053bda0a88bda5177b86a15c3b29f559873")
+	encode := func(prefix [2]byte) string {
+		payload := append(prefix[:], hash...)
+		return base58.Encode(append(payload, chainhash.DoubleHashB(payload)[:4]...))
+	}
+
+	addr, err := decodeZcashAddress(encode(zcashMainnetPrefix.pubKeyHash), &chaincfg.MainNetParams)
+	assert.NoError(t, err)
+	script, _ := txscript.PayToAddrScript(addr)
+	assert.Equal(t, txscript.PubKeyHashTy, txscript.GetScriptClass(script))
+	assert.Equal(t, hash, addr.ScriptAddress())
+
+	addr, err = decodeZcashAddress(encode(zcashTestnetPrefix.scriptHash), &chaincfg.TestNet3Params)
+	assert.NoError(t, err)
+	script, _ = txscript.PayToAddrScript(addr)
+	assert.Equal(t, txscript.ScriptHashTy, txscript.GetScriptClass(script))
+
+	// wrong network
+	_, err = decodeZcashAddress(encode(zcashTestnetPrefix.pubKeyHash), &chaincfg.MainNetParams)
+	assert.Error(t, err)
+	// wrong checksum
+	wrong := []byte(encode(zcashMainnetPrefix.pubKeyHash))
+	wrong[len(wrong)-1]++
+	_, err = decodeZcashAddress(string(wrong), &chaincfg.MainNetParams)
+	assert.Error(t, err)
+}
+
+func TestCalcZcashSigHash(t *testing.T) {
+	rs, _ := hex.DecodeString(redeem)
+	txb, _ := hex.DecodeString(unsignedTx)
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	_ = mtx.BtcDecode(bytes.NewBuffer(txb), wire.ProtocolVersion, wire.LatestEncoding)
+
+	h1, err := calcZcashSigHash(rs, txscript.SigHashAll, mtx, 0, 1e5, 0x76b809bb)
+	assert.NoError(t, err)
+	h2, err := calcZcashSigHash(rs, txscript.SigHashAll, mtx, 0, 1e5, 0x2bb40e60)
+	assert.NoError(t, err)
+	// the digest commits to the network upgrade
+	assert.NotEqual(t, h1, h2)
+
+	_, err = calcZcashSigHash(rs, txscript.SigHashAll, mtx, 1, 1e5, 0x76b809bb)
+	assert.Error(t, err)
+}
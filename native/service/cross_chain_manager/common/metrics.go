@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package common
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// VERIFY_METRICS_BLOCKS is the number of recent blocks the per block verify metrics are kept for
+const VERIFY_METRICS_BLOCKS = 1000
+
+var (
+	// VerifyLatencyBounds are the upper bounds in microseconds of the verify latency histogram buckets
+	VerifyLatencyBounds = []uint64{1000, 5000, 10000, 50000, 100000, 500000, 1000000}
+	// ProofSizeBounds are the upper bounds in bytes of the proof size histogram buckets
+	ProofSizeBounds = []uint64{256, 1024, 4096, 16384, 65536, 262144}
+)
+
+// Histogram counts the observed values by bucket, Counts[i] counts the values not greater than
+// Bounds[i] and the last count is of the values above all the bounds
+type Histogram struct {
+	Bounds []uint64
+	Counts []uint64
+	Sum    uint64
+	Count  uint64
+}
+
+func newHistogram(bounds []uint64) *Histogram {
+	return &Histogram{Bounds: bounds, Counts: make([]uint64, len(bounds)+1)}
+}
+
+func (this *Histogram) observe(value uint64) {
+	i := sort.Search(len(this.Bounds), func(i int) bool { return value <= this.Bounds[i] })
+	this.Counts[i]++
+	this.Sum += value
+	this.Count++
+}
+
+func (this *Histogram) copy() *Histogram {
+	h := *this
+	h.Counts = append([]uint64{}, this.Counts...)
+	return &h
+}
+
+// HandlerMetrics are the histograms of the proof verifications of a chain handler, Latency is
+// in microseconds and ProofSize in bytes
+type HandlerMetrics struct {
+	Router    uint64
+	Latency   *Histogram
+	ProofSize *Histogram
+}
+
+// BlockMetrics are the totals of the proof verifications of a chain handler in a block
+type BlockMetrics struct {
+	Height    uint32
+	Router    uint64
+	Count     uint64
+	Latency   uint64
+	ProofSize uint64
+}
+
+// verifyMetrics are node local, they are measured by the node executing the blocks and never
+// take part in the state
+var verifyMetrics = struct {
+	sync.Mutex
+	handlers map[uint64]*HandlerMetrics
+	blocks   map[uint32]map[uint64]*BlockMetrics
+}{
+	handlers: make(map[uint64]*HandlerMetrics),
+	blocks:   make(map[uint32]map[uint64]*BlockMetrics),
+}
+
+// ObserveVerify records a proof verification of proofSize bytes taking elapsed by the handler of
+// router in the block of height
+func ObserveVerify(router uint64, height uint32, elapsed time.Duration, proofSize int) {
+	latency := uint64(elapsed / time.Microsecond)
+	verifyMetrics.Lock()
+	defer verifyMetrics.Unlock()
+
+	handler, ok := verifyMetrics.handlers[router]
+	if !ok {
+		handler = &HandlerMetrics{
+			Router:    router,
+			Latency:   newHistogram(VerifyLatencyBounds),
+			ProofSize: newHistogram(ProofSizeBounds),
+		}
+		verifyMetrics.handlers[router] = handler
+	}
+	handler.Latency.observe(latency)
+	handler.ProofSize.observe(uint64(proofSize))
+
+	routers, ok := verifyMetrics.blocks[height]
+	if !ok {
+		routers = make(map[uint64]*BlockMetrics)
+		verifyMetrics.blocks[height] = routers
+		for h := range verifyMetrics.blocks {
+			if h+VERIFY_METRICS_BLOCKS <= height {
+				delete(verifyMetrics.blocks, h)
+			}
+		}
+	}
+	block, ok := routers[router]
+	if !ok {
+		block = &BlockMetrics{Height: height, Router: router}
+		routers[router] = block
+	}
+	block.Count++
+	block.Latency += latency
+	block.ProofSize += uint64(proofSize)
+}
+
+// GetVerifyMetrics returns a copy of the verify metrics, the handlers are sorted by router and the
+// blocks by height and router
+func GetVerifyMetrics() ([]*HandlerMetrics, []*BlockMetrics) {
+	verifyMetrics.Lock()
+	defer verifyMetrics.Unlock()
+
+	handlers := make([]*HandlerMetrics, 0, len(verifyMetrics.handlers))
+	for _, h := range verifyMetrics.handlers {
+		handlers = append(handlers, &HandlerMetrics{Router: h.Router, Latency: h.Latency.copy(), ProofSize: h.ProofSize.copy()})
+	}
+	sort.Slice(handlers, func(i, j int) bool { return handlers[i].Router < handlers[j].Router })
+
+	blocks := make([]*BlockMetrics, 0)
+	for _, routers := range verifyMetrics.blocks {
+		for _, b := range routers {
+			block := *b
+			blocks = append(blocks, &block)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Height != blocks[j].Height {
+			return blocks[i].Height < blocks[j].Height
+		}
+		return blocks[i].Router < blocks[j].Router
+	})
+	return handlers, blocks
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveVerify(t *testing.T) {
+	router := uint64(1000)
+	ObserveVerify(router, 10, 2*time.Millisecond, 100)
+	ObserveVerify(router, 10, 20*time.Millisecond, 2000)
+	ObserveVerify(router, 10+VERIFY_METRICS_BLOCKS, 2*time.Second, 1000000)
+
+	handlers, blocks := GetVerifyMetrics()
+	var handler *HandlerMetrics
+	for _, h := range handlers {
+		if h.Router == router {
+			handler = h
+		}
+	}
+	assert.NotNil(t, handler)
+	assert.Equal(t, uint64(3), handler.Latency.Count)
+	assert.Equal(t, []uint64{0, 1, 0, 1, 0, 0, 0, 1}, handler.Latency.Counts)
+	assert.Equal(t, []uint64{1, 0, 1, 0, 0, 0, 1}, handler.ProofSize.Counts)
+	assert.Equal(t, uint64(1002100), handler.ProofSize.Sum)
+
+	// the block falling out of the window is dropped
+	var routerBlocks []*BlockMetrics
+	for _, b := range blocks {
+		if b.Router == router {
+			routerBlocks = append(routerBlocks, b)
+		}
+	}
+	assert.Equal(t, 1, len(routerBlocks))
+	assert.Equal(t, uint32(10+VERIFY_METRICS_BLOCKS), routerBlocks[0].Height)
+	assert.Equal(t, uint64(1), routerBlocks[0].Count)
+	assert.Equal(t, uint64(2000000), routerBlocks[0].Latency)
+}
@@ -17,6 +17,7 @@
 package common
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/native"
@@ -32,6 +33,78 @@ var (
 	NOTIFY_MAKE_PROOF = "makeProof"
 )
 
+// versions of the EntranceParam and MakeTxParam formats, the legacy one has no version encoded.
+// A version is only written when it's not the legacy one, fields added by later versions follow it.
+const (
+	PAYLOAD_VERSION_LEGACY uint64 = 0
+	// same fields as the legacy one with the version explicitly encoded
+	PAYLOAD_VERSION_1 uint64 = 1
+
+	MAX_PAYLOAD_VERSION = PAYLOAD_VERSION_1
+)
+
+// CheckPayloadVersion fails for the versions this node doesn't understand, so that a relayer
+// ahead of the network can fall back to an older format.
+func CheckPayloadVersion(version uint64) error {
+	if version > MAX_PAYLOAD_VERSION {
+		return fmt.Errorf("payload version %d is not supported, the highest supported is %d", version, MAX_PAYLOAD_VERSION)
+	}
+	return nil
+}
+
+// types of the admin messages poly governance sends to the CCM contract of a destination chain
+const (
+	ADMIN_PAUSE          uint8 = 1
+	ADMIN_UNPAUSE        uint8 = 2
+	ADMIN_UPDATE_KEEPERS uint8 = 3
+	ADMIN_SET_FEE        uint8 = 4
+
+	// the method of the MakeTxParam carrying an AdminMessage, the CCM contract handles it by itself
+	// instead of calling ToContractAddress
+	ADMIN_MESSAGE_METHOD = "polyAdminMessage"
+)
+
+func CheckAdminMessageType(typ uint8) error {
+	if typ < ADMIN_PAUSE || typ > ADMIN_SET_FEE {
+		return fmt.Errorf("admin message type %d is not supported", typ)
+	}
+	return nil
+}
+
+// AdminMessage is the args of the MakeTxParam of an admin message. The sequence is counted per destination
+// chain from 0, a CCM contract only accepts the one following the last accepted so no message is replayed.
+type AdminMessage struct {
+	Type     uint8
+	Sequence uint64
+	Args     []byte // keepers for ADMIN_UPDATE_KEEPERS, fee for ADMIN_SET_FEE, encoded as the destination expects
+}
+
+func (this *AdminMessage) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint8(this.Type)
+	sink.WriteUint64(this.Sequence)
+	sink.WriteVarBytes(this.Args)
+}
+
+func (this *AdminMessage) Deserialization(source *common.ZeroCopySource) error {
+	typ, eof := source.NextUint8()
+	if eof {
+		return fmt.Errorf("AdminMessage deserialize type error")
+	}
+	sequence, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("AdminMessage deserialize sequence error")
+	}
+	args, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("AdminMessage deserialize args error")
+	}
+
+	this.Type = typ
+	this.Sequence = sequence
+	this.Args = args
+	return nil
+}
+
 type ChainHandler interface {
 	MakeDepositProposal(service *native.NativeService) (*MakeTxParam, error)
 }
@@ -61,6 +134,7 @@ type EntranceParam struct {
 	RelayerAddress        []byte `json:"relayerAddress"`
 	Extra                 []byte `json:"extra"`
 	HeaderOrCrossChainMsg []byte `json:"headerOrCrossChainMsg"`
+	Version               uint64 `json:"version"`
 }
 
 func (this *EntranceParam) Serialization(sink *common.ZeroCopySink) {
@@ -70,6 +144,9 @@ func (this *EntranceParam) Serialization(sink *common.ZeroCopySink) {
 	sink.WriteVarBytes(this.RelayerAddress)
 	sink.WriteVarBytes(this.Extra)
 	sink.WriteVarBytes(this.HeaderOrCrossChainMsg)
+	if this.Version != PAYLOAD_VERSION_LEGACY {
+		sink.WriteVarUint(this.Version)
+	}
 }
 
 func (this *EntranceParam) Deserialization(source *common.ZeroCopySource) error {
@@ -104,6 +181,11 @@ func (this *EntranceParam) Deserialization(source *common.ZeroCopySource) error
 	this.RelayerAddress = relayerAddr
 	this.Extra = extra
 	this.HeaderOrCrossChainMsg = headerOrCrossChainMsg
+	// nothing left for the legacy format
+	this.Version = PAYLOAD_VERSION_LEGACY
+	if version, eof := source.NextVarUint(); !eof {
+		this.Version = version
+	}
 	return nil
 }
 
@@ -115,6 +197,7 @@ type MakeTxParam struct {
 	ToContractAddress   []byte
 	Method              string
 	Args                []byte
+	Version             uint64
 }
 
 func (this *MakeTxParam) Serialization(sink *common.ZeroCopySink) {
@@ -125,6 +208,9 @@ func (this *MakeTxParam) Serialization(sink *common.ZeroCopySink) {
 	sink.WriteVarBytes(this.ToContractAddress)
 	sink.WriteVarBytes([]byte(this.Method))
 	sink.WriteVarBytes(this.Args)
+	if this.Version != PAYLOAD_VERSION_LEGACY {
+		sink.WriteVarUint(this.Version)
+	}
 }
 
 func (this *MakeTxParam) Deserialization(source *common.ZeroCopySource) error {
@@ -164,6 +250,11 @@ func (this *MakeTxParam) Deserialization(source *common.ZeroCopySource) error {
 	this.ToContractAddress = toContractAddress
 	this.Method = method
 	this.Args = args
+	// nothing left for the legacy format
+	this.Version = PAYLOAD_VERSION_LEGACY
+	if version, eof := source.NextVarUint(); !eof {
+		this.Version = version
+	}
 	return nil
 }
 
@@ -224,6 +315,39 @@ func (this *MultiSignParam) Deserialization(source *common.ZeroCopySource) error
 	return nil
 }
 
+// instanceLeafMarker starts the cross states made in a bridge instance. Read as the var bytes of the tx hash
+// of a ToMerkleValue it's a length of 2^64-1, exceeding any cross state, so the CCM contracts of the default
+// instance reject the cross states of instances, while the ones of an instance check the instance id after it.
+var instanceLeafMarker = []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+// MakeInstanceLeaf binds the serialized ToMerkleValue to the bridge instance, the cross states of the
+// default instance, nil, are kept as they are
+func MakeInstanceLeaf(instance []byte, value []byte) []byte {
+	if len(instance) == 0 {
+		return value
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteBytes(instanceLeafMarker)
+	sink.WriteVarBytes(instance)
+	sink.WriteBytes(value)
+	return sink.Bytes()
+}
+
+// ParseInstanceLeaf splits the cross state into the bridge instance, nil for the default one, and the
+// serialized ToMerkleValue
+func ParseInstanceLeaf(leaf []byte) ([]byte, []byte, error) {
+	if !bytes.HasPrefix(leaf, instanceLeafMarker) {
+		return nil, leaf, nil
+	}
+	source := common.NewZeroCopySource(leaf[len(instanceLeafMarker):])
+	instance, eof := source.NextVarBytes()
+	if eof || len(instance) == 0 {
+		return nil, nil, fmt.Errorf("ParseInstanceLeaf, deserialize instance error")
+	}
+	value, _ := source.NextBytes(source.Len())
+	return instance, value, nil
+}
+
 type ToMerkleValue struct {
 	TxHash      []byte
 	FromChainID uint64
@@ -49,3 +49,111 @@ func TestVote(t *testing.T) {
 	err := v.Deserialization(common.NewZeroCopySource(sink.Bytes()))
 	assert.NoError(t, err)
 }
+
+func TestEntranceParamVersion(t *testing.T) {
+	param := &EntranceParam{
+		SourceChainID: 2,
+		Height:        100,
+		Proof:         []byte{1, 2},
+		Extra:         []byte{3},
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	legacy := sink.Bytes()
+
+	var p EntranceParam
+	err := p.Deserialization(common.NewZeroCopySource(legacy))
+	assert.NoError(t, err)
+	assert.Equal(t, PAYLOAD_VERSION_LEGACY, p.Version)
+
+	param.Version = PAYLOAD_VERSION_1
+	sink = common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	assert.Equal(t, legacy, sink.Bytes()[:len(legacy)])
+
+	err = p.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, PAYLOAD_VERSION_1, p.Version)
+}
+
+func TestMakeTxParamVersion(t *testing.T) {
+	param := &MakeTxParam{
+		TxHash:              []byte{1},
+		CrossChainID:        []byte{2},
+		FromContractAddress: []byte{3},
+		ToChainID:           4,
+		ToContractAddress:   []byte{5},
+		Method:              "unlock",
+		Args:                []byte{6},
+	}
+	for _, version := range []uint64{PAYLOAD_VERSION_LEGACY, PAYLOAD_VERSION_1} {
+		param.Version = version
+		sink := common.NewZeroCopySink(nil)
+		param.Serialization(sink)
+
+		p := new(MakeTxParam)
+		err := p.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+		assert.NoError(t, err)
+		assert.Equal(t, param, p)
+	}
+
+	assert.NoError(t, CheckPayloadVersion(MAX_PAYLOAD_VERSION))
+	assert.Error(t, CheckPayloadVersion(MAX_PAYLOAD_VERSION+1))
+}
+
+func TestAdminMessage(t *testing.T) {
+	msg := &AdminMessage{
+		Type:     ADMIN_UPDATE_KEEPERS,
+		Sequence: 3,
+		Args:     []byte{1, 2, 3},
+	}
+	sink := common.NewZeroCopySink(nil)
+	msg.Serialization(sink)
+
+	msg1 := new(AdminMessage)
+	err := msg1.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, msg, msg1)
+
+	assert.NoError(t, CheckAdminMessageType(ADMIN_PAUSE))
+	assert.Error(t, CheckAdminMessageType(0))
+	assert.Error(t, CheckAdminMessageType(ADMIN_SET_FEE+1))
+}
+
+func TestInstanceLeaf(t *testing.T) {
+	value := &ToMerkleValue{
+		TxHash:      make([]byte, 32),
+		FromChainID: 1,
+		MakeTxParam: &MakeTxParam{
+			TxHash:              []byte{1},
+			CrossChainID:        []byte{2},
+			FromContractAddress: []byte{3},
+			ToChainID:           4,
+			ToContractAddress:   []byte{5},
+			Method:              "unlock",
+			Args:                []byte{6},
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	value.Serialization(sink)
+
+	// the cross states of the default instance are kept as they are
+	assert.Equal(t, sink.Bytes(), MakeInstanceLeaf(nil, sink.Bytes()))
+	instance, raw, err := ParseInstanceLeaf(sink.Bytes())
+	assert.NoError(t, err)
+	assert.Nil(t, instance)
+	assert.Equal(t, sink.Bytes(), raw)
+
+	leaf := MakeInstanceLeaf([]byte("canary"), sink.Bytes())
+	instance, raw, err = ParseInstanceLeaf(leaf)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("canary"), instance)
+	value1 := new(ToMerkleValue)
+	assert.NoError(t, value1.Deserialization(common.NewZeroCopySource(raw)))
+	assert.Equal(t, value, value1)
+
+	// taken for a ToMerkleValue as the CCM contracts of the default instance do, the leaf is rejected
+	assert.Error(t, new(ToMerkleValue).Deserialization(common.NewZeroCopySource(leaf)))
+	_, _, err = ParseInstanceLeaf(leaf[:len(instanceLeafMarker)+1])
+	assert.Error(t, err)
+}
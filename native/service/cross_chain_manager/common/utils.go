@@ -17,9 +17,12 @@
 package common
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 
+	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/native"
@@ -27,6 +30,9 @@ import (
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
+// ErrTxDone is wrapped by the error of CheckDoneTx for a cross chain tx imported already
+var ErrTxDone = errors.New("tx already done")
+
 func Replace0x(s string) string {
 	return strings.Replace(strings.ToLower(s), "0x", "", 1)
 }
@@ -39,9 +45,14 @@ func NotifyMakeProof(native *native.NativeService, fromChainID, toChainID uint64
 		&event.NotifyEventInfo{
 			ContractAddress: utils.CrossChainManagerContractAddress,
 			States:          []interface{}{NOTIFY_MAKE_PROOF, fromChainID, toChainID, txHash, native.GetHeight(), key},
+			FromChainID:     event.ChainID(fromChainID),
+			ToChainID:       event.ChainID(toChainID),
 		})
 }
 
+// PutDoneTx marks the event identified by crossChainID on the source chain as
+// done. The key is the identity of the event, not the proof of it, so that
+// another proof of the same event is rejected as a replay by CheckDoneTx.
 func PutDoneTx(native *native.NativeService, crossChainID []byte, chainID uint64) error {
 	contract := utils.CrossChainManagerContractAddress
 	chainIDBytes := utils.GetUint64Bytes(chainID)
@@ -50,6 +61,14 @@ func PutDoneTx(native *native.NativeService, crossChainID []byte, chainID uint64
 	return nil
 }
 
+// DeleteDoneTx unmarks the event identified by crossChainID, e.g. when the block proving it is reorganized
+// out of the source chain before it's committed, so that it can be proved again
+func DeleteDoneTx(native *native.NativeService, crossChainID []byte, chainID uint64) {
+	contract := utils.CrossChainManagerContractAddress
+	chainIDBytes := utils.GetUint64Bytes(chainID)
+	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(DONE_TX), chainIDBytes, crossChainID))
+}
+
 func CheckDoneTx(native *native.NativeService, crossChainID []byte, chainID uint64) error {
 	contract := utils.CrossChainManagerContractAddress
 	chainIDBytes := utils.GetUint64Bytes(chainID)
@@ -58,7 +77,79 @@ func CheckDoneTx(native *native.NativeService, crossChainID []byte, chainID uint
 		return fmt.Errorf("checkDoneTx, native.GetCacheDB().Get error: %v", err)
 	}
 	if value != nil {
-		return fmt.Errorf("checkDoneTx, tx already done")
+		return fmt.Errorf("checkDoneTx, %w", ErrTxDone)
 	}
 	return nil
 }
+
+// GetTransferAmount returns the amount of the transfer in extra which is the MakeTxParam
+// made by lock proxy. Nil is returned if the amount can't be resolved.
+func GetTransferAmount(extra []byte) *big.Int {
+	txParam := new(MakeTxParam)
+	if err := txParam.Deserialization(common.NewZeroCopySource(extra)); err != nil {
+		return nil
+	}
+	_, amount := DecodeTransferArgs(txParam.Args)
+	return amount
+}
+
+// DecodeTransferArgs returns the asset and the amount of the args made by lock proxy, which are
+// the asset, the receiver and the amount of 32 bytes in little endian. Nil is returned if the
+// args can't be resolved.
+func DecodeTransferArgs(args []byte) ([]byte, *big.Int) {
+	asset, _, amount := DecodeTransfer(args)
+	return asset, amount
+}
+
+// DecodeTransfer returns the asset, the receiver and the amount of the args made by lock proxy,
+// nil is returned if the args can't be resolved.
+func DecodeTransfer(args []byte) ([]byte, []byte, *big.Int) {
+	source := common.NewZeroCopySource(args)
+	asset, eof := source.NextVarBytes()
+	if eof {
+		return nil, nil, nil
+	}
+	receiver, eof := source.NextVarBytes()
+	if eof {
+		return nil, nil, nil
+	}
+	raw, eof := source.NextBytes(32)
+	if eof {
+		return nil, nil, nil
+	}
+	be := make([]byte, len(raw))
+	for i, b := range raw {
+		be[len(raw)-1-i] = b
+	}
+	return asset, receiver, new(big.Int).SetBytes(be)
+}
+
+// SetTransferAmount returns a copy of the args made by lock proxy with the amount replaced, the
+// rest of the args is kept. Nil is returned if the args can't be resolved or amount doesn't fit.
+func SetTransferAmount(args []byte, amount *big.Int) []byte {
+	source := common.NewZeroCopySource(args)
+	if _, eof := source.NextVarBytes(); eof {
+		return nil
+	}
+	if _, eof := source.NextVarBytes(); eof {
+		return nil
+	}
+	offset := source.Pos()
+	if _, eof := source.NextBytes(32); eof {
+		return nil
+	}
+	be := amount.Bytes()
+	if amount.Sign() < 0 || len(be) > 32 {
+		return nil
+	}
+	replaced := make([]byte, len(args))
+	copy(replaced, args)
+	raw := replaced[offset : offset+32]
+	for i := range raw {
+		raw[i] = 0
+	}
+	for i, b := range be {
+		raw[len(be)-1-i] = b
+	}
+	return replaced
+}
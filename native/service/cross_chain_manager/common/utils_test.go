@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package common
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTransferAmount(t *testing.T) {
+	amount := make([]byte, 32)
+	amount[0], amount[1] = 0x10, 0x27
+	args := common.NewZeroCopySink(nil)
+	args.WriteVarBytes([]byte{1, 2, 3})
+	args.WriteVarBytes([]byte{4, 5, 6})
+	args.WriteBytes(amount)
+	param := &MakeTxParam{
+		TxHash:       []byte{1},
+		CrossChainID: []byte{1},
+		ToChainID:    2,
+		Method:       "unlock",
+		Args:         args.Bytes(),
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	assert.Equal(t, big.NewInt(10000), GetTransferAmount(sink.Bytes()))
+	asset, value := DecodeTransferArgs(args.Bytes())
+	assert.Equal(t, []byte{1, 2, 3}, asset)
+	assert.Equal(t, big.NewInt(10000), value)
+
+	param.Args = []byte{1, 2}
+	sink.Reset()
+	param.Serialization(sink)
+	assert.Nil(t, GetTransferAmount(sink.Bytes()))
+}
+
+func TestSetTransferAmount(t *testing.T) {
+	amount := make([]byte, 32)
+	amount[0], amount[1] = 0x10, 0x27
+	args := common.NewZeroCopySink(nil)
+	args.WriteVarBytes([]byte{1, 2, 3})
+	args.WriteVarBytes([]byte{4, 5, 6})
+	args.WriteBytes(amount)
+	args.WriteVarBytes([]byte{7})
+
+	replaced := SetTransferAmount(args.Bytes(), big.NewInt(9970))
+	asset, receiver, value := DecodeTransfer(replaced)
+	assert.Equal(t, []byte{1, 2, 3}, asset)
+	assert.Equal(t, []byte{4, 5, 6}, receiver)
+	assert.Equal(t, big.NewInt(9970), value)
+	assert.Equal(t, args.Bytes()[len(args.Bytes())-2:], replaced[len(replaced)-2:])
+	_, _, value = DecodeTransfer(args.Bytes())
+	assert.Equal(t, big.NewInt(10000), value)
+
+	assert.Nil(t, SetTransferAmount([]byte{1, 2}, big.NewInt(1)))
+	assert.Nil(t, SetTransferAmount(args.Bytes(), new(big.Int).Lsh(big.NewInt(1), 256)))
+}
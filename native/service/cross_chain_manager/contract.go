@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// ContractCrossChain is the system call of the application contracts on poly to make a cross chain tx,
+// a vm layer pushes the context of the application contract before calling it by NativeCall. The calling
+// contract is the witness and is recorded as the source, it can't be called by a transaction directly.
+// Only the contracts allowed by governance may make cross chain txs to a chain.
+func ContractCrossChain(native *native.NativeService) ([]byte, error) {
+	params := new(ContractCrossChainParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ContractCrossChain, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	caller := native.CallingContext()
+	if caller == common.ADDRESS_EMPTY || !native.CheckWitness(caller) {
+		return utils.BYTE_FALSE, fmt.Errorf("ContractCrossChain, must be called by a contract")
+	}
+	allowed, err := IsContractAllowed(native, caller, params.ToChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ContractCrossChain, %v", err)
+	}
+	if !allowed {
+		return utils.BYTE_FALSE, fmt.Errorf("ContractCrossChain, contract %s is not allowed to chain %d",
+			caller.ToHexString(), params.ToChainID)
+	}
+
+	blacked, err := CheckIfChainBlacked(native, params.ToChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ContractCrossChain, CheckIfChainBlacked error: %v", err)
+	}
+	if blacked {
+		return utils.BYTE_FALSE, fmt.Errorf("ContractCrossChain, target chain %d is blacked", params.ToChainID)
+	}
+	sideChain, err := side_chain_manager.GetSideChain(native, params.ToChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ContractCrossChain, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ContractCrossChain, side chain %d is not registered", params.ToChainID)
+	}
+	if err := checkNotQuitting(native, params.ToChainID); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ContractCrossChain, %v", err)
+	}
+
+	seq, err := GetContractSequence(native, caller)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ContractCrossChain, %v", err)
+	}
+	crossChainID := sha256.Sum256(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(CONTRACT_SEQUENCE),
+		caller[:], utils.GetUint64Bytes(seq)))
+	txHash := native.GetTx().Hash()
+	txParam := &scom.MakeTxParam{
+		TxHash:              txHash.ToArray(),
+		CrossChainID:        crossChainID[:],
+		FromContractAddress: caller[:],
+		ToChainID:           params.ToChainID,
+		ToContractAddress:   params.ToContractAddress,
+		Method:              params.Method,
+		Args:                params.Args,
+	}
+	if err := MakeTransaction(native, txParam, native.GetChainID()); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ContractCrossChain, %v", err)
+	}
+	native.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(CONTRACT_SEQUENCE), caller[:]),
+		cstates.GenRawStorageItem(utils.GetUint64Bytes(seq+1)))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{"contractCrossChain", caller.ToHexString(), params.ToChainID,
+				hex.EncodeToString(params.ToContractAddress), params.Method, seq},
+			FromChainID: event.ChainID(native.GetChainID()),
+			ToChainID:   event.ChainID(params.ToChainID),
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// GetContractSequence returns the number of the cross chain txs made by the application contract, which
+// is the sequence of the next one.
+func GetContractSequence(native *native.NativeService, contract common.Address) (uint64, error) {
+	raw, err := getCounter(native, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(CONTRACT_SEQUENCE), contract[:]))
+	if err != nil {
+		return 0, fmt.Errorf("GetContractSequence, %v", err)
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+// AllowContract allows an application contract on poly to make cross chain txs to a chain or revokes it once the
+// consensus peers approve it, so that a buggy contract only reaches the chains it's allowed to.
+func AllowContract(native *native.NativeService) ([]byte, error) {
+	params := new(AllowContractParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("AllowContract, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("AllowContract, checkWitness error: %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(params.Contract[:])
+	sink.WriteVarUint(params.ChainID)
+	sink.WriteBool(params.Allowed)
+	ok, err := node_manager.CheckConsensusSigns(native, ALLOW_CONTRACT, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("AllowContract, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if params.Allowed {
+		native.GetCacheDB().Put(allowedContractKey(params.Contract, params.ChainID), cstates.GenRawStorageItem([]byte{1}))
+	} else {
+		native.GetCacheDB().Delete(allowedContractKey(params.Contract, params.ChainID))
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"allowContract", params.Contract.ToHexString(), params.ChainID, params.Allowed},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+func allowedContractKey(contract common.Address, chainID uint64) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ALLOWED_CONTRACT), contract[:], utils.GetUint64Bytes(chainID))
+}
+
+// IsContractAllowed tells whether the application contract may make cross chain txs to chainID
+func IsContractAllowed(native *native.NativeService, contract common.Address, chainID uint64) (bool, error) {
+	raw, err := getCounter(native, allowedContractKey(contract, chainID))
+	if err != nil {
+		return false, fmt.Errorf("IsContractAllowed, %v", err)
+	}
+	return raw != nil, nil
+}
+
+// GetContractAllowedQuery returns whether the contract is allowed to the chain, to be called by preExec
+func GetContractAllowedQuery(native *native.NativeService) ([]byte, error) {
+	params := new(ContractChainParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetContractAllowedQuery, contract params deserialize error: %v", err)
+	}
+	allowed, err := IsContractAllowed(native, params.Contract, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteBool(allowed)
+	return sink.Bytes(), nil
+}
@@ -61,7 +61,8 @@ func (this *CosmosHandler) MakeDepositProposal(service *native.NativeService) (*
 	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
 		return nil, fmt.Errorf("Cosmos MakeDepositProposal, contract params deserialize error: %s", err)
 	}
-	info, err := cosmos.GetEpochSwitchInfo(service, params.SourceChainID)
+	// the proof is verified by the validators of its height, which may have switched since
+	info, err := cosmos.GetEpochSwitchInfoByHeight(service, params.SourceChainID, int64(params.Height))
 	if err != nil {
 		return nil, fmt.Errorf("Cosmos MakeDepositProposal, failed to get epoch switching height: %v", err)
 	}
@@ -85,14 +86,21 @@ func (this *CosmosHandler) MakeDepositProposal(service *native.NativeService) (*
 	if err = cosmos.VerifyCosmosHeader(&myHeader, info); err != nil {
 		return nil, fmt.Errorf("Cosmos MakeDepositProposal, failed to verify cosmos header: %v", err)
 	}
+	current, err := cosmos.GetEpochSwitchInfo(service, params.SourceChainID)
+	if err != nil {
+		return nil, fmt.Errorf("Cosmos MakeDepositProposal, failed to get epoch switching height: %v", err)
+	}
 	if !bytes.Equal(myHeader.Header.ValidatorsHash, myHeader.Header.NextValidatorsHash) &&
-		myHeader.Header.Height > info.Height {
-		cosmos.PutEpochSwitchInfo(service, params.SourceChainID, &cosmos.CosmosEpochSwitchInfo{
+		myHeader.Header.Height > current.Height {
+		err = cosmos.PutEpochSwitchInfo(service, params.SourceChainID, &cosmos.CosmosEpochSwitchInfo{
 			Height:             myHeader.Header.Height,
 			BlockHash:          myHeader.Header.Hash(),
 			NextValidatorsHash: myHeader.Header.NextValidatorsHash,
 			ChainID:            myHeader.Header.ChainID,
 		})
+		if err != nil {
+			return nil, fmt.Errorf("Cosmos MakeDepositProposal, %v", err)
+		}
 	}
 
 	var proofValue CosmosProofValue
@@ -123,7 +131,7 @@ func (this *CosmosHandler) MakeDepositProposal(service *native.NativeService) (*
 		return nil, fmt.Errorf("Cosmos MakeDepositProposal, deserialize merkleValue error:%s", err)
 	}
 	if err := scom.CheckDoneTx(service, txParam.CrossChainID, params.SourceChainID); err != nil {
-		return nil, fmt.Errorf("Cosmos MakeDepositProposal, check done transaction error:%s", err)
+		return nil, fmt.Errorf("Cosmos MakeDepositProposal, check done transaction error:%w", err)
 	}
 	if err := scom.PutDoneTx(service, txParam.CrossChainID, params.SourceChainID); err != nil {
 		return nil, fmt.Errorf("Cosmos MakeDepositProposal, PutDoneTx error:%s", err)
@@ -0,0 +1,322 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	hsbsc "github.com/polynetwork/poly/native/service/header_sync/bsc"
+	hsbtc "github.com/polynetwork/poly/native/service/header_sync/btc"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	hscosmos "github.com/polynetwork/poly/native/service/header_sync/cosmos"
+	hseth "github.com/polynetwork/poly/native/service/header_sync/eth"
+	hsheco "github.com/polynetwork/poly/native/service/header_sync/heco"
+	hsmsc "github.com/polynetwork/poly/native/service/header_sync/msc"
+	hsokex "github.com/polynetwork/poly/native/service/header_sync/okex"
+	hsont "github.com/polynetwork/poly/native/service/header_sync/ont"
+	hsquorum "github.com/polynetwork/poly/native/service/header_sync/quorum"
+	hszcash "github.com/polynetwork/poly/native/service/header_sync/zcash"
+	hszilliqa "github.com/polynetwork/poly/native/service/header_sync/zilliqa"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+//status of a chain check
+const (
+	CHECK_PASS uint8 = iota
+	CHECK_FAIL
+	CHECK_SKIP
+)
+
+func passCheck(name, format string, args ...interface{}) *ChainCheck {
+	return &ChainCheck{Name: name, Status: CHECK_PASS, Message: fmt.Sprintf(format, args...)}
+}
+
+func failCheck(name, format string, args ...interface{}) *ChainCheck {
+	return &ChainCheck{Name: name, Status: CHECK_FAIL, Message: fmt.Sprintf(format, args...)}
+}
+
+func skipCheck(name, format string, args ...interface{}) *ChainCheck {
+	return &ChainCheck{Name: name, Status: CHECK_SKIP, Message: fmt.Sprintf(format, args...)}
+}
+
+// CheckChainQuery checks the configuration of a registered side chain end to end for the operators
+// onboarding it, to be called by preExec. The checks of a chain not registered are left out.
+func CheckChainQuery(native *native.NativeService) ([]byte, error) {
+	params := new(CheckChainParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CheckChainQuery, contract params deserialize error: %v", err)
+	}
+	sideChain, err := side_chain_manager.GetSideChain(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CheckChainQuery, side_chain_manager.GetSideChain error: %v", err)
+	}
+	report := &ChainReport{
+		ChainID: params.ChainID,
+		Height:  native.GetHeight(),
+		Checks:  []*ChainCheck{checkRegistration(native, params.ChainID, sideChain)},
+	}
+	if sideChain != nil {
+		height, check := checkHeaderSync(native, sideChain, params.MaxLag)
+		report.Checks = append(report.Checks,
+			check,
+			checkValidators(native, sideChain, height),
+			checkAssetBinds(native, params.ChainID),
+			checkFeeRate(native, params.ChainID),
+			checkProofSpec(native, sideChain, height),
+		)
+	}
+	sink := common.NewZeroCopySink(nil)
+	report.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+func checkRegistration(native *native.NativeService, chainID uint64, sideChain *side_chain_manager.SideChain) *ChainCheck {
+	const name = "registration"
+	if sideChain == nil {
+		return failCheck(name, "chain %d is not registered, register it by registerSideChain and have it approved", chainID)
+	}
+	blacked, err := CheckIfChainBlacked(native, chainID)
+	if err != nil {
+		return failCheck(name, "%v", err)
+	}
+	if blacked {
+		return failCheck(name, "chain %d is blacked, have it whited by WhiteChain", chainID)
+	}
+	if err := checkNotQuitting(native, chainID); err != nil {
+		return failCheck(name, "%v", err)
+	}
+	return passCheck(name, "%s on router %d, %d blocks to wait", sideChain.Name, sideChain.Router, sideChain.BlocksToWait)
+}
+
+// currentHeaderHeight returns the height of the current header synced of the chain, false if the headers of
+// its router are not tracked by height
+func currentHeaderHeight(native *native.NativeService, sideChain *side_chain_manager.SideChain) (uint64, bool, error) {
+	chainID := sideChain.ChainId
+	switch sideChain.Router {
+	case utils.ETH_ROUTER:
+		height, err := hseth.GetCurrentHeaderHeight(native, chainID)
+		return height, true, err
+	case utils.BSC_ROUTER:
+		height, err := hsbsc.GetCanonicalHeight(native, chainID)
+		return height, true, err
+	case utils.HECO_ROUTER:
+		height, err := hsheco.GetCanonicalHeight(native, chainID)
+		return height, true, err
+	case utils.MSC_ROUTER:
+		height, err := hsmsc.GetCanonicalHeight(native, chainID)
+		return height, true, err
+	case utils.ZILLIQA_ROUTER:
+		height, err := hszilliqa.GetCurrentTxHeaderHeight(native, chainID)
+		return height, true, err
+	case utils.BTC_ROUTER, utils.BCH_ROUTER:
+		header, err := hsbtc.GetBestBlockHeader(native, chainID)
+		if err != nil {
+			return 0, true, err
+		}
+		return uint64(header.Height), true, nil
+	case utils.ZCASH_ROUTER:
+		header, err := hszcash.GetBestBlockHeader(native, chainID)
+		if err != nil {
+			return 0, true, err
+		}
+		return uint64(header.Height), true, nil
+	case utils.ONT_ROUTER:
+		raw, err := getCounter(native, utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.CURRENT_HEADER_HEIGHT),
+			utils.GetUint64Bytes(chainID)))
+		if err != nil {
+			return 0, true, err
+		}
+		if raw == nil {
+			return 0, true, fmt.Errorf("no header synced")
+		}
+		return uint64(utils.GetBytesUint32(raw)), true, nil
+	default:
+		return 0, false, nil
+	}
+}
+
+// checkHeaderSync checks the chain has headers synced, and synced within maxLag poly blocks if it is set
+func checkHeaderSync(native *native.NativeService, sideChain *side_chain_manager.SideChain, maxLag uint32) (uint64, *ChainCheck) {
+	const name = "header sync"
+	height, ok, err := currentHeaderHeight(native, sideChain)
+	if !ok {
+		return 0, skipCheck(name, "headers of router %d are not synced by height", sideChain.Router)
+	}
+	if err != nil {
+		return 0, failCheck(name, "%v, sync the genesis header by syncGenesisHeader and start the header relayer", err)
+	}
+	commitment, err := hscommon.GetHeaderCommitment(native, sideChain.ChainId, height)
+	if err != nil {
+		return height, failCheck(name, "%v", err)
+	}
+	if commitment == nil {
+		return height, passCheck(name, "header %d synced, not committed so when is unknown", height)
+	}
+	lag := native.GetHeight() - commitment.PolyHeight
+	if maxLag > 0 && lag > maxLag {
+		return height, failCheck(name, "header %d synced %d poly blocks ago, over %d, check the header relayer",
+			height, lag, maxLag)
+	}
+	return height, passCheck(name, "header %d synced %d poly blocks ago", height, lag)
+}
+
+// checkValidators checks the validators the headers of the chain are verified against are tracked, for the
+// routers keeping them apart from the headers
+func checkValidators(native *native.NativeService, sideChain *side_chain_manager.SideChain, height uint64) *ChainCheck {
+	const name = "validator set"
+	chainID := sideChain.ChainId
+	switch sideChain.Router {
+	case utils.ONT_ROUTER:
+		keyHeights, err := hsont.GetKeyHeights(native, chainID)
+		if err != nil {
+			return failCheck(name, "%v", err)
+		}
+		if len(keyHeights.HeightList) == 0 {
+			return failCheck(name, "no consensus peers tracked, sync the genesis header by syncGenesisHeader")
+		}
+		last := keyHeights.HeightList[len(keyHeights.HeightList)-1]
+		if uint64(last) > height {
+			return failCheck(name, "consensus peers switched at %d above the current header %d, the key heights are inconsistent",
+				last, height)
+		}
+		return passCheck(name, "consensus peers switched at %d", last)
+	case utils.COSMOS_ROUTER:
+		info, err := hscosmos.GetEpochSwitchInfo(native, chainID)
+		if err != nil || info == nil {
+			return failCheck(name, "no epoch tracked: %v, sync the genesis header by syncGenesisHeader", err)
+		}
+		return passCheck(name, "validators switched at %d", info.Height)
+	case utils.OKEX_ROUTER:
+		info, err := hsokex.GetEpochSwitchInfo(native, chainID)
+		if err != nil || info == nil {
+			return failCheck(name, "no epoch tracked: %v, sync the genesis header by syncGenesisHeader", err)
+		}
+		return passCheck(name, "validators switched at %d", info.Height)
+	case utils.QUORUM_ROUTER:
+		vals, err := hsquorum.GetValSet(native, chainID)
+		if err != nil {
+			return failCheck(name, "%v, sync the genesis header by syncGenesisHeader", err)
+		}
+		if len(vals) == 0 {
+			return failCheck(name, "validator set is empty, sync the genesis header by syncGenesisHeader")
+		}
+		return passCheck(name, "%d validators", len(vals))
+	case utils.NEO_ROUTER:
+		raw, err := getCounter(native, utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.CONSENSUS_PEER),
+			utils.GetUint64Bytes(chainID)))
+		if err != nil {
+			return failCheck(name, "%v", err)
+		}
+		if raw == nil {
+			return failCheck(name, "no consensus tracked, sync the genesis header by syncGenesisHeader")
+		}
+		return passCheck(name, "consensus tracked")
+	default:
+		return skipCheck(name, "validators of router %d are verified with the headers", sideChain.Router)
+	}
+}
+
+// checkAssetBinds checks every asset bound on the chain is bound on another chain as well, so that its
+// transfers resolve both ways
+func checkAssetBinds(native *native.NativeService, chainID uint64) *ChainCheck {
+	const name = "asset binds"
+	prefix := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ASSET_BIND))
+	local := make(map[string]bool)
+	remote := make(map[string]bool)
+	iter := native.GetCacheDB().NewIterator(prefix)
+	for has := iter.First(); has; has = iter.Next() {
+		rest := iter.Key()[len(prefix):]
+		if len(rest) < 8 {
+			continue
+		}
+		asset, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			continue
+		}
+		if utils.GetBytesUint64(rest[:8]) == chainID {
+			local[string(asset)] = true
+		} else {
+			remote[string(asset)] = true
+		}
+	}
+	iter.Release()
+	if len(local) == 0 {
+		return skipCheck(name, "no asset bound on the chain, BindAsset to track the supply of its assets")
+	}
+	unresolved := make([]string, 0)
+	for asset := range local {
+		if !remote[asset] {
+			unresolved = append(unresolved, asset)
+		}
+	}
+	if len(unresolved) > 0 {
+		sort.Strings(unresolved)
+		return failCheck(name, "%s bound on no other chain, BindAsset their hashes on the chains they are transferred to",
+			strings.Join(unresolved, ", "))
+	}
+	return passCheck(name, "%d assets bound both ways", len(local))
+}
+
+func checkFeeRate(native *native.NativeService, chainID uint64) *ChainCheck {
+	const name = "fee"
+	rate, err := GetFeeRate(native, chainID)
+	if err != nil {
+		return failCheck(name, "%v", err)
+	}
+	if rate == 0 {
+		return failCheck(name, "no relay fee set for the transfers to the chain, set one by UpdateFee")
+	}
+	route, err := getFeeRoute(native, chainID)
+	if err != nil {
+		return failCheck(name, "%v", err)
+	}
+	if route == nil {
+		return failCheck(name, "no payout route for the fees on the chain, pin one by UpdateFeeProxy")
+	}
+	return passCheck(name, "rate %d/%d", rate, FEE_RATE_BASE)
+}
+
+// checkProofSpec checks what the proofs of the chain are verified with is set and parseable
+func checkProofSpec(native *native.NativeService, sideChain *side_chain_manager.SideChain, height uint64) *ChainCheck {
+	const name = "proof spec"
+	switch sideChain.Router {
+	case utils.BTC_ROUTER, utils.BCH_ROUTER, utils.ZCASH_ROUTER:
+		return skipCheck(name, "txs of router %d are proved against the headers", sideChain.Router)
+	}
+	if len(sideChain.CCMCAddress) == 0 {
+		return failCheck(name, "no cross chain manager contract set, set it by updateSideChain")
+	}
+	switch sideChain.Router {
+	case utils.BSC_ROUTER, utils.HECO_ROUTER, utils.MSC_ROUTER:
+		params, err := hscommon.GetVerifyParams(native, sideChain.ChainId, height, sideChain.ExtraInfo)
+		if err != nil {
+			return failCheck(name, "%v", err)
+		}
+		if !json.Valid(params) {
+			return failCheck(name, "verify params of header %d are not json, publish them by setVerifyParams", height)
+		}
+	}
+	return passCheck(name, "cross chain manager contract %x", sideChain.CCMCAddress)
+}
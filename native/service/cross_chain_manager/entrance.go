@@ -17,11 +17,17 @@
 package cross_chain_manager
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/signature"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/bsc"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
@@ -33,6 +39,7 @@ import (
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/okex"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/ont"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/quorum"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/rollup"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/zilliqa"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
@@ -44,8 +51,69 @@ const (
 	MULTI_SIGN                 = "MultiSign"
 	BLACK_CHAIN                = "BlackChain"
 	WHITE_CHAIN                = "WhiteChain"
+	SIGN_RECEIPT               = "SignReceipt"
+	GET_TRANSFER_COUNT         = "getTransferCount"
+	GET_ASSET_VOLUME           = "getAssetVolume"
+	GET_FAILURE_COUNT          = "getFailureCount"
+	SEND_ADMIN_MESSAGE         = "SendAdminMessage"
+	GET_ADMIN_SEQUENCE         = "getAdminSequence"
+	GET_EPOCH_PUSH             = "getEpochPush"
+	BIND_ASSET                 = "BindAsset"
+	GET_ASSET_SUPPLY           = "getAssetSupply"
+	FREEZE_ASSET               = "FreezeAsset"
+	UNFREEZE_ASSET             = "UnfreezeAsset"
+	GET_ASSET_FROZEN           = "getAssetFrozen"
+	RETRY_COMMIT               = "RetryCommit"
+	REFUND_PENDING_COMMIT      = "RefundPendingCommit"
+	GET_PENDING_COMMIT         = "getPendingCommit"
+	CORRECT_BTC_UTXOS          = "CorrectBtcUtxos"
+	GET_BTC_UTXOS              = "getBtcUtxos"
+	GET_EPOCH_SUMMARY          = "getEpochSummary"
+	BLOCK_RECIPIENT            = "BlockRecipient"
+	GET_BLOCKED_RECIPIENT      = "getBlockedRecipient"
+	GET_FAILED_IMPORTS         = "getFailedImports"
+	CONTRACT_CROSS_CHAIN       = "ContractCrossChain"
+	ALLOW_CONTRACT             = "AllowContract"
+	GET_CONTRACT_ALLOWED       = "getContractAllowed"
+	UPDATE_FEE                 = "UpdateFee"
+	GET_FEE_RATE               = "getFeeRate"
+	GET_RELAYER_FEE            = "getRelayerFee"
+	CLAIM_FEE                  = "ClaimFee"
+	UPDATE_FEE_PROXY           = "UpdateFeeProxy"
+	CHECK_CHAIN                = "checkChain"
+	INVOKE_INSTANCE            = side_chain_manager.INVOKE_INSTANCE
 
-	BLACKED_CHAIN = "BlackedChain"
+	BLACKED_CHAIN       = "BlackedChain"
+	RECEIPT             = "receipt"
+	RECEIPT_SIGS        = "receiptSigs"
+	RELAYER_ATTRIBUTION = "relayerAttribution"
+	RELAYER_STATS       = "relayerStats"
+	OUTBOUND_SEQUENCE   = "outboundSequence"
+	OUTBOUND_PAYLOAD    = "outboundPayload"
+	TRANSFER_COUNT      = "transferCount"
+	ASSET_VOLUME        = "assetVolume"
+	FAILURE_COUNT       = "failureCount"
+	ADMIN_SEQUENCE      = "adminSequence"
+	EPOCH_PUSH          = "epochPush"
+	ASSET_BIND          = "assetBind"
+	ASSET_SUPPLY        = "assetSupply"
+	ASSET_FROZEN        = "assetFrozen"
+	PENDING_COMMIT      = "pendingCommit"
+	CHAIN_STATS         = "chainStats"
+	EPOCH_SUMMARY       = "epochSummary"
+	BLOCKED_RECIPIENT   = "blockedRecipient"
+	BLOCKED_ATTEMPTS    = "blockedAttempts"
+	FAILED_IMPORT       = "failedImport"
+	FAILED_IMPORT_COUNT = "failedImportCount"
+	CONTRACT_SEQUENCE   = "contractSequence"
+	ALLOWED_CONTRACT    = "allowedContract"
+	FEE_RATE            = "feeRate"
+	PENDING_FEE         = "pendingFee"
+	RELAYER_FEE         = "relayerFee"
+	FEE_ROUTE           = "feeRoute"
+	FEE_PROXY           = "feeProxy"
+
+	NOTIFY_RELAYER_ATTRIBUTION = "relayerAttribution"
 )
 
 func RegisterCrossChainManagerContract(native *native.NativeService) {
@@ -54,11 +122,52 @@ func RegisterCrossChainManagerContract(native *native.NativeService) {
 
 	native.Register(BLACK_CHAIN, BlackChain)
 	native.Register(WHITE_CHAIN, WhiteChain)
+
+	native.Register(SIGN_RECEIPT, SignReceipt)
+
+	native.Register(GET_TRANSFER_COUNT, GetTransferCountQuery)
+	native.Register(GET_ASSET_VOLUME, GetAssetVolumeQuery)
+	native.Register(GET_FAILURE_COUNT, GetFailureCountQuery)
+	native.Register(GET_EPOCH_SUMMARY, GetEpochSummaryQuery)
+	native.Register(GET_FAILED_IMPORTS, GetFailedImportsQuery)
+
+	native.Register(SEND_ADMIN_MESSAGE, SendAdminMessage)
+	native.Register(GET_ADMIN_SEQUENCE, GetAdminSequenceQuery)
+	native.Register(GET_EPOCH_PUSH, GetEpochPushQuery)
+
+	native.Register(BIND_ASSET, BindAsset)
+	native.Register(GET_ASSET_SUPPLY, GetAssetSupplyQuery)
+	native.Register(FREEZE_ASSET, FreezeAsset)
+	native.Register(UNFREEZE_ASSET, UnfreezeAsset)
+	native.Register(GET_ASSET_FROZEN, GetAssetFrozenQuery)
+
+	native.Register(RETRY_COMMIT, RetryCommit)
+	native.Register(REFUND_PENDING_COMMIT, RefundPendingCommit)
+	native.Register(GET_PENDING_COMMIT, GetPendingCommitQuery)
+
+	native.Register(CORRECT_BTC_UTXOS, CorrectBtcUtxos)
+	native.Register(GET_BTC_UTXOS, GetBtcUtxosQuery)
+
+	native.Register(CONTRACT_CROSS_CHAIN, ContractCrossChain)
+	native.Register(ALLOW_CONTRACT, AllowContract)
+	native.Register(GET_CONTRACT_ALLOWED, GetContractAllowedQuery)
+
+	native.Register(UPDATE_FEE, UpdateFee)
+	native.Register(GET_FEE_RATE, GetFeeRateQuery)
+	native.Register(GET_RELAYER_FEE, GetRelayerFeeQuery)
+	native.Register(CLAIM_FEE, ClaimFee)
+	native.Register(UPDATE_FEE_PROXY, UpdateFeeProxy)
+	native.Register(CHECK_CHAIN, CheckChainQuery)
+
+	native.Register(BLOCK_RECIPIENT, BlockRecipient)
+	native.Register(GET_BLOCKED_RECIPIENT, GetBlockedRecipientQuery)
+
+	native.Register(INVOKE_INSTANCE, side_chain_manager.InvokeInstance)
 }
 
 func GetChainHandler(router uint64) (scom.ChainHandler, error) {
 	switch router {
-	case utils.BTC_ROUTER:
+	case utils.BTC_ROUTER, utils.BCH_ROUTER, utils.ZCASH_ROUTER:
 		return btc.NewBTCHandler(), nil
 	case utils.ETH_ROUTER:
 		return eth.NewETHHandler(), nil
@@ -80,77 +189,170 @@ func GetChainHandler(router uint64) (scom.ChainHandler, error) {
 		return msc.NewHandler(), nil
 	case utils.OKEX_ROUTER:
 		return okex.NewHandler(), nil
+	case utils.QBFT_ROUTER:
+		return quorum.NewQBFTHandler(), nil
+	case utils.ROLLUP_ROUTER:
+		return rollup.NewRollupHandler(), nil
 	default:
 		return nil, fmt.Errorf("not a supported router:%d", router)
 	}
 }
 
 func ImportExTransfer(native *native.NativeService) ([]byte, error) {
+	chainID, doneID, txParam, err := verifyTransfer(native)
+	var blocked *RecipientBlockedError
+	if errors.As(err, &blocked) {
+		return utils.BYTE_FALSE, &verifiedError{writes: native.GetCacheDB().Snapshot(), cause: err}
+	}
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	// the failure hook keeps the tx verified with the writes of the verification instead of verifying it again
+	verified := native.GetCacheDB().Snapshot()
+	if err := commitTransfer(native, chainID, txParam); err != nil {
+		return utils.BYTE_FALSE, &verifiedError{
+			fromChainID: chainID,
+			doneID:      doneID,
+			txParam:     txParam,
+			writes:      verified,
+			cause:       err,
+		}
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+// verifyTransfer is the first phase of ImportExTransfer, it verifies the proof of the cross chain tx from the
+// source chain and marks it done by the returned id
+func verifyTransfer(native *native.NativeService) (uint64, []byte, *scom.MakeTxParam, error) {
 	params := new(scom.EntranceParam)
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, contract params deserialize error: %v", err)
+		return 0, nil, nil, fmt.Errorf("ImportExTransfer, contract params deserialize error: %v", err)
+	}
+	if err := scom.CheckPayloadVersion(params.Version); err != nil {
+		return 0, nil, nil, fmt.Errorf("ImportExTransfer, %v", err)
+	}
+	if err := checkRelayer(native); err != nil {
+		return 0, nil, nil, fmt.Errorf("ImportExTransfer, %v", err)
 	}
 
 	chainID := params.SourceChainID
 	blacked, err := CheckIfChainBlacked(native, chainID)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, CheckIfChainBlacked error: %v", err)
+		return 0, nil, nil, fmt.Errorf("ImportExTransfer, CheckIfChainBlacked error: %v", err)
 	}
 	if blacked {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, source chain is blacked")
+		return 0, nil, nil, fmt.Errorf("ImportExTransfer, source %w", errChainBlacked)
 	}
 
 	//check if chainid exist
 	sideChain, err := side_chain_manager.GetSideChain(native, chainID)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side_chain_manager.GetSideChain error: %v", err)
+		return 0, nil, nil, fmt.Errorf("ImportExTransfer, side_chain_manager.GetSideChain error: %v", err)
 	}
 	if sideChain == nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side chain %d is not registered", chainID)
+		return 0, nil, nil, fmt.Errorf("ImportExTransfer, %w: %d", errChainUnregistered, chainID)
+	}
+	if err := checkNotQuitting(native, chainID); err != nil {
+		return 0, nil, nil, fmt.Errorf("ImportExTransfer, %w", err)
 	}
 
 	handler, err := GetChainHandler(sideChain.Router)
 	if err != nil {
-		return utils.BYTE_FALSE, err
+		return 0, nil, nil, err
 	}
 	//1. verify tx
-	txParam, err := handler.MakeDepositProposal(native)
+	start := time.Now()
+	txParam, err := makeDepositProposal(native, handler)
+	if !native.IsPreExec() {
+		scom.ObserveVerify(sideChain.Router, native.GetHeight(), time.Since(start), len(params.Proof))
+	}
 	if err != nil {
-		return utils.BYTE_FALSE, err
+		return 0, nil, nil, err
+	}
+	if err := scom.CheckPayloadVersion(txParam.Version); err != nil {
+		return 0, nil, nil, fmt.Errorf("ImportExTransfer, cross chain tx: %v", err)
+	}
+	// the tx is marked done by the proposal, this is the first valid submission
+	doneID := txParam.CrossChainID
+	if sideChain.Router == utils.BTC_ROUTER || sideChain.Router == utils.BCH_ROUTER || sideChain.Router == utils.ZCASH_ROUTER {
+		doneID = txParam.TxHash
+	}
+	if err := attributeRelayer(native, chainID, doneID); err != nil {
+		return 0, nil, nil, fmt.Errorf("ImportExTransfer, %v", err)
+	}
+	// the error is returned as it is, so that the failure hook records the attempt
+	if err := checkRecipient(native, chainID, doneID, txParam); err != nil {
+		return 0, nil, nil, err
+	}
+	return chainID, doneID, txParam, nil
+}
+
+// makeDepositProposal runs the proof verification of handler, a panic of the handler on the untrusted
+// input fails the verification like any error instead of the block execution
+func makeDepositProposal(native *native.NativeService, handler scom.ChainHandler) (txParam *scom.MakeTxParam, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			txParam, err = nil, fmt.Errorf("ImportExTransfer, %w: %v", errHandlerPanic, r)
+		}
+	}()
+	txParam, err = handler.MakeDepositProposal(native)
+	if err != nil {
+		return nil, &proofError{cause: err}
+	}
+	return txParam, nil
+}
+
+// commitTransfer is the second phase of ImportExTransfer, it makes the tx to the target chain for the
+// cross chain tx from chainID verified by the first phase
+func commitTransfer(native *native.NativeService, chainID uint64, txParam *scom.MakeTxParam) error {
+	// transfers to poly itself deposit bonds for the registration of side chains
+	if txParam.ToChainID == native.GetChainID() {
+		if err := side_chain_manager.DepositBond(native, chainID, txParam.Args); err != nil {
+			return fmt.Errorf("ImportExTransfer, %v", err)
+		}
+		return nil
 	}
 
 	//2. make target chain tx
 	targetid := txParam.ToChainID
-	blacked, err = CheckIfChainBlacked(native, targetid)
+	blacked, err := CheckIfChainBlacked(native, targetid)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, CheckIfChainBlacked error: %v", err)
+		return fmt.Errorf("ImportExTransfer, CheckIfChainBlacked error: %v", err)
 	}
 	if blacked {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, target chain is blacked")
+		return fmt.Errorf("ImportExTransfer, target %w", errChainBlacked)
 	}
 
 	//check if chainid exist
-	sideChain, err = side_chain_manager.GetSideChain(native, targetid)
+	sideChain, err := side_chain_manager.GetSideChain(native, targetid)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side_chain_manager.GetSideChain error: %v", err)
+		return fmt.Errorf("ImportExTransfer, side_chain_manager.GetSideChain error: %v", err)
 	}
 	if sideChain == nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side chain %d is not registered", targetid)
+		return fmt.Errorf("ImportExTransfer, %w: %d", errChainUnregistered, targetid)
 	}
-	if sideChain.Router == utils.BTC_ROUTER {
-		err := btc.NewBTCHandler().MakeTransaction(native, txParam, chainID)
-		if err != nil {
-			return utils.BYTE_FALSE, err
-		}
-		return utils.BYTE_TRUE, nil
+	if err := checkNotQuitting(native, targetid); err != nil {
+		return fmt.Errorf("ImportExTransfer, %w", err)
+	}
+	if err := side_chain_manager.CheckProbation(native, chainID, targetid, txParam.Args); err != nil {
+		return fmt.Errorf("ImportExTransfer, %v", err)
+	}
+	if err := checkAssetNotFrozen(native, txParam); err != nil {
+		return fmt.Errorf("ImportExTransfer, %w", err)
+	}
+	if err := countTransfer(native, chainID, txParam); err != nil {
+		return fmt.Errorf("ImportExTransfer, %v", err)
+	}
+	if sideChain.Router == utils.BTC_ROUTER || sideChain.Router == utils.BCH_ROUTER || sideChain.Router == utils.ZCASH_ROUTER {
+		return btc.NewBTCHandler().MakeTransaction(native, txParam, chainID)
 	}
 
-	//NOTE, you need to store the tx in this
-	err = MakeTransaction(native, txParam, chainID)
-	if err != nil {
-		return utils.BYTE_FALSE, err
+	if err := chargeFee(native, chainID, txParam); err != nil {
+		return fmt.Errorf("ImportExTransfer, %v", err)
 	}
-	return utils.BYTE_TRUE, nil
+
+	//NOTE, you need to store the tx in this
+	return MakeTransaction(native, txParam, chainID)
 }
 
 func MultiSign(native *native.NativeService) ([]byte, error) {
@@ -174,13 +376,25 @@ func MakeTransaction(service *native.NativeService, params *scom.MakeTxParam, fr
 
 	sink := common.NewZeroCopySink(nil)
 	merkleValue.Serialization(sink)
-	err := PutRequest(service, merkleValue.TxHash, params.ToChainID, sink.Bytes())
+	// the cross states made in a bridge instance are bound to it, so that the side chains verifying them
+	// against the poly headers shared by every instance never take them for the ones of another instance
+	leaf := scom.MakeInstanceLeaf(service.GetCacheDB().Namespace(), sink.Bytes())
+	err := PutRequest(service, merkleValue.TxHash, params.ToChainID, leaf)
 	if err != nil {
 		return fmt.Errorf("MakeTransaction, putRequest error:%s", err)
 	}
-	service.PutMerkleVal(sink.Bytes())
+	service.PutMerkleVal(leaf)
+	if err := putOutboundPayload(service, params.ToChainID, merkleValue.TxHash); err != nil {
+		return fmt.Errorf("MakeTransaction, %v", err)
+	}
+	putReceipt(service, &Receipt{
+		CrossChainID: merkleValue.TxHash,
+		ToChainID:    params.ToChainID,
+		PayloadHash:  sha256.Sum256(leaf),
+	})
 	chainIDBytes := utils.GetUint64Bytes(params.ToChainID)
-	key := hex.EncodeToString(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(scom.REQUEST), chainIDBytes, merkleValue.TxHash))
+	key := hex.EncodeToString(service.GetCacheDB().NamespacedKey(
+		utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(scom.REQUEST), chainIDBytes, merkleValue.TxHash)))
 	scom.NotifyMakeProof(service, fromChainID, params.ToChainID, hex.EncodeToString(params.TxHash), key)
 	return nil
 }
@@ -233,3 +447,77 @@ func WhiteChain(native *native.NativeService) ([]byte, error) {
 	RemoveBlackChain(native, params.ChainID)
 	return utils.BYTE_TRUE, nil
 }
+
+// SignReceipt collects the signature of a consensus peer on the receipt of a cross chain
+// transfer, the receipt is attested once the signatures reach two thirds of the peers.
+func SignReceipt(native *native.NativeService) ([]byte, error) {
+	params := new(SignReceiptParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, contract params deserialize error: %v", err)
+	}
+	receipt, err := GetReceipt(native, params.ToChainID, params.CrossChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, GetReceipt error: %v", err)
+	}
+	if receipt == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, receipt of %x to chain %d not found", params.CrossChainID, params.ToChainID)
+	}
+	sigs, err := GetReceiptSigs(native, params.ToChainID, params.CrossChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, GetReceiptSigs error: %v", err)
+	}
+	if sigs.Attested {
+		return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, receipt is already attested")
+	}
+
+	view, err := node_manager.GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, GetView error: %v", err)
+	}
+	peerPoolMap, err := node_manager.GetPeerPoolMap(native, view)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, GetPeerPoolMap error: %v", err)
+	}
+	peer, ok := peerPoolMap.PeerPoolMap[params.PeerPubkey]
+	if !ok || peer.Status != node_manager.ConsensusStatus {
+		return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, %s is not a consensus peer", params.PeerPubkey)
+	}
+	k, err := hex.DecodeString(params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, hex.DecodeString public key error: %v", err)
+	}
+	publicKey, err := keypair.DeserializePublicKey(k)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, keypair.DeserializePublicKey error: %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	receipt.Serialization(sink)
+	if err := signature.Verify(publicKey, sink.Bytes(), params.Sig); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, failed to verify signature: %v", err)
+	}
+	sigs.Sigs[params.PeerPubkey] = params.Sig
+
+	num, sum := 0, 0
+	for key, v := range peerPoolMap.PeerPoolMap {
+		if v.Status == node_manager.ConsensusStatus {
+			if _, ok := sigs.Sigs[key]; ok {
+				num++
+			}
+			sum++
+		}
+	}
+	if num >= (2*sum+2)/3 {
+		sigs.Attested = true
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States:          []interface{}{"ReceiptAttested", params.ToChainID, hex.EncodeToString(params.CrossChainID), num},
+				ToChainID:       event.ChainID(params.ToChainID),
+			})
+		if err := settleFee(native, params.ToChainID, params.CrossChainID); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("SignReceipt, %v", err)
+		}
+	}
+	putReceiptSigs(native, params.ToChainID, params.CrossChainID, sigs)
+	return utils.BYTE_TRUE, nil
+}
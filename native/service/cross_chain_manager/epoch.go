@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func init() {
+	node_manager.RegisterViewChangeHook("cross_chain_manager/pushEpoch", node_manager.VIEW_CHANGE_PUSH, pushEpoch)
+}
+
+// pushEpoch sends the keepers of the new view to the CCM contract of every registered chain as an
+// ADMIN_UPDATE_KEEPERS admin message, the args are the view followed by the public keys of the keepers.
+// A failed push doesn't stop the view change, it is recorded in the push status of the chain.
+func pushEpoch(native *native.NativeService, view uint32) {
+	sideChains, err := side_chain_manager.GetSideChains(native)
+	if err != nil {
+		return
+	}
+	args, keeperErr := epochArgs(native, view)
+	for _, sideChain := range sideChains {
+		if !hasCCMContract(sideChain) || checkNotQuitting(native, sideChain.ChainId) != nil {
+			continue
+		}
+		txHash := native.GetTx().Hash()
+		push := &EpochPush{
+			View:   view,
+			Height: native.GetHeight(),
+			TxHash: txHash.ToArray(),
+		}
+		err := keeperErr
+		if err == nil {
+			push.Sequence, err = emitAdminMessage(native, sideChain, scom.ADMIN_UPDATE_KEEPERS, args)
+		}
+		if err != nil {
+			push.Error = err.Error()
+		}
+		putEpochPush(native, sideChain.ChainId, push)
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States:          []interface{}{"pushEpoch", sideChain.ChainId, view, push.Sequence, push.Error},
+			})
+	}
+}
+
+func epochArgs(native *native.NativeService, view uint32) ([]byte, error) {
+	peerPoolMap, err := node_manager.GetPeerPoolMap(native, view)
+	if err != nil {
+		return nil, fmt.Errorf("epochArgs, GetPeerPoolMap error: %v", err)
+	}
+	keepers := make([][]byte, 0)
+	for _, key := range peerPoolMap.SortedPubkeys() {
+		if peerPoolMap.PeerPoolMap[key].Status != node_manager.ConsensusStatus {
+			continue
+		}
+		k, err := hex.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("epochArgs, hex.DecodeString public key error: %v", err)
+		}
+		keepers = append(keepers, k)
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint32(view)
+	sink.WriteVarUint(uint64(len(keepers)))
+	for _, k := range keepers {
+		sink.WriteVarBytes(k)
+	}
+	return sink.Bytes(), nil
+}
+
+func putEpochPush(native *native.NativeService, toChainID uint64, push *EpochPush) {
+	sink := common.NewZeroCopySink(nil)
+	push.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(EPOCH_PUSH), utils.GetUint64Bytes(toChainID)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// GetEpochPush returns nil if no keepers were pushed to the chain
+func GetEpochPush(native *native.NativeService, toChainID uint64) (*EpochPush, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(EPOCH_PUSH), utils.GetUint64Bytes(toChainID)))
+	if err != nil {
+		return nil, fmt.Errorf("GetEpochPush, get epoch push store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetEpochPush, deserialize from raw storage item err: %v", err)
+	}
+	push := new(EpochPush)
+	if err := push.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetEpochPush, deserialize epoch push error: %v", err)
+	}
+	return push, nil
+}
+
+// GetEpochPushQuery returns the status of the last keepers push to the chain followed by whether its
+// receipt is attested, empty if nothing was pushed, to be called by preExec
+func GetEpochPushQuery(native *native.NativeService) ([]byte, error) {
+	params := new(ToChainParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetEpochPushQuery, contract params deserialize error: %v", err)
+	}
+	push, err := GetEpochPush(native, params.ToChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if push == nil {
+		return []byte{}, nil
+	}
+	sigs, err := GetReceiptSigs(native, params.ToChainID, push.TxHash)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	sink := common.NewZeroCopySink(nil)
+	push.Serialization(sink)
+	sink.WriteBool(push.Error == "" && sigs.Attested)
+	return sink.Bytes(), nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/types"
+	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/testsupport"
+	synccom "github.com/polynetwork/poly/native/service/header_sync/common"
+	synceth "github.com/polynetwork/poly/native/service/header_sync/eth"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+)
+
+func TestConformance(t *testing.T) {
+	valid := proofHandleEntranceParam()
+	testsupport.Run(t, &testsupport.Fixture{
+		Handler: NewETHHandler(),
+		Setup: func(t *testing.T, db *storage.CacheDB) {
+			tx := &types.Transaction{
+				ChainID:    0,
+				SignedAddr: []common.Address{acct.Address},
+			}
+			if err := synceth.NewETHHandler().SyncGenesisHeader(NewNative(proofHandleGenesisParam(), tx, db)); err != nil {
+				t.Fatalf("SyncGenesisHeader error: %v", err)
+			}
+			ns := NewNative(proofHandleHeadersParam(), &types.Transaction{ChainID: 0}, db)
+			if err := synceth.NewETHHandler().SyncBlockHeader(ns); err != nil {
+				t.Fatalf("SyncBlockHeader error: %v", err)
+			}
+			SetChain(ns, "4b61a4c0ab51b53cfabf1339bfdb7dfd27be596a", 1)
+		},
+		Valid:     valid,
+		Malformed: testsupport.MalformedEntrances(valid),
+		Equivalent: testsupport.EquivalentEntrances(valid, map[string][]byte{
+			"indented proof": indentProof(t, valid),
+		}),
+		// point the main chain at the proven height to another header
+		Reorg: func(t *testing.T, db *storage.CacheDB) {
+			ns := NewNative(nil, &types.Transaction{ChainID: 0}, db)
+			SetChain(ns, "4b61a4c0ab51b53cfabf1339bfdb7dfd27be596a", 1)
+			sibling := getHeaderHashByHeight(ns, 7259463)
+			db.Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(synccom.MAIN_CHAIN), utils.GetUint64Bytes(2),
+				utils.GetUint64Bytes(7259464)), cstates.GenRawStorageItem(sibling.Bytes()))
+		},
+	})
+}
+
+// indentProof re-encodes the json proof of the entrance with indentation, the
+// proof bytes differ but the account and storage proven are the same
+func indentProof(t *testing.T, entrance []byte) []byte {
+	params := new(ccmcom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(entrance)); err != nil {
+		t.Fatalf("Deserialization error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, params.Proof, "", "  "); err != nil {
+		t.Fatalf("json.Indent error: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -52,6 +52,14 @@ type ETHProof struct {
 	StorageProofs []StorageProof `json:"storageProof"`
 }
 
+// ETHEventProof proves a log emitted by a lock contract through the receipt trie,
+// it is used for the lock contracts which have an event topic configured.
+type ETHEventProof struct {
+	TxIndex      uint64   `json:"txIndex"`
+	LogIndex     uint64   `json:"logIndex"`
+	ReceiptProof []string `json:"receiptProof"`
+}
+
 func (this *ETHProof) String() string {
 	bs := bytes.NewBuffer([]byte("ETHProof:\n"))
 	bs.WriteString("AccountProof:\n")
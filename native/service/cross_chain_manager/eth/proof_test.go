@@ -114,3 +114,40 @@ func SendRestRequest(restClient *http.Client, addr string, data []byte) ([]byte,
 	}
 	return body, nil
 }
+
+func TestDecodeEventBytesArg(t *testing.T) {
+	word := func(v uint64) []byte {
+		w := make([]byte, 32)
+		for i := 0; i < 8; i++ {
+			w[31-i] = byte(v >> (8 * uint(i)))
+		}
+		return w
+	}
+	// (address, bytes, uint64, bytes) with the bytes of 3 and 33 long
+	data := append(word(0xff), word(128)...)
+	data = append(data, word(1)...)
+	data = append(data, word(192)...)
+	data = append(data, word(3)...)
+	data = append(data, append([]byte{1, 2, 3}, make([]byte, 29)...)...)
+	data = append(data, word(33)...)
+	long := make([]byte, 64)
+	for i := 0; i < 33; i++ {
+		long[i] = byte(i + 1)
+	}
+	data = append(data, long...)
+
+	raw, err := decodeEventBytesArg(data, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, raw)
+
+	raw, err = decodeEventBytesArg(data, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, long[:33], raw)
+
+	_, err = decodeEventBytesArg(data, 2)
+	assert.Error(t, err)
+	_, err = decodeEventBytesArg(data[:200], 3)
+	assert.Error(t, err)
+	_, err = decodeEventBytesArg(data, 100)
+	assert.Error(t, err)
+}
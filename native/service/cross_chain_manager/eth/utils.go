@@ -26,7 +26,6 @@ import (
 
 	ecom "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/light"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
@@ -36,23 +35,39 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	cmanager "github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/eth"
+	"github.com/polynetwork/poly/native/service/precompile"
 )
 
+// the crypto primitives the proof verification relies on
+var keccak256 = precompile.MustHash("cross_chain_manager/eth", precompile.KECCAK256)
+
 func verifyFromEthTx(native *native.NativeService, proof, extra []byte, fromChainID uint64, height uint32, sideChain *cmanager.SideChain) (*scom.MakeTxParam, error) {
 	bestHeader, _, err := eth.GetCurrentHeader(native, fromChainID)
 	if err != nil {
 		return nil, fmt.Errorf("VerifyFromEthProof, get current header fail, error:%s", err)
 	}
+	blocksToWait, err := cmanager.GetRequiredConfirmations(native, sideChain, scom.GetTransferAmount(extra))
+	if err != nil {
+		return nil, fmt.Errorf("VerifyFromEthProof, get required confirmations error:%s", err)
+	}
 	bestHeight := uint32(bestHeader.Number.Uint64())
-	if bestHeight < height || bestHeight-height < uint32(sideChain.BlocksToWait-1) {
+	if bestHeight < height || bestHeight-height < uint32(blocksToWait-1) {
 		return nil, fmt.Errorf("VerifyFromEthProof, transaction is not confirmed, current height: %d, input height: %d", bestHeight, height)
 	}
+	if err := cmanager.CheckProofAge(native, fromChainID, uint64(height), uint64(bestHeight)); err != nil {
+		return nil, fmt.Errorf("VerifyFromEthProof, %v", err)
+	}
 
 	blockData, _, err := eth.GetHeaderByHeight(native, uint64(height), fromChainID)
 	if err != nil {
 		return nil, fmt.Errorf("VerifyFromEthProof, get header by height, height:%d, error:%s", height, err)
 	}
 
+	eventProof := new(ETHEventProof)
+	if err := json.Unmarshal(proof, eventProof); err == nil && len(eventProof.ReceiptProof) != 0 {
+		return verifyFromEthEvent(native, eventProof, extra, fromChainID, blockData)
+	}
+
 	ethProof := new(ETHProof)
 	err = json.Unmarshal(proof, ethProof)
 	if err != nil {
@@ -85,6 +100,115 @@ func verifyFromEthTx(native *native.NativeService, proof, extra []byte, fromChai
 	return txParam, nil
 }
 
+// verifyFromEthEvent verifies the cross chain request carried by the event of a lock
+// contract, the contract and the event layout should be configured in side chain manager.
+func verifyFromEthEvent(native *native.NativeService, eventProof *ETHEventProof, extra []byte, fromChainID uint64,
+	blockData *types.Header) (*scom.MakeTxParam, error) {
+	nodeList := new(light.NodeList)
+	for _, s := range eventProof.ReceiptProof {
+		nodeList.Put(nil, ecom.Hex2Bytes(scom.Replace0x(s)))
+	}
+	key, err := rlp.EncodeToBytes(uint(eventProof.TxIndex))
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromEthEvent, encode receipt key error:%s", err)
+	}
+	val, err := trie.VerifyProof(blockData.ReceiptHash, key, nodeList.NodeSet())
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromEthEvent, verify receipt proof error:%s", err)
+	}
+	receipt, err := decodeReceipt(val)
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromEthEvent, decode receipt error:%s", err)
+	}
+	if eventProof.LogIndex >= uint64(len(receipt.Logs)) {
+		return nil, fmt.Errorf("verifyFromEthEvent, log index %d out of range, %d logs in receipt",
+			eventProof.LogIndex, len(receipt.Logs))
+	}
+	lg := receipt.Logs[eventProof.LogIndex]
+
+	topic, err := cmanager.GetLockEventTopic(native, fromChainID, lg.Address.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromEthEvent, GetLockEventTopic error:%s", err)
+	}
+	if topic == nil {
+		return nil, fmt.Errorf("verifyFromEthEvent, no event topic configured for lock contract %s", lg.Address.Hex())
+	}
+	if len(lg.Topics) == 0 || !bytes.Equal(lg.Topics[0].Bytes(), topic.Topic) {
+		return nil, fmt.Errorf("verifyFromEthEvent, log of lock contract %s is not the configured event %x",
+			lg.Address.Hex(), topic.Topic)
+	}
+	raw, err := decodeEventBytesArg(lg.Data, topic.ParamIndex)
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromEthEvent, decode event data error:%s", err)
+	}
+	if !bytes.Equal(raw, extra) {
+		return nil, fmt.Errorf("verifyFromEthEvent, event param %x is not equal to extra %x", raw, extra)
+	}
+
+	txParam := new(scom.MakeTxParam)
+	if err := txParam.Deserialization(common.NewZeroCopySource(extra)); err != nil {
+		return nil, fmt.Errorf("verifyFromEthEvent, deserialize merkleValue error:%s", err)
+	}
+	// the target chains release the assets of the from contract, only the lock contract can claim to be it
+	if !bytes.Equal(txParam.FromContractAddress, lg.Address.Bytes()) {
+		return nil, fmt.Errorf("verifyFromEthEvent, from contract %x is not the lock contract %s emitting the event",
+			txParam.FromContractAddress, lg.Address.Hex())
+	}
+	return txParam, nil
+}
+
+// the transaction types of EIP-2718 the receipts are typed by
+const (
+	accessListTxType = 0x01
+	dynamicFeeTxType = 0x02
+	blobTxType       = 0x03
+	setCodeTxType    = 0x04
+)
+
+// decodeReceipt decodes a receipt in the consensus encoding kept by the receipt trie, it does what
+// types.Receipt.UnmarshalBinary of the later go-ethereum does: a typed receipt of EIP-2718 is the type
+// byte followed by the rlp of the same fields as a legacy one.
+func decodeReceipt(b []byte) (*types.Receipt, error) {
+	receipt := new(types.Receipt)
+	if len(b) > 0 && b[0] > 0x7f {
+		if err := rlp.DecodeBytes(b, receipt); err != nil {
+			return nil, err
+		}
+		return receipt, nil
+	}
+	if len(b) <= 1 {
+		return nil, fmt.Errorf("typed receipt too short")
+	}
+	switch b[0] {
+	case accessListTxType, dynamicFeeTxType, blobTxType, setCodeTxType:
+	default:
+		return nil, fmt.Errorf("receipt type %d not supported", b[0])
+	}
+	if err := rlp.DecodeBytes(b[1:], receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// decodeEventBytesArg extracts the bytes argument at index from the abi encoded
+// non-indexed arguments of an event.
+func decodeEventBytesArg(data []byte, index uint64) ([]byte, error) {
+	size := uint64(len(data))
+	if index >= size/32 {
+		return nil, fmt.Errorf("argument index %d out of range", index)
+	}
+	offset := new(big.Int).SetBytes(data[index*32 : index*32+32])
+	if !offset.IsUint64() || offset.Uint64() > size-32 {
+		return nil, fmt.Errorf("invalid offset of argument %d", index)
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(data[start-32 : start])
+	if !length.IsUint64() || length.Uint64() > size-start {
+		return nil, fmt.Errorf("invalid length of argument %d", index)
+	}
+	return data[start : start+length.Uint64()], nil
+}
+
 func VerifyMerkleProof(ethProof *ETHProof, blockData *types.Header, contractAddr []byte) ([]byte, error) {
 	//1. prepare verify account
 	nodeList := new(light.NodeList)
@@ -99,7 +223,7 @@ func VerifyMerkleProof(ethProof *ETHProof, blockData *types.Header, contractAddr
 	if !bytes.Equal(addr, contractAddr) {
 		return nil, fmt.Errorf("verifyMerkleProof, contract address is error, proof address: %s, side chain address: %s", ethProof.Address, hex.EncodeToString(contractAddr))
 	}
-	acctKey := crypto.Keccak256(addr)
+	acctKey := keccak256(addr)
 
 	// 2. verify account proof
 	acctVal, err := trie.VerifyProof(blockData.Root, acctKey, ns)
@@ -145,7 +269,7 @@ func VerifyMerkleProof(ethProof *ETHProof, blockData *types.Header, contractAddr
 	}
 
 	sp := ethProof.StorageProofs[0]
-	storageKey := crypto.Keccak256(ecom.HexToHash(scom.Replace0x(sp.Key)).Bytes())
+	storageKey := keccak256(ecom.HexToHash(scom.Replace0x(sp.Key)).Bytes())
 
 	for _, prf := range sp.Proof {
 		nodeList.Put(nil, ecom.Hex2Bytes(scom.Replace0x(prf)))
@@ -173,6 +297,6 @@ func CheckProofResult(result, value []byte) bool {
 		s = append(s, 0)
 	}
 	s = append(s, s_temp...)
-	hash := crypto.Keccak256(value)
+	hash := keccak256(value)
 	return bytes.Equal(s, hash)
 }
@@ -0,0 +1,469 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package cross_chain_manager
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/governance/treasury"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	// FEE_RATE_BASE is the denominator of the fee rates, a rate of 30 charges 0.3% of the amount
+	FEE_RATE_BASE = 10000
+	// TREASURY_FEE_SHARE is the part of every relay fee going to the treasury, over FEE_RATE_BASE
+	TREASURY_FEE_SHARE = 1000
+)
+
+func init() {
+	treasury.RegisterPayouts(payTreasury, creditRelayerFee)
+}
+
+// UpdateFee sets the relay fee of the transfers to a chain once the consensus peers approve it. The fee
+// is deducted from the amount of the transfers between the lock proxies registered by UpdateFeeProxy and
+// settled to the relayer who submitted the transfer once its receipt is attested, the relayer takes it
+// by ClaimFee.
+func UpdateFee(native *native.NativeService) ([]byte, error) {
+	params := new(UpdateFeeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateFee, contract params deserialize error: %v", err)
+	}
+	if params.Rate >= FEE_RATE_BASE {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateFee, rate %d should be less than %d", params.Rate, FEE_RATE_BASE)
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateFee, checkWitness error: %v", err)
+	}
+
+	sideChain, err := side_chain_manager.GetSideChain(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateFee, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateFee, side chain %d is not registered", params.ChainID)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(params.ChainID)
+	sink.WriteVarUint(params.Rate)
+	ok, err := node_manager.CheckConsensusSigns(native, UPDATE_FEE, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateFee, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(FEE_RATE), utils.GetUint64Bytes(params.ChainID))
+	if params.Rate == 0 {
+		native.GetCacheDB().Delete(key)
+	} else {
+		native.GetCacheDB().Put(key, cstates.GenRawStorageItem(utils.GetUint64Bytes(params.Rate)))
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"updateFee", params.ChainID, params.Rate},
+			ToChainID:       event.ChainID(params.ChainID),
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// GetFeeRate returns the relay fee rate of the transfers to chainID, 0 if no fee is charged
+func GetFeeRate(native *native.NativeService, chainID uint64) (uint64, error) {
+	raw, err := getCounter(native, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(FEE_RATE),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return 0, fmt.Errorf("GetFeeRate, %v", err)
+	}
+	if raw == nil {
+		return 0, nil
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+func pendingFeeKey(toChainID uint64, crossChainID []byte) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(PENDING_FEE),
+		utils.GetUint64Bytes(toChainID), crossChainID)
+}
+
+func relayerFeeKey(relayer common.Address, toChainID uint64, asset []byte) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(RELAYER_FEE), relayer[:],
+		utils.GetUint64Bytes(toChainID), asset)
+}
+
+func feeRouteKey(toChainID uint64) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(FEE_ROUTE), utils.GetUint64Bytes(toChainID))
+}
+
+func feeProxyKey(toChainID uint64, route *FeeRoute) []byte {
+	sink := common.NewZeroCopySink(nil)
+	route.Serialization(sink)
+	id := sha256.Sum256(sink.Bytes())
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(FEE_PROXY), utils.GetUint64Bytes(toChainID), id[:])
+}
+
+// UpdateFeeProxy registers a pair of lock proxies as the one the transfers between are charged the fee,
+// or unregisters it, once the consensus peers approve it. A registered pair may be pinned as the route
+// the fees on the target chain are paid out by, unregistering the pair unpins it.
+func UpdateFeeProxy(native *native.NativeService) ([]byte, error) {
+	params := new(UpdateFeeProxyParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateFeeProxy, contract params deserialize error: %v", err)
+	}
+	if len(params.Route.FromContract) == 0 || len(params.Route.ToContract) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateFeeProxy, proxy contract is empty")
+	}
+	if params.Payout && !params.Allowed {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateFeeProxy, an unregistered proxy pair can't be the payout route")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateFeeProxy, checkWitness error: %v", err)
+	}
+
+	for _, chainID := range []uint64{params.Route.FromChainID, params.ToChainID} {
+		sideChain, err := side_chain_manager.GetSideChain(native, chainID)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("UpdateFeeProxy, side_chain_manager.GetSideChain error: %v", err)
+		}
+		if sideChain == nil {
+			return utils.BYTE_FALSE, fmt.Errorf("UpdateFeeProxy, side chain %d is not registered", chainID)
+		}
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(params.ToChainID)
+	params.Route.Serialization(sink)
+	sink.WriteBool(params.Allowed)
+	sink.WriteBool(params.Payout)
+	ok, err := node_manager.CheckConsensusSigns(native, UPDATE_FEE_PROXY, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateFeeProxy, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if params.Allowed {
+		native.GetCacheDB().Put(feeProxyKey(params.ToChainID, params.Route), cstates.GenRawStorageItem([]byte{1}))
+		if params.Payout {
+			route := common.NewZeroCopySink(nil)
+			params.Route.Serialization(route)
+			native.GetCacheDB().Put(feeRouteKey(params.ToChainID), cstates.GenRawStorageItem(route.Bytes()))
+		}
+	} else {
+		native.GetCacheDB().Delete(feeProxyKey(params.ToChainID, params.Route))
+		payout, err := getFeeRoute(native, params.ToChainID)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("UpdateFeeProxy, %v", err)
+		}
+		if payout != nil && payout.FromChainID == params.Route.FromChainID &&
+			bytes.Equal(payout.FromContract, params.Route.FromContract) && bytes.Equal(payout.ToContract, params.Route.ToContract) {
+			native.GetCacheDB().Delete(feeRouteKey(params.ToChainID))
+		}
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{"updateFeeProxy", params.Route.FromChainID, hex.EncodeToString(params.Route.FromContract),
+				params.ToChainID, hex.EncodeToString(params.Route.ToContract), params.Allowed, params.Payout},
+			ToChainID: event.ChainID(params.ToChainID),
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// IsFeeProxy tells whether the transfers from fromContract of fromChainID to toContract of toChainID are
+// the ones between a registered pair of lock proxies
+func IsFeeProxy(native *native.NativeService, fromChainID uint64, fromContract []byte, toChainID uint64, toContract []byte) (bool, error) {
+	raw, err := getCounter(native, feeProxyKey(toChainID, &FeeRoute{
+		FromChainID:  fromChainID,
+		FromContract: fromContract,
+		ToContract:   toContract,
+	}))
+	if err != nil {
+		return false, fmt.Errorf("IsFeeProxy, %v", err)
+	}
+	return raw != nil, nil
+}
+
+// getFeeRoute returns the payout route pinned for toChainID, nil if none is
+func getFeeRoute(native *native.NativeService, toChainID uint64) (*FeeRoute, error) {
+	raw, err := getCounter(native, feeRouteKey(toChainID))
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	route := new(FeeRoute)
+	if err := route.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, err
+	}
+	return route, nil
+}
+
+// chargeFee deducts the relay fee of the target chain from the amount of the unlock between a registered
+// pair of lock proxies, the fee is kept pending under the cross chain id of the tx made by poly until its
+// receipt is attested. Nothing is charged for the other payloads or the txs without a submitter.
+func chargeFee(native *native.NativeService, fromChainID uint64, txParam *scom.MakeTxParam) error {
+	rate, err := GetFeeRate(native, txParam.ToChainID)
+	if err != nil || rate == 0 {
+		return err
+	}
+	if txParam.Method != "unlock" {
+		return nil
+	}
+	ok, err := IsFeeProxy(native, fromChainID, txParam.FromContractAddress, txParam.ToChainID, txParam.ToContractAddress)
+	if err != nil || !ok {
+		return err
+	}
+	asset, _, amount := scom.DecodeTransfer(txParam.Args)
+	if amount == nil {
+		return nil
+	}
+	fee := new(big.Int).Mul(amount, new(big.Int).SetUint64(rate))
+	fee.Div(fee, big.NewInt(FEE_RATE_BASE))
+	if fee.Sign() == 0 {
+		return nil
+	}
+	relayer, ok, err := submitter(native)
+	if err != nil || !ok {
+		return err
+	}
+	args := scom.SetTransferAmount(txParam.Args, new(big.Int).Sub(amount, fee))
+	if args == nil {
+		return fmt.Errorf("chargeFee, failed to deduct fee from the transfer args")
+	}
+	txParam.Args = args
+
+	crossChainID := native.GetTx().Hash()
+	pending := &PendingFee{
+		Relayer: relayer,
+		Asset:   asset,
+		Fee:     fee,
+	}
+	sink := common.NewZeroCopySink(nil)
+	pending.Serialization(sink)
+	native.GetCacheDB().Put(pendingFeeKey(txParam.ToChainID, crossChainID[:]), cstates.GenRawStorageItem(sink.Bytes()))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{"chargeFee", txParam.ToChainID, hex.EncodeToString(crossChainID[:]), relayer.ToBase58(),
+				hex.EncodeToString(asset), fee.String()},
+			FromChainID: event.ChainID(fromChainID),
+			ToChainID:   event.ChainID(txParam.ToChainID),
+		})
+	return nil
+}
+
+// GetPendingFee returns nil if no fee is pending for the cross chain id to toChainID
+func GetPendingFee(native *native.NativeService, toChainID uint64, crossChainID []byte) (*PendingFee, error) {
+	raw, err := getCounter(native, pendingFeeKey(toChainID, crossChainID))
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingFee, %v", err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	pending := new(PendingFee)
+	if err := pending.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetPendingFee, %v", err)
+	}
+	return pending, nil
+}
+
+// settleFee accrues the fee pending for the cross chain id once the transfer is delivered to toChainID,
+// TREASURY_FEE_SHARE of it goes to the treasury, the remainder of the split to the treasury as dust and
+// the rest to the relayer.
+func settleFee(native *native.NativeService, toChainID uint64, crossChainID []byte) error {
+	pending, err := GetPendingFee(native, toChainID, crossChainID)
+	if err != nil || pending == nil {
+		return err
+	}
+	share := new(big.Int).Mul(pending.Fee, big.NewInt(TREASURY_FEE_SHARE))
+	share.Div(share, big.NewInt(FEE_RATE_BASE))
+	relayerFee := new(big.Int).Mul(pending.Fee, big.NewInt(FEE_RATE_BASE-TREASURY_FEE_SHARE))
+	relayerFee.Div(relayerFee, big.NewInt(FEE_RATE_BASE))
+	dust := new(big.Int).Sub(pending.Fee, share)
+	dust.Sub(dust, relayerFee)
+	if share.Sign() > 0 {
+		if err := treasury.Credit(native, treasury.INCOME_FEE, toChainID, pending.Asset, share); err != nil {
+			return err
+		}
+	}
+	if dust.Sign() > 0 {
+		if err := treasury.Credit(native, treasury.INCOME_DUST, toChainID, pending.Asset, dust); err != nil {
+			return err
+		}
+	}
+	if err := creditRelayerFee(native, pending.Relayer, toChainID, pending.Asset, relayerFee); err != nil {
+		return err
+	}
+	native.GetCacheDB().Delete(pendingFeeKey(toChainID, crossChainID))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{"settleFee", toChainID, hex.EncodeToString(crossChainID), pending.Relayer.ToBase58(),
+				hex.EncodeToString(pending.Asset), relayerFee.String()},
+			ToChainID: event.ChainID(toChainID),
+		})
+	return nil
+}
+
+// GetRelayerFee returns the fees of the asset on toChainID settled to the relayer
+func GetRelayerFee(native *native.NativeService, relayer common.Address, toChainID uint64, asset []byte) (*big.Int, error) {
+	raw, err := getCounter(native, relayerFeeKey(relayer, toChainID, asset))
+	if err != nil {
+		return nil, fmt.Errorf("GetRelayerFee, %v", err)
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+// ClaimFee pays the fees of an asset on a chain settled to the relayer out to the address of the
+// relayer on the chain. The fees stay locked in the proxy of the chain as the part of the transfers
+// not unlocked, so they are paid by an unlock of the proxy through the payout route of the chain.
+func ClaimFee(native *native.NativeService) ([]byte, error) {
+	params := new(ClaimFeeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ClaimFee, contract params deserialize error: %v", err)
+	}
+	if len(params.ToAddress) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("ClaimFee, to address is empty")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Relayer); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ClaimFee, checkWitness error: %v", err)
+	}
+
+	accrued, err := GetRelayerFee(native, params.Relayer, params.ToChainID, params.Asset)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ClaimFee, %v", err)
+	}
+	if accrued.Sign() == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("ClaimFee, no fee of asset %s on chain %d to claim",
+			hex.EncodeToString(params.Asset), params.ToChainID)
+	}
+	if err := makeUnlock(native, params.ToChainID, params.Asset, params.ToAddress, accrued,
+		relayerFeeKey(params.Relayer, params.ToChainID, params.Asset)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ClaimFee, %v", err)
+	}
+	native.GetCacheDB().Delete(relayerFeeKey(params.Relayer, params.ToChainID, params.Asset))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{"claimFee", params.ToChainID, params.Relayer.ToBase58(), hex.EncodeToString(params.Asset),
+				hex.EncodeToString(params.ToAddress), accrued.String()},
+			ToChainID: event.ChainID(params.ToChainID),
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// makeUnlock makes the cross chain tx unlocking amount of asset on toChainID to toAddress by the proxy
+// in the payout route pinned for the chain, the cross chain id is made of id and the poly tx
+func makeUnlock(native *native.NativeService, toChainID uint64, asset, toAddress []byte, amount *big.Int, id []byte) error {
+	route, err := getFeeRoute(native, toChainID)
+	if err != nil {
+		return fmt.Errorf("makeUnlock, %v", err)
+	}
+	if route == nil {
+		return fmt.Errorf("makeUnlock, no payout route on chain %d, pin one by UpdateFeeProxy", toChainID)
+	}
+
+	// the args of an unlock by lock proxy
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(asset)
+	sink.WriteVarBytes(toAddress)
+	sink.WriteBytes(make([]byte, 32))
+	args := scom.SetTransferAmount(sink.Bytes(), amount)
+	if args == nil {
+		return fmt.Errorf("makeUnlock, amount %s doesn't fit in the transfer args", amount.String())
+	}
+	txHash := native.GetTx().Hash()
+	crossChainID := sha256.Sum256(utils.ConcatKey(id, txHash[:]))
+	txParam := &scom.MakeTxParam{
+		TxHash:              txHash.ToArray(),
+		CrossChainID:        crossChainID[:],
+		FromContractAddress: route.FromContract,
+		ToChainID:           toChainID,
+		ToContractAddress:   route.ToContract,
+		Method:              "unlock",
+		Args:                args,
+	}
+	return MakeTransaction(native, txParam, route.FromChainID)
+}
+
+// payTreasury pays amount of asset on chainID out of the treasury to recipient on the chain
+func payTreasury(native *native.NativeService, chainID uint64, asset, recipient []byte, amount *big.Int) error {
+	if len(recipient) == 0 {
+		return fmt.Errorf("payTreasury, recipient is empty")
+	}
+	return makeUnlock(native, chainID, asset, recipient, amount, utils.ConcatKey(utils.TreasuryContractAddress,
+		utils.GetUint64Bytes(chainID), asset))
+}
+
+// creditRelayerFee adds amount of asset on chainID to the fees the relayer claims by ClaimFee
+func creditRelayerFee(native *native.NativeService, relayer common.Address, chainID uint64, asset []byte, amount *big.Int) error {
+	accrued, err := GetRelayerFee(native, relayer, chainID, asset)
+	if err != nil {
+		return err
+	}
+	accrued.Add(accrued, amount)
+	native.GetCacheDB().Put(relayerFeeKey(relayer, chainID, asset), cstates.GenRawStorageItem(accrued.Bytes()))
+	return nil
+}
+
+// GetFeeRateQuery returns the relay fee rate of the transfers to the chain, to be called by preExec
+func GetFeeRateQuery(native *native.NativeService) ([]byte, error) {
+	params := new(ToChainParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetFeeRateQuery, contract params deserialize error: %v", err)
+	}
+	rate, err := GetFeeRate(native, params.ToChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.GetUint64Bytes(rate), nil
+}
+
+// GetRelayerFeeQuery returns the fees settled to the relayer in big endian, to be called by preExec
+func GetRelayerFeeQuery(native *native.NativeService) ([]byte, error) {
+	params := new(RelayerFeeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerFeeQuery, contract params deserialize error: %v", err)
+	}
+	fee, err := GetRelayerFee(native, params.Relayer, params.ToChainID, params.Asset)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return fee.Bytes(), nil
+}
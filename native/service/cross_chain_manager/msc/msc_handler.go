@@ -64,7 +64,7 @@ func (h *Handler) MakeDepositProposal(service *native.NativeService) (*scom.Make
 	}
 
 	if err := scom.CheckDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
-		return nil, fmt.Errorf("msc MakeDepositProposal, check done transaction error:%s", err)
+		return nil, fmt.Errorf("msc MakeDepositProposal, check done transaction error:%w", err)
 	}
 	if err := scom.PutDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
 		return nil, fmt.Errorf("msc MakeDepositProposal, PutDoneTx error:%s", err)
@@ -78,11 +78,18 @@ func verifyFromTx(native *native.NativeService, proof, extra []byte, fromChainID
 		return
 	}
 
+	blocksToWait, err := side_chain_manager.GetRequiredConfirmations(native, sideChain, scom.GetTransferAmount(extra))
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromTx, get required confirmations error:%s", err)
+	}
 	cheight32 := uint32(cheight)
 
-	if cheight32 < height || cheight32-height < uint32(sideChain.BlocksToWait-1) {
+	if cheight32 < height || cheight32-height < uint32(blocksToWait-1) {
 		return nil, fmt.Errorf("verifyFromTx, transaction is not confirmed, current height: %d, input height: %d", cheight, height)
 	}
+	if err := side_chain_manager.CheckProofAge(native, fromChainID, uint64(height), cheight); err != nil {
+		return nil, fmt.Errorf("verifyFromTx, %v", err)
+	}
 
 	headerWithSum, err := msc.GetCanonicalHeader(native, fromChainID, uint64(height))
 	if err != nil {
@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package neo
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/testsupport"
+	hs "github.com/polynetwork/poly/native/service/header_sync/neo"
+	"github.com/polynetwork/poly/native/storage"
+)
+
+func TestConformance(t *testing.T) {
+	tx := &types.Transaction{
+		SignedAddr: []common.Address{acct.Address},
+	}
+	sink := common.NewZeroCopySink(nil)
+	entranceParam().Serialization(sink)
+
+	testsupport.Run(t, &testsupport.Fixture{
+		Handler: NewNEOHandler(),
+		Setup: func(t *testing.T, db *storage.CacheDB) {
+			ns := NewNative(genesisHeaderParam(), tx, db)
+			if err := hs.NewNEOHandler().SyncGenesisHeader(ns); err != nil {
+				t.Fatalf("SyncGenesisHeader error: %v", err)
+			}
+			SetContract(ns, "b0d4f20da68a6007d4fb7eac374b5566a5b0e229")
+		},
+		Tx:        tx,
+		Valid:     sink.Bytes(),
+		Malformed: testsupport.MalformedEntrances(sink.Bytes()),
+	})
+}
@@ -18,21 +18,20 @@ package neo
 
 import (
 	"bytes"
-	"encoding/hex"
 	"fmt"
-	"github.com/joeqian10/neo-gogogo/helper"
 	"github.com/joeqian10/neo-gogogo/mpt"
 	"github.com/polynetwork/poly/common"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/header_sync/neo"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 func verifyFromNeoTx(proof []byte, crosschainMsg *neo.NeoCrossChainMsg, contractAddr []byte) (*scom.MakeTxParam, error) {
-	crossStateProofRoot, err := helper.UInt256FromString(crosschainMsg.StateRoot.StateRoot)
+	crossStateProofRoot, err := utils.ParseDisplayHash(crosschainMsg.StateRoot.StateRoot, common.UINT256_SIZE)
 	if err != nil {
 		return nil, fmt.Errorf("verifyFromNeoTx, decode cross state proof root from string error:%s", err)
 	}
-	value, err := VerifyNeoCrossChainProof(proof, crossStateProofRoot.Bytes(), contractAddr)
+	value, err := VerifyNeoCrossChainProof(proof, crossStateProofRoot, contractAddr)
 	if err != nil {
 		return nil, fmt.Errorf("VerifyFromNeoTx, Verify Neo cross chain proof error:%v", err)
 	}
@@ -50,7 +49,7 @@ func VerifyNeoCrossChainProof(proof []byte, stateRoot []byte, contractAddr []byt
 		return nil, fmt.Errorf("VerifyNeoCrossChainProof, joeqian10/neo-gogogo/mpt.ResolveProof error:%v", err)
 	}
 	if !bytes.Equal(scriptHash.Bytes(), contractAddr) {
-		return nil, fmt.Errorf("VerifyNeoCrossChainProof, error:scriptHash is not CCMC contract address, expected:%s, but got %s", hex.EncodeToString(common.ToArrayReverse(contractAddr)), scriptHash.String())
+		return nil, fmt.Errorf("VerifyNeoCrossChainProof, error:scriptHash is not CCMC contract address, expected:%s, but got %s", utils.FormatDisplayHash(contractAddr), scriptHash.String())
 	}
 	value, err := mpt.VerifyProof(stateRoot, scriptHash, key, proofs)
 	if err != nil {
@@ -51,7 +51,8 @@ func (this *OKHandler) MakeDepositProposal(service *native.NativeService) (*scom
 	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
 		return nil, fmt.Errorf("okex MakeDepositProposal, contract params deserialize error: %s", err)
 	}
-	info, err := okex.GetEpochSwitchInfo(service, params.SourceChainID)
+	// the proof is verified by the validators of its height, which may have switched since
+	info, err := okex.GetEpochSwitchInfoByHeight(service, params.SourceChainID, int64(params.Height))
 	if err != nil {
 		return nil, fmt.Errorf("okex MakeDepositProposal, failed to get epoch switching height: %v", err)
 	}
@@ -75,14 +76,21 @@ func (this *OKHandler) MakeDepositProposal(service *native.NativeService) (*scom
 	if err = okex.VerifyCosmosHeader(&myHeader, info); err != nil {
 		return nil, fmt.Errorf("okex MakeDepositProposal, failed to verify okex header: %v", err)
 	}
+	current, err := okex.GetEpochSwitchInfo(service, params.SourceChainID)
+	if err != nil {
+		return nil, fmt.Errorf("okex MakeDepositProposal, failed to get epoch switching height: %v", err)
+	}
 	if !bytes.Equal(myHeader.Header.ValidatorsHash, myHeader.Header.NextValidatorsHash) &&
-		myHeader.Header.Height > info.Height {
-		okex.PutEpochSwitchInfo(service, params.SourceChainID, &okex.CosmosEpochSwitchInfo{
+		myHeader.Header.Height > current.Height {
+		err = okex.PutEpochSwitchInfo(service, params.SourceChainID, &okex.CosmosEpochSwitchInfo{
 			Height:             myHeader.Header.Height,
 			BlockHash:          myHeader.Header.Hash(),
 			NextValidatorsHash: myHeader.Header.NextValidatorsHash,
 			ChainID:            myHeader.Header.ChainID,
 		})
+		if err != nil {
+			return nil, fmt.Errorf("okex MakeDepositProposal, %v", err)
+		}
 	}
 
 	var proofValue CosmosProofValue
@@ -125,7 +133,7 @@ func (this *OKHandler) MakeDepositProposal(service *native.NativeService) (*scom
 		return nil, fmt.Errorf("Cosmos MakeDepositProposal, deserialize merkleValue error:%s", err)
 	}
 	if err := scom.CheckDoneTx(service, txParam.CrossChainID, params.SourceChainID); err != nil {
-		return nil, fmt.Errorf("Cosmos MakeDepositProposal, check done transaction error:%s", err)
+		return nil, fmt.Errorf("Cosmos MakeDepositProposal, check done transaction error:%w", err)
 	}
 	if err := scom.PutDoneTx(service, txParam.CrossChainID, params.SourceChainID); err != nil {
 		return nil, fmt.Errorf("Cosmos MakeDepositProposal, PutDoneTx error:%s", err)
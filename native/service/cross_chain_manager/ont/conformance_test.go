@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ont
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/testsupport"
+)
+
+// there is no synced ontology fixture yet, so only the rejection cases run
+func TestConformance(t *testing.T) {
+	params := &scom.EntranceParam{
+		SourceChainID:         3,
+		Height:                100,
+		Proof:                 []byte{0x80, 0x00, 0x00, 0x00},
+		RelayerAddress:        common.ADDRESS_EMPTY[:],
+		HeaderOrCrossChainMsg: []byte{0x00},
+	}
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	malformed := testsupport.MalformedEntrances(sink.Bytes())
+	malformed["unsynced chain"] = sink.Bytes()
+
+	testsupport.Run(t, &testsupport.Fixture{
+		Handler:   NewONTHandler(),
+		Malformed: malformed,
+	})
+}
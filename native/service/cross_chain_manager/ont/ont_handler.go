@@ -26,7 +26,9 @@ import (
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/native"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/ont"
+	"github.com/polynetwork/poly/native/service/precompile"
 )
 
 type ONTHandler struct {
@@ -76,12 +78,20 @@ func (this *ONTHandler) MakeDepositProposal(service *native.NativeService) (*sco
 		}
 	}
 
-	value, err := VerifyFromOntTx(params.Proof, crossChainMsg)
+	hashName, err := side_chain_manager.GetMerkleHash(service, params.SourceChainID)
+	if err != nil {
+		return nil, fmt.Errorf("ont MakeDepositProposal, %v", err)
+	}
+	hashFunc, err := precompile.Hash("cross_chain_manager/ont", hashName)
+	if err != nil {
+		return nil, fmt.Errorf("ont MakeDepositProposal, %v", err)
+	}
+	value, err := VerifyFromOntTx(params.Proof, crossChainMsg, hashFunc)
 	if err != nil {
 		return nil, fmt.Errorf("ont MakeDepositProposal, VerifyOntTx error: %v", err)
 	}
 	if err := scom.CheckDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
-		return nil, fmt.Errorf("ont MakeDepositProposal, check done transaction error:%s", err)
+		return nil, fmt.Errorf("ont MakeDepositProposal, check done transaction error:%w", err)
 	}
 	if err = scom.PutDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
 		return nil, fmt.Errorf("VerifyFromOntTx, putDoneTx error:%s", err)
@@ -23,10 +23,13 @@ import (
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/merkle"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/precompile"
 )
 
-func VerifyFromOntTx(proof []byte, crossChainMsg *otypes.CrossChainMsg) (*scom.MakeTxParam, error) {
-	v, err := merkle.MerkleProve(proof, crossChainMsg.StatesRoot.ToArray())
+// VerifyFromOntTx verifies the merkle proof against the states root of crossChainMsg with the merkle
+// hash of the source chain
+func VerifyFromOntTx(proof []byte, crossChainMsg *otypes.CrossChainMsg, hashFunc precompile.HashFunc) (*scom.MakeTxParam, error) {
+	v, err := merkle.MerkleProveWithHash(proof, crossChainMsg.StatesRoot.ToArray(), hashFunc)
 	if err != nil {
 		return nil, fmt.Errorf("VerifyFromOntTx, merkle.MerkleProve verify merkle proof error")
 	}
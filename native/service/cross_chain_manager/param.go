@@ -20,6 +20,7 @@ package cross_chain_manager
 import (
 	"fmt"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
 )
 
 type BlackChainParam struct {
@@ -39,3 +40,817 @@ func (this *BlackChainParam) Deserialization(source *common.ZeroCopySource) erro
 	this.ChainID = chainID
 	return nil
 }
+
+type SignReceiptParam struct {
+	ToChainID    uint64
+	CrossChainID []byte
+	PeerPubkey   string
+	Sig          []byte
+}
+
+func (this *SignReceiptParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ToChainID)
+	sink.WriteVarBytes(this.CrossChainID)
+	sink.WriteString(this.PeerPubkey)
+	sink.WriteVarBytes(this.Sig)
+}
+
+func (this *SignReceiptParam) Deserialization(source *common.ZeroCopySource) error {
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("SignReceiptParam deserialize to chain id error")
+	}
+	crossChainID, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SignReceiptParam deserialize cross chain id error")
+	}
+	peerPubkey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("SignReceiptParam deserialize peer pubkey error")
+	}
+	sig, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SignReceiptParam deserialize signature error")
+	}
+
+	this.ToChainID = toChainID
+	this.CrossChainID = crossChainID
+	this.PeerPubkey = peerPubkey
+	this.Sig = sig
+	return nil
+}
+
+type TransferCountParam struct {
+	FromChainID uint64
+	ToChainID   uint64
+}
+
+func (this *TransferCountParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.FromChainID)
+	sink.WriteVarUint(this.ToChainID)
+}
+
+func (this *TransferCountParam) Deserialization(source *common.ZeroCopySource) error {
+	fromChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("TransferCountParam deserialize from chain id error")
+	}
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("TransferCountParam deserialize to chain id error")
+	}
+
+	this.FromChainID = fromChainID
+	this.ToChainID = toChainID
+	return nil
+}
+
+type AssetVolumeParam struct {
+	ToChainID uint64
+	Asset     []byte
+}
+
+func (this *AssetVolumeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ToChainID)
+	sink.WriteVarBytes(this.Asset)
+}
+
+func (this *AssetVolumeParam) Deserialization(source *common.ZeroCopySource) error {
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("AssetVolumeParam deserialize to chain id error")
+	}
+	asset, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("AssetVolumeParam deserialize asset error")
+	}
+
+	this.ToChainID = toChainID
+	this.Asset = asset
+	return nil
+}
+
+type FailureCountParam struct {
+	Reason string
+}
+
+func (this *FailureCountParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Reason)
+}
+
+func (this *FailureCountParam) Deserialization(source *common.ZeroCopySource) error {
+	reason, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("FailureCountParam deserialize reason error")
+	}
+
+	this.Reason = reason
+	return nil
+}
+
+type EpochSummaryParam struct {
+	View uint32
+}
+
+func (this *EpochSummaryParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.View)
+}
+
+func (this *EpochSummaryParam) Deserialization(source *common.ZeroCopySource) error {
+	view, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("EpochSummaryParam deserialize view error")
+	}
+
+	this.View = view
+	return nil
+}
+
+type SendAdminMessageParam struct {
+	ToChainID uint64
+	Type      uint8
+	Args      []byte
+	Address   common.Address
+}
+
+func (this *SendAdminMessageParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ToChainID)
+	sink.WriteUint8(this.Type)
+	sink.WriteVarBytes(this.Args)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *SendAdminMessageParam) Deserialization(source *common.ZeroCopySource) error {
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("SendAdminMessageParam deserialize to chain id error")
+	}
+	typ, eof := source.NextUint8()
+	if eof {
+		return fmt.Errorf("SendAdminMessageParam deserialize type error")
+	}
+	args, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SendAdminMessageParam deserialize args error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SendAdminMessageParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("SendAdminMessageParam deserialize address error: %v", err)
+	}
+
+	this.ToChainID = toChainID
+	this.Type = typ
+	this.Args = args
+	this.Address = addr
+	return nil
+}
+
+// ToChainParam is the param of the queries about a destination chain
+type ToChainParam struct {
+	ToChainID uint64
+}
+
+func (this *ToChainParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ToChainID)
+}
+
+func (this *ToChainParam) Deserialization(source *common.ZeroCopySource) error {
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ToChainParam deserialize to chain id error")
+	}
+
+	this.ToChainID = toChainID
+	return nil
+}
+
+// BindAssetParam binds the asset hash on ChainID to the asset named Asset, so that transfers of
+// it are counted in the supply of the asset
+type BindAssetParam struct {
+	Asset     string
+	ChainID   uint64
+	AssetHash []byte
+	Address   common.Address
+}
+
+func (this *BindAssetParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Asset)
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarBytes(this.AssetHash)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *BindAssetParam) Deserialization(source *common.ZeroCopySource) error {
+	asset, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("BindAssetParam deserialize asset error")
+	}
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("BindAssetParam deserialize chain id error")
+	}
+	assetHash, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BindAssetParam deserialize asset hash error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BindAssetParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("BindAssetParam deserialize address error: %v", err)
+	}
+
+	this.Asset = asset
+	this.ChainID = chainID
+	this.AssetHash = assetHash
+	this.Address = addr
+	return nil
+}
+
+// FreezeAssetParam is the param of FreezeAsset and UnfreezeAsset
+type FreezeAssetParam struct {
+	Asset   string
+	Address common.Address
+}
+
+func (this *FreezeAssetParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Asset)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *FreezeAssetParam) Deserialization(source *common.ZeroCopySource) error {
+	asset, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("FreezeAssetParam deserialize asset error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("FreezeAssetParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("FreezeAssetParam deserialize address error: %v", err)
+	}
+
+	this.Asset = asset
+	this.Address = addr
+	return nil
+}
+
+// AssetParam is the param of the queries about an asset
+type AssetParam struct {
+	Asset string
+}
+
+func (this *AssetParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Asset)
+}
+
+func (this *AssetParam) Deserialization(source *common.ZeroCopySource) error {
+	asset, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("AssetParam deserialize asset error")
+	}
+
+	this.Asset = asset
+	return nil
+}
+
+// PendingCommitParam names the cross chain tx from FromChainID marked done by DoneID
+type PendingCommitParam struct {
+	FromChainID uint64
+	DoneID      []byte
+}
+
+func (this *PendingCommitParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.FromChainID)
+	sink.WriteVarBytes(this.DoneID)
+}
+
+func (this *PendingCommitParam) Deserialization(source *common.ZeroCopySource) error {
+	fromChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("PendingCommitParam deserialize from chain id error")
+	}
+	doneID, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("PendingCommitParam deserialize done id error")
+	}
+
+	this.FromChainID = fromChainID
+	this.DoneID = doneID
+	return nil
+}
+
+// RefundPendingCommitParam drops the pending commit of the cross chain tx so that it's refunded on the source chain
+type RefundPendingCommitParam struct {
+	FromChainID uint64
+	DoneID      []byte
+	Address     common.Address
+}
+
+func (this *RefundPendingCommitParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.FromChainID)
+	sink.WriteVarBytes(this.DoneID)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *RefundPendingCommitParam) Deserialization(source *common.ZeroCopySource) error {
+	fromChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("RefundPendingCommitParam deserialize from chain id error")
+	}
+	doneID, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RefundPendingCommitParam deserialize done id error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RefundPendingCommitParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("RefundPendingCommitParam deserialize address error: %v", err)
+	}
+
+	this.FromChainID = fromChainID
+	this.DoneID = doneID
+	this.Address = addr
+	return nil
+}
+
+type BtcUtxosParam struct {
+	ChainID   uint64
+	RedeemKey string
+}
+
+func (this *BtcUtxosParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteString(this.RedeemKey)
+}
+
+func (this *BtcUtxosParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("BtcUtxosParam deserialize chain id error")
+	}
+	redeemKey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("BtcUtxosParam deserialize redeem key error")
+	}
+
+	this.ChainID = chainID
+	this.RedeemKey = redeemKey
+	return nil
+}
+
+// CorrectBtcUtxosParam replaces the utxos tracked for the redeem of the chain with Utxos
+type CorrectBtcUtxosParam struct {
+	ChainID   uint64
+	RedeemKey string
+	Utxos     *btc.Utxos
+	Address   common.Address
+}
+
+func (this *CorrectBtcUtxosParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteString(this.RedeemKey)
+	this.Utxos.Serialization(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *CorrectBtcUtxosParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("CorrectBtcUtxosParam deserialize chain id error")
+	}
+	redeemKey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("CorrectBtcUtxosParam deserialize redeem key error")
+	}
+	utxos := new(btc.Utxos)
+	if err := utxos.Deserialization(source); err != nil {
+		return fmt.Errorf("CorrectBtcUtxosParam deserialize utxos error: %v", err)
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("CorrectBtcUtxosParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("CorrectBtcUtxosParam, common.AddressParseFromBytes error: %s", err)
+	}
+
+	this.ChainID = chainID
+	this.RedeemKey = redeemKey
+	this.Utxos = utxos
+	this.Address = addr
+	return nil
+}
+
+// BlockRecipientParam adds Recipient on ChainID to the blocklist, or removes it if Blocked is false
+type BlockRecipientParam struct {
+	ChainID   uint64
+	Recipient []byte
+	Blocked   bool
+	Address   common.Address
+}
+
+func (this *BlockRecipientParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarBytes(this.Recipient)
+	sink.WriteBool(this.Blocked)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *BlockRecipientParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("BlockRecipientParam deserialize chain id error")
+	}
+	recipient, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BlockRecipientParam deserialize recipient error")
+	}
+	blocked, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("BlockRecipientParam deserialize blocked error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BlockRecipientParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("BlockRecipientParam deserialize address error: %v", err)
+	}
+
+	this.ChainID = chainID
+	this.Recipient = recipient
+	this.Blocked = blocked
+	this.Address = addr
+	return nil
+}
+
+// RecipientParam is the param of the queries about a recipient on a chain
+type RecipientParam struct {
+	ChainID   uint64
+	Recipient []byte
+}
+
+func (this *RecipientParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarBytes(this.Recipient)
+}
+
+func (this *RecipientParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("RecipientParam deserialize chain id error")
+	}
+	recipient, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RecipientParam deserialize recipient error")
+	}
+
+	this.ChainID = chainID
+	this.Recipient = recipient
+	return nil
+}
+
+type FailedImportsParam struct {
+	Count uint64
+}
+
+func (this *FailedImportsParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.Count)
+}
+
+func (this *FailedImportsParam) Deserialization(source *common.ZeroCopySource) error {
+	count, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("FailedImportsParam deserialize count error")
+	}
+
+	this.Count = count
+	return nil
+}
+
+type ContractCrossChainParam struct {
+	ToChainID         uint64
+	ToContractAddress []byte
+	Method            string
+	Args              []byte
+}
+
+func (this *ContractCrossChainParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ToChainID)
+	sink.WriteVarBytes(this.ToContractAddress)
+	sink.WriteString(this.Method)
+	sink.WriteVarBytes(this.Args)
+}
+
+func (this *ContractCrossChainParam) Deserialization(source *common.ZeroCopySource) error {
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ContractCrossChainParam deserialize to chain id error")
+	}
+	toContractAddress, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ContractCrossChainParam deserialize to contract address error")
+	}
+	method, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("ContractCrossChainParam deserialize method error")
+	}
+	args, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ContractCrossChainParam deserialize args error")
+	}
+
+	this.ToChainID = toChainID
+	this.ToContractAddress = toContractAddress
+	this.Method = method
+	this.Args = args
+	return nil
+}
+
+type AllowContractParam struct {
+	Contract common.Address
+	ChainID  uint64
+	Allowed  bool
+	Address  common.Address
+}
+
+func (this *AllowContractParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Contract[:])
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteBool(this.Allowed)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *AllowContractParam) Deserialization(source *common.ZeroCopySource) error {
+	contract, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("AllowContractParam deserialize contract error")
+	}
+	contractAddr, err := common.AddressParseFromBytes(contract)
+	if err != nil {
+		return fmt.Errorf("AllowContractParam deserialize contract error: %v", err)
+	}
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("AllowContractParam deserialize chain id error")
+	}
+	allowed, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("AllowContractParam deserialize allowed error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("AllowContractParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("AllowContractParam deserialize address error: %v", err)
+	}
+
+	this.Contract = contractAddr
+	this.ChainID = chainID
+	this.Allowed = allowed
+	this.Address = addr
+	return nil
+}
+
+// ContractChainParam is the param of the queries about a contract on poly and a target chain
+type ContractChainParam struct {
+	Contract common.Address
+	ChainID  uint64
+}
+
+func (this *ContractChainParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Contract[:])
+	sink.WriteVarUint(this.ChainID)
+}
+
+func (this *ContractChainParam) Deserialization(source *common.ZeroCopySource) error {
+	contract, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ContractChainParam deserialize contract error")
+	}
+	addr, err := common.AddressParseFromBytes(contract)
+	if err != nil {
+		return fmt.Errorf("ContractChainParam deserialize contract error: %v", err)
+	}
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ContractChainParam deserialize chain id error")
+	}
+
+	this.Contract = addr
+	this.ChainID = chainID
+	return nil
+}
+
+// UpdateFeeParam sets the relay fee of the transfers to ChainID to Rate ten-thousandths of the
+// transferred amount, a zero Rate removes the fee
+type UpdateFeeParam struct {
+	ChainID uint64
+	Rate    uint64
+	Address common.Address
+}
+
+func (this *UpdateFeeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarUint(this.Rate)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *UpdateFeeParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("UpdateFeeParam deserialize chain id error")
+	}
+	rate, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("UpdateFeeParam deserialize rate error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("UpdateFeeParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("UpdateFeeParam deserialize address error: %v", err)
+	}
+
+	this.ChainID = chainID
+	this.Rate = rate
+	this.Address = addr
+	return nil
+}
+
+// RelayerFeeParam is the param of the query about the fees of an asset on ToChainID settled to a relayer
+type RelayerFeeParam struct {
+	Relayer   common.Address
+	ToChainID uint64
+	Asset     []byte
+}
+
+func (this *RelayerFeeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Relayer[:])
+	sink.WriteVarUint(this.ToChainID)
+	sink.WriteVarBytes(this.Asset)
+}
+
+func (this *RelayerFeeParam) Deserialization(source *common.ZeroCopySource) error {
+	relayer, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RelayerFeeParam deserialize relayer error")
+	}
+	addr, err := common.AddressParseFromBytes(relayer)
+	if err != nil {
+		return fmt.Errorf("RelayerFeeParam deserialize relayer error: %v", err)
+	}
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("RelayerFeeParam deserialize to chain id error")
+	}
+	asset, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RelayerFeeParam deserialize asset error")
+	}
+
+	this.Relayer = addr
+	this.ToChainID = toChainID
+	this.Asset = asset
+	return nil
+}
+
+// ClaimFeeParam is the param of claiming the fees of an asset on ToChainID settled to Relayer, they're
+// paid to ToAddress on the chain
+type ClaimFeeParam struct {
+	Relayer   common.Address
+	ToChainID uint64
+	Asset     []byte
+	ToAddress []byte
+}
+
+func (this *ClaimFeeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Relayer[:])
+	sink.WriteVarUint(this.ToChainID)
+	sink.WriteVarBytes(this.Asset)
+	sink.WriteVarBytes(this.ToAddress)
+}
+
+func (this *ClaimFeeParam) Deserialization(source *common.ZeroCopySource) error {
+	relayer, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ClaimFeeParam deserialize relayer error")
+	}
+	addr, err := common.AddressParseFromBytes(relayer)
+	if err != nil {
+		return fmt.Errorf("ClaimFeeParam deserialize relayer error: %v", err)
+	}
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ClaimFeeParam deserialize to chain id error")
+	}
+	asset, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ClaimFeeParam deserialize asset error")
+	}
+	toAddress, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ClaimFeeParam deserialize to address error")
+	}
+
+	this.Relayer = addr
+	this.ToChainID = toChainID
+	this.Asset = asset
+	this.ToAddress = toAddress
+	return nil
+}
+
+// UpdateFeeProxyParam registers the lock proxy pair of Route to ToChainID as one charged the fee or
+// unregisters it, Payout pins the registered pair as the route the fees on ToChainID are paid out by
+type UpdateFeeProxyParam struct {
+	ToChainID uint64
+	Route     *FeeRoute
+	Allowed   bool
+	Payout    bool
+	Address   common.Address
+}
+
+func (this *UpdateFeeProxyParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ToChainID)
+	this.Route.Serialization(sink)
+	sink.WriteBool(this.Allowed)
+	sink.WriteBool(this.Payout)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *UpdateFeeProxyParam) Deserialization(source *common.ZeroCopySource) error {
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("UpdateFeeProxyParam deserialize to chain id error")
+	}
+	route := new(FeeRoute)
+	if err := route.Deserialization(source); err != nil {
+		return fmt.Errorf("UpdateFeeProxyParam deserialize route error: %v", err)
+	}
+	allowed, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("UpdateFeeProxyParam deserialize allowed error")
+	}
+	payout, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("UpdateFeeProxyParam deserialize payout error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("UpdateFeeProxyParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("UpdateFeeProxyParam deserialize address error: %v", err)
+	}
+
+	this.ToChainID = toChainID
+	this.Route = route
+	this.Allowed = allowed
+	this.Payout = payout
+	this.Address = addr
+	return nil
+}
+
+type CheckChainParam struct {
+	ChainID uint64
+	// the header of the chain synced more poly blocks ago than MaxLag fails the check, 0 skips it
+	MaxLag uint32
+}
+
+func (this *CheckChainParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteUint32(this.MaxLag)
+}
+
+func (this *CheckChainParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("CheckChainParam deserialize chain id error")
+	}
+	maxLag, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("CheckChainParam deserialize max lag error")
+	}
+	this.ChainID = chainID
+	this.MaxLag = maxLag
+	return nil
+}
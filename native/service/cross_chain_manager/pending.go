@@ -0,0 +1,220 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func pendingCommitKey(fromChainID uint64, doneID []byte) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(PENDING_COMMIT),
+		utils.GetUint64Bytes(fromChainID), doneID)
+}
+
+func putPendingCommit(native *native.NativeService, pending *PendingCommit) {
+	sink := common.NewZeroCopySink(nil)
+	pending.Serialization(sink)
+	native.GetCacheDB().Put(pendingCommitKey(pending.FromChainID, pending.DoneID), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// GetPendingCommit returns nil if the cross chain tx from fromChainID marked done by doneID is not pending commit
+func GetPendingCommit(native *native.NativeService, fromChainID uint64, doneID []byte) (*PendingCommit, error) {
+	store, err := native.GetCacheDB().Get(pendingCommitKey(fromChainID, doneID))
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingCommit, get pending commit error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingCommit, deserialize from raw storage item error: %v", err)
+	}
+	pending := new(PendingCommit)
+	if err := pending.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetPendingCommit, deserialize PendingCommit error: %v", err)
+	}
+	return pending, nil
+}
+
+// transientFailures are the reasons of the failures to make the target tx that governance lifts later, the
+// cross chain txs failing for them are kept pending commit, the ones failing for others are not marked done.
+var transientFailures = map[string]bool{
+	FAILURE_BLACKED: true,
+	FAILURE_FROZEN:  true,
+}
+
+// verifiedError is a failure of ImportOuterTransfer after the cross chain tx is verified and marked done, it
+// carries the tx and the writes of the verification out of the failed invocation to the failure hook
+type verifiedError struct {
+	fromChainID uint64
+	doneID      []byte
+	txParam     *scom.MakeTxParam
+	writes      *overlaydb.MemDB
+	cause       error
+}
+
+func (this *verifiedError) Error() string {
+	return this.cause.Error()
+}
+
+func (this *verifiedError) Unwrap() error {
+	return this.cause
+}
+
+// keepPendingCommit keeps the cross chain tx of a failed ImportOuterTransfer marked done and pending commit
+// if making the target tx failed for a transient reason, instead of it being verified again and failing until
+// then. A transfer to a blocked recipient fails after being marked done, it stays done and the attempt is
+// recorded instead. The writes of the verification are restored as the failed invocation made them.
+func keepPendingCommit(native *native.NativeService, cause error) {
+	var verified *verifiedError
+	if !errors.As(cause, &verified) {
+		return
+	}
+	var blocked *RecipientBlockedError
+	if errors.As(verified.cause, &blocked) {
+		native.GetCacheDB().Restore(verified.writes)
+		recordBlockedAttempt(native, blocked)
+		return
+	}
+	reason := failureReason(verified.cause)
+	if !transientFailures[reason] {
+		return
+	}
+	native.GetCacheDB().Restore(verified.writes)
+	putPendingCommit(native, &PendingCommit{
+		FromChainID: verified.fromChainID,
+		DoneID:      verified.doneID,
+		MakeTxParam: verified.txParam,
+		Reason:      reason,
+		Height:      native.GetHeight(),
+	})
+	recordPendingDeposit(native, verified.fromChainID)
+}
+
+// RetryCommit makes the tx to the target chain of a cross chain tx pending commit, the pending commit
+// is kept if it fails again, anyone can retry.
+func RetryCommit(native *native.NativeService) ([]byte, error) {
+	params := new(PendingCommitParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RetryCommit, contract params deserialize error: %v", err)
+	}
+	pending, err := GetPendingCommit(native, params.FromChainID, params.DoneID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RetryCommit, %v", err)
+	}
+	if pending == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RetryCommit, cross chain tx %x from chain %d is not pending commit",
+			params.DoneID, params.FromChainID)
+	}
+	// the recipient may be blocked after the tx was kept pending, it's left for the refund then
+	if err := checkRecipient(native, pending.FromChainID, pending.DoneID, pending.MakeTxParam); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RetryCommit, %v", err)
+	}
+	native.GetCacheDB().Delete(pendingCommitKey(params.FromChainID, params.DoneID))
+	btc.DeleteDepositBlock(native, params.FromChainID, params.DoneID)
+	if err := commitTransfer(native, pending.FromChainID, pending.MakeTxParam); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RetryCommit, %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"retryCommit", params.FromChainID, hex.EncodeToString(params.DoneID)},
+			FromChainID:     event.ChainID(params.FromChainID),
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// RefundPendingCommit drops a cross chain tx pending commit once the consensus peers approve it, the event
+// carries the transfer for the source chain to refund it.
+func RefundPendingCommit(native *native.NativeService) ([]byte, error) {
+	params := new(RefundPendingCommitParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RefundPendingCommit, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RefundPendingCommit, checkWitness error: %v", err)
+	}
+
+	pending, err := GetPendingCommit(native, params.FromChainID, params.DoneID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RefundPendingCommit, %v", err)
+	}
+	if pending == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RefundPendingCommit, cross chain tx %x from chain %d is not pending commit",
+			params.DoneID, params.FromChainID)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(params.FromChainID)
+	sink.WriteVarBytes(params.DoneID)
+	ok, err := node_manager.CheckConsensusSigns(native, REFUND_PENDING_COMMIT, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RefundPendingCommit, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	native.GetCacheDB().Delete(pendingCommitKey(params.FromChainID, params.DoneID))
+	btc.DeleteDepositBlock(native, params.FromChainID, params.DoneID)
+	txParam := pending.MakeTxParam
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{"refundPendingCommit", params.FromChainID, hex.EncodeToString(params.DoneID),
+				hex.EncodeToString(txParam.TxHash), hex.EncodeToString(txParam.FromContractAddress),
+				txParam.ToChainID, hex.EncodeToString(txParam.Args)},
+			FromChainID: event.ChainID(params.FromChainID),
+			ToChainID:   event.ChainID(txParam.ToChainID),
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+func GetPendingCommitQuery(native *native.NativeService) ([]byte, error) {
+	params := new(PendingCommitParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetPendingCommitQuery, contract params deserialize error: %v", err)
+	}
+	pending, err := GetPendingCommit(native, params.FromChainID, params.DoneID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if pending == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetPendingCommitQuery, cross chain tx %x from chain %d is not pending commit",
+			params.DoneID, params.FromChainID)
+	}
+	sink := common.NewZeroCopySink(nil)
+	pending.Serialization(sink)
+	return sink.Bytes(), nil
+}
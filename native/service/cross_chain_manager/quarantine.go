@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	// FAILED_IMPORT_LOG_SIZE is the number of the recent failed imports kept, the older ones are overwritten
+	FAILED_IMPORT_LOG_SIZE uint64 = 1000
+)
+
+func failedImportKey(index uint64) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(FAILED_IMPORT),
+		utils.GetUint64Bytes(index%FAILED_IMPORT_LOG_SIZE))
+}
+
+// logFailedImport appends the failed ImportOuterTransfer to the ring buffer of the failed imports,
+// the imports which can't be parsed are logged with the tx hash and reason only. The error message
+// isn't kept, it depends on the versions of the node and its dependencies while the log is in the state.
+func logFailedImport(native *native.NativeService, reason string) {
+	count, err := GetFailedImportCount(native)
+	if err != nil {
+		return
+	}
+	entry := &FailedImport{
+		Index:  count,
+		Height: native.GetHeight(),
+		TxHash: native.GetTx().Hash(),
+		Reason: reason,
+	}
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err == nil {
+		entry.ChainID = params.SourceChainID
+		entry.ProofHash = sha256.Sum256(params.Proof)
+		entry.Relayer = params.RelayerAddress
+	}
+	sink := common.NewZeroCopySink(nil)
+	entry.Serialization(sink)
+	native.GetCacheDB().Put(failedImportKey(count), cstates.GenRawStorageItem(sink.Bytes()))
+	native.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(FAILED_IMPORT_COUNT)),
+		cstates.GenRawStorageItem(utils.GetUint64Bytes(count+1)))
+}
+
+// GetFailedImportCount returns the number of the failed imports ever logged
+func GetFailedImportCount(native *native.NativeService) (uint64, error) {
+	raw, err := getCounter(native, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(FAILED_IMPORT_COUNT)))
+	if err != nil {
+		return 0, fmt.Errorf("GetFailedImportCount, %v", err)
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+// GetFailedImports returns the count most recent failed imports still in the log, the latest first
+func GetFailedImports(native *native.NativeService, count uint64) ([]*FailedImport, error) {
+	total, err := GetFailedImportCount(native)
+	if err != nil {
+		return nil, fmt.Errorf("GetFailedImports, %v", err)
+	}
+	if count > total {
+		count = total
+	}
+	if count > FAILED_IMPORT_LOG_SIZE {
+		count = FAILED_IMPORT_LOG_SIZE
+	}
+	items := make([]*FailedImport, 0, count)
+	for index := total; index > total-count; index-- {
+		raw, err := getCounter(native, failedImportKey(index-1))
+		if err != nil {
+			return nil, fmt.Errorf("GetFailedImports, %v", err)
+		}
+		item := new(FailedImport)
+		if err := item.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+			return nil, fmt.Errorf("GetFailedImports, deserialize failed import error: %v", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetFailedImportsQuery returns the given number of the most recent failed imports, to be called by preExec
+func GetFailedImportsQuery(native *native.NativeService) ([]byte, error) {
+	params := new(FailedImportsParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetFailedImportsQuery, contract params deserialize error: %v", err)
+	}
+	items, err := GetFailedImports(native, params.Count)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&FailedImports{Items: items}).Serialization(sink)
+	return sink.Bytes(), nil
+}
@@ -28,12 +28,31 @@ import (
 	"github.com/polynetwork/poly/native/service/header_sync/quorum"
 )
 
-type QuorumHandler struct{}
+type QuorumHandler struct {
+	qbft bool
+}
 
 func NewQuorumHandler() *QuorumHandler {
 	return &QuorumHandler{}
 }
 
+func NewQBFTHandler() *QuorumHandler {
+	return &QuorumHandler{qbft: true}
+}
+
+func (this *QuorumHandler) verifyHeader(vs quorum.QuorumValSet, header *types.Header) error {
+	if this.qbft {
+		if _, err := quorum.VerifyQBFTHeader(vs, header, false); err != nil {
+			return fmt.Errorf("failed to verify qbft header %s: %v", quorum.GetQBFTHeaderHash(header).String(), err)
+		}
+		return nil
+	}
+	if _, err := quorum.VerifyQuorumHeader(vs, header, false); err != nil {
+		return fmt.Errorf("failed to verify quorum header %s: %v", header.Hash().String(), err)
+	}
+	return nil
+}
+
 func (this *QuorumHandler) MakeDepositProposal(ns *native.NativeService) (*common.MakeTxParam, error) {
 	params := new(common.EntranceParam)
 	if err := params.Deserialization(pcom.NewZeroCopySource(ns.GetInput())); err != nil {
@@ -53,7 +72,7 @@ func (this *QuorumHandler) MakeDepositProposal(ns *native.NativeService) (*commo
 		return nil, fmt.Errorf("Quorum MakeDepositProposal, failed to deserialize MakeTxParam: %v", err)
 	}
 	if err := common.CheckDoneTx(ns, val.CrossChainID, params.SourceChainID); err != nil {
-		return nil, fmt.Errorf("Quorum MakeDepositProposal, check done transaction error: %v", err)
+		return nil, fmt.Errorf("Quorum MakeDepositProposal, check done transaction error: %w", err)
 	}
 	if err := common.PutDoneTx(ns, val.CrossChainID, params.SourceChainID); err != nil {
 		return nil, fmt.Errorf("Quorum MakeDepositProposal, PutDoneTx error: %v", err)
@@ -63,19 +82,22 @@ func (this *QuorumHandler) MakeDepositProposal(ns *native.NativeService) (*commo
 	if err := json.Unmarshal(params.HeaderOrCrossChainMsg, header); err != nil {
 		return nil, fmt.Errorf("Quorum MakeDepositProposal, deserialize header err: %v", err)
 	}
-	valh, err := quorum.GetCurrentValHeight(ns, params.SourceChainID)
-	if err != nil {
-		return nil, fmt.Errorf("Quorum MakeDepositProposal, failed to get current validators height: %v", err)
-	}
-	if header.Number.Uint64() < valh {
-		return nil, fmt.Errorf("Quorum MakeDepositProposal, height of header %d is less than epoch height %d", header.Number.Uint64(), valh)
-	}
-	vs, err := quorum.GetValSet(ns, params.SourceChainID)
+	// the header is verified by the validators of its height, which may have changed since
+	vs, ok, err := quorum.GetValSetByHeight(ns, params.SourceChainID, header.Number.Uint64())
 	if err != nil {
 		return nil, fmt.Errorf("Quorum MakeDepositProposal, failed to get quorum validators: %v", err)
 	}
-	if _, err := quorum.VerifyQuorumHeader(vs, header, false); err != nil {
-		return nil, fmt.Errorf("Quorum MakeDepositProposal, failed to verify quorum header %s: %v", header.Hash().String(), err)
+	if !ok {
+		valh, err := quorum.GetCurrentValHeight(ns, params.SourceChainID)
+		if err != nil {
+			return nil, fmt.Errorf("Quorum MakeDepositProposal, failed to get current validators height: %v", err)
+		}
+		if header.Number.Uint64() < valh {
+			return nil, fmt.Errorf("Quorum MakeDepositProposal, height of header %d is less than epoch height %d", header.Number.Uint64(), valh)
+		}
+	}
+	if err := this.verifyHeader(vs, header); err != nil {
+		return nil, fmt.Errorf("Quorum MakeDepositProposal, %v", err)
 	}
 
 	if err := verifyFromQuorumTx(params.Proof, params.Extra, header, sideChain); err != nil {
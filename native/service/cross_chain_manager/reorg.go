@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"encoding/hex"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func init() {
+	node_manager.RegisterViewChangeHook("cross_chain_manager/recheckPendingDeposits", node_manager.VIEW_CHANGE_HOUSEKEEPING,
+		recheckPendingDeposits)
+}
+
+// recordPendingDeposit records the block of the btc deposit in the input being kept pending commit, so that
+// the deposit can be reversed if the block leaves the canonical chain before the deposit is committed.
+func recordPendingDeposit(native *native.NativeService, chainID uint64) {
+	sideChain, err := side_chain_manager.GetSideChain(native, chainID)
+	if err != nil || sideChain == nil {
+		return
+	}
+	if sideChain.Router != utils.BTC_ROUTER && sideChain.Router != utils.BCH_ROUTER {
+		return
+	}
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return
+	}
+	if err := btc.PutDepositBlock(native, chainID, params.Height, params.Extra); err != nil {
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States:          []interface{}{"btcDepositBlockFailed", chainID, err.Error()},
+			})
+	}
+}
+
+// recheckPendingDeposits checks the blocks of the btc deposits pending commit once a view, the deposits
+// whose blocks were reorganized out of the chain are reversed and dropped, they can be proved again if
+// the new chain contains them as well. The deposits failing to be reversed are left pending.
+func recheckPendingDeposits(native *native.NativeService, view uint32) {
+	prefix := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(PENDING_COMMIT))
+	pendings := make([]*PendingCommit, 0)
+	iter := native.GetCacheDB().NewIterator(prefix)
+	for has := iter.First(); has; has = iter.Next() {
+		raw, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			continue
+		}
+		pending := new(PendingCommit)
+		if err := pending.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+			continue
+		}
+		pendings = append(pendings, pending)
+	}
+	iter.Release()
+	for _, pending := range pendings {
+		block, err := btc.GetDepositBlock(native, pending.FromChainID, pending.DoneID)
+		if err != nil || block == nil || block.IsCanonical(native, pending.FromChainID) {
+			continue
+		}
+		if err := btc.ReverseDeposit(native, pending.FromChainID, block, pending.MakeTxParam.ToChainID); err != nil {
+			native.AddNotify(
+				&event.NotifyEventInfo{
+					ContractAddress: utils.CrossChainManagerContractAddress,
+					States: []interface{}{"btcDepositReorgFailed", pending.FromChainID, hex.EncodeToString(pending.DoneID),
+						err.Error()},
+				})
+			continue
+		}
+		native.GetCacheDB().Delete(pendingCommitKey(pending.FromChainID, pending.DoneID))
+		scom.DeleteDoneTx(native, pending.DoneID, pending.FromChainID)
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States: []interface{}{"btcDepositReorged", pending.FromChainID, hex.EncodeToString(pending.DoneID),
+					block.Height, hex.EncodeToString(block.Hash)},
+			})
+	}
+}
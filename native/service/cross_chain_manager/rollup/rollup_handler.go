@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package rollup
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+)
+
+// RollupHandler verifies the cross chain transactions of an optimistic rollup, the
+// rollup headers are not synced, instead the L2 state root is proved by the output
+// committed to the output oracle on the synced L1 ethereum chain.
+type RollupHandler struct {
+}
+
+func NewRollupHandler() *RollupHandler {
+	return &RollupHandler{}
+}
+
+func (this *RollupHandler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return nil, fmt.Errorf("rollup MakeDepositProposal, contract params deserialize error: %s", err)
+	}
+
+	sideChain, err := side_chain_manager.GetSideChain(service, params.SourceChainID)
+	if err != nil {
+		return nil, fmt.Errorf("rollup MakeDepositProposal, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return nil, fmt.Errorf("rollup MakeDepositProposal, side chain %d is not registered", params.SourceChainID)
+	}
+	info := new(RollupInfo)
+	if err := json.Unmarshal(sideChain.ExtraInfo, info); err != nil {
+		return nil, fmt.Errorf("rollup MakeDepositProposal, ExtraInfo Unmarshal error: %v", err)
+	}
+
+	value, err := verifyFromRollupTx(service, params.Proof, params.Extra, params.Height, sideChain, info)
+	if err != nil {
+		return nil, fmt.Errorf("rollup MakeDepositProposal, verifyFromRollupTx error: %s", err)
+	}
+	if err := scom.CheckDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
+		return nil, fmt.Errorf("rollup MakeDepositProposal, check done transaction error:%w", err)
+	}
+	if err := scom.PutDoneTx(service, value.CrossChainID, params.SourceChainID); err != nil {
+		return nil, fmt.Errorf("rollup MakeDepositProposal, PutDoneTx error:%s", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package rollup
+
+import (
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/eth"
+)
+
+// RollupInfo is stored as the ExtraInfo of the rollup side chain
+type RollupInfo struct {
+	L1ChainID          uint64 // chain id of the L1 ethereum synced on poly
+	OutputOracle       string // address of the output oracle on L1
+	OutputsSlot        uint64 // storage slot of the l2Outputs array in the output oracle
+	FinalizationPeriod uint64 // seconds to wait before an output could be trusted
+}
+
+// RollupProof proves the storage of the cross chain manager on L2 through the
+// output l2Outputs[OutputIndex] which is proposed to the oracle at L1 height.
+type RollupProof struct {
+	OutputIndex              uint64       `json:"outputIndex"`
+	OutputRootProof          eth.ETHProof `json:"outputRootProof"`
+	OutputMetaProof          eth.ETHProof `json:"outputMetaProof"`
+	Version                  string       `json:"version"`
+	StateRoot                string       `json:"stateRoot"`
+	MessagePasserStorageRoot string       `json:"messagePasserStorageRoot"`
+	LatestBlockHash          string       `json:"latestBlockHash"`
+	StorageProof             eth.ETHProof `json:"storageProof"`
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package rollup
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	ecom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	ceth "github.com/polynetwork/poly/native/service/cross_chain_manager/eth"
+	cmanager "github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/header_sync/eth"
+)
+
+func verifyFromRollupTx(native *native.NativeService, proof, extra []byte, l1Height uint32, sideChain *cmanager.SideChain,
+	info *RollupInfo) (*scom.MakeTxParam, error) {
+	bestHeader, _, err := eth.GetCurrentHeader(native, info.L1ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromRollupTx, get current L1 header fail, error:%s", err)
+	}
+	blocksToWait, err := cmanager.GetRequiredConfirmations(native, sideChain, scom.GetTransferAmount(extra))
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromRollupTx, get required confirmations error:%s", err)
+	}
+	bestHeight := uint32(bestHeader.Number.Uint64())
+	if bestHeight < l1Height || bestHeight-l1Height < uint32(blocksToWait-1) {
+		return nil, fmt.Errorf("verifyFromRollupTx, output is not confirmed on L1, current height: %d, input height: %d",
+			bestHeight, l1Height)
+	}
+	l1Header, _, err := eth.GetHeaderByHeight(native, uint64(l1Height), info.L1ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromRollupTx, get L1 header by height, height:%d, error:%s", l1Height, err)
+	}
+
+	rollupProof := new(RollupProof)
+	if err := json.Unmarshal(proof, rollupProof); err != nil {
+		return nil, fmt.Errorf("verifyFromRollupTx, unmarshal proof error:%s", err)
+	}
+
+	//1. verify the output proposed to the oracle on L1
+	oracle := ecom.HexToAddress(info.OutputOracle)
+	rootSlot, metaSlot := outputSlots(info.OutputsSlot, rollupProof.OutputIndex)
+	outputRoot, err := verifyOracleSlot(&rollupProof.OutputRootProof, l1Header, oracle, rootSlot)
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromRollupTx, verify output root error:%v", err)
+	}
+	meta, err := verifyOracleSlot(&rollupProof.OutputMetaProof, l1Header, oracle, metaSlot)
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromRollupTx, verify output meta error:%v", err)
+	}
+	// the timestamp takes the lower 128 bits of the slot
+	timestamp := new(big.Int).SetBytes(meta[16:])
+	if !timestamp.IsUint64() || timestamp.Uint64()+info.FinalizationPeriod > l1Header.Time {
+		return nil, fmt.Errorf("verifyFromRollupTx, output %d proposed at %s is not finalized at L1 time %d",
+			rollupProof.OutputIndex, timestamp.String(), l1Header.Time)
+	}
+
+	//2. verify the L2 state root with the output root
+	stateRoot := ecom.HexToHash(rollupProof.StateRoot)
+	if hashOutputRoot(ecom.HexToHash(rollupProof.Version), stateRoot, ecom.HexToHash(rollupProof.MessagePasserStorageRoot),
+		ecom.HexToHash(rollupProof.LatestBlockHash)) != outputRoot {
+		return nil, fmt.Errorf("verifyFromRollupTx, output root %s mismatch", outputRoot.Hex())
+	}
+
+	//3. verify the cross chain manager storage on L2
+	if len(rollupProof.StorageProof.StorageProofs) != 1 {
+		return nil, fmt.Errorf("verifyFromRollupTx, incorrect proof format")
+	}
+	proofResult, err := ceth.VerifyMerkleProof(&rollupProof.StorageProof, &types.Header{Root: stateRoot}, sideChain.CCMCAddress)
+	if err != nil {
+		return nil, fmt.Errorf("verifyFromRollupTx, verifyMerkleProof error:%v", err)
+	}
+	if proofResult == nil {
+		return nil, fmt.Errorf("verifyFromRollupTx, verifyMerkleProof failed!")
+	}
+	if !ceth.CheckProofResult(proofResult, extra) {
+		return nil, fmt.Errorf("verifyFromRollupTx, verify proof value hash failed, proof result:%x, extra:%x", proofResult, extra)
+	}
+
+	txParam := new(scom.MakeTxParam)
+	if err := txParam.Deserialization(common.NewZeroCopySource(extra)); err != nil {
+		return nil, fmt.Errorf("verifyFromRollupTx, deserialize merkleValue error:%s", err)
+	}
+	return txParam, nil
+}
+
+// outputSlots returns the storage slots of l2Outputs[index], each output takes two slots,
+// the first is the output root and the second packs the timestamp and the L2 block number.
+func outputSlots(arraySlot, index uint64) (ecom.Hash, ecom.Hash) {
+	base := new(big.Int).SetBytes(crypto.Keccak256(ecom.BigToHash(new(big.Int).SetUint64(arraySlot)).Bytes()))
+	root := new(big.Int).Add(base, new(big.Int).Mul(new(big.Int).SetUint64(index), big.NewInt(2)))
+	meta := new(big.Int).Add(root, big.NewInt(1))
+	return ecom.BigToHash(root), ecom.BigToHash(meta)
+}
+
+func verifyOracleSlot(proof *ceth.ETHProof, l1Header *types.Header, oracle ecom.Address, slot ecom.Hash) (ecom.Hash, error) {
+	if len(proof.StorageProofs) != 1 {
+		return ecom.Hash{}, fmt.Errorf("incorrect proof format")
+	}
+	if ecom.HexToHash(scom.Replace0x(proof.StorageProofs[0].Key)) != slot {
+		return ecom.Hash{}, fmt.Errorf("storage key %s is not the expected slot %s", proof.StorageProofs[0].Key, slot.Hex())
+	}
+	result, err := ceth.VerifyMerkleProof(proof, l1Header, oracle.Bytes())
+	if err != nil {
+		return ecom.Hash{}, err
+	}
+	if result == nil {
+		return ecom.Hash{}, fmt.Errorf("slot %s not found", slot.Hex())
+	}
+	var value []byte
+	if err := rlp.DecodeBytes(result, &value); err != nil {
+		return ecom.Hash{}, fmt.Errorf("decode slot value error: %v", err)
+	}
+	return ecom.BytesToHash(value), nil
+}
+
+func hashOutputRoot(version, stateRoot, messagePasserStorageRoot, latestBlockHash ecom.Hash) ecom.Hash {
+	return crypto.Keccak256Hash(version.Bytes(), stateRoot.Bytes(), messagePasserStorageRoot.Bytes(), latestBlockHash.Bytes())
+}
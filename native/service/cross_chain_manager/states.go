@@ -0,0 +1,691 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package cross_chain_manager
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+)
+
+// Receipt is the compact summary of a cross chain transfer made by poly, the consensus
+// peers sign the serialized receipt so that it could be verified without a light client.
+type Receipt struct {
+	CrossChainID []byte
+	ToChainID    uint64
+	PayloadHash  common.Uint256
+}
+
+func (this *Receipt) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.CrossChainID)
+	sink.WriteVarUint(this.ToChainID)
+	sink.WriteHash(this.PayloadHash)
+}
+
+func (this *Receipt) Deserialization(source *common.ZeroCopySource) error {
+	crossChainID, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("Receipt deserialize cross chain id error")
+	}
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("Receipt deserialize to chain id error")
+	}
+	payloadHash, eof := source.NextHash()
+	if eof {
+		return fmt.Errorf("Receipt deserialize payload hash error")
+	}
+
+	this.CrossChainID = crossChainID
+	this.ToChainID = toChainID
+	this.PayloadHash = payloadHash
+	return nil
+}
+
+// ReceiptSigs collects the signatures of consensus peers keyed by the hex public key
+type ReceiptSigs struct {
+	Attested bool
+	Sigs     map[string][]byte
+}
+
+func (this *ReceiptSigs) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteBool(this.Attested)
+	keys := make([]string, 0, len(this.Sigs))
+	for k := range this.Sigs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	sink.WriteVarUint(uint64(len(keys)))
+	for _, k := range keys {
+		sink.WriteString(k)
+		sink.WriteVarBytes(this.Sigs[k])
+	}
+}
+
+func (this *ReceiptSigs) Deserialization(source *common.ZeroCopySource) error {
+	attested, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("ReceiptSigs deserialize attested error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ReceiptSigs deserialize length error")
+	}
+	sigs := make(map[string][]byte)
+	for i := uint64(0); i < n; i++ {
+		k, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("ReceiptSigs deserialize No.%d public key error", i)
+		}
+		v, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("ReceiptSigs deserialize No.%d signature error", i)
+		}
+		sigs[k] = v
+	}
+
+	this.Attested = attested
+	this.Sigs = sigs
+	return nil
+}
+
+// RelayerAttribution records the relayer whose submission of a cross chain tx landed first
+type RelayerAttribution struct {
+	Relayer common.Address
+	TxHash  common.Uint256
+	Height  uint32
+}
+
+func (this *RelayerAttribution) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteAddress(this.Relayer)
+	sink.WriteHash(this.TxHash)
+	sink.WriteUint32(this.Height)
+}
+
+func (this *RelayerAttribution) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Relayer, eof = source.NextAddress()
+	if eof {
+		return fmt.Errorf("RelayerAttribution deserialize relayer error")
+	}
+	this.TxHash, eof = source.NextHash()
+	if eof {
+		return fmt.Errorf("RelayerAttribution deserialize tx hash error")
+	}
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("RelayerAttribution deserialize height error")
+	}
+	return nil
+}
+
+// RelayerStats counts the cross chain txs attributed to a relayer
+type RelayerStats struct {
+	Imported   uint64
+	LastHeight uint32
+}
+
+func (this *RelayerStats) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.Imported)
+	sink.WriteUint32(this.LastHeight)
+}
+
+func (this *RelayerStats) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Imported, eof = source.NextUint64()
+	if eof {
+		return fmt.Errorf("RelayerStats deserialize imported error")
+	}
+	this.LastHeight, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("RelayerStats deserialize last height error")
+	}
+	return nil
+}
+
+// OutboundPayload locates the No.Sequence payload emitted to a chain, the ToMerkleValue
+// is the request stored under TxHash and proved by the cross states of PolyHeight.
+type OutboundPayload struct {
+	TxHash     []byte
+	PolyHeight uint32
+}
+
+func (this *OutboundPayload) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.TxHash)
+	sink.WriteUint32(this.PolyHeight)
+}
+
+func (this *OutboundPayload) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.TxHash, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("OutboundPayload deserialize tx hash error")
+	}
+	this.PolyHeight, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("OutboundPayload deserialize poly height error")
+	}
+	return nil
+}
+
+// EpochPush is the status of the last keepers update pushed to a chain on view change, TxHash is the
+// poly tx which changed the view, locating the request and the receipt of the push.
+type EpochPush struct {
+	View     uint32
+	Sequence uint64
+	Height   uint32
+	TxHash   []byte
+	Error    string // why the push failed, empty if it was sent
+}
+
+func (this *EpochPush) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.View)
+	sink.WriteUint64(this.Sequence)
+	sink.WriteUint32(this.Height)
+	sink.WriteVarBytes(this.TxHash)
+	sink.WriteString(this.Error)
+}
+
+func (this *EpochPush) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.View, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("EpochPush deserialize view error")
+	}
+	this.Sequence, eof = source.NextUint64()
+	if eof {
+		return fmt.Errorf("EpochPush deserialize sequence error")
+	}
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("EpochPush deserialize height error")
+	}
+	this.TxHash, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("EpochPush deserialize tx hash error")
+	}
+	this.Error, eof = source.NextString()
+	if eof {
+		return fmt.Errorf("EpochPush deserialize error error")
+	}
+	return nil
+}
+
+// ChainSupply is what moved of an asset on a chain, Minted is the amount delivered to the chain
+// and Burned the amount sent from it, so the supply on the chain is Minted - Burned.
+type ChainSupply struct {
+	ChainID uint64
+	Minted  *big.Int
+	Burned  *big.Int
+}
+
+// AssetSupply is the supply of an asset on every chain it moved on, sorted by chain id. The
+// supplies sum up to zero, the chain holding the locked asset has the negative one.
+type AssetSupply struct {
+	Chains []*ChainSupply
+}
+
+// chain returns the supply on chainID, adding it if there is none
+func (this *AssetSupply) chain(chainID uint64) *ChainSupply {
+	i := sort.Search(len(this.Chains), func(i int) bool { return this.Chains[i].ChainID >= chainID })
+	if i < len(this.Chains) && this.Chains[i].ChainID == chainID {
+		return this.Chains[i]
+	}
+	supply := &ChainSupply{ChainID: chainID, Minted: new(big.Int), Burned: new(big.Int)}
+	this.Chains = append(this.Chains, nil)
+	copy(this.Chains[i+1:], this.Chains[i:])
+	this.Chains[i] = supply
+	return supply
+}
+
+func (this *AssetSupply) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Chains)))
+	for _, v := range this.Chains {
+		sink.WriteVarUint(v.ChainID)
+		sink.WriteVarBytes(v.Minted.Bytes())
+		sink.WriteVarBytes(v.Burned.Bytes())
+	}
+}
+
+func (this *AssetSupply) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("AssetSupply deserialize length error")
+	}
+	chains := make([]*ChainSupply, 0)
+	for i := uint64(0); i < n; i++ {
+		chainID, eof := source.NextVarUint()
+		if eof {
+			return fmt.Errorf("AssetSupply deserialize chain id of No.%d chain error", i)
+		}
+		minted, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("AssetSupply deserialize minted of No.%d chain error", i)
+		}
+		burned, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("AssetSupply deserialize burned of No.%d chain error", i)
+		}
+		chains = append(chains, &ChainSupply{
+			ChainID: chainID,
+			Minted:  new(big.Int).SetBytes(minted),
+			Burned:  new(big.Int).SetBytes(burned),
+		})
+	}
+	this.Chains = chains
+	return nil
+}
+
+// PendingCommit is a cross chain tx verified and marked done whose tx to the target chain failed to be made,
+// it's kept until the commit is retried successfully or the transfer is refunded by governance. Reason is
+// one of the transient FailureReasons, never the error message, which isn't stable across versions.
+type PendingCommit struct {
+	FromChainID uint64
+	DoneID      []byte
+	MakeTxParam *scom.MakeTxParam
+	Reason      string
+	Height      uint32
+}
+
+func (this *PendingCommit) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.FromChainID)
+	sink.WriteVarBytes(this.DoneID)
+	this.MakeTxParam.Serialization(sink)
+	sink.WriteString(this.Reason)
+	sink.WriteUint32(this.Height)
+}
+
+func (this *PendingCommit) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.FromChainID, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("PendingCommit deserialize from chain id error")
+	}
+	this.DoneID, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("PendingCommit deserialize done id error")
+	}
+	this.MakeTxParam = new(scom.MakeTxParam)
+	if err := this.MakeTxParam.Deserialization(source); err != nil {
+		return fmt.Errorf("PendingCommit deserialize make tx param error: %v", err)
+	}
+	this.Reason, eof = source.NextString()
+	if eof {
+		return fmt.Errorf("PendingCommit deserialize reason error")
+	}
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("PendingCommit deserialize height error")
+	}
+	return nil
+}
+
+// ChainStats are the counters of the processing of a chain, TransfersIn are the transfers imported from
+// the chain, TransfersOut the ones made to it and Failures the failed imports from it.
+type ChainStats struct {
+	ChainID      uint64
+	Headers      uint64
+	TransfersIn  uint64
+	TransfersOut uint64
+	Failures     uint64
+}
+
+func (this *ChainStats) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarUint(this.Headers)
+	sink.WriteVarUint(this.TransfersIn)
+	sink.WriteVarUint(this.TransfersOut)
+	sink.WriteVarUint(this.Failures)
+}
+
+func (this *ChainStats) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.ChainID, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ChainStats deserialize chain id error")
+	}
+	this.Headers, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ChainStats deserialize headers error")
+	}
+	this.TransfersIn, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ChainStats deserialize transfers in error")
+	}
+	this.TransfersOut, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ChainStats deserialize transfers out error")
+	}
+	this.Failures, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ChainStats deserialize failures error")
+	}
+	return nil
+}
+
+// EpochSummary snapshots the counters of the registered chains when View ends at poly Height. The
+// counters are totals, the processing within the view is the difference to the summary of the view before.
+type EpochSummary struct {
+	View   uint32
+	Height uint32
+	Chains []*ChainStats
+}
+
+func (this *EpochSummary) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.View)
+	sink.WriteUint32(this.Height)
+	sink.WriteVarUint(uint64(len(this.Chains)))
+	for _, v := range this.Chains {
+		v.Serialization(sink)
+	}
+}
+
+func (this *EpochSummary) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.View, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("EpochSummary deserialize view error")
+	}
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("EpochSummary deserialize height error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("EpochSummary deserialize length error")
+	}
+	chains := make([]*ChainStats, 0)
+	for i := uint64(0); i < n; i++ {
+		stats := new(ChainStats)
+		if err := stats.Deserialization(source); err != nil {
+			return fmt.Errorf("EpochSummary deserialize No.%d chain error: %v", i, err)
+		}
+		chains = append(chains, stats)
+	}
+	this.Chains = chains
+	return nil
+}
+
+// BlockedAttempts counts the cross chain txs to a blocked recipient rejected by the verification, the last
+// one is the tx DoneID from FromChainID rejected at poly Height.
+type BlockedAttempts struct {
+	Count       uint64
+	FromChainID uint64
+	DoneID      []byte
+	Height      uint32
+}
+
+func (this *BlockedAttempts) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.Count)
+	sink.WriteVarUint(this.FromChainID)
+	sink.WriteVarBytes(this.DoneID)
+	sink.WriteUint32(this.Height)
+}
+
+func (this *BlockedAttempts) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Count, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("BlockedAttempts deserialize count error")
+	}
+	this.FromChainID, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("BlockedAttempts deserialize from chain id error")
+	}
+	this.DoneID, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BlockedAttempts deserialize done id error")
+	}
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("BlockedAttempts deserialize height error")
+	}
+	return nil
+}
+
+// FailedImport is an entry of the log of the failed ImportOuterTransfer, ProofHash is the sha256 of the proof
+// and Reason one of FailureReasons
+type FailedImport struct {
+	Index     uint64
+	Height    uint32
+	TxHash    common.Uint256
+	ChainID   uint64
+	ProofHash common.Uint256
+	Relayer   []byte
+	Reason    string
+}
+
+func (this *FailedImport) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.Index)
+	sink.WriteUint32(this.Height)
+	sink.WriteHash(this.TxHash)
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteHash(this.ProofHash)
+	sink.WriteVarBytes(this.Relayer)
+	sink.WriteString(this.Reason)
+}
+
+func (this *FailedImport) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Index, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("FailedImport deserialize index error")
+	}
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("FailedImport deserialize height error")
+	}
+	this.TxHash, eof = source.NextHash()
+	if eof {
+		return fmt.Errorf("FailedImport deserialize tx hash error")
+	}
+	this.ChainID, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("FailedImport deserialize chain id error")
+	}
+	this.ProofHash, eof = source.NextHash()
+	if eof {
+		return fmt.Errorf("FailedImport deserialize proof hash error")
+	}
+	this.Relayer, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("FailedImport deserialize relayer error")
+	}
+	this.Reason, eof = source.NextString()
+	if eof {
+		return fmt.Errorf("FailedImport deserialize reason error")
+	}
+	return nil
+}
+
+type FailedImports struct {
+	Items []*FailedImport
+}
+
+func (this *FailedImports) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Items)))
+	for _, v := range this.Items {
+		v.Serialization(sink)
+	}
+}
+
+func (this *FailedImports) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("FailedImports deserialize length error")
+	}
+	items := make([]*FailedImport, 0, n)
+	for i := uint64(0); i < n; i++ {
+		item := new(FailedImport)
+		if err := item.Deserialization(source); err != nil {
+			return fmt.Errorf("FailedImports deserialize item error: %v", err)
+		}
+		items = append(items, item)
+	}
+	this.Items = items
+	return nil
+}
+
+// PendingFee is the relay fee charged on a cross chain transfer, it's settled to Relayer once
+// the receipt of the transfer is attested.
+type PendingFee struct {
+	Relayer common.Address
+	Asset   []byte
+	Fee     *big.Int
+}
+
+func (this *PendingFee) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteAddress(this.Relayer)
+	sink.WriteVarBytes(this.Asset)
+	sink.WriteVarBytes(this.Fee.Bytes())
+}
+
+func (this *PendingFee) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Relayer, eof = source.NextAddress()
+	if eof {
+		return fmt.Errorf("PendingFee deserialize relayer error")
+	}
+	this.Asset, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("PendingFee deserialize asset error")
+	}
+	fee, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("PendingFee deserialize fee error")
+	}
+	this.Fee = new(big.Int).SetBytes(fee)
+	return nil
+}
+
+// FeeRoute is a pair of lock proxies registered by governance, the transfers from the proxy FromContract
+// of FromChainID to the proxy ToContract are charged the fee. The fees on the target chain are paid out by
+// an unlock of ToContract made as if sent by FromContract through the route pinned as the payout one.
+type FeeRoute struct {
+	FromChainID  uint64
+	FromContract []byte
+	ToContract   []byte
+}
+
+func (this *FeeRoute) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.FromChainID)
+	sink.WriteVarBytes(this.FromContract)
+	sink.WriteVarBytes(this.ToContract)
+}
+
+func (this *FeeRoute) Deserialization(source *common.ZeroCopySource) error {
+	fromChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("FeeRoute deserialize from chain id error")
+	}
+	fromContract, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("FeeRoute deserialize from contract error")
+	}
+	toContract, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("FeeRoute deserialize to contract error")
+	}
+	this.FromChainID = fromChainID
+	this.FromContract = fromContract
+	this.ToContract = toContract
+	return nil
+}
+
+// ChainCheck is the result of a check of the chain configuration, Message tells what to do when it fails
+type ChainCheck struct {
+	Name    string
+	Status  uint8
+	Message string
+}
+
+func (this *ChainCheck) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Name)
+	sink.WriteUint8(this.Status)
+	sink.WriteString(this.Message)
+}
+
+func (this *ChainCheck) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Name, eof = source.NextString()
+	if eof {
+		return fmt.Errorf("ChainCheck deserialize name error")
+	}
+	this.Status, eof = source.NextUint8()
+	if eof {
+		return fmt.Errorf("ChainCheck deserialize status error")
+	}
+	this.Message, eof = source.NextString()
+	if eof {
+		return fmt.Errorf("ChainCheck deserialize message error")
+	}
+	return nil
+}
+
+// ChainReport is the result of the checks of the chain configuration at poly Height
+type ChainReport struct {
+	ChainID uint64
+	Height  uint32
+	Checks  []*ChainCheck
+}
+
+func (this *ChainReport) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteUint32(this.Height)
+	sink.WriteVarUint(uint64(len(this.Checks)))
+	for _, v := range this.Checks {
+		v.Serialization(sink)
+	}
+}
+
+func (this *ChainReport) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.ChainID, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ChainReport deserialize chain id error")
+	}
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("ChainReport deserialize height error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ChainReport deserialize checks length error")
+	}
+	this.Checks = make([]*ChainCheck, 0, n)
+	for i := uint64(0); i < n; i++ {
+		check := new(ChainCheck)
+		if err := check.Deserialization(source); err != nil {
+			return fmt.Errorf("ChainReport, %v", err)
+		}
+		this.Checks = append(this.Checks, check)
+	}
+	return nil
+}
+
+// Failed returns whether any check of the report fails
+func (this *ChainReport) Failed() bool {
+	for _, v := range this.Checks {
+		if v.Status == CHECK_FAIL {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,341 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// reasons of the failed ImportOuterTransfer
+const (
+	FAILURE_BLACKED      = "blacked"
+	FAILURE_BLOCKED      = "blocked"
+	FAILURE_FROZEN       = "frozen"
+	FAILURE_UNREGISTERED = "unregistered"
+	FAILURE_QUITTING     = "quitting"
+	FAILURE_DONE         = "done"
+	FAILURE_PANIC        = "panic"
+	FAILURE_PROOF        = "proof"
+	FAILURE_OTHER        = "other"
+)
+
+// errors wrapped by the errors of the failed ImportOuterTransfer, failureReason classifies the failures by them
+var (
+	errChainBlacked      = errors.New("chain is blacked")
+	errChainUnregistered = errors.New("side chain is not registered")
+	errChainQuitting     = errors.New("side chain is quitting")
+	errAssetFrozen       = errors.New("asset is frozen")
+	errHandlerPanic      = errors.New("MakeDepositProposal panics")
+)
+
+// proofError is a failure of the proof verification of a chain handler
+type proofError struct {
+	cause error
+}
+
+func (this *proofError) Error() string {
+	return this.cause.Error()
+}
+
+func (this *proofError) Unwrap() error {
+	return this.cause
+}
+
+var FailureReasons = []string{FAILURE_BLACKED, FAILURE_BLOCKED, FAILURE_FROZEN, FAILURE_UNREGISTERED,
+	FAILURE_QUITTING, FAILURE_DONE, FAILURE_PANIC, FAILURE_PROOF, FAILURE_OTHER}
+
+func init() {
+	native.FailureHooks[utils.CrossChainManagerContractAddress] = onFailure
+	node_manager.RegisterViewChangeHook("cross_chain_manager/epochSummary", node_manager.VIEW_CHANGE_SUMMARY, summarizeEpoch)
+}
+
+// onFailure counts the failed ImportOuterTransfer by the reason of cause and by the source chain, and logs it
+func onFailure(native *native.NativeService, method string, cause error) {
+	if method != IMPORT_OUTER_TRANSFER_NAME {
+		return
+	}
+	keepPendingCommit(native, cause)
+	countChainFailure(native)
+	reason := failureReason(cause)
+	logFailedImport(native, reason)
+	count, err := GetFailureCount(native, reason)
+	if err != nil {
+		return
+	}
+	native.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(FAILURE_COUNT), []byte(reason)),
+		cstates.GenRawStorageItem(utils.GetUint64Bytes(count+1)))
+}
+
+func failureReason(cause error) string {
+	var blocked *RecipientBlockedError
+	var proof *proofError
+	switch {
+	case errors.Is(cause, errChainBlacked):
+		return FAILURE_BLACKED
+	case errors.As(cause, &blocked):
+		return FAILURE_BLOCKED
+	case errors.Is(cause, errAssetFrozen):
+		return FAILURE_FROZEN
+	case errors.Is(cause, errChainUnregistered):
+		return FAILURE_UNREGISTERED
+	case errors.Is(cause, errChainQuitting):
+		return FAILURE_QUITTING
+	case errors.Is(cause, scom.ErrTxDone):
+		return FAILURE_DONE
+	case errors.Is(cause, errHandlerPanic):
+		return FAILURE_PANIC
+	case errors.As(cause, &proof):
+		return FAILURE_PROOF
+	default:
+		return FAILURE_OTHER
+	}
+}
+
+// countTransfer counts the transfer from fromChainID made by txParam, the volume and the supply are only
+// counted for the transfers made by lock proxy.
+func countTransfer(native *native.NativeService, fromChainID uint64, txParam *scom.MakeTxParam) error {
+	contract := utils.CrossChainManagerContractAddress
+	toChainIDBytes := utils.GetUint64Bytes(txParam.ToChainID)
+	count, err := GetTransferCount(native, fromChainID, txParam.ToChainID)
+	if err != nil {
+		return err
+	}
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(TRANSFER_COUNT), utils.GetUint64Bytes(fromChainID), toChainIDBytes),
+		cstates.GenRawStorageItem(utils.GetUint64Bytes(count+1)))
+	if err := countChain(native, fromChainID, func(stats *ChainStats) { stats.TransfersIn++ }); err != nil {
+		return err
+	}
+	if err := countChain(native, txParam.ToChainID, func(stats *ChainStats) { stats.TransfersOut++ }); err != nil {
+		return err
+	}
+
+	asset, amount := scom.DecodeTransferArgs(txParam.Args)
+	if amount == nil {
+		return nil
+	}
+	volume, err := GetAssetVolume(native, txParam.ToChainID, asset)
+	if err != nil {
+		return err
+	}
+	volume.Add(volume, amount)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(ASSET_VOLUME), toChainIDBytes, asset),
+		cstates.GenRawStorageItem(volume.Bytes()))
+	return countSupply(native, fromChainID, txParam.ToChainID, asset, amount)
+}
+
+// countChainFailure counts the failed import against its source chain, the imports which can't be
+// parsed or come from unregistered chains are only counted by reason
+func countChainFailure(native *native.NativeService) {
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return
+	}
+	sideChain, err := side_chain_manager.GetSideChain(native, params.SourceChainID)
+	if err != nil || sideChain == nil {
+		return
+	}
+	countChain(native, params.SourceChainID, func(stats *ChainStats) { stats.Failures++ })
+}
+
+func countChain(native *native.NativeService, chainID uint64, count func(stats *ChainStats)) error {
+	stats, err := getChainStats(native, chainID)
+	if err != nil {
+		return err
+	}
+	count(stats)
+	sink := common.NewZeroCopySink(nil)
+	stats.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(CHAIN_STATS), utils.GetUint64Bytes(chainID)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
+
+// getChainStats returns the counters of chainID kept by this contract, the headers are counted by header sync
+func getChainStats(native *native.NativeService, chainID uint64) (*ChainStats, error) {
+	raw, err := getCounter(native, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(CHAIN_STATS),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("getChainStats, %v", err)
+	}
+	stats := &ChainStats{ChainID: chainID}
+	if raw == nil {
+		return stats, nil
+	}
+	if err := stats.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("getChainStats, deserialize chain stats error: %v", err)
+	}
+	return stats, nil
+}
+
+// summarizeEpoch records the summary of the view ended by the change to view
+func summarizeEpoch(native *native.NativeService, view uint32) {
+	sideChains, err := side_chain_manager.GetSideChains(native)
+	if err != nil {
+		return
+	}
+	summary := &EpochSummary{
+		View:   view - 1,
+		Height: native.GetHeight(),
+		Chains: make([]*ChainStats, 0, len(sideChains)),
+	}
+	for _, sideChain := range sideChains {
+		stats, err := getChainStats(native, sideChain.ChainId)
+		if err != nil {
+			return
+		}
+		stats.Headers, err = hscommon.GetHeaderCount(native, sideChain.ChainId)
+		if err != nil {
+			return
+		}
+		summary.Chains = append(summary.Chains, stats)
+	}
+	sink := common.NewZeroCopySink(nil)
+	summary.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(EPOCH_SUMMARY),
+		utils.GetUint32Bytes(summary.View)), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// GetEpochSummary returns nil if no summary was recorded for view
+func GetEpochSummary(native *native.NativeService, view uint32) (*EpochSummary, error) {
+	raw, err := getCounter(native, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(EPOCH_SUMMARY),
+		utils.GetUint32Bytes(view)))
+	if err != nil {
+		return nil, fmt.Errorf("GetEpochSummary, %v", err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	summary := new(EpochSummary)
+	if err := summary.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetEpochSummary, deserialize epoch summary error: %v", err)
+	}
+	return summary, nil
+}
+
+func getCounter(native *native.NativeService, key []byte) ([]byte, error) {
+	store, err := native.GetCacheDB().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("get store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize from raw storage item err: %v", err)
+	}
+	return raw, nil
+}
+
+// GetTransferCount returns the number of transfers from fromChainID to toChainID
+func GetTransferCount(native *native.NativeService, fromChainID, toChainID uint64) (uint64, error) {
+	raw, err := getCounter(native, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(TRANSFER_COUNT),
+		utils.GetUint64Bytes(fromChainID), utils.GetUint64Bytes(toChainID)))
+	if err != nil {
+		return 0, fmt.Errorf("GetTransferCount, %v", err)
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+// GetAssetVolume returns the total amount transferred of asset on toChainID
+func GetAssetVolume(native *native.NativeService, toChainID uint64, asset []byte) (*big.Int, error) {
+	raw, err := getCounter(native, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ASSET_VOLUME),
+		utils.GetUint64Bytes(toChainID), asset))
+	if err != nil {
+		return nil, fmt.Errorf("GetAssetVolume, %v", err)
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+// GetFailureCount returns the number of the failed ImportOuterTransfer for reason
+func GetFailureCount(native *native.NativeService, reason string) (uint64, error) {
+	raw, err := getCounter(native, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(FAILURE_COUNT),
+		[]byte(reason)))
+	if err != nil {
+		return 0, fmt.Errorf("GetFailureCount, %v", err)
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+// GetTransferCountQuery returns the transfer count of the chain pair, to be called by preExec
+func GetTransferCountQuery(native *native.NativeService) ([]byte, error) {
+	params := new(TransferCountParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetTransferCountQuery, contract params deserialize error: %v", err)
+	}
+	count, err := GetTransferCount(native, params.FromChainID, params.ToChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.GetUint64Bytes(count), nil
+}
+
+// GetAssetVolumeQuery returns the volume of the asset in big endian, to be called by preExec
+func GetAssetVolumeQuery(native *native.NativeService) ([]byte, error) {
+	params := new(AssetVolumeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetAssetVolumeQuery, contract params deserialize error: %v", err)
+	}
+	volume, err := GetAssetVolume(native, params.ToChainID, params.Asset)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return volume.Bytes(), nil
+}
+
+// GetFailureCountQuery returns the failure count of the reason, to be called by preExec
+func GetFailureCountQuery(native *native.NativeService) ([]byte, error) {
+	params := new(FailureCountParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetFailureCountQuery, contract params deserialize error: %v", err)
+	}
+	count, err := GetFailureCount(native, params.Reason)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.GetUint64Bytes(count), nil
+}
+
+// GetEpochSummaryQuery returns the summary of the view, empty if there is none, to be called by preExec
+func GetEpochSummaryQuery(native *native.NativeService) ([]byte, error) {
+	params := new(EpochSummaryParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetEpochSummaryQuery, contract params deserialize error: %v", err)
+	}
+	summary, err := GetEpochSummary(native, params.View)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	if summary == nil {
+		return []byte{}, nil
+	}
+	sink := common.NewZeroCopySink(nil)
+	summary.Serialization(sink)
+	return sink.Bytes(), nil
+}
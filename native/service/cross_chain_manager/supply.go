@@ -0,0 +1,241 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// BindAsset binds an asset hash on a chain to a named asset once the consensus peers approve it,
+// the transfers delivering the asset hash are counted in the supply of the asset from then on.
+func BindAsset(native *native.NativeService) ([]byte, error) {
+	params := new(BindAssetParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("BindAsset, contract params deserialize error: %v", err)
+	}
+	if params.Asset == "" || len(params.AssetHash) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("BindAsset, asset and asset hash can't be empty")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("BindAsset, checkWitness error: %v", err)
+	}
+
+	sideChain, err := side_chain_manager.GetSideChain(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("BindAsset, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("BindAsset, side chain %d is not registered", params.ChainID)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteString(params.Asset)
+	sink.WriteVarUint(params.ChainID)
+	sink.WriteVarBytes(params.AssetHash)
+	ok, err := node_manager.CheckConsensusSigns(native, BIND_ASSET, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("BindAsset, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	native.GetCacheDB().Put(assetBindKey(params.ChainID, params.AssetHash), cstates.GenRawStorageItem([]byte(params.Asset)))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"bindAsset", params.Asset, params.ChainID, params.AssetHash},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+func assetBindKey(chainID uint64, assetHash []byte) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ASSET_BIND), utils.GetUint64Bytes(chainID), assetHash)
+}
+
+// GetAssetBind returns the name of the asset the asset hash on chainID is bound to, empty if none
+func GetAssetBind(native *native.NativeService, chainID uint64, assetHash []byte) (string, error) {
+	raw, err := getCounter(native, assetBindKey(chainID, assetHash))
+	if err != nil {
+		return "", fmt.Errorf("GetAssetBind, %v", err)
+	}
+	return string(raw), nil
+}
+
+// FreezeAsset halts the transfers of an asset on every chain once the consensus peers approve it, e.g. when
+// the contract of the asset on one chain is exploited. The transfers delivering the asset hashes bound to
+// the asset fail to be committed and are kept pending commit, so they can be retried after UnfreezeAsset.
+func FreezeAsset(native *native.NativeService) ([]byte, error) {
+	return setAssetFrozen(native, FREEZE_ASSET, true)
+}
+
+// UnfreezeAsset resumes the transfers of an asset frozen once the consensus peers approve it
+func UnfreezeAsset(native *native.NativeService) ([]byte, error) {
+	return setAssetFrozen(native, UNFREEZE_ASSET, false)
+}
+
+func setAssetFrozen(native *native.NativeService, method string, frozen bool) ([]byte, error) {
+	params := new(FreezeAssetParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("%s, contract params deserialize error: %v", method, err)
+	}
+	if params.Asset == "" {
+		return utils.BYTE_FALSE, fmt.Errorf("%s, asset can't be empty", method)
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("%s, checkWitness error: %v", method, err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteString(params.Asset)
+	ok, err := node_manager.CheckConsensusSigns(native, method, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("%s, CheckConsensusSigns error: %v", method, err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ASSET_FROZEN), []byte(params.Asset))
+	if frozen {
+		native.GetCacheDB().Put(key, cstates.GenRawStorageItem([]byte{1}))
+	} else {
+		native.GetCacheDB().Delete(key)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"freezeAsset", params.Asset, frozen},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// IsAssetFrozen tells whether the transfers of the asset are halted
+func IsAssetFrozen(native *native.NativeService, asset string) (bool, error) {
+	raw, err := getCounter(native, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ASSET_FROZEN), []byte(asset)))
+	if err != nil {
+		return false, fmt.Errorf("IsAssetFrozen, %v", err)
+	}
+	return raw != nil, nil
+}
+
+// checkAssetNotFrozen fails the transfer made by lock proxy if the asset hash it delivers is bound to
+// a frozen asset
+func checkAssetNotFrozen(native *native.NativeService, txParam *scom.MakeTxParam) error {
+	assetHash, amount := scom.DecodeTransferArgs(txParam.Args)
+	if amount == nil {
+		return nil
+	}
+	asset, err := GetAssetBind(native, txParam.ToChainID, assetHash)
+	if err != nil || asset == "" {
+		return err
+	}
+	frozen, err := IsAssetFrozen(native, asset)
+	if err != nil {
+		return err
+	}
+	if frozen {
+		return fmt.Errorf("%w: %s", errAssetFrozen, asset)
+	}
+	return nil
+}
+
+// countSupply counts amount of the asset bound to assetHash on toChainID as minted on toChainID
+// and burned on fromChainID, nothing is counted for an unbound asset hash.
+func countSupply(native *native.NativeService, fromChainID, toChainID uint64, assetHash []byte, amount *big.Int) error {
+	asset, err := GetAssetBind(native, toChainID, assetHash)
+	if err != nil || asset == "" {
+		return err
+	}
+	supply, err := GetAssetSupply(native, asset)
+	if err != nil {
+		return err
+	}
+	minted := supply.chain(toChainID).Minted
+	minted.Add(minted, amount)
+	burned := supply.chain(fromChainID).Burned
+	burned.Add(burned, amount)
+
+	sink := common.NewZeroCopySink(nil)
+	supply.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ASSET_SUPPLY), []byte(asset)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
+
+// GetAssetSupply returns the supply of the asset on every chain it moved on
+func GetAssetSupply(native *native.NativeService, asset string) (*AssetSupply, error) {
+	raw, err := getCounter(native, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ASSET_SUPPLY), []byte(asset)))
+	if err != nil {
+		return nil, fmt.Errorf("GetAssetSupply, %v", err)
+	}
+	supply := &AssetSupply{Chains: make([]*ChainSupply, 0)}
+	if raw == nil {
+		return supply, nil
+	}
+	if err := supply.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetAssetSupply, %v", err)
+	}
+	return supply, nil
+}
+
+// GetAssetFrozenQuery returns whether the transfers of the asset are halted, to be called by preExec
+func GetAssetFrozenQuery(native *native.NativeService) ([]byte, error) {
+	params := new(AssetParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetAssetFrozenQuery, contract params deserialize error: %v", err)
+	}
+	frozen, err := IsAssetFrozen(native, params.Asset)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteBool(frozen)
+	return sink.Bytes(), nil
+}
+
+// GetAssetSupplyQuery returns the serialized supply of the asset, to be called by preExec
+func GetAssetSupplyQuery(native *native.NativeService) ([]byte, error) {
+	params := new(AssetParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetAssetSupplyQuery, contract params deserialize error: %v", err)
+	}
+	supply, err := GetAssetSupply(native, params.Asset)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	sink := common.NewZeroCopySink(nil)
+	supply.Serialization(sink)
+	return sink.Bytes(), nil
+}
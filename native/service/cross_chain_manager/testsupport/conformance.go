@@ -0,0 +1,290 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package testsupport holds the conformance suite every cross chain handler
+// is expected to pass before it is wired into the cross chain manager.
+package testsupport
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// Fixture describes a handler together with the inputs the suite feeds it.
+type Fixture struct {
+	Handler scom.ChainHandler
+	// Setup writes the header, side chain and binding state the valid input
+	// is proven against, it is committed before any input is executed
+	Setup func(t *testing.T, db *storage.CacheDB)
+	// Tx is the poly transaction the handler runs in, an empty one if nil
+	Tx     *types.Transaction
+	Height uint32
+	// Valid is a serialized EntranceParam the handler must accept
+	Valid []byte
+	// Malformed inputs must all be rejected, keyed by a short description
+	Malformed map[string][]byte
+	// Reorg, if set, rewrites the committed state so that the block the valid
+	// input is proven against is no longer on the main chain, chains with
+	// instant finality leave it nil
+	Reorg func(t *testing.T, db *storage.CacheDB)
+	// Equivalent inputs prove the same event as the valid input through
+	// different bytes, each must be accepted on its own and rejected once the
+	// valid input is done, keyed by a short description
+	Equivalent map[string][]byte
+}
+
+type result struct {
+	param  []byte
+	writes map[string]string
+	notify []*event.NotifyEventInfo
+}
+
+// Run executes the conformance suite against the fixture.
+func Run(t *testing.T, f *Fixture) {
+	if f.Valid != nil {
+		t.Run("Deterministic", func(t *testing.T) { testDeterministic(t, f) })
+		t.Run("Replay", func(t *testing.T) { testReplay(t, f) })
+		t.Run("PreExec", func(t *testing.T) { testPreExec(t, f) })
+	}
+	if f.Valid != nil && len(f.Equivalent) != 0 {
+		t.Run("Equivalent", func(t *testing.T) { testEquivalent(t, f) })
+	}
+	if f.Valid != nil && f.Reorg != nil {
+		t.Run("Reorg", func(t *testing.T) { testReorg(t, f) })
+	}
+	t.Run("Malformed", func(t *testing.T) { testMalformed(t, f) })
+}
+
+func testDeterministic(t *testing.T, f *Fixture) {
+	first, err := execute(t, f, storage.NewCacheDB(prepare(t, f)), f.Valid, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	second, err := execute(t, f, storage.NewCacheDB(prepare(t, f)), f.Valid, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, first.param, second.param, "make tx param differs between runs")
+	assert.Equal(t, first.writes, second.writes, "state writes differ between runs")
+	assert.Equal(t, first.notify, second.notify, "notifications differ between runs")
+}
+
+func testReplay(t *testing.T, f *Fixture) {
+	overlay := prepare(t, f)
+	db := storage.NewCacheDB(overlay)
+	if _, err := execute(t, f, db, f.Valid, false); !assert.NoError(t, err) {
+		return
+	}
+	_, err := execute(t, f, db, f.Valid, false)
+	assert.Error(t, err, "replay within the same block is accepted")
+
+	db.Commit()
+	_, err = execute(t, f, storage.NewCacheDB(overlay), f.Valid, false)
+	assert.Error(t, err, "replay after commit is accepted")
+}
+
+func testPreExec(t *testing.T, f *Fixture) {
+	overlay := prepare(t, f)
+	before := overlay.ChangeHash()
+	pre, err := execute(t, f, storage.NewCacheDB(overlay), f.Valid, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, before, overlay.ChangeHash(), "preExec changes committed state")
+
+	// a preExec run must not consume the transfer
+	res, err := execute(t, f, storage.NewCacheDB(overlay), f.Valid, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, res.param, pre.param, "preExec result differs from execution")
+}
+
+func testEquivalent(t *testing.T, f *Fixture) {
+	overlay := prepare(t, f)
+	valid, err := execute(t, f, storage.NewCacheDB(overlay), f.Valid, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for name, input := range f.Equivalent {
+		res, err := execute(t, f, storage.NewCacheDB(overlay), input, false)
+		if !assert.NoError(t, err, "equivalent input %q is rejected", name) {
+			continue
+		}
+		assert.Equal(t, valid.param, res.param, "equivalent input %q proves another event", name)
+	}
+
+	db := storage.NewCacheDB(overlay)
+	if _, err := execute(t, f, db, f.Valid, false); !assert.NoError(t, err) {
+		return
+	}
+	for name, input := range f.Equivalent {
+		_, err := execute(t, f, db, input, false)
+		assert.Error(t, err, "equivalent input %q is accepted after the valid one", name)
+	}
+	db.Commit()
+	for name, input := range f.Equivalent {
+		_, err := execute(t, f, storage.NewCacheDB(overlay), input, false)
+		assert.Error(t, err, "equivalent input %q is accepted after commit", name)
+	}
+
+	// and the other way round, the valid input is a replay of an equivalent one
+	for name, input := range f.Equivalent {
+		db := storage.NewCacheDB(prepare(t, f))
+		if _, err := execute(t, f, db, input, false); !assert.NoError(t, err) {
+			continue
+		}
+		_, err := execute(t, f, db, f.Valid, false)
+		assert.Error(t, err, "valid input is accepted after equivalent input %q", name)
+	}
+}
+
+func testReorg(t *testing.T, f *Fixture) {
+	overlay := prepare(t, f)
+	db := storage.NewCacheDB(overlay)
+	f.Reorg(t, db)
+	db.Commit()
+	_, err := execute(t, f, storage.NewCacheDB(overlay), f.Valid, false)
+	assert.Error(t, err, "proof of a block off the main chain is accepted")
+}
+
+func testMalformed(t *testing.T, f *Fixture) {
+	overlay := prepare(t, f)
+	for name, input := range f.Malformed {
+		_, err := execute(t, f, storage.NewCacheDB(overlay), input, false)
+		assert.Error(t, err, "malformed input %q is accepted", name)
+	}
+}
+
+func prepare(t *testing.T, f *Fixture) *overlaydb.OverlayDB {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	if err != nil {
+		t.Fatalf("NewMemLevelDBStore error: %v", err)
+	}
+	overlay := overlaydb.NewOverlayDB(store)
+	if f.Setup != nil {
+		db := storage.NewCacheDB(overlay)
+		f.Setup(t, db)
+		db.Commit()
+	}
+	return overlay
+}
+
+func execute(t *testing.T, f *Fixture, db *storage.CacheDB, input []byte, preExec bool) (res *result, err error) {
+	tx := f.Tx
+	if tx == nil {
+		tx = new(types.Transaction)
+	}
+	service, err := native.NewNativeService(db, tx, 0, f.Height, common.Uint256{}, 0, input, preExec)
+	if err != nil {
+		t.Fatalf("NewNativeService error: %v", err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("handler panics on input: %v", r)
+			res, err = nil, fmt.Errorf("panic: %v", r)
+		}
+	}()
+	param, err := f.Handler.MakeDepositProposal(service)
+	if err != nil {
+		return nil, err
+	}
+	res = &result{
+		writes: make(map[string]string),
+		notify: service.GetNotify(),
+	}
+	if param != nil {
+		sink := common.NewZeroCopySink(nil)
+		param.Serialization(sink)
+		res.param = sink.Bytes()
+	}
+	db.ForEach(func(key, val []byte) {
+		res.writes[hex.EncodeToString(key)] = hex.EncodeToString(val)
+	})
+	return res, nil
+}
+
+// EquivalentEntrances derives inputs proving the same event as a valid
+// serialized EntranceParam from it, with the proof replaced by each of the
+// given re-encodings of the same proof and with another relayer.
+func EquivalentEntrances(valid []byte, proofs map[string][]byte) map[string][]byte {
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(valid)); err != nil {
+		panic(fmt.Sprintf("EquivalentEntrances, deserialize valid input error: %v", err))
+	}
+	mutate := func(f func(p *scom.EntranceParam)) []byte {
+		p := *params
+		f(&p)
+		sink := common.NewZeroCopySink(nil)
+		p.Serialization(sink)
+		return sink.Bytes()
+	}
+	res := map[string][]byte{
+		"other relayer": mutate(func(p *scom.EntranceParam) {
+			p.RelayerAddress = append([]byte{0xff}, p.RelayerAddress...)
+		}),
+	}
+	for name, proof := range proofs {
+		proof := proof
+		res[name] = mutate(func(p *scom.EntranceParam) {
+			p.Proof = proof
+		})
+	}
+	return res
+}
+
+// MalformedEntrances derives the inputs every handler must reject from a
+// valid serialized EntranceParam.
+func MalformedEntrances(valid []byte) map[string][]byte {
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(valid)); err != nil {
+		panic(fmt.Sprintf("MalformedEntrances, deserialize valid input error: %v", err))
+	}
+	mutate := func(f func(p *scom.EntranceParam)) []byte {
+		p := *params
+		f(&p)
+		sink := common.NewZeroCopySink(nil)
+		p.Serialization(sink)
+		return sink.Bytes()
+	}
+	return map[string][]byte{
+		"empty input":     {},
+		"truncated input": valid[:len(valid)/2],
+		"empty proof": mutate(func(p *scom.EntranceParam) {
+			p.Proof = nil
+		}),
+		"corrupted proof": mutate(func(p *scom.EntranceParam) {
+			p.Proof = append([]byte{}, p.Proof...)
+			p.Proof[len(p.Proof)/2] ^= 0x01
+		}),
+		"unknown source chain": mutate(func(p *scom.EntranceParam) {
+			p.SourceChainID = math.MaxUint64
+		}),
+	}
+}
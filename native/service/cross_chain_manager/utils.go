@@ -17,9 +17,17 @@
 package cross_chain_manager
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
+	"sort"
+
+	"github.com/polynetwork/poly/common"
 	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
@@ -48,3 +56,246 @@ func RemoveBlackChain(native *native.NativeService, chainID uint64) {
 	chainIDBytes := utils.GetUint64Bytes(chainID)
 	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(BLACKED_CHAIN), chainIDBytes))
 }
+
+// checkNotQuitting fails if the side chain is winding down, no new transfer is accepted from or to it
+func checkNotQuitting(native *native.NativeService, chainID uint64) error {
+	windDown, err := side_chain_manager.GetWindDown(native, chainID)
+	if err != nil {
+		return err
+	}
+	if windDown != nil {
+		return fmt.Errorf("%w: chain %d quit at height %d", errChainQuitting, chainID, windDown.QuitHeight)
+	}
+	return nil
+}
+
+func putReceipt(native *native.NativeService, receipt *Receipt) {
+	contract := utils.CrossChainManagerContractAddress
+	sink := common.NewZeroCopySink(nil)
+	receipt.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(RECEIPT), utils.GetUint64Bytes(receipt.ToChainID), receipt.CrossChainID),
+		cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func GetReceipt(native *native.NativeService, toChainID uint64, crossChainID []byte) (*Receipt, error) {
+	contract := utils.CrossChainManagerContractAddress
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(RECEIPT), utils.GetUint64Bytes(toChainID), crossChainID))
+	if err != nil {
+		return nil, fmt.Errorf("GetReceipt, get receipt store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetReceipt, deserialize from raw storage item err: %v", err)
+	}
+	receipt := new(Receipt)
+	if err := receipt.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetReceipt, deserialize receipt error: %v", err)
+	}
+	return receipt, nil
+}
+
+func putReceiptSigs(native *native.NativeService, toChainID uint64, crossChainID []byte, sigs *ReceiptSigs) {
+	contract := utils.CrossChainManagerContractAddress
+	sink := common.NewZeroCopySink(nil)
+	sigs.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(RECEIPT_SIGS), utils.GetUint64Bytes(toChainID), crossChainID),
+		cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// GetReceiptSigs returns the signatures collected for the receipt, the receipt is
+// attested by poly consensus once Attested is true.
+func GetReceiptSigs(native *native.NativeService, toChainID uint64, crossChainID []byte) (*ReceiptSigs, error) {
+	contract := utils.CrossChainManagerContractAddress
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(RECEIPT_SIGS), utils.GetUint64Bytes(toChainID), crossChainID))
+	if err != nil {
+		return nil, fmt.Errorf("GetReceiptSigs, get receipt signatures store error: %v", err)
+	}
+	sigs := &ReceiptSigs{Sigs: make(map[string][]byte)}
+	if store == nil {
+		return sigs, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetReceiptSigs, deserialize from raw storage item err: %v", err)
+	}
+	if err := sigs.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetReceiptSigs, deserialize receipt signatures error: %v", err)
+	}
+	return sigs, nil
+}
+
+// submitter returns the relayer to attribute the invoking tx to. A tx could be signed by
+// several addresses, the registered relayers are preferred and the least address wins
+// the tie so that the attribution doesn't depend on the order of the signatures.
+func submitter(native *native.NativeService) (common.Address, bool, error) {
+	addrs, err := native.GetTx().GetSignatureAddresses()
+	if err != nil {
+		return common.ADDRESS_EMPTY, false, fmt.Errorf("submitter, get signature addresses error: %v", err)
+	}
+	if len(addrs) == 0 {
+		return common.ADDRESS_EMPTY, false, nil
+	}
+	sorted := make([]common.Address, len(addrs))
+	copy(sorted, addrs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+	for _, addr := range sorted {
+		value, err := native.GetCacheDB().Get(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(relayer_manager.RELAYER), addr[:]))
+		if err != nil {
+			return common.ADDRESS_EMPTY, false, fmt.Errorf("submitter, get relayer error: %v", err)
+		}
+		if value != nil {
+			return addr, true, nil
+		}
+	}
+	return sorted[0], true, nil
+}
+
+// checkRelayer requires the tx to be signed by a registered relayer once the relayer config
+// requires it, the proofs of others are rejected
+func checkRelayer(native *native.NativeService) error {
+	config, err := relayer_manager.GetRelayerConfig(native)
+	if err != nil {
+		return fmt.Errorf("checkRelayer, %v", err)
+	}
+	if config == nil || !config.Required {
+		return nil
+	}
+	for _, addr := range native.GetWitnesses() {
+		ok, err := relayer_manager.IsRelayer(native, addr)
+		if err != nil {
+			return fmt.Errorf("checkRelayer, %v", err)
+		}
+		if ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("checkRelayer, tx is not signed by any registered relayer")
+}
+
+// attributeRelayer records the submitter of the first valid proof of the cross chain tx
+// id from chainID, the later submissions fail as the tx is done.
+func attributeRelayer(native *native.NativeService, chainID uint64, id []byte) error {
+	relayer, ok, err := submitter(native)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	contract := utils.CrossChainManagerContractAddress
+	attribution := &RelayerAttribution{
+		Relayer: relayer,
+		TxHash:  native.GetTx().Hash(),
+		Height:  native.GetHeight(),
+	}
+	sink := common.NewZeroCopySink(nil)
+	attribution.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(RELAYER_ATTRIBUTION), utils.GetUint64Bytes(chainID), id),
+		cstates.GenRawStorageItem(sink.Bytes()))
+
+	stats, err := GetRelayerStats(native, relayer)
+	if err != nil {
+		return err
+	}
+	stats.Imported++
+	stats.LastHeight = native.GetHeight()
+	sink = common.NewZeroCopySink(nil)
+	stats.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(RELAYER_STATS), relayer[:]),
+		cstates.GenRawStorageItem(sink.Bytes()))
+
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: contract,
+			States: []interface{}{NOTIFY_RELAYER_ATTRIBUTION, chainID, hex.EncodeToString(id),
+				relayer.ToBase58(), attribution.TxHash.ToHexString(), attribution.Height},
+		})
+	return nil
+}
+
+// GetRelayerAttribution returns the relayer whose proof of the cross chain tx id landed first,
+// nil if the tx is not attributed.
+func GetRelayerAttribution(native *native.NativeService, chainID uint64, id []byte) (*RelayerAttribution, error) {
+	contract := utils.CrossChainManagerContractAddress
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(RELAYER_ATTRIBUTION), utils.GetUint64Bytes(chainID), id))
+	if err != nil {
+		return nil, fmt.Errorf("GetRelayerAttribution, get attribution store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetRelayerAttribution, deserialize from raw storage item err: %v", err)
+	}
+	attribution := new(RelayerAttribution)
+	if err := attribution.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetRelayerAttribution, deserialize attribution error: %v", err)
+	}
+	return attribution, nil
+}
+
+// GetRelayerStats returns the statistics of the cross chain txs attributed to relayer
+func GetRelayerStats(native *native.NativeService, relayer common.Address) (*RelayerStats, error) {
+	contract := utils.CrossChainManagerContractAddress
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(RELAYER_STATS), relayer[:]))
+	if err != nil {
+		return nil, fmt.Errorf("GetRelayerStats, get relayer stats store error: %v", err)
+	}
+	stats := new(RelayerStats)
+	if store == nil {
+		return stats, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetRelayerStats, deserialize from raw storage item err: %v", err)
+	}
+	if err := stats.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetRelayerStats, deserialize relayer stats error: %v", err)
+	}
+	return stats, nil
+}
+
+// GetOutboundSequence returns the number of the payloads emitted to toChainID, which is
+// the sequence of the next one.
+func GetOutboundSequence(native *native.NativeService, toChainID uint64) (uint64, error) {
+	contract := utils.CrossChainManagerContractAddress
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(OUTBOUND_SEQUENCE), utils.GetUint64Bytes(toChainID)))
+	if err != nil {
+		return 0, fmt.Errorf("GetOutboundSequence, get sequence store error: %v", err)
+	}
+	if store == nil {
+		return 0, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("GetOutboundSequence, deserialize from raw storage item err: %v", err)
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+// putOutboundPayload appends the payload of txHash to the queue of toChainID
+func putOutboundPayload(native *native.NativeService, toChainID uint64, txHash []byte) error {
+	contract := utils.CrossChainManagerContractAddress
+	seq, err := GetOutboundSequence(native, toChainID)
+	if err != nil {
+		return err
+	}
+	payload := &OutboundPayload{
+		TxHash:     txHash,
+		PolyHeight: native.GetHeight(),
+	}
+	sink := common.NewZeroCopySink(nil)
+	payload.Serialization(sink)
+	chainIDBytes := utils.GetUint64Bytes(toChainID)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(OUTBOUND_PAYLOAD), chainIDBytes, utils.GetUint64Bytes(seq)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(OUTBOUND_SEQUENCE), chainIDBytes),
+		cstates.GenRawStorageItem(utils.GetUint64Bytes(seq+1)))
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cross_chain_manager
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// CorrectBtcUtxos replaces the utxos tracked for a redeem of a utxo chain once the consensus peers
+// approve the same set, to repair it after a rollback of the chain or an accounting bug
+func CorrectBtcUtxos(native *native.NativeService) ([]byte, error) {
+	params := new(CorrectBtcUtxosParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CorrectBtcUtxos, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CorrectBtcUtxos, checkWitness error: %v", err)
+	}
+
+	sideChain, err := side_chain_manager.GetSideChain(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CorrectBtcUtxos, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CorrectBtcUtxos, side chain %d is not registered", params.ChainID)
+	}
+	if sideChain.Router != utils.BTC_ROUTER && sideChain.Router != utils.BCH_ROUTER && sideChain.Router != utils.ZCASH_ROUTER {
+		return utils.BYTE_FALSE, fmt.Errorf("CorrectBtcUtxos, side chain %d is not a utxo chain", params.ChainID)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(params.ChainID)
+	sink.WriteString(params.RedeemKey)
+	params.Utxos.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, CORRECT_BTC_UTXOS, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CorrectBtcUtxos, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	before, after, err := btc.CorrectUtxos(native, params.ChainID, params.RedeemKey, params.Utxos)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CorrectBtcUtxos, %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"correctBtcUtxos", params.ChainID, params.RedeemKey, before, after},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// GetBtcUtxosQuery returns the utxos tracked for a redeem of a utxo chain, to be called by preExec
+func GetBtcUtxosQuery(native *native.NativeService) ([]byte, error) {
+	params := new(BtcUtxosParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetBtcUtxosQuery, contract params deserialize error: %v", err)
+	}
+	utxos, err := btc.GetUtxoSet(native, params.ChainID, params.RedeemKey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetBtcUtxosQuery, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	utxos.Serialization(sink)
+	return sink.Bytes(), nil
+}
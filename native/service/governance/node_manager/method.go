@@ -19,10 +19,59 @@ package node_manager
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
+// ViewChangeHook is run by executeCommitDpos once the new view and its peer pool are stored
+type ViewChangeHook func(native *native.NativeService, view uint32)
+
+// orders of the view change hooks, the housekeeping of the contracts runs before the keepers of the new
+// view are pushed and the epoch is summarized last
+const (
+	VIEW_CHANGE_HOUSEKEEPING = 0
+	VIEW_CHANGE_PUSH         = 10
+	VIEW_CHANGE_SUMMARY      = 20
+)
+
+type viewChangeHook struct {
+	name  string
+	order int
+	hook  ViewChangeHook
+}
+
+// viewChangeHooks are registered by the contracts depending on node_manager, which can't be called from here
+var viewChangeHooks []*viewChangeHook
+
+// RegisterViewChangeHook registers hook by name from the init of a contract. The hooks run in ascending order
+// and then by name, so the order doesn't depend on the order the packages are initialized in.
+func RegisterViewChangeHook(name string, order int, hook ViewChangeHook) {
+	for _, v := range viewChangeHooks {
+		if v.name == name {
+			panic(fmt.Sprintf("view change hook %s is already registered", name))
+		}
+	}
+	viewChangeHooks = append(viewChangeHooks, &viewChangeHook{name: name, order: order, hook: hook})
+	sort.SliceStable(viewChangeHooks, func(i, j int) bool {
+		if viewChangeHooks[i].order != viewChangeHooks[j].order {
+			return viewChangeHooks[i].order < viewChangeHooks[j].order
+		}
+		return viewChangeHooks[i].name < viewChangeHooks[j].name
+	})
+}
+
+// ViewChangeHooks returns the names of the hooks registered in the order they run
+func ViewChangeHooks() []string {
+	names := make([]string, 0, len(viewChangeHooks))
+	for _, v := range viewChangeHooks {
+		names = append(names, v.name)
+	}
+	return names
+}
+
 func executeCommitDpos(native *native.NativeService) error {
 	governanceView, err := GetGovernanceView(native)
 	if err != nil {
@@ -41,7 +90,8 @@ func executeCommitDpos(native *native.NativeService) error {
 		return fmt.Errorf("executeCommitDpos, get peerPoolMap error: %v", err)
 	}
 
-	for k, peerPoolItem := range peerPoolMap.PeerPoolMap {
+	for _, k := range peerPoolMap.SortedPubkeys() {
+		peerPoolItem := peerPoolMap.PeerPoolMap[k]
 		if peerPoolItem.Status == QuitingStatus {
 			delete(peerPoolMap.PeerPoolMap, peerPoolItem.PeerPubkey)
 		}
@@ -58,6 +108,8 @@ func executeCommitDpos(native *native.NativeService) error {
 	oldView := view - 1
 	oldViewBytes := utils.GetUint32Bytes(oldView)
 	native.GetCacheDB().Delete(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PEER_POOL), oldViewBytes))
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(MUTATION_COUNT),
+		utils.GetUint32Bytes(view)))
 
 	//update view
 	governanceView = &GovernanceView{
@@ -66,5 +118,36 @@ func executeCommitDpos(native *native.NativeService) error {
 		TxHash: native.GetTx().Hash(),
 	}
 	putGovernanceView(native, governanceView)
+	if err := activatePreConfig(native, newView); err != nil {
+		return fmt.Errorf("executeCommitDpos, %v", err)
+	}
+	// the peers of the new view list again what they sign
+	dropPendingConsensusSigns(native)
+	for _, v := range viewChangeHooks {
+		v.hook(native, newView)
+	}
+	return nil
+}
+
+// activatePreConfig puts the config updated in the ended view in force for view
+func activatePreConfig(native *native.NativeService, view uint32) error {
+	preConfig, err := getPreConfig(native)
+	if err != nil {
+		return err
+	}
+	if preConfig == nil {
+		return nil
+	}
+	config, err := GetConfig(native)
+	if err != nil {
+		return fmt.Errorf("activatePreConfig, get config error: %v", err)
+	}
+	putConfig(native, preConfig)
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PRE_CONFIG)))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"activateConfig", view, config, preConfig},
+		})
 	return nil
 }
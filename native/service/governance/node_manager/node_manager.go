@@ -18,8 +18,11 @@
 package node_manager
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"math"
+
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/core/genesis"
@@ -37,27 +40,61 @@ const (
 	BlackStatus
 
 	//function name
-	REGISTER_CANDIDATE   = "registerCandidate"
-	UNREGISTER_CANDIDATE = "unRegisterCandidate"
-	APPROVE_CANDIDATE    = "approveCandidate"
-	BLACK_NODE           = "blackNode"
-	WHITE_NODE           = "whiteNode"
-	QUIT_NODE            = "quitNode"
-	UPDATE_CONFIG        = "updateConfig"
-	COMMIT_DPOS          = "commitDpos"
+	REGISTER_CANDIDATE     = "registerCandidate"
+	UNREGISTER_CANDIDATE   = "unRegisterCandidate"
+	APPROVE_CANDIDATE      = "approveCandidate"
+	BLACK_NODE             = "blackNode"
+	WHITE_NODE             = "whiteNode"
+	QUIT_NODE              = "quitNode"
+	UPDATE_CONFIG          = "updateConfig"
+	COMMIT_DPOS            = "commitDpos"
+	RESET_CONFIG           = "resetConfig"
+	SET_APPLY_LIMIT        = "setApplyLimit"
+	GET_PENDING_APPLIES    = "getPendingApplies"
+	GET_BLACK_LIST         = "getBlackList"
+	VALIDATE_CONFIG        = "validateConfig"
+	GET_PEER_INDEXES       = "getPeerIndexes"
+	SLASH                  = "slash"
+	SET_SLASH_LIMIT        = "setSlashLimit"
+	GET_EVIDENCES          = "getEvidences"
+	SET_OPERATOR_COMMITTEE = "setOperatorCommittee"
+	APPROVE_OPERATOR_CALL  = "approveOperatorCall"
+	GET_OPERATOR_COMMITTEE = "getOperatorCommittee"
+	SET_MUTATION_LIMIT     = "setMutationLimit"
+	GET_PENDING_SIGNS      = "getPendingConsensusSigns"
 
 	//key prefix
-	GOVERNANCE_VIEW = "governanceView"
-	VBFT_CONFIG     = "vbftConfig"
-	CANDIDITE_INDEX = "candidateIndex"
-	PEER_APPLY      = "peerApply"
-	PEER_POOL       = "peerPool"
-	PEER_INDEX      = "peerIndex"
-	BLACK_LIST      = "blackList"
-	CONSENSUS_SIGNS = "consensusSigns"
+	GOVERNANCE_VIEW    = "governanceView"
+	VBFT_CONFIG        = "vbftConfig"
+	PRE_CONFIG         = "preConfig"
+	CANDIDITE_INDEX    = "candidateIndex"
+	PEER_APPLY         = "peerApply"
+	PEER_POOL          = "peerPool"
+	PEER_INDEX         = "peerIndex"
+	INDEX_PEER         = "indexPeer"
+	BLACK_LIST         = "blackList"
+	CONSENSUS_SIGNS    = "consensusSigns"
+	DEV_CHAIN          = "devChain"
+	APPLY_LIMIT        = "applyLimit"
+	APPLY_QUEUE        = "applyQueue"
+	EVIDENCE           = "evidence"
+	SLASH_LIMIT        = "slashLimit"
+	OPERATOR_COMMITTEE = "operatorCommittee"
+	OPERATOR_SIGNS     = "operatorSigns"
+	MUTATION_LIMIT     = "mutationLimit"
+	MUTATION_COUNT     = "mutationCount"
+
+	PENDING_CONSENSUS_SIGNS = "pendingConsensusSigns"
 
 	//const
 	MIN_PEER_NUM = 4
+	// blocks a proposal of blackNode collects the approvals of the consensus peers in before it expires
+	BLACK_NODE_EXPIRY = 100000
+
+	//kind of evidence
+	EVIDENCE_DOUBLE_SIGN uint8 = 1
+	//downtime can not be proved by the signed messages of the peer, slash does not accept it
+	EVIDENCE_DOWNTIME uint8 = 2
 )
 
 //Register methods of node_manager contract
@@ -71,6 +108,20 @@ func RegisterNodeManagerContract(native *native.NativeService) {
 	native.Register(WHITE_NODE, WhiteNode)
 	native.Register(UPDATE_CONFIG, UpdateConfig)
 	native.Register(COMMIT_DPOS, CommitDpos)
+	native.Register(RESET_CONFIG, ResetConfig)
+	native.Register(SET_APPLY_LIMIT, SetApplyLimit)
+	native.Register(GET_PENDING_APPLIES, GetPendingAppliesQuery)
+	native.Register(GET_BLACK_LIST, GetBlackListQuery)
+	native.Register(VALIDATE_CONFIG, ValidateConfigQuery)
+	native.Register(GET_PEER_INDEXES, GetPeerIndexesQuery)
+	native.Register(SLASH, Slash)
+	native.Register(SET_SLASH_LIMIT, SetSlashLimit)
+	native.Register(GET_EVIDENCES, GetEvidencesQuery)
+	native.Register(SET_OPERATOR_COMMITTEE, SetOperatorCommittee)
+	native.Register(APPROVE_OPERATOR_CALL, ApproveOperatorCall)
+	native.Register(GET_OPERATOR_COMMITTEE, GetOperatorCommitteeQuery)
+	native.Register(SET_MUTATION_LIMIT, SetMutationLimit)
+	native.Register(GET_PENDING_SIGNS, GetPendingConsensusSignsQuery)
 }
 
 //Init node_manager contract
@@ -90,10 +141,66 @@ func InitConfig(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("initConfig. initConfig is already executed")
 	}
 
+	if err := initConfig(native, configuration); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("initConfig, %v", err)
+	}
+	if configuration.DevChain {
+		native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(DEV_CHAIN)), cstates.GenRawStorageItem([]byte{1}))
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+// ResetConfig runs the initialization of node_manager again on a network whose genesis sets the dev chain
+// flag, the peer pool, governance views and the config left by the previous run are cleared first.
+func ResetConfig(native *native.NativeService) ([]byte, error) {
+	configuration := new(config.VBFTConfig)
+	if err := configuration.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("resetConfig, contract params deserialize error: %v", err)
+	}
+	contract := utils.NodeManagerContractAddress
+
+	devChain, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(DEV_CHAIN)))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("resetConfig, get dev chain flag error: %v", err)
+	}
+	if devChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("resetConfig, only allowed on a dev chain")
+	}
+
+	// Get current epoch operator
+	operatorAddress, err := GetCurConOperator(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("resetConfig, get current consensus operator address error: %v", err)
+	}
+	//check witness
+	err = utils.ValidateOwner(native, operatorAddress)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("resetConfig, checkWitness error: %v", err)
+	}
+
+	for _, prefix := range []string{GOVERNANCE_VIEW, VBFT_CONFIG, PRE_CONFIG, CANDIDITE_INDEX, PEER_APPLY, PEER_POOL, PEER_INDEX,
+		INDEX_PEER, BLACK_LIST, CONSENSUS_SIGNS, PENDING_CONSENSUS_SIGNS, APPLY_LIMIT, APPLY_QUEUE} {
+		deletePrefix(native, utils.ConcatKey(contract, []byte(prefix)))
+	}
+	if err := initConfig(native, configuration); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("resetConfig, %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: contract,
+			States:          []interface{}{"resetConfig", len(configuration.Peers)},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// initConfig puts the peer pool, governance view and config of the first view from configuration
+func initConfig(native *native.NativeService, configuration *config.VBFTConfig) error {
+	contract := utils.NodeManagerContractAddress
+
 	//check the configuration
-	err = CheckVBFTConfig(configuration)
+	err := CheckVBFTConfig(configuration)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("initConfig, checkVBFTConfig failed: %v", err)
+		return fmt.Errorf("checkVBFTConfig failed: %v", err)
 	}
 
 	var view uint32 = 1
@@ -108,7 +215,7 @@ func InitConfig(native *native.NativeService) ([]byte, error) {
 		}
 		address, err := common.AddressFromBase58(peer.Address)
 		if err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("initConfig, address format error: %v", err)
+			return fmt.Errorf("address format error: %v", err)
 		}
 
 		peerPoolItem := new(PeerPoolItem)
@@ -120,11 +227,14 @@ func InitConfig(native *native.NativeService) ([]byte, error) {
 
 		peerPubkeyPrefix, err := hex.DecodeString(peerPoolItem.PeerPubkey)
 		if err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("initConfig, peerPubkey format error: %v", err)
+			return fmt.Errorf("peerPubkey format error: %v", err)
 		}
-		index := peerPoolItem.Index
-		indexBytes := utils.GetUint32Bytes(index)
-		native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(PEER_INDEX), peerPubkeyPrefix), cstates.GenRawStorageItem(indexBytes))
+		if err := assignPeerIndex(native, peerPubkeyPrefix, peerPoolItem.Index); err != nil {
+			return err
+		}
+	}
+	if maxId == math.MaxUint32 {
+		return fmt.Errorf("index of peers can't be %d", maxId)
 	}
 
 	//init peer pool
@@ -149,8 +259,7 @@ func InitConfig(native *native.NativeService) ([]byte, error) {
 		MaxBlockChangeView:   configuration.MaxBlockChangeView,
 	}
 	putConfig(native, config)
-
-	return utils.BYTE_TRUE, nil
+	return nil
 }
 
 //Register a candidate node, used by users.
@@ -176,13 +285,21 @@ func RegisterCandidate(native *native.NativeService) ([]byte, error) {
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("registerCandidate, peerPubkey format error: %v", err)
 	}
-	//get black list
-	blackList, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix))
+	//get current view
+	view, err := GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("registerCandidate, get view error: %v", err)
+	}
+	//get black list, an expired blacking is lifted by the registration
+	blackListItem, err := getBlackListItem(native, peerPubkeyPrefix)
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("registerCandidate, get BlackList error: %v", err)
 	}
-	if blackList != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("registerCandidate, this Peer is in BlackList")
+	if blackListItem != nil {
+		if !blackListItem.Expired(view) {
+			return utils.BYTE_FALSE, fmt.Errorf("registerCandidate, this Peer is in BlackList")
+		}
+		native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix))
 	}
 
 	//check if applied
@@ -194,11 +311,6 @@ func RegisterCandidate(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("registerCandidate, peer already applied")
 	}
 
-	//get current view
-	view, err := GetView(native)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("registerCandidate, get view error: %v", err)
-	}
 	//get peerPoolMap
 	peerPoolMap, err := GetPeerPoolMap(native, view)
 	if err != nil {
@@ -209,6 +321,25 @@ func RegisterCandidate(native *native.NativeService) ([]byte, error) {
 	if ok {
 		return utils.BYTE_FALSE, fmt.Errorf("registerCandidate, peerPubkey is already in peerPoolMap")
 	}
+	if err := countMutation(native, view); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("registerCandidate, %v", err)
+	}
+
+	//check the limit of pending applications
+	evicted, err := enqueueApply(native, params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("registerCandidate, %v", err)
+	}
+	for _, peerPubkey := range evicted {
+		if err := deletePeerApply(native, peerPubkey); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("registerCandidate, %v", err)
+		}
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.NodeManagerContractAddress,
+				States:          []interface{}{"evictCandidate", peerPubkey},
+			})
+	}
 
 	err = putPeerApply(native, params)
 	if err != nil {
@@ -254,6 +385,9 @@ func UnRegisterCandidate(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("unRegisterCandidate, peerPubkey format error: %v", err)
 	}
 	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(PEER_APPLY), peerPubkeyPrefix))
+	if err := dequeueApply(native, params.PeerPubkey); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("unRegisterCandidate, %v", err)
+	}
 	native.AddNotify(
 		&event.NotifyEventInfo{
 			ContractAddress: utils.NodeManagerContractAddress,
@@ -293,6 +427,13 @@ func ApproveCandidate(native *native.NativeService) ([]byte, error) {
 	if !ok {
 		return utils.BYTE_TRUE, nil
 	}
+	view, err := GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approveCandidate, get view error: %v", err)
+	}
+	if err := countMutation(native, view); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approveCandidate, %v", err)
+	}
 
 	peerPoolItem := &PeerPoolItem{
 		PeerPubkey: peer.PeerPubkey,
@@ -321,21 +462,20 @@ func ApproveCandidate(native *native.NativeService) ([]byte, error) {
 		if err != nil {
 			return nil, fmt.Errorf("approveCandidate, get candidateIndex error: %v", err)
 		}
+		if candidateIndex == math.MaxUint32 {
+			return utils.BYTE_FALSE, fmt.Errorf("approveCandidate, candidate indexes are used up")
+		}
 		peerPoolItem.Index = candidateIndex
 
 		//update candidateIndex
 		newCandidateIndex := candidateIndex + 1
 		putCandidateIndex(native, newCandidateIndex)
-
-		indexBytes := utils.GetUint32Bytes(peerPoolItem.Index)
-		native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(PEER_INDEX), peerPubkeyPrefix), cstates.GenRawStorageItem(indexBytes))
 	}
-
-	//get current view
-	view, err := GetView(native)
-	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("approveCandidate, get view error: %v", err)
+	// the index kept by a peer approved again is recorded for the index query as well
+	if err := assignPeerIndex(native, peerPubkeyPrefix, peerPoolItem.Index); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approveCandidate, %v", err)
 	}
+
 	//get peerPoolMap
 	peerPoolMap, err := GetPeerPoolMap(native, view)
 	if err != nil {
@@ -347,6 +487,9 @@ func ApproveCandidate(native *native.NativeService) ([]byte, error) {
 	putPeerPoolMap(native, peerPoolMap, view)
 
 	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(PEER_APPLY), peerPubkeyPrefix))
+	if err := dequeueApply(native, params.PeerPubkey); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approveCandidate, %v", err)
+	}
 
 	native.AddNotify(
 		&event.NotifyEventInfo{
@@ -358,12 +501,13 @@ func ApproveCandidate(native *native.NativeService) ([]byte, error) {
 
 //Put a node into black list, remove node from pool
 //Node in black list can't be registered.
+//The proposal is executed by the approval reaching two thirds of the consensus peers, approvals older than
+//BLACK_NODE_EXPIRY blocks are dropped.
 func BlackNode(native *native.NativeService) ([]byte, error) {
 	params := new(PeerListParam)
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("blackNode, contract params deserialize error: %v", err)
 	}
-	contract := utils.NodeManagerContractAddress
 
 	//check witness
 	err := utils.ValidateOwner(native, params.Address)
@@ -406,50 +550,25 @@ func BlackNode(native *native.NativeService) ([]byte, error) {
 	for _, v := range params.PeerPubkeyList {
 		input = append(input, []byte(v)...)
 	}
-	//check consensus signs
-	ok, err := CheckConsensusSigns(native, BLACK_NODE, input, params.Address)
+	// the signers agree on the terms of the blacking as well, kept out when unset so pending signs stay valid
+	if params.Reason != "" || params.Views != 0 {
+		input = append(input, []byte(params.Reason)...)
+		input = append(input, utils.GetUint32Bytes(params.Views)...)
+	}
+	//check consensus signs, blacking a peer takes the approvals collected within BLACK_NODE_EXPIRY blocks
+	ok, err := checkConsensusSigns(native, BLACK_NODE, input, params.Address, BLACK_NODE_EXPIRY)
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("blackNode, CheckConsensusSigns error: %v", err)
 	}
 	if !ok {
 		return utils.BYTE_TRUE, nil
 	}
-
-	commit := false
-	for _, peerPubkey := range params.PeerPubkeyList {
-		peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
-		if err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("blackNode, peerPubkey format error: %v", err)
-		}
-		peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
-		if !ok {
-			return utils.BYTE_FALSE, fmt.Errorf("blackNode, peerPubkey is not in peerPoolMap")
-		}
-
-		blackListItem := &BlackListItem{
-			PeerPubkey: peerPoolItem.PeerPubkey,
-			Address:    peerPoolItem.Address,
-		}
-		sink := common.NewZeroCopySink(nil)
-		blackListItem.Serialization(sink)
-		//put peer into black list
-		native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), cstates.GenRawStorageItem(sink.Bytes()))
-
-		//change peerPool status
-		if peerPoolItem.Status == ConsensusStatus {
-			commit = true
-		}
-		peerPoolItem.Status = BlackStatus
-		peerPoolMap.PeerPoolMap[peerPubkey] = peerPoolItem
+	if err := countMutation(native, view); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("blackNode, %v", err)
 	}
-	putPeerPoolMap(native, peerPoolMap, view)
 
-	//commitDpos
-	if commit {
-		err = executeCommitDpos(native)
-		if err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("blackNode, executeCommitDpos error: %v", err)
-		}
+	if err := blackPeers(native, view, peerPoolMap, params.PeerPubkeyList, params.Reason, params.Views); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("blackNode, %v", err)
 	}
 	native.AddNotify(
 		&event.NotifyEventInfo{
@@ -494,6 +613,13 @@ func WhiteNode(native *native.NativeService) ([]byte, error) {
 	if !ok {
 		return utils.BYTE_TRUE, nil
 	}
+	view, err := GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("whiteNode, get view error: %v", err)
+	}
+	if err := countMutation(native, view); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("whiteNode, %v", err)
+	}
 
 	//remove peer from black list
 	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix))
@@ -626,24 +752,504 @@ func UpdateConfig(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("updateConfig, checkWitness error: %v", err)
 	}
 
-	if params.Configuration.BlockMsgDelay < 5000 {
-		return utils.BYTE_FALSE, fmt.Errorf("updateConfig. BlockMsgDelay must >= 5000")
+	peerNum, err := getPeerNum(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("updateConfig, %v", err)
+	}
+	if violations := checkConfig(params.Configuration, peerNum); len(violations) > 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("updateConfig. %s", violations[0])
 	}
-	if params.Configuration.HashMsgDelay < 5000 {
-		return utils.BYTE_FALSE, fmt.Errorf("updateConfig. HashMsgDelay must >= 5000")
+
+	// the config is read by consensus at the view change, it's kept as PreConfig until then
+	putPreConfig(native, params.Configuration)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"updateConfig", params.Configuration},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// SetApplyLimit bounds the number of pending candidate applications, once the limit is reached
+// the oldest application is evicted by a new one if Evict is set, otherwise new ones are rejected.
+// Limit 0 removes the bound.
+func SetApplyLimit(native *native.NativeService) ([]byte, error) {
+	params := new(ApplyLimitParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setApplyLimit, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setApplyLimit, checkWitness error: %v", err)
 	}
-	if params.Configuration.PeerHandshakeTimeout < 10 {
-		return utils.BYTE_FALSE, fmt.Errorf("updateConfig. PeerHandshakeTimeout must >= 10")
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := CheckConsensusSigns(native, SET_APPLY_LIMIT, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setApplyLimit, CheckConsensusSigns error: %v", err)
 	}
-	if params.Configuration.MaxBlockChangeView < 10000 {
-		return utils.BYTE_FALSE, fmt.Errorf("updateConfig. MaxBlockChangeView must >= 10000")
+	if !ok {
+		return utils.BYTE_TRUE, nil
 	}
 
-	putConfig(native, params.Configuration)
+	if params.Limit == 0 {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(APPLY_LIMIT)))
+	} else {
+		putApplyLimit(native, &ApplyLimit{Limit: params.Limit, Evict: params.Evict})
+	}
 	native.AddNotify(
 		&event.NotifyEventInfo{
 			ContractAddress: utils.NodeManagerContractAddress,
-			States:          []interface{}{"updateConfig", params.Configuration},
+			States:          []interface{}{"setApplyLimit", params.Limit, params.Evict},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// SetMutationLimit bounds the registrations, approvals, blackings and whitings taking effect in one view,
+// 0 removes the bound
+func SetMutationLimit(native *native.NativeService) ([]byte, error) {
+	params := new(MutationLimitParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setMutationLimit, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setMutationLimit, checkWitness error: %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := CheckConsensusSigns(native, SET_MUTATION_LIMIT, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setMutationLimit, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	key := utils.ConcatKey(utils.NodeManagerContractAddress, []byte(MUTATION_LIMIT))
+	if params.Limit == 0 {
+		native.GetCacheDB().Delete(key)
+	} else {
+		native.GetCacheDB().Put(key, cstates.GenRawStorageItem(utils.GetUint32Bytes(params.Limit)))
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"setMutationLimit", params.Limit},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// Slash records the evidence of a double sign of a peer once approved by the consensus peers, the peer is
+// blacked when the evidences against it reach the slash limit. The evidence is verified before it's
+// counted: two different block headers at the height both signed by the peer.
+func Slash(native *native.NativeService) ([]byte, error) {
+	params := new(SlashParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, checkWitness error: %v", err)
+	}
+
+	if params.Kind != EVIDENCE_DOUBLE_SIGN {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, kind %d of evidence can not be verified", params.Kind)
+	}
+	peerPubkeyPrefix, err := hex.DecodeString(params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, peerPubkey format error: %v", err)
+	}
+	if err := verifyDoubleSign(peerPubkeyPrefix, params.Height, params.Evidence); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, %v", err)
+	}
+
+	//get current view
+	view, err := GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, get view error: %v", err)
+	}
+	//get peerPoolMap
+	peerPoolMap, err := GetPeerPoolMap(native, view)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, get peerPoolMap error: %v", err)
+	}
+	if _, ok := peerPoolMap.PeerPoolMap[params.PeerPubkey]; !ok {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, peerPubkey: %s is not in peerPoolMap", params.PeerPubkey)
+	}
+
+	evidences, err := getEvidenceList(native, peerPubkeyPrefix)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, %v", err)
+	}
+	for _, v := range evidences.Items {
+		if v.Kind == params.Kind && v.Height == params.Height {
+			return utils.BYTE_FALSE, fmt.Errorf("slash, evidence of kind %d at height %d is already recorded", params.Kind, params.Height)
+		}
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteString(params.PeerPubkey)
+	sink.WriteUint8(params.Kind)
+	sink.WriteUint32(params.Height)
+	sink.WriteVarBytes(params.Evidence)
+	ok, err := CheckConsensusSigns(native, SLASH, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	evidences.Items = append(evidences.Items, &Evidence{
+		Kind:         params.Kind,
+		Height:       params.Height,
+		Data:         params.Evidence,
+		RecordHeight: native.GetHeight(),
+	})
+	putEvidenceList(native, peerPubkeyPrefix, evidences)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"slash", params.PeerPubkey, params.Kind, params.Height, len(evidences.Items)},
+		})
+
+	limit, err := GetSlashLimit(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, %v", err)
+	}
+	if limit == nil || uint32(len(evidences.Items)) < limit.Limit ||
+		peerPoolMap.PeerPoolMap[params.PeerPubkey].Status == BlackStatus {
+		return utils.BYTE_TRUE, nil
+	}
+	//keep the consensus alive, the peer is left for blackNode if it can not be blacked safely
+	num := 0
+	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+		if peerPoolItem.Status == CandidateStatus || peerPoolItem.Status == ConsensusStatus {
+			num = num + 1
+		}
+	}
+	if num <= MIN_PEER_NUM {
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.NodeManagerContractAddress,
+				States:          []interface{}{"slashBlackSkipped", params.PeerPubkey, num},
+			})
+		return utils.BYTE_TRUE, nil
+	}
+	if err := blackPeers(native, view, peerPoolMap, []string{params.PeerPubkey}, "slashed", limit.Views); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("slash, %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"blackNode", []string{params.PeerPubkey}},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// SetSlashLimit sets the number of evidences a peer is blacked at, for Views views or for ever if 0.
+// Limit 0 turns the blacking off.
+func SetSlashLimit(native *native.NativeService) ([]byte, error) {
+	params := new(SlashLimitParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setSlashLimit, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setSlashLimit, checkWitness error: %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := CheckConsensusSigns(native, SET_SLASH_LIMIT, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setSlashLimit, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if params.Limit == 0 {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(SLASH_LIMIT)))
+	} else {
+		putSlashLimit(native, &SlashLimit{Limit: params.Limit, Views: params.Views})
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"setSlashLimit", params.Limit, params.Views},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// GetEvidencesQuery returns the evidences recorded against the peer of the hex encoded pubkey in the
+// input, to be called by preExec
+func GetEvidencesQuery(native *native.NativeService) ([]byte, error) {
+	peerPubkeyPrefix, err := hex.DecodeString(string(native.GetInput()))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getEvidences, peerPubkey format error: %v", err)
+	}
+	evidences, err := getEvidenceList(native, peerPubkeyPrefix)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getEvidences, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	evidences.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// SetOperatorCommittee hands the operator over to a committee of Threshold out of Members, it's called by
+// the current operator, which is the committee itself once set. No members hands the operator back to the
+// consensus peers.
+func SetOperatorCommittee(native *native.NativeService) ([]byte, error) {
+	params := new(OperatorCommitteeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setOperatorCommittee, contract params deserialize error: %v", err)
+	}
+
+	// Get current epoch operator
+	operatorAddress, err := GetCurConOperator(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setOperatorCommittee, get current consensus operator address error: %v", err)
+	}
+	//check witness
+	err = utils.ValidateOwner(native, operatorAddress)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("setOperatorCommittee, checkWitness error: %v", err)
+	}
+
+	contract := utils.NodeManagerContractAddress
+	if len(params.Members) == 0 {
+		native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(OPERATOR_COMMITTEE)))
+	} else {
+		if err := checkOperatorCommittee(params.Members, params.Threshold); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("setOperatorCommittee, %v", err)
+		}
+		putOperatorCommittee(native, &OperatorCommittee{Members: params.Members, Threshold: params.Threshold})
+	}
+	// approvals of the old committee don't count for the new one
+	deletePrefix(native, utils.ConcatKey(contract, []byte(OPERATOR_SIGNS)))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: contract,
+			States:          []interface{}{"setOperatorCommittee", len(params.Members), params.Threshold},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// ApproveOperatorCall records the approval of a committee member on a call of the operator, the first
+// approval proposes the call and it's made once the approvals reach the threshold of the committee
+func ApproveOperatorCall(native *native.NativeService) ([]byte, error) {
+	params := new(OperatorCallParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approveOperatorCall, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approveOperatorCall, checkWitness error: %v", err)
+	}
+
+	committee, err := GetOperatorCommittee(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approveOperatorCall, %v", err)
+	}
+	if committee == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approveOperatorCall, no operator committee is set")
+	}
+	if !committee.IsMember(params.Address) {
+		return utils.BYTE_FALSE, fmt.Errorf("approveOperatorCall, %s is not a member of the operator committee", params.Address.ToBase58())
+	}
+	if !isOperatorMethod(params.Contract, params.Method) {
+		return utils.BYTE_FALSE, fmt.Errorf("approveOperatorCall, %s of %s is not guarded by the operator", params.Method,
+			params.Contract.ToHexString())
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(params.Contract[:])
+	sink.WriteString(params.Method)
+	sink.WriteVarBytes(params.Args)
+	key := common.Uint256(sha256.Sum256(sink.Bytes()))
+	signs, err := getOperatorSigns(native, key)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approveOperatorCall, %v", err)
+	}
+	if len(signs.SignsMap) == 0 {
+		signs.Height = native.GetHeight()
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.NodeManagerContractAddress,
+				States:          []interface{}{"proposeOperatorCall", key.ToHexString(), params.Contract.ToHexString(), params.Method, params.Address.ToBase58()},
+			})
+	}
+	signs.SignsMap[params.Address] = true
+	// the other members signing the tx approve as well
+	for _, v := range native.GetWitnesses() {
+		if committee.IsMember(v) {
+			signs.SignsMap[v] = true
+		}
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"approveOperatorCall", key.ToHexString(), len(signs.SignsMap), committee.Threshold},
 		})
+	if uint32(len(signs.SignsMap)) < committee.Threshold {
+		putOperatorSigns(native, key, signs)
+		return utils.BYTE_TRUE, nil
+	}
+
+	deleteOperatorSigns(native, key)
+	if _, err := native.NativeCall(params.Contract, params.Method, params.Args); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("approveOperatorCall, call %s of %s error: %v", params.Method,
+			params.Contract.ToHexString(), err)
+	}
 	return utils.BYTE_TRUE, nil
 }
+
+// GetOperatorCommitteeQuery returns the operator committee, empty if the chain is operated by the consensus
+// peers, to be called by preExec
+func GetOperatorCommitteeQuery(native *native.NativeService) ([]byte, error) {
+	committee, err := GetOperatorCommittee(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getOperatorCommittee, %v", err)
+	}
+	if committee == nil {
+		committee = &OperatorCommittee{}
+	}
+	sink := common.NewZeroCopySink(nil)
+	committee.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// GetPendingConsensusSignsQuery returns the proposals waiting for the signs of the consensus peers, to be
+// called by preExec
+func GetPendingConsensusSignsQuery(native *native.NativeService) ([]byte, error) {
+	pending, err := GetPendingConsensusSigns(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getPendingConsensusSigns, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	pending.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// GetPendingAppliesQuery returns the pending candidate applications from the oldest with their ages
+// in blocks, to be called by preExec. The applications made before they were queued come first with
+// height and age 0.
+func GetPendingAppliesQuery(native *native.NativeService) ([]byte, error) {
+	queue, err := getApplyQueue(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getPendingApplies, %v", err)
+	}
+	queued := make(map[string]bool, len(queue.Items))
+	for _, v := range queue.Items {
+		queued[v.PeerPubkey] = true
+	}
+	applies := &PendingApplies{}
+	iter := native.GetCacheDB().NewIterator(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PEER_APPLY)))
+	for has := iter.First(); has; has = iter.Next() {
+		value, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			iter.Release()
+			return utils.BYTE_FALSE, fmt.Errorf("getPendingApplies, deserialize from raw storage item error: %v", err)
+		}
+		peer := new(RegisterPeerParam)
+		if err := peer.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			iter.Release()
+			return utils.BYTE_FALSE, fmt.Errorf("getPendingApplies, deserialize peer error: %v", err)
+		}
+		if !queued[peer.PeerPubkey] {
+			applies.Items = append(applies.Items, &PendingApply{PeerPubkey: peer.PeerPubkey, Address: peer.Address})
+		}
+	}
+	iter.Release()
+	for _, v := range queue.Items {
+		peer, err := GetPeerApply(native, v.PeerPubkey)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("getPendingApplies, GetPeerApply error: %v", err)
+		}
+		if peer == nil {
+			continue
+		}
+		applies.Items = append(applies.Items, &PendingApply{
+			PeerPubkey: v.PeerPubkey,
+			Address:    peer.Address,
+			Height:     v.Height,
+			Age:        native.GetHeight() - v.Height,
+		})
+	}
+	sink := common.NewZeroCopySink(nil)
+	applies.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// GetBlackListQuery returns all blacked peers with the reason, height and expiry of the blacking
+func GetBlackListQuery(native *native.NativeService) ([]byte, error) {
+	blackList := &BlackList{}
+	iter := native.GetCacheDB().NewIterator(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(BLACK_LIST)))
+	for has := iter.First(); has; has = iter.Next() {
+		value, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			iter.Release()
+			return utils.BYTE_FALSE, fmt.Errorf("getBlackList, deserialize from raw storage item error: %v", err)
+		}
+		item := new(BlackListItem)
+		if err := item.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			iter.Release()
+			return utils.BYTE_FALSE, fmt.Errorf("getBlackList, deserialize black list item error: %v", err)
+		}
+		blackList.Items = append(blackList.Items, item)
+	}
+	iter.Release()
+	sink := common.NewZeroCopySink(nil)
+	blackList.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// GetPeerIndexesQuery returns the index assigned to every peer in ascending order of index, to be called by preExec
+func GetPeerIndexesQuery(native *native.NativeService) ([]byte, error) {
+	peerIndexes, err := getPeerIndexes(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getPeerIndexes, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	peerIndexes.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// ValidateConfigQuery runs the constraints of UpdateConfig on a configuration against the current
+// peer pool and returns all violations found, to be called by preExec
+func ValidateConfigQuery(native *native.NativeService) ([]byte, error) {
+	params := new(UpdateConfigParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("validateConfig, deserialize configuration error: %v", err)
+	}
+	peerNum, err := getPeerNum(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("validateConfig, %v", err)
+	}
+	result := &ConfigViolations{
+		PeerNum:    uint32(peerNum),
+		Violations: checkConfig(params.Configuration, peerNum),
+	}
+	sink := common.NewZeroCopySink(nil)
+	result.Serialization(sink)
+	return sink.Bytes(), nil
+}
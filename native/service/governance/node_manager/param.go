@@ -83,6 +83,8 @@ func (this *PeerParam) Deserialization(source *common.ZeroCopySource) error {
 type PeerListParam struct {
 	PeerPubkeyList []string
 	Address        common.Address
+	Reason         string // recorded in the black list by blackNode
+	Views          uint32 // views the peers stay blacked by blackNode, 0 for ever
 }
 
 func (this *PeerListParam) Serialization(sink *common.ZeroCopySink) {
@@ -91,6 +93,8 @@ func (this *PeerListParam) Serialization(sink *common.ZeroCopySink) {
 		sink.WriteString(v)
 	}
 	sink.WriteVarBytes(this.Address[:])
+	sink.WriteString(this.Reason)
+	sink.WriteUint32(this.Views)
 }
 
 func (this *PeerListParam) Deserialization(source *common.ZeroCopySource) error {
@@ -115,8 +119,23 @@ func (this *PeerListParam) Deserialization(source *common.ZeroCopySource) error
 	if err != nil {
 		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
 	}
+	// params built before the reason and views were added end here
+	var reason string
+	var views uint32
+	if source.Len() > 0 {
+		reason, eof = source.NextString()
+		if eof {
+			return fmt.Errorf("source.NextString, deserialize reason error")
+		}
+		views, eof = source.NextUint32()
+		if eof {
+			return fmt.Errorf("source.NextUint32, deserialize views error")
+		}
+	}
 	this.PeerPubkeyList = peerPubkeyList
 	this.Address = addr
+	this.Reason = reason
+	this.Views = views
 	return nil
 }
 
@@ -137,3 +156,245 @@ func (this *UpdateConfigParam) Deserialization(source *common.ZeroCopySource) er
 	this.Configuration = configuration
 	return nil
 }
+
+type ApplyLimitParam struct {
+	Address common.Address
+	Limit   uint32
+	Evict   bool
+}
+
+func (this *ApplyLimitParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteUint32(this.Limit)
+	sink.WriteBool(this.Evict)
+}
+
+func (this *ApplyLimitParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	limit, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize limit error")
+	}
+	evict, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("source.NextBool, deserialize evict error")
+	}
+
+	this.Address = addr
+	this.Limit = limit
+	this.Evict = evict
+	return nil
+}
+
+// SlashParam submits the evidence of a misbehavior of the peer, Height is the height it happened at
+type SlashParam struct {
+	PeerPubkey string
+	Kind       uint8
+	Height     uint32
+	Evidence   []byte
+	Address    common.Address
+}
+
+func (this *SlashParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.PeerPubkey)
+	sink.WriteUint8(this.Kind)
+	sink.WriteUint32(this.Height)
+	sink.WriteVarBytes(this.Evidence)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *SlashParam) Deserialization(source *common.ZeroCopySource) error {
+	peerPubkey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("source.NextString, deserialize peerPubkey error")
+	}
+	kind, eof := source.NextUint8()
+	if eof {
+		return fmt.Errorf("source.NextUint8, deserialize kind error")
+	}
+	height, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize height error")
+	}
+	evidence, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize evidence error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+
+	this.PeerPubkey = peerPubkey
+	this.Kind = kind
+	this.Height = height
+	this.Evidence = evidence
+	this.Address = addr
+	return nil
+}
+
+type SlashLimitParam struct {
+	Address common.Address
+	Limit   uint32
+	Views   uint32
+}
+
+func (this *SlashLimitParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteUint32(this.Limit)
+	sink.WriteUint32(this.Views)
+}
+
+func (this *SlashLimitParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	limit, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize limit error")
+	}
+	views, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize views error")
+	}
+
+	this.Address = addr
+	this.Limit = limit
+	this.Views = views
+	return nil
+}
+
+// OperatorCommitteeParam replaces the committee operating the chain, no members to go back to the operator
+// derived from the consensus peers
+type OperatorCommitteeParam struct {
+	Members   []common.Address
+	Threshold uint32
+}
+
+func (this *OperatorCommitteeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Members)))
+	for _, v := range this.Members {
+		sink.WriteVarBytes(v[:])
+	}
+	sink.WriteUint32(this.Threshold)
+}
+
+func (this *OperatorCommitteeParam) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize members length error")
+	}
+	members := make([]common.Address, 0)
+	for i := 0; uint64(i) < n; i++ {
+		address, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("source.NextVarBytes, deserialize member error")
+		}
+		addr, err := common.AddressParseFromBytes(address)
+		if err != nil {
+			return fmt.Errorf("common.AddressParseFromBytes, deserialize member error: %s", err)
+		}
+		members = append(members, addr)
+	}
+	threshold, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize threshold error")
+	}
+
+	this.Members = members
+	this.Threshold = threshold
+	return nil
+}
+
+// OperatorCallParam is the approval of a member of the operator committee on calling Method of Contract with Args
+type OperatorCallParam struct {
+	Contract common.Address
+	Method   string
+	Args     []byte
+	Address  common.Address
+}
+
+func (this *OperatorCallParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Contract[:])
+	sink.WriteString(this.Method)
+	sink.WriteVarBytes(this.Args)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *OperatorCallParam) Deserialization(source *common.ZeroCopySource) error {
+	contract, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize contract error")
+	}
+	contractAddr, err := common.AddressParseFromBytes(contract)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize contract error: %s", err)
+	}
+	method, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("source.NextString, deserialize method error")
+	}
+	args, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize args error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+
+	this.Contract = contractAddr
+	this.Method = method
+	this.Args = args
+	this.Address = addr
+	return nil
+}
+
+type MutationLimitParam struct {
+	Address common.Address
+	Limit   uint32
+}
+
+func (this *MutationLimitParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteUint32(this.Limit)
+}
+
+func (this *MutationLimitParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	limit, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize limit error")
+	}
+
+	this.Address = addr
+	this.Limit = limit
+	return nil
+}
@@ -43,11 +43,17 @@ func (this *Status) Deserialization(source *common.ZeroCopySource) error {
 type BlackListItem struct {
 	PeerPubkey string         //peerPubkey in black list
 	Address    common.Address //the owner of this peer
+	Reason     string         //why the peer is blacked, empty for items blacked before reasons were recorded
+	Height     uint32         //height the peer is blacked at
+	ExpireView uint32         //view from which the peer may register again, 0 if it never expires
 }
 
 func (this *BlackListItem) Serialization(sink *common.ZeroCopySink) {
 	sink.WriteString(this.PeerPubkey)
 	sink.WriteVarBytes(this.Address[:])
+	sink.WriteString(this.Reason)
+	sink.WriteUint32(this.Height)
+	sink.WriteUint32(this.ExpireView)
 }
 
 func (this *BlackListItem) Deserialization(source *common.ZeroCopySource) error {
@@ -63,9 +69,69 @@ func (this *BlackListItem) Deserialization(source *common.ZeroCopySource) error
 	if err != nil {
 		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
 	}
+	// items blacked before the reason and expiry were recorded end here
+	var reason string
+	var height, expireView uint32
+	if source.Len() > 0 {
+		reason, eof = source.NextString()
+		if eof {
+			return fmt.Errorf("source.NextString, deserialize reason error")
+		}
+		height, eof = source.NextUint32()
+		if eof {
+			return fmt.Errorf("source.NextUint32, deserialize height error")
+		}
+		expireView, eof = source.NextUint32()
+		if eof {
+			return fmt.Errorf("source.NextUint32, deserialize expireView error")
+		}
+	}
 
 	this.PeerPubkey = peerPubkey
 	this.Address = addr
+	this.Reason = reason
+	this.Height = height
+	this.ExpireView = expireView
+	return nil
+}
+
+// Expired reports whether the peer may register again at view
+func (this *BlackListItem) Expired(view uint32) bool {
+	return this.ExpireView != 0 && view >= this.ExpireView
+}
+
+type BlackList struct {
+	Items []*BlackListItem
+}
+
+func (this *BlackList) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Items)))
+	for _, v := range this.Items {
+		// each item is framed as the stored one whose tail is optional
+		item := common.NewZeroCopySink(nil)
+		v.Serialization(item)
+		sink.WriteVarBytes(item.Bytes())
+	}
+}
+
+func (this *BlackList) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize length of items error")
+	}
+	items := make([]*BlackListItem, 0)
+	for i := uint64(0); i < n; i++ {
+		raw, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("source.NextVarBytes, deserialize item error")
+		}
+		item := new(BlackListItem)
+		if err := item.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+	this.Items = items
 	return nil
 }
 
@@ -73,17 +139,21 @@ type PeerPoolMap struct {
 	PeerPoolMap map[string]*PeerPoolItem
 }
 
+// SortedPubkeys returns the keys of PeerPoolMap in descending order, which is the order they are stored in.
+// Any loop whose result goes into state should iterate the map by it instead of ranging over the map.
+func (this *PeerPoolMap) SortedPubkeys() []string {
+	pubkeys := make([]string, 0, len(this.PeerPoolMap))
+	for k := range this.PeerPoolMap {
+		pubkeys = append(pubkeys, k)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(pubkeys)))
+	return pubkeys
+}
+
 func (this *PeerPoolMap) Serialization(sink *common.ZeroCopySink) {
 	sink.WriteVarUint(uint64(len(this.PeerPoolMap)))
-	var peerPoolItemList []*PeerPoolItem
-	for _, v := range this.PeerPoolMap {
-		peerPoolItemList = append(peerPoolItemList, v)
-	}
-	sort.SliceStable(peerPoolItemList, func(i, j int) bool {
-		return peerPoolItemList[i].PeerPubkey > peerPoolItemList[j].PeerPubkey
-	})
-	for _, v := range peerPoolItemList {
-		v.Serialization(sink)
+	for _, k := range this.SortedPubkeys() {
+		this.PeerPoolMap[k].Serialization(sink)
 	}
 }
 
@@ -181,6 +251,7 @@ func (this *GovernanceView) Deserialization(source *common.ZeroCopySource) error
 
 type ConsensusSigns struct {
 	SignsMap map[common.Address]bool
+	Height   uint32 //height of the first sign, 0 for the signs collected before it was recorded
 }
 
 func (this *ConsensusSigns) Serialization(sink *common.ZeroCopySink) {
@@ -196,6 +267,7 @@ func (this *ConsensusSigns) Serialization(sink *common.ZeroCopySink) {
 		sink.WriteVarBytes(v[:])
 		sink.WriteBool(this.SignsMap[v])
 	}
+	sink.WriteUint32(this.Height)
 }
 
 func (this *ConsensusSigns) Deserialization(source *common.ZeroCopySource) error {
@@ -219,7 +291,77 @@ func (this *ConsensusSigns) Deserialization(source *common.ZeroCopySource) error
 		}
 		signsMap[addr] = v
 	}
+	// signs collected before the height was recorded end here
+	var height uint32
+	if source.Len() > 0 {
+		height, eof = source.NextUint32()
+		if eof {
+			return fmt.Errorf("source.NextUint32, deserialize height error")
+		}
+	}
 	this.SignsMap = signsMap
+	this.Height = height
+	return nil
+}
+
+// PendingConsensusSign is a proposal which has been signed by some but not enough consensus peers,
+// Key is the key of its ConsensusSigns.
+type PendingConsensusSign struct {
+	Key    common.Uint256
+	Method string
+	Input  []byte
+}
+
+func (this *PendingConsensusSign) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteHash(this.Key)
+	sink.WriteString(this.Method)
+	sink.WriteVarBytes(this.Input)
+}
+
+func (this *PendingConsensusSign) Deserialization(source *common.ZeroCopySource) error {
+	key, eof := source.NextHash()
+	if eof {
+		return fmt.Errorf("source.NextHash, deserialize key error")
+	}
+	method, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("source.NextString, deserialize method error")
+	}
+	input, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize input error")
+	}
+	this.Key = key
+	this.Method = method
+	this.Input = input
+	return nil
+}
+
+type PendingConsensusSigns struct {
+	Items []*PendingConsensusSign
+}
+
+func (this *PendingConsensusSigns) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Items)))
+	for _, v := range this.Items {
+		v.Serialization(sink)
+	}
+}
+
+func (this *PendingConsensusSigns) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize length of items error")
+	}
+	items := make([]*PendingConsensusSign, 0)
+	for i := uint64(0); i < n; i++ {
+		item := new(PendingConsensusSign)
+		if err := item.Deserialization(source); err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+	this.Items = items
 	return nil
 }
 
@@ -261,3 +403,350 @@ func (this *Configuration) Deserialization(source *common.ZeroCopySource) error
 	this.MaxBlockChangeView = maxBlockChangeView
 	return nil
 }
+
+// ApplyLimit bounds the number of pending candidate applications
+type ApplyLimit struct {
+	Limit uint32
+	Evict bool
+}
+
+func (this *ApplyLimit) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Limit)
+	sink.WriteBool(this.Evict)
+}
+
+func (this *ApplyLimit) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Limit, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize limit error")
+	}
+	this.Evict, eof = source.NextBool()
+	if eof {
+		return fmt.Errorf("source.NextBool, deserialize evict error")
+	}
+	return nil
+}
+
+type ApplyItem struct {
+	PeerPubkey string
+	Height     uint32
+}
+
+// ApplyQueue keeps the pending candidate applications in the order of application
+type ApplyQueue struct {
+	Items []*ApplyItem
+}
+
+func (this *ApplyQueue) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Items)))
+	for _, v := range this.Items {
+		sink.WriteString(v.PeerPubkey)
+		sink.WriteUint32(v.Height)
+	}
+}
+
+func (this *ApplyQueue) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize length of items error")
+	}
+	items := make([]*ApplyItem, 0)
+	for i := uint64(0); i < n; i++ {
+		peerPubkey, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("source.NextString, deserialize peerPubkey error")
+		}
+		height, eof := source.NextUint32()
+		if eof {
+			return fmt.Errorf("source.NextUint32, deserialize height error")
+		}
+		items = append(items, &ApplyItem{PeerPubkey: peerPubkey, Height: height})
+	}
+	this.Items = items
+	return nil
+}
+
+// ConfigViolations lists the constraints of UpdateConfig a configuration violates with PeerNum
+// candidate and consensus peers in the pool
+type ConfigViolations struct {
+	PeerNum    uint32
+	Violations []string
+}
+
+func (this *ConfigViolations) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.PeerNum)
+	sink.WriteVarUint(uint64(len(this.Violations)))
+	for _, v := range this.Violations {
+		sink.WriteString(v)
+	}
+}
+
+func (this *ConfigViolations) Deserialization(source *common.ZeroCopySource) error {
+	peerNum, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize peerNum error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize length of violations error")
+	}
+	violations := make([]string, 0)
+	for i := uint64(0); i < n; i++ {
+		v, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("source.NextString, deserialize violation error")
+		}
+		violations = append(violations, v)
+	}
+	this.PeerNum = peerNum
+	this.Violations = violations
+	return nil
+}
+
+// PendingApply is a pending candidate application applied at Height, Age blocks ago
+type PendingApply struct {
+	PeerPubkey string
+	Address    common.Address
+	Height     uint32
+	Age        uint32
+}
+
+type PendingApplies struct {
+	Items []*PendingApply
+}
+
+func (this *PendingApplies) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Items)))
+	for _, v := range this.Items {
+		sink.WriteString(v.PeerPubkey)
+		sink.WriteVarBytes(v.Address[:])
+		sink.WriteUint32(v.Height)
+		sink.WriteUint32(v.Age)
+	}
+}
+
+func (this *PendingApplies) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize length of items error")
+	}
+	items := make([]*PendingApply, 0)
+	for i := uint64(0); i < n; i++ {
+		peerPubkey, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("source.NextString, deserialize peerPubkey error")
+		}
+		address, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("source.NextVarBytes, deserialize address error")
+		}
+		addr, err := common.AddressParseFromBytes(address)
+		if err != nil {
+			return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+		}
+		height, eof := source.NextUint32()
+		if eof {
+			return fmt.Errorf("source.NextUint32, deserialize height error")
+		}
+		age, eof := source.NextUint32()
+		if eof {
+			return fmt.Errorf("source.NextUint32, deserialize age error")
+		}
+		items = append(items, &PendingApply{PeerPubkey: peerPubkey, Address: addr, Height: height, Age: age})
+	}
+	this.Items = items
+	return nil
+}
+
+type PeerIndex struct {
+	Index      uint32
+	PeerPubkey string
+}
+
+// PeerIndexes maps the index of a peer used by consensus to its public key
+type PeerIndexes struct {
+	Peers []*PeerIndex
+}
+
+func (this *PeerIndexes) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Peers)))
+	for _, v := range this.Peers {
+		sink.WriteUint32(v.Index)
+		sink.WriteString(v.PeerPubkey)
+	}
+}
+
+func (this *PeerIndexes) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize length of peers error")
+	}
+	peers := make([]*PeerIndex, 0)
+	for i := uint64(0); i < n; i++ {
+		index, eof := source.NextUint32()
+		if eof {
+			return fmt.Errorf("source.NextUint32, deserialize index error")
+		}
+		peerPubkey, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("source.NextString, deserialize peerPubkey error")
+		}
+		peers = append(peers, &PeerIndex{Index: index, PeerPubkey: peerPubkey})
+	}
+	this.Peers = peers
+	return nil
+}
+
+// DoubleSignEvidence is two blocks of different proposals at the same height signed by one peer, the
+// blocks are in the serialized form of core/types
+type DoubleSignEvidence struct {
+	First  []byte
+	Second []byte
+}
+
+func (this *DoubleSignEvidence) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.First)
+	sink.WriteVarBytes(this.Second)
+}
+
+func (this *DoubleSignEvidence) Deserialization(source *common.ZeroCopySource) error {
+	first, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize first block error")
+	}
+	second, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize second block error")
+	}
+	this.First = first
+	this.Second = second
+	return nil
+}
+
+// Evidence is a misbehavior of a peer recorded by slash
+type Evidence struct {
+	Kind         uint8
+	Height       uint32 //height the misbehavior happened at
+	Data         []byte
+	RecordHeight uint32 //height the evidence is recorded at
+}
+
+// EvidenceList keeps the evidences of a peer in the order of recording
+type EvidenceList struct {
+	Items []*Evidence
+}
+
+func (this *EvidenceList) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Items)))
+	for _, v := range this.Items {
+		sink.WriteUint8(v.Kind)
+		sink.WriteUint32(v.Height)
+		sink.WriteVarBytes(v.Data)
+		sink.WriteUint32(v.RecordHeight)
+	}
+}
+
+func (this *EvidenceList) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize evidence length error")
+	}
+	items := make([]*Evidence, 0, n)
+	for i := uint64(0); i < n; i++ {
+		item := new(Evidence)
+		item.Kind, eof = source.NextUint8()
+		if eof {
+			return fmt.Errorf("source.NextUint8, deserialize kind error")
+		}
+		item.Height, eof = source.NextUint32()
+		if eof {
+			return fmt.Errorf("source.NextUint32, deserialize height error")
+		}
+		item.Data, eof = source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("source.NextVarBytes, deserialize data error")
+		}
+		item.RecordHeight, eof = source.NextUint32()
+		if eof {
+			return fmt.Errorf("source.NextUint32, deserialize record height error")
+		}
+		items = append(items, item)
+	}
+	this.Items = items
+	return nil
+}
+
+// SlashLimit blacks a peer for Views views, 0 for ever, once Limit evidences are recorded against it
+type SlashLimit struct {
+	Limit uint32
+	Views uint32
+}
+
+func (this *SlashLimit) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Limit)
+	sink.WriteUint32(this.Views)
+}
+
+func (this *SlashLimit) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Limit, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize limit error")
+	}
+	this.Views, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize views error")
+	}
+	return nil
+}
+
+// OperatorCommittee operates the chain in place of the operator derived from the consensus peers,
+// a call of the operator is made once Threshold of the Members approved it
+type OperatorCommittee struct {
+	Members   []common.Address
+	Threshold uint32
+}
+
+func (this *OperatorCommittee) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Members)))
+	for _, v := range this.Members {
+		sink.WriteVarBytes(v[:])
+	}
+	sink.WriteUint32(this.Threshold)
+}
+
+func (this *OperatorCommittee) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize members length error")
+	}
+	members := make([]common.Address, 0, n)
+	for i := uint64(0); i < n; i++ {
+		address, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("source.NextVarBytes, deserialize member error")
+		}
+		addr, err := common.AddressParseFromBytes(address)
+		if err != nil {
+			return fmt.Errorf("common.AddressParseFromBytes, deserialize member error: %s", err)
+		}
+		members = append(members, addr)
+	}
+	threshold, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize threshold error")
+	}
+	this.Members = members
+	this.Threshold = threshold
+	return nil
+}
+
+func (this *OperatorCommittee) IsMember(address common.Address) bool {
+	for _, v := range this.Members {
+		if v == address {
+			return true
+		}
+	}
+	return false
+}
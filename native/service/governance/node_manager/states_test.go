@@ -18,9 +18,23 @@
 package node_manager
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/storage"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 func Test_Deserialize_GovernanceView(t *testing.T) {
@@ -38,3 +52,423 @@ func Test_Deserialize_GovernanceView(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, *govView, *govView1)
 }
+
+func Test_Deserialize_PendingConsensusSigns(t *testing.T) {
+	pending := &PendingConsensusSigns{
+		Items: []*PendingConsensusSign{
+			{Key: common.Uint256{1}, Method: UPDATE_CONFIG, Input: []byte{1, 2, 3}},
+			{Key: common.Uint256{2}, Method: BLACK_NODE, Input: []byte{}},
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	pending.Serialization(sink)
+
+	pending1 := new(PendingConsensusSigns)
+	err := pending1.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, pending, pending1)
+}
+
+func Test_PeerPoolMap_Deterministic_Serialization(t *testing.T) {
+	items := make([]*PeerPoolItem, 0)
+	for i := 0; i < 16; i++ {
+		items = append(items, &PeerPoolItem{
+			Index:      uint32(i),
+			PeerPubkey: fmt.Sprintf("%02x", 255-i*7),
+			Address:    common.Address{byte(i)},
+			Status:     ConsensusStatus,
+		})
+	}
+	serialize := func(order []int) []byte {
+		peerPoolMap := &PeerPoolMap{PeerPoolMap: make(map[string]*PeerPoolItem)}
+		for _, i := range order {
+			peerPoolMap.PeerPoolMap[items[i].PeerPubkey] = items[i]
+		}
+		sink := common.NewZeroCopySink(nil)
+		peerPoolMap.Serialization(sink)
+		return sink.Bytes()
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	expected := serialize(order)
+	// nodes build the map in different orders and go randomizes map iteration,
+	// the stored bytes must be the same anyway
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		r.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		assert.Equal(t, expected, serialize(order))
+	}
+
+	peerPoolMap := new(PeerPoolMap)
+	assert.Nil(t, peerPoolMap.Deserialization(common.NewZeroCopySource(expected)))
+	assert.Equal(t, len(items), len(peerPoolMap.PeerPoolMap))
+	sink := common.NewZeroCopySink(nil)
+	peerPoolMap.Serialization(sink)
+	assert.Equal(t, expected, sink.Bytes())
+
+	pubkeys := peerPoolMap.SortedPubkeys()
+	for i := 1; i < len(pubkeys); i++ {
+		assert.True(t, pubkeys[i-1] > pubkeys[i])
+	}
+}
+
+func Test_Deserialize_ApplyQueue(t *testing.T) {
+	queue := &ApplyQueue{
+		Items: []*ApplyItem{
+			{PeerPubkey: "0202", Height: 10},
+			{PeerPubkey: "0303", Height: 20},
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	queue.Serialization(sink)
+
+	queue1 := new(ApplyQueue)
+	err := queue1.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, queue, queue1)
+
+	applies := &PendingApplies{
+		Items: []*PendingApply{
+			{PeerPubkey: "0202", Address: common.Address{1}, Height: 10, Age: 5},
+		},
+	}
+	sink.Reset()
+	applies.Serialization(sink)
+	applies1 := new(PendingApplies)
+	err = applies1.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, applies, applies1)
+
+	limit := &ApplyLimit{Limit: 16, Evict: true}
+	sink.Reset()
+	limit.Serialization(sink)
+	limit1 := new(ApplyLimit)
+	err = limit1.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, limit, limit1)
+}
+
+func Test_Deserialize_BlackList(t *testing.T) {
+	// items blacked before the reason and expiry were recorded
+	addr := common.Address{1}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteString("0202")
+	sink.WriteVarBytes(addr[:])
+	item := new(BlackListItem)
+	err := item.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, &BlackListItem{PeerPubkey: "0202", Address: addr}, item)
+	assert.False(t, item.Expired(100))
+
+	blackList := &BlackList{
+		Items: []*BlackListItem{
+			item,
+			{PeerPubkey: "0303", Address: common.Address{2}, Reason: "double sign", Height: 10, ExpireView: 5},
+		},
+	}
+	assert.False(t, blackList.Items[1].Expired(4))
+	assert.True(t, blackList.Items[1].Expired(5))
+	sink.Reset()
+	blackList.Serialization(sink)
+	blackList1 := new(BlackList)
+	err = blackList1.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, blackList, blackList1)
+}
+
+func Test_Deserialize_EvidenceList(t *testing.T) {
+	evidences := &EvidenceList{
+		Items: []*Evidence{
+			{Kind: EVIDENCE_DOUBLE_SIGN, Height: 10, Data: []byte{1, 2, 3}, RecordHeight: 12},
+			{Kind: EVIDENCE_DOWNTIME, Height: 20, Data: []byte{}, RecordHeight: 25},
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	evidences.Serialization(sink)
+	evidences1 := new(EvidenceList)
+	err := evidences1.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, evidences, evidences1)
+
+	err = evidences1.Deserialization(common.NewZeroCopySource(sink.Bytes()[:sink.Size()-1]))
+	assert.NotNil(t, err)
+}
+
+func Test_OperatorCommittee(t *testing.T) {
+	committee := &OperatorCommittee{
+		Members:   []common.Address{{1}, {2}, {3}},
+		Threshold: 2,
+	}
+	sink := common.NewZeroCopySink(nil)
+	committee.Serialization(sink)
+	committee1 := new(OperatorCommittee)
+	err := committee1.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, committee, committee1)
+	assert.True(t, committee1.IsMember(common.Address{2}))
+	assert.False(t, committee1.IsMember(common.Address{4}))
+
+	assert.Nil(t, checkOperatorCommittee(committee.Members, 2))
+	assert.Nil(t, checkOperatorCommittee(committee.Members, 3))
+	assert.NotNil(t, checkOperatorCommittee(committee.Members, 0))
+	assert.NotNil(t, checkOperatorCommittee(committee.Members, 4))
+	assert.NotNil(t, checkOperatorCommittee([]common.Address{{1}, {1}}, 1))
+	assert.NotNil(t, checkOperatorCommittee([]common.Address{{1}, common.ADDRESS_EMPTY}, 1))
+}
+
+func Test_CheckConfig(t *testing.T) {
+	configuration := &Configuration{
+		BlockMsgDelay:        10000,
+		HashMsgDelay:         10000,
+		PeerHandshakeTimeout: 10,
+		MaxBlockChangeView:   60000,
+	}
+	assert.Empty(t, checkConfig(configuration, MIN_PEER_NUM))
+
+	configuration.HashMsgDelay = 4000
+	configuration.MaxBlockChangeView = 100
+	violations := checkConfig(configuration, MIN_PEER_NUM-1)
+	assert.Equal(t, []string{
+		"HashMsgDelay must >= 5000",
+		"MaxBlockChangeView must >= 10000",
+		"num of peers 3 is less than 4",
+	}, violations)
+
+	result := &ConfigViolations{PeerNum: MIN_PEER_NUM - 1, Violations: violations}
+	sink := common.NewZeroCopySink(nil)
+	result.Serialization(sink)
+	result1 := new(ConfigViolations)
+	err := result1.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, result, result1)
+}
+
+func Test_AssignPeerIndex(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	ns, err := native.NewNativeService(storage.NewCacheDB(overlaydb.NewOverlayDB(store)), &types.Transaction{}, 0, 0,
+		common.Uint256{}, 0, nil, false)
+	assert.Nil(t, err)
+
+	assert.Nil(t, assignPeerIndex(ns, []byte{1}, 2))
+	assert.Nil(t, assignPeerIndex(ns, []byte{2}, 1))
+	// a peer approved again keeps its index, which is never given to another peer
+	assert.Nil(t, assignPeerIndex(ns, []byte{1}, 2))
+	assert.NotNil(t, assignPeerIndex(ns, []byte{3}, 2))
+
+	peerIndexes, err := getPeerIndexes(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, &PeerIndexes{Peers: []*PeerIndex{{Index: 1, PeerPubkey: "02"}, {Index: 2, PeerPubkey: "01"}}}, peerIndexes)
+
+	sink := common.NewZeroCopySink(nil)
+	peerIndexes.Serialization(sink)
+	peerIndexes1 := new(PeerIndexes)
+	assert.Nil(t, peerIndexes1.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, peerIndexes, peerIndexes1)
+}
+
+func Test_GetPendingAppliesQuery(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	ns, err := native.NewNativeService(storage.NewCacheDB(overlaydb.NewOverlayDB(store)), &types.Transaction{}, 0, 10,
+		common.Uint256{}, 0, nil, false)
+	assert.Nil(t, err)
+
+	// an application made before the applications were queued
+	assert.Nil(t, putPeerApply(ns, &RegisterPeerParam{PeerPubkey: "0202", Address: common.Address{2}}))
+	_, err = enqueueApply(ns, "0101")
+	assert.Nil(t, err)
+	assert.Nil(t, putPeerApply(ns, &RegisterPeerParam{PeerPubkey: "0101", Address: common.Address{1}}))
+
+	res, err := GetPendingAppliesQuery(ns)
+	assert.Nil(t, err)
+	applies := new(PendingApplies)
+	assert.Nil(t, applies.Deserialization(common.NewZeroCopySource(res)))
+	assert.Equal(t, &PendingApplies{Items: []*PendingApply{
+		{PeerPubkey: "0202", Address: common.Address{2}},
+		{PeerPubkey: "0101", Address: common.Address{1}, Height: 10},
+	}}, applies)
+}
+
+func Test_CheckConsensusSigns_Witnesses(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	accts := make([]*account.Account, 4)
+	peerPoolMap := &PeerPoolMap{PeerPoolMap: make(map[string]*PeerPoolItem)}
+	for i := range accts {
+		accts[i] = account.NewAccount(fmt.Sprint(i))
+		peerPubkey := hex.EncodeToString(keypair.SerializePublicKey(accts[i].PublicKey))
+		peerPoolMap.PeerPoolMap[peerPubkey] = &PeerPoolItem{Index: uint32(i), PeerPubkey: peerPubkey,
+			Address: accts[i].Address, Status: ConsensusStatus}
+	}
+	newNative := func(signers ...*account.Account) *native.NativeService {
+		tx := &types.Transaction{}
+		for _, v := range signers {
+			tx.SignedAddr = append(tx.SignedAddr, v.Address)
+		}
+		ns, err := native.NewNativeService(db, tx, 0, 10, common.Uint256{}, 0, nil, false)
+		assert.Nil(t, err)
+		return ns
+	}
+	ns := newNative()
+	putGovernanceView(ns, &GovernanceView{View: 1})
+	putPeerPoolMap(ns, peerPoolMap, 1)
+
+	// the approvals of the peers signing one tx are counted together
+	ok, err := CheckConsensusSigns(newNative(accts[0], accts[1]), "test", []byte{1}, accts[0].Address)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	ok, err = CheckConsensusSigns(newNative(accts[2], accts[1]), "test", []byte{1}, accts[2].Address)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	ok, err = CheckConsensusSigns(newNative(accts[0], accts[1], accts[2]), "test", []byte{2}, accts[0].Address)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []common.Address{accts[0].Address, accts[1].Address},
+		newNative(accts[0], accts[1], accts[0]).GetWitnesses())
+}
+
+func Test_PendingConsensusSigns(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	accts := make([]*account.Account, 4)
+	peerPoolMap := &PeerPoolMap{PeerPoolMap: make(map[string]*PeerPoolItem)}
+	for i := range accts {
+		accts[i] = account.NewAccount(fmt.Sprint(i))
+		peerPubkey := hex.EncodeToString(keypair.SerializePublicKey(accts[i].PublicKey))
+		peerPoolMap.PeerPoolMap[peerPubkey] = &PeerPoolItem{Index: uint32(i), PeerPubkey: peerPubkey,
+			Address: accts[i].Address, Status: ConsensusStatus}
+	}
+	newNative := func(signers ...*account.Account) *native.NativeService {
+		tx := &types.Transaction{}
+		for _, v := range signers {
+			tx.SignedAddr = append(tx.SignedAddr, v.Address)
+		}
+		ns, err := native.NewNativeService(db, tx, 0, 10, common.Uint256{}, 0, nil, false)
+		assert.Nil(t, err)
+		return ns
+	}
+	ns := newNative()
+	putGovernanceView(ns, &GovernanceView{View: 1})
+	putPeerPoolMap(ns, peerPoolMap, 1)
+	pendingKeys := func() []common.Uint256 {
+		pending, err := GetPendingConsensusSigns(ns)
+		assert.Nil(t, err)
+		keys := make([]common.Uint256, 0, len(pending.Items))
+		for _, v := range pending.Items {
+			keys = append(keys, v.Key)
+		}
+		return keys
+	}
+
+	// the signs of the accounts out of the consensus peers are not listed
+	outsider := account.NewAccount("outsider")
+	ok, err := CheckConsensusSigns(newNative(outsider), "test", []byte{1}, outsider.Address)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, pendingKeys())
+
+	// listed once per proposal on the first sign of a peer, dropped once approved
+	key := sha256.Sum256(append([]byte("test"), 1))
+	for _, v := range accts[:2] {
+		ok, err = CheckConsensusSigns(newNative(v), "test", []byte{1}, v.Address)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, []common.Uint256{key}, pendingKeys())
+	}
+	ok, err = CheckConsensusSigns(newNative(accts[2]), "test", []byte{1}, accts[2].Address)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, pendingKeys())
+
+	// the view change drops the proposals left
+	ok, err = CheckConsensusSigns(newNative(accts[0]), "test", []byte{2}, accts[0].Address)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Len(t, pendingKeys(), 1)
+	dropPendingConsensusSigns(ns)
+	assert.Empty(t, pendingKeys())
+}
+
+func Test_Deserialize_ConsensusSigns(t *testing.T) {
+	signs := &ConsensusSigns{
+		SignsMap: map[common.Address]bool{{1}: true, {2}: true},
+		Height:   100,
+	}
+	sink := common.NewZeroCopySink(nil)
+	signs.Serialization(sink)
+	signs1 := new(ConsensusSigns)
+	assert.Nil(t, signs1.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, signs, signs1)
+
+	// signs stored before the height was recorded
+	legacy := sink.Bytes()[:len(sink.Bytes())-4]
+	signs2 := new(ConsensusSigns)
+	assert.Nil(t, signs2.Deserialization(common.NewZeroCopySource(legacy)))
+	assert.Equal(t, signs.SignsMap, signs2.SignsMap)
+	assert.Equal(t, uint32(0), signs2.Height)
+}
+
+func Test_RegisterViewChangeHook(t *testing.T) {
+	hooks := viewChangeHooks
+	defer func() { viewChangeHooks = hooks }()
+	viewChangeHooks = nil
+
+	hook := func(native *native.NativeService, view uint32) {}
+	RegisterViewChangeHook("summary", VIEW_CHANGE_SUMMARY, hook)
+	RegisterViewChangeHook("push", VIEW_CHANGE_PUSH, hook)
+	RegisterViewChangeHook("b", VIEW_CHANGE_HOUSEKEEPING, hook)
+	RegisterViewChangeHook("a", VIEW_CHANGE_HOUSEKEEPING, hook)
+	assert.Equal(t, []string{"a", "b", "push", "summary"}, ViewChangeHooks())
+	assert.Panics(t, func() { RegisterViewChangeHook("push", VIEW_CHANGE_PUSH, hook) })
+}
+
+func Test_VerifyDoubleSign(t *testing.T) {
+	acct := account.NewAccount("")
+	other := account.NewAccount("")
+	pubkey := keypair.SerializePublicKey(acct.PublicKey)
+	newTx := func(nonce uint32) *types.Transaction {
+		mutable := &types.Transaction{TxType: types.Invoke, Nonce: nonce, Payload: &payload.InvokeCode{}}
+		sink := common.NewZeroCopySink(nil)
+		assert.Nil(t, mutable.Serialization(sink))
+		tx, err := types.TransactionFromRawBytes(sink.Bytes())
+		assert.Nil(t, err)
+		return tx
+	}
+	sysTx, txA, txB := newTx(1), newTx(2), newTx(3)
+	signedBlock := func(height, timestamp uint32, signer *account.Account, txs ...*types.Transaction) []byte {
+		hashes := make([]common.Uint256, 0, len(txs))
+		for _, tx := range txs {
+			hashes = append(hashes, tx.Hash())
+		}
+		header := &types.Header{Height: height, Timestamp: timestamp, TransactionsRoot: common.ComputeMerkleRoot(hashes)}
+		hash := header.Hash()
+		sig, err := signature.Sign(signer, hash[:])
+		assert.Nil(t, err)
+		header.SigData = [][]byte{sig}
+		sink := common.NewZeroCopySink(nil)
+		assert.Nil(t, (&types.Block{Header: header, Transactions: txs}).Serialization(sink))
+		return sink.Bytes()
+	}
+	evidence := func(first, second []byte) []byte {
+		sink := common.NewZeroCopySink(nil)
+		(&DoubleSignEvidence{First: first, Second: second}).Serialization(sink)
+		return sink.Bytes()
+	}
+
+	assert.Nil(t, verifyDoubleSign(pubkey, 10, evidence(signedBlock(10, 1, acct, sysTx, txA), signedBlock(10, 2, acct, sysTx, txB))))
+	assert.NotNil(t, verifyDoubleSign(pubkey, 10, evidence(signedBlock(10, 1, acct, txA), signedBlock(10, 1, acct, txA))),
+		"same block")
+	assert.NotNil(t, verifyDoubleSign(pubkey, 10, evidence(signedBlock(10, 1, acct, txA), signedBlock(11, 2, acct, txB))),
+		"different heights")
+	assert.NotNil(t, verifyDoubleSign(pubkey, 10, evidence(signedBlock(10, 1, acct, txA), signedBlock(10, 2, other, txB))),
+		"signed by another peer")
+	// the block and the empty block of a proposal are both signed by the proposer
+	assert.NotNil(t, verifyDoubleSign(pubkey, 10, evidence(signedBlock(10, 1, acct, sysTx, txA), signedBlock(10, 1, acct, sysTx))),
+		"block and empty block of a proposal")
+	assert.NotNil(t, verifyDoubleSign(pubkey, 10, evidence(signedBlock(10, 1, acct), signedBlock(10, 1, acct, txA))),
+		"empty block without system txs")
+	assert.NotNil(t, verifyDoubleSign(pubkey, 10, []byte{1, 2}))
+}
@@ -22,11 +22,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sort"
+
 	"github.com/polynetwork/poly/native/event"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/signature"
 	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native"
@@ -69,6 +72,234 @@ func putPeerApply(native *native.NativeService, peer *RegisterPeerParam) error {
 	return nil
 }
 
+func deletePeerApply(native *native.NativeService, peerPubkey string) error {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return fmt.Errorf("deletePeerApply, peerPubkey format error: %v", err)
+	}
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PEER_APPLY), peerPubkeyPrefix))
+	return nil
+}
+
+// getBlackListItem returns nil if the peer is not blacked
+func getBlackListItem(native *native.NativeService, peerPubkeyPrefix []byte) (*BlackListItem, error) {
+	itemBytes, err := native.GetCacheDB().Get(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(BLACK_LIST), peerPubkeyPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("getBlackListItem, get black list item error: %v", err)
+	}
+	if itemBytes == nil {
+		return nil, nil
+	}
+	itemStore, err := cstates.GetValueFromRawStorageItem(itemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("getBlackListItem, deserialize from raw storage item err:%v", err)
+	}
+	item := new(BlackListItem)
+	if err := item.Deserialization(common.NewZeroCopySource(itemStore)); err != nil {
+		return nil, fmt.Errorf("getBlackListItem, deserialize black list item error: %v", err)
+	}
+	return item, nil
+}
+
+// blackPeers puts the peers into the black list for views views, forever if 0, and removes them from
+// the peer pool of view, the consensus is committed again if any of them is a consensus peer
+func blackPeers(native *native.NativeService, view uint32, peerPoolMap *PeerPoolMap, peerPubkeys []string,
+	reason string, views uint32) error {
+	contract := utils.NodeManagerContractAddress
+	commit := false
+	for _, peerPubkey := range peerPubkeys {
+		peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+		if err != nil {
+			return fmt.Errorf("peerPubkey format error: %v", err)
+		}
+		peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
+		if !ok {
+			return fmt.Errorf("peerPubkey is not in peerPoolMap")
+		}
+
+		blackListItem := &BlackListItem{
+			PeerPubkey: peerPoolItem.PeerPubkey,
+			Address:    peerPoolItem.Address,
+			Reason:     reason,
+			Height:     native.GetHeight(),
+		}
+		if views != 0 {
+			blackListItem.ExpireView = view + views
+		}
+		sink := common.NewZeroCopySink(nil)
+		blackListItem.Serialization(sink)
+		//put peer into black list
+		native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), cstates.GenRawStorageItem(sink.Bytes()))
+
+		//change peerPool status
+		if peerPoolItem.Status == ConsensusStatus {
+			commit = true
+		}
+		peerPoolItem.Status = BlackStatus
+		peerPoolMap.PeerPoolMap[peerPubkey] = peerPoolItem
+	}
+	putPeerPoolMap(native, peerPoolMap, view)
+
+	//commitDpos
+	if commit {
+		if err := executeCommitDpos(native); err != nil {
+			return fmt.Errorf("executeCommitDpos error: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetApplyLimit returns nil if the pending applications are not bounded
+func GetApplyLimit(native *native.NativeService) (*ApplyLimit, error) {
+	limitBytes, err := native.GetCacheDB().Get(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(APPLY_LIMIT)))
+	if err != nil {
+		return nil, fmt.Errorf("GetApplyLimit, get limit error: %v", err)
+	}
+	if limitBytes == nil {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(limitBytes)
+	if err != nil {
+		return nil, fmt.Errorf("GetApplyLimit, deserialize from raw storage item err:%v", err)
+	}
+	limit := new(ApplyLimit)
+	if err := limit.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("GetApplyLimit, deserialize limit error: %v", err)
+	}
+	return limit, nil
+}
+
+func putApplyLimit(native *native.NativeService, limit *ApplyLimit) {
+	sink := common.NewZeroCopySink(nil)
+	limit.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(APPLY_LIMIT)), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// getApplyQueue returns the pending applications in the order of application, the ones made
+// before the queue is introduced are not tracked
+func getEvidenceList(native *native.NativeService, peerPubkeyPrefix []byte) (*EvidenceList, error) {
+	evidences := new(EvidenceList)
+	evidenceBytes, err := native.GetCacheDB().Get(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(EVIDENCE), peerPubkeyPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("getEvidenceList, get evidences error: %v", err)
+	}
+	if evidenceBytes == nil {
+		return evidences, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(evidenceBytes)
+	if err != nil {
+		return nil, fmt.Errorf("getEvidenceList, deserialize from raw storage item err:%v", err)
+	}
+	if err := evidences.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("getEvidenceList, deserialize evidences error: %v", err)
+	}
+	return evidences, nil
+}
+
+func putEvidenceList(native *native.NativeService, peerPubkeyPrefix []byte, evidences *EvidenceList) {
+	sink := common.NewZeroCopySink(nil)
+	evidences.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(EVIDENCE), peerPubkeyPrefix), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// GetSlashLimit returns nil if peers are not blacked by slash
+func GetSlashLimit(native *native.NativeService) (*SlashLimit, error) {
+	limitBytes, err := native.GetCacheDB().Get(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(SLASH_LIMIT)))
+	if err != nil {
+		return nil, fmt.Errorf("GetSlashLimit, get limit error: %v", err)
+	}
+	if limitBytes == nil {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(limitBytes)
+	if err != nil {
+		return nil, fmt.Errorf("GetSlashLimit, deserialize from raw storage item err:%v", err)
+	}
+	limit := new(SlashLimit)
+	if err := limit.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("GetSlashLimit, deserialize limit error: %v", err)
+	}
+	return limit, nil
+}
+
+func putSlashLimit(native *native.NativeService, limit *SlashLimit) {
+	sink := common.NewZeroCopySink(nil)
+	limit.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(SLASH_LIMIT)), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func getApplyQueue(native *native.NativeService) (*ApplyQueue, error) {
+	queue := new(ApplyQueue)
+	queueBytes, err := native.GetCacheDB().Get(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(APPLY_QUEUE)))
+	if err != nil {
+		return nil, fmt.Errorf("getApplyQueue, get queue error: %v", err)
+	}
+	if queueBytes == nil {
+		return queue, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(queueBytes)
+	if err != nil {
+		return nil, fmt.Errorf("getApplyQueue, deserialize from raw storage item err:%v", err)
+	}
+	if err := queue.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("getApplyQueue, deserialize queue error: %v", err)
+	}
+	return queue, nil
+}
+
+func putApplyQueue(native *native.NativeService, queue *ApplyQueue) {
+	key := utils.ConcatKey(utils.NodeManagerContractAddress, []byte(APPLY_QUEUE))
+	if len(queue.Items) == 0 {
+		native.GetCacheDB().Delete(key)
+		return
+	}
+	sink := common.NewZeroCopySink(nil)
+	queue.Serialization(sink)
+	native.GetCacheDB().Put(key, cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// enqueueApply appends a new application to the queue under the apply limit, returns the
+// evicted applications when the queue is full and eviction is enabled
+func enqueueApply(native *native.NativeService, peerPubkey string) ([]string, error) {
+	limit, err := GetApplyLimit(native)
+	if err != nil {
+		return nil, err
+	}
+	queue, err := getApplyQueue(native)
+	if err != nil {
+		return nil, err
+	}
+	var evicted []string
+	if limit != nil && uint32(len(queue.Items)) >= limit.Limit {
+		if !limit.Evict {
+			return nil, fmt.Errorf("enqueueApply, pending applications reach the limit %d", limit.Limit)
+		}
+		n := len(queue.Items) - int(limit.Limit) + 1
+		for _, v := range queue.Items[:n] {
+			evicted = append(evicted, v.PeerPubkey)
+		}
+		queue.Items = queue.Items[n:]
+	}
+	queue.Items = append(queue.Items, &ApplyItem{PeerPubkey: peerPubkey, Height: native.GetHeight()})
+	putApplyQueue(native, queue)
+	return evicted, nil
+}
+
+func dequeueApply(native *native.NativeService, peerPubkey string) error {
+	queue, err := getApplyQueue(native)
+	if err != nil {
+		return err
+	}
+	for i, v := range queue.Items {
+		if v.PeerPubkey == peerPubkey {
+			queue.Items = append(queue.Items[:i], queue.Items[i+1:]...)
+			putApplyQueue(native, queue)
+			break
+		}
+	}
+	return nil
+}
+
 func GetPeerPoolMap(native *native.NativeService, view uint32) (*PeerPoolMap, error) {
 	contract := utils.NodeManagerContractAddress
 	viewBytes := utils.GetUint32Bytes(view)
@@ -149,6 +380,47 @@ func CheckVBFTConfig(configuration *config.VBFTConfig) error {
 	return nil
 }
 
+// checkConfig returns the constraints of UpdateConfig that configuration violates with peerNum
+// candidate and consensus peers in the pool, all of them rather than the first one
+func checkConfig(configuration *Configuration, peerNum int) []string {
+	violations := make([]string, 0)
+	if configuration.BlockMsgDelay < 5000 {
+		violations = append(violations, "BlockMsgDelay must >= 5000")
+	}
+	if configuration.HashMsgDelay < 5000 {
+		violations = append(violations, "HashMsgDelay must >= 5000")
+	}
+	if configuration.PeerHandshakeTimeout < 10 {
+		violations = append(violations, "PeerHandshakeTimeout must >= 10")
+	}
+	if configuration.MaxBlockChangeView < 10000 {
+		violations = append(violations, "MaxBlockChangeView must >= 10000")
+	}
+	if peerNum < MIN_PEER_NUM {
+		violations = append(violations, fmt.Sprintf("num of peers %d is less than %d", peerNum, MIN_PEER_NUM))
+	}
+	return violations
+}
+
+// getPeerNum returns the number of candidate and consensus peers in the peer pool of current view
+func getPeerNum(native *native.NativeService) (int, error) {
+	view, err := GetView(native)
+	if err != nil {
+		return 0, fmt.Errorf("getPeerNum, get view error: %v", err)
+	}
+	peerPoolMap, err := GetPeerPoolMap(native, view)
+	if err != nil {
+		return 0, fmt.Errorf("getPeerNum, get peerPoolMap error: %v", err)
+	}
+	num := 0
+	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+		if peerPoolItem.Status == CandidateStatus || peerPoolItem.Status == ConsensusStatus {
+			num = num + 1
+		}
+	}
+	return num, nil
+}
+
 func GetConfig(native *native.NativeService) (*Configuration, error) {
 	contract := utils.NodeManagerContractAddress
 	config := new(Configuration)
@@ -176,6 +448,78 @@ func putConfig(native *native.NativeService, config *Configuration) {
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(VBFT_CONFIG)), cstates.GenRawStorageItem(sink.Bytes()))
 }
 
+// getPreConfig returns the config updated in the current view, nil if there is none
+func getPreConfig(native *native.NativeService) (*Configuration, error) {
+	configBytes, err := native.GetCacheDB().Get(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PRE_CONFIG)))
+	if err != nil {
+		return nil, fmt.Errorf("native.CacheDB.Get, get preConfig error: %v", err)
+	}
+	if configBytes == nil {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(configBytes)
+	if err != nil {
+		return nil, fmt.Errorf("getPreConfig, deserialize from raw storage item err:%v", err)
+	}
+	config := new(Configuration)
+	if err := config.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("getPreConfig, deserialize config error: %v", err)
+	}
+	return config, nil
+}
+
+func putPreConfig(native *native.NativeService, config *Configuration) {
+	sink := common.NewZeroCopySink(nil)
+	config.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PRE_CONFIG)), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// assignPeerIndex records index as the one of the peer in both directions, an index assigned to a peer is
+// never assigned to another one.
+func assignPeerIndex(native *native.NativeService, peerPubkey []byte, index uint32) error {
+	contract := utils.NodeManagerContractAddress
+	indexKey := utils.ConcatKey(contract, []byte(INDEX_PEER), utils.GetUint32Bytes(index))
+	store, err := native.GetCacheDB().Get(indexKey)
+	if err != nil {
+		return fmt.Errorf("assignPeerIndex, get peer of index error: %v", err)
+	}
+	if store != nil {
+		assigned, err := cstates.GetValueFromRawStorageItem(store)
+		if err != nil {
+			return fmt.Errorf("assignPeerIndex, deserialize from raw storage item err:%v", err)
+		}
+		if !bytes.Equal(assigned, peerPubkey) {
+			return fmt.Errorf("assignPeerIndex, index %d is assigned to peer %x", index, assigned)
+		}
+	}
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(PEER_INDEX), peerPubkey), cstates.GenRawStorageItem(utils.GetUint32Bytes(index)))
+	native.GetCacheDB().Put(indexKey, cstates.GenRawStorageItem(peerPubkey))
+	return nil
+}
+
+// getPeerIndexes lists the index of every peer by PEER_INDEX, which covers the peers indexed before the
+// indexes were recorded in the other direction
+func getPeerIndexes(native *native.NativeService) (*PeerIndexes, error) {
+	prefix := utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PEER_INDEX))
+	peerIndexes := &PeerIndexes{Peers: make([]*PeerIndex, 0)}
+	iter := native.GetCacheDB().NewIterator(prefix)
+	defer iter.Release()
+	for has := iter.First(); has; has = iter.Next() {
+		value, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("getPeerIndexes, deserialize from raw storage item err:%v", err)
+		}
+		peerIndexes.Peers = append(peerIndexes.Peers, &PeerIndex{
+			Index:      utils.GetBytesUint32(value),
+			PeerPubkey: hex.EncodeToString(iter.Key()[len(prefix):]),
+		})
+	}
+	sort.SliceStable(peerIndexes.Peers, func(i, j int) bool {
+		return peerIndexes.Peers[i].Index < peerIndexes.Peers[j].Index
+	})
+	return peerIndexes, nil
+}
+
 func getCandidateIndex(native *native.NativeService) (uint32, error) {
 	contract := utils.NodeManagerContractAddress
 	candidateIndexBytes, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(CANDIDITE_INDEX)))
@@ -227,6 +571,52 @@ func putGovernanceView(native *native.NativeService, governanceView *GovernanceV
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(GOVERNANCE_VIEW)), cstates.GenRawStorageItem(sink.Bytes()))
 }
 
+// GetMutationLimit returns the max governance mutations in a view, 0 if they are not bounded
+func GetMutationLimit(native *native.NativeService) (uint32, error) {
+	limitBytes, err := native.GetCacheDB().Get(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(MUTATION_LIMIT)))
+	if err != nil {
+		return 0, fmt.Errorf("GetMutationLimit, get limit error: %v", err)
+	}
+	if limitBytes == nil {
+		return 0, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(limitBytes)
+	if err != nil {
+		return 0, fmt.Errorf("GetMutationLimit, deserialize from raw storage item err:%v", err)
+	}
+	return utils.GetBytesUint32(value), nil
+}
+
+// countMutation counts a governance mutation taking effect in view, it fails once the mutations of
+// the view reach the limit so that the running peer set of consensus can't drift far from the stored one
+func countMutation(native *native.NativeService, view uint32) error {
+	limit, err := GetMutationLimit(native)
+	if err != nil {
+		return err
+	}
+	if limit == 0 {
+		return nil
+	}
+	key := utils.ConcatKey(utils.NodeManagerContractAddress, []byte(MUTATION_COUNT), utils.GetUint32Bytes(view))
+	countBytes, err := native.GetCacheDB().Get(key)
+	if err != nil {
+		return fmt.Errorf("countMutation, get count error: %v", err)
+	}
+	count := uint32(0)
+	if countBytes != nil {
+		value, err := cstates.GetValueFromRawStorageItem(countBytes)
+		if err != nil {
+			return fmt.Errorf("countMutation, deserialize from raw storage item err:%v", err)
+		}
+		count = utils.GetBytesUint32(value)
+	}
+	if count >= limit {
+		return fmt.Errorf("countMutation, governance mutations of view %d reach the limit %d", view, limit)
+	}
+	native.GetCacheDB().Put(key, cstates.GenRawStorageItem(utils.GetUint32Bytes(count+1)))
+	return nil
+}
+
 func GetView(native *native.NativeService) (uint32, error) {
 	governanceView, err := GetGovernanceView(native)
 	if err != nil {
@@ -268,13 +658,101 @@ func deleteConsensusSigns(native *native.NativeService, key common.Uint256) {
 	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(CONSENSUS_SIGNS), key.ToArray()))
 }
 
+func pendingConsensusSignKey(key common.Uint256) []byte {
+	return utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PENDING_CONSENSUS_SIGNS), key.ToArray())
+}
+
+// GetPendingConsensusSigns returns the proposals waiting for the signs of the consensus peers in the order of keys
+func GetPendingConsensusSigns(native *native.NativeService) (*PendingConsensusSigns, error) {
+	pending := &PendingConsensusSigns{
+		Items: make([]*PendingConsensusSign, 0),
+	}
+	prefix := utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PENDING_CONSENSUS_SIGNS))
+	iter := native.GetCacheDB().NewIterator(prefix)
+	defer iter.Release()
+	for has := iter.First(); has; has = iter.Next() {
+		// skip the list all the proposals were kept in before
+		if len(iter.Key()) != len(prefix)+common.UINT256_SIZE {
+			continue
+		}
+		value, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("GetPendingConsensusSigns, deserialize from raw storage item err:%v", err)
+		}
+		item := new(PendingConsensusSign)
+		if err := item.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, fmt.Errorf("GetPendingConsensusSigns, deserialize pending consensus sign error: %v", err)
+		}
+		pending.Items = append(pending.Items, item)
+	}
+	return pending, nil
+}
+
+// updatePendingConsensusSigns lists the proposal once a consensus peer signs it and drops it once it's done,
+// so that the signers can find what is waiting for them. Every proposal is listed under its own key, a sign
+// touches its own entry only.
+func updatePendingConsensusSigns(native *native.NativeService, key common.Uint256, method string, input []byte,
+	peerSigned, done bool) error {
+	k := pendingConsensusSignKey(key)
+	if done {
+		native.GetCacheDB().Delete(k)
+		return nil
+	}
+	if !peerSigned {
+		return nil
+	}
+	listed, err := native.GetCacheDB().Get(k)
+	if err != nil {
+		return fmt.Errorf("updatePendingConsensusSigns, get pending consensus sign error: %v", err)
+	}
+	if listed != nil {
+		return nil
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&PendingConsensusSign{Key: key, Method: method, Input: input}).Serialization(sink)
+	native.GetCacheDB().Put(k, cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
+
+// dropPendingConsensusSigns drops all the proposals listed, the ones signed again are listed again
+func dropPendingConsensusSigns(native *native.NativeService) {
+	deletePrefix(native, utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PENDING_CONSENSUS_SIGNS)))
+}
+
 func CheckConsensusSigns(native *native.NativeService, method string, input []byte, address common.Address) (bool, error) {
+	return checkConsensusSigns(native, method, input, address, 0)
+}
+
+// checkConsensusSigns drops the signs collected for the proposal once expiry blocks passed since its first sign,
+// the sign being checked starts the proposal again then. The signs never expire if expiry is 0.
+func checkConsensusSigns(native *native.NativeService, method string, input []byte, address common.Address,
+	expiry uint32) (bool, error) {
+	// the admin of a bridge instance approves the governance methods of the instance alone, InvokeInstance
+	// fails the methods writing the state shared with the default instance
+	if admin, ok := native.InstanceAdmin(); ok {
+		if address != admin || !native.CheckWitness(admin) {
+			return false, fmt.Errorf("CheckConsensusSigns, %s is not the admin of the instance", address.ToBase58())
+		}
+		return true, nil
+	}
 	message := append([]byte(method), input...)
 	key := sha256.Sum256(message)
 	consensusSigns, err := getConsensusSigns(native, key)
 	if err != nil {
 		return false, fmt.Errorf("CheckConsensusSigns, GetConsensusSigns error: %v", err)
 	}
+	if expiry != 0 && consensusSigns.Height != 0 && native.GetHeight() >= consensusSigns.Height+expiry {
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.NodeManagerContractAddress,
+				States:          []interface{}{"consensusSignsExpired", method, len(consensusSigns.SignsMap), consensusSigns.Height},
+			})
+		consensusSigns = &ConsensusSigns{SignsMap: make(map[common.Address]bool)}
+		native.GetCacheDB().Delete(pendingConsensusSignKey(key))
+	}
+	if len(consensusSigns.SignsMap) == 0 {
+		consensusSigns.Height = native.GetHeight()
+	}
 	consensusSigns.SignsMap[address] = true
 	native.AddNotify(
 		&event.NotifyEventInfo{
@@ -292,10 +770,15 @@ func CheckConsensusSigns(native *native.NativeService, method string, input []by
 	if err != nil {
 		return false, fmt.Errorf("CheckConsensusSigns, GetPeerPoolMap error: %v", err)
 	}
+	// every consensus peer signing the tx approves, so that the approvals can be collected in one tx
+	witnesses := make(map[common.Address]bool)
+	for _, v := range native.GetWitnesses() {
+		witnesses[v] = true
+	}
 	num := 0
 	sum := 0
-	for key, v := range peerPoolMap.PeerPoolMap {
-		if v.Status == ConsensusStatus {
+	for _, key := range peerPoolMap.SortedPubkeys() {
+		if v := peerPoolMap.PeerPoolMap[key]; v.Status == ConsensusStatus {
 			k, err := hex.DecodeString(key)
 			if err != nil {
 				return false, fmt.Errorf("CheckConsensusSigns, hex.DecodeString public key error: %v", err)
@@ -304,14 +787,22 @@ func CheckConsensusSigns(native *native.NativeService, method string, input []by
 			if err != nil {
 				return false, fmt.Errorf("CheckConsensusSigns, keypair.DeserializePublicKey error: %v", err)
 			}
-			_, ok := consensusSigns.SignsMap[types.AddressFromPubKey(publicKey)]
+			peerAddress := types.AddressFromPubKey(publicKey)
+			if witnesses[peerAddress] {
+				consensusSigns.SignsMap[peerAddress] = true
+			}
+			_, ok := consensusSigns.SignsMap[peerAddress]
 			if ok {
 				num = num + 1
 			}
 			sum = sum + 1
 		}
 	}
-	if num >= (2*sum+2)/3 {
+	done := num >= (2*sum+2)/3
+	if err := updatePendingConsensusSigns(native, key, method, input, num > 0, done); err != nil {
+		return false, fmt.Errorf("CheckConsensusSigns, %v", err)
+	}
+	if done {
 		deleteConsensusSigns(native, key)
 		return true, nil
 	} else {
@@ -320,8 +811,119 @@ func CheckConsensusSigns(native *native.NativeService, method string, input []by
 	}
 }
 
+// operatorMethods are the methods guarded by the operator witness by contract, the only ones the operator
+// committee calls through approveOperatorCall. The contracts depending on node_manager register theirs.
+var operatorMethods = map[common.Address]map[string]bool{
+	utils.NodeManagerContractAddress: {
+		UPDATE_CONFIG:          true,
+		COMMIT_DPOS:            true,
+		RESET_CONFIG:           true,
+		SET_OPERATOR_COMMITTEE: true,
+	},
+}
+
+// RegisterOperatorMethod registers method of contract as guarded by the operator witness from the init of
+// the contract, so that the operator committee can call it
+func RegisterOperatorMethod(contract common.Address, method string) {
+	if operatorMethods[contract] == nil {
+		operatorMethods[contract] = make(map[string]bool)
+	}
+	operatorMethods[contract][method] = true
+}
+
+func isOperatorMethod(contract common.Address, method string) bool {
+	return operatorMethods[contract][method]
+}
+
+// GetOperatorCommittee returns nil if the chain is operated by the consensus peers
+func GetOperatorCommittee(native *native.NativeService) (*OperatorCommittee, error) {
+	committeeBytes, err := native.GetCacheDB().Get(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(OPERATOR_COMMITTEE)))
+	if err != nil {
+		return nil, fmt.Errorf("GetOperatorCommittee, get committee error: %v", err)
+	}
+	if committeeBytes == nil {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(committeeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("GetOperatorCommittee, deserialize from raw storage item err:%v", err)
+	}
+	committee := new(OperatorCommittee)
+	if err := committee.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("GetOperatorCommittee, deserialize committee error: %v", err)
+	}
+	return committee, nil
+}
+
+func putOperatorCommittee(native *native.NativeService, committee *OperatorCommittee) {
+	sink := common.NewZeroCopySink(nil)
+	committee.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(OPERATOR_COMMITTEE)), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// checkOperatorCommittee requires threshold out of distinct members
+func checkOperatorCommittee(members []common.Address, threshold uint32) error {
+	if threshold == 0 || int(threshold) > len(members) {
+		return fmt.Errorf("threshold %d is out of range of %d members", threshold, len(members))
+	}
+	seen := make(map[common.Address]bool, len(members))
+	for _, v := range members {
+		if v == common.ADDRESS_EMPTY {
+			return fmt.Errorf("empty member address")
+		}
+		if seen[v] {
+			return fmt.Errorf("duplicate member %s", v.ToBase58())
+		}
+		seen[v] = true
+	}
+	return nil
+}
+
+func getOperatorSigns(native *native.NativeService, key common.Uint256) (*ConsensusSigns, error) {
+	signs := &ConsensusSigns{
+		SignsMap: make(map[common.Address]bool),
+	}
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(OPERATOR_SIGNS), key.ToArray()))
+	if err != nil {
+		return nil, fmt.Errorf("getOperatorSigns, get signs error: %v", err)
+	}
+	if store == nil {
+		return signs, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("getOperatorSigns, deserialize from raw storage item err:%v", err)
+	}
+	if err := signs.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("getOperatorSigns, deserialize signs error: %v", err)
+	}
+	return signs, nil
+}
+
+func putOperatorSigns(native *native.NativeService, key common.Uint256, signs *ConsensusSigns) {
+	sink := common.NewZeroCopySink(nil)
+	signs.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(OPERATOR_SIGNS), key.ToArray()), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func deleteOperatorSigns(native *native.NativeService, key common.Uint256) {
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(OPERATOR_SIGNS), key.ToArray()))
+}
+
 // Get current epoch operator derived from current epoch consensus book keepers' public keys
 func GetCurConOperator(native *native.NativeService) (common.Address, error) {
+	// the admin of a bridge instance operates the instance
+	if admin, ok := native.InstanceAdmin(); ok {
+		return admin, nil
+	}
+	// the operator committee calls through node manager once enough members approved the call
+	committee, err := GetOperatorCommittee(native)
+	if err != nil {
+		return common.ADDRESS_EMPTY, fmt.Errorf("GetCurConOperator, %v", err)
+	}
+	if committee != nil {
+		return utils.NodeManagerContractAddress, nil
+	}
 	view, err := GetView(native)
 	if err != nil {
 		return common.ADDRESS_EMPTY, fmt.Errorf("GetCurConOperator, GetView error: %v", err)
@@ -335,8 +937,8 @@ func GetCurConOperator(native *native.NativeService) (common.Address, error) {
 		return common.ADDRESS_EMPTY, fmt.Errorf("GetCurConOperator, GetPeerPoolMap empty peerPoolMap")
 	}
 	publicKeys := make([]keypair.PublicKey, 0)
-	for key, v := range peerPoolMap.PeerPoolMap {
-		if v.Status == ConsensusStatus {
+	for _, key := range peerPoolMap.SortedPubkeys() {
+		if v := peerPoolMap.PeerPoolMap[key]; v.Status == ConsensusStatus {
 			k, err := hex.DecodeString(key)
 			if err != nil {
 				return common.ADDRESS_EMPTY, fmt.Errorf("GetCurConOperator, hex.DecodeString public key error: %v", err)
@@ -354,3 +956,77 @@ func GetCurConOperator(native *native.NativeService) (common.Address, error) {
 	}
 	return operator, nil
 }
+
+// deletePrefix removes every item of the contract storage under prefix
+func deletePrefix(native *native.NativeService, prefix []byte) {
+	var keys [][]byte
+	iter := native.GetCacheDB().NewIterator(prefix)
+	for has := iter.First(); has; has = iter.Next() {
+		keys = append(keys, append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+	for _, key := range keys {
+		native.GetCacheDB().Delete(key)
+	}
+}
+
+// verifyDoubleSign checks the evidence of a double sign by the peer at height. A vbft proposal carries a block
+// and its empty variant, which has only the system txs of the block, and the proposer signs both, endorsers
+// may sign the empty one after a timeout as well. So the evidence holds two blocks each with a tx the other
+// doesn't have, which can't be the two variants of one proposal.
+func verifyDoubleSign(peerPubkey []byte, height uint32, evidence []byte) error {
+	pub, err := keypair.DeserializePublicKey(peerPubkey)
+	if err != nil {
+		return fmt.Errorf("verifyDoubleSign, deserialize peer pubkey error: %v", err)
+	}
+	ev := new(DoubleSignEvidence)
+	if err := ev.Deserialization(common.NewZeroCopySource(evidence)); err != nil {
+		return fmt.Errorf("verifyDoubleSign, deserialize evidence error: %v", err)
+	}
+	blocks := make([]*types.Block, 0, 2)
+	for _, raw := range [][]byte{ev.First, ev.Second} {
+		block, err := types.BlockFromRawBytes(raw)
+		if err != nil {
+			return fmt.Errorf("verifyDoubleSign, deserialize block error: %v", err)
+		}
+		header := block.Header
+		if header.Height != height {
+			return fmt.Errorf("verifyDoubleSign, header at height %d is not at %d", header.Height, height)
+		}
+		hash := header.Hash()
+		signed := false
+		for _, sig := range header.SigData {
+			if signature.Verify(pub, hash[:], sig) == nil {
+				signed = true
+				break
+			}
+		}
+		if !signed {
+			return fmt.Errorf("verifyDoubleSign, header %s is not signed by the peer", hash.ToHexString())
+		}
+		blocks = append(blocks, block)
+	}
+	if blocks[0].Hash() == blocks[1].Hash() {
+		return fmt.Errorf("verifyDoubleSign, the blocks are the same")
+	}
+	for i, block := range blocks {
+		if !hasTxNotIn(block, blocks[1-i]) {
+			return fmt.Errorf("verifyDoubleSign, block %s has no tx the other doesn't have, it may be the empty "+
+				"variant of the same proposal", block.Hash().ToHexString())
+		}
+	}
+	return nil
+}
+
+func hasTxNotIn(block, other *types.Block) bool {
+	txs := make(map[common.Uint256]struct{}, len(other.Transactions))
+	for _, tx := range other.Transactions {
+		txs[tx.Hash()] = struct{}{}
+	}
+	for _, tx := range block.Transactions {
+		if _, ok := txs[tx.Hash()]; !ok {
+			return true
+		}
+	}
+	return false
+}
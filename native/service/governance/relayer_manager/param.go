@@ -94,3 +94,29 @@ func (this *ApproveRelayerParam) Deserialization(source *common.ZeroCopySource)
 	this.Address = addr
 	return nil
 }
+
+type RelayerConfigParam struct {
+	Config  RelayerConfig
+	Address common.Address
+}
+
+func (this *RelayerConfigParam) Serialization(sink *common.ZeroCopySink) {
+	this.Config.Serialization(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *RelayerConfigParam) Deserialization(source *common.ZeroCopySource) error {
+	if err := this.Config.Deserialization(source); err != nil {
+		return err
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	this.Address = addr
+	return nil
+}
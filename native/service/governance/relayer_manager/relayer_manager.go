@@ -19,9 +19,12 @@ package relayer_manager
 
 import (
 	"fmt"
+	"math/big"
+
 	"github.com/polynetwork/poly/native/event"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
 	"github.com/polynetwork/poly/native/service/utils"
@@ -33,6 +36,10 @@ const (
 	APPROVE_REGISTER_RELAYER = "approveRegisterRelayer"
 	REMOVE_RELAYER           = "RemoveRelayer"
 	APPROVE_REMOVE_RELAYER   = "approveRemoveRelayer"
+	INIT_RELAYER             = "initRelayer"
+	SET_RELAYER_CONFIG       = "setRelayerConfig"
+	GET_RELAYER              = "getRelayer"
+	GET_RELAYER_APPLY        = "getRelayerApply"
 
 	//key prefix
 	RELAYER        = "relayer"
@@ -40,6 +47,9 @@ const (
 	RELAYER_REMOVE = "relayerRemove"
 	APPLY_ID       = "applyID"
 	REMOVE_ID      = "removeID"
+	RELAYER_CONFIG = "relayerConfig"
+	RELAYER_STAKE  = "relayerStake"
+	APPLY_STAKE    = "applyStake"
 )
 
 //Register methods of node_manager contract
@@ -48,6 +58,36 @@ func RegisterRelayerManagerContract(native *native.NativeService) {
 	native.Register(APPROVE_REGISTER_RELAYER, ApproveRegisterRelayer)
 	native.Register(REMOVE_RELAYER, RemoveRelayer)
 	native.Register(APPROVE_REMOVE_RELAYER, ApproveRemoveRelayer)
+	native.Register(INIT_RELAYER, InitRelayer)
+	native.Register(SET_RELAYER_CONFIG, SetRelayerConfig)
+	native.Register(GET_RELAYER, GetRelayerQuery)
+	native.Register(GET_RELAYER_APPLY, GetRelayerApplyQuery)
+}
+
+// InitRelayer puts the relayers of the bootstrap genesis config, it is only run by the genesis block
+func InitRelayer(native *native.NativeService) ([]byte, error) {
+	if !native.IsGenesis() {
+		return utils.BYTE_FALSE, fmt.Errorf("InitRelayer, only allowed in genesis block")
+	}
+	configuration := new(config.BootstrapConfig)
+	if err := configuration.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InitRelayer, contract params deserialize error: %v", err)
+	}
+	for _, relayer := range configuration.Relayers {
+		address, err := common.AddressFromBase58(relayer)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitRelayer, common.AddressFromBase58 error: %v", err)
+		}
+		if err := putRelayer(native, address); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitRelayer, putRelayer error: %v", err)
+		}
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerManagerContractAddress,
+			States:          []interface{}{"InitRelayer", len(configuration.Relayers)},
+		})
+	return utils.BYTE_TRUE, nil
 }
 
 func RegisterRelayer(native *native.NativeService) ([]byte, error) {
@@ -59,9 +99,13 @@ func RegisterRelayer(native *native.NativeService) ([]byte, error) {
 	if err := utils.ValidateOwner(native, params.Address); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("RegisterRelayer, checkWitness: %s, error: %v", params.Address.ToBase58(), err)
 	}
-	if err := putRelayerApply(native, params); err != nil {
+	applyID, err := putRelayerApply(native, params)
+	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("RegisterRelayer, putRelayer error: %v", err)
 	}
+	if err := lockApplyStake(native, applyID, params); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterRelayer, %v", err)
+	}
 	return utils.BYTE_TRUE, nil
 }
 
@@ -97,6 +141,9 @@ func ApproveRegisterRelayer(native *native.NativeService) ([]byte, error) {
 			return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterRelayer, putRelayer error: %v", err)
 		}
 	}
+	if err := splitApplyStake(native, params.ID, relayerListParam.AddressList); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveRegisterRelayer, %v", err)
+	}
 	native.GetCacheDB().Delete(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_APPLY), utils.GetUint64Bytes(params.ID)))
 	native.AddNotify(
 		&event.NotifyEventInfo{
@@ -150,6 +197,9 @@ func ApproveRemoveRelayer(native *native.NativeService) ([]byte, error) {
 
 	for _, address := range relayerListParam.AddressList {
 		native.GetCacheDB().Delete(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER), address[:]))
+		if err := releaseRelayerStake(native, address); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("ApproveRemoveRelayer, %v", err)
+		}
 	}
 	native.AddNotify(
 		&event.NotifyEventInfo{
@@ -158,3 +208,78 @@ func ApproveRemoveRelayer(native *native.NativeService) ([]byte, error) {
 		})
 	return utils.BYTE_TRUE, nil
 }
+
+// SetRelayerConfig sets whether only the registered relayers can submit the proofs of the cross chain txs
+// and the stake each relayer registered is backed by, approved by the consensus peers
+func SetRelayerConfig(native *native.NativeService) ([]byte, error) {
+	params := new(RelayerConfigParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRelayerConfig, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRelayerConfig, checkWitness error: %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_RELAYER_CONFIG, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRelayerConfig, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	putRelayerConfig(native, &params.Config)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerManagerContractAddress,
+			States:          []interface{}{"SetRelayerConfig", params.Config.Required, params.Config.Stake.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// GetRelayerQuery returns the stake of the relayer of the address in input, with no owner and amount if
+// the relayer is not staked, to be called by preExec
+func GetRelayerQuery(native *native.NativeService) ([]byte, error) {
+	relayer, err := common.AddressParseFromBytes(native.GetInput())
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerQuery, address format error: %v", err)
+	}
+	ok, err := IsRelayer(native, relayer)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerQuery, %v", err)
+	}
+	if !ok {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerQuery, %s is not a registered relayer", relayer.ToBase58())
+	}
+	stake, err := GetRelayerStake(native, relayer)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerQuery, %v", err)
+	}
+	if stake == nil {
+		stake = &RelayerStake{Amount: new(big.Int)}
+	}
+	sink := common.NewZeroCopySink(nil)
+	stake.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// GetRelayerApplyQuery returns the relayers of the application of the id in input, to be called by preExec
+func GetRelayerApplyQuery(native *native.NativeService) ([]byte, error) {
+	applyID, eof := common.NewZeroCopySource(native.GetInput()).NextVarUint()
+	if eof {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerApplyQuery, deserialize apply id error")
+	}
+	relayerListParam, err := getRelayerApply(native, applyID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerApplyQuery, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	relayerListParam.Serialization(sink)
+	return sink.Bytes(), nil
+}
@@ -17,9 +17,11 @@
 package relayer_manager
 
 import (
+	"fmt"
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/genesis"
 	cstates "github.com/polynetwork/poly/core/states"
@@ -31,6 +33,7 @@ import (
 	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/polynetwork/poly/native/storage"
 	"github.com/stretchr/testify/assert"
+	"math/big"
 	"strconv"
 	"testing"
 )
@@ -197,3 +200,112 @@ func TestRemoveRelayer(t *testing.T) {
 		}
 	}
 }
+
+func TestInitRelayer(t *testing.T) {
+	relayer := common.Address{1, 2, 4, 6}
+	configuration := &config.BootstrapConfig{
+		Relayers: []string{relayer.ToBase58()},
+	}
+	sink := common.NewZeroCopySink(nil)
+	assert.Nil(t, configuration.Serialization(sink))
+
+	nativeService = NewNative(sink.Bytes(), new(types.Transaction), nil)
+	_, err := InitRelayer(nativeService)
+	assert.NotNil(t, err)
+
+	nativeService.SetGenesis()
+	res, err := InitRelayer(nativeService)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{1}, res)
+
+	store, err := nativeService.GetCacheDB().Get(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER), relayer[:]))
+	assert.Nil(t, err)
+	assert.NotNil(t, store)
+}
+
+type testBondKeeper map[common.Address]*big.Int
+
+func (this testBondKeeper) LockBond(native *native.NativeService, owner common.Address, amount *big.Int) error {
+	if this[owner].Cmp(amount) < 0 {
+		return fmt.Errorf("bond balance %s is less than %s", this[owner].String(), amount.String())
+	}
+	this[owner] = new(big.Int).Sub(this[owner], amount)
+	return nil
+}
+
+func (this testBondKeeper) ReleaseBond(native *native.NativeService, owner common.Address, amount *big.Int) error {
+	this[owner] = new(big.Int).Add(this[owner], amount)
+	return nil
+}
+
+func approveByConsensus(db *storage.CacheDB, id uint64, approve func(*native.NativeService) ([]byte, error)) {
+	for _, conAcct := range conAccts() {
+		sink := common.NewZeroCopySink(nil)
+		(&ApproveRelayerParam{id, conAcct.Address}).Serialization(sink)
+		tx := &types.Transaction{
+			SignedAddr: []common.Address{conAcct.Address},
+		}
+		approve(NewNative(sink.Bytes(), tx, db))
+	}
+}
+
+func TestRelayerStake(t *testing.T) {
+	keeper := testBondKeeper{acct.Address: big.NewInt(25)}
+	bondKeeper = keeper
+	defer func() { bondKeeper = nil }()
+
+	nativeService = NewNative(nil, new(types.Transaction), nil)
+	db := nativeService.GetCacheDB()
+	putPeerMapPoolAndView(db, conAccts())
+	putRelayerConfig(nativeService, &RelayerConfig{Required: true, Stake: big.NewInt(10)})
+
+	relayers := []common.Address{{1, 2, 4, 6}, {1, 4, 5, 7}}
+	params := &RelayerListParam{AddressList: relayers, Address: acct.Address}
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	tx := &types.Transaction{
+		SignedAddr: []common.Address{acct.Address},
+	}
+
+	res, err := RegisterRelayer(NewNative(sink.Bytes(), tx, db))
+	assert.Nil(t, err)
+	assert.Equal(t, utils.BYTE_TRUE, res)
+	assert.Equal(t, big.NewInt(5), keeper[acct.Address])
+
+	approveByConsensus(db, 0, ApproveRegisterRelayer)
+	for _, relayer := range relayers {
+		ok, err := IsRelayer(nativeService, relayer)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		stake, err := GetRelayerStake(nativeService, relayer)
+		assert.Nil(t, err)
+		assert.Equal(t, &RelayerStake{Owner: acct.Address, Amount: big.NewInt(10)}, stake)
+	}
+	stake, err := getStake(nativeService, applyStakeKey(0))
+	assert.Nil(t, err)
+	assert.Nil(t, stake)
+
+	raw, err := GetRelayerQuery(NewNative(relayers[0][:], tx, db))
+	assert.Nil(t, err)
+	queried := new(RelayerStake)
+	assert.Nil(t, queried.Deserialization(common.NewZeroCopySource(raw)))
+	assert.Equal(t, big.NewInt(10), queried.Amount)
+
+	res, err = RemoveRelayer(NewNative(sink.Bytes(), tx, db))
+	assert.Nil(t, err)
+	assert.Equal(t, utils.BYTE_TRUE, res)
+	approveByConsensus(db, 0, ApproveRemoveRelayer)
+	for _, relayer := range relayers {
+		ok, err := IsRelayer(nativeService, relayer)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+	}
+	assert.Equal(t, big.NewInt(25), keeper[acct.Address])
+
+	// the bond is not enough for three relayers
+	three := &RelayerListParam{AddressList: append([]common.Address{{9}}, relayers...), Address: acct.Address}
+	sink = common.NewZeroCopySink(nil)
+	three.Serialization(sink)
+	_, err = RegisterRelayer(NewNative(sink.Bytes(), tx, db))
+	assert.NotNil(t, err)
+}
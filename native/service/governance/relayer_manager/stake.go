@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relayer_manager
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// The relayers are staked with the bond deposited to side_chain_manager by lock proxy transfers, the same
+// balance the side chains registered with bond are backed by. The bond is locked from the balance of the
+// applicant on RegisterRelayer, kept for each relayer once approved and released to the applicant when
+// the relayer is removed.
+
+// BondKeeper locks and releases the bond balance of an address
+type BondKeeper interface {
+	LockBond(native *native.NativeService, owner common.Address, amount *big.Int) error
+	ReleaseBond(native *native.NativeService, owner common.Address, amount *big.Int) error
+}
+
+// bondKeeper is registered by side_chain_manager keeping the bond balance, which can't be called from here
+var bondKeeper BondKeeper
+
+// RegisterBondKeeper registers the keeper of the bond balance from the init of a contract
+func RegisterBondKeeper(keeper BondKeeper) {
+	if bondKeeper != nil {
+		panic("relayer bond keeper is already registered")
+	}
+	bondKeeper = keeper
+}
+
+// GetRelayerConfig returns the relayer config, nil if it is never set
+func GetRelayerConfig(native *native.NativeService) (*RelayerConfig, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_CONFIG)))
+	if err != nil {
+		return nil, fmt.Errorf("GetRelayerConfig, get relayer config store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetRelayerConfig, deserialize from raw storage item err:%v", err)
+	}
+	config := new(RelayerConfig)
+	if err := config.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetRelayerConfig, deserialize relayer config error: %v", err)
+	}
+	return config, nil
+}
+
+func putRelayerConfig(native *native.NativeService, config *RelayerConfig) {
+	sink := common.NewZeroCopySink(nil)
+	config.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_CONFIG)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// IsRelayer returns whether address is a registered relayer
+func IsRelayer(native *native.NativeService, address common.Address) (bool, error) {
+	value, err := native.GetCacheDB().Get(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER), address[:]))
+	if err != nil {
+		return false, fmt.Errorf("IsRelayer, get relayer error: %v", err)
+	}
+	return value != nil, nil
+}
+
+func getStake(native *native.NativeService, key []byte) (*RelayerStake, error) {
+	store, err := native.GetCacheDB().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("getStake, get stake store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("getStake, deserialize from raw storage item err:%v", err)
+	}
+	stake := new(RelayerStake)
+	if err := stake.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("getStake, deserialize stake error: %v", err)
+	}
+	return stake, nil
+}
+
+func putStake(native *native.NativeService, key []byte, stake *RelayerStake) {
+	sink := common.NewZeroCopySink(nil)
+	stake.Serialization(sink)
+	native.GetCacheDB().Put(key, cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func relayerStakeKey(relayer common.Address) []byte {
+	return utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_STAKE), relayer[:])
+}
+
+func applyStakeKey(applyID uint64) []byte {
+	return utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(APPLY_STAKE), utils.GetUint64Bytes(applyID))
+}
+
+// GetRelayerStake returns the bond locked for the relayer, nil if it is not staked
+func GetRelayerStake(native *native.NativeService, relayer common.Address) (*RelayerStake, error) {
+	return getStake(native, relayerStakeKey(relayer))
+}
+
+// lockApplyStake locks the stake of every relayer applied from the bond balance of the applicant
+func lockApplyStake(native *native.NativeService, applyID uint64, params *RelayerListParam) error {
+	config, err := GetRelayerConfig(native)
+	if err != nil {
+		return err
+	}
+	if config == nil || config.Stake.Sign() <= 0 || len(params.AddressList) == 0 {
+		return nil
+	}
+	if bondKeeper == nil {
+		return fmt.Errorf("lockApplyStake, no bond keeper registered")
+	}
+	amount := new(big.Int).Mul(config.Stake, big.NewInt(int64(len(params.AddressList))))
+	if err := bondKeeper.LockBond(native, params.Address, amount); err != nil {
+		return fmt.Errorf("lockApplyStake, %v", err)
+	}
+	putStake(native, applyStakeKey(applyID), &RelayerStake{Owner: params.Address, Amount: amount})
+	return nil
+}
+
+// splitApplyStake moves the stake locked for an application to the relayers approved evenly, the stake
+// a relayer approved again is backed by before is released
+func splitApplyStake(native *native.NativeService, applyID uint64, relayers []common.Address) error {
+	stake, err := getStake(native, applyStakeKey(applyID))
+	if err != nil {
+		return err
+	}
+	if stake == nil {
+		return nil
+	}
+	amount := new(big.Int).Div(stake.Amount, big.NewInt(int64(len(relayers))))
+	for _, relayer := range relayers {
+		if err := releaseRelayerStake(native, relayer); err != nil {
+			return err
+		}
+		putStake(native, relayerStakeKey(relayer), &RelayerStake{Owner: stake.Owner, Amount: amount})
+	}
+	native.GetCacheDB().Delete(applyStakeKey(applyID))
+	return nil
+}
+
+// releaseRelayerStake releases the stake of a relayer removed to its owner
+func releaseRelayerStake(native *native.NativeService, relayer common.Address) error {
+	stake, err := GetRelayerStake(native, relayer)
+	if err != nil {
+		return err
+	}
+	if stake == nil {
+		return nil
+	}
+	if bondKeeper == nil {
+		return fmt.Errorf("releaseRelayerStake, no bond keeper registered")
+	}
+	if err := bondKeeper.ReleaseBond(native, stake.Owner, stake.Amount); err != nil {
+		return fmt.Errorf("releaseRelayerStake, %v", err)
+	}
+	native.GetCacheDB().Delete(relayerStakeKey(relayer))
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relayer_manager
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+)
+
+// RelayerConfig is set by the consensus peers. The proofs of the cross chain txs are only taken from the
+// registered relayers when Required is set, and each relayer registered is backed by Stake of the bond
+// deposited by the applicant when Stake is positive.
+type RelayerConfig struct {
+	Required bool
+	Stake    *big.Int
+}
+
+func (this *RelayerConfig) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteBool(this.Required)
+	sink.WriteVarBytes(this.Stake.Bytes())
+}
+
+func (this *RelayerConfig) Deserialization(source *common.ZeroCopySource) error {
+	required, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("RelayerConfig deserialize required error")
+	}
+	stake, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RelayerConfig deserialize stake error")
+	}
+	this.Required = required
+	this.Stake = new(big.Int).SetBytes(stake)
+	return nil
+}
+
+// RelayerStake is the bond of Owner locked for a relayer, or for all the relayers of an application
+// before it's approved
+type RelayerStake struct {
+	Owner  common.Address
+	Amount *big.Int
+}
+
+func (this *RelayerStake) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteAddress(this.Owner)
+	sink.WriteVarBytes(this.Amount.Bytes())
+}
+
+func (this *RelayerStake) Deserialization(source *common.ZeroCopySource) error {
+	owner, eof := source.NextAddress()
+	if eof {
+		return fmt.Errorf("RelayerStake deserialize owner error")
+	}
+	amount, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RelayerStake deserialize amount error")
+	}
+	this.Owner = owner
+	this.Amount = new(big.Int).SetBytes(amount)
+	return nil
+}
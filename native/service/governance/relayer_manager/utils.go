@@ -33,16 +33,16 @@ func putRelayer(native *native.NativeService, relayer common.Address) error {
 	return nil
 }
 
-func putRelayerApply(native *native.NativeService, relayerListParam *RelayerListParam) error {
+func putRelayerApply(native *native.NativeService, relayerListParam *RelayerListParam) (uint64, error) {
 	contract := utils.RelayerManagerContractAddress
 	applyID, err := getApplyID(native)
 	if err != nil {
-		return fmt.Errorf("putRelayerApply, getApplyID error: %v", err)
+		return 0, fmt.Errorf("putRelayerApply, getApplyID error: %v", err)
 	}
 	newApplyID := applyID + 1
 	err = putApplyID(native, newApplyID)
 	if err != nil {
-		return fmt.Errorf("putRelayerApply, putApplyID error: %v", err)
+		return 0, fmt.Errorf("putRelayerApply, putApplyID error: %v", err)
 	}
 	sink := common.NewZeroCopySink(nil)
 	relayerListParam.Serialization(sink)
@@ -53,7 +53,7 @@ func putRelayerApply(native *native.NativeService, relayerListParam *RelayerList
 			ContractAddress: utils.NodeManagerContractAddress,
 			States:          []interface{}{"putRelayerApply", applyID},
 		})
-	return nil
+	return applyID, nil
 }
 
 func getRelayerApply(native *native.NativeService, applyID uint64) (*RelayerListParam, error) {
@@ -0,0 +1,457 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package side_chain_manager
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/treasury"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// A side chain can be registered by anyone with a bond instead of the approval of the consensus nodes.
+// The bond is deposited by a lock proxy transfer from the bond chain to poly whose receiver is the poly
+// address of the depositor, and the chain registered with it stays in probation, where only lock proxy
+// transfers of capped volume are allowed, until the consensus nodes approve it and release the bond or
+// slash the bond to the treasury.
+
+func init() {
+	relayer_manager.RegisterBondKeeper(relayerBondKeeper{})
+}
+
+// relayerBondKeeper stakes the relayers registered with the bond balance
+type relayerBondKeeper struct{}
+
+func (relayerBondKeeper) LockBond(native *native.NativeService, owner common.Address, amount *big.Int) error {
+	balance, err := GetBondBalance(native, owner)
+	if err != nil {
+		return fmt.Errorf("LockBond, %v", err)
+	}
+	if balance.Cmp(amount) < 0 {
+		return fmt.Errorf("LockBond, bond balance %s is less than %s", balance.String(), amount.String())
+	}
+	putBondBalance(native, owner, balance.Sub(balance, amount))
+	return nil
+}
+
+func (relayerBondKeeper) ReleaseBond(native *native.NativeService, owner common.Address, amount *big.Int) error {
+	balance, err := GetBondBalance(native, owner)
+	if err != nil {
+		return fmt.Errorf("ReleaseBond, %v", err)
+	}
+	putBondBalance(native, owner, balance.Add(balance, amount))
+	return nil
+}
+
+// getRawItem returns nil if key is not found
+func getRawItem(native *native.NativeService, key []byte) ([]byte, error) {
+	store, err := native.GetCacheDB().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("get store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize from raw storage item error: %v", err)
+	}
+	return raw, nil
+}
+
+func bondConfigKey() []byte {
+	return utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(BOND_CONFIG))
+}
+
+func getBondConfig(native *native.NativeService) (*BondConfig, error) {
+	raw, err := getRawItem(native, bondConfigKey())
+	if err != nil {
+		return nil, fmt.Errorf("getBondConfig, %v", err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	config := new(BondConfig)
+	if err := config.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("getBondConfig, deserialize error: %v", err)
+	}
+	return config, nil
+}
+
+func bondBalanceKey(address common.Address) []byte {
+	return utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(BOND_BALANCE), address[:])
+}
+
+// GetBondBalance returns the bond deposited by address and not used by any registration
+func GetBondBalance(native *native.NativeService, address common.Address) (*big.Int, error) {
+	raw, err := getRawItem(native, bondBalanceKey(address))
+	if err != nil {
+		return nil, fmt.Errorf("GetBondBalance, %v", err)
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+func putBondBalance(native *native.NativeService, address common.Address, balance *big.Int) {
+	if balance.Sign() == 0 {
+		native.GetCacheDB().Delete(bondBalanceKey(address))
+		return
+	}
+	native.GetCacheDB().Put(bondBalanceKey(address), cstates.GenRawStorageItem(balance.Bytes()))
+}
+
+func bondKey(chainID uint64) []byte {
+	return utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(BOND), utils.GetUint64Bytes(chainID))
+}
+
+// GetBond returns the bond of the side chain in probation, nil if the chain is not in probation
+func GetBond(native *native.NativeService, chainID uint64) (*Bond, error) {
+	raw, err := getRawItem(native, bondKey(chainID))
+	if err != nil {
+		return nil, fmt.Errorf("GetBond, %v", err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	bond := new(Bond)
+	if err := bond.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetBond, deserialize error: %v", err)
+	}
+	return bond, nil
+}
+
+func putBond(native *native.NativeService, chainID uint64, bond *Bond) {
+	sink := common.NewZeroCopySink(nil)
+	bond.Serialization(sink)
+	native.GetCacheDB().Put(bondKey(chainID), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// endProbation removes the bond and the volumes counted of the chain
+func endProbation(native *native.NativeService, chainID uint64) {
+	native.GetCacheDB().Delete(bondKey(chainID))
+	prefix := utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(PROBATION_VOLUME), utils.GetUint64Bytes(chainID))
+	keys := make([][]byte, 0)
+	iter := native.GetCacheDB().NewIterator(prefix)
+	for has := iter.First(); has; has = iter.Next() {
+		keys = append(keys, append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+	for _, key := range keys {
+		native.GetCacheDB().Delete(key)
+	}
+}
+
+// SetBondConfig enables the registration with bond, approved by the consensus nodes
+func SetBondConfig(native *native.NativeService) ([]byte, error) {
+	params := new(BondConfigParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetBondConfig, contract params deserialize error: %v", err)
+	}
+	if params.Config.Amount.Sign() <= 0 || params.Config.ProbationCap.Sign() <= 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("SetBondConfig, amount and probation cap should be positive")
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetBondConfig, checkWitness error: %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_BOND_CONFIG, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetBondConfig, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	sink = common.NewZeroCopySink(nil)
+	params.Config.Serialization(sink)
+	utils.PutBytes(native, bondConfigKey(), sink.Bytes())
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States: []interface{}{"SetBondConfig", params.Config.ChainID, hex.EncodeToString(params.Config.Asset),
+				params.Config.Amount.String(), params.Config.ProbationCap.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// DepositBond credits the bond locked by the lock proxy transfer from fromChainID to poly, it's called by
+// the cross chain manager for the transfers to poly
+func DepositBond(native *native.NativeService, fromChainID uint64, args []byte) error {
+	config, err := getBondConfig(native)
+	if err != nil {
+		return fmt.Errorf("DepositBond, %v", err)
+	}
+	if config == nil {
+		return fmt.Errorf("DepositBond, registration with bond is not enabled")
+	}
+	if fromChainID != config.ChainID {
+		return fmt.Errorf("DepositBond, bond should be locked on chain %d", config.ChainID)
+	}
+	asset, receiver, amount := scom.DecodeTransfer(args)
+	if amount == nil || amount.Sign() <= 0 || !bytes.Equal(asset, config.Asset) {
+		return fmt.Errorf("DepositBond, not a lock proxy transfer of the bond asset")
+	}
+	depositor, err := common.AddressParseFromBytes(receiver)
+	if err != nil {
+		return fmt.Errorf("DepositBond, receiver should be a poly address: %v", err)
+	}
+	balance, err := GetBondBalance(native, depositor)
+	if err != nil {
+		return fmt.Errorf("DepositBond, %v", err)
+	}
+	putBondBalance(native, depositor, balance.Add(balance, amount))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States:          []interface{}{"DepositBond", depositor.ToBase58(), amount.String()},
+		})
+	return nil
+}
+
+// RegisterBondedSideChain registers a side chain in probation with the bond of the applicant
+func RegisterBondedSideChain(native *native.NativeService) ([]byte, error) {
+	params := new(RegisterBondedParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, contract params deserialize error: %v", err)
+	}
+	register := &params.Register
+	if len(params.Refund) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, refund address is empty")
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, register.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, checkWitness error: %v", err)
+	}
+
+	config, err := getBondConfig(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, %v", err)
+	}
+	if config == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, registration with bond is not enabled")
+	}
+	apply, err := getSideChainApply(native, register.ChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, getRegisterSideChain error: %v", err)
+	}
+	sideChain, err := GetSideChain(native, register.ChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, getSideChain error: %v", err)
+	}
+	if apply != nil || sideChain != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, chainid %d already requested or registered", register.ChainId)
+	}
+	if err := checkChainID(native, register.ChainId, register.Router, register.Name); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, %v", err)
+	}
+	balance, err := GetBondBalance(native, register.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, %v", err)
+	}
+	if balance.Cmp(config.Amount) < 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, bond balance %s is less than %s",
+			balance.String(), config.Amount.String())
+	}
+
+	putBondBalance(native, register.Address, balance.Sub(balance, config.Amount))
+	putBond(native, register.ChainId, &Bond{
+		Owner:   register.Address,
+		Refund:  params.Refund,
+		ChainID: config.ChainID,
+		Asset:   config.Asset,
+		Amount:  config.Amount,
+	})
+	sideChain = &SideChain{
+		Address:      register.Address,
+		ChainId:      register.ChainId,
+		Router:       register.Router,
+		Name:         register.Name,
+		BlocksToWait: register.BlocksToWait,
+		CCMCAddress:  register.CCMCAddress,
+		ExtraInfo:    register.ExtraInfo,
+		Meta:         register.Meta,
+	}
+	if err := PutSideChain(native, sideChain); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterBondedSideChain, putSideChain error: %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States: []interface{}{"RegisterBondedSideChain", register.ChainId, register.Router, register.Name,
+				register.BlocksToWait, config.Amount.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// ApproveProbation ends the probation of a side chain and releases its bond, the event is what the
+// refund on the bond chain is made from
+func ApproveProbation(native *native.NativeService) ([]byte, error) {
+	params := new(ChainidParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveProbation, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveProbation, checkWitness error: %v", err)
+	}
+
+	bond, err := GetBond(native, params.Chainid)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveProbation, %v", err)
+	}
+	if bond == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveProbation, side chain %d is not in probation", params.Chainid)
+	}
+
+	//check consensus signs
+	ok, err := node_manager.CheckConsensusSigns(native, APPROVE_PROBATION, utils.GetUint64Bytes(params.Chainid), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveProbation, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	endProbation(native, params.Chainid)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States: []interface{}{"ReleaseBond", params.Chainid, bond.ChainID, hex.EncodeToString(bond.Asset),
+				hex.EncodeToString(bond.Refund), bond.Amount.String()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// SlashBond credits the bond of a side chain in probation to the treasury and winds the chain down
+func SlashBond(native *native.NativeService) ([]byte, error) {
+	params := new(ChainidParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SlashBond, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SlashBond, checkWitness error: %v", err)
+	}
+
+	bond, err := GetBond(native, params.Chainid)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SlashBond, %v", err)
+	}
+	if bond == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SlashBond, side chain %d is not in probation", params.Chainid)
+	}
+
+	//check consensus signs
+	ok, err := node_manager.CheckConsensusSigns(native, SLASH_BOND, utils.GetUint64Bytes(params.Chainid), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SlashBond, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	endProbation(native, params.Chainid)
+	if err := treasury.Credit(native, treasury.INCOME_SLASH, bond.ChainID, bond.Asset, bond.Amount); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SlashBond, %v", err)
+	}
+	windDown := &WindDown{
+		QuitHeight: native.GetHeight(),
+		EndHeight:  native.GetHeight() + WIND_DOWN_BLOCKS,
+	}
+	putWindDown(native, params.Chainid, windDown)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States:          []interface{}{"SlashBond", params.Chainid, bond.Amount.String(), windDown.EndHeight},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// CheckProbation counts the volume of the transfer from fromChainID to toChainID against the cap of the
+// chains in probation, only the transfers made by lock proxy are allowed for them
+func CheckProbation(native *native.NativeService, fromChainID, toChainID uint64, args []byte) error {
+	var config *BondConfig
+	for _, chainID := range []uint64{fromChainID, toChainID} {
+		bond, err := GetBond(native, chainID)
+		if err != nil {
+			return fmt.Errorf("CheckProbation, %v", err)
+		}
+		if bond == nil {
+			continue
+		}
+		if config == nil {
+			if config, err = getBondConfig(native); err != nil {
+				return fmt.Errorf("CheckProbation, %v", err)
+			}
+			if config == nil {
+				return fmt.Errorf("CheckProbation, bond config of chain %d in probation not found", chainID)
+			}
+		}
+		asset, amount := scom.DecodeTransferArgs(args)
+		if amount == nil {
+			return fmt.Errorf("CheckProbation, only lock proxy transfers are allowed for chain %d in probation", chainID)
+		}
+		key := utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(PROBATION_VOLUME),
+			utils.GetUint64Bytes(chainID), asset)
+		raw, err := getRawItem(native, key)
+		if err != nil {
+			return fmt.Errorf("CheckProbation, %v", err)
+		}
+		volume := new(big.Int).Add(new(big.Int).SetBytes(raw), amount)
+		if volume.Cmp(config.ProbationCap) > 0 {
+			return fmt.Errorf("CheckProbation, volume %s of chain %d in probation exceeds cap %s",
+				volume.String(), chainID, config.ProbationCap.String())
+		}
+		native.GetCacheDB().Put(key, cstates.GenRawStorageItem(volume.Bytes()))
+	}
+	return nil
+}
+
+// GetBondBalanceQuery returns the bond balance of the address in big endian, to be called by preExec
+func GetBondBalanceQuery(native *native.NativeService) ([]byte, error) {
+	params := new(BondBalanceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetBondBalanceQuery, contract params deserialize error: %v", err)
+	}
+	balance, err := GetBondBalance(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetBondBalanceQuery, %v", err)
+	}
+	return balance.Bytes(), nil
+}
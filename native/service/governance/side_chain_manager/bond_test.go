@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package side_chain_manager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func lockArgs(asset []byte, receiver []byte, amount int64) []byte {
+	le := make([]byte, 32)
+	be := big.NewInt(amount).Bytes()
+	for i, b := range be {
+		le[len(be)-1-i] = b
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(asset)
+	sink.WriteVarBytes(receiver)
+	sink.WriteBytes(le)
+	return sink.Bytes()
+}
+
+func TestBondedSideChain(t *testing.T) {
+	ns := NewNative(nil, new(types.Transaction), nil)
+	asset := []byte{1, 2, 3}
+	assert.Error(t, DepositBond(ns, 2, lockArgs(asset, acct.Address[:], 100)), "bond not enabled")
+
+	sink := common.NewZeroCopySink(nil)
+	(&BondConfig{ChainID: 2, Asset: asset, Amount: big.NewInt(100), ProbationCap: big.NewInt(50)}).Serialization(sink)
+	utils.PutBytes(ns, bondConfigKey(), sink.Bytes())
+
+	assert.Error(t, DepositBond(ns, 3, lockArgs(asset, acct.Address[:], 100)), "wrong bond chain")
+	assert.Error(t, DepositBond(ns, 2, lockArgs([]byte{9}, acct.Address[:], 100)), "wrong bond asset")
+	assert.Nil(t, DepositBond(ns, 2, lockArgs(asset, acct.Address[:], 60)))
+	assert.Nil(t, DepositBond(ns, 2, lockArgs(asset, acct.Address[:], 60)))
+	balance, err := GetBondBalance(ns, acct.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(120), balance)
+
+	sink = common.NewZeroCopySink(nil)
+	param := &RegisterBondedParam{
+		Refund:   []byte{7, 8},
+		Register: RegisterSideChainParam{Address: acct.Address, ChainId: 9, Router: 2, Name: "bonded", BlocksToWait: 1},
+	}
+	assert.Nil(t, param.Serialization(sink))
+	tx := &types.Transaction{SignedAddr: []common.Address{acct.Address}}
+	ns = NewNative(sink.Bytes(), tx, ns.GetCacheDB())
+	_, err = RegisterBondedSideChain(ns)
+	assert.Nil(t, err)
+	_, err = RegisterBondedSideChain(ns)
+	assert.Error(t, err, "chain already registered")
+
+	sideChain, err := GetSideChain(ns, 9)
+	assert.Nil(t, err)
+	assert.Equal(t, "bonded", sideChain.Name)
+	bond, err := GetBond(ns, 9)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(100), bond.Amount)
+	assert.Equal(t, []byte{7, 8}, bond.Refund)
+	balance, err = GetBondBalance(ns, acct.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(20), balance)
+
+	assert.Nil(t, CheckProbation(ns, 9, 2, lockArgs(asset, []byte{1}, 30)))
+	assert.Error(t, CheckProbation(ns, 2, 9, lockArgs(asset, []byte{1}, 30)), "volume exceeds cap")
+	assert.Nil(t, CheckProbation(ns, 2, 9, lockArgs([]byte{4}, []byte{1}, 30)), "cap is per asset")
+	assert.Error(t, CheckProbation(ns, 2, 9, []byte{1, 2}), "not a lock proxy transfer")
+	assert.Nil(t, CheckProbation(ns, 2, 3, []byte{1, 2}), "chains not in probation are not capped")
+
+	endProbation(ns, 9)
+	bond, err = GetBond(ns, 9)
+	assert.Nil(t, err)
+	assert.Nil(t, bond)
+	assert.Nil(t, CheckProbation(ns, 2, 9, lockArgs(asset, []byte{1}, 1000)))
+}
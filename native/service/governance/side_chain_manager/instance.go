@@ -0,0 +1,193 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package side_chain_manager
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// A bridge instance is an independent bridge co-existing with the default one, e.g. a canary. The state
+// of cross_chain_manager and side_chain_manager written through InvokeInstance is kept in the namespace
+// of the instance, and the governance methods of the instance are approved by its admin instead of the
+// consensus nodes. The header sync state is shared by all instances, and the view change hooks of the
+// cross chain manager only run for the default instance.
+//
+// An instance never writes the state shared with the default instance, so that its admin only approves
+// the changes of the instance. The cross chain txs made in an instance are verified by the side chains
+// against the shared poly headers, so their cross states carry the instance id, see scom.MakeInstanceLeaf,
+// and the CCM contracts deployed for an instance only take the ones of the instance.
+
+func checkInstanceID(instance string) error {
+	if len(instance) == 0 || len(instance) > MAX_INSTANCE_ID_LEN {
+		return fmt.Errorf("length of instance id should be 1 to %d", MAX_INSTANCE_ID_LEN)
+	}
+	return nil
+}
+
+func instanceKey(instance string) []byte {
+	return utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(INSTANCE), []byte(instance))
+}
+
+// GetInstanceAdmin returns the admin of the bridge instance, nil if it's not created
+func GetInstanceAdmin(native *native.NativeService, instance string) (*common.Address, error) {
+	store, err := native.GetCacheDB().Get(instanceKey(instance))
+	if err != nil {
+		return nil, fmt.Errorf("GetInstanceAdmin, get admin error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetInstanceAdmin, deserialize from raw storage item err:%v", err)
+	}
+	admin, err := common.AddressParseFromBytes(raw)
+	if err != nil {
+		return nil, fmt.Errorf("GetInstanceAdmin, parse admin error: %v", err)
+	}
+	return &admin, nil
+}
+
+func putInstanceAdmin(native *native.NativeService, instance string, admin common.Address) {
+	native.GetCacheDB().Put(instanceKey(instance), cstates.GenRawStorageItem(admin[:]))
+}
+
+// CreateInstance creates a bridge instance with its admin, approved by the consensus nodes
+func CreateInstance(native *native.NativeService) ([]byte, error) {
+	params := new(InstanceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CreateInstance, contract params deserialize error: %v", err)
+	}
+	if native.GetCacheDB().Namespace() != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CreateInstance, not allowed in an instance")
+	}
+	if err := checkInstanceID(params.Instance); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CreateInstance, %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CreateInstance, checkWitness error: %v", err)
+	}
+
+	admin, err := GetInstanceAdmin(native, params.Instance)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CreateInstance, %v", err)
+	}
+	if admin != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CreateInstance, instance %s already created", params.Instance)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, CREATE_INSTANCE, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("CreateInstance, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	putInstanceAdmin(native, params.Instance, params.Admin)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States:          []interface{}{"CreateInstance", params.Instance, params.Admin.ToBase58()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// SetInstanceAdmin hands a bridge instance over to a new admin, signed by the current one
+func SetInstanceAdmin(native *native.NativeService) ([]byte, error) {
+	params := new(InstanceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetInstanceAdmin, contract params deserialize error: %v", err)
+	}
+	if native.GetCacheDB().Namespace() != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetInstanceAdmin, not allowed in an instance")
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetInstanceAdmin, checkWitness error: %v", err)
+	}
+
+	admin, err := GetInstanceAdmin(native, params.Instance)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetInstanceAdmin, %v", err)
+	}
+	if admin == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetInstanceAdmin, instance %s is not created", params.Instance)
+	}
+	if *admin != params.Address {
+		return utils.BYTE_FALSE, fmt.Errorf("SetInstanceAdmin, %s is not the admin of instance %s",
+			params.Address.ToBase58(), params.Instance)
+	}
+
+	putInstanceAdmin(native, params.Instance, params.Admin)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States:          []interface{}{"SetInstanceAdmin", params.Instance, params.Admin.ToBase58()},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// InvokeInstance runs a method of the contract being invoked in the namespace of a bridge instance,
+// both cross_chain_manager and side_chain_manager register it
+func InvokeInstance(native *native.NativeService) ([]byte, error) {
+	params := new(InvokeInstanceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InvokeInstance, contract params deserialize error: %v", err)
+	}
+	if native.GetCacheDB().Namespace() != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InvokeInstance, already in instance %s", native.GetCacheDB().Namespace())
+	}
+	if params.Method == INVOKE_INSTANCE || params.Method == CREATE_INSTANCE || params.Method == SET_INSTANCE_ADMIN {
+		return utils.BYTE_FALSE, fmt.Errorf("InvokeInstance, method %s is not allowed in an instance", params.Method)
+	}
+	admin, err := GetInstanceAdmin(native, params.Instance)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InvokeInstance, %v", err)
+	}
+	if admin == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InvokeInstance, instance %s is not created", params.Instance)
+	}
+
+	native.EnterInstance([]byte(params.Instance), *admin,
+		utils.CrossChainManagerContractAddress, utils.SideChainManagerContractAddress)
+	res, err := native.Dispatch(params.Method, params.Args)
+	if err != nil {
+		return res, err
+	}
+	if native.GetCacheDB().SharedWritten() {
+		return utils.BYTE_FALSE, fmt.Errorf("InvokeInstance, method %s writes the state shared with the default instance",
+			params.Method)
+	}
+	return res, nil
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package side_chain_manager
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func invokeInstance(db *storage.CacheDB, instance, method string, args []byte) error {
+	sink := common.NewZeroCopySink(nil)
+	(&InvokeInstanceParam{Instance: instance, Method: method, Args: args}).Serialization(sink)
+	tx := &types.Transaction{
+		SignedAddr: []common.Address{acct.Address},
+	}
+	ns := NewNative(sink.Bytes(), tx, db)
+	RegisterSideChainManagerContract(ns)
+	_, err := InvokeInstance(ns)
+	if err == nil {
+		db.Commit()
+	}
+	db.Reset()
+	return err
+}
+
+func TestInstance(t *testing.T) {
+	ns := NewNative(nil, new(types.Transaction), nil)
+	db := ns.GetCacheDB()
+	putInstanceAdmin(ns, "canary", acct.Address)
+	putInstanceAdmin(ns, "other", common.Address{1})
+	db.Commit()
+
+	sink := common.NewZeroCopySink(nil)
+	param := &RegisterSideChainParam{Address: acct.Address, ChainId: 9, Router: 3, Name: "canary chain", BlocksToWait: 1}
+	assert.Nil(t, param.Serialization(sink))
+	register := sink.Bytes()
+	sink = common.NewZeroCopySink(nil)
+	(&ChainidParam{Chainid: 9, Address: acct.Address}).Serialization(sink)
+	approve := sink.Bytes()
+
+	err := invokeInstance(db, "unknown", REGISTER_SIDE_CHAIN, register)
+	assert.Error(t, err, "instance not created")
+	err = invokeInstance(db, "canary", INVOKE_INSTANCE, register)
+	assert.Error(t, err, "nested instance")
+
+	err = invokeInstance(db, "canary", REGISTER_SIDE_CHAIN, register)
+	assert.Nil(t, err)
+	err = invokeInstance(db, "canary", APPROVE_REGISTER_SIDE_CHAIN, approve)
+	assert.Nil(t, err, "admin approves alone")
+
+	sideChain, err := GetSideChain(ns, 9)
+	assert.Nil(t, err)
+	assert.Nil(t, sideChain, "side chain of instance should not be in default")
+	db.SetNamespace([]byte("canary"), utils.SideChainManagerContractAddress[:])
+	sideChain, err = GetSideChain(ns, 9)
+	assert.Nil(t, err)
+	assert.Equal(t, "canary chain", sideChain.Name)
+	db.Reset()
+
+	err = invokeInstance(db, "other", REGISTER_SIDE_CHAIN, register)
+	assert.Nil(t, err)
+	err = invokeInstance(db, "other", APPROVE_REGISTER_SIDE_CHAIN, approve)
+	assert.Error(t, err, "only the admin of the instance approves")
+}
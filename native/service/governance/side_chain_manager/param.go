@@ -18,6 +18,7 @@ package side_chain_manager
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
@@ -32,6 +33,7 @@ type RegisterSideChainParam struct {
 	BlocksToWait uint64
 	CCMCAddress  []byte
 	ExtraInfo    []byte
+	Meta         *SideChainMeta
 }
 
 func (this *RegisterSideChainParam) Serialization(sink *common.ZeroCopySink) error {
@@ -45,6 +47,7 @@ func (this *RegisterSideChainParam) Serialization(sink *common.ZeroCopySink) err
 	height := config.GetExtraInfoHeight(config.DefConfig.P2PNode.NetworkId)
 	if !config.EXTRA_INFO_HEIGHT_FORK_CHECK || ledger.DefLedger.GetCurrentBlockHeight() >= height {
 		sink.WriteVarBytes(this.ExtraInfo)
+		writeSideChainMeta(sink, this.Meta)
 	}
 
 	return nil
@@ -83,6 +86,10 @@ func (this *RegisterSideChainParam) Deserialization(source *common.ZeroCopySourc
 		return fmt.Errorf("source.NextVarBytes, deserialize CCMCAddress error")
 	}
 	ExtraInfo, _ := source.NextVarBytes()
+	meta, err := readSideChainMeta(source)
+	if err != nil {
+		return err
+	}
 	this.Address = addr
 	this.ChainId = chainId
 	this.Router = router
@@ -90,6 +97,7 @@ func (this *RegisterSideChainParam) Deserialization(source *common.ZeroCopySourc
 	this.BlocksToWait = blocksToWait
 	this.CCMCAddress = CCMCAddress
 	this.ExtraInfo = ExtraInfo
+	this.Meta = meta
 	return nil
 }
 
@@ -261,3 +269,425 @@ func (this *BtcTxParam) Deserialization(source *common.ZeroCopySource) error {
 	this.Detial = detial
 	return nil
 }
+
+type LockEventTopicParam struct {
+	Address      common.Address
+	ChainId      uint64
+	LockContract []byte
+	Topic        []byte
+	ParamIndex   uint64
+}
+
+func (this *LockEventTopicParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteVarUint(this.ChainId)
+	sink.WriteVarBytes(this.LockContract)
+	sink.WriteVarBytes(this.Topic)
+	sink.WriteVarUint(this.ParamIndex)
+}
+
+func (this *LockEventTopicParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("LockEventTopicParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("LockEventTopicParam, common.AddressParseFromBytes error: %s", err)
+	}
+	chainId, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("LockEventTopicParam deserialize chain id error")
+	}
+	lockContract, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("LockEventTopicParam deserialize lock contract error")
+	}
+	topic, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("LockEventTopicParam deserialize topic error")
+	}
+	paramIndex, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("LockEventTopicParam deserialize param index error")
+	}
+
+	this.Address = addr
+	this.ChainId = chainId
+	this.LockContract = lockContract
+	this.Topic = topic
+	this.ParamIndex = paramIndex
+	return nil
+}
+
+type ConfirmationTiersParam struct {
+	Address common.Address
+	ChainId uint64
+	Tiers   *ConfirmationTiers
+}
+
+func (this *ConfirmationTiersParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteVarUint(this.ChainId)
+	this.Tiers.Serialization(sink)
+}
+
+func (this *ConfirmationTiersParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ConfirmationTiersParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("ConfirmationTiersParam, common.AddressParseFromBytes error: %s", err)
+	}
+	chainId, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ConfirmationTiersParam deserialize chain id error")
+	}
+	tiers := new(ConfirmationTiers)
+	if err := tiers.Deserialization(source); err != nil {
+		return fmt.Errorf("ConfirmationTiersParam deserialize tiers error: %v", err)
+	}
+
+	this.Address = addr
+	this.ChainId = chainId
+	this.Tiers = tiers
+	return nil
+}
+
+type ForkScheduleParam struct {
+	Address  common.Address
+	ChainId  uint64
+	Schedule *ForkSchedule
+}
+
+func (this *ForkScheduleParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteVarUint(this.ChainId)
+	this.Schedule.Serialization(sink)
+}
+
+func (this *ForkScheduleParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ForkScheduleParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("ForkScheduleParam, common.AddressParseFromBytes error: %s", err)
+	}
+	chainId, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ForkScheduleParam deserialize chain id error")
+	}
+	schedule := new(ForkSchedule)
+	if err := schedule.Deserialization(source); err != nil {
+		return fmt.Errorf("ForkScheduleParam deserialize schedule error: %v", err)
+	}
+
+	this.Address = addr
+	this.ChainId = chainId
+	this.Schedule = schedule
+	return nil
+}
+
+type MaxProofAgeParam struct {
+	Address common.Address
+	ChainId uint64
+	MaxAge  uint64
+}
+
+func (this *MaxProofAgeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteVarUint(this.ChainId)
+	sink.WriteVarUint(this.MaxAge)
+}
+
+func (this *MaxProofAgeParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("MaxProofAgeParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("MaxProofAgeParam, common.AddressParseFromBytes error: %s", err)
+	}
+	chainId, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("MaxProofAgeParam deserialize chain id error")
+	}
+	maxAge, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("MaxProofAgeParam deserialize max age error")
+	}
+
+	this.Address = addr
+	this.ChainId = chainId
+	this.MaxAge = maxAge
+	return nil
+}
+
+// MerkleHashParam sets the hash primitive registered in precompile for the merkle proofs of ChainId
+type MerkleHashParam struct {
+	Address common.Address
+	ChainId uint64
+	Hash    string
+}
+
+func (this *MerkleHashParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteVarUint(this.ChainId)
+	sink.WriteString(this.Hash)
+}
+
+func (this *MerkleHashParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("MerkleHashParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("MerkleHashParam, common.AddressParseFromBytes error: %s", err)
+	}
+	chainId, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("MerkleHashParam deserialize chain id error")
+	}
+	hash, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("MerkleHashParam deserialize hash error")
+	}
+
+	this.Address = addr
+	this.ChainId = chainId
+	this.Hash = hash
+	return nil
+}
+
+// LateProofParam lets the proofs at Height of ChainId through the max proof age
+type LateProofParam struct {
+	Address common.Address
+	ChainId uint64
+	Height  uint64
+}
+
+func (this *LateProofParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteVarUint(this.ChainId)
+	sink.WriteVarUint(this.Height)
+}
+
+func (this *LateProofParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("LateProofParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("LateProofParam, common.AddressParseFromBytes error: %s", err)
+	}
+	chainId, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("LateProofParam deserialize chain id error")
+	}
+	height, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("LateProofParam deserialize height error")
+	}
+
+	this.Address = addr
+	this.ChainId = chainId
+	this.Height = height
+	return nil
+}
+
+type OpReturnLimitParam struct {
+	Address common.Address
+	ChainId uint64
+	Limit   uint64
+}
+
+func (this *OpReturnLimitParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteVarUint(this.ChainId)
+	sink.WriteVarUint(this.Limit)
+}
+
+func (this *OpReturnLimitParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("OpReturnLimitParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("OpReturnLimitParam, common.AddressParseFromBytes error: %s", err)
+	}
+	chainId, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("OpReturnLimitParam deserialize chain id error")
+	}
+	limit, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("OpReturnLimitParam deserialize limit error")
+	}
+
+	this.Address = addr
+	this.ChainId = chainId
+	this.Limit = limit
+	return nil
+}
+
+type InstanceParam struct {
+	Address  common.Address
+	Instance string
+	Admin    common.Address
+}
+
+func (this *InstanceParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteString(this.Instance)
+	sink.WriteVarBytes(this.Admin[:])
+}
+
+func (this *InstanceParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("InstanceParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("InstanceParam, common.AddressParseFromBytes error: %s", err)
+	}
+	instance, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("InstanceParam deserialize instance error")
+	}
+	admin, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("InstanceParam deserialize admin error")
+	}
+	adminAddr, err := common.AddressParseFromBytes(admin)
+	if err != nil {
+		return fmt.Errorf("InstanceParam, common.AddressParseFromBytes admin error: %s", err)
+	}
+
+	this.Address = addr
+	this.Instance = instance
+	this.Admin = adminAddr
+	return nil
+}
+
+type InvokeInstanceParam struct {
+	Instance string
+	Method   string
+	Args     []byte
+}
+
+func (this *InvokeInstanceParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Instance)
+	sink.WriteString(this.Method)
+	sink.WriteVarBytes(this.Args)
+}
+
+func (this *InvokeInstanceParam) Deserialization(source *common.ZeroCopySource) error {
+	instance, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("InvokeInstanceParam deserialize instance error")
+	}
+	method, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("InvokeInstanceParam deserialize method error")
+	}
+	args, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("InvokeInstanceParam deserialize args error")
+	}
+
+	this.Instance = instance
+	this.Method = method
+	this.Args = args
+	return nil
+}
+
+type BondConfigParam struct {
+	Address common.Address
+	Config  *BondConfig
+}
+
+func (this *BondConfigParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	this.Config.Serialization(sink)
+}
+
+func (this *BondConfigParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BondConfigParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("BondConfigParam, common.AddressParseFromBytes error: %s", err)
+	}
+	config := new(BondConfig)
+	if err := config.Deserialization(source); err != nil {
+		return fmt.Errorf("BondConfigParam deserialize config error: %v", err)
+	}
+
+	this.Address = addr
+	this.Config = config
+	return nil
+}
+
+// RegisterBondedParam registers a side chain in probation with the bond of Address, the bond is paid
+// back to Refund on the bond chain once the chain is approved
+type RegisterBondedParam struct {
+	Refund   []byte
+	Register RegisterSideChainParam
+}
+
+func (this *RegisterBondedParam) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteVarBytes(this.Refund)
+	return this.Register.Serialization(sink)
+}
+
+func (this *RegisterBondedParam) Deserialization(source *common.ZeroCopySource) error {
+	refund, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("RegisterBondedParam deserialize refund error")
+	}
+	if err := this.Register.Deserialization(source); err != nil {
+		return fmt.Errorf("RegisterBondedParam deserialize register param error: %v", err)
+	}
+	this.Refund = refund
+	return nil
+}
+
+type BondBalanceParam struct {
+	Address common.Address
+}
+
+func (this *BondBalanceParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *BondBalanceParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BondBalanceParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("BondBalanceParam, common.AddressParseFromBytes error: %s", err)
+	}
+	this.Address = addr
+	return nil
+}
+
+func decodeAmount(source *common.ZeroCopySource) (*big.Int, error) {
+	raw, eof := source.NextVarBytes()
+	if eof {
+		return nil, fmt.Errorf("deserialize amount error")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -39,6 +39,25 @@ func TestRegisterSideChain(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.Equal(t, param, p)
+
+	param.Meta = &SideChainMeta{ExplorerURL: "https://etherscan.io/tx/%s", Decimals: 18}
+	sink = common.NewZeroCopySink(nil)
+	assert.NoError(t, param.Serialization(sink))
+	var p1 RegisterSideChainParam
+	assert.NoError(t, p1.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, param, p1)
+
+	// fields appended by later versions of the meta are skipped
+	frame := common.NewZeroCopySink(nil)
+	param.Meta.Serialization(frame)
+	frame.WriteString("appended")
+	sink = common.NewZeroCopySink(nil)
+	param.Meta = nil
+	assert.NoError(t, param.Serialization(sink))
+	sink.WriteVarBytes(frame.Bytes())
+	var p2 RegisterSideChainParam
+	assert.NoError(t, p2.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, &SideChainMeta{ExplorerURL: "https://etherscan.io/tx/%s", Decimals: 18}, p2.Meta)
 }
 
 func TestChainidParam(t *testing.T) {
@@ -55,3 +74,93 @@ func TestChainidParam(t *testing.T) {
 
 	assert.Equal(t, p, param)
 }
+
+func TestLockEventTopicParam(t *testing.T) {
+	p := LockEventTopicParam{
+		Address:      common.Address{1, 2, 3},
+		ChainId:      2,
+		LockContract: []byte{4, 5, 6},
+		Topic:        make([]byte, 32),
+		ParamIndex:   4,
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	p.Serialization(sink)
+
+	var param LockEventTopicParam
+	err := param.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+
+	assert.Equal(t, p, param)
+}
+
+func TestOpReturnLimitParam(t *testing.T) {
+	p := OpReturnLimitParam{
+		Address: common.Address{1, 2, 3},
+		ChainId: 1,
+		Limit:   160,
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	p.Serialization(sink)
+
+	var param OpReturnLimitParam
+	err := param.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+
+	assert.Equal(t, p, param)
+}
+
+func TestForkScheduleParam(t *testing.T) {
+	p := ForkScheduleParam{
+		Address: common.Address{1, 2, 3},
+		ChainId: 2,
+		Schedule: &ForkSchedule{Forks: []*Fork{
+			{Name: "london", Height: 12965000},
+			{Name: "shanghai", Height: 17034870},
+		}},
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	p.Serialization(sink)
+
+	var param ForkScheduleParam
+	err := param.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+
+	assert.Equal(t, p, param)
+}
+
+func TestMerkleHashParam(t *testing.T) {
+	p := MerkleHashParam{
+		Address: common.Address{1, 2, 3},
+		ChainId: 3,
+		Hash:    "keccak256",
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	p.Serialization(sink)
+
+	var param MerkleHashParam
+	err := param.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+
+	assert.Equal(t, p, param)
+}
+
+func TestLateProofParam(t *testing.T) {
+	p := LateProofParam{
+		Address: common.Address{1, 2, 3},
+		ChainId: 2,
+		Height:  1000,
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	p.Serialization(sink)
+
+	var param LateProofParam
+	err := param.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+
+	assert.Equal(t, p, param)
+}
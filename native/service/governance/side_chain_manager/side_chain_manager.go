@@ -24,9 +24,12 @@ import (
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcutil"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/event"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/precompile"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
@@ -40,6 +43,23 @@ const (
 	APPROVE_QUIT_SIDE_CHAIN     = "approveQuitSideChain"
 	REGISTER_REDEEM             = "registerRedeem"
 	SET_BTC_TX_PARAM            = "setBtcTxParam"
+	SET_LOCK_EVENT_TOPIC        = "setLockEventTopic"
+	SET_CONFIRMATION_TIERS      = "setConfirmationTiers"
+	SET_OP_RETURN_LIMIT         = "setOpReturnLimit"
+	SET_FORK_SCHEDULE           = "setForkSchedule"
+	SET_MAX_PROOF_AGE           = "setMaxProofAge"
+	APPROVE_LATE_PROOF          = "approveLateProof"
+	SET_MERKLE_HASH             = "setMerkleHash"
+	PRUNE_SIDE_CHAIN            = "pruneSideChain"
+	INIT_SIDE_CHAIN             = "initSideChain"
+	CREATE_INSTANCE             = "createInstance"
+	SET_INSTANCE_ADMIN          = "setInstanceAdmin"
+	INVOKE_INSTANCE             = "invokeInstance"
+	SET_BOND_CONFIG             = "setBondConfig"
+	REGISTER_BONDED_SIDE_CHAIN  = "registerBondedSideChain"
+	APPROVE_PROBATION           = "approveProbation"
+	SLASH_BOND                  = "slashBond"
+	GET_BOND_BALANCE            = "getBondBalance"
 
 	//key prefix
 	SIDE_CHAIN_APPLY          = "sideChainApply"
@@ -50,8 +70,37 @@ const (
 	BIND_SIGN_INFO            = "bindSignInfo"
 	BTC_TX_PARAM              = "btcTxParam"
 	REDEEM_SCRIPT             = "redeemScript"
+	LOCK_EVENT_TOPIC          = "lockEventTopic"
+	CONFIRMATION_TIERS        = "confirmationTiers"
+	OP_RETURN_LIMIT           = "opReturnLimit"
+	FORK_SCHEDULE             = "forkSchedule"
+	MAX_PROOF_AGE             = "maxProofAge"
+	LATE_PROOF                = "lateProof"
+	MERKLE_HASH               = "merkleHash"
+	SIDE_CHAIN_WIND_DOWN      = "sideChainWindDown"
+	INSTANCE                  = "instance"
+	BOND_CONFIG               = "bondConfig"
+	BOND_BALANCE              = "bondBalance"
+	BOND                      = "bond"
+	PROBATION_VOLUME          = "probationVolume"
+	CHAIN_ID_REGISTRY         = "chainIdRegistry"
+
+	//const
+	// blocks during which the transfers to a quitting chain already committed can still complete
+	WIND_DOWN_BLOCKS = 100000
+	// max bytes pushed by the OP_RETURN output of a btc deposit when no limit is set, the standard relay limit
+	DEFAULT_OP_RETURN_LIMIT = 80
+	// max bytes of the id of a bridge instance
+	MAX_INSTANCE_ID_LEN = 32
+	// version of the fields of SideChainMeta, fields are only appended by later versions
+	SIDE_CHAIN_META_VERSION = 1
 )
 
+// pruneSideChain is guarded by the operator, the operator committee calls it through node_manager
+func init() {
+	node_manager.RegisterOperatorMethod(utils.SideChainManagerContractAddress, PRUNE_SIDE_CHAIN)
+}
+
 //Register methods of node_manager contract
 func RegisterSideChainManagerContract(native *native.NativeService) {
 	native.Register(REGISTER_SIDE_CHAIN, RegisterSideChain)
@@ -63,6 +112,82 @@ func RegisterSideChainManagerContract(native *native.NativeService) {
 
 	native.Register(REGISTER_REDEEM, RegisterRedeem)
 	native.Register(SET_BTC_TX_PARAM, SetBtcTxParam)
+	native.Register(SET_LOCK_EVENT_TOPIC, SetLockEventTopic)
+	native.Register(SET_CONFIRMATION_TIERS, SetConfirmationTiers)
+	native.Register(SET_OP_RETURN_LIMIT, SetOpReturnLimit)
+	native.Register(SET_FORK_SCHEDULE, SetForkSchedule)
+	native.Register(SET_MAX_PROOF_AGE, SetMaxProofAge)
+	native.Register(APPROVE_LATE_PROOF, ApproveLateProof)
+	native.Register(SET_MERKLE_HASH, SetMerkleHash)
+	native.Register(PRUNE_SIDE_CHAIN, PruneSideChain)
+	native.Register(INIT_SIDE_CHAIN, InitSideChain)
+
+	native.Register(CREATE_INSTANCE, CreateInstance)
+	native.Register(SET_INSTANCE_ADMIN, SetInstanceAdmin)
+	native.Register(INVOKE_INSTANCE, InvokeInstance)
+
+	native.Register(SET_BOND_CONFIG, SetBondConfig)
+	native.Register(REGISTER_BONDED_SIDE_CHAIN, RegisterBondedSideChain)
+	native.Register(APPROVE_PROBATION, ApproveProbation)
+	native.Register(SLASH_BOND, SlashBond)
+	native.Register(GET_BOND_BALANCE, GetBondBalanceQuery)
+}
+
+// InitSideChain registers the side chains of the bootstrap genesis config owned by the genesis consensus
+// operator, it is only run by the genesis block
+func InitSideChain(native *native.NativeService) ([]byte, error) {
+	if !native.IsGenesis() {
+		return utils.BYTE_FALSE, fmt.Errorf("InitSideChain, only allowed in genesis block")
+	}
+	configuration := new(config.BootstrapConfig)
+	if err := configuration.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InitSideChain, contract params deserialize error: %v", err)
+	}
+	operatorAddress, err := node_manager.GetCurConOperator(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InitSideChain, get current consensus operator address error: %v", err)
+	}
+	for _, v := range configuration.SideChains {
+		if v.BlocksToWait == 0 {
+			return utils.BYTE_FALSE, fmt.Errorf("InitSideChain, minimal value of BlocksToWait of chain %d is 1", v.ChainId)
+		}
+		sideChain, err := GetSideChain(native, v.ChainId)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitSideChain, getSideChain error: %v", err)
+		}
+		if sideChain != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitSideChain, chainid %d already registered", v.ChainId)
+		}
+		if err := checkChainID(native, v.ChainId, v.Router, v.Name); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitSideChain, %v", err)
+		}
+		ccmcAddress, err := hex.DecodeString(v.CCMCAddress)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitSideChain, decode ccmc address of chain %d error: %v", v.ChainId, err)
+		}
+		extraInfo, err := hex.DecodeString(v.ExtraInfo)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitSideChain, decode extra info of chain %d error: %v", v.ChainId, err)
+		}
+		sideChain = &SideChain{
+			Address:      operatorAddress,
+			ChainId:      v.ChainId,
+			Router:       v.Router,
+			Name:         v.Name,
+			BlocksToWait: v.BlocksToWait,
+			CCMCAddress:  ccmcAddress,
+			ExtraInfo:    extraInfo,
+		}
+		if err := PutSideChain(native, sideChain); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitSideChain, putSideChain error: %v", err)
+		}
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.SideChainManagerContractAddress,
+				States:          []interface{}{"InitSideChain", v.ChainId, v.Router, v.Name, v.BlocksToWait},
+			})
+	}
+	return utils.BYTE_TRUE, nil
 }
 
 func RegisterSideChain(native *native.NativeService) ([]byte, error) {
@@ -91,6 +216,9 @@ func RegisterSideChain(native *native.NativeService) ([]byte, error) {
 	if sideChain != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("RegisterSideChain, chainid already registered")
 	}
+	if err := checkChainID(native, params.ChainId, params.Router, params.Name); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterSideChain, %v", err)
+	}
 	sideChain = &SideChain{
 		Address:      params.Address,
 		ChainId:      params.ChainId,
@@ -99,6 +227,7 @@ func RegisterSideChain(native *native.NativeService) ([]byte, error) {
 		BlocksToWait: params.BlocksToWait,
 		CCMCAddress:  params.CCMCAddress,
 		ExtraInfo:    params.ExtraInfo,
+		Meta:         params.Meta,
 	}
 	err = putSideChainApply(native, sideChain)
 	if err != nil {
@@ -177,6 +306,16 @@ func UpdateSideChain(native *native.NativeService) ([]byte, error) {
 	if sideChain.Address != params.Address {
 		return utils.BYTE_FALSE, fmt.Errorf("UpdateSideChain, side chain owner is wrong")
 	}
+	windDown, err := GetWindDown(native, sideChain.ChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateSideChain, %v", err)
+	}
+	if windDown != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateSideChain, side chain is quitting")
+	}
+	if err := utils.CheckChainID(params.ChainId, params.Router); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateSideChain, %v", err)
+	}
 	updateSideChain := &SideChain{
 		Address:      params.Address,
 		ChainId:      params.ChainId,
@@ -185,6 +324,7 @@ func UpdateSideChain(native *native.NativeService) ([]byte, error) {
 		BlocksToWait: params.BlocksToWait,
 		CCMCAddress:  params.CCMCAddress,
 		ExtraInfo:    params.ExtraInfo,
+		Meta:         params.Meta,
 	}
 	err = putUpdateSideChain(native, updateSideChain)
 	if err != nil {
@@ -264,6 +404,13 @@ func QuitSideChain(native *native.NativeService) ([]byte, error) {
 	if sideChain.Address != params.Address {
 		return utils.BYTE_FALSE, fmt.Errorf("QuitSideChain, side chain owner is wrong")
 	}
+	windDown, err := GetWindDown(native, sideChain.ChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("QuitSideChain, %v", err)
+	}
+	if windDown != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("QuitSideChain, side chain is quitting")
+	}
 
 	err = putQuitSideChain(native, params.Chainid)
 	if err != nil {
@@ -304,13 +451,65 @@ func ApproveQuitSideChain(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_TRUE, nil
 	}
 
+	// the side chain is kept until it's pruned, so that the transfers to it already committed can complete
 	chainidByte := utils.GetUint64Bytes(params.Chainid)
-	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(QUIT_SIDE_CHAIN), chainidByte))
-	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(SIDE_CHAIN), chainidByte))
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(QUIT_SIDE_CHAIN_REQUEST), chainidByte))
+	windDown := &WindDown{
+		QuitHeight: native.GetHeight(),
+		EndHeight:  native.GetHeight() + WIND_DOWN_BLOCKS,
+	}
+	putWindDown(native, params.Chainid, windDown)
 	native.AddNotify(
 		&event.NotifyEventInfo{
 			ContractAddress: utils.NodeManagerContractAddress,
-			States:          []interface{}{"ApproveQuitSideChain", params.Chainid},
+			States:          []interface{}{"ApproveQuitSideChain", params.Chainid, windDown.EndHeight},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// PruneSideChain removes the state of a quit side chain once its wind-down is over
+func PruneSideChain(native *native.NativeService) ([]byte, error) {
+	params := new(ChainidParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("PruneSideChain, contract params deserialize error: %v", err)
+	}
+
+	// Get current epoch operator
+	operatorAddress, err := node_manager.GetCurConOperator(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("PruneSideChain, get current consensus operator address error: %v", err)
+	}
+	//check witness
+	err = utils.ValidateOwner(native, operatorAddress)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("PruneSideChain, checkWitness error: %v", err)
+	}
+
+	windDown, err := GetWindDown(native, params.Chainid)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("PruneSideChain, %v", err)
+	}
+	if windDown == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("PruneSideChain, side chain %d is not quitting", params.Chainid)
+	}
+	if native.GetHeight() <= windDown.EndHeight {
+		return utils.BYTE_FALSE, fmt.Errorf("PruneSideChain, side chain %d winds down until height %d",
+			params.Chainid, windDown.EndHeight)
+	}
+
+	chainidByte := utils.GetUint64Bytes(params.Chainid)
+	for _, prefix := range []string{SIDE_CHAIN, CONFIRMATION_TIERS, OP_RETURN_LIMIT, SIDE_CHAIN_WIND_DOWN} {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(prefix), chainidByte))
+	}
+	// the synced headers are only reachable through these records
+	for _, prefix := range []string{hscommon.GENESIS_HEADER, hscommon.CURRENT_HEADER_HEIGHT, hscommon.CURRENT_MSG_HEIGHT,
+		hscommon.CONSENSUS_PEER, hscommon.CONSENSUS_PEER_BLOCK_HEIGHT, hscommon.KEY_HEIGHTS, hscommon.EPOCH_SWITCH, hscommon.EPOCH_HEIGHTS} {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(prefix), chainidByte))
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States:          []interface{}{"PruneSideChain", params.Chainid},
 		})
 	return utils.BYTE_TRUE, nil
 }
@@ -327,6 +526,9 @@ func RegisterRedeem(native *native.NativeService) ([]byte, error) {
 	if ty != txscript.MultiSigTy {
 		return utils.BYTE_FALSE, fmt.Errorf("RegisterRedeem, wrong type of redeem: %s", ty.String())
 	}
+	if err := checkRedeemThreshold(m, len(addrs)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterRedeem, %v", err)
+	}
 	rk := btcutil.Hash160(params.Redeem)
 	contract, err := GetContractBind(native, params.RedeemChainID, params.ContractChainID, rk)
 	if err != nil {
@@ -431,3 +633,356 @@ func SetBtcTxParam(native *native.NativeService) ([]byte, error) {
 	}
 	return utils.BYTE_TRUE, nil
 }
+
+// SetLockEventTopic configures the event which a lock contract of an ethereum-like side chain
+// emits for cross chain requests, an empty topic removes the configuration.
+func SetLockEventTopic(native *native.NativeService) ([]byte, error) {
+	params := new(LockEventTopicParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetLockEventTopic, contract params deserialize error: %v", err)
+	}
+	if len(params.Topic) != 0 && len(params.Topic) != 32 {
+		return utils.BYTE_FALSE, fmt.Errorf("SetLockEventTopic, topic length should be 32 but got %d", len(params.Topic))
+	}
+	if len(params.LockContract) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("SetLockEventTopic, lock contract is empty")
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetLockEventTopic, checkWitness error: %v", err)
+	}
+
+	sideChain, err := GetSideChain(native, params.ChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetLockEventTopic, GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetLockEventTopic, side chain %d is not registered", params.ChainId)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_LOCK_EVENT_TOPIC, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetLockEventTopic, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if len(params.Topic) == 0 {
+		deleteLockEventTopic(native, params.ChainId, params.LockContract)
+	} else {
+		putLockEventTopic(native, params.ChainId, params.LockContract, &LockEventTopic{
+			Topic:      params.Topic,
+			ParamIndex: params.ParamIndex,
+		})
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States: []interface{}{"SetLockEventTopic", params.ChainId, hex.EncodeToString(params.LockContract),
+				hex.EncodeToString(params.Topic), params.ParamIndex},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// SetConfirmationTiers configures the blocks to wait by transfer amount for a side chain, the tiers
+// should be sorted by amount in ascending order and an empty list removes the configuration.
+func SetConfirmationTiers(native *native.NativeService) ([]byte, error) {
+	params := new(ConfirmationTiersParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetConfirmationTiers, contract params deserialize error: %v", err)
+	}
+	for i, v := range params.Tiers.Tiers {
+		if i > 0 && v.Amount.Cmp(params.Tiers.Tiers[i-1].Amount) <= 0 {
+			return utils.BYTE_FALSE, fmt.Errorf("SetConfirmationTiers, amount of No.%d tier is not ascending", i)
+		}
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetConfirmationTiers, checkWitness error: %v", err)
+	}
+
+	sideChain, err := GetSideChain(native, params.ChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetConfirmationTiers, GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetConfirmationTiers, side chain %d is not registered", params.ChainId)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_CONFIRMATION_TIERS, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetConfirmationTiers, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if len(params.Tiers.Tiers) == 0 {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(CONFIRMATION_TIERS),
+			utils.GetUint64Bytes(params.ChainId)))
+	} else {
+		putConfirmationTiers(native, params.ChainId, params.Tiers)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States:          []interface{}{"SetConfirmationTiers", params.ChainId, len(params.Tiers.Tiers)},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// SetForkSchedule replaces the fork schedule of a side chain consulted by its header validators, approved
+// by the consensus nodes, an empty schedule removes it
+func SetForkSchedule(native *native.NativeService) ([]byte, error) {
+	params := new(ForkScheduleParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetForkSchedule, contract params deserialize error: %v", err)
+	}
+	names := make(map[string]bool)
+	for i, v := range params.Schedule.Forks {
+		if v.Name == "" || names[v.Name] {
+			return utils.BYTE_FALSE, fmt.Errorf("SetForkSchedule, name of No.%d fork is empty or duplicated", i)
+		}
+		names[v.Name] = true
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetForkSchedule, checkWitness error: %v", err)
+	}
+
+	sideChain, err := GetSideChain(native, params.ChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetForkSchedule, GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetForkSchedule, side chain %d is not registered", params.ChainId)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_FORK_SCHEDULE, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetForkSchedule, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if len(params.Schedule.Forks) == 0 {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(FORK_SCHEDULE),
+			utils.GetUint64Bytes(params.ChainId)))
+	} else {
+		putForkSchedule(native, params.ChainId, params.Schedule)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States:          []interface{}{"SetForkSchedule", params.ChainId, len(params.Schedule.Forks)},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// SetMaxProofAge sets the max blocks the source height of a proof from the side chain can be below its
+// latest synced height, approved by the consensus nodes, 0 removes the limit. Only the chains of the
+// routers checking the proof age can have the limit.
+func SetMaxProofAge(native *native.NativeService) ([]byte, error) {
+	params := new(MaxProofAgeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMaxProofAge, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMaxProofAge, checkWitness error: %v", err)
+	}
+
+	sideChain, err := GetSideChain(native, params.ChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMaxProofAge, GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMaxProofAge, side chain %d is not registered", params.ChainId)
+	}
+	if params.MaxAge != 0 && !proofAgeRouters[sideChain.Router] {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMaxProofAge, router %d of side chain %d doesn't check the proof age",
+			sideChain.Router, params.ChainId)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_MAX_PROOF_AGE, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMaxProofAge, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if params.MaxAge == 0 {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(MAX_PROOF_AGE),
+			utils.GetUint64Bytes(params.ChainId)))
+	} else {
+		putMaxProofAge(native, params.ChainId, params.MaxAge)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States:          []interface{}{"SetMaxProofAge", params.ChainId, params.MaxAge},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// ApproveLateProof lets the legitimate late claims with proofs at a height of the side chain through the
+// max proof age, approved by the consensus nodes
+func ApproveLateProof(native *native.NativeService) ([]byte, error) {
+	params := new(LateProofParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveLateProof, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveLateProof, checkWitness error: %v", err)
+	}
+
+	sideChain, err := GetSideChain(native, params.ChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveLateProof, GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveLateProof, side chain %d is not registered", params.ChainId)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, APPROVE_LATE_PROOF, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveLateProof, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	putLateProof(native, params.ChainId, params.Height)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States:          []interface{}{"ApproveLateProof", params.ChainId, params.Height},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// SetMerkleHash sets the hash primitive the merkle proofs from the side chain are verified with,
+// approved by the consensus nodes, an empty name restores the default sha256
+func SetMerkleHash(native *native.NativeService) ([]byte, error) {
+	params := new(MerkleHashParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMerkleHash, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMerkleHash, checkWitness error: %v", err)
+	}
+
+	if params.Hash != "" && !precompile.IsHash(params.Hash) {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMerkleHash, hash %s is not registered", params.Hash)
+	}
+	sideChain, err := GetSideChain(native, params.ChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMerkleHash, GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMerkleHash, side chain %d is not registered", params.ChainId)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_MERKLE_HASH, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMerkleHash, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if params.Hash == "" {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(MERKLE_HASH),
+			utils.GetUint64Bytes(params.ChainId)))
+	} else {
+		putMerkleHash(native, params.ChainId, params.Hash)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States:          []interface{}{"SetMerkleHash", params.ChainId, params.Hash},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// SetOpReturnLimit sets the max payload pushed by the OP_RETURN output of a deposit from a btc side chain,
+// zero restores the default limit
+func SetOpReturnLimit(native *native.NativeService) ([]byte, error) {
+	params := new(OpReturnLimitParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetOpReturnLimit, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetOpReturnLimit, checkWitness error: %v", err)
+	}
+
+	sideChain, err := GetSideChain(native, params.ChainId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetOpReturnLimit, GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetOpReturnLimit, side chain %d is not registered", params.ChainId)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_OP_RETURN_LIMIT, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetOpReturnLimit, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if params.Limit == 0 {
+		native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(OP_RETURN_LIMIT),
+			utils.GetUint64Bytes(params.ChainId)))
+	} else {
+		putOpReturnLimit(native, params.ChainId, params.Limit)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.SideChainManagerContractAddress,
+			States:          []interface{}{"SetOpReturnLimit", params.ChainId, params.Limit},
+		})
+	return utils.BYTE_TRUE, nil
+}
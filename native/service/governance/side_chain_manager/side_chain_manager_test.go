@@ -27,6 +27,7 @@ import (
 	"github.com/polynetwork/poly/core/store/overlaydb"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/precompile"
 	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/polynetwork/poly/native/storage"
 	"github.com/stretchr/testify/assert"
@@ -207,6 +208,15 @@ func TestRegisterRedeem(t *testing.T) {
 	assert.Equal(t, utils.BYTE_FALSE, ok)
 }
 
+func TestCheckRedeemThreshold(t *testing.T) {
+	assert.Nil(t, checkRedeemThreshold(5, 7))
+	assert.Nil(t, checkRedeemThreshold(1, 1))
+	assert.Nil(t, checkRedeemThreshold(3, 4))
+	assert.NotNil(t, checkRedeemThreshold(2, 4))
+	assert.NotNil(t, checkRedeemThreshold(8, 7))
+	assert.NotNil(t, checkRedeemThreshold(0, 3))
+}
+
 func TestSetBtcTxParam(t *testing.T) {
 	redeem, _ := hex.DecodeString("552102dec9a415b6384ec0a9331d0cdf02020f0f1e5731c327b86e2b5a92455a289748210365b1066bcfa21987c3e207b92e309b95ca6bee5f1133cf04d6ed4ed265eafdbc21031104e387cd1a103c27fdc8a52d5c68dec25ddfb2f574fbdca405edfd8c5187de21031fdb4b44a9f20883aff505009ebc18702774c105cb04b1eecebcb294d404b1cb210387cda955196cc2b2fc0adbbbac1776f8de77b563c6d2a06a77d96457dc3d0d1f2102dd7767b6a7cc83693343ba721e0f5f4c7b4b8d85eeb7aec20d227625ec0f59d321034ad129efdab75061e8d4def08f5911495af2dae6d3e9a4b6e7aeb5186fa432fc57ae")
 	sigStr := strings.Split("3045022100dbd452e851efbe8ae56c9a7da38d8ba59bf9fa5baefd439383271dba8998d4a00220227313c17e1438c5f679f10d520a5b7a1e56cbf6f0e6a824537a963ffb4d27f8,3045022100f22368985fbb00e3649b6d36e85b849c91dd57d3fc762fd63bbb7cdd478e3aeb022029aee48ed40473dcb9d1c634fe93b182b3ea6df400f09c00040339dabe67fd30,30450221009d3f736b27c991f78b84856c70e30813448d33284d04bb4dd0560b3bd250a15a02201e722d0087d537b2609e9140b398ce992b89ed583f2beb793ac78ded9bd0a207,3044022018f6cc301029843332794745b020dcb3fc768198c04026eb2efcbf0ba7febc0d02203c463b1b96eb6b7dcfff61bb6ccb87bffd6388d43ed464a9537e09b9c58c7e88,30440220301e5e1c37e699d8a0f999796b481a0611c11da7ea16389a760dccf5a8b659e8022072b4e0d48ef80db976bbd8f249a9b2aa0fb7fc995363a3ee9c629401cffe05b7", ",")
@@ -242,3 +252,89 @@ func TestSetBtcTxParam(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, utils.BYTE_FALSE, ok)
 }
+
+func TestGetSideChains(t *testing.T) {
+	ns := NewNative(nil, new(types.Transaction), nil)
+	for _, chainID := range []uint64{2, 6} {
+		assert.Nil(t, PutSideChain(ns, &SideChain{ChainId: chainID, Name: "chain", BlocksToWait: 1}))
+	}
+	// applications and wind downs share the prefix of side chains
+	assert.Nil(t, putSideChainApply(ns, &SideChain{ChainId: 7, Name: "apply", BlocksToWait: 1}))
+	putWindDown(ns, 2, &WindDown{})
+
+	sideChains, err := GetSideChains(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(sideChains))
+	assert.Equal(t, uint64(2), sideChains[0].ChainId)
+	assert.Equal(t, uint64(6), sideChains[1].ChainId)
+}
+
+func TestCheckChainID(t *testing.T) {
+	ns := NewNative(nil, new(types.Transaction), nil)
+	assert.NotNil(t, checkChainID(ns, ns.GetChainID(), utils.ETH_ROUTER, "poly"))
+	assert.NotNil(t, checkChainID(ns, utils.ETH_CHAIN_ID, utils.BSC_ROUTER, "fake ethereum"))
+	assert.Nil(t, checkChainID(ns, 100, utils.BSC_ROUTER, "devnet"))
+
+	assert.Nil(t, PutSideChain(ns, &SideChain{ChainId: 100, Router: utils.BSC_ROUTER, Name: "devnet", BlocksToWait: 1}))
+	// the id stays taken after the chain is removed, only the same chain can register it again
+	ns.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(SIDE_CHAIN),
+		utils.GetUint64Bytes(100)))
+	assert.Nil(t, checkChainID(ns, 100, utils.BSC_ROUTER, "devnet"))
+	assert.NotNil(t, checkChainID(ns, 100, utils.BSC_ROUTER, "other"))
+	assert.NotNil(t, checkChainID(ns, 100, utils.HECO_ROUTER, "devnet"))
+
+	registry, err := GetChainIDRegistry(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(registry.Records))
+}
+
+func TestGetForkHeight(t *testing.T) {
+	ns := NewNative(nil, new(types.Transaction), nil)
+	_, ok, err := GetForkHeight(ns, 2, "london")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	putForkSchedule(ns, 2, &ForkSchedule{Forks: []*Fork{{Name: "london", Height: 100}}})
+	height, ok, err := GetForkHeight(ns, 2, "london")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(100), height)
+	_, ok, err = GetForkHeight(ns, 2, "shanghai")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	_, ok, err = GetForkHeight(ns, 3, "london")
+	assert.Nil(t, err)
+	assert.False(t, ok, "schedule is per chain")
+}
+
+func TestCheckProofAge(t *testing.T) {
+	ns := NewNative(nil, new(types.Transaction), nil)
+	assert.Nil(t, CheckProofAge(ns, 2, 1, 1000), "no limit by default")
+
+	putMaxProofAge(ns, 2, 100)
+	assert.Nil(t, CheckProofAge(ns, 2, 900, 1000))
+	assert.Error(t, CheckProofAge(ns, 2, 899, 1000))
+	assert.Nil(t, CheckProofAge(ns, 3, 899, 1000), "limit is per chain")
+
+	putLateProof(ns, 2, 899)
+	assert.Nil(t, CheckProofAge(ns, 2, 899, 1000), "late proofs approved")
+	assert.Error(t, CheckProofAge(ns, 2, 898, 1000))
+
+	assert.True(t, proofAgeRouters[utils.ETH_ROUTER])
+	assert.False(t, proofAgeRouters[utils.COSMOS_ROUTER], "cosmos doesn't check the proof age")
+}
+
+func TestGetMerkleHash(t *testing.T) {
+	ns := NewNative(nil, new(types.Transaction), nil)
+	hash, err := GetMerkleHash(ns, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, precompile.SHA256, hash, "sha256 by default")
+
+	putMerkleHash(ns, 3, precompile.KECCAK256)
+	hash, err = GetMerkleHash(ns, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, precompile.KECCAK256, hash)
+	hash, err = GetMerkleHash(ns, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, precompile.SHA256, hash, "hash is per chain")
+}
@@ -19,6 +19,7 @@ package side_chain_manager
 
 import (
 	"fmt"
+	"math/big"
 	"sort"
 
 	"github.com/polynetwork/poly/common"
@@ -34,6 +35,7 @@ type SideChain struct {
 	BlocksToWait uint64
 	CCMCAddress  []byte
 	ExtraInfo    []byte
+	Meta         *SideChainMeta // nil for the chains registered without it
 }
 
 func (this *SideChain) Serialization(sink *common.ZeroCopySink) error {
@@ -46,6 +48,7 @@ func (this *SideChain) Serialization(sink *common.ZeroCopySink) error {
 	height := config.GetExtraInfoHeight(config.DefConfig.P2PNode.NetworkId)
 	if !config.EXTRA_INFO_HEIGHT_FORK_CHECK || ledger.DefLedger.GetCurrentBlockHeight() >= height {
 		sink.WriteVarBytes(this.ExtraInfo)
+		writeSideChainMeta(sink, this.Meta)
 	}
 	return nil
 }
@@ -80,6 +83,10 @@ func (this *SideChain) Deserialization(source *common.ZeroCopySource) error {
 		return fmt.Errorf("source.NextVarBytes, deserialize CCMCAddress error")
 	}
 	ExtraInfo, _ := source.NextVarBytes()
+	meta, err := readSideChainMeta(source)
+	if err != nil {
+		return err
+	}
 
 	this.Address = addr
 	this.ChainId = chainId
@@ -88,9 +95,73 @@ func (this *SideChain) Deserialization(source *common.ZeroCopySource) error {
 	this.BlocksToWait = blocksToWait
 	this.CCMCAddress = CCMCAddress
 	this.ExtraInfo = ExtraInfo
+	this.Meta = meta
+	return nil
+}
+
+// SideChainMeta describes a side chain for front-ends along with the name and the CCM contract address of
+// it. ExplorerURL is the url template of a tx on the block explorer of the chain with %s in place of the tx
+// hash, Decimals are the ones of the native asset of the chain.
+type SideChainMeta struct {
+	ExplorerURL string
+	Decimals    uint8
+}
+
+func (this *SideChainMeta) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint8(SIDE_CHAIN_META_VERSION)
+	sink.WriteString(this.ExplorerURL)
+	sink.WriteUint8(this.Decimals)
+}
+
+// Deserialization reads the fields known by SIDE_CHAIN_META_VERSION, the ones appended by later versions
+// are skipped
+func (this *SideChainMeta) Deserialization(source *common.ZeroCopySource) error {
+	version, eof := source.NextUint8()
+	if eof {
+		return fmt.Errorf("source.NextUint8, deserialize meta version error")
+	}
+	if version == 0 {
+		return fmt.Errorf("SideChainMeta, invalid meta version %d", version)
+	}
+	explorerURL, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("source.NextString, deserialize explorer url error")
+	}
+	decimals, eof := source.NextUint8()
+	if eof {
+		return fmt.Errorf("source.NextUint8, deserialize decimals error")
+	}
+	this.ExplorerURL = explorerURL
+	this.Decimals = decimals
 	return nil
 }
 
+// writeSideChainMeta frames meta after the extra info, nothing is written without meta so that the chains
+// registered without it are serialized as before
+func writeSideChainMeta(sink *common.ZeroCopySink, meta *SideChainMeta) {
+	if meta == nil {
+		return
+	}
+	frame := common.NewZeroCopySink(nil)
+	meta.Serialization(frame)
+	sink.WriteVarBytes(frame.Bytes())
+}
+
+func readSideChainMeta(source *common.ZeroCopySource) (*SideChainMeta, error) {
+	if source.Len() == 0 {
+		return nil, nil
+	}
+	frame, eof := source.NextVarBytes()
+	if eof {
+		return nil, fmt.Errorf("source.NextVarBytes, deserialize meta error")
+	}
+	meta := new(SideChainMeta)
+	if err := meta.Deserialization(common.NewZeroCopySource(frame)); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
 type BindSignInfo struct {
 	BindSignInfo map[string][]byte
 }
@@ -153,3 +224,267 @@ func (this *ContractBinded) Deserialization(source *common.ZeroCopySource) error
 	}
 	return nil
 }
+
+// LockEventTopic tells which event of a lock contract carries the cross chain request,
+// ParamIndex is the position of the bytes argument holding the serialized MakeTxParam
+// among the non-indexed arguments of the event.
+type LockEventTopic struct {
+	Topic      []byte
+	ParamIndex uint64
+}
+
+func (this *LockEventTopic) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Topic)
+	sink.WriteVarUint(this.ParamIndex)
+}
+
+func (this *LockEventTopic) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Topic, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("LockEventTopic deserialize topic error")
+	}
+	this.ParamIndex, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("LockEventTopic deserialize param index error")
+	}
+	return nil
+}
+
+// ConfirmationTier raises the blocks to wait of the transfers not less than Amount
+type ConfirmationTier struct {
+	Amount       *big.Int
+	BlocksToWait uint64
+}
+
+type ConfirmationTiers struct {
+	Tiers []*ConfirmationTier
+}
+
+func (this *ConfirmationTiers) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Tiers)))
+	for _, v := range this.Tiers {
+		sink.WriteVarBytes(v.Amount.Bytes())
+		sink.WriteVarUint(v.BlocksToWait)
+	}
+}
+
+func (this *ConfirmationTiers) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ConfirmationTiers deserialize length error")
+	}
+	tiers := make([]*ConfirmationTier, 0)
+	for i := uint64(0); i < n; i++ {
+		amount, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("ConfirmationTiers deserialize amount of No.%d tier error", i)
+		}
+		blocksToWait, eof := source.NextVarUint()
+		if eof {
+			return fmt.Errorf("ConfirmationTiers deserialize blocks to wait of No.%d tier error", i)
+		}
+		tiers = append(tiers, &ConfirmationTier{Amount: new(big.Int).SetBytes(amount), BlocksToWait: blocksToWait})
+	}
+	this.Tiers = tiers
+	return nil
+}
+
+// Fork is a rule switch of a side chain activated from Height, e.g. london of ethereum
+type Fork struct {
+	Name   string
+	Height uint64
+}
+
+// ForkSchedule tells the heights the header validators switch their rules of a side chain at
+type ForkSchedule struct {
+	Forks []*Fork
+}
+
+func (this *ForkSchedule) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Forks)))
+	for _, v := range this.Forks {
+		sink.WriteString(v.Name)
+		sink.WriteVarUint(v.Height)
+	}
+}
+
+func (this *ForkSchedule) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ForkSchedule deserialize length error")
+	}
+	forks := make([]*Fork, 0)
+	for i := uint64(0); i < n; i++ {
+		name, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("ForkSchedule deserialize name of No.%d fork error", i)
+		}
+		height, eof := source.NextVarUint()
+		if eof {
+			return fmt.Errorf("ForkSchedule deserialize height of No.%d fork error", i)
+		}
+		forks = append(forks, &Fork{Name: name, Height: height})
+	}
+	this.Forks = forks
+	return nil
+}
+
+// ChainIDRecord is the name and router a chain id is registered with on poly
+type ChainIDRecord struct {
+	ChainId uint64
+	Name    string
+	Router  uint64
+}
+
+// ChainIDRegistry keeps every chain id ever registered, a chain removed from poly stays in it so that its
+// id can't be reused by another chain
+type ChainIDRegistry struct {
+	Records []*ChainIDRecord
+}
+
+func (this *ChainIDRegistry) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Records)))
+	for _, v := range this.Records {
+		sink.WriteVarUint(v.ChainId)
+		sink.WriteString(v.Name)
+		sink.WriteVarUint(v.Router)
+	}
+}
+
+func (this *ChainIDRegistry) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ChainIDRegistry deserialize length error")
+	}
+	records := make([]*ChainIDRecord, 0)
+	for i := uint64(0); i < n; i++ {
+		chainID, eof := source.NextVarUint()
+		if eof {
+			return fmt.Errorf("ChainIDRegistry deserialize chain id of No.%d record error", i)
+		}
+		name, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("ChainIDRegistry deserialize name of No.%d record error", i)
+		}
+		router, eof := source.NextVarUint()
+		if eof {
+			return fmt.Errorf("ChainIDRegistry deserialize router of No.%d record error", i)
+		}
+		records = append(records, &ChainIDRecord{ChainId: chainID, Name: name, Router: router})
+	}
+	this.Records = records
+	return nil
+}
+
+// WindDown is set when a side chain quits, no transfer from or to it is accepted any more
+// while the ones already committed can complete until EndHeight.
+type WindDown struct {
+	QuitHeight uint32
+	EndHeight  uint32
+}
+
+func (this *WindDown) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.QuitHeight)
+	sink.WriteUint32(this.EndHeight)
+}
+
+func (this *WindDown) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.QuitHeight, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("WindDown deserialize quit height error")
+	}
+	this.EndHeight, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("WindDown deserialize end height error")
+	}
+	return nil
+}
+
+// BondConfig tells the bond a side chain registered without governance approval is backed by, it's
+// locked by lock proxy on ChainID to poly, and the volume of each asset transferred from or to the chain
+// in probation is capped by ProbationCap
+type BondConfig struct {
+	ChainID      uint64
+	Asset        []byte
+	Amount       *big.Int
+	ProbationCap *big.Int
+}
+
+func (this *BondConfig) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarBytes(this.Asset)
+	sink.WriteVarBytes(this.Amount.Bytes())
+	sink.WriteVarBytes(this.ProbationCap.Bytes())
+}
+
+func (this *BondConfig) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.ChainID, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("BondConfig deserialize chain id error")
+	}
+	this.Asset, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BondConfig deserialize asset error")
+	}
+	var err error
+	if this.Amount, err = decodeAmount(source); err != nil {
+		return fmt.Errorf("BondConfig %v", err)
+	}
+	if this.ProbationCap, err = decodeAmount(source); err != nil {
+		return fmt.Errorf("BondConfig deserialize probation cap: %v", err)
+	}
+	return nil
+}
+
+// Bond is the bond a side chain in probation is backed by, Amount of Asset is locked on ChainID
+type Bond struct {
+	Owner   common.Address
+	Refund  []byte
+	ChainID uint64
+	Asset   []byte
+	Amount  *big.Int
+}
+
+func (this *Bond) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Owner[:])
+	sink.WriteVarBytes(this.Refund)
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarBytes(this.Asset)
+	sink.WriteVarBytes(this.Amount.Bytes())
+}
+
+func (this *Bond) Deserialization(source *common.ZeroCopySource) error {
+	owner, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("Bond deserialize owner error")
+	}
+	addr, err := common.AddressParseFromBytes(owner)
+	if err != nil {
+		return fmt.Errorf("Bond, common.AddressParseFromBytes error: %s", err)
+	}
+	refund, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("Bond deserialize refund error")
+	}
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("Bond deserialize chain id error")
+	}
+	asset, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("Bond deserialize asset error")
+	}
+	amount, err := decodeAmount(source)
+	if err != nil {
+		return fmt.Errorf("Bond %v", err)
+	}
+	this.Owner = addr
+	this.Refund = refund
+	this.ChainID = chainID
+	this.Asset = asset
+	this.Amount = amount
+	return nil
+}
@@ -18,7 +18,10 @@
 package side_chain_manager
 
 import (
+	"math/big"
+
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -38,3 +41,46 @@ func TestSideChain_Serialization(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, paramDeserialize, paramSerialize)
 }
+
+func TestConfirmationTiers(t *testing.T) {
+	tiers := &ConfirmationTiers{
+		Tiers: []*ConfirmationTier{
+			{Amount: big.NewInt(100000), BlocksToWait: 6},
+			{Amount: new(big.Int).Lsh(big.NewInt(1), 100), BlocksToWait: 30},
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	tiers.Serialization(sink)
+
+	got := new(ConfirmationTiers)
+	err := got.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, tiers, got)
+}
+
+func TestWindDown(t *testing.T) {
+	windDown := &WindDown{QuitHeight: 100, EndHeight: 100 + WIND_DOWN_BLOCKS}
+	sink := common.NewZeroCopySink(nil)
+	windDown.Serialization(sink)
+
+	got := new(WindDown)
+	err := got.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, windDown, got)
+}
+
+func TestChainIDRegistry(t *testing.T) {
+	registry := &ChainIDRegistry{
+		Records: []*ChainIDRecord{
+			{ChainId: 2, Name: "ethereum", Router: utils.ETH_ROUTER},
+			{ChainId: 100, Name: "devnet", Router: utils.BSC_ROUTER},
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	registry.Serialization(sink)
+
+	got := new(ChainIDRegistry)
+	err := got.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, registry, got)
+}
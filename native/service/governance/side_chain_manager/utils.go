@@ -19,6 +19,7 @@ package side_chain_manager
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
@@ -27,6 +28,7 @@ import (
 	"github.com/polynetwork/poly/common"
 	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/precompile"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
@@ -95,6 +97,30 @@ func GetSideChain(native *native.NativeService, chainID uint64) (*SideChain, err
 
 }
 
+// GetSideChains returns all registered side chains in the order of the storage keys
+func GetSideChains(native *native.NativeService) ([]*SideChain, error) {
+	prefix := utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(SIDE_CHAIN))
+	sideChains := make([]*SideChain, 0)
+	iter := native.GetCacheDB().NewIterator(prefix)
+	defer iter.Release()
+	for has := iter.First(); has; has = iter.Next() {
+		// other prefixes starting with SIDE_CHAIN, such as SIDE_CHAIN_APPLY, have longer keys
+		if len(iter.Key()) != len(prefix)+8 {
+			continue
+		}
+		sideChainBytes, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("GetSideChains, deserialize from raw storage item err:%v", err)
+		}
+		sideChain := new(SideChain)
+		if err := sideChain.Deserialization(common.NewZeroCopySource(sideChainBytes)); err != nil {
+			return nil, fmt.Errorf("GetSideChains, deserialize sideChain error: %v", err)
+		}
+		sideChains = append(sideChains, sideChain)
+	}
+	return sideChains, nil
+}
+
 func PutSideChain(native *native.NativeService, sideChain *SideChain) error {
 	contract := utils.SideChainManagerContractAddress
 	chainidByte := utils.GetUint64Bytes(sideChain.ChainId)
@@ -107,6 +133,9 @@ func PutSideChain(native *native.NativeService, sideChain *SideChain) error {
 
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(SIDE_CHAIN), chainidByte),
 		cstates.GenRawStorageItem(sink.Bytes()))
+	if err := putChainIDRecord(native, sideChain); err != nil {
+		return fmt.Errorf("putSideChain, %v", err)
+	}
 	return nil
 }
 
@@ -279,6 +308,19 @@ func GetBtcTxParam(native *native.NativeService, redeemKey []byte, redeemChainId
 	return nil, nil
 }
 
+// checkRedeemThreshold checks the m of the m-of-n redeem script, the signatures required to spend
+// the utxos should be a majority of the keys so that a minority can't move the funds. The threshold
+// is changed by registering the redeem script of the new one.
+func checkRedeemThreshold(m, n int) error {
+	if m < 1 || m > n {
+		return fmt.Errorf("threshold %d of the redeem is out of the %d keys", m, n)
+	}
+	if 2*m <= n {
+		return fmt.Errorf("threshold %d of the redeem is not a majority of the %d keys", m, n)
+	}
+	return nil
+}
+
 func verifyRedeemRegister(param *RegisterRedeemParam, addrs []btcutil.Address) (map[string][]byte, error) {
 	r := make([]byte, len(param.Redeem))
 	copy(r, param.Redeem)
@@ -351,3 +393,332 @@ func GetBtcRedeemScriptBytes(native *native.NativeService, redeemScriptKey strin
 	}
 	return redeemBytes, nil
 }
+
+func putLockEventTopic(native *native.NativeService, chainID uint64, lockContract []byte, topic *LockEventTopic) {
+	sink := common.NewZeroCopySink(nil)
+	topic.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(LOCK_EVENT_TOPIC),
+		utils.GetUint64Bytes(chainID), lockContract), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func deleteLockEventTopic(native *native.NativeService, chainID uint64, lockContract []byte) {
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(LOCK_EVENT_TOPIC),
+		utils.GetUint64Bytes(chainID), lockContract))
+}
+
+// GetLockEventTopic returns nil if no event topic is configured for the lock contract
+func GetLockEventTopic(native *native.NativeService, chainID uint64, lockContract []byte) (*LockEventTopic, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(LOCK_EVENT_TOPIC),
+		utils.GetUint64Bytes(chainID), lockContract))
+	if err != nil {
+		return nil, fmt.Errorf("GetLockEventTopic, get lock event topic error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	topicBytes, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetLockEventTopic, deserialize from raw storage item error: %v", err)
+	}
+	topic := new(LockEventTopic)
+	if err := topic.Deserialization(common.NewZeroCopySource(topicBytes)); err != nil {
+		return nil, fmt.Errorf("GetLockEventTopic, deserialize LockEventTopic error: %v", err)
+	}
+	return topic, nil
+}
+
+func putConfirmationTiers(native *native.NativeService, chainID uint64, tiers *ConfirmationTiers) {
+	sink := common.NewZeroCopySink(nil)
+	tiers.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(CONFIRMATION_TIERS),
+		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func GetConfirmationTiers(native *native.NativeService, chainID uint64) (*ConfirmationTiers, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(CONFIRMATION_TIERS),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("GetConfirmationTiers, get confirmation tiers error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetConfirmationTiers, deserialize from raw storage item error: %v", err)
+	}
+	tiers := new(ConfirmationTiers)
+	if err := tiers.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetConfirmationTiers, deserialize ConfirmationTiers error: %v", err)
+	}
+	return tiers, nil
+}
+
+func putForkSchedule(native *native.NativeService, chainID uint64, schedule *ForkSchedule) {
+	sink := common.NewZeroCopySink(nil)
+	schedule.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(FORK_SCHEDULE),
+		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func GetForkSchedule(native *native.NativeService, chainID uint64) (*ForkSchedule, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(FORK_SCHEDULE),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("GetForkSchedule, get fork schedule error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetForkSchedule, deserialize from raw storage item error: %v", err)
+	}
+	schedule := new(ForkSchedule)
+	if err := schedule.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetForkSchedule, deserialize ForkSchedule error: %v", err)
+	}
+	return schedule, nil
+}
+
+// GetForkHeight returns the activation height of the fork name of the side chain, false if it's not scheduled
+func GetForkHeight(native *native.NativeService, chainID uint64, name string) (uint64, bool, error) {
+	schedule, err := GetForkSchedule(native, chainID)
+	if err != nil {
+		return 0, false, err
+	}
+	if schedule == nil {
+		return 0, false, nil
+	}
+	for _, v := range schedule.Forks {
+		if v.Name == name {
+			return v.Height, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// GetRequiredConfirmations returns the blocks to wait for a transfer of amount from the side chain,
+// a nil amount means the amount is unknown and the highest tier applies.
+func GetRequiredConfirmations(native *native.NativeService, sideChain *SideChain, amount *big.Int) (uint64, error) {
+	tiers, err := GetConfirmationTiers(native, sideChain.ChainId)
+	if err != nil {
+		return 0, err
+	}
+	required := sideChain.BlocksToWait
+	if tiers == nil {
+		return required, nil
+	}
+	for _, v := range tiers.Tiers {
+		if amount != nil && amount.Cmp(v.Amount) < 0 {
+			break
+		}
+		if v.BlocksToWait > required {
+			required = v.BlocksToWait
+		}
+	}
+	return required, nil
+}
+
+func putMaxProofAge(native *native.NativeService, chainID uint64, maxAge uint64) {
+	native.GetCacheDB().Put(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(MAX_PROOF_AGE),
+		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(maxAge)))
+}
+
+// GetMaxProofAge returns the max blocks the source height of a proof can be below the latest synced
+// height of the side chain, 0 if there is no limit
+func GetMaxProofAge(native *native.NativeService, chainID uint64) (uint64, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(MAX_PROOF_AGE),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return 0, fmt.Errorf("GetMaxProofAge, get max proof age error: %v", err)
+	}
+	if store == nil {
+		return 0, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("GetMaxProofAge, deserialize from raw storage item error: %v", err)
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+func putMerkleHash(native *native.NativeService, chainID uint64, hash string) {
+	native.GetCacheDB().Put(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(MERKLE_HASH),
+		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem([]byte(hash)))
+}
+
+// GetMerkleHash returns the name of the hash primitive in precompile the merkle proofs from the side
+// chain are verified with, sha256 if it is not set
+func GetMerkleHash(native *native.NativeService, chainID uint64) (string, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(MERKLE_HASH),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return "", fmt.Errorf("GetMerkleHash, get merkle hash error: %v", err)
+	}
+	if store == nil {
+		return precompile.SHA256, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return "", fmt.Errorf("GetMerkleHash, deserialize from raw storage item error: %v", err)
+	}
+	return string(raw), nil
+}
+
+func lateProofKey(chainID, height uint64) []byte {
+	return utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(LATE_PROOF), utils.GetUint64Bytes(chainID),
+		utils.GetUint64Bytes(height))
+}
+
+func putLateProof(native *native.NativeService, chainID, height uint64) {
+	native.GetCacheDB().Put(lateProofKey(chainID, height), cstates.GenRawStorageItem(utils.BYTE_TRUE))
+}
+
+// proofAgeRouters are the routers whose handlers check the proofs by CheckProofAge, a max proof age
+// set for the chains of the other routers would never be enforced
+var proofAgeRouters = map[uint64]bool{
+	utils.BTC_ROUTER:   true,
+	utils.BCH_ROUTER:   true,
+	utils.ZCASH_ROUTER: true,
+	utils.ETH_ROUTER:   true,
+	utils.BSC_ROUTER:   true,
+	utils.HECO_ROUTER:  true,
+	utils.MSC_ROUTER:   true,
+}
+
+// CheckProofAge rejects the proof at height of the side chain older than its max proof age from the
+// latest synced height, unless the late proofs at height are approved
+func CheckProofAge(native *native.NativeService, chainID uint64, height, bestHeight uint64) error {
+	maxAge, err := GetMaxProofAge(native, chainID)
+	if err != nil {
+		return err
+	}
+	if maxAge == 0 || height >= bestHeight || bestHeight-height <= maxAge {
+		return nil
+	}
+	approved, err := native.GetCacheDB().Get(lateProofKey(chainID, height))
+	if err != nil {
+		return fmt.Errorf("CheckProofAge, get late proof error: %v", err)
+	}
+	if approved == nil {
+		return fmt.Errorf("CheckProofAge, proof at height %d is older than %d blocks from synced height %d",
+			height, maxAge, bestHeight)
+	}
+	return nil
+}
+
+func putOpReturnLimit(native *native.NativeService, chainID uint64, limit uint64) {
+	native.GetCacheDB().Put(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(OP_RETURN_LIMIT),
+		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(limit)))
+}
+
+// GetOpReturnLimit returns the max payload accepted in the OP_RETURN output of a deposit from the btc side chain
+func GetOpReturnLimit(native *native.NativeService, chainID uint64) (uint64, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(OP_RETURN_LIMIT),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return 0, fmt.Errorf("GetOpReturnLimit, get op return limit error: %v", err)
+	}
+	if store == nil {
+		return DEFAULT_OP_RETURN_LIMIT, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("GetOpReturnLimit, deserialize from raw storage item error: %v", err)
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+func putWindDown(native *native.NativeService, chainID uint64, windDown *WindDown) {
+	sink := common.NewZeroCopySink(nil)
+	windDown.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(SIDE_CHAIN_WIND_DOWN),
+		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// GetWindDown returns nil if the side chain is not quitting
+func GetWindDown(native *native.NativeService, chainID uint64) (*WindDown, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(SIDE_CHAIN_WIND_DOWN),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("GetWindDown, get wind down error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetWindDown, deserialize from raw storage item error: %v", err)
+	}
+	windDown := new(WindDown)
+	if err := windDown.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetWindDown, deserialize WindDown error: %v", err)
+	}
+	return windDown, nil
+}
+
+// GetChainIDRegistry returns the chain ids ever registered on poly
+func GetChainIDRegistry(native *native.NativeService) (*ChainIDRegistry, error) {
+	registry := new(ChainIDRegistry)
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(CHAIN_ID_REGISTRY)))
+	if err != nil {
+		return nil, fmt.Errorf("GetChainIDRegistry, get chain id registry error: %v", err)
+	}
+	if store == nil {
+		return registry, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetChainIDRegistry, deserialize from raw storage item error: %v", err)
+	}
+	if err := registry.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetChainIDRegistry, deserialize ChainIDRegistry error: %v", err)
+	}
+	return registry, nil
+}
+
+func putChainIDRecord(native *native.NativeService, sideChain *SideChain) error {
+	registry, err := GetChainIDRegistry(native)
+	if err != nil {
+		return err
+	}
+	record := &ChainIDRecord{ChainId: sideChain.ChainId, Name: sideChain.Name, Router: sideChain.Router}
+	found := false
+	for i, v := range registry.Records {
+		if v.ChainId == sideChain.ChainId {
+			registry.Records[i] = record
+			found = true
+			break
+		}
+	}
+	if !found {
+		registry.Records = append(registry.Records, record)
+	}
+	sink := common.NewZeroCopySink(nil)
+	registry.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(CHAIN_ID_REGISTRY)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
+
+// checkChainID rejects a chain id of poly itself, assigned to another router, or registered before by another chain,
+// a chain quitted can register again with the same name and router
+func checkChainID(native *native.NativeService, chainID, router uint64, name string) error {
+	if chainID == native.GetChainID() {
+		return fmt.Errorf("chain id %d is poly", chainID)
+	}
+	if err := utils.CheckChainID(chainID, router); err != nil {
+		return err
+	}
+	registry, err := GetChainIDRegistry(native)
+	if err != nil {
+		return err
+	}
+	for _, v := range registry.Records {
+		if v.ChainId == chainID && (v.Name != name || v.Router != router) {
+			return fmt.Errorf("chain id %d is already used by %s of router %d", chainID, v.Name, v.Router)
+		}
+	}
+	return nil
+}
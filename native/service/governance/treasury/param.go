@@ -0,0 +1,213 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package treasury
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+)
+
+type DisbursementParam struct {
+	Address   common.Address
+	ChainID   uint64
+	Asset     []byte
+	Recipient []byte
+	Amount    *big.Int
+	Purpose   string
+}
+
+func (this *DisbursementParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarBytes(this.Asset)
+	sink.WriteVarBytes(this.Recipient)
+	sink.WriteVarBytes(this.Amount.Bytes())
+	sink.WriteString(this.Purpose)
+}
+
+func (this *DisbursementParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("DisbursementParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("DisbursementParam, common.AddressParseFromBytes error: %s", err)
+	}
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("DisbursementParam deserialize chain id error")
+	}
+	asset, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("DisbursementParam deserialize asset error")
+	}
+	recipient, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("DisbursementParam deserialize recipient error")
+	}
+	amount, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("DisbursementParam deserialize amount error")
+	}
+	purpose, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("DisbursementParam deserialize purpose error")
+	}
+
+	this.Address = addr
+	this.ChainID = chainID
+	this.Asset = asset
+	this.Recipient = recipient
+	this.Amount = new(big.Int).SetBytes(amount)
+	this.Purpose = purpose
+	return nil
+}
+
+type ApproveDisbursementParam struct {
+	ID      uint64
+	Address common.Address
+}
+
+func (this *ApproveDisbursementParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ID)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *ApproveDisbursementParam) Deserialization(source *common.ZeroCopySource) error {
+	id, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ApproveDisbursementParam deserialize id error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ApproveDisbursementParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("ApproveDisbursementParam, common.AddressParseFromBytes error: %s", err)
+	}
+
+	this.ID = id
+	this.Address = addr
+	return nil
+}
+
+type BalanceParam struct {
+	ChainID uint64
+	Asset   []byte
+}
+
+func (this *BalanceParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarBytes(this.Asset)
+}
+
+func (this *BalanceParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("BalanceParam deserialize chain id error")
+	}
+	asset, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BalanceParam deserialize asset error")
+	}
+
+	this.ChainID = chainID
+	this.Asset = asset
+	return nil
+}
+
+// HeaderReward is paid out of the treasury to the relayer who lands a new canonical header of a side
+// chain first, Amount of Asset on ChainID for each header once it's Depth blocks deep.
+type HeaderReward struct {
+	ChainID uint64
+	Asset   []byte
+	Amount  *big.Int
+	Depth   uint64
+}
+
+func (this *HeaderReward) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarBytes(this.Asset)
+	sink.WriteVarBytes(this.Amount.Bytes())
+	sink.WriteVarUint(this.Depth)
+}
+
+func (this *HeaderReward) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("HeaderReward deserialize chain id error")
+	}
+	asset, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("HeaderReward deserialize asset error")
+	}
+	amount, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("HeaderReward deserialize amount error")
+	}
+	depth, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("HeaderReward deserialize depth error")
+	}
+
+	this.ChainID = chainID
+	this.Asset = asset
+	this.Amount = new(big.Int).SetBytes(amount)
+	this.Depth = depth
+	return nil
+}
+
+type HeaderRewardParam struct {
+	Address     common.Address
+	SyncChainID uint64
+	Reward      *HeaderReward
+}
+
+func (this *HeaderRewardParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Address[:])
+	sink.WriteVarUint(this.SyncChainID)
+	this.Reward.Serialization(sink)
+}
+
+func (this *HeaderRewardParam) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("HeaderRewardParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("HeaderRewardParam, common.AddressParseFromBytes error: %s", err)
+	}
+	syncChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("HeaderRewardParam deserialize sync chain id error")
+	}
+	reward := new(HeaderReward)
+	if err := reward.Deserialization(source); err != nil {
+		return fmt.Errorf("HeaderRewardParam deserialize reward error: %v", err)
+	}
+
+	this.Address = addr
+	this.SyncChainID = syncChainID
+	this.Reward = reward
+	return nil
+}
@@ -0,0 +1,274 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package treasury
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	//function name
+	PROPOSE_DISBURSEMENT = "proposeDisbursement"
+	APPROVE_DISBURSEMENT = "approveDisbursement"
+	GET_BALANCE          = "getBalance"
+	SET_HEADER_REWARD    = "setHeaderReward"
+
+	//key prefix
+	BALANCE         = "balance"
+	DISBURSEMENT    = "disbursement"
+	DISBURSEMENT_ID = "disbursementID"
+	HEADER_REWARD   = "headerReward"
+
+	//income kind
+	INCOME_FEE   = "fee"
+	INCOME_SLASH = "slash"
+	INCOME_DUST  = "dust"
+)
+
+// PayoutFunc makes the cross chain tx paying amount of asset on chainID to recipient on the chain
+type PayoutFunc func(native *native.NativeService, chainID uint64, asset, recipient []byte, amount *big.Int) error
+
+// RelayerCreditFunc adds amount of asset on chainID to the fees the relayer claims on the chain
+type RelayerCreditFunc func(native *native.NativeService, relayer common.Address, chainID uint64, asset []byte,
+	amount *big.Int) error
+
+var (
+	payout        PayoutFunc
+	relayerCredit RelayerCreditFunc
+)
+
+// RegisterPayouts sets how the treasury pays out, the cross chain manager making the cross chain txs
+// registers them from init as the treasury can't import it.
+func RegisterPayouts(pay PayoutFunc, credit RelayerCreditFunc) {
+	payout = pay
+	relayerCredit = credit
+}
+
+//Register methods of treasury contract
+func RegisterTreasuryContract(native *native.NativeService) {
+	native.Register(PROPOSE_DISBURSEMENT, ProposeDisbursement)
+	native.Register(APPROVE_DISBURSEMENT, ApproveDisbursement)
+	native.Register(GET_BALANCE, GetBalanceQuery)
+	native.Register(SET_HEADER_REWARD, SetHeaderReward)
+}
+
+// Credit adds amount of asset on chainID to the treasury, it's called by the native contracts
+// collecting fees, slashed stake and dust remainders, kind tells which of them the income is.
+func Credit(native *native.NativeService, kind string, chainID uint64, asset []byte, amount *big.Int) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return fmt.Errorf("Credit, amount should be positive")
+	}
+	balance, err := GetBalance(native, chainID, asset)
+	if err != nil {
+		return fmt.Errorf("Credit, %v", err)
+	}
+	putBalance(native, chainID, asset, balance.Add(balance, amount))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.TreasuryContractAddress,
+			States:          []interface{}{"credit", kind, chainID, hex.EncodeToString(asset), amount.String()},
+		})
+	return nil
+}
+
+// ProposeDisbursement applies for paying out of the treasury, e.g. a grant or a relayer subsidy,
+// the proposal is paid after approved by the consensus peers.
+func ProposeDisbursement(native *native.NativeService) ([]byte, error) {
+	params := new(DisbursementParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ProposeDisbursement, contract params deserialize error: %v", err)
+	}
+	if params.Amount.Sign() <= 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("ProposeDisbursement, amount should be positive")
+	}
+	if len(params.Recipient) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("ProposeDisbursement, recipient is empty")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ProposeDisbursement, checkWitness error: %v", err)
+	}
+
+	id, err := putDisbursement(native, params)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ProposeDisbursement, %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.TreasuryContractAddress,
+			States: []interface{}{"proposeDisbursement", id, params.ChainID, hex.EncodeToString(params.Asset),
+				hex.EncodeToString(params.Recipient), params.Amount.String(), params.Purpose},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// ApproveDisbursement pays the proposal out of the treasury once signed by enough consensus peers, by
+// the cross chain tx made to the recipient on the side chain.
+func ApproveDisbursement(native *native.NativeService) ([]byte, error) {
+	params := new(ApproveDisbursementParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveDisbursement, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveDisbursement, checkWitness error: %v", err)
+	}
+
+	disbursement, err := getDisbursement(native, params.ID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveDisbursement, %v", err)
+	}
+	if disbursement == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveDisbursement, disbursement %d not found", params.ID)
+	}
+
+	//check consensus signs
+	ok, err := node_manager.CheckConsensusSigns(native, APPROVE_DISBURSEMENT, utils.GetUint64Bytes(params.ID), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveDisbursement, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	balance, err := GetBalance(native, disbursement.ChainID, disbursement.Asset)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveDisbursement, %v", err)
+	}
+	if balance.Cmp(disbursement.Amount) < 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveDisbursement, balance %s is less than amount %s",
+			balance.String(), disbursement.Amount.String())
+	}
+	if payout == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveDisbursement, no payout is registered")
+	}
+	if err := payout(native, disbursement.ChainID, disbursement.Asset, disbursement.Recipient, disbursement.Amount); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ApproveDisbursement, %v", err)
+	}
+	putBalance(native, disbursement.ChainID, disbursement.Asset, balance.Sub(balance, disbursement.Amount))
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.TreasuryContractAddress, []byte(DISBURSEMENT), utils.GetUint64Bytes(params.ID)))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.TreasuryContractAddress,
+			States: []interface{}{"disburse", params.ID, disbursement.ChainID, hex.EncodeToString(disbursement.Asset),
+				hex.EncodeToString(disbursement.Recipient), disbursement.Amount.String(), disbursement.Purpose},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// SetHeaderReward sets the reward of the headers of a side chain relayed to poly, approved by the
+// consensus peers, a zero amount stops the reward
+func SetHeaderReward(native *native.NativeService) ([]byte, error) {
+	params := new(HeaderRewardParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderReward, contract params deserialize error: %v", err)
+	}
+	if params.Reward.Amount.Sign() > 0 && params.Reward.Depth == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderReward, depth should be positive")
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderReward, checkWitness error: %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_HEADER_REWARD, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetHeaderReward, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if params.Reward.Amount.Sign() == 0 {
+		native.GetCacheDB().Delete(headerRewardKey(params.SyncChainID))
+	} else {
+		sink = common.NewZeroCopySink(nil)
+		params.Reward.Serialization(sink)
+		native.GetCacheDB().Put(headerRewardKey(params.SyncChainID), cstates.GenRawStorageItem(sink.Bytes()))
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.TreasuryContractAddress,
+			States: []interface{}{"setHeaderReward", params.SyncChainID, params.Reward.ChainID,
+				hex.EncodeToString(params.Reward.Asset), params.Reward.Amount.String(), params.Reward.Depth},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// PayHeaderReward pays the reward of the header of syncChainID at height to relayer out of the
+// treasury, it's added to the fees the relayer claims on the chain of the reward. Nothing is paid
+// when the headers are not rewarded or the treasury runs short.
+func PayHeaderReward(native *native.NativeService, syncChainID, height uint64, relayer common.Address) error {
+	reward, err := GetHeaderReward(native, syncChainID)
+	if err != nil {
+		return fmt.Errorf("PayHeaderReward, %v", err)
+	}
+	if reward == nil {
+		return nil
+	}
+	balance, err := GetBalance(native, reward.ChainID, reward.Asset)
+	if err != nil {
+		return fmt.Errorf("PayHeaderReward, %v", err)
+	}
+	if balance.Cmp(reward.Amount) < 0 {
+		return nil
+	}
+	if relayerCredit == nil {
+		return fmt.Errorf("PayHeaderReward, no relayer credit is registered")
+	}
+	if err := relayerCredit(native, relayer, reward.ChainID, reward.Asset, reward.Amount); err != nil {
+		return fmt.Errorf("PayHeaderReward, %v", err)
+	}
+	putBalance(native, reward.ChainID, reward.Asset, balance.Sub(balance, reward.Amount))
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.TreasuryContractAddress,
+			States: []interface{}{"headerReward", syncChainID, height, relayer.ToBase58(), reward.ChainID,
+				hex.EncodeToString(reward.Asset), reward.Amount.String()},
+		})
+	return nil
+}
+
+// GetBalanceQuery returns the balance of the asset in big endian, to be called by preExec
+func GetBalanceQuery(native *native.NativeService) ([]byte, error) {
+	params := new(BalanceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetBalanceQuery, contract params deserialize error: %v", err)
+	}
+	balance, err := GetBalance(native, params.ChainID, params.Asset)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetBalanceQuery, %v", err)
+	}
+	return balance.Bytes(), nil
+}
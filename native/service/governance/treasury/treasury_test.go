@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package treasury
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func getNativeFunc() *native.NativeService {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	cacheDB := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	ns, _ := native.NewNativeService(cacheDB, new(types.Transaction), 0, 200, common.Uint256{}, 0, nil, false)
+	return ns
+}
+
+func TestDisbursementParam(t *testing.T) {
+	p := DisbursementParam{
+		Address:   common.Address{1, 2, 3},
+		ChainID:   2,
+		Asset:     []byte{4, 5, 6},
+		Recipient: []byte{7, 8, 9},
+		Amount:    big.NewInt(1000),
+		Purpose:   "relayer subsidy",
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	p.Serialization(sink)
+
+	var param DisbursementParam
+	err := param.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+
+	assert.Equal(t, p, param)
+}
+
+func TestHeaderRewardParam(t *testing.T) {
+	p := HeaderRewardParam{
+		Address:     common.Address{1, 2, 3},
+		SyncChainID: 2,
+		Reward:      &HeaderReward{ChainID: 3, Asset: []byte{4, 5, 6}, Amount: big.NewInt(10), Depth: 12},
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	p.Serialization(sink)
+
+	var param HeaderRewardParam
+	err := param.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+
+	assert.Equal(t, p, param)
+}
+
+func TestCredit(t *testing.T) {
+	ns := getNativeFunc()
+	asset := []byte{1, 2, 3}
+
+	assert.Error(t, Credit(ns, INCOME_FEE, 2, asset, big.NewInt(0)))
+	assert.NoError(t, Credit(ns, INCOME_FEE, 2, asset, big.NewInt(100)))
+	assert.NoError(t, Credit(ns, INCOME_SLASH, 2, asset, big.NewInt(50)))
+
+	balance, err := GetBalance(ns, 2, asset)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(150), balance)
+	balance, err = GetBalance(ns, 3, asset)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, balance.Sign())
+	assert.Equal(t, 2, len(ns.GetNotify()))
+
+	id, err := putDisbursement(ns, &DisbursementParam{ChainID: 2, Asset: asset, Recipient: []byte{4}, Amount: big.NewInt(10)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), id)
+	id, err = putDisbursement(ns, &DisbursementParam{ChainID: 2, Asset: asset, Recipient: []byte{5}, Amount: big.NewInt(20)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), id)
+	disbursement, err := getDisbursement(ns, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{5}, disbursement.Recipient)
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package treasury
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func putBalance(native *native.NativeService, chainID uint64, asset []byte, balance *big.Int) {
+	key := utils.ConcatKey(utils.TreasuryContractAddress, []byte(BALANCE), utils.GetUint64Bytes(chainID), asset)
+	if balance.Sign() == 0 {
+		native.GetCacheDB().Delete(key)
+		return
+	}
+	native.GetCacheDB().Put(key, cstates.GenRawStorageItem(balance.Bytes()))
+}
+
+// GetBalance returns the amount of asset on chainID held by the treasury
+func GetBalance(native *native.NativeService, chainID uint64, asset []byte) (*big.Int, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.TreasuryContractAddress, []byte(BALANCE),
+		utils.GetUint64Bytes(chainID), asset))
+	if err != nil {
+		return nil, fmt.Errorf("GetBalance, get balance store error: %v", err)
+	}
+	if store == nil {
+		return new(big.Int), nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetBalance, deserialize from raw storage item err: %v", err)
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+func putDisbursement(native *native.NativeService, disbursement *DisbursementParam) (uint64, error) {
+	contract := utils.TreasuryContractAddress
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(DISBURSEMENT_ID)))
+	if err != nil {
+		return 0, fmt.Errorf("putDisbursement, get disbursement id store error: %v", err)
+	}
+	var id uint64
+	if store != nil {
+		raw, err := cstates.GetValueFromRawStorageItem(store)
+		if err != nil {
+			return 0, fmt.Errorf("putDisbursement, deserialize from raw storage item err: %v", err)
+		}
+		id = utils.GetBytesUint64(raw)
+	}
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(DISBURSEMENT_ID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(id+1)))
+
+	sink := common.NewZeroCopySink(nil)
+	disbursement.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(DISBURSEMENT), utils.GetUint64Bytes(id)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	return id, nil
+}
+
+func getDisbursement(native *native.NativeService, id uint64) (*DisbursementParam, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.TreasuryContractAddress, []byte(DISBURSEMENT), utils.GetUint64Bytes(id)))
+	if err != nil {
+		return nil, fmt.Errorf("getDisbursement, get disbursement store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("getDisbursement, deserialize from raw storage item err: %v", err)
+	}
+	disbursement := new(DisbursementParam)
+	if err := disbursement.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("getDisbursement, deserialize disbursement error: %v", err)
+	}
+	return disbursement, nil
+}
+
+func headerRewardKey(syncChainID uint64) []byte {
+	return utils.ConcatKey(utils.TreasuryContractAddress, []byte(HEADER_REWARD), utils.GetUint64Bytes(syncChainID))
+}
+
+// GetHeaderReward returns the reward of the headers of syncChainID, nil if they are not rewarded
+func GetHeaderReward(native *native.NativeService, syncChainID uint64) (*HeaderReward, error) {
+	store, err := native.GetCacheDB().Get(headerRewardKey(syncChainID))
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderReward, get header reward store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderReward, deserialize from raw storage item err: %v", err)
+	}
+	reward := new(HeaderReward)
+	if err := reward.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetHeaderReward, deserialize reward error: %v", err)
+	}
+	return reward, nil
+}
@@ -36,10 +36,17 @@ import (
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/precompile"
 	"github.com/polynetwork/poly/native/service/utils"
 	"golang.org/x/crypto/sha3"
 )
 
+// the crypto primitives the header verification relies on
+var (
+	secp256k1Recover = precompile.MustRecover("header_sync/bsc", precompile.SECP256K1_RECOVER)
+	keccak256        = precompile.MustHash("header_sync/bsc", precompile.KECCAK256)
+)
+
 // Handler ...
 type Handler struct {
 }
@@ -215,13 +222,7 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 	if err != nil {
 		return fmt.Errorf("bsc Handler SyncBlockHeader, GetSideChain error: %v", err)
 	}
-	var extraInfo ExtraInfo
-	err = json.Unmarshal(side.ExtraInfo, &extraInfo)
-	if err != nil {
-		return fmt.Errorf("bsc Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
-	}
-
-	ctx := &Context{ExtraInfo: extraInfo, ChainID: headerParams.ChainID}
+	ctx := &Context{ChainID: headerParams.ChainID}
 
 	for _, v := range headerParams.Headers {
 		var header types.Header
@@ -229,6 +230,15 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 		if err != nil {
 			return fmt.Errorf("bsc Handler SyncBlockHeader, deserialize header err: %v", err)
 		}
+		// the parameters published by governance for the height take the place of the side chain ExtraInfo
+		extraInfo, err := scom.GetVerifyParams(native, headerParams.ChainID, header.Number.Uint64(), side.ExtraInfo)
+		if err != nil {
+			return fmt.Errorf("bsc Handler SyncBlockHeader, GetVerifyParams error: %v", err)
+		}
+		ctx.ExtraInfo = ExtraInfo{}
+		if err := json.Unmarshal(extraInfo, &ctx.ExtraInfo); err != nil {
+			return fmt.Errorf("bsc Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
+		}
 		headerHash := header.Hash()
 
 		exist, err := isHeaderExist(native, headerHash, ctx)
@@ -363,6 +373,7 @@ func GetCanonicalHeader(native *native.NativeService, chainID uint64, height uin
 
 func deleteCanonicalHash(native *native.NativeService, chainID uint64, height uint64) {
 	native.GetCacheDB().Delete(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)))
+	scom.DeleteHeaderCommitment(native, chainID, height)
 }
 
 func getCanonicalHash(native *native.NativeService, chainID uint64, height uint64) (hash ecommon.Hash, err error) {
@@ -388,6 +399,7 @@ func getCanonicalHash(native *native.NativeService, chainID uint64, height uint6
 func putCanonicalHash(native *native.NativeService, chainID uint64, height uint64, hash ecommon.Hash) {
 	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)),
 		cstates.GenRawStorageItem(hash.Bytes()))
+	scom.PutHeaderCommitment(native, chainID, height, hash.Bytes())
 }
 
 func putHeaderWithSum(native *native.NativeService, chainID uint64, headerWithSum *HeaderWithDifficultySum) (err error) {
@@ -784,12 +796,12 @@ func ecrecover(header *types.Header, chainID *big.Int) (ecommon.Address, error)
 	signature := header.Extra[len(header.Extra)-extraSeal:]
 
 	// Recover the public key and the Ethereum address
-	pubkey, err := crypto.Ecrecover(SealHash(header, chainID).Bytes(), signature)
+	pubkey, err := secp256k1Recover(SealHash(header, chainID).Bytes(), signature)
 	if err != nil {
 		return ecommon.Address{}, err
 	}
 	var signer ecommon.Address
-	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	copy(signer[:], keccak256(pubkey[1:])[12:])
 
 	return signer, nil
 }
@@ -0,0 +1,192 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// Bitcoin Cash shares the header format, the proof of work and the storage layout with
+// bitcoin, only the difficulty adjustment differs: cw-144 DAA since Nov 2017 and
+// aserti3-2d since Nov 2020.
+const (
+	bchDaaWindow         = 144
+	bchTargetSpacing     = int64(600)
+	bchMinDaaTimespan    = 72 * bchTargetSpacing
+	bchMaxDaaTimespan    = 288 * bchTargetSpacing
+	bchAsertHalfLife     = int64(2 * 24 * 3600)
+	bchAsertRadixBits    = 16
+	bchAsertFractionMask = int64(1<<bchAsertRadixBits - 1)
+)
+
+// asertAnchor is the last block computed by cw-144, all the blocks after it
+// are computed by aserti3-2d relative to it.
+type asertAnchor struct {
+	height   uint32
+	bits     uint32
+	prevTime int64
+}
+
+var bchAsertAnchors = map[string]asertAnchor{
+	chaincfg.MainNetParams.Name:  {height: 661647, bits: 0x1804dafe, prevTime: 1605447844},
+	chaincfg.TestNet3Params.Name: {height: 1421481, bits: 0x1d00ffff, prevTime: 1605445400},
+}
+
+var oneLsh256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// IsBchChain tells whether the utxo chain registered with chainId is Bitcoin Cash
+func IsBchChain(native *native.NativeService, chainId uint64) (bool, error) {
+	side, err := side_chain_manager.GetSideChain(native, chainId)
+	if err != nil {
+		return false, fmt.Errorf("IsBchChain, get side chain error: %v", err)
+	}
+	if side == nil {
+		return false, fmt.Errorf("IsBchChain, side chain info for chainId: %d is not registered", chainId)
+	}
+	return side.Router == utils.BCH_ROUTER, nil
+}
+
+// Get the PoW target a bitcoin cash block at height should meet. The blocks under the cw-144 DAA
+// need the 146 ancestors of the window synced, the genesis synced for them must be deep enough.
+func calcBchRequiredWork(native *native.NativeService, chainID uint64, header wire.BlockHeader, height uint32,
+	prevHeader *StoredHeader, netParam *chaincfg.Params) (uint32, error) {
+	// If it's been more than 20 minutes since the last header on testnet, return the minimum difficulty
+	if netParam.ReduceMinDifficulty && header.Timestamp.After(prevHeader.Header.Timestamp.Add(targetSpacing*2)) {
+		return netParam.PowLimitBits, nil
+	}
+	if anchor, ok := bchAsertAnchors[netParam.Name]; ok && height > anchor.height {
+		return calcAsertWork(anchor, prevHeader, netParam), nil
+	}
+	bits, err := calcCashWork(native, chainID, prevHeader, netParam)
+	if err != nil {
+		return 0, fmt.Errorf("calcBchRequiredWork, no enough history for block %d: %v", height, err)
+	}
+	return bits, nil
+}
+
+// calcCashWork implements the cw-144 DAA which uses the work done over the last 144
+// blocks, both ends are the median of three to resist the timestamp manipulation.
+func calcCashWork(native *native.NativeService, chainID uint64, prevHeader *StoredHeader, netParam *chaincfg.Params) (uint32, error) {
+	last, err := getSuitableHeader(native, chainID, prevHeader)
+	if err != nil {
+		return 0, err
+	}
+	sh := prevHeader
+	for i := 0; i < bchDaaWindow; i++ {
+		sh, err = GetPreviousHeader(native, chainID, sh.Header)
+		if err != nil {
+			return 0, err
+		}
+	}
+	first, err := getSuitableHeader(native, chainID, sh)
+	if err != nil {
+		return 0, err
+	}
+	target := computeCashTarget(first, last)
+	if target.Sign() <= 0 || target.Cmp(netParam.PowLimit) > 0 {
+		return netParam.PowLimitBits, nil
+	}
+	return blockchain.BigToCompact(target), nil
+}
+
+func computeCashTarget(first, last *StoredHeader) *big.Int {
+	work := new(big.Int).Sub(last.totalWork, first.totalWork)
+	work.Mul(work, big.NewInt(bchTargetSpacing))
+
+	timespan := last.Header.Timestamp.Unix() - first.Header.Timestamp.Unix()
+	if timespan < bchMinDaaTimespan {
+		timespan = bchMinDaaTimespan
+	} else if timespan > bchMaxDaaTimespan {
+		timespan = bchMaxDaaTimespan
+	}
+	work.Div(work, big.NewInt(timespan))
+	if work.Sign() <= 0 {
+		return work
+	}
+	// target = (2^256 - work) / work
+	target := new(big.Int).Sub(oneLsh256, work)
+	return target.Div(target, work)
+}
+
+// getSuitableHeader returns the header with the median timestamp among sh and its two parents
+func getSuitableHeader(native *native.NativeService, chainID uint64, sh *StoredHeader) (*StoredHeader, error) {
+	parent, err := GetPreviousHeader(native, chainID, sh.Header)
+	if err != nil {
+		return nil, err
+	}
+	grandParent, err := GetPreviousHeader(native, chainID, parent.Header)
+	if err != nil {
+		return nil, err
+	}
+	hdrs := [3]*StoredHeader{grandParent, parent, sh}
+	if hdrs[0].Header.Timestamp.After(hdrs[2].Header.Timestamp) {
+		hdrs[0], hdrs[2] = hdrs[2], hdrs[0]
+	}
+	if hdrs[0].Header.Timestamp.After(hdrs[1].Header.Timestamp) {
+		hdrs[0], hdrs[1] = hdrs[1], hdrs[0]
+	}
+	if hdrs[1].Header.Timestamp.After(hdrs[2].Header.Timestamp) {
+		hdrs[1], hdrs[2] = hdrs[2], hdrs[1]
+	}
+	return hdrs[1], nil
+}
+
+// calcAsertWork implements aserti3-2d, the target is exponentially adjusted by
+// how far the chain is ahead of or behind the ideal schedule since the anchor.
+func calcAsertWork(anchor asertAnchor, prevHeader *StoredHeader, netParam *chaincfg.Params) uint32 {
+	timeDelta := prevHeader.Header.Timestamp.Unix() - anchor.prevTime
+	heightDelta := int64(prevHeader.Height) - int64(anchor.height)
+
+	// the division rounds towards zero as the reference implementation does
+	exponent := ((timeDelta - bchTargetSpacing*(heightDelta+1)) << bchAsertRadixBits) / bchAsertHalfLife
+	shifts := exponent >> bchAsertRadixBits
+	frac := exponent & bchAsertFractionMask
+
+	// factor = 2^16 + approximation of 2^16 * (2^frac - 1) with a cubic polynomial
+	factor := new(big.Int).Mul(big.NewInt(195766423245049), big.NewInt(frac))
+	factor.Add(factor, new(big.Int).Mul(big.NewInt(971821376), big.NewInt(frac*frac)))
+	factor.Add(factor, new(big.Int).Mul(big.NewInt(5127), big.NewInt(frac*frac*frac)))
+	factor.Add(factor, new(big.Int).Lsh(big.NewInt(1), 47))
+	factor.Rsh(factor, 48)
+	factor.Add(factor, big.NewInt(1<<bchAsertRadixBits))
+
+	target := blockchain.CompactToBig(anchor.bits)
+	target.Mul(target, factor)
+	if shifts < 0 {
+		target.Rsh(target, uint(-shifts))
+	} else {
+		target.Lsh(target, uint(shifts))
+	}
+	target.Rsh(target, bchAsertRadixBits)
+
+	if target.Sign() == 0 {
+		return blockchain.BigToCompact(big.NewInt(1))
+	}
+	if target.Cmp(netParam.PowLimit) > 0 {
+		return netParam.PowLimitBits
+	}
+	return blockchain.BigToCompact(target)
+}
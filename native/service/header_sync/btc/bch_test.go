@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package btc
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalcAsertWork(t *testing.T) {
+	anchor := bchAsertAnchors[chaincfg.MainNetParams.Name]
+	prev := &StoredHeader{
+		Header: wire.BlockHeader{
+			Timestamp: time.Unix(anchor.prevTime+bchTargetSpacing, 0),
+		},
+		Height: anchor.height,
+	}
+	// exactly on schedule keeps the anchor target
+	assert.Equal(t, anchor.bits, calcAsertWork(anchor, prev, &chaincfg.MainNetParams))
+
+	// one half life behind the schedule doubles the target
+	prev.Header.Timestamp = time.Unix(anchor.prevTime+bchTargetSpacing+bchAsertHalfLife, 0)
+	expect := new(big.Int).Lsh(blockchain.CompactToBig(anchor.bits), 1)
+	assert.Equal(t, blockchain.BigToCompact(expect), calcAsertWork(anchor, prev, &chaincfg.MainNetParams))
+
+	// one half life ahead of the schedule halves the target
+	prev.Header.Timestamp = time.Unix(anchor.prevTime+bchTargetSpacing-bchAsertHalfLife, 0)
+	expect = new(big.Int).Rsh(blockchain.CompactToBig(anchor.bits), 1)
+	assert.Equal(t, blockchain.BigToCompact(expect), calcAsertWork(anchor, prev, &chaincfg.MainNetParams))
+
+	// never easier than the pow limit
+	prev.Header.Timestamp = time.Unix(anchor.prevTime+bchAsertHalfLife*100, 0)
+	assert.Equal(t, chaincfg.MainNetParams.PowLimitBits, calcAsertWork(anchor, prev, &chaincfg.MainNetParams))
+}
+
+func TestComputeCashTarget(t *testing.T) {
+	bits := uint32(0x1804dafe)
+	work := blockchain.CalcWork(bits)
+	first := &StoredHeader{
+		Header:    wire.BlockHeader{Timestamp: time.Unix(1600000000, 0)},
+		totalWork: big.NewInt(0),
+	}
+	last := &StoredHeader{
+		Header:    wire.BlockHeader{Timestamp: time.Unix(1600000000+bchDaaWindow*bchTargetSpacing, 0)},
+		totalWork: new(big.Int).Mul(work, big.NewInt(bchDaaWindow)),
+	}
+	// blocks found on schedule keep the difficulty
+	target := computeCashTarget(first, last)
+	assert.Equal(t, bits, blockchain.BigToCompact(target))
+
+	// blocks found twice as fast double the difficulty
+	last.Header.Timestamp = time.Unix(1600000000+bchDaaWindow*bchTargetSpacing/2, 0)
+	target = computeCashTarget(first, last)
+	expect := new(big.Int).Rsh(blockchain.CompactToBig(bits), 1)
+	assert.Equal(t, blockchain.BigToCompact(expect), blockchain.BigToCompact(target))
+}
@@ -90,6 +90,7 @@ func putGenesisBlockHeader(native *native.NativeService, chainID uint64, blockHe
 func putBlockHash(native *native.NativeService, chainID uint64, height uint32, hash chainhash.Hash) {
 	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.HEADER_INDEX), utils.GetUint64Bytes(chainID), utils.GetUint32Bytes(height)),
 		cstates.GenRawStorageItem(hash.CloneBytes()))
+	scom.PutHeaderCommitment(native, chainID, uint64(height), hash.CloneBytes())
 }
 
 func GetBlockHashByHeight(native *native.NativeService, chainID uint64, height uint32) (*chainhash.Hash, error) {
@@ -211,8 +212,17 @@ func CheckHeader(native *native.NativeService, chainID uint64, header wire.Block
 	}
 
 	if netParam.Name != "regtest" && netParam.Name != "simnet" {
+		isBch, err := IsBchChain(native, chainID)
+		if err != nil {
+			return false, fmt.Errorf("CheckHeader, %v", err)
+		}
 		// Check the header meets the difficulty requirement
-		diffTarget, err := calcRequiredWork(native, chainID, header, int32(height+1), prevHeader, netParam)
+		var diffTarget uint32
+		if isBch {
+			diffTarget, err = calcBchRequiredWork(native, chainID, header, height+1, prevHeader, netParam)
+		} else {
+			diffTarget, err = calcRequiredWork(native, chainID, header, int32(height+1), prevHeader, netParam)
+		}
 		if err != nil {
 			return false, fmt.Errorf("CheckHeader, calclating difficulty error: %v", err)
 		}
@@ -328,6 +338,7 @@ func ReIndexHeaderHeight(native *native.NativeService, chainID uint64, bestHeade
 	contract := utils.HeaderSyncContractAddress
 	for i := bestHeaderHeight; i > newBlock.Height; i-- {
 		native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(scom.HEADER_INDEX), utils.GetUint64Bytes(chainID), utils.GetUint32Bytes(i)))
+		scom.DeleteHeaderCommitment(native, chainID, uint64(i))
 	}
 
 	for i, v := range hdrs {
@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const GENESIS_ATTESTATION = "genesisAttestation"
+
+// GenesisAttestation collects the consensus peers attesting a genesis header of a side chain, the
+// header is synced once the signers still in the consensus reach two thirds of it.
+type GenesisAttestation struct {
+	Signers []common.Address
+}
+
+func (this *GenesisAttestation) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Signers)))
+	for _, v := range this.Signers {
+		sink.WriteAddress(v)
+	}
+}
+
+func (this *GenesisAttestation) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("GenesisAttestation deserialize length error")
+	}
+	signers := make([]common.Address, 0, n)
+	for i := uint64(0); i < n; i++ {
+		signer, eof := source.NextAddress()
+		if eof {
+			return fmt.Errorf("GenesisAttestation deserialize signer error")
+		}
+		signers = append(signers, signer)
+	}
+	this.Signers = signers
+	return nil
+}
+
+// Add appends signer, false is returned if it has attested already
+func (this *GenesisAttestation) Add(signer common.Address) bool {
+	for _, v := range this.Signers {
+		if v == signer {
+			return false
+		}
+	}
+	this.Signers = append(this.Signers, signer)
+	return true
+}
+
+func genesisAttestationKey(chainID uint64, header []byte) []byte {
+	hash := sha256.Sum256(header)
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(GENESIS_ATTESTATION), utils.GetUint64Bytes(chainID), hash[:])
+}
+
+// GetGenesisAttestation returns the attestation of the genesis header of chainID, empty if none
+func GetGenesisAttestation(native *native.NativeService, chainID uint64, header []byte) (*GenesisAttestation, error) {
+	store, err := native.GetCacheDB().Get(genesisAttestationKey(chainID, header))
+	if err != nil {
+		return nil, fmt.Errorf("GetGenesisAttestation, get genesis attestation store error: %v", err)
+	}
+	attestation := &GenesisAttestation{Signers: make([]common.Address, 0)}
+	if store == nil {
+		return attestation, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetGenesisAttestation, deserialize from raw storage item error: %v", err)
+	}
+	if err := attestation.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetGenesisAttestation, %v", err)
+	}
+	return attestation, nil
+}
+
+func PutGenesisAttestation(native *native.NativeService, chainID uint64, header []byte, attestation *GenesisAttestation) {
+	sink := common.NewZeroCopySink(nil)
+	attestation.Serialization(sink)
+	native.GetCacheDB().Put(genesisAttestationKey(chainID, header), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// DeleteGenesisAttestation drops the attestation once the header is synced, a later sync of the
+// chain is attested again
+func DeleteGenesisAttestation(native *native.NativeService, chainID uint64, header []byte) {
+	native.GetCacheDB().Delete(genesisAttestationKey(chainID, header))
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenesisAttestation(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	ns, err := native.NewNativeService(db, new(types.Transaction), 0, 0, common.Uint256{}, 0, nil, false)
+	assert.NoError(t, err)
+
+	header := []byte("genesis")
+	attestation, err := GetGenesisAttestation(ns, 2, header)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(attestation.Signers))
+
+	assert.True(t, attestation.Add(common.Address{1}))
+	assert.True(t, attestation.Add(common.Address{2}))
+	assert.False(t, attestation.Add(common.Address{1}), "a peer attests once")
+	PutGenesisAttestation(ns, 2, header, attestation)
+
+	stored, err := GetGenesisAttestation(ns, 2, header)
+	assert.NoError(t, err)
+	assert.Equal(t, attestation, stored)
+	other, err := GetGenesisAttestation(ns, 2, []byte("other"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(other.Signers), "attestations are per header")
+	other, err = GetGenesisAttestation(ns, 3, header)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(other.Signers), "attestations are per chain")
+
+	DeleteGenesisAttestation(ns, 2, header)
+	stored, err = GetGenesisAttestation(ns, 2, header)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(stored.Signers))
+}
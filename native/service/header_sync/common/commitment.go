@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	HEADER_COMMITMENT      = "headerCommitment"
+	HEADER_COMMITMENT_HASH = "headerCommitmentHash"
+	HEADER_COUNT           = "headerCount"
+)
+
+// HeaderCommitment tells the canonical header of a side chain at Height. It is put
+// into the cross states of poly block PolyHeight when the header becomes canonical,
+// so the merkle proof against the CrossStateRoot of that block shows which header
+// poly consensus took.
+type HeaderCommitment struct {
+	ChainID    uint64
+	Height     uint64
+	Hash       []byte
+	PolyHeight uint32
+}
+
+func (this *HeaderCommitment) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ChainID)
+	sink.WriteUint64(this.Height)
+	sink.WriteVarBytes(this.Hash)
+	sink.WriteUint32(this.PolyHeight)
+}
+
+func (this *HeaderCommitment) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.ChainID, eof = source.NextUint64()
+	if eof {
+		return fmt.Errorf("HeaderCommitment deserialize chain id error")
+	}
+	this.Height, eof = source.NextUint64()
+	if eof {
+		return fmt.Errorf("HeaderCommitment deserialize height error")
+	}
+	this.Hash, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("HeaderCommitment deserialize hash error")
+	}
+	this.PolyHeight, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("HeaderCommitment deserialize poly height error")
+	}
+	return nil
+}
+
+// PutHeaderCommitment commits hash as the canonical header of chainID at height
+func PutHeaderCommitment(native *native.NativeService, chainID, height uint64, hash []byte) {
+	if native.GetHeight() < config.GetHeaderCommitmentHeight(config.DefConfig.P2PNode.NetworkId) {
+		return
+	}
+	contract := utils.HeaderSyncContractAddress
+	commitment := &HeaderCommitment{
+		ChainID:    chainID,
+		Height:     height,
+		Hash:       hash,
+		PolyHeight: native.GetHeight(),
+	}
+	sink := common.NewZeroCopySink(nil)
+	commitment.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(HEADER_COMMITMENT), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(HEADER_COMMITMENT_HASH), utils.GetUint64Bytes(chainID), hash),
+		cstates.GenRawStorageItem(utils.GetUint64Bytes(height)))
+	native.PutMerkleVal(sink.Bytes())
+	if err := countHeader(native, chainID); err != nil {
+		log.Errorf("PutHeaderCommitment, count header %d of chain %d error: %v", height, chainID, err)
+	}
+	if err := rewardHeader(native, chainID, height, hash); err != nil {
+		log.Errorf("PutHeaderCommitment, reward header %d of chain %d error: %v", height, chainID, err)
+	}
+}
+
+func countHeader(native *native.NativeService, chainID uint64) error {
+	count, err := GetHeaderCount(native, chainID)
+	if err != nil {
+		return err
+	}
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_COUNT), utils.GetUint64Bytes(chainID)),
+		cstates.GenRawStorageItem(utils.GetUint64Bytes(count+1)))
+	return nil
+}
+
+// GetHeaderCount returns the number of headers of chainID committed, the ones committed again after a
+// reorganization included
+func GetHeaderCount(native *native.NativeService, chainID uint64) (uint64, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_COUNT),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return 0, fmt.Errorf("GetHeaderCount, get header count store error: %v", err)
+	}
+	if store == nil {
+		return 0, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("GetHeaderCount, deserialize from raw storage item err: %v", err)
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+// DeleteHeaderCommitment removes the commitment of chainID at height when the header is
+// reorganized out, the index by hash is left and checked against the commitment.
+func DeleteHeaderCommitment(native *native.NativeService, chainID, height uint64) {
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_COMMITMENT),
+		utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)))
+}
+
+// GetHeaderCommitment returns the commitment of chainID at height, nil if there is none
+func GetHeaderCommitment(native *native.NativeService, chainID, height uint64) (*HeaderCommitment, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_COMMITMENT),
+		utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)))
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderCommitment, get commitment store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderCommitment, deserialize from raw storage item err: %v", err)
+	}
+	commitment := new(HeaderCommitment)
+	if err := commitment.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetHeaderCommitment, deserialize commitment error: %v", err)
+	}
+	return commitment, nil
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	// EPOCH_HEIGHTS keeps the heights of a side chain where its validator set changed, so that the set
+	// effective at the height of a proof can be found among the ones kept by height
+	EPOCH_HEIGHTS = "epochHeights"
+	// EPOCH_SUPERSEDED keeps the poly block time when a validator set of a side chain was replaced
+	EPOCH_SUPERSEDED = "epochSuperseded"
+	// EPOCH_TRUSTING_PERIOD is how long in seconds a replaced validator set is still trusted, its members
+	// may have withdrawn their bonds since then and could sign another history of the chain at no cost
+	EPOCH_TRUSTING_PERIOD = 14 * 24 * 3600
+)
+
+// EpochHeights are the heights of the validator set changes of a side chain in ascending order
+type EpochHeights struct {
+	Heights []uint64
+}
+
+func (this *EpochHeights) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Heights)))
+	for _, v := range this.Heights {
+		sink.WriteVarUint(v)
+	}
+}
+
+func (this *EpochHeights) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("EpochHeights deserialize length error")
+	}
+	heights := make([]uint64, 0, n)
+	for i := uint64(0); i < n; i++ {
+		height, eof := source.NextVarUint()
+		if eof {
+			return fmt.Errorf("EpochHeights deserialize height error")
+		}
+		heights = append(heights, height)
+	}
+	this.Heights = heights
+	return nil
+}
+
+// GetEpochHeights returns the heights of the validator set changes of chainID recorded so far
+func GetEpochHeights(native *native.NativeService, chainID uint64) (*EpochHeights, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(EPOCH_HEIGHTS),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("GetEpochHeights, get epoch heights error: %v", err)
+	}
+	heights := new(EpochHeights)
+	if store == nil {
+		return heights, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetEpochHeights, deserialize from raw storage item err: %v", err)
+	}
+	if err := heights.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetEpochHeights, deserialize epoch heights err: %v", err)
+	}
+	return heights, nil
+}
+
+func epochSupersededKey(chainID, height uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(EPOCH_SUPERSEDED), utils.GetUint64Bytes(chainID),
+		utils.GetUint64Bytes(height))
+}
+
+// putEpochSuperseded records now as the time the set of chainID at height was replaced, the earliest
+// time recorded is kept
+func putEpochSuperseded(native *native.NativeService, chainID, height uint64) error {
+	store, err := native.GetCacheDB().Get(epochSupersededKey(chainID, height))
+	if err != nil {
+		return fmt.Errorf("putEpochSuperseded, get superseded time error: %v", err)
+	}
+	if store != nil {
+		return nil
+	}
+	native.GetCacheDB().Put(epochSupersededKey(chainID, height),
+		cstates.GenRawStorageItem(utils.GetUint32Bytes(native.GetBlockTime())))
+	return nil
+}
+
+// getEpochSuperseded returns the time the set of chainID at height was replaced, false is returned if
+// it is not recorded
+func getEpochSuperseded(native *native.NativeService, chainID, height uint64) (uint32, bool, error) {
+	store, err := native.GetCacheDB().Get(epochSupersededKey(chainID, height))
+	if err != nil {
+		return 0, false, fmt.Errorf("getEpochSuperseded, get superseded time error: %v", err)
+	}
+	if store == nil {
+		return 0, false, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, false, fmt.Errorf("getEpochSuperseded, deserialize from raw storage item err: %v", err)
+	}
+	return utils.GetBytesUint32(raw), true, nil
+}
+
+// PutEpochHeight records a validator set change of chainID at height, the set itself is kept by the
+// header sync of the chain under the height. The set it replaces starts its trusting period now.
+func PutEpochHeight(native *native.NativeService, chainID, height uint64) error {
+	heights, err := GetEpochHeights(native, chainID)
+	if err != nil {
+		return err
+	}
+	i := sort.Search(len(heights.Heights), func(i int) bool { return heights.Heights[i] >= height })
+	if i < len(heights.Heights) && heights.Heights[i] == height {
+		return nil
+	}
+	if i > 0 {
+		if err := putEpochSuperseded(native, chainID, heights.Heights[i-1]); err != nil {
+			return err
+		}
+	}
+	// a set put before the latest one is replaced already
+	if i < len(heights.Heights) {
+		if err := putEpochSuperseded(native, chainID, height); err != nil {
+			return err
+		}
+	}
+	heights.Heights = append(heights.Heights, 0)
+	copy(heights.Heights[i+1:], heights.Heights[i:])
+	heights.Heights[i] = height
+	sink := common.NewZeroCopySink(nil)
+	heights.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(EPOCH_HEIGHTS),
+		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
+
+// FindEpochHeight returns the latest validator set change of chainID not above height, false is returned
+// if no change was recorded for chainID, then the current set is the only one known. A set replaced over
+// EPOCH_TRUSTING_PERIOD ago is not trusted any more, and an error is returned for the heights of it.
+func FindEpochHeight(native *native.NativeService, chainID, height uint64) (uint64, bool, error) {
+	heights, err := GetEpochHeights(native, chainID)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(heights.Heights) == 0 {
+		return 0, false, nil
+	}
+	i := sort.Search(len(heights.Heights), func(i int) bool { return heights.Heights[i] > height })
+	if i == 0 {
+		return 0, false, fmt.Errorf("FindEpochHeight, height %d is lower than the first epoch height %d of chain %d",
+			height, heights.Heights[0], chainID)
+	}
+	epoch := heights.Heights[i-1]
+	if i == len(heights.Heights) {
+		return epoch, true, nil
+	}
+	superseded, ok, err := getEpochSuperseded(native, chainID, epoch)
+	if err != nil {
+		return 0, false, err
+	}
+	if !ok || uint64(native.GetBlockTime()) > uint64(superseded)+EPOCH_TRUSTING_PERIOD {
+		return 0, false, fmt.Errorf("FindEpochHeight, validator set at height %d of chain %d is out of the trusting period",
+			epoch, chainID)
+	}
+	return epoch, true, nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindEpochHeight(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	ns, err := native.NewNativeService(db, &types.Transaction{}, 0, 0, common.Uint256{}, 0, nil, false)
+	assert.NoError(t, err)
+
+	// nothing recorded, the current set is used
+	_, ok, err := FindEpochHeight(ns, 4, 100)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	for _, height := range []uint64{200, 100, 300, 200} {
+		assert.NoError(t, PutEpochHeight(ns, 4, height))
+	}
+	heights, err := GetEpochHeights(ns, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{100, 200, 300}, heights.Heights)
+
+	for height, expected := range map[uint64]uint64{100: 100, 199: 100, 200: 200, 1000: 300} {
+		epoch, ok, err := FindEpochHeight(ns, 4, height)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, expected, epoch)
+	}
+	_, _, err = FindEpochHeight(ns, 4, 99)
+	assert.Error(t, err)
+
+	// the replaced sets are not trusted after the trusting period
+	later, err := native.NewNativeService(db, &types.Transaction{}, EPOCH_TRUSTING_PERIOD+1, 0, common.Uint256{}, 0, nil, false)
+	assert.NoError(t, err)
+	for _, height := range []uint64{100, 250} {
+		_, _, err = FindEpochHeight(later, 4, height)
+		assert.Error(t, err)
+	}
+	epoch, ok, err := FindEpochHeight(later, 4, 1000)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(300), epoch)
+}
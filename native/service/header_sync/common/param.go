@@ -182,3 +182,82 @@ func NotifyPutCrossChainMsg(native *native.NativeService, chainID uint64, height
 			States:          []interface{}{SYNC_CROSSCHAIN_MSG, chainID, height, native.GetHeight()},
 		})
 }
+
+// SetVerifyParamsParam publishes the verification parameters of ChainID effective from EffectiveHeight
+type SetVerifyParamsParam struct {
+	ChainID         uint64
+	EffectiveHeight uint64
+	Params          []byte
+	Address         common.Address
+}
+
+func (this *SetVerifyParamsParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ChainID)
+	sink.WriteUint64(this.EffectiveHeight)
+	sink.WriteVarBytes(this.Params)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *SetVerifyParamsParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("SetVerifyParamsParam deserialize chain id error")
+	}
+	effectiveHeight, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("SetVerifyParamsParam deserialize effective height error")
+	}
+	params, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SetVerifyParamsParam deserialize params error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SetVerifyParamsParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("SetVerifyParamsParam, common.AddressParseFromBytes error: %v", err)
+	}
+	this.ChainID = chainID
+	this.EffectiveHeight = effectiveHeight
+	this.Params = params
+	this.Address = addr
+	return nil
+}
+
+// AttestGenesisHeaderParam is the attestation of the consensus peer of Address on the genesis header of ChainID
+type AttestGenesisHeaderParam struct {
+	ChainID       uint64
+	GenesisHeader []byte
+	Address       common.Address
+}
+
+func (this *AttestGenesisHeaderParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ChainID)
+	sink.WriteVarBytes(this.GenesisHeader)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *AttestGenesisHeaderParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("AttestGenesisHeaderParam deserialize chain id error")
+	}
+	genesisHeader, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("AttestGenesisHeaderParam deserialize genesis header error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("AttestGenesisHeaderParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("AttestGenesisHeaderParam, common.AddressParseFromBytes error: %v", err)
+	}
+	this.ChainID = chainID
+	this.GenesisHeader = genesisHeader
+	this.Address = addr
+	return nil
+}
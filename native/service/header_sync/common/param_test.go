@@ -56,3 +56,59 @@ func TestSyncBlockHeaderParam(t *testing.T) {
 
 	assert.Equal(t, p, param)
 }
+
+func TestHeaderCommitment(t *testing.T) {
+	c := HeaderCommitment{
+		ChainID:    2,
+		Height:     100,
+		Hash:       []byte{1, 2, 3},
+		PolyHeight: 10,
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	c.Serialization(sink)
+
+	var commitment HeaderCommitment
+	err := commitment.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, c, commitment)
+}
+
+func TestAttestGenesisHeaderParam(t *testing.T) {
+	p := AttestGenesisHeaderParam{
+		ChainID:       2,
+		GenesisHeader: []byte{1, 2, 3},
+		Address:       common.Address{1},
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	p.Serialization(sink)
+
+	var param AttestGenesisHeaderParam
+	err := param.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, p, param)
+}
+
+func TestSetVerifyParamsParam(t *testing.T) {
+	p := SetVerifyParamsParam{
+		ChainID:         2,
+		EffectiveHeight: 100,
+		Params:          []byte(`{"chainID":56}`),
+		Address:         common.Address{1},
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	p.Serialization(sink)
+
+	var param SetVerifyParamsParam
+	err := param.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, p, param)
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/treasury"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	HEADER_RELAYER         = "headerRelayer"
+	HEADER_REWARDED_HEIGHT = "headerRewardedHeight"
+)
+
+// headerRelayer returns the registered relayer signing the tx, headers relayed by others are not rewarded
+func headerRelayer(native *native.NativeService) (common.Address, bool, error) {
+	addrs, err := native.GetTx().GetSignatureAddresses()
+	if err != nil {
+		return common.ADDRESS_EMPTY, false, fmt.Errorf("get signature addresses error: %v", err)
+	}
+	for _, addr := range addrs {
+		value, err := native.GetCacheDB().Get(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(relayer_manager.RELAYER), addr[:]))
+		if err != nil {
+			return common.ADDRESS_EMPTY, false, fmt.Errorf("get relayer error: %v", err)
+		}
+		if value != nil {
+			return addr, true, nil
+		}
+	}
+	return common.ADDRESS_EMPTY, false, nil
+}
+
+func headerRelayerKey(chainID, height uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_RELAYER), utils.GetUint64Bytes(chainID),
+		utils.GetUint64Bytes(height))
+}
+
+// GetRewardedHeight returns the highest header of chainID recorded for the reward
+func GetRewardedHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_REWARDED_HEIGHT),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return 0, fmt.Errorf("GetRewardedHeight, get rewarded height store error: %v", err)
+	}
+	if store == nil {
+		return 0, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("GetRewardedHeight, deserialize from raw storage item err: %v", err)
+	}
+	return utils.GetBytesUint64(raw), nil
+}
+
+// rewardHeader records the relayer who lands the canonical header of chainID at height first and pays
+// the one recorded for the header Depth blocks below. Only the heights above the highest recorded are
+// taken, so a relayer gains nothing from reorganizing the headers, and the reward of a header is
+// dropped if it's no longer canonical when paid.
+func rewardHeader(native *native.NativeService, chainID, height uint64, hash []byte) error {
+	reward, err := treasury.GetHeaderReward(native, chainID)
+	if err != nil {
+		return err
+	}
+	if reward == nil {
+		return nil
+	}
+	highest, err := GetRewardedHeight(native, chainID)
+	if err != nil {
+		return err
+	}
+	if height <= highest {
+		return nil
+	}
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_REWARDED_HEIGHT),
+		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(height)))
+	// the first header recorded, usually the genesis one, is the base line of the reward
+	if highest == 0 {
+		return nil
+	}
+
+	relayer, ok, err := headerRelayer(native)
+	if err != nil {
+		return err
+	}
+	if ok {
+		sink := common.NewZeroCopySink(nil)
+		sink.WriteAddress(relayer)
+		sink.WriteVarBytes(hash)
+		native.GetCacheDB().Put(headerRelayerKey(chainID, height), cstates.GenRawStorageItem(sink.Bytes()))
+	}
+
+	if height <= reward.Depth {
+		return nil
+	}
+	return payHeaderReward(native, chainID, height-reward.Depth)
+}
+
+func payHeaderReward(native *native.NativeService, chainID, height uint64) error {
+	key := headerRelayerKey(chainID, height)
+	store, err := native.GetCacheDB().Get(key)
+	if err != nil {
+		return fmt.Errorf("payHeaderReward, get header relayer store error: %v", err)
+	}
+	if store == nil {
+		return nil
+	}
+	native.GetCacheDB().Delete(key)
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return fmt.Errorf("payHeaderReward, deserialize from raw storage item err: %v", err)
+	}
+	source := common.NewZeroCopySource(raw)
+	relayer, eof := source.NextAddress()
+	if eof {
+		return fmt.Errorf("payHeaderReward, deserialize relayer error")
+	}
+	hash, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("payHeaderReward, deserialize hash error")
+	}
+	commitment, err := GetHeaderCommitment(native, chainID, height)
+	if err != nil {
+		return err
+	}
+	if commitment == nil || !bytes.Equal(commitment.Hash, hash) {
+		return nil
+	}
+	return treasury.PayHeaderReward(native, chainID, height, relayer)
+}
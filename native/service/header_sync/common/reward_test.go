@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/treasury"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewardHeader(t *testing.T) {
+	// header commitments are put from genesis on solo net
+	networkID := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkID }()
+
+	relayer := common.Address{1}
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	ns, err := native.NewNativeService(db, &types.Transaction{SignedAddr: []common.Address{relayer}}, 0, 0,
+		common.Uint256{}, 0, nil, false)
+	assert.NoError(t, err)
+
+	credited := new(big.Int)
+	treasury.RegisterPayouts(nil, func(native *native.NativeService, to common.Address, chainID uint64, asset []byte,
+		amount *big.Int) error {
+		assert.Equal(t, relayer, to)
+		credited.Add(credited, amount)
+		return nil
+	})
+	defer treasury.RegisterPayouts(nil, nil)
+
+	asset := []byte{1, 2, 3}
+	assert.NoError(t, treasury.Credit(ns, treasury.INCOME_FEE, 2, asset, big.NewInt(25)))
+	reward := &treasury.HeaderReward{ChainID: 2, Asset: asset, Amount: big.NewInt(10), Depth: 2}
+	sink := common.NewZeroCopySink(nil)
+	reward.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.TreasuryContractAddress, []byte(treasury.HEADER_REWARD), utils.GetUint64Bytes(5)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	db.Put(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(relayer_manager.RELAYER), relayer[:]),
+		cstates.GenRawStorageItem([]byte{1}))
+
+	balance := func() int64 {
+		b, err := treasury.GetBalance(ns, 2, asset)
+		assert.NoError(t, err)
+		return b.Int64()
+	}
+
+	// the base line and the headers above it
+	for height := uint64(10); height <= 13; height++ {
+		PutHeaderCommitment(ns, 5, height, []byte{byte(height)})
+	}
+	assert.Equal(t, int64(15), balance(), "header 11 is paid when header 13 lands")
+	// headers 12 and 13 are reorganized out before they settle, and relaying the heights again pays nothing
+	PutHeaderCommitment(ns, 5, 12, []byte{0xff})
+	PutHeaderCommitment(ns, 5, 13, []byte{0xfe})
+	height, err := GetRewardedHeight(ns, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(13), height)
+	PutHeaderCommitment(ns, 5, 14, []byte{14})
+	PutHeaderCommitment(ns, 5, 15, []byte{15})
+	assert.Equal(t, int64(15), balance(), "headers 12 and 13 are no longer canonical")
+	PutHeaderCommitment(ns, 5, 16, []byte{16})
+	assert.Equal(t, int64(5), balance())
+	PutHeaderCommitment(ns, 5, 17, []byte{17})
+	assert.Equal(t, int64(5), balance(), "treasury runs short")
+	assert.Equal(t, int64(20), credited.Int64(), "the rewards are credited to the relayer")
+
+	// headers of other chains are not rewarded
+	PutHeaderCommitment(ns, 6, 10, []byte{10})
+	height, err = GetRewardedHeight(ns, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), height)
+
+	// every commitment is counted, the reorganized headers included
+	count, err := GetHeaderCount(ns, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), count)
+	count, err = GetHeaderCount(ns, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const VERIFY_PARAMS = "verifyParams"
+
+// VerifyParams are the verification parameters of a side chain engine published by governance, e.g.
+// the signer set, the epoch or the chain id of a PoSA chain. They are encoded as the ExtraInfo of the
+// side chain and take its place for the headers from EffectiveHeight on, so that a fork upgrade of the
+// side chain doesn't need a release of poly.
+type VerifyParams struct {
+	EffectiveHeight uint64
+	Params          []byte
+}
+
+// VerifyParamsList is ordered by EffectiveHeight
+type VerifyParamsList []*VerifyParams
+
+func (this VerifyParamsList) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this)))
+	for _, v := range this {
+		sink.WriteUint64(v.EffectiveHeight)
+		sink.WriteVarBytes(v.Params)
+	}
+}
+
+func (this *VerifyParamsList) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("VerifyParamsList deserialize length error")
+	}
+	list := make(VerifyParamsList, 0, n)
+	for i := uint64(0); i < n; i++ {
+		effectiveHeight, eof := source.NextUint64()
+		if eof {
+			return fmt.Errorf("VerifyParamsList deserialize effective height error")
+		}
+		params, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("VerifyParamsList deserialize params error")
+		}
+		list = append(list, &VerifyParams{EffectiveHeight: effectiveHeight, Params: params})
+	}
+	*this = list
+	return nil
+}
+
+// GetVerifyParamsList returns the verification parameters published for chainID
+func GetVerifyParamsList(native *native.NativeService, chainID uint64) (VerifyParamsList, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(VERIFY_PARAMS),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("GetVerifyParamsList, get verify params store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetVerifyParamsList, deserialize from raw storage item err: %v", err)
+	}
+	list := new(VerifyParamsList)
+	if err := list.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetVerifyParamsList, deserialize verify params error: %v", err)
+	}
+	return *list, nil
+}
+
+// AddVerifyParams publishes params for the headers of chainID from effectiveHeight on, the effective
+// height should be above the ones published before
+func AddVerifyParams(native *native.NativeService, chainID, effectiveHeight uint64, params []byte) error {
+	list, err := GetVerifyParamsList(native, chainID)
+	if err != nil {
+		return fmt.Errorf("AddVerifyParams, %v", err)
+	}
+	if len(list) > 0 && list[len(list)-1].EffectiveHeight >= effectiveHeight {
+		return fmt.Errorf("AddVerifyParams, effective height %d is not above the last published %d",
+			effectiveHeight, list[len(list)-1].EffectiveHeight)
+	}
+	list = append(list, &VerifyParams{EffectiveHeight: effectiveHeight, Params: params})
+	sink := common.NewZeroCopySink(nil)
+	list.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(VERIFY_PARAMS),
+		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
+
+// GetVerifyParams returns the verification parameters of chainID in effect at height, extraInfo of the
+// side chain is returned if none is published for height
+func GetVerifyParams(native *native.NativeService, chainID, height uint64, extraInfo []byte) ([]byte, error) {
+	list, err := GetVerifyParamsList(native, chainID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i].EffectiveHeight <= height {
+			return list[i].Params, nil
+		}
+	}
+	return extraInfo, nil
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyParams(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	ns, err := native.NewNativeService(db, new(types.Transaction), 0, 0, common.Uint256{}, 0, nil, false)
+	assert.NoError(t, err)
+
+	extraInfo := []byte("default")
+	params, err := GetVerifyParams(ns, 2, 100, extraInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, extraInfo, params)
+
+	assert.NoError(t, AddVerifyParams(ns, 2, 100, []byte("fork1")))
+	assert.NoError(t, AddVerifyParams(ns, 2, 200, []byte("fork2")))
+	assert.Error(t, AddVerifyParams(ns, 2, 200, []byte("fork3")), "effective height should increase")
+
+	for height, expected := range map[uint64][]byte{99: extraInfo, 100: []byte("fork1"), 199: []byte("fork1"), 300: []byte("fork2")} {
+		params, err := GetVerifyParams(ns, 2, height, extraInfo)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, params, "height %d", height)
+	}
+	params, err = GetVerifyParams(ns, 3, 300, extraInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, extraInfo, params, "params are per chain")
+}
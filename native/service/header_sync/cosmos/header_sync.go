@@ -80,12 +80,14 @@ func (this *CosmosHandler) SyncGenesisHeader(native *native.NativeService) error
 	if err == nil && info != nil {
 		return fmt.Errorf("CosmosHandler SyncGenesisHeader, genesis header had been initialized")
 	}
-	PutEpochSwitchInfo(native, param.ChainID, &CosmosEpochSwitchInfo{
+	if err := PutEpochSwitchInfo(native, param.ChainID, &CosmosEpochSwitchInfo{
 		Height:             header.Header.Height,
 		NextValidatorsHash: header.Header.NextValidatorsHash,
 		ChainID:            header.Header.ChainID,
 		BlockHash:          header.Header.Hash(),
-	})
+	}); err != nil {
+		return fmt.Errorf("CosmosHandler SyncGenesisHeader, %v", err)
+	}
 	return nil
 }
 
@@ -120,12 +122,15 @@ func (this *CosmosHandler) SyncBlockHeader(native *native.NativeService) error {
 		info.NextValidatorsHash = myHeader.Header.NextValidatorsHash
 		info.Height = myHeader.Header.Height
 		info.BlockHash = myHeader.Header.Hash()
+		// every switch is kept by its height for the proofs made before the next one
+		if err := PutEpochSwitchInfo(native, params.ChainID, info); err != nil {
+			return fmt.Errorf("SyncBlockHeader, %v", err)
+		}
 		cnt++
 	}
 	if cnt == 0 {
 		return fmt.Errorf("no header you commited is useful")
 	}
-	PutEpochSwitchInfo(native, params.ChainID, info)
 	return nil
 }
 
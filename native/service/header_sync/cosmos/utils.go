@@ -27,10 +27,23 @@ import (
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/event"
 	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/precompile"
 	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/tendermint/tendermint/crypto/ed25519"
 	"github.com/tendermint/tendermint/types"
 )
 
+// the crypto primitive the commit verification relies on, the keys of other types and the
+// header hashes are left to tendermint
+var ed25519Verify = precompile.MustVerify("header_sync/cosmos", precompile.ED25519_VERIFY)
+
+func verifyCommitSig(val *types.Validator, msg, sig []byte) bool {
+	if pk, ok := val.PubKey.(ed25519.PubKeyEd25519); ok {
+		return ed25519Verify(pk[:], msg, sig)
+	}
+	return val.PubKey.VerifyBytes(msg, sig)
+}
+
 func notifyEpochSwitchInfo(native *native.NativeService, chainID uint64, info *CosmosEpochSwitchInfo) {
 	if !config.DefConfig.Common.EnableEventLog {
 		return
@@ -60,13 +73,48 @@ func GetEpochSwitchInfo(service *native.NativeService, chainId uint64) (*CosmosE
 	return info, nil
 }
 
-func PutEpochSwitchInfo(service *native.NativeService, chainId uint64, info *CosmosEpochSwitchInfo) {
+// GetEpochSwitchInfoByHeight returns the epoch switch info effective at height, which is the latest switch not
+// above it, the current one is returned for the chains synced before the switches were kept by height
+func GetEpochSwitchInfoByHeight(service *native.NativeService, chainId uint64, height int64) (*CosmosEpochSwitchInfo, error) {
+	switchHeight, ok, err := hscommon.FindEpochHeight(service, chainId, uint64(height))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return GetEpochSwitchInfo(service, chainId)
+	}
+	val, err := service.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH),
+		utils.GetUint64Bytes(chainId), utils.GetUint64Bytes(switchHeight)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get epoch switch info at height %d: %v", switchHeight, err)
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(val)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize bytes from raw storage item err: %v", err)
+	}
+	info := &CosmosEpochSwitchInfo{}
+	if err = info.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("failed to deserialize CosmosEpochSwitchInfo: %v", err)
+	}
+	return info, nil
+}
+
+// PutEpochSwitchInfo puts info as the current epoch switch info, and keeps it by its height as well
+func PutEpochSwitchInfo(service *native.NativeService, chainId uint64, info *CosmosEpochSwitchInfo) error {
 	sink := common.NewZeroCopySink(nil)
 	info.Serialization(sink)
+	chainIdBytes := utils.GetUint64Bytes(chainId)
+	service.GetCacheDB().Put(
+		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH), chainIdBytes),
+		cstates.GenRawStorageItem(sink.Bytes()))
 	service.GetCacheDB().Put(
-		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH), utils.GetUint64Bytes(chainId)),
+		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH), chainIdBytes, utils.GetUint64Bytes(uint64(info.Height))),
 		cstates.GenRawStorageItem(sink.Bytes()))
+	if err := hscommon.PutEpochHeight(service, chainId, uint64(info.Height)); err != nil {
+		return err
+	}
 	notifyEpochSwitchInfo(service, chainId, info)
+	return nil
 }
 
 func VerifyCosmosHeader(myHeader *CosmosHeader, info *CosmosEpochSwitchInfo) error {
@@ -102,7 +150,7 @@ func VerifyCosmosHeader(myHeader *CosmosHeader, info *CosmosEpochSwitchInfo) err
 		_, val := valset.GetByIndex(idx)
 		// Validate signature.
 		precommitSignBytes := myHeader.Commit.VoteSignBytes(info.ChainID, idx)
-		if !val.PubKey.VerifyBytes(precommitSignBytes, commitSig.Signature) {
+		if !verifyCommitSig(val, precommitSignBytes, commitSig.Signature) {
 			return fmt.Errorf("VerifyCosmosHeader, Invalid commit -- invalid signature: %v", commitSig)
 		}
 		// Good precommit!
@@ -18,14 +18,21 @@
 package header_sync
 
 import (
+	"encoding/hex"
 	"fmt"
 
+	"github.com/ontio/ontology-crypto/keypair"
+
 	"github.com/polynetwork/poly/native/service/header_sync/heco"
 	"github.com/polynetwork/poly/native/service/header_sync/msc"
 	"github.com/polynetwork/poly/native/service/header_sync/okex"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/bsc"
 	"github.com/polynetwork/poly/native/service/header_sync/btc"
@@ -35,6 +42,7 @@ import (
 	"github.com/polynetwork/poly/native/service/header_sync/neo"
 	"github.com/polynetwork/poly/native/service/header_sync/ont"
 	"github.com/polynetwork/poly/native/service/header_sync/quorum"
+	"github.com/polynetwork/poly/native/service/header_sync/zcash"
 	"github.com/polynetwork/poly/native/service/header_sync/zilliqa"
 	"github.com/polynetwork/poly/native/service/utils"
 )
@@ -43,18 +51,29 @@ const (
 	SYNC_GENESIS_HEADER  = "syncGenesisHeader"
 	SYNC_BLOCK_HEADER    = "syncBlockHeader"
 	SYNC_CROSS_CHAIN_MSG = "syncCrossChainMsg"
+	INIT_GENESIS_HEADER  = "initGenesisHeader"
+	SET_VERIFY_PARAMS    = "setVerifyParams"
+	ATTEST_GENESIS       = "attestGenesisHeader"
 )
 
+// syncGenesisHeader is guarded by the operator, the operator committee calls it through node_manager
+func init() {
+	node_manager.RegisterOperatorMethod(utils.HeaderSyncContractAddress, SYNC_GENESIS_HEADER)
+}
+
 //Register methods of node_manager contract
 func RegisterHeaderSyncContract(native *native.NativeService) {
 	native.Register(SYNC_GENESIS_HEADER, SyncGenesisHeader)
 	native.Register(SYNC_BLOCK_HEADER, SyncBlockHeader)
 	native.Register(SYNC_CROSS_CHAIN_MSG, SyncCrossChainMsg)
+	native.Register(INIT_GENESIS_HEADER, InitGenesisHeader)
+	native.Register(SET_VERIFY_PARAMS, SetVerifyParams)
+	native.Register(ATTEST_GENESIS, AttestGenesisHeader)
 }
 
 func GetChainHandler(router uint64) (hscommon.HeaderSyncHandler, error) {
 	switch router {
-	case utils.BTC_ROUTER:
+	case utils.BTC_ROUTER, utils.BCH_ROUTER:
 		return btc.NewBTCHandler(), nil
 	case utils.ETH_ROUTER:
 		return eth.NewETHHandler(), nil
@@ -76,6 +95,10 @@ func GetChainHandler(router uint64) (hscommon.HeaderSyncHandler, error) {
 		return msc.NewHandler(), nil
 	case utils.OKEX_ROUTER:
 		return okex.NewHandler(), nil
+	case utils.ZCASH_ROUTER:
+		return zcash.NewZcashHandler(), nil
+	case utils.QBFT_ROUTER:
+		return quorum.NewQBFTHandler(), nil
 	default:
 		return nil, fmt.Errorf("not a supported router:%d", router)
 	}
@@ -97,15 +120,150 @@ func SyncGenesisHeader(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("SyncGenesisHeader, side chain is not registered")
 	}
 
+	// the genesis headers of the bootstrap config are trusted by the genesis block
+	if !native.IsGenesis() {
+		if err := checkGenesisAttested(native, chainID, params.GenesisHeader); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("SyncGenesisHeader, %v", err)
+		}
+	}
+
 	handler, err := GetChainHandler(sideChain.Router)
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
 
-	err = handler.SyncGenesisHeader(native)
+	err = invokeHandler(native, "SyncGenesisHeader", handler.SyncGenesisHeader)
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
+	hscommon.DeleteGenesisAttestation(native, chainID, params.GenesisHeader)
+	return utils.BYTE_TRUE, nil
+}
+
+// AttestGenesisHeader records the attestation of a consensus peer on the genesis header of a side chain,
+// syncGenesisHeader accepts the header once two thirds of the consensus peers attest the same bytes.
+func AttestGenesisHeader(native *native.NativeService) ([]byte, error) {
+	params := new(hscommon.AttestGenesisHeaderParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("AttestGenesisHeader, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Address); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("AttestGenesisHeader, checkWitness error: %v", err)
+	}
+
+	sideChain, err := side_chain_manager.GetSideChain(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("AttestGenesisHeader, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("AttestGenesisHeader, side chain is not registered")
+	}
+
+	peers, err := consensusAddresses(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("AttestGenesisHeader, %v", err)
+	}
+	if !peers[params.Address] {
+		return utils.BYTE_FALSE, fmt.Errorf("AttestGenesisHeader, %s is not a consensus peer", params.Address.ToBase58())
+	}
+	attestation, err := hscommon.GetGenesisAttestation(native, params.ChainID, params.GenesisHeader)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("AttestGenesisHeader, %v", err)
+	}
+	if !attestation.Add(params.Address) {
+		return utils.BYTE_FALSE, fmt.Errorf("AttestGenesisHeader, %s has attested the header", params.Address.ToBase58())
+	}
+	hscommon.PutGenesisAttestation(native, params.ChainID, params.GenesisHeader, attestation)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.HeaderSyncContractAddress,
+			States: []interface{}{ATTEST_GENESIS, params.ChainID, hex.EncodeToString(params.GenesisHeader),
+				params.Address.ToBase58(), len(attestation.Signers)},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// consensusAddresses returns the addresses of the peers in the consensus of the current view
+func consensusAddresses(native *native.NativeService) (map[common.Address]bool, error) {
+	view, err := node_manager.GetView(native)
+	if err != nil {
+		return nil, fmt.Errorf("GetView error: %v", err)
+	}
+	peerPoolMap, err := node_manager.GetPeerPoolMap(native, view)
+	if err != nil {
+		return nil, fmt.Errorf("GetPeerPoolMap error: %v", err)
+	}
+	addrs := make(map[common.Address]bool)
+	for key, v := range peerPoolMap.PeerPoolMap {
+		if v.Status != node_manager.ConsensusStatus {
+			continue
+		}
+		k, err := hex.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("hex.DecodeString public key error: %v", err)
+		}
+		publicKey, err := keypair.DeserializePublicKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("keypair.DeserializePublicKey error: %v", err)
+		}
+		addrs[types.AddressFromPubKey(publicKey)] = true
+	}
+	return addrs, nil
+}
+
+// checkGenesisAttested checks that two thirds of the current consensus peers attested the genesis
+// header, the attestations of the peers out of the consensus are not counted
+func checkGenesisAttested(native *native.NativeService, chainID uint64, header []byte) error {
+	peers, err := consensusAddresses(native)
+	if err != nil {
+		return err
+	}
+	attestation, err := hscommon.GetGenesisAttestation(native, chainID, header)
+	if err != nil {
+		return err
+	}
+	num := 0
+	for _, signer := range attestation.Signers {
+		if peers[signer] {
+			num++
+		}
+	}
+	if num < (2*len(peers)+2)/3 {
+		return fmt.Errorf("genesis header is attested by %d of %d consensus peers", num, len(peers))
+	}
+	return nil
+}
+
+// InitGenesisHeader syncs the genesis headers of the side chains of the bootstrap genesis config, it is
+// only run by the genesis block after the side chains are registered
+func InitGenesisHeader(native *native.NativeService) ([]byte, error) {
+	if !native.IsGenesis() {
+		return utils.BYTE_FALSE, fmt.Errorf("InitGenesisHeader, only allowed in genesis block")
+	}
+	configuration := new(config.BootstrapConfig)
+	if err := configuration.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InitGenesisHeader, contract params deserialize error: %v", err)
+	}
+	for _, v := range configuration.SideChains {
+		if v.GenesisHeader == "" {
+			continue
+		}
+		header, err := hex.DecodeString(v.GenesisHeader)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesisHeader, decode genesis header of chain %d error: %v", v.ChainId, err)
+		}
+		params := &hscommon.SyncGenesisHeaderParam{
+			ChainID:       v.ChainId,
+			GenesisHeader: header,
+		}
+		sink := common.NewZeroCopySink(nil)
+		params.Serialization(sink)
+		if _, err := native.NativeCall(utils.HeaderSyncContractAddress, SYNC_GENESIS_HEADER, sink.Bytes()); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesisHeader, sync genesis header of chain %d error: %v", v.ChainId, err)
+		}
+	}
 	return utils.BYTE_TRUE, nil
 }
 
@@ -130,7 +288,7 @@ func SyncBlockHeader(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, err
 	}
 
-	err = handler.SyncBlockHeader(native)
+	err = invokeHandler(native, "SyncBlockHeader", handler.SyncBlockHeader)
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
@@ -158,9 +316,63 @@ func SyncCrossChainMsg(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, err
 	}
 
-	err = handler.SyncCrossChainMsg(native)
+	err = invokeHandler(native, "SyncCrossChainMsg", handler.SyncCrossChainMsg)
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
 	return utils.BYTE_TRUE, nil
 }
+
+// invokeHandler runs method of a header sync handler, a panic of the handler on the untrusted headers
+// fails the sync like any error instead of the block execution
+func invokeHandler(native *native.NativeService, method string, handle func(*native.NativeService) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s, handler panics: %v", method, r)
+		}
+	}()
+	return handle(native)
+}
+
+// SetVerifyParams publishes the verification parameters of a side chain engine effective from a height of
+// the side chain, approved by the consensus nodes. Headers already synced are not verified again, so the
+// effective height should be above the synced height of the chain.
+func SetVerifyParams(native *native.NativeService) ([]byte, error) {
+	params := new(hscommon.SetVerifyParamsParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetVerifyParams, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetVerifyParams, checkWitness error: %v", err)
+	}
+
+	sideChain, err := side_chain_manager.GetSideChain(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetVerifyParams, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetVerifyParams, side chain is not registered")
+	}
+
+	//check consensus signs
+	ok, err := node_manager.CheckConsensusSigns(native, SET_VERIFY_PARAMS, native.GetInput(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetVerifyParams, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	if err := hscommon.AddVerifyParams(native, params.ChainID, params.EffectiveHeight, params.Params); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetVerifyParams, %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.HeaderSyncContractAddress,
+			States:          []interface{}{SET_VERIFY_PARAMS, params.ChainID, params.EffectiveHeight, hex.EncodeToString(params.Params)},
+		})
+	return utils.BYTE_TRUE, nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"golang.org/x/crypto/sha3"
 	"hash"
 	"math/big"
@@ -102,6 +103,10 @@ func (this *ETHHandler) SyncBlockHeader(native *native.NativeService) error {
 	if err := headerParams.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
 		return fmt.Errorf("SyncBlockHeader, contract params deserialize error: %v", err)
 	}
+	londonHeight, london, err := side_chain_manager.GetForkHeight(native, headerParams.ChainID, LONDON_FORK)
+	if err != nil {
+		return fmt.Errorf("SyncBlockHeader, get fork height error: %v", err)
+	}
 	caches := NewCaches(3, native)
 	for _, v := range headerParams.Headers {
 		var header cty.Header
@@ -157,13 +162,18 @@ func (this *ETHHandler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("SyncBlockHeader, invalid gasUsed: have %d, gasLimit %d, header: %s", header.GasUsed, header.GasLimit, string(v))
 		}
 		// GasLimit adjustment range 0.0976%（=1/1024 ）
-		diff := int64(parentHeader.GasLimit) - int64(header.GasLimit)
+		parentGasLimit := parentHeader.GasLimit
+		// the gas limit is scaled by the elasticity multiplier at the london block, EIP-1559
+		if london && header.Number.Uint64() == londonHeight {
+			parentGasLimit = parentGasLimit * elasticityMultiplier
+		}
+		diff := int64(parentGasLimit) - int64(header.GasLimit)
 		if diff < 0 {
 			diff *= -1
 		}
-		limit := parentHeader.GasLimit / params.GasLimitBoundDivisor
+		limit := parentGasLimit / params.GasLimitBoundDivisor
 		if uint64(diff) >= limit || header.GasLimit < params.MinGasLimit {
-			return fmt.Errorf("SyncBlockHeader, invalid gas limit: have %d, want %d += %d, header: %s", header.GasLimit, parentHeader.GasLimit, limit, string(v))
+			return fmt.Errorf("SyncBlockHeader, invalid gas limit: have %d, want %d += %d, header: %s", header.GasLimit, parentGasLimit, limit, string(v))
 		}
 		//verify difficulty
 		expected := difficultyCalculator(new(big.Int).SetUint64(header.Time), parentHeader)
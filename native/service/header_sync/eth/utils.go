@@ -49,6 +49,10 @@ const (
 	cacheInitBytes         = 1 << 24
 	cacheGrowthBytes       = 1 << 17
 	cacheRounds            = 3
+
+	// name of the london fork in the fork schedule of the side chain
+	LONDON_FORK          = "london"
+	elasticityMultiplier = 2
 )
 
 type HeaderWithDifficultySum struct {
@@ -69,6 +73,7 @@ func putGenesisBlockHeader(native *native.NativeService, blockHeader types.Heade
 		cstates.GenRawStorageItem(storeBytes))
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(blockHeader.Number.Uint64())),
 		cstates.GenRawStorageItem(blockHeader.Hash().Bytes()))
+	scom.PutHeaderCommitment(native, chainID, blockHeader.Number.Uint64(), blockHeader.Hash().Bytes())
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.CURRENT_HEADER_HEIGHT),
 		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(blockHeader.Number.Uint64())))
 	scom.NotifyPutHeader(native, chainID, blockHeader.Number.Uint64(), blockHeader.Hash().String())
@@ -90,6 +95,7 @@ func appendHeader2Main(native *native.NativeService, height uint64, txhash commo
 	contract := utils.HeaderSyncContractAddress
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)),
 		cstates.GenRawStorageItem(txhash.Bytes()))
+	scom.PutHeaderCommitment(native, chainID, height, txhash.Bytes())
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.CURRENT_HEADER_HEIGHT),
 		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(height)))
 	scom.NotifyPutHeader(native, chainID, height, txhash.String())
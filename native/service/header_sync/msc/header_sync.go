@@ -36,9 +36,16 @@ import (
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/precompile"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
+// the crypto primitives the header verification relies on
+var (
+	secp256k1Recover = precompile.MustRecover("header_sync/msc", precompile.SECP256K1_RECOVER)
+	keccak256        = precompile.MustHash("header_sync/msc", precompile.KECCAK256)
+)
+
 // Handler ...
 type Handler struct {
 }
@@ -210,13 +217,7 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 	if err != nil {
 		return fmt.Errorf("msc Handler SyncBlockHeader, GetSideChain error: %v", err)
 	}
-	var extraInfo ExtraInfo
-	err = json.Unmarshal(side.ExtraInfo, &extraInfo)
-	if err != nil {
-		return fmt.Errorf("msc Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
-	}
-
-	ctx := &Context{ExtraInfo: extraInfo, ChainID: headerParams.ChainID}
+	ctx := &Context{ChainID: headerParams.ChainID}
 
 	for _, v := range headerParams.Headers {
 		var header types.Header
@@ -224,6 +225,15 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 		if err != nil {
 			return fmt.Errorf("msc Handler SyncBlockHeader, deserialize header err: %v", err)
 		}
+		// the parameters published by governance for the height take the place of the side chain ExtraInfo
+		extraInfo, err := scom.GetVerifyParams(native, headerParams.ChainID, header.Number.Uint64(), side.ExtraInfo)
+		if err != nil {
+			return fmt.Errorf("msc Handler SyncBlockHeader, GetVerifyParams error: %v", err)
+		}
+		ctx.ExtraInfo = ExtraInfo{}
+		if err := json.Unmarshal(extraInfo, &ctx.ExtraInfo); err != nil {
+			return fmt.Errorf("msc Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
+		}
 		headerHash := header.Hash()
 
 		exist, err := isHeaderExist(native, headerHash, ctx)
@@ -305,6 +315,7 @@ func GetCanonicalHeader(native *native.NativeService, chainID uint64, height uin
 
 func deleteCanonicalHash(native *native.NativeService, chainID uint64, height uint64) {
 	native.GetCacheDB().Delete(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)))
+	scom.DeleteHeaderCommitment(native, chainID, height)
 }
 
 func getCanonicalHash(native *native.NativeService, chainID uint64, height uint64) (hash ecommon.Hash, err error) {
@@ -330,6 +341,7 @@ func getCanonicalHash(native *native.NativeService, chainID uint64, height uint6
 func putCanonicalHash(native *native.NativeService, chainID uint64, height uint64, hash ecommon.Hash) {
 	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)),
 		cstates.GenRawStorageItem(hash.Bytes()))
+	scom.PutHeaderCommitment(native, chainID, height, hash.Bytes())
 }
 
 func putHeaderWithSum(native *native.NativeService, chainID uint64, headerWithSum *HeaderWithDifficultySum) (err error) {
@@ -795,12 +807,12 @@ func ecrecover(header *types.Header) (ecommon.Address, error) {
 	signature := header.Extra[len(header.Extra)-extraSeal:]
 
 	// Recover the public key and the Ethereum address
-	pubkey, err := crypto.Ecrecover(clique.SealHash(header).Bytes(), signature)
+	pubkey, err := secp256k1Recover(clique.SealHash(header).Bytes(), signature)
 	if err != nil {
 		return ecommon.Address{}, err
 	}
 	var signer ecommon.Address
-	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	copy(signer[:], keccak256(pubkey[1:])[12:])
 
 	return signer, nil
 }
@@ -76,7 +76,7 @@ func (this *NEOHandler) SyncBlockHeader(native *native.NativeService) error {
 	if err != nil {
 		return fmt.Errorf("SyncBlockHeader, the consensus validator has not been initialized, chainId: %d", params.ChainID)
 	}
-	var newNeoConsensus *NeoConsensus
+	// every change is kept by its height for the msgs signed before the next one
 	for _, v := range params.Headers {
 		header := new(NeoBlockHeader)
 		if err := header.Deserialization(common.NewZeroCopySource(v)); err != nil {
@@ -86,16 +86,14 @@ func (this *NEOHandler) SyncBlockHeader(native *native.NativeService) error {
 			if err = verifyHeader(native, params.ChainID, header); err != nil {
 				return fmt.Errorf("SyncBlockHeader, verifyHeader error: %v", err)
 			}
-			newNeoConsensus = &NeoConsensus{
+			neoConsensus = &NeoConsensus{
 				ChainID:       neoConsensus.ChainID,
 				Height:        header.Index,
 				NextConsensus: header.NextConsensus,
 			}
-		}
-	}
-	if newNeoConsensus != nil {
-		if err = putConsensusValByChainId(native, newNeoConsensus); err != nil {
-			return fmt.Errorf("SyncBlockHeader, update ConsensusPeer error: %v", err)
+			if err = putConsensusValByChainId(native, neoConsensus); err != nil {
+				return fmt.Errorf("SyncBlockHeader, update ConsensusPeer error: %v", err)
+			}
 		}
 	}
 	return nil
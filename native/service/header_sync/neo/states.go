@@ -21,13 +21,17 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/joeqian10/neo-gogogo/block"
-	"github.com/joeqian10/neo-gogogo/crypto"
 	"github.com/joeqian10/neo-gogogo/helper"
 	"github.com/joeqian10/neo-gogogo/helper/io"
 	"github.com/joeqian10/neo-gogogo/mpt"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native/service/precompile"
 )
 
+// the crypto primitive the consensus check relies on, the witness signatures are
+// verified by neo-gogogo itself
+var hash160 = precompile.MustHash("header_sync/neo", precompile.HASH160)
+
 type NeoConsensus struct {
 	ChainID       uint64
 	Height        uint32
@@ -126,7 +130,7 @@ func (this *NeoCrossChainMsg) GetScriptHash() (helper.UInt160, error) {
 	if len(verificationScriptBs) == 0 {
 		return helper.UInt160{}, fmt.Errorf("NeoCrossChainMsg.Witness.VerificationScript length is 0 ")
 	}
-	scriptHash, err := helper.UInt160FromBytes(crypto.Hash160(verificationScriptBs))
+	scriptHash, err := helper.UInt160FromBytes(hash160(verificationScriptBs))
 	if err != nil {
 		return helper.UInt160{}, fmt.Errorf("joeqian10/neo-gogogo/tx.Witness GetScriptHash error:%s", err)
 	}
@@ -49,8 +49,10 @@ func verifyHeader(native *native.NativeService, chainID uint64, header *NeoBlock
 	return nil
 }
 
+// VerifyCrossChainMsgSig verifies crossChainMsg against the consensus effective at its height, which is the
+// NextConsensus of the latest change below it, so that the msgs signed before a change can still be verified
 func VerifyCrossChainMsgSig(native *native.NativeService, chainID uint64, crossChainMsg *NeoCrossChainMsg) error {
-	neoConsensus, err := getConsensusValByChainId(native, chainID)
+	neoConsensus, err := getConsensusValByHeight(native, chainID, crossChainMsg.Index)
 	if err != nil {
 		return fmt.Errorf("verifyCrossChainMsg, get ConsensusPeer error:%v", err)
 	}
@@ -98,11 +100,51 @@ func getConsensusValByChainId(native *native.NativeService, chainID uint64) (*Ne
 	return neoConsensus, nil
 }
 
+// getConsensusValByHeight returns the consensus which signs the block at height, the current one is returned
+// for the chains synced before the consensus was kept by height
+func getConsensusValByHeight(native *native.NativeService, chainID uint64, height uint32) (*NeoConsensus, error) {
+	// the NextConsensus of a block signs the blocks above it
+	if height > 0 {
+		height--
+	}
+	keyHeight, ok, err := hscommon.FindEpochHeight(native, chainID, uint64(height))
+	if err != nil {
+		return nil, fmt.Errorf("getConsensusValByHeight, %v", err)
+	}
+	if !ok {
+		return getConsensusValByChainId(native, chainID)
+	}
+	contract := utils.HeaderSyncContractAddress
+	neoConsensusStore, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(hscommon.CONSENSUS_PEER),
+		utils.GetUint64Bytes(chainID), utils.GetUint32Bytes(uint32(keyHeight))))
+	if err != nil {
+		return nil, fmt.Errorf("getConsensusValByHeight, get neoConsensusStore error: %v", err)
+	}
+	if neoConsensusStore == nil {
+		return nil, fmt.Errorf("getConsensusValByHeight, can not find consensus at height %d", keyHeight)
+	}
+	neoConsensusBytes, err := cstates.GetValueFromRawStorageItem(neoConsensusStore)
+	if err != nil {
+		return nil, fmt.Errorf("getConsensusValByHeight, deserialize from raw storage item err:%v", err)
+	}
+	neoConsensus := new(NeoConsensus)
+	if err := neoConsensus.Deserialization(common.NewZeroCopySource(neoConsensusBytes)); err != nil {
+		return nil, fmt.Errorf("getConsensusValByHeight, deserialize consensusPeer error: %v", err)
+	}
+	return neoConsensus, nil
+}
+
+// putConsensusValByChainId puts neoConsensus as the current one, and keeps it by its height as well
 func putConsensusValByChainId(native *native.NativeService, neoConsensus *NeoConsensus) error {
 	contract := utils.HeaderSyncContractAddress
 	sink := common.NewZeroCopySink(nil)
 	neoConsensus.Serialization(sink)
 	chainIDBytes := utils.GetUint64Bytes(neoConsensus.ChainID)
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(hscommon.CONSENSUS_PEER), chainIDBytes), cstates.GenRawStorageItem(sink.Bytes()))
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(hscommon.CONSENSUS_PEER), chainIDBytes, utils.GetUint32Bytes(neoConsensus.Height)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	if err := hscommon.PutEpochHeight(native, neoConsensus.ChainID, uint64(neoConsensus.Height)); err != nil {
+		return fmt.Errorf("putConsensusValByChainId, %v", err)
+	}
 	return nil
 }
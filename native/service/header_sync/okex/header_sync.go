@@ -89,12 +89,14 @@ func (h *Handler) SyncGenesisHeader(native *native.NativeService) (err error) {
 	if err == nil && info != nil {
 		return fmt.Errorf("CosmosHandler SyncGenesisHeader, genesis header had been initialized")
 	}
-	PutEpochSwitchInfo(native, param.ChainID, &CosmosEpochSwitchInfo{
+	if err := PutEpochSwitchInfo(native, param.ChainID, &CosmosEpochSwitchInfo{
 		Height:             header.Header.Height,
 		NextValidatorsHash: header.Header.NextValidatorsHash,
 		ChainID:            header.Header.ChainID,
 		BlockHash:          header.Header.Hash(),
-	})
+	}); err != nil {
+		return fmt.Errorf("CosmosHandler SyncGenesisHeader, %v", err)
+	}
 	return nil
 }
 
@@ -129,12 +131,15 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 		info.NextValidatorsHash = myHeader.Header.NextValidatorsHash
 		info.Height = myHeader.Header.Height
 		info.BlockHash = myHeader.Header.Hash()
+		// every switch is kept by its height for the proofs made before the next one
+		if err := PutEpochSwitchInfo(native, params.ChainID, info); err != nil {
+			return fmt.Errorf("SyncBlockHeader, %v", err)
+		}
 		cnt++
 	}
 	if cnt == 0 {
 		return fmt.Errorf("no header you commited is useful")
 	}
-	PutEpochSwitchInfo(native, params.ChainID, info)
 	return nil
 }
 
@@ -160,13 +165,48 @@ func GetEpochSwitchInfo(service *native.NativeService, chainId uint64) (*CosmosE
 	return info, nil
 }
 
-func PutEpochSwitchInfo(service *native.NativeService, chainId uint64, info *CosmosEpochSwitchInfo) {
+// GetEpochSwitchInfoByHeight returns the epoch switch info effective at height, which is the latest switch not
+// above it, the current one is returned for the chains synced before the switches were kept by height
+func GetEpochSwitchInfoByHeight(service *native.NativeService, chainId uint64, height int64) (*CosmosEpochSwitchInfo, error) {
+	switchHeight, ok, err := hscommon.FindEpochHeight(service, chainId, uint64(height))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return GetEpochSwitchInfo(service, chainId)
+	}
+	val, err := service.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH),
+		utils.GetUint64Bytes(chainId), utils.GetUint64Bytes(switchHeight)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get epoch switch info at height %d: %v", switchHeight, err)
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(val)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize bytes from raw storage item err: %v", err)
+	}
+	info := &CosmosEpochSwitchInfo{}
+	if err = info.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("failed to deserialize CosmosEpochSwitchInfo: %v", err)
+	}
+	return info, nil
+}
+
+// PutEpochSwitchInfo puts info as the current epoch switch info, and keeps it by its height as well
+func PutEpochSwitchInfo(service *native.NativeService, chainId uint64, info *CosmosEpochSwitchInfo) error {
 	sink := common.NewZeroCopySink(nil)
 	info.Serialization(sink)
+	chainIdBytes := utils.GetUint64Bytes(chainId)
+	service.GetCacheDB().Put(
+		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH), chainIdBytes),
+		cstates.GenRawStorageItem(sink.Bytes()))
 	service.GetCacheDB().Put(
-		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH), utils.GetUint64Bytes(chainId)),
+		utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH), chainIdBytes, utils.GetUint64Bytes(uint64(info.Height))),
 		cstates.GenRawStorageItem(sink.Bytes()))
+	if err := hscommon.PutEpochHeight(service, chainId, uint64(info.Height)); err != nil {
+		return err
+	}
 	notifyEpochSwitchInfo(service, chainId, info)
+	return nil
 }
 
 func notifyEpochSwitchInfo(native *native.NativeService, chainID uint64, info *CosmosEpochSwitchInfo) {
@@ -84,6 +84,7 @@ func PutBlockHeader(native *native.NativeService, chainID uint64, blockHeader *o
 		cstates.GenRawStorageItem(sink.Bytes()))
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(hscommon.HEADER_INDEX), chainIDBytes, heightBytes),
 		cstates.GenRawStorageItem(blockHash.ToArray()))
+	hscommon.PutHeaderCommitment(native, chainID, uint64(blockHeader.Height), blockHash.ToArray())
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(hscommon.CURRENT_HEADER_HEIGHT), chainIDBytes),
 		cstates.GenRawStorageItem(heightBytes))
 	hscommon.NotifyPutHeader(native, chainID, uint64(blockHeader.Height), blockHash.ToHexString())
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	ecom "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	pcom "github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/native"
@@ -28,12 +29,50 @@ import (
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
-type QuorumHandler struct{}
+// QuorumHandler syncs the validators of istanbul chains, the legacy IBFT
+// and the QBFT share the same way and differ in the extra-data layout.
+type QuorumHandler struct {
+	qbft bool
+}
 
 func NewQuorumHandler() *QuorumHandler {
 	return &QuorumHandler{}
 }
 
+func NewQBFTHandler() *QuorumHandler {
+	return &QuorumHandler{qbft: true}
+}
+
+func (h *QuorumHandler) extractValidators(header *types.Header) ([]ecom.Address, error) {
+	if h.qbft {
+		extra, err := ExtractQBFTExtra(header)
+		if err != nil {
+			return nil, err
+		}
+		return extra.Validators, nil
+	}
+	extra, err := ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	return extra.Validators, nil
+}
+
+func (h *QuorumHandler) verifyHeader(vs QuorumValSet, header *types.Header) (QuorumValSet, error) {
+	if h.qbft {
+		extra, err := VerifyQBFTHeader(vs, header, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify qbft header %s: %v", GetQBFTHeaderHash(header).String(), err)
+		}
+		return extra.Validators, nil
+	}
+	extra, err := VerifyQuorumHeader(vs, header, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify quorum header %s: %v", GetQuorumHeaderHash(header).String(), err)
+	}
+	return extra.Validators, nil
+}
+
 func (h *QuorumHandler) SyncGenesisHeader(ns *native.NativeService) error {
 	params := new(common.SyncGenesisHeaderParam)
 	if err := params.Deserialization(pcom.NewZeroCopySource(ns.GetInput())); err != nil {
@@ -54,12 +93,14 @@ func (h *QuorumHandler) SyncGenesisHeader(ns *native.NativeService) error {
 	if err = json.Unmarshal(params.GenesisHeader, header); err != nil {
 		return fmt.Errorf("QuorumHandler SyncGenesisHeader, deserialize header err: %v", err)
 	}
-	extra, err := ExtractIstanbulExtra(header)
+	vals, err := h.extractValidators(header)
 	if err != nil {
-		return fmt.Errorf("QuorumHandler SyncGenesisHeader, failed to ExtractIstanbulExtra: %v", err)
+		return fmt.Errorf("QuorumHandler SyncGenesisHeader, failed to extract validators: %v", err)
 	}
 
-	putValSet(ns, params.ChainID, header.Number.Uint64(), extra.Validators)
+	if err := putValSet(ns, params.ChainID, header.Number.Uint64(), vals); err != nil {
+		return fmt.Errorf("QuorumHandler SyncGenesisHeader, failed to put validators: %v", err)
+	}
 	return nil
 }
 
@@ -86,20 +127,22 @@ func (h *QuorumHandler) SyncBlockHeader(ns *native.NativeService) error {
 		if err := json.Unmarshal(v, header); err != nil {
 			return fmt.Errorf("QuorumHandler SyncBlockHeader, deserialize No.%d header err: %v", i, err)
 		}
-		h := header.Number.Uint64()
-		if currh >= h {
-			return fmt.Errorf("QuorumHandler SyncBlockHeader, wrong height of No.%d header: (curr: %d, commit: %d)", i, currh, h)
+		height := header.Number.Uint64()
+		if currh >= height {
+			return fmt.Errorf("QuorumHandler SyncBlockHeader, wrong height of No.%d header: (curr: %d, commit: %d)", i, currh, height)
 		}
 
-		extra, err := VerifyQuorumHeader(vs, header, true)
+		nvs, err := h.verifyHeader(vs, header)
 		if err != nil {
-			return fmt.Errorf("QuorumHandler SyncBlockHeader, failed to verify No.%d quorum header %s: %v", i, GetQuorumHeaderHash(header).String(), err)
+			return fmt.Errorf("QuorumHandler SyncBlockHeader, No.%d header: %v", i, err)
 		}
 
-		currh, vs = h, extra.Validators
+		currh, vs = height, nvs
+		// every change is kept by its height for the headers signed before the next one
+		if err := putValSet(ns, params.ChainID, currh, vs); err != nil {
+			return fmt.Errorf("QuorumHandler SyncBlockHeader, No.%d header: failed to put validators: %v", i, err)
+		}
 	}
-
-	putValSet(ns, params.ChainID, currh, vs)
 	return nil
 }
 
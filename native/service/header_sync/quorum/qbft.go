@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package quorum
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// QBFT drops the proposer seal of the legacy istanbul and encodes the whole
+// extra-data as a rlp list, the committed seals sign the header hash with the
+// round in which the block is committed, without hashing it again.
+type ValidatorVote struct {
+	RecipientAddress common.Address
+	VoteType         byte
+}
+
+type QBFTExtra struct {
+	VanityData    []byte
+	Validators    []common.Address
+	Vote          *ValidatorVote `rlp:"nil"`
+	Round         uint32
+	CommittedSeal [][]byte
+}
+
+// EncodeRLP serializes qst into the Ethereum RLP format.
+func (qst *QBFTExtra) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, []interface{}{
+		qst.VanityData,
+		qst.Validators,
+		qst.Vote,
+		qst.Round,
+		qst.CommittedSeal,
+	})
+}
+
+// DecodeRLP implements rlp.Decoder, and load the qbft fields from a RLP stream.
+func (qst *QBFTExtra) DecodeRLP(s *rlp.Stream) error {
+	var qbftExtra struct {
+		VanityData    []byte
+		Validators    []common.Address
+		Vote          *ValidatorVote `rlp:"nil"`
+		Round         uint32
+		CommittedSeal [][]byte
+	}
+	if err := s.Decode(&qbftExtra); err != nil {
+		return err
+	}
+	qst.VanityData, qst.Validators, qst.Vote, qst.Round, qst.CommittedSeal = qbftExtra.VanityData, qbftExtra.Validators,
+		qbftExtra.Vote, qbftExtra.Round, qbftExtra.CommittedSeal
+	return nil
+}
+
+func ExtractQBFTExtra(h *types.Header) (*QBFTExtra, error) {
+	qbftExtra := new(QBFTExtra)
+	if err := rlp.DecodeBytes(h.Extra, qbftExtra); err != nil {
+		return nil, err
+	}
+	return qbftExtra, nil
+}
+
+// QBFTFilteredHeaderWithRound returns the copy of the header with the committed seals
+// removed and the round set to round
+func QBFTFilteredHeaderWithRound(h *types.Header, round uint32) *types.Header {
+	newHeader := CopyHeader(h)
+	qbftExtra, err := ExtractQBFTExtra(newHeader)
+	if err != nil {
+		return nil
+	}
+	qbftExtra.CommittedSeal = [][]byte{}
+	qbftExtra.Round = round
+
+	payload, err := rlp.EncodeToBytes(qbftExtra)
+	if err != nil {
+		return nil
+	}
+	newHeader.Extra = payload
+	return newHeader
+}
+
+func qbftHashWithRound(h *types.Header, round uint32) common.Hash {
+	if qbftHeader := QBFTFilteredHeaderWithRound(h, round); qbftHeader != nil {
+		raw, _ := rlp.EncodeToBytes(qbftHeader)
+		return common.BytesToHash(keccak256(raw))
+	}
+	return h.Hash()
+}
+
+func GetQBFTHeaderHash(h *types.Header) common.Hash {
+	if h.MixDigest == IstanbulDigest {
+		return qbftHashWithRound(h, 0)
+	}
+	return h.Hash()
+}
+
+func VerifyQBFTHeader(vs QuorumValSet, hdr *types.Header, isEpoch bool) (*QBFTExtra, error) {
+	extra, err := ExtractQBFTExtra(hdr)
+	if err != nil {
+		return nil, fmt.Errorf("extract qbft extra from header %s error: %v", GetQBFTHeaderHash(hdr).String(), err)
+	}
+	if hdr.MixDigest != IstanbulDigest {
+		return nil, fmt.Errorf("mix digest of header %s is not the istanbul digest", GetQBFTHeaderHash(hdr).String())
+	}
+
+	checker := vs
+	if isEpoch {
+		if !vs.IfChanged(extra.Validators) {
+			return nil, fmt.Errorf("header %s is not epoch header supposed to contains new validators", GetQBFTHeaderHash(hdr).String())
+		}
+		if err := vs.JustOneChanged(extra.Validators); err != nil {
+			return nil, err
+		}
+		checker = extra.Validators
+	}
+
+	if err := checker.VerifyQBFTCommittedSeals(extra, qbftHashWithRound(hdr, extra.Round)); err != nil {
+		return nil, fmt.Errorf("verify committed seals failed for header %s: %v", GetQBFTHeaderHash(hdr).String(), err)
+	}
+	return extra, nil
+}
+
+// VerifyQBFTCommittedSeals checks that distinct validators reaching the quorum ceil(2N/3)
+// have committed the header, no seal is implied by the proposer in qbft.
+func (vs QuorumValSet) VerifyQBFTCommittedSeals(extra *QBFTExtra, hash common.Hash) error {
+	signed := make(map[common.Address]struct{})
+	for _, seal := range extra.CommittedSeal {
+		addr, err := GetSignatureAddressNoHashing(hash.Bytes(), seal)
+		if err != nil {
+			return fmt.Errorf("failed to recover committed seal: %v", err)
+		}
+		if !vs.Exist(addr) {
+			return fmt.Errorf("addess %s is not in validators", addr.String())
+		}
+		if _, ok := signed[addr]; ok {
+			return fmt.Errorf("duplicate committed seal from %s", addr.String())
+		}
+		signed[addr] = struct{}{}
+	}
+	if len(signed) < vs.QBFTQuorum() {
+		return fmt.Errorf("valid seal not enough: (%d found, %d required)", len(signed), vs.QBFTQuorum())
+	}
+	return nil
+}
+
+func (vs QuorumValSet) QBFTQuorum() int { return (2*len(vs) + 2) / 3 }
@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package quorum
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	ecom "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeQBFTHeader(t *testing.T, keys []*ecdsa.PrivateKey, signers int, round uint32) *types.Header {
+	vals := make([]ecom.Address, len(keys))
+	for i, k := range keys {
+		vals[i] = crypto.PubkeyToAddress(k.PublicKey)
+	}
+	extra := &QBFTExtra{
+		VanityData:    make([]byte, 32),
+		Validators:    vals,
+		Round:         round,
+		CommittedSeal: [][]byte{},
+	}
+	raw, err := rlp.EncodeToBytes(extra)
+	assert.NoError(t, err)
+	hdr := &types.Header{
+		Number:     big.NewInt(100),
+		Difficulty: big.NewInt(1),
+		MixDigest:  IstanbulDigest,
+		Extra:      raw,
+	}
+
+	hash := qbftHashWithRound(hdr, round)
+	for i := 0; i < signers; i++ {
+		seal, err := crypto.Sign(hash.Bytes(), keys[i])
+		assert.NoError(t, err)
+		extra.CommittedSeal = append(extra.CommittedSeal, seal)
+	}
+	hdr.Extra, err = rlp.EncodeToBytes(extra)
+	assert.NoError(t, err)
+	return hdr
+}
+
+func TestVerifyQBFTHeader(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	vs := QuorumValSet(make([]ecom.Address, 4))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		vs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+
+	hdr := makeQBFTHeader(t, keys, 3, 2)
+	extra, err := VerifyQBFTHeader(vs, hdr, false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), extra.Round)
+	assert.Nil(t, extra.Vote)
+	// the block hash commits to neither the round nor the seals
+	assert.Equal(t, GetQBFTHeaderHash(makeQBFTHeader(t, keys, 0, 0)), GetQBFTHeaderHash(hdr))
+
+	_, err = VerifyQBFTHeader(vs, makeQBFTHeader(t, keys, 2, 2), false)
+	assert.Error(t, err)
+
+	extra.CommittedSeal = append(extra.CommittedSeal, extra.CommittedSeal[0])
+	assert.Error(t, vs.VerifyQBFTCommittedSeals(extra, qbftHashWithRound(hdr, 2)))
+
+	// the seals over the hash of the hash are not the ones made by qbft
+	hash := qbftHashWithRound(hdr, 2)
+	extra.CommittedSeal = [][]byte{}
+	for i := 0; i < 3; i++ {
+		seal, err := crypto.Sign(crypto.Keccak256(hash.Bytes()), keys[i])
+		assert.NoError(t, err)
+		extra.CommittedSeal = append(extra.CommittedSeal, seal)
+	}
+	assert.Error(t, vs.VerifyQBFTCommittedSeals(extra, hash))
+}
+
+func TestQBFTQuorum(t *testing.T) {
+	for n, q := range map[int]int{1: 1, 3: 2, 4: 3, 6: 4, 7: 5, 10: 7} {
+		assert.Equal(t, q, QuorumValSet(make([]ecom.Address, n)).QBFTQuorum())
+	}
+}
@@ -20,13 +20,18 @@ import (
 	"errors"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
-	"golang.org/x/crypto/sha3"
+	"github.com/polynetwork/poly/native/service/precompile"
 	"io"
 	"math/big"
 )
 
+// the crypto primitives the header verification relies on
+var (
+	secp256k1Recover = precompile.MustRecover("header_sync/quorum", precompile.SECP256K1_RECOVER)
+	keccak256        = precompile.MustHash("header_sync/quorum", precompile.KECCAK256)
+)
+
 type IstanbulExtra struct {
 	Validators    []common.Address
 	Seal          []byte
@@ -58,11 +63,9 @@ func (ist *IstanbulExtra) DecodeRLP(s *rlp.Stream) error {
 
 // copy from quorum
 func sigHash(header *types.Header) (hash common.Hash) {
-	hasher := sha3.NewLegacyKeccak256()
-
 	// Clean seal is required for calculating proposer seal.
-	rlp.Encode(hasher, IstanbulFilteredHeader(header, false))
-	hasher.Sum(hash[:0])
+	raw, _ := rlp.EncodeToBytes(IstanbulFilteredHeader(header, false))
+	copy(hash[:], keccak256(raw))
 	return hash
 }
 
@@ -116,13 +119,18 @@ func PrepareCommittedSeal(hash common.Hash) []byte {
 // copy from quorum
 func GetSignatureAddress(data []byte, sig []byte) (common.Address, error) {
 	// 1. Keccak data
-	hashData := crypto.Keccak256(data)
+	hashData := keccak256(data)
 	// 2. Recover public key
-	pubkey, err := crypto.SigToPub(hashData, sig)
+	return GetSignatureAddressNoHashing(hashData, sig)
+}
+
+// copy from quorum, the qbft committed seals sign the hash itself
+func GetSignatureAddressNoHashing(data []byte, sig []byte) (common.Address, error) {
+	pubkey, err := secp256k1Recover(data, sig)
 	if err != nil {
 		return common.Address{}, err
 	}
-	return crypto.PubkeyToAddress(*pubkey), nil
+	return common.BytesToAddress(keccak256(pubkey[1:])[12:]), nil
 }
 
 // copy from quorum
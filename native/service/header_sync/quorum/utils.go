@@ -31,7 +31,9 @@ var (
 	IstanbulDigest      = ecom.HexToHash("0x63746963616c2062797a616e74696e65206661756c7420746f6c6572616e6365")
 )
 
-func putValSet(ns *native.NativeService, chainID, height uint64, vals []ecom.Address) {
+// putValSet puts the validators taking over after the epoch header at height as the current ones, and keeps
+// them by the height as well
+func putValSet(ns *native.NativeService, chainID, height uint64, vals []ecom.Address) error {
 	vs := QuorumValSet(vals)
 	sink := pcom.NewZeroCopySink(nil)
 	vs.Serialize(sink)
@@ -41,6 +43,9 @@ func putValSet(ns *native.NativeService, chainID, height uint64, vals []ecom.Add
 	ns.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(common.CONSENSUS_PEER), rawChainID), states.GenRawStorageItem(sink.Bytes()))
 	ns.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(common.CONSENSUS_PEER_BLOCK_HEIGHT), rawChainID),
 		states.GenRawStorageItem(rawHeight))
+	ns.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(common.CONSENSUS_PEER), rawChainID, rawHeight),
+		states.GenRawStorageItem(sink.Bytes()))
+	return common.PutEpochHeight(ns, chainID, height)
 }
 
 func GetValSet(ns *native.NativeService, chainID uint64) (QuorumValSet, error) {
@@ -63,6 +68,41 @@ func GetValSet(ns *native.NativeService, chainID uint64) (QuorumValSet, error) {
 	return vs, nil
 }
 
+// GetValSetByHeight returns the validators signing the header at height, which took over after the latest
+// epoch header below it, so that the headers signed before a change can still be verified. The current
+// validators are returned for the chains synced before the validators were kept by height, false is
+// returned then.
+func GetValSetByHeight(ns *native.NativeService, chainID, height uint64) (QuorumValSet, bool, error) {
+	if height > 0 {
+		height--
+	}
+	epoch, ok, err := common.FindEpochHeight(ns, chainID, height)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		vs, err := GetValSet(ns, chainID)
+		return vs, false, err
+	}
+	store, err := ns.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(common.CONSENSUS_PEER),
+		utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(epoch)))
+	if err != nil {
+		return nil, false, err
+	}
+	if store == nil {
+		return nil, false, fmt.Errorf("GetValSetByHeight, can not find validators at height %d", epoch)
+	}
+	raw, err := states.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, false, fmt.Errorf("GetValSetByHeight, deserialize from raw storage item err: %v", err)
+	}
+	vs := QuorumValSet(make([]ecom.Address, 0))
+	if err = vs.Deserialize(pcom.NewZeroCopySource(raw)); err != nil {
+		return nil, false, err
+	}
+	return vs, true, nil
+}
+
 func GetCurrentValHeight(ns *native.NativeService, chainID uint64) (uint64, error) {
 	rawChainID := utils.GetUint64Bytes(chainID)
 	store, err := ns.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(common.CONSENSUS_PEER_BLOCK_HEIGHT), rawChainID))
@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package zcash syncs the headers of zcash in the checkpoint-attested mode, it trusts the consensus peers
+// instead of the proof of work of zcash:
+//
+//   - A batch of headers is accepted once 2/3 of the consensus peers sign the same batch. The equihash
+//     solution is never verified on chain, only the header hash against the target of its bits, so the
+//     peers attest the solution and the work by checking the headers against their own zcash nodes.
+//   - There is no fork choice and no reorg. A header has to extend the best one, the attested headers are
+//     final and the cross chain txs proved against them are never rolled back. The peers should therefore
+//     only attest headers buried deep enough in zcash to be final; a header attested on a branch zcash
+//     later abandons stops the sync at the fork and the transfers proved on it can't be undone.
+//   - The confirmations required by the side chain are still counted from the best attested header, as an
+//     extra margin on top of the attestation.
+//
+// A transfer from zcash is thus as safe as the honesty of 2/3 of the consensus peers, unlike the headers of
+// bitcoin, which are verified by their work and follow the chain with the most of it.
+package zcash
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// ZcashHandler syncs zcash headers in the checkpoint-attested mode, see the package doc for its trust model
+type ZcashHandler struct {
+}
+
+func NewZcashHandler() *ZcashHandler {
+	return &ZcashHandler{}
+}
+
+func (this *ZcashHandler) SyncGenesisHeader(native *native.NativeService) error {
+	params := new(scom.SyncGenesisHeaderParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return fmt.Errorf("ZcashHandler SyncGenesisHeader, contract params deserialize error: %v", err)
+	}
+	// Get current epoch operator
+	operatorAddress, err := node_manager.GetCurConOperator(native)
+	if err != nil {
+		return fmt.Errorf("ZcashHandler SyncGenesisHeader, get current consensus operator address error: %v", err)
+	}
+	//check witness
+	err = utils.ValidateOwner(native, operatorAddress)
+	if err != nil {
+		return fmt.Errorf("ZcashHandler SyncGenesisHeader, checkWitness error: %v", err)
+	}
+
+	// genesis header is the raw header followed by its height in 4 bytes big endian
+	l := len(params.GenesisHeader)
+	if l < 4 {
+		return fmt.Errorf("ZcashHandler SyncGenesisHeader, wrong genesis header length %d", l)
+	}
+	header := new(ZcashHeader)
+	if err := header.Deserialization(common.NewZeroCopySource(params.GenesisHeader[:l-4])); err != nil {
+		return fmt.Errorf("ZcashHandler SyncGenesisHeader, deserialize header error: %v", err)
+	}
+
+	headerStore, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.GENESIS_HEADER), utils.GetUint64Bytes(params.ChainID)))
+	if err != nil {
+		return fmt.Errorf("ZcashHandler SyncGenesisHeader, get genesis header store error: %v", err)
+	}
+	if headerStore != nil {
+		return fmt.Errorf("ZcashHandler SyncGenesisHeader, genesis header had been initialized")
+	}
+
+	putGenesisBlockHeader(native, params.ChainID, &StoredHeader{
+		Header: *header,
+		Height: binary.BigEndian.Uint32(params.GenesisHeader[l-4:]),
+	})
+	return nil
+}
+
+func (this *ZcashHandler) SyncBlockHeader(native *native.NativeService) error {
+	headerParams := new(scom.SyncBlockHeaderParam)
+	if err := headerParams.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return fmt.Errorf("ZcashHandler SyncBlockHeader, contract params deserialize error: %v", err)
+	}
+	//check witness
+	err := utils.ValidateOwner(native, headerParams.Address)
+	if err != nil {
+		return fmt.Errorf("ZcashHandler SyncBlockHeader, checkWitness error: %v", err)
+	}
+	//check consensus signs
+	ok, err := node_manager.CheckConsensusSigns(native, scom.SYNC_HEADER_NAME, getAttestedMessage(headerParams), headerParams.Address)
+	if err != nil {
+		return fmt.Errorf("ZcashHandler SyncBlockHeader, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	checkPow, err := needCheckPow(native, headerParams.ChainID)
+	if err != nil {
+		return fmt.Errorf("ZcashHandler SyncBlockHeader, %v", err)
+	}
+	for i, v := range headerParams.Headers {
+		header := new(ZcashHeader)
+		if err := header.Deserialization(common.NewZeroCopySource(v)); err != nil {
+			return fmt.Errorf("ZcashHandler SyncBlockHeader, deserialize No.%d header error: %v", i, err)
+		}
+		if _, err := GetHeaderByHash(native, headerParams.ChainID, header.BlockHash()); err == nil {
+			continue
+		}
+		best, err := GetBestBlockHeader(native, headerParams.ChainID)
+		if err != nil {
+			return fmt.Errorf("ZcashHandler SyncBlockHeader, %v", err)
+		}
+		if best.Header.BlockHash() != header.PrevBlock {
+			return fmt.Errorf("ZcashHandler SyncBlockHeader, No.%d header %s does not extend the best header %d",
+				i, header.BlockHash().String(), best.Height)
+		}
+		if checkPow {
+			if err := checkProofOfWork(header); err != nil {
+				return fmt.Errorf("ZcashHandler SyncBlockHeader, No.%d header: %v", i, err)
+			}
+		}
+		sh := &StoredHeader{
+			Header: *header,
+			Height: best.Height + 1,
+		}
+		putBlockHeader(native, headerParams.ChainID, sh)
+		putBestBlockHeader(native, headerParams.ChainID, sh)
+	}
+	return nil
+}
+
+func (this *ZcashHandler) SyncCrossChainMsg(native *native.NativeService) error {
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package zcash
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native/service/precompile"
+)
+
+// the crypto primitive the header verification relies on
+var sha256d = precompile.MustHash("header_sync/zcash", precompile.SHA256D)
+
+// ZcashHeader is the block header of zcash, the equihash solution is part of it
+type ZcashHeader struct {
+	Version          int32
+	PrevBlock        chainhash.Hash
+	MerkleRoot       chainhash.Hash
+	BlockCommitments chainhash.Hash
+	Timestamp        uint32
+	Bits             uint32
+	Nonce            [32]byte
+	Solution         []byte
+}
+
+func (this *ZcashHeader) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteInt32(this.Version)
+	sink.WriteBytes(this.PrevBlock[:])
+	sink.WriteBytes(this.MerkleRoot[:])
+	sink.WriteBytes(this.BlockCommitments[:])
+	sink.WriteUint32(this.Timestamp)
+	sink.WriteUint32(this.Bits)
+	sink.WriteBytes(this.Nonce[:])
+	sink.WriteVarBytes(this.Solution)
+}
+
+func (this *ZcashHeader) Deserialization(source *common.ZeroCopySource) error {
+	version, eof := source.NextInt32()
+	if eof {
+		return fmt.Errorf("ZcashHeader deserialize version error")
+	}
+	prevBlock, eof := source.NextBytes(chainhash.HashSize)
+	if eof {
+		return fmt.Errorf("ZcashHeader deserialize prevBlock error")
+	}
+	merkleRoot, eof := source.NextBytes(chainhash.HashSize)
+	if eof {
+		return fmt.Errorf("ZcashHeader deserialize merkleRoot error")
+	}
+	commitments, eof := source.NextBytes(chainhash.HashSize)
+	if eof {
+		return fmt.Errorf("ZcashHeader deserialize blockCommitments error")
+	}
+	timestamp, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("ZcashHeader deserialize timestamp error")
+	}
+	bits, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("ZcashHeader deserialize bits error")
+	}
+	nonce, eof := source.NextBytes(32)
+	if eof {
+		return fmt.Errorf("ZcashHeader deserialize nonce error")
+	}
+	solution, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ZcashHeader deserialize solution error")
+	}
+	this.Version = version
+	copy(this.PrevBlock[:], prevBlock)
+	copy(this.MerkleRoot[:], merkleRoot)
+	copy(this.BlockCommitments[:], commitments)
+	this.Timestamp = timestamp
+	this.Bits = bits
+	copy(this.Nonce[:], nonce)
+	this.Solution = solution
+	return nil
+}
+
+// BlockHash is the double sha256 of the whole header including the solution
+func (this *ZcashHeader) BlockHash() chainhash.Hash {
+	sink := common.NewZeroCopySink(nil)
+	this.Serialization(sink)
+	var hash chainhash.Hash
+	copy(hash[:], sha256d(sink.Bytes()))
+	return hash
+}
+
+type StoredHeader struct {
+	Header ZcashHeader
+	Height uint32
+}
+
+func (this *StoredHeader) Serialization(sink *common.ZeroCopySink) {
+	this.Header.Serialization(sink)
+	sink.WriteUint32(this.Height)
+}
+
+func (this *StoredHeader) Deserialization(source *common.ZeroCopySource) error {
+	if err := this.Header.Deserialization(source); err != nil {
+		return fmt.Errorf("StoredHeader deserialize header error: %v", err)
+	}
+	height, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("StoredHeader deserialize height error")
+	}
+	this.Height = height
+	return nil
+}
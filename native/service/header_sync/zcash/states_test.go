@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package zcash
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoredHeader(t *testing.T) {
+	sh := &StoredHeader{
+		Header: ZcashHeader{
+			Version:   4,
+			Timestamp: 1600000000,
+			Bits:      0x1c01f8f2,
+			Solution:  make([]byte, 1344),
+		},
+		Height: 1000000,
+	}
+	sh.Header.PrevBlock[0] = 1
+	sh.Header.MerkleRoot[1] = 2
+	sh.Header.BlockCommitments[2] = 3
+	sh.Header.Nonce[3] = 4
+	sh.Header.Solution[5] = 6
+
+	sink := common.NewZeroCopySink(nil)
+	sh.Serialization(sink)
+	// 140 bytes header, 3 bytes solution length and the height
+	assert.Equal(t, 140+3+1344+4, len(sink.Bytes()))
+
+	nsh := new(StoredHeader)
+	assert.NoError(t, nsh.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, sh, nsh)
+	assert.Equal(t, sh.Header.BlockHash(), nsh.Header.BlockHash())
+}
+
+func TestCheckProofOfWork(t *testing.T) {
+	header := &ZcashHeader{Bits: 0x1f07ffff}
+	// one hash in 8192 meets the pow limit
+	for i := 0; i < 1<<16 && checkProofOfWork(header) != nil; i++ {
+		header.Nonce[0], header.Nonce[1] = byte(i), byte(i>>8)
+	}
+	assert.NoError(t, checkProofOfWork(header))
+
+	header.Bits = 0x207fffff
+	assert.Error(t, checkProofOfWork(header))
+	header.Bits = 0x03000001
+	assert.Error(t, checkProofOfWork(header))
+}
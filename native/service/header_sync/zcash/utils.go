@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package zcash
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// the pow limit of both zcash mainnet and testnet: 0x0007ffff...ff
+var powLimit = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 243), big.NewInt(1))
+
+// getAttestedMessage is what the consensus peers sign for, the relayer address excluded
+func getAttestedMessage(params *scom.SyncBlockHeaderParam) []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint64(params.ChainID)
+	for _, v := range params.Headers {
+		sink.WriteVarBytes(v)
+	}
+	return sink.Bytes()
+}
+
+// the difficulty is not checked on regtest
+func needCheckPow(native *native.NativeService, chainID uint64) (bool, error) {
+	side, err := side_chain_manager.GetSideChain(native, chainID)
+	if err != nil {
+		return false, fmt.Errorf("needCheckPow, get side chain error: %v", err)
+	}
+	if side == nil {
+		return false, fmt.Errorf("needCheckPow, side chain info for chainId: %d is not registered", chainID)
+	}
+	if len(side.CCMCAddress) != 8 {
+		return false, fmt.Errorf("needCheckPow, CCMCAddress is nil or its length is not 8")
+	}
+	return utils.BtcNetType(binary.LittleEndian.Uint64(side.CCMCAddress)) != utils.TyRegtest, nil
+}
+
+// checkProofOfWork only checks the header hash against the target, the equihash
+// solution is attested by the consensus peers.
+func checkProofOfWork(header *ZcashHeader) error {
+	target := blockchain.CompactToBig(header.Bits)
+	if target.Sign() <= 0 {
+		return fmt.Errorf("checkProofOfWork, target %064x is not positive", target)
+	}
+	if target.Cmp(powLimit) > 0 {
+		return fmt.Errorf("checkProofOfWork, target %064x is higher than the pow limit", target)
+	}
+	hash := header.BlockHash()
+	if blockchain.HashToBig(&hash).Cmp(target) > 0 {
+		return fmt.Errorf("checkProofOfWork, hash %s is higher than the target %064x", hash.String(), target)
+	}
+	return nil
+}
+
+func putGenesisBlockHeader(native *native.NativeService, chainID uint64, sh *StoredHeader) {
+	sink := common.NewZeroCopySink(nil)
+	sh.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.GENESIS_HEADER), utils.GetUint64Bytes(chainID)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	putBlockHeader(native, chainID, sh)
+	putBestBlockHeader(native, chainID, sh)
+}
+
+func putBlockHeader(native *native.NativeService, chainID uint64, sh *StoredHeader) {
+	contract := utils.HeaderSyncContractAddress
+	blockHash := sh.Header.BlockHash()
+	sink := common.NewZeroCopySink(nil)
+	sh.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.BLOCK_HEADER), utils.GetUint64Bytes(chainID), blockHash[:]),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.HEADER_INDEX), utils.GetUint64Bytes(chainID), utils.GetUint32Bytes(sh.Height)),
+		cstates.GenRawStorageItem(blockHash[:]))
+	scom.PutHeaderCommitment(native, chainID, uint64(sh.Height), blockHash[:])
+	scom.NotifyPutHeader(native, chainID, uint64(sh.Height), hex.EncodeToString(blockHash[:]))
+}
+
+func putBestBlockHeader(native *native.NativeService, chainID uint64, sh *StoredHeader) {
+	sink := common.NewZeroCopySink(nil)
+	sh.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.CURRENT_HEADER_HEIGHT), utils.GetUint64Bytes(chainID)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func getStoredHeader(native *native.NativeService, key []byte) (*StoredHeader, error) {
+	store, err := native.GetCacheDB().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("get header store error: %v", err)
+	}
+	if store == nil {
+		return nil, fmt.Errorf("can not find any records")
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize from raw storage item err: %v", err)
+	}
+	sh := new(StoredHeader)
+	if err := sh.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("deserialize stored header error: %v", err)
+	}
+	return sh, nil
+}
+
+func GetBestBlockHeader(native *native.NativeService, chainID uint64) (*StoredHeader, error) {
+	sh, err := getStoredHeader(native, utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.CURRENT_HEADER_HEIGHT),
+		utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("GetBestBlockHeader, %v", err)
+	}
+	return sh, nil
+}
+
+func GetHeaderByHash(native *native.NativeService, chainID uint64, hash chainhash.Hash) (*StoredHeader, error) {
+	sh, err := getStoredHeader(native, utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.BLOCK_HEADER),
+		utils.GetUint64Bytes(chainID), hash[:]))
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderByHash, %v", err)
+	}
+	return sh, nil
+}
+
+func GetHeaderByHeight(native *native.NativeService, chainID uint64, height uint32) (*StoredHeader, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(scom.HEADER_INDEX),
+		utils.GetUint64Bytes(chainID), utils.GetUint32Bytes(height)))
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderByHeight, get header index error: %v", err)
+	}
+	if store == nil {
+		return nil, fmt.Errorf("GetHeaderByHeight, no header at height %d", height)
+	}
+	hashBs, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderByHeight, deserialize from raw storage item err: %v", err)
+	}
+	hash, err := chainhash.NewHash(hashBs)
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderByHeight, %v", err)
+	}
+	return GetHeaderByHash(native, chainID, *hash)
+}
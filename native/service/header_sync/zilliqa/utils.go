@@ -109,6 +109,7 @@ func AppendHeader2Main(native *native.NativeService, height uint64, txHash []byt
 	contract := utils.HeaderSyncContractAddress
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)),
 		cstates.GenRawStorageItem(txHash))
+	scom.PutHeaderCommitment(native, chainID, height, txHash)
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.CURRENT_HEADER_HEIGHT),
 		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(height)))
 	scom.NotifyPutHeader(native, chainID, height, util.EncodeHex(txHash))
@@ -186,6 +187,7 @@ func putGenesisBlockHeader(native *native.NativeService, txBlockAndDsComm TxBloc
 		cstates.GenRawStorageItem(storeBytes))
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(blockNum)),
 		cstates.GenRawStorageItem(blockHash))
+	scom.PutHeaderCommitment(native, chainID, blockNum, blockHash)
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.CURRENT_HEADER_HEIGHT),
 		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(blockNum)))
 	putDsComm(native, dsBlockNum, txBlockAndDsComm.DsComm, chainID)
@@ -24,6 +24,7 @@ import (
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
 	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/governance/treasury"
 	"github.com/polynetwork/poly/native/service/header_sync"
 	"github.com/polynetwork/poly/native/service/utils"
 )
@@ -34,6 +35,7 @@ func init() {
 	native.Contracts[utils.CrossChainManagerContractAddress] = cross_chain_manager.RegisterCrossChainManagerContract
 	native.Contracts[utils.NodeManagerContractAddress] = node_manager.RegisterNodeManagerContract
 	native.Contracts[utils.RelayerManagerContractAddress] = relayer_manager.RegisterRelayerManagerContract
+	native.Contracts[utils.TreasuryContractAddress] = treasury.RegisterTreasuryContract
 
 	config.EXTRA_INFO_HEIGHT_FORK_CHECK = true
 }
@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package precompile is the registry of the crypto primitives the chain handlers rely on. A
+// handler requests a primitive by name and the registry records it, so Usage tells exactly
+// which crypto each chain integration depends on.
+package precompile
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	SECP256K1_RECOVER = "secp256k1Recover"
+	ED25519_VERIFY    = "ed25519Verify"
+	BLS12381_VERIFY   = "bls12381Verify"
+	KECCAK256         = "keccak256"
+	SHA256            = "sha256"
+	SHA256D           = "sha256d"
+	HASH160           = "hash160"
+	BLAKE2B256        = "blake2b256"
+	SHA3_256          = "sha3256"
+)
+
+type (
+	// HashFunc returns the digest of data
+	HashFunc func(data []byte) []byte
+	// RecoverFunc returns the public key which signs hash with sig
+	RecoverFunc func(hash, sig []byte) ([]byte, error)
+	// VerifyFunc tells if sig is the signature of msg by pubkey
+	VerifyFunc func(pubkey, msg, sig []byte) bool
+)
+
+var (
+	hashes = map[string]HashFunc{
+		KECCAK256: crypto.Keccak256,
+		SHA256: func(data []byte) []byte {
+			sum := sha256.Sum256(data)
+			return sum[:]
+		},
+		SHA256D: chainhash.DoubleHashB,
+		HASH160: btcutil.Hash160,
+		BLAKE2B256: func(data []byte) []byte {
+			sum := blake2b.Sum256(data)
+			return sum[:]
+		},
+		SHA3_256: func(data []byte) []byte {
+			sum := sha3.Sum256(data)
+			return sum[:]
+		},
+	}
+	recovers = map[string]RecoverFunc{
+		SECP256K1_RECOVER: crypto.Ecrecover,
+	}
+	verifies = map[string]VerifyFunc{
+		ED25519_VERIFY:  ed25519Verify,
+		BLS12381_VERIFY: bls12381Verify,
+	}
+
+	lock  sync.Mutex
+	usage = make(map[string]map[string]struct{})
+)
+
+func use(user, name string) {
+	lock.Lock()
+	defer lock.Unlock()
+	if usage[user] == nil {
+		usage[user] = make(map[string]struct{})
+	}
+	usage[user][name] = struct{}{}
+}
+
+// Hash returns the hash primitive name requested by user
+func Hash(user, name string) (HashFunc, error) {
+	f, ok := hashes[name]
+	if !ok {
+		return nil, fmt.Errorf("hash %s is not registered", name)
+	}
+	use(user, name)
+	return f, nil
+}
+
+// IsHash tells if name is a registered hash primitive, without recording any usage
+func IsHash(name string) bool {
+	_, ok := hashes[name]
+	return ok
+}
+
+// Recover returns the public key recovery primitive name requested by user
+func Recover(user, name string) (RecoverFunc, error) {
+	f, ok := recovers[name]
+	if !ok {
+		return nil, fmt.Errorf("recover %s is not registered", name)
+	}
+	use(user, name)
+	return f, nil
+}
+
+// Verify returns the signature verification primitive name requested by user
+func Verify(user, name string) (VerifyFunc, error) {
+	f, ok := verifies[name]
+	if !ok {
+		return nil, fmt.Errorf("verify %s is not registered", name)
+	}
+	use(user, name)
+	return f, nil
+}
+
+// MustHash is Hash for the package level variables of the handlers, it panics if name is unknown
+func MustHash(user, name string) HashFunc {
+	f, err := Hash(user, name)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// MustRecover is Recover for the package level variables of the handlers, it panics if name is unknown
+func MustRecover(user, name string) RecoverFunc {
+	f, err := Recover(user, name)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// MustVerify is Verify for the package level variables of the handlers, it panics if name is unknown
+func MustVerify(user, name string) VerifyFunc {
+	f, err := Verify(user, name)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Usage returns the sorted names of the primitives requested by each user
+func Usage() map[string][]string {
+	lock.Lock()
+	defer lock.Unlock()
+	result := make(map[string][]string, len(usage))
+	for user, names := range usage {
+		list := make([]string, 0, len(names))
+		for name := range names {
+			list = append(list, name)
+		}
+		sort.Strings(list)
+		result[user] = list
+	}
+	return result
+}
+
+func ed25519Verify(pubkey, msg, sig []byte) bool {
+	if len(pubkey) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	return ed25519.Verify(pubkey, msg, sig)
+}
+
+// bls12381Verify verifies the signature in G2 of the public key in G1, msg is the point in G2
+// the message is already hashed to, all in uncompressed form.
+func bls12381Verify(pubkey, msg, sig []byte) bool {
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+	pk, err := g1.FromBytes(pubkey)
+	if err != nil || g1.IsZero(pk) || !g1.InCorrectSubgroup(pk) {
+		return false
+	}
+	hm, err := g2.FromBytes(msg)
+	if err != nil || !g2.InCorrectSubgroup(hm) {
+		return false
+	}
+	s, err := g2.FromBytes(sig)
+	if err != nil || !g2.InCorrectSubgroup(s) {
+		return false
+	}
+	// e(pk, H(m)) == e(g1, sig)
+	return bls12381.NewPairingEngine().AddPair(pk, hm).AddPairInv(g1.One(), s).Check()
+}
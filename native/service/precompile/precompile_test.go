@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package precompile
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash(t *testing.T) {
+	cases := map[string]string{
+		KECCAK256:  "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		SHA256:     "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		SHA256D:    "5df6e0e2761359d30a8275058e299fcc0381534545f55cf43e41983f5d4c9456",
+		HASH160:    "b472a266d0bd89c13706a4132ccfb16f7c3b9fcb",
+		BLAKE2B256: "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+		SHA3_256:   "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a",
+	}
+	for name, digest := range cases {
+		f, err := Hash("test", name)
+		assert.NoError(t, err)
+		assert.Equal(t, digest, hex.EncodeToString(f(nil)), name)
+	}
+	_, err := Hash("test", "md5")
+	assert.Error(t, err)
+	assert.True(t, IsHash(SHA3_256))
+	assert.False(t, IsHash("md5"))
+}
+
+func TestRecover(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	hash := crypto.Keccak256([]byte("poly"))
+	sig, err := crypto.Sign(hash, key)
+	assert.NoError(t, err)
+
+	secp256k1Recover := MustRecover("test", SECP256K1_RECOVER)
+	pubkey, err := secp256k1Recover(hash, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.FromECDSAPub(&key.PublicKey), pubkey)
+}
+
+func TestVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("poly")
+	verify := MustVerify("test", ED25519_VERIFY)
+	assert.True(t, verify(pub, msg, ed25519.Sign(priv, msg)))
+	assert.False(t, verify(pub, []byte("ploy"), ed25519.Sign(priv, msg)))
+	assert.False(t, verify(pub[1:], msg, ed25519.Sign(priv, msg)))
+
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+	sk := big.NewInt(7)
+	pk := g1.MulScalar(g1.New(), g1.One(), sk)
+	hm := g2.MulScalar(g2.New(), g2.One(), big.NewInt(5))
+	sig := g2.MulScalar(g2.New(), hm, sk)
+	other := g2.MulScalar(g2.New(), g2.One(), big.NewInt(6))
+	verify = MustVerify("test", BLS12381_VERIFY)
+	assert.True(t, verify(g1.ToBytes(pk), g2.ToBytes(hm), g2.ToBytes(sig)))
+	assert.False(t, verify(g1.ToBytes(pk), g2.ToBytes(other), g2.ToBytes(sig)))
+}
+
+func TestUsage(t *testing.T) {
+	MustHash("usage", SHA256D)
+	MustHash("usage", KECCAK256)
+	MustHash("usage", SHA256D)
+	assert.Equal(t, []string{KECCAK256, SHA256D}, Usage()["usage"])
+}
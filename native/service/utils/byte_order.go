@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/polynetwork/poly/common"
+)
+
+// The txids, block hashes and roots of btc, zcash and neo are kept in the byte order they are digested
+// in, the internal order, but displayed and passed around as hex strings byte reversed, while eth like
+// chains and cosmos display them as digested. Handlers cross between the two orders only by the helpers
+// below, so a hash is never reversed twice or not at all.
+
+// HashToDisplay returns hash in internal order reversed into display order
+func HashToDisplay(hash []byte) []byte {
+	return common.ToArrayReverse(hash)
+}
+
+// HashFromDisplay returns hash in display order reversed into internal order
+func HashFromDisplay(hash []byte) []byte {
+	return common.ToArrayReverse(hash)
+}
+
+// FormatDisplayHash returns the hex string of hash in internal order as btc and neo display it
+func FormatDisplayHash(hash []byte) string {
+	return hex.EncodeToString(HashToDisplay(hash))
+}
+
+// ParseDisplayHash decodes the hex string of a hash displayed by btc or neo, with an optional 0x prefix,
+// into internal order, the hash must be size bytes
+func ParseDisplayHash(s string, size int) ([]byte, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("ParseDisplayHash, decode hex error: %v", err)
+	}
+	if len(raw) != size {
+		return nil, fmt.Errorf("ParseDisplayHash, expect %d bytes but got %d", size, len(raw))
+	}
+	return HashFromDisplay(raw), nil
+}
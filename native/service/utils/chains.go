@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import "fmt"
+
+// chain ids assigned to the side chains of the poly mainnet, a registered chain must not reuse them for other chains
+var (
+	BTC_CHAIN_ID      = uint64(1)
+	ETH_CHAIN_ID      = uint64(2)
+	ONT_CHAIN_ID      = uint64(3)
+	NEO_CHAIN_ID      = uint64(4)
+	SWITCHEO_CHAIN_ID = uint64(5)
+	BSC_CHAIN_ID      = uint64(6)
+	HECO_CHAIN_ID     = uint64(7)
+	OKEX_CHAIN_ID     = uint64(12)
+)
+
+// KnownChain is the name and router a chain id is assigned to
+type KnownChain struct {
+	Name   string
+	Router uint64
+}
+
+// KnownChains is the canonical registry of the assigned chain ids
+var KnownChains = map[uint64]*KnownChain{
+	BTC_CHAIN_ID:      {Name: "bitcoin", Router: BTC_ROUTER},
+	ETH_CHAIN_ID:      {Name: "ethereum", Router: ETH_ROUTER},
+	ONT_CHAIN_ID:      {Name: "ontology", Router: ONT_ROUTER},
+	NEO_CHAIN_ID:      {Name: "neo", Router: NEO_ROUTER},
+	SWITCHEO_CHAIN_ID: {Name: "switcheo", Router: COSMOS_ROUTER},
+	BSC_CHAIN_ID:      {Name: "binance smart chain", Router: BSC_ROUTER},
+	HECO_CHAIN_ID:     {Name: "heco", Router: HECO_ROUTER},
+	OKEX_CHAIN_ID:     {Name: "okexchain", Router: OKEX_ROUTER},
+}
+
+// CheckChainID rejects an assigned chain id used for a chain of another router
+func CheckChainID(chainID, router uint64) error {
+	known, ok := KnownChains[chainID]
+	if !ok {
+		return nil
+	}
+	if known.Router != router {
+		return fmt.Errorf("chain id %d is assigned to %s of router %d, not router %d", chainID, known.Name,
+			known.Router, router)
+	}
+	return nil
+}
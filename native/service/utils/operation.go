@@ -27,8 +27,14 @@ import (
 	"github.com/polynetwork/poly/native"
 )
 
+// ConcatKey builds the storage key of contract with a single allocation
 func ConcatKey(contract common.Address, args ...[]byte) []byte {
-	temp := contract[:]
+	size := len(contract)
+	for _, arg := range args {
+		size += len(arg)
+	}
+	temp := make([]byte, 0, size)
+	temp = append(temp, contract[:]...)
 	for _, arg := range args {
 		temp = append(temp, arg...)
 	}
@@ -42,6 +48,14 @@ func ValidateOwner(native *native.NativeService, address common.Address) error {
 	return nil
 }
 
+// ValidateContractWitness checks that address is the native contract calling the current method
+func ValidateContractWitness(native *native.NativeService, address common.Address) error {
+	if !native.CheckContractWitness(address) {
+		return fmt.Errorf("validateContractWitness, %s is not the calling contract", address.ToHexString())
+	}
+	return nil
+}
+
 func GetUint32Bytes(num uint32) []byte {
 	var p [4]byte
 	binary.LittleEndian.PutUint32(p[:], num)
@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+)
+
+func TestConcatKey(t *testing.T) {
+	contract := common.Address{1, 2, 3}
+	key := ConcatKey(contract, []byte("header"), []byte{4, 5})
+	expected := append(append(contract[:], []byte("header")...), 4, 5)
+	if !bytes.Equal(key, expected) {
+		t.Fatalf("ConcatKey got %x, expect %x", key, expected)
+	}
+	if len(key) != cap(key) {
+		t.Fatalf("ConcatKey should allocate the exact size")
+	}
+	if !bytes.Equal(ConcatKey(contract), contract[:]) {
+		t.Fatalf("ConcatKey without args should be the contract")
+	}
+}
+
+// BenchmarkConcatKey builds the keys of a header, the way header sync and governance contracts do for every Get/Put
+func BenchmarkConcatKey(b *testing.B) {
+	contract := common.Address{1}
+	prefix := []byte("mainChain")
+	chainID := GetUint64Bytes(2)
+	height := GetUint32Bytes(100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ConcatKey(contract, prefix, chainID, height)
+	}
+}
+
+func TestValidateContractWitness(t *testing.T) {
+	caller := common.Address{0xca}
+	native.Contracts[caller] = func(*native.NativeService) {}
+	defer delete(native.Contracts, caller)
+
+	service, err := native.NewNativeService(nil, &types.Transaction{}, 0, 0, common.UINT256_EMPTY, 0, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateContractWitness(service, caller); err == nil {
+		t.Fatalf("contract is not calling yet")
+	}
+	service.PushContext(caller)
+	service.PushContext(NodeManagerContractAddress)
+	if err := ValidateContractWitness(service, caller); err != nil {
+		t.Fatalf("calling contract should be the witness: %v", err)
+	}
+	if err := ValidateContractWitness(service, common.Address{0xcb}); err == nil {
+		t.Fatalf("unregistered address should not be the witness")
+	}
+}
+
+func TestCheckChainID(t *testing.T) {
+	if err := CheckChainID(ETH_CHAIN_ID, ETH_ROUTER); err != nil {
+		t.Fatalf("CheckChainID of ethereum error: %v", err)
+	}
+	if err := CheckChainID(ETH_CHAIN_ID, BSC_ROUTER); err == nil {
+		t.Fatalf("CheckChainID should reject the id of ethereum used by another router")
+	}
+	if err := CheckChainID(1000, BSC_ROUTER); err != nil {
+		t.Fatalf("CheckChainID of an unassigned id error: %v", err)
+	}
+}
+
+func TestDisplayHash(t *testing.T) {
+	display := "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	btcHash, _ := chainhash.NewHashFromStr(display)
+
+	internal, err := ParseDisplayHash(display, common.UINT256_SIZE)
+	if err != nil {
+		t.Fatalf("ParseDisplayHash error: %v", err)
+	}
+	if !bytes.Equal(internal, btcHash[:]) {
+		t.Fatalf("ParseDisplayHash should return the internal order, got %x", internal)
+	}
+	if s := FormatDisplayHash(btcHash[:]); s != display {
+		t.Fatalf("FormatDisplayHash should return the display order, got %s", s)
+	}
+	if !bytes.Equal(HashFromDisplay(HashToDisplay(internal)), internal) {
+		t.Fatalf("converting back and forth should not change the hash")
+	}
+
+	// neo displays script hashes with 0x prefix
+	scriptHash, err := ParseDisplayHash("0x"+display[:40], common.ADDR_LEN)
+	if err != nil {
+		t.Fatalf("ParseDisplayHash of script hash error: %v", err)
+	}
+	if FormatDisplayHash(scriptHash) != display[:40] {
+		t.Fatalf("FormatDisplayHash of script hash should return the display order")
+	}
+	if _, err := ParseDisplayHash(display, common.ADDR_LEN); err == nil {
+		t.Fatalf("ParseDisplayHash should reject the hash of another size")
+	}
+	if _, err := ParseDisplayHash("zz", 1); err == nil {
+		t.Fatalf("ParseDisplayHash should reject invalid hex")
+	}
+}
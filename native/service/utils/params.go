@@ -37,6 +37,7 @@ var (
 	SideChainManagerContractAddress, _  = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04})
 	NodeManagerContractAddress, _       = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05})
 	RelayerManagerContractAddress, _    = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x06})
+	TreasuryContractAddress, _          = common.AddressParseFromBytes([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x07})
 
 	BTC_ROUTER     = uint64(1)
 	ETH_ROUTER     = uint64(2)
@@ -49,4 +50,8 @@ var (
 	ZILLIQA_ROUTER = uint64(9)
 	MSC_ROUTER     = uint64(10)
 	OKEX_ROUTER    = uint64(12)
+	BCH_ROUTER     = uint64(13)
+	ZCASH_ROUTER   = uint64(14)
+	QBFT_ROUTER    = uint64(15)
+	ROLLUP_ROUTER  = uint64(16)
 )
@@ -19,6 +19,8 @@
 package storage
 
 import (
+	"bytes"
+
 	"github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/store/overlaydb"
 	"github.com/syndtr/goleveldb/leveldb/util"
@@ -27,9 +29,13 @@ import (
 // CacheDB is smart contract execute cache, it contain transaction cache and block cache
 // When smart contract execute finish, need to commit transaction cache to block cache
 type CacheDB struct {
-	memdb      *overlaydb.MemDB
-	backend    *overlaydb.OverlayDB
-	keyScratch []byte
+	memdb       *overlaydb.MemDB
+	backend     *overlaydb.OverlayDB
+	keyScratch  []byte
+	nsScratch   []byte
+	namespace   []byte   //segment inserted after the address of namespaced contracts, empty for the default namespace
+	nsContracts [][]byte //addresses of the contracts whose storage is namespaced
+	sharedWrite bool     //whether a key of the contracts not namespaced is written in the namespace
 }
 
 const initCap = 16 * 1024
@@ -43,8 +49,78 @@ func NewCacheDB(store *overlaydb.OverlayDB) *CacheDB {
 	}
 }
 
+// Reset drops the transaction cache and restores the default namespace
 func (self *CacheDB) Reset() {
 	self.memdb.Reset()
+	self.namespace = nil
+	self.nsContracts = nil
+	self.sharedWrite = false
+}
+
+// SetNamespace moves the storage of contracts into namespace until Reset, the keys of other contracts
+// are kept and an empty namespace restores the default one.
+//
+// The namespace is inserted after the contract address as a zero byte, its length and itself. The key
+// prefixes of contracts are readable strings, so namespaced keys never collide with the default ones.
+func (self *CacheDB) SetNamespace(namespace []byte, contracts ...[]byte) {
+	if len(namespace) == 0 {
+		self.namespace = nil
+		self.nsContracts = nil
+		return
+	}
+	self.namespace = append([]byte{0, byte(len(namespace))}, namespace...)
+	self.nsContracts = contracts
+	self.sharedWrite = false
+}
+
+// SharedWritten returns whether the storage of the contracts not namespaced is written since the namespace
+// was set, such writes reach the state shared with the default namespace
+func (self *CacheDB) SharedWritten() bool {
+	return self.sharedWrite
+}
+
+func (self *CacheDB) checkSharedWrite(key []byte) {
+	if len(self.namespace) != 0 && self.namespacedContract(key) == 0 {
+		self.sharedWrite = true
+	}
+}
+
+// Namespace returns the current namespace, nil for the default one
+func (self *CacheDB) Namespace() []byte {
+	if len(self.namespace) == 0 {
+		return nil
+	}
+	return self.namespace[2:]
+}
+
+// namespacedContract returns the length of the namespaced contract address key starts with, 0 if none
+func (self *CacheDB) namespacedContract(key []byte) int {
+	if len(self.namespace) == 0 {
+		return 0
+	}
+	for _, contract := range self.nsContracts {
+		if bytes.HasPrefix(key, contract) {
+			return len(contract)
+		}
+	}
+	return 0
+}
+
+func (self *CacheDB) namespacedKey(key []byte) []byte {
+	n := self.namespacedContract(key)
+	if n == 0 {
+		return key
+	}
+	self.nsScratch = ensureBuffer(self.nsScratch, len(key)+len(self.namespace))
+	copy(self.nsScratch, key[:n])
+	copy(self.nsScratch[n:], self.namespace)
+	copy(self.nsScratch[n+len(self.namespace):], key[n:])
+	return self.nsScratch
+}
+
+// NamespacedKey returns the key the storage of key is kept at in the namespace entered
+func (self *CacheDB) NamespacedKey(key []byte) []byte {
+	return append([]byte{}, self.namespacedKey(key)...)
 }
 
 func ensureBuffer(b []byte, n int) []byte {
@@ -72,12 +148,34 @@ func (self *CacheDB) Commit() {
 	})
 }
 
+// Snapshot returns a copy of the transaction cache, Restore puts it back e.g. after Reset
+func (self *CacheDB) Snapshot() *overlaydb.MemDB {
+	snapshot := overlaydb.NewMemDB(0, 0)
+	self.memdb.ForEach(func(key, val []byte) {
+		snapshot.Put(key, val)
+	})
+	return snapshot
+}
+
+// Restore writes the transaction cache copied by Snapshot into the current one
+func (self *CacheDB) Restore(snapshot *overlaydb.MemDB) {
+	snapshot.ForEach(func(key, val []byte) {
+		self.memdb.Put(key, val)
+	})
+}
+
+// ForEach iterates the transaction cache, the value of deleted key is empty
+func (self *CacheDB) ForEach(f func(key, val []byte)) {
+	self.memdb.ForEach(f)
+}
+
 func (self *CacheDB) Put(key []byte, value []byte) {
 	self.put(common.ST_STORAGE, key, value)
 }
 
 func (self *CacheDB) put(prefix common.DataEntryPrefix, key []byte, value []byte) {
-	self.keyScratch = makePrefixedKey(self.keyScratch, byte(prefix), key)
+	self.checkSharedWrite(key)
+	self.keyScratch = makePrefixedKey(self.keyScratch, byte(prefix), self.namespacedKey(key))
 	self.memdb.Put(self.keyScratch, value)
 }
 
@@ -86,7 +184,7 @@ func (self *CacheDB) Get(key []byte) ([]byte, error) {
 }
 
 func (self *CacheDB) get(prefix common.DataEntryPrefix, key []byte) ([]byte, error) {
-	self.keyScratch = makePrefixedKey(self.keyScratch, byte(prefix), key)
+	self.keyScratch = makePrefixedKey(self.keyScratch, byte(prefix), self.namespacedKey(key))
 	value, unknown := self.memdb.Get(self.keyScratch)
 	if unknown {
 		v, err := self.backend.Get(self.keyScratch)
@@ -105,11 +203,15 @@ func (self *CacheDB) Delete(key []byte) {
 
 // Delete item from cache
 func (self *CacheDB) delete(prefix common.DataEntryPrefix, key []byte) {
-	self.keyScratch = makePrefixedKey(self.keyScratch, byte(prefix), key)
+	self.checkSharedWrite(key)
+	self.keyScratch = makePrefixedKey(self.keyScratch, byte(prefix), self.namespacedKey(key))
 	self.memdb.Delete(self.keyScratch)
 }
 
+// NewIterator iterates the keys with prefix key, the namespace is removed from the keys returned
 func (self *CacheDB) NewIterator(key []byte) common.StoreIterator {
+	contractLen := self.namespacedContract(key)
+	key = self.namespacedKey(key)
 	pkey := make([]byte, 1+len(key))
 	pkey[0] = byte(common.ST_STORAGE)
 	copy(pkey[1:], key)
@@ -117,11 +219,18 @@ func (self *CacheDB) NewIterator(key []byte) common.StoreIterator {
 	backIter := self.backend.NewIterator(pkey)
 	memIter := self.memdb.NewIterator(prefixRange)
 
-	return &Iter{overlaydb.NewJoinIter(memIter, backIter)}
+	iter := &Iter{JoinIter: overlaydb.NewJoinIter(memIter, backIter)}
+	if contractLen != 0 {
+		iter.contractLen = contractLen
+		iter.nsLen = len(self.namespace)
+	}
+	return iter
 }
 
 type Iter struct {
 	*overlaydb.JoinIter
+	contractLen int
+	nsLen       int
 }
 
 func (self *Iter) Key() []byte {
@@ -129,5 +238,8 @@ func (self *Iter) Key() []byte {
 	if len(key) != 0 {
 		key = key[1:] // remove the first prefix
 	}
+	if self.nsLen != 0 && len(key) >= self.contractLen+self.nsLen {
+		key = append(key[:self.contractLen:self.contractLen], key[self.contractLen+self.nsLen:]...)
+	}
 	return key
 }
@@ -18,6 +18,7 @@
 package storage
 
 import (
+	"bytes"
 	"github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/store/leveldbstore"
 	"github.com/polynetwork/poly/core/store/overlaydb"
@@ -82,3 +83,75 @@ func TestCacheDB(t *testing.T) {
 	}
 
 }
+
+func TestCacheDBNamespace(t *testing.T) {
+	memback, _ := leveldbstore.NewMemLevelDBStore()
+	overlay := overlaydb.NewOverlayDB(memback)
+	cache := NewCacheDB(overlay)
+	contract := []byte("contract")
+
+	cache.Put([]byte("contractkey1"), []byte("default"))
+	cache.SetNamespace([]byte("canary"), contract)
+	assert.Equal(t, []byte("canary"), cache.Namespace())
+	value, err := cache.Get([]byte("contractkey1"))
+	assert.Nil(t, err)
+	assert.Nil(t, value, "default namespace should not be visible")
+	cache.Put([]byte("contractkey1"), []byte("canary1"))
+	cache.Put([]byte("contractkey2"), []byte("canary2"))
+	assert.False(t, cache.SharedWritten())
+	cache.Put([]byte("otherkey1"), []byte("other"))
+	assert.True(t, cache.SharedWritten(), "storage of other contracts is shared")
+	key := cache.NamespacedKey([]byte("contractkey1"))
+	assert.True(t, bytes.HasPrefix(key, contract) && bytes.HasSuffix(key, []byte("key1")))
+	assert.NotEqual(t, []byte("contractkey1"), key)
+	assert.Equal(t, []byte("otherkey1"), cache.NamespacedKey([]byte("otherkey1")))
+
+	iter := cache.NewIterator([]byte("contractkey"))
+	keys := make([]string, 0)
+	for has := iter.First(); has; has = iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+	iter.Release()
+	assert.Equal(t, []string{"contractkey1", "contractkey2"}, keys)
+
+	cache.SetNamespace(nil)
+	assert.Nil(t, cache.Namespace())
+	value, err = cache.Get([]byte("contractkey1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("default"), value)
+	value, err = cache.Get([]byte("contractkey2"))
+	assert.Nil(t, err)
+	assert.Nil(t, value, "namespace should not be visible from default")
+	value, err = cache.Get([]byte("otherkey1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("other"), value, "storage of other contracts is not namespaced")
+
+	cache.SetNamespace([]byte("canary"), contract)
+	cache.Reset()
+	assert.Nil(t, cache.Namespace())
+	assert.False(t, cache.SharedWritten())
+}
+
+func TestCacheDBSnapshot(t *testing.T) {
+	memback, _ := leveldbstore.NewMemLevelDBStore()
+	overlay := overlaydb.NewOverlayDB(memback)
+	overlay.Put([]byte("key2"), []byte("value2"))
+	cache := NewCacheDB(overlay)
+
+	cache.Put([]byte("key1"), []byte("value1"))
+	cache.Delete([]byte("key2"))
+	snapshot := cache.Snapshot()
+	cache.Put([]byte("key3"), []byte("value3"))
+	cache.Reset()
+
+	cache.Restore(snapshot)
+	value, err := cache.Get([]byte("key1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value1"), value)
+	value, err = cache.Get([]byte("key2"))
+	assert.Nil(t, err)
+	assert.Nil(t, value, "deletion should be restored")
+	value, err = cache.Get([]byte("key3"))
+	assert.Nil(t, err)
+	assert.Nil(t, value, "writes after the snapshot should not be restored")
+}
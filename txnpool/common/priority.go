@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"crypto/sha256"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/header_sync"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+)
+
+// txClass tells how the pool treats a transaction: the packing weight, and the key
+// of the proof it submits if any, the same proof submitted by several relayers
+// shares the key whatever the relayer address is.
+type txClass struct {
+	weight        uint
+	proofKey      common.Uint256
+	hasProof      bool
+	sourceChainID uint64
+	crossChainID  []byte
+}
+
+// extraCrossChainID returns the cross chain id of the MakeTxParam carried as the extra
+// by the routers proving the param itself, nil if extra is not such a param.
+func extraCrossChainID(extra []byte) []byte {
+	param := new(scom.MakeTxParam)
+	source := common.NewZeroCopySource(extra)
+	if err := param.Deserialization(source); err != nil || source.Len() != 0 {
+		return nil
+	}
+	if len(param.CrossChainID) == 0 {
+		return nil
+	}
+	return param.CrossChainID
+}
+
+// GetProofKey returns the key of the proof submitted by tx, false if tx submits none.
+func GetProofKey(tx *types.Transaction) (common.Uint256, bool) {
+	class := classifyTx(tx)
+	return class.proofKey, class.hasProof
+}
+
+// GetCrossChainID returns the source chain and the cross chain id of the
+// ImportOuterTransfer transaction tx if they can be read without verifying the
+// proof. They're only a hint and never used to drop a transaction, the cross chain
+// manager checks the done tx when the transaction is executed.
+func GetCrossChainID(tx *types.Transaction) (uint64, []byte, bool) {
+	class := classifyTx(tx)
+	if class.crossChainID == nil {
+		return 0, nil, false
+	}
+	return class.sourceChainID, class.crossChainID, true
+}
+
+// classifyTx decodes the invoke payload of tx, the transactions it can't decode
+// are ordinary ones.
+func classifyTx(tx *types.Transaction) *txClass {
+	class := &txClass{}
+	if tx.TxType != types.Invoke {
+		return class
+	}
+	code, ok := tx.Payload.(*payload.InvokeCode)
+	if !ok {
+		return class
+	}
+	param := new(states.ContractInvokeParam)
+	if err := param.Deserialization(common.NewZeroCopySource(code.Code)); err != nil {
+		return class
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	switch {
+	case param.Address == utils.HeaderSyncContractAddress:
+		class.weight = config.DefConfig.TxPool.HeaderSyncWeight
+		switch param.Method {
+		case header_sync.SYNC_BLOCK_HEADER:
+			p := new(hscommon.SyncBlockHeaderParam)
+			if err := p.Deserialization(common.NewZeroCopySource(param.Args)); err != nil {
+				return class
+			}
+			sink.WriteString(param.Method)
+			sink.WriteUint64(p.ChainID)
+			sink.WriteVarUint(uint64(len(p.Headers)))
+			for _, v := range p.Headers {
+				sink.WriteVarBytes(v)
+			}
+		case header_sync.SYNC_CROSS_CHAIN_MSG:
+			p := new(hscommon.SyncCrossChainMsgParam)
+			if err := p.Deserialization(common.NewZeroCopySource(param.Args)); err != nil {
+				return class
+			}
+			sink.WriteString(param.Method)
+			sink.WriteUint64(p.ChainID)
+			sink.WriteVarUint(uint64(len(p.CrossChainMsgs)))
+			for _, v := range p.CrossChainMsgs {
+				sink.WriteVarBytes(v)
+			}
+		default:
+			return class
+		}
+	case param.Address == utils.CrossChainManagerContractAddress &&
+		param.Method == cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME:
+		class.weight = config.DefConfig.TxPool.ImportTransferWeight
+		p := new(scom.EntranceParam)
+		if err := p.Deserialization(common.NewZeroCopySource(param.Args)); err != nil {
+			return class
+		}
+		if crossChainID := extraCrossChainID(p.Extra); crossChainID != nil {
+			class.sourceChainID = p.SourceChainID
+			class.crossChainID = crossChainID
+		}
+		// the cross chain id is not verified yet, keying on it would let a forged proof of the id keep
+		// the real one out of the pool
+		sink.WriteString(param.Method)
+		sink.WriteUint64(p.SourceChainID)
+		sink.WriteVarBytes(p.Proof)
+		sink.WriteVarBytes(p.Extra)
+	default:
+		return class
+	}
+	class.proofKey = sha256.Sum256(sink.Bytes())
+	class.hasProof = true
+	return class
+}
@@ -20,6 +20,7 @@
 package common
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/polynetwork/poly/common"
@@ -39,15 +40,26 @@ type TXAttr struct {
 type TXEntry struct {
 	Tx    *types.Transaction // transaction which has been verified
 	Attrs []*TXAttr          // the result from each validator
+	class *txClass           // the packing weight and proof key
+}
+
+// weight returns the packing weight of the entry
+func (txEntry *TXEntry) weight() uint {
+	if txEntry.class == nil {
+		return 0
+	}
+	return txEntry.class.weight
 }
 
 // TXPool contains all currently valid transactions. Transactions
 // enter the pool when they are valid from the network,
 // consensus or submitted. They exit the pool when they are included
-// in the ledger.
+// in the ledger. The proofs submitted by several relayers are kept
+// only once.
 type TXPool struct {
 	sync.RWMutex
-	txList map[common.Uint256]*TXEntry // Transactions which have been verified
+	txList map[common.Uint256]*TXEntry       // Transactions which have been verified
+	proofs map[common.Uint256]common.Uint256 // Proof key to the transaction submitting it
 }
 
 // Init creates a new transaction pool to gather.
@@ -55,12 +67,13 @@ func (tp *TXPool) Init() {
 	tp.Lock()
 	defer tp.Unlock()
 	tp.txList = make(map[common.Uint256]*TXEntry)
+	tp.proofs = make(map[common.Uint256]common.Uint256)
 }
 
 // AddTxList adds a valid transaction to the transaction pool. If the
-// transaction is already in the pool, just return false. Parameter
-// txEntry includes transaction, fee, and verified information(height,
-// validator, error code).
+// transaction or another one submitting the same proof is already in
+// the pool, just return false. Parameter txEntry includes transaction,
+// fee, and verified information(height, validator, error code).
 func (tp *TXPool) AddTxList(txEntry *TXEntry) bool {
 	tp.Lock()
 	defer tp.Unlock()
@@ -71,10 +84,35 @@ func (tp *TXPool) AddTxList(txEntry *TXEntry) bool {
 		return false
 	}
 
+	if txEntry.class == nil {
+		txEntry.class = classifyTx(txEntry.Tx)
+	}
+	if txEntry.class.hasProof {
+		if hash, ok := tp.proofs[txEntry.class.proofKey]; ok {
+			log.Infof("AddTxList: transaction %x submits the same proof as %x in the pool",
+				txHash, hash)
+			return false
+		}
+		tp.proofs[txEntry.class.proofKey] = txHash
+	}
+
 	tp.txList[txHash] = txEntry
 	return true
 }
 
+// delTx removes the transaction and its proof key, the caller holds the lock.
+func (tp *TXPool) delTx(txHash common.Uint256) bool {
+	txEntry, ok := tp.txList[txHash]
+	if !ok {
+		return false
+	}
+	if txEntry.class != nil && txEntry.class.hasProof {
+		delete(tp.proofs, txEntry.class.proofKey)
+	}
+	delete(tp.txList, txHash)
+	return true
+}
+
 // CleanTransactionList cleans the transaction list included in the ledger.
 func (tp *TXPool) CleanTransactionList(txs []*types.Transaction) error {
 	cleaned := 0
@@ -82,8 +120,7 @@ func (tp *TXPool) CleanTransactionList(txs []*types.Transaction) error {
 	tp.Lock()
 	defer tp.Unlock()
 	for _, tx := range txs {
-		if _, ok := tp.txList[tx.Hash()]; ok {
-			delete(tp.txList, tx.Hash())
+		if tp.delTx(tx.Hash()) {
 			cleaned++
 		}
 	}
@@ -97,12 +134,7 @@ func (tp *TXPool) CleanTransactionList(txs []*types.Transaction) error {
 func (tp *TXPool) DelTxList(tx *types.Transaction) bool {
 	tp.Lock()
 	defer tp.Unlock()
-	txHash := tx.Hash()
-	if _, ok := tp.txList[txHash]; !ok {
-		return false
-	}
-	delete(tp.txList, txHash)
-	return true
+	return tp.delTx(tx.Hash())
 }
 
 // compareTxHeight compares a verifed transaction's height with the next
@@ -121,15 +153,20 @@ func (tp *TXPool) compareTxHeight(txEntry *TXEntry, height uint32) bool {
 // GetTxPool gets the transaction lists from the pool for the consensus,
 // if the byCount is marked, return the configured number at most; if the
 // the byCount is not marked, return all of the current transaction pool.
+// The transactions of heavier weight come first so that the header-sync
+// and cross chain imports are packed however busy the pool is.
 func (tp *TXPool) GetTxPool(byCount bool, height uint32) ([]*TXEntry,
 	[]*types.Transaction) {
 	tp.RLock()
 	defer tp.RUnlock()
 
-	orderByFee := make([]*TXEntry, 0, len(tp.txList))
+	orderByWeight := make([]*TXEntry, 0, len(tp.txList))
 	for _, txEntry := range tp.txList {
-		orderByFee = append(orderByFee, txEntry)
+		orderByWeight = append(orderByWeight, txEntry)
 	}
+	sort.SliceStable(orderByWeight, func(i, j int) bool {
+		return orderByWeight[i].weight() > orderByWeight[j].weight()
+	})
 
 	count := int(config.DefConfig.Consensus.MaxTxInBlock)
 	if count <= 0 {
@@ -142,7 +179,7 @@ func (tp *TXPool) GetTxPool(byCount bool, height uint32) ([]*TXEntry,
 	var num int
 	txList := make([]*TXEntry, 0, count)
 	oldTxList := make([]*types.Transaction, 0)
-	for _, txEntry := range orderByFee {
+	for _, txEntry := range orderByWeight {
 		if !tp.compareTxHeight(txEntry, height) {
 			oldTxList = append(oldTxList, txEntry.Tx)
 			continue
@@ -168,6 +205,15 @@ func (tp *TXPool) GetTransaction(hash common.Uint256) *types.Transaction {
 	return tp.txList[hash].Tx
 }
 
+// GetProofTx returns the hash of the transaction in the pool which submits
+// the proof of key.
+func (tp *TXPool) GetProofTx(key common.Uint256) (common.Uint256, bool) {
+	tp.RLock()
+	defer tp.RUnlock()
+	hash, ok := tp.proofs[key]
+	return hash, ok
+}
+
 // GetTxStatus returns a transaction status if it is contained in the pool
 // and nil otherwise.
 func (tp *TXPool) GetTxStatus(hash common.Uint256) *TxStatus {
@@ -212,7 +258,7 @@ func (tp *TXPool) GetUnverifiedTxs(txs []*types.Transaction,
 		}
 
 		if !tp.compareTxHeight(txEntry, height) {
-			delete(tp.txList, tx.Hash())
+			tp.delTx(tx.Hash())
 			res.OldTxs = append(res.OldTxs, txEntry.Tx)
 			continue
 		}
@@ -242,7 +288,7 @@ func (tp *TXPool) Remain() []*types.Transaction {
 	txList := make([]*types.Transaction, 0, len(tp.txList))
 	for _, txEntry := range tp.txList {
 		txList = append(txList, txEntry.Tx)
-		delete(tp.txList, txEntry.Tx.Hash())
+		tp.delTx(txEntry.Tx.Hash())
 	}
 
 	return txList
@@ -19,9 +19,13 @@
 package common
 
 import (
+	pcom "github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/payload"
 	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/client"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -98,3 +102,84 @@ func TestTxPool(t *testing.T) {
 		return
 	}
 }
+
+func TestTxPoolPriority(t *testing.T) {
+	txPool := &TXPool{}
+	txPool.Init()
+
+	assert.True(t, txPool.AddTxList(&TXEntry{Tx: txn, Attrs: []*TXAttr{}}))
+
+	importTx := func(relayer byte, nonce uint32) *types.Transaction {
+		tx, err := client.ImportOuterTransfer(&scom.EntranceParam{
+			SourceChainID:  2,
+			Height:         100,
+			Proof:          []byte("proof"),
+			RelayerAddress: []byte{relayer},
+			Extra:          []byte("extra"),
+		}).Transaction(0, nonce)
+		assert.Nil(t, err)
+		return tx
+	}
+	imported := importTx(1, 1)
+	assert.True(t, txPool.AddTxList(&TXEntry{Tx: imported, Attrs: []*TXAttr{}}))
+	// the same proof from another relayer
+	dup := importTx(2, 2)
+	assert.False(t, txPool.AddTxList(&TXEntry{Tx: dup, Attrs: []*TXAttr{}}))
+
+	headerTx, err := client.SyncBlockHeader(&hscommon.SyncBlockHeaderParam{
+		ChainID: 2,
+		Headers: [][]byte{[]byte("header")},
+	}).Transaction(0, 3)
+	assert.Nil(t, err)
+	assert.True(t, txPool.AddTxList(&TXEntry{Tx: headerTx, Attrs: []*TXAttr{}}))
+
+	txList, _ := txPool.GetTxPool(false, 0)
+	assert.Equal(t, 3, len(txList))
+	assert.Equal(t, headerTx.Hash(), txList[0].Tx.Hash())
+	assert.Equal(t, imported.Hash(), txList[1].Tx.Hash())
+	assert.Equal(t, txn.Hash(), txList[2].Tx.Hash())
+
+	// the proof is accepted again once the first submission leaves the pool
+	assert.True(t, txPool.DelTxList(imported))
+	assert.True(t, txPool.AddTxList(&TXEntry{Tx: dup, Attrs: []*TXAttr{}}))
+}
+
+func TestTxPoolCrossChainID(t *testing.T) {
+	txPool := &TXPool{}
+	txPool.Init()
+
+	sink := pcom.NewZeroCopySink(nil)
+	(&scom.MakeTxParam{
+		TxHash:       []byte{1},
+		CrossChainID: []byte{2},
+		Method:       "unlock",
+	}).Serialization(sink)
+	importTx := func(height uint32, proof string) *types.Transaction {
+		tx, err := client.ImportOuterTransfer(&scom.EntranceParam{
+			SourceChainID: 2,
+			Height:        height,
+			Proof:         []byte(proof),
+			Extra:         sink.Bytes(),
+		}).Transaction(0, height)
+		assert.Nil(t, err)
+		return tx
+	}
+
+	first := importTx(100, "proof at 100")
+	chainID, crossChainID, ok := GetCrossChainID(first)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), chainID)
+	assert.Equal(t, []byte{2}, crossChainID)
+	assert.True(t, txPool.AddTxList(&TXEntry{Tx: first, Attrs: []*TXAttr{}}))
+
+	// the same cross chain id with another proof isn't taken for a duplicate, the id is unverified
+	second := importTx(101, "proof at 101")
+	key, ok := GetProofKey(second)
+	assert.True(t, ok)
+	_, ok = txPool.GetProofTx(key)
+	assert.False(t, ok)
+	assert.True(t, txPool.AddTxList(&TXEntry{Tx: second, Attrs: []*TXAttr{}}))
+
+	_, _, ok = GetCrossChainID(txn)
+	assert.False(t, ok)
+}
@@ -95,6 +95,15 @@ func (ta *TxActor) handleTransaction(sender tc.SenderType, self *actor.PID,
 			replyTxResult(txResultCh, txn.Hash(), errors.ErrDuplicateInput,
 				fmt.Sprintf("transaction %x is already in the tx pool", txn.Hash()))
 		}
+	} else if hash, ok := ta.server.getProofTx(txn); ok {
+		log.Debugf("handleTransaction: transaction %x submits the same proof as %x",
+			txn.Hash(), hash)
+
+		ta.server.increaseStats(tc.DuplicateStats)
+		if sender == tc.HttpSender && txResultCh != nil {
+			replyTxResult(txResultCh, txn.Hash(), errors.ErrDuplicateInput,
+				fmt.Sprintf("transaction %x submits the same proof as %x", txn.Hash(), hash))
+		}
 	} else if ta.server.getTransactionCount() >= tc.MAX_CAPACITY {
 		log.Debugf("handleTransaction: transaction pool is full for tx %x",
 			txn.Hash())
@@ -38,9 +38,11 @@ type txStats struct {
 }
 
 type serverPendingTx struct {
-	tx     *tx.Transaction   // Pending tx
-	sender tc.SenderType     // Indicate which sender tx is from
-	ch     chan *tc.TxResult // channel to send tx result
+	tx       *tx.Transaction   // Pending tx
+	sender   tc.SenderType     // Indicate which sender tx is from
+	ch       chan *tc.TxResult // channel to send tx result
+	proofKey common.Uint256    // The key of the proof submitted by the tx
+	hasProof bool              // Whether the tx submits a proof
 }
 
 type pendingBlock struct {
@@ -68,6 +70,7 @@ type TXPoolServer struct {
 	workers               []txPoolWorker                      // Worker pool
 	txPool                *tc.TXPool                          // The tx pool that holds the valid transaction
 	allPendingTxs         map[common.Uint256]*serverPendingTx // The txs that server is processing
+	pendingProofs         map[common.Uint256]common.Uint256   // The proofs submitted by the processing txs
 	pendingBlock          *pendingBlock                       // The block that server is processing
 	actors                map[tc.ActorType]*actor.PID         // The actors running in the server
 	validators            *registerValidators                 // The registered validators
@@ -93,6 +96,7 @@ func (s *TXPoolServer) init(num uint8, disablePreExec, disableBroadcastNetTx boo
 	s.txPool = &tc.TXPool{}
 	s.txPool.Init()
 	s.allPendingTxs = make(map[common.Uint256]*serverPendingTx)
+	s.pendingProofs = make(map[common.Uint256]common.Uint256)
 	s.actors = make(map[tc.ActorType]*actor.PID)
 
 	s.validators = &registerValidators{
@@ -215,6 +219,9 @@ func (s *TXPoolServer) removePendingTx(hash common.Uint256,
 	}
 
 	delete(s.allPendingTxs, hash)
+	if pt.hasProof && s.pendingProofs[pt.proofKey] == hash {
+		delete(s.pendingProofs, pt.proofKey)
+	}
 
 	if len(s.allPendingTxs) < tc.MAX_LIMITATION {
 		select {
@@ -249,11 +256,33 @@ func (s *TXPoolServer) setPendingTx(tx *tx.Transaction,
 		sender: sender,
 		ch:     txResultCh,
 	}
+	// the duplicated proofs are rejected before, the ones reaching here are from
+	// the blocks to verify which are processed anyway
+	pt.proofKey, pt.hasProof = tc.GetProofKey(tx)
+	if _, ok := s.pendingProofs[pt.proofKey]; pt.hasProof && !ok {
+		s.pendingProofs[pt.proofKey] = tx.Hash()
+	}
 
 	s.allPendingTxs[tx.Hash()] = pt
 	return true
 }
 
+// getProofTx returns the hash of the transaction in the verifying process or
+// in the tx pool which submits the same proof as tx.
+func (s *TXPoolServer) getProofTx(tx *tx.Transaction) (common.Uint256, bool) {
+	key, ok := tc.GetProofKey(tx)
+	if !ok {
+		return common.UINT256_EMPTY, false
+	}
+	s.mu.RLock()
+	hash, ok := s.pendingProofs[key]
+	s.mu.RUnlock()
+	if ok {
+		return hash, true
+	}
+	return s.txPool.GetProofTx(key)
+}
+
 // assignTxToWorker assigns a new transaction to a worker by LB
 func (s *TXPoolServer) assignTxToWorker(tx *tx.Transaction,
 	sender tc.SenderType, txResultCh chan *tc.TxResult) bool {
@@ -22,8 +22,12 @@ import (
 	"github.com/ontio/ontology-eventbus/actor"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/ledger"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/errors"
+	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	tc "github.com/polynetwork/poly/txnpool/common"
 	"github.com/polynetwork/poly/validator/db"
 	vatypes "github.com/polynetwork/poly/validator/types"
 	"reflect"
@@ -77,6 +81,11 @@ func (self *validator) Receive(context actor.Context) {
 			errCode = errors.ErrUnknown
 		} else if exist {
 			errCode = errors.ErrDuplicatedTx
+		} else if done, err := isCrossChainTxDone(msg.Tx); err != nil {
+			log.Warn("query done tx error:", err)
+			errCode = errors.ErrUnknown
+		} else if done {
+			errCode = errors.ErrDuplicatedTx
 		}
 
 		response := &vatypes.CheckResponse{
@@ -105,6 +114,25 @@ func (self *validator) Receive(context actor.Context) {
 
 }
 
+// isCrossChainTxDone checks the done tx registry of the cross chain manager for
+// the cross chain tx imported by tx, so that the proofs of a tx already imported
+// don't reach the blocks.
+func isCrossChainTxDone(tx *types.Transaction) (bool, error) {
+	chainID, crossChainID, ok := tc.GetCrossChainID(tx)
+	if !ok {
+		return false, nil
+	}
+	key := append([]byte(ccmcom.DONE_TX), utils.GetUint64Bytes(chainID)...)
+	_, err := ledger.DefLedger.GetStorageItem(utils.CrossChainManagerContractAddress, append(key, crossChainID...))
+	if err == scom.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (self *validator) VerifyType() vatypes.VerifyType {
 	return vatypes.Stateful
 }